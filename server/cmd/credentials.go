@@ -0,0 +1,206 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+)
+
+// adminUsage is the usage of the `admin create-user` command.
+const adminUsage = `Usage: burzcontent admin create-user [flags]
+
+Creates an admin user of a site along with an admin API key owned by the user, through
+the management API of a running server, to bootstrap a fresh deployment. The API key
+is printed once, it can not be retrieved later.
+
+The server must be running: its store lives in the memory of its process, hence the
+command can not reach the store directly, nor work while the server is down.
+
+Flags:
+`
+
+// apiKeyUsage is the usage of the `apikey rotate` command.
+const apiKeyUsage = `Usage: burzcontent apikey rotate [flags] <id>
+
+Replaces the key of an API key of a site, through the management API of a running
+server, e.g. when it leaked. The former key stops working right away and the new key
+is printed once, it can not be retrieved later.
+
+The server must be running: its store lives in the memory of its process, hence the
+command can not reach the store directly, nor work while the server is down.
+
+Flags:
+`
+
+/*
+runAdmin runs the `admin` command with the given arguments, i.e. creates an admin user
+of a site of a running server along with an admin API key owned by the user.
+
+The store of the server lives in the memory of its process, hence the command goes
+through the management API of the server (see `credentialsFlags`), authenticated
+with the root API key of the deployment by default, which is configured out of band.
+*/
+func runAdmin(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "create-user" {
+		return errors.New("unknown admin command, expected: create-user")
+	}
+
+	flags, shared := newCredentialsFlags("admin create-user", adminUsage)
+	name := flags.String("name", "", "the name of the user")
+	email := flags.String("email", "", "the email address of the user")
+
+	if err := flags.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if *name == "" || *email == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("expected the name and the email address of the user")
+	}
+
+	client := shared.client()
+
+	var created struct {
+		User models.User `json:"user"`
+	}
+	user := models.User{Name: *name, Email: *email, Role: auth.RoleAdmin}
+	if err := client.do(http.MethodPost, "/users", user, &created); err != nil {
+		return fmt.Errorf("unable to create user: %w", err)
+	}
+
+	var issued struct {
+		APIKey models.APIKey `json:"api_key"`
+		Key    string        `json:"key"`
+	}
+	key := models.APIKey{
+		Name:   "Admin key of " + created.User.Name,
+		Role:   string(auth.RoleAdmin),
+		UserID: created.User.ID,
+	}
+	if err := client.do(http.MethodPost, "/keys", key, &issued); err != nil {
+		return fmt.Errorf("unable to issue API key: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "created user %s (%s)\n", created.User.ID, created.User.Email)
+	fmt.Fprintf(stdout, "issued API key %s: %s\n", issued.APIKey.ID, issued.Key)
+
+	return nil
+}
+
+/*
+runAPIKey runs the `apikey` command with the given arguments, i.e. rotates an API key
+of a site of a running server.
+
+Like the `admin` command, the command goes through the management API of the server
+(see `credentialsFlags`), authenticated with the root API key of the deployment by
+default, so that any API key can be rotated even if every other one leaked.
+*/
+func runAPIKey(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "rotate" {
+		return errors.New("unknown apikey command, expected: rotate")
+	}
+
+	flags, shared := newCredentialsFlags("apikey rotate", apiKeyUsage)
+
+	if err := flags.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single API key ID")
+	}
+
+	id, err := uuid.Parse(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid API key ID %q", flags.Arg(0))
+	}
+
+	client := shared.client()
+
+	var rotated struct {
+		APIKey models.APIKey `json:"api_key"`
+		Key    string        `json:"key"`
+	}
+	path := "/keys/" + id.String() + "/rotate"
+	if err := client.do(http.MethodPost, path, nil, &rotated); err != nil {
+		return fmt.Errorf("unable to rotate API key: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "rotated API key %s: %s\n", rotated.APIKey.ID, rotated.Key)
+
+	return nil
+}
+
+/*
+credentialsFlags holds the flags shared by the commands managing the credentials of a
+site, which configure the client of the management API:
+  - url: The URL of the management API (`BURZCONTENT_URL`).
+  - key: The API key to authenticate with (`BURZCONTENT_API_KEY`), the root API key of
+    the deployment (`ROOT_API_KEY`) if not set.
+  - site: The slug of the site, resolved from the hostname of the URL if empty.
+*/
+type credentialsFlags struct {
+	baseURL *string
+	apiKey  *string
+	site    *string
+}
+
+// newCredentialsFlags returns the flags of the named command managing the credentials
+// of a site, along with the ones shared by every such command.
+func newCredentialsFlags(name, usage string) (*flag.FlagSet, credentialsFlags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	shared := credentialsFlags{
+		baseURL: flags.String(
+			"url",
+			cmp.Or(os.Getenv("BURZCONTENT_URL"), "http://localhost:8000/admin"),
+			"the URL of the management API of the server",
+		),
+		apiKey: flags.String(
+			"key",
+			"",
+			"the API key to authenticate with (BURZCONTENT_API_KEY or ROOT_API_KEY)",
+		),
+		site: flags.String(
+			"site",
+			"",
+			"the slug of the site, resolved from the hostname of the URL if empty",
+		),
+	}
+
+	return flags, shared
+}
+
+// client returns the client of the management API configured by the parsed flags. The
+// API key is read from the environment if not set, so that it is never printed in the
+// usage of the command.
+func (cf credentialsFlags) client() *apiClient {
+	client := &apiClient{
+		baseURL: strings.TrimSuffix(*cf.baseURL, "/"),
+		apiKey: cmp.Or(
+			*cf.apiKey,
+			os.Getenv("BURZCONTENT_API_KEY"),
+			os.Getenv("ROOT_API_KEY"),
+		),
+	}
+	if *cf.site != "" {
+		client.baseURL += "/s/" + *cf.site
+	}
+
+	return client
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// encryptionUsage is the usage of the `encryption rotate` command.
+const encryptionUsage = `Usage: burzcontent encryption rotate [flags]
+
+Encrypts the personal data of a site again with the primary encryption key, through
+the management API of a running server, once a new primary key is configured. The
+former keys can be removed from ENCRYPTION_KEYS once every site is rotated.
+
+Flags:
+`
+
+/*
+runEncryption runs the `encryption` command with the given arguments, i.e. encrypts
+the personal data of a site of a running server again with the primary encryption key
+(see `Config.NewKeyring`).
+
+Like the `admin` command, the command goes through the management API of the server
+(see `credentialsFlags`), authenticated with the root API key of the deployment by
+default.
+*/
+func runEncryption(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "rotate" {
+		return errors.New("unknown encryption command, expected: rotate")
+	}
+
+	flags, shared := newCredentialsFlags("encryption rotate", encryptionUsage)
+
+	if err := flags.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	var rotated struct {
+		Rotation models.KeyRotation `json:"rotation"`
+	}
+	err := shared.client().do(http.MethodPost, "/encryption/rotate", nil, &rotated)
+	if err != nil {
+		return fmt.Errorf("unable to rotate encryption keys: %w", err)
+	}
+
+	fmt.Fprintf(
+		stdout,
+		"%d users, %d comments, %d consents encrypted again\n",
+		rotated.Rotation.Users, rotated.Rotation.Comments, rotated.Rotation.Consents,
+	)
+
+	return nil
+}
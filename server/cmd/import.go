@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/markdown"
+)
+
+// importUsage is the usage of the `import` command.
+const importUsage = `Usage: burzcontent import markdown [flags] <dir>
+
+Imports the Markdown documents of a directory as the articles of a site, through the
+management API of a running server. The articles are matched to the documents by slug,
+hence importing a directory again only updates the articles which changed. The articles
+changed on the server while they are imported are skipped, to be imported again.
+
+Flags:
+`
+
+/*
+runImport runs the `import` command with the given arguments, i.e. imports the
+Markdown documents of a directory into a site of a running server.
+
+Each document is created as an article, or updates the article of the site holding
+the same slug, from the version it was fetched at. The articles which are already up to
+date are left alone, so that the command can be run again whenever the content changes,
+and the ones updated on the server since they were fetched (`409 Conflict`) are
+skipped, an error being returned once the others are imported. The front matter of the
+documents is mapped as follows:
+  - title, slug and tags: The title, the slug and the tags of the article.
+  - date: The publication date of the article.
+  - draft: The article is not published if it is a draft.
+
+The server is reached at the URL of its management API (`-url`, or the
+`BURZCONTENT_URL` environment variable) with an API key (`-key`, or the
+`BURZCONTENT_API_KEY` environment variable) holding at least the editor role.
+*/
+func runImport(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "markdown" {
+		return errors.New("unknown import source, expected: markdown")
+	}
+
+	flags := flag.NewFlagSet("import markdown", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), importUsage)
+		flags.PrintDefaults()
+	}
+
+	baseURL := flags.String(
+		"url",
+		cmp.Or(os.Getenv("BURZCONTENT_URL"), "http://localhost:8000/admin"),
+		"the URL of the management API of the server",
+	)
+	apiKey := flags.String(
+		"key",
+		os.Getenv("BURZCONTENT_API_KEY"),
+		"the API key to authenticate with",
+	)
+	site := flags.String(
+		"site",
+		"",
+		"the slug of the site, resolved from the hostname of the URL if empty",
+	)
+	author := flags.String("author", "", "the author of the created articles")
+	dryRun := flags.Bool("dry-run", false, "report the changes without making them")
+
+	if err := flags.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a single content directory")
+	}
+
+	docs, err := markdown.ReadDir(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client := &apiClient{baseURL: strings.TrimSuffix(*baseURL, "/"), apiKey: *apiKey}
+	if *site != "" {
+		client.baseURL += "/s/" + *site
+	}
+
+	var existing struct {
+		Articles []models.Article `json:"articles"`
+	}
+	if err := client.do(http.MethodGet, "/articles", nil, &existing); err != nil {
+		return fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	var created, updated, unchanged, conflicting int
+	for _, doc := range docs {
+		article := articleOf(doc)
+
+		i := slices.IndexFunc(existing.Articles, func(a models.Article) bool {
+			return a.Slug == article.Slug
+		})
+
+		action, path, method := "created", "/articles", http.MethodPost
+		if i >= 0 {
+			current := existing.Articles[i]
+			article.Author = current.Author
+			if sameArticle(current, article) {
+				unchanged++
+				continue
+			}
+
+			action = "updated"
+			path, method = "/articles/"+current.ID.String(), http.MethodPut
+			article.Version = current.Version
+		} else {
+			article.Author = *author
+		}
+
+		if !*dryRun {
+			err := client.do(method, path, article, nil)
+
+			var apiErr *apiError
+			if errors.As(err, &apiErr) && apiErr.code == http.StatusConflict {
+				conflicting++
+				fmt.Fprintf(
+					stdout,
+					"skipped %s (%s): changed on the server since it was fetched\n",
+					article.Slug, doc.Path,
+				)
+				continue
+			} else if err != nil {
+				return fmt.Errorf("unable to import %s: %w", doc.Path, err)
+			}
+		}
+
+		if action == "created" {
+			created++
+		} else {
+			updated++
+		}
+		fmt.Fprintf(stdout, "%s %s (%s)\n", action, article.Slug, doc.Path)
+	}
+
+	fmt.Fprintf(
+		stdout,
+		"%d created, %d updated, %d unchanged, %d skipped\n",
+		created, updated, unchanged, conflicting,
+	)
+	if *dryRun {
+		fmt.Fprintln(stdout, "dry run: no changes were made")
+	}
+
+	if conflicting > 0 {
+		return fmt.Errorf(
+			"%d articles changed on the server during the import, import again",
+			conflicting,
+		)
+	}
+
+	return nil
+}
+
+// articleOf returns the article of a Markdown document, without any author.
+func articleOf(doc markdown.Document) models.Article {
+	article := models.Article{
+		Title:       doc.Title,
+		IsPublished: !doc.Draft,
+		ArticleBody: models.ArticleBody{
+			Slug:    doc.Slug,
+			Content: doc.Body,
+			Tags:    doc.Tags,
+		},
+	}
+
+	if !doc.Date.IsZero() && !doc.Draft {
+		date := doc.Date.UTC()
+		article.PublishedAt = &date
+	}
+
+	return article
+}
+
+// sameArticle reports whether the current article is already up to date with the
+// imported one.
+func sameArticle(current, imported models.Article) bool {
+	samePublication := imported.PublishedAt == nil ||
+		(current.PublishedAt != nil && current.PublishedAt.Equal(*imported.PublishedAt))
+
+	return current.Title == imported.Title &&
+		current.IsPublished == imported.IsPublished &&
+		current.Content == imported.Content &&
+		slices.Equal(current.Tags, imported.Tags) &&
+		samePublication
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	code    int    // The status code of the response
+	status  string // The status of the response, e.g. "409 Conflict"
+	message string // The beginning of the body of the response
+}
+
+func (e *apiError) Error() string {
+	return e.status + ": " + e.message
+}
+
+// apiClient is a minimal client of the management API of a server.
+type apiClient struct {
+	baseURL string
+	apiKey  string
+}
+
+// do sends a request with the JSON encoding of body (if any) to the path of the API,
+// and decodes the JSON response into out (if any). The fields of the responses are
+// asked for as the models declare them, whatever the naming convention the server
+// defaults to (see `middleware.JSONNaming`). The error responses are returned as an
+// `*apiError`.
+func (c *apiClient) do(method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", `application/json; profile="default"`)
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &apiError{
+			code:    resp.StatusCode,
+			status:  resp.Status,
+			message: strings.TrimSpace(string(msg)),
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
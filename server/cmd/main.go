@@ -11,12 +11,33 @@ The main function in this package follows these high-level steps:
 3. Starts the server to listen for requests on port 8000.
 
 The application does not exit until the server is stopped.
+
+The application also provides commands to manage the content of a running server:
+  - `burzcontent import markdown <dir>`: imports the Markdown documents of a directory
+    as articles (see `runImport`).
+  - `burzcontent admin create-user`: creates an admin user along with an admin API
+    key, to bootstrap a fresh deployment (see `runAdmin`).
+  - `burzcontent apikey rotate <id>`: replaces the key of a leaked API key (see
+    `runAPIKey`).
+  - `burzcontent encryption rotate`: encrypts the personal data again with the
+    primary encryption key, once the keys are rotated (see `runEncryption`).
 */
 package main
 
 import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	// Embed the IANA time zone database, for the time zones of the sites and of the
+	// schedules to be known on the hosts without one
+	_ "time/tzdata"
+
 	"github.com/Weburz/burzcontent/server/internal/api"
 	"github.com/Weburz/burzcontent/server/internal/config"
+	"github.com/Weburz/burzcontent/server/internal/errreport"
+	"github.com/Weburz/burzcontent/server/internal/logger"
 )
 
 /*
@@ -26,11 +47,18 @@ This function performs the following steps:
 
  1. Initializes a new configuration instance using `config.NewConfig()` to retrieve
     necessary configurations for the server.
- 2. Initializes the request handlers by calling `cfg.InitialiseHandlers()` to set up
-    handler functions based on the configuration.
- 3. Creates a new API instance using `api.NewAPI(handlers)` and initializes it with
-    the handlers.
- 4. Starts the server with the `server.Run()` function, which listens for HTTP requests
+ 2. Enables the reporting of the unexpected errors with `errreport.Init()`, if a
+    Sentry DSN is configured.
+ 3. Constructs the logger of the server with `cfg.NewLogger()`, which writes to a
+    file in production (the commands never open it).
+ 4. Initializes the request handlers by calling `cfg.InitialiseHandlers()` to set up
+    handler functions based on the configuration, logging with the logger. The
+    server does not start if they can not be set up as configured (e.g. the
+    encryption keys are invalid).
+ 5. Creates a new API instance using `api.NewAPI(cfg, handlers)` and initializes it
+    with the configuration and the handlers, which does not start either if the
+    middleware can not be set up as configured (e.g. the GeoIP database).
+ 6. Starts the server with the `server.Run()` function, which listens for HTTP requests
     and processes them based on the defined handlers.
 
 The server will run continuously, handling incoming requests until it is manually
@@ -41,11 +69,58 @@ Example:
   - Requests will be routed according to the handler configuration.
 
 This function is the main entry point for running the server and will not exit until
-the server is stopped.
+the server is stopped. When a command is given instead (e.g. `import`), the command is
+run and the function exits.
 */
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Mask the personal data and the secrets from the lines of the standard log package
+	log.SetOutput(logger.NewRedactWriter(os.Stderr))
+
 	cfg := config.NewConfig()
-	handlers := cfg.InitialiseHandlers()
-	server := api.NewAPI(handlers)
+
+	// Enable the error reporting (if configured), flushing the pending reports on exit
+	if err := errreport.Init(cfg.SentryDSN, cfg.Release, cfg.Env); err != nil {
+		log.Printf("Error reporting is disabled: %v", err)
+	}
+	defer errreport.Flush(2 * time.Second)
+
+	requestLogger, err := cfg.NewLogger()
+	if err != nil {
+		log.Printf("Logs will be written to the standard output: %v", err)
+		requestLogger = logger.New(cfg.Env, os.Stdout)
+	}
+
+	handlers, err := cfg.InitialiseHandlers(requestLogger)
+	if err != nil {
+		log.Fatal("Error starting server: ", err)
+	}
+	server, err := api.NewAPI(cfg, handlers)
+	if err != nil {
+		log.Fatal("Error starting server: ", err)
+	}
 	server.Run()
 }
+
+// runCommand runs the named command with the given arguments.
+func runCommand(name string, args []string) error {
+	switch name {
+	case "import":
+		return runImport(args, os.Stdout)
+	case "admin":
+		return runAdmin(args, os.Stdout)
+	case "apikey":
+		return runAPIKey(args, os.Stdout)
+	case "encryption":
+		return runEncryption(args, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Package adminui serves the admin interface of the sites, a single-page application
+embedded in the binary of the server, so that the small teams get a usable CMS without
+deploying a frontend of their own.
+
+The interface is served at the root of the management API (i.e. at `/admin`, or at `/`
+when the management API has a port of its own), its assets under `/assets/`, and it
+routes its views with the fragment of its URL (e.g. `/admin#/articles`), so that they
+never collide with the routes of the API. It signs in by opening a session of the
+management API with an API key (see the `session` package), whose cookie then
+authenticates its requests to the API.
+*/
+package adminui
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	chi "github.com/go-chi/chi/v5"
+)
+
+// contentSecurityPolicy is the policy of the pages of the interface, which only run
+// their own scripts and can not be framed by another site.
+const contentSecurityPolicy = "default-src 'self'; base-uri 'self'; " +
+	"form-action 'self'; frame-ancestors 'none'"
+
+//go:embed static
+var static embed.FS
+
+// assets holds the assets of the interface, served under `/assets/`.
+var assets, _ = fs.Sub(static, "static/assets")
+
+// index is the page of the interface, which the assets and the requests to the API are
+// resolved against through its `<base>` element.
+var index = template.Must(template.ParseFS(static, "static/index.html"))
+
+// etags holds the entity tag of each asset, by name, so that the browsers revalidate
+// the assets rather than downloading them again.
+var etags = func() map[string]string {
+	tags := make(map[string]string)
+	_ = fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256(content)
+		tags[name] = `"` + hex.EncodeToString(digest[:8]) + `"`
+
+		return nil
+	})
+
+	return tags
+}()
+
+/*
+Index serves the page of the interface, whose `<base>` element points to the path it
+is served at (e.g. `/admin/`), so that it can be mounted under any prefix.
+
+Example:
+
+	admin.Get("/", adminui.Index)
+*/
+func Index(w http.ResponseWriter, r *http.Request) {
+	base := strings.TrimSuffix(r.URL.Path, "/") + "/"
+
+	setHeaders(w)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := index.Execute(w, map[string]string{"Base": base}); err != nil {
+		http.Error(
+			w,
+			"Unable to render admin interface",
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+/*
+Assets serves the assets of the interface (i.e. its scripts and its stylesheets) named
+by the wildcard of the route, revalidated by the browsers with their entity tag.
+
+Example:
+
+	admin.Get("/assets/*", adminui.Assets)
+*/
+func Assets(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "*")
+
+	etag, ok := etags[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	setHeaders(w)
+	w.Header().Set("ETag", etag)
+	http.ServeFileFS(w, r, assets, name)
+}
+
+// setHeaders sets the headers shared by the page and the assets of the interface.
+func setHeaders(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "same-origin")
+}
@@ -0,0 +1,589 @@
+// The admin interface of BurzContent.
+//
+// The views are routed with the fragment of the URL (e.g. `#/articles/<id>`), and the
+// requests to the management API are resolved against the `<base>` of the page (and
+// the `/s/<site>` prefix of the site picked when signing in, if any). They are
+// authenticated with the cookie of the session opened with an API key, the write
+// requests presenting the CSRF token of the session as well.
+"use strict";
+
+const state = {
+  session: null,
+  site: localStorage.getItem("burz.site") || "",
+};
+
+// h creates an element with the given attributes (or event listeners, for the ones
+// starting with "on") and children, the strings being added as text.
+function h(tag, attrs, ...children) {
+  const el = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (value === null || value === undefined || value === false) {
+      continue;
+    }
+    if (name.startsWith("on")) {
+      el.addEventListener(name.slice(2), value);
+    } else if (value === true) {
+      el.setAttribute(name, "");
+    } else {
+      el.setAttribute(name, value);
+    }
+  }
+  for (const child of children.flat()) {
+    if (child !== null && child !== undefined && child !== false) {
+      el.append(child instanceof Node ? child : String(child));
+    }
+  }
+  return el;
+}
+
+// APIError is thrown for the responses with an error status, holding their body.
+class APIError extends Error {
+  constructor(status, message, body) {
+    super(message || "Request failed with status " + status);
+    this.status = status;
+    this.body = body;
+  }
+}
+
+// api makes a request to the management API and returns its decoded JSON body, if it
+// has one.
+async function api(method, path, body, headers) {
+  const prefix = state.site ? "s/" + encodeURIComponent(state.site) + "/" : "";
+  const init = {
+    method,
+    credentials: "same-origin",
+    // Ask for the fields as the models declare them, whatever the default naming
+    // convention of the server
+    headers: { Accept: 'application/vnd.api+json; profile="default", application/json', ...headers },
+  };
+  if (body !== undefined) {
+    init.headers["Content-Type"] = "application/json";
+    init.body = JSON.stringify(body);
+  }
+  if (method !== "GET" && state.session) {
+    init.headers["X-CSRF-Token"] = state.session.csrf_token;
+  }
+
+  const resp = await fetch(new URL(prefix + path, document.baseURI), init);
+  const text = await resp.text();
+  let data = null;
+  if (text && (resp.headers.get("Content-Type") || "").includes("json")) {
+    data = JSON.parse(text);
+  }
+
+  if (!resp.ok) {
+    if (resp.status === 401 && state.session) {
+      state.session = null;
+      render();
+    }
+    const message = data ? data.error || data.detail || data.title : text.trim();
+    throw new APIError(resp.status, message, data);
+  }
+
+  return data;
+}
+
+// notify shows a short message in the corner of the page.
+function notify(message) {
+  const el = h("div", { class: "notice", role: "status" }, message);
+  document.body.append(el);
+  setTimeout(() => el.remove(), 3000);
+}
+
+// formatDate formats a timestamp of the API for humans.
+function formatDate(value) {
+  return value ? new Date(value).toLocaleString() : "";
+}
+
+// toLocalInput and fromLocalInput convert the timestamps of the API from and to the
+// values of the `datetime-local` inputs, in the time zone of the site: the API gives
+// the scheduled times with the offset of the site, and takes the times given without
+// an offset in its time zone.
+function toLocalInput(value) {
+  return value ? value.slice(0, 16) : "";
+}
+
+function fromLocalInput(value) {
+  return value || undefined;
+}
+
+function show(...children) {
+  document.getElementById("main").replaceChildren(...children);
+}
+
+function showError(err) {
+  show(h("p", { class: "error" }, err.message));
+}
+
+function toolbar(title, ...actions) {
+  return h("div", { class: "toolbar" }, h("h1", {}, title), h("div", {}, actions));
+}
+
+function table(columns, rows) {
+  return h(
+    "table",
+    {},
+    h("thead", {}, h("tr", {}, columns.map((column) => h("th", {}, column)))),
+    h("tbody", {}, rows.length ? rows : h("tr", {}, h("td", { colspan: columns.length, class: "muted" }, "Nothing here yet."))),
+  );
+}
+
+function field(label, input) {
+  return h("label", {}, label, input);
+}
+
+// confirmed runs the action once confirmed, reporting its failure.
+async function confirmed(question, action) {
+  if (!confirm(question)) {
+    return;
+  }
+  try {
+    await action();
+  } catch (err) {
+    notify(err.message);
+  }
+}
+
+/* Views */
+
+async function dashboardView() {
+  const { dashboard } = await api("GET", "dashboard");
+  const counts = dashboard.counts;
+
+  show(
+    toolbar("Dashboard"),
+    h(
+      "div",
+      { class: "stats" },
+      [
+        ["Published articles", counts.published_articles],
+        ["Drafts", counts.draft_articles],
+        ["Users", counts.users],
+        ["Comments", counts.comments],
+      ].map(([label, value]) => h("div", { class: "card stat" }, h("strong", {}, value), label)),
+    ),
+    h("h2", {}, "Top articles (7 days)"),
+    table(
+      ["Title", "Views", "Visitors"],
+      (dashboard.top_articles || []).map((a) =>
+        h("tr", {}, h("td", {}, h("a", { href: "#/articles/" + a.article_id }, a.title)), h("td", {}, a.views), h("td", {}, a.visitors)),
+      ),
+    ),
+    h("h2", {}, "Recent activity"),
+    table(
+      ["When", "Request", "Status"],
+      (dashboard.recent_activity || []).map((e) =>
+        h("tr", {}, h("td", {}, formatDate(e.at)), h("td", {}, e.method + " " + e.path), h("td", {}, e.status)),
+      ),
+    ),
+  );
+}
+
+async function articlesView() {
+  const { articles } = await api("GET", "articles");
+
+  const setPublished = (article, published) => async () => {
+    try {
+      await api("POST", published ? "articles/publish" : "articles/unpublish", { ids: [article.id] });
+      notify(published ? "Article published" : "Article unpublished");
+      route();
+    } catch (err) {
+      notify(err.message);
+    }
+  };
+
+  show(
+    toolbar("Articles", h("a", { href: "#/articles/new" }, h("button", { type: "button" }, "New article"))),
+    table(
+      ["Title", "Author", "Status", "Updated", ""],
+      (articles || []).map((article) =>
+        h(
+          "tr",
+          {},
+          h("td", {}, h("a", { href: "#/articles/" + article.id }, article.title || "(untitled)")),
+          h("td", {}, article.author),
+          h("td", {}, h("span", { class: article.isPublished ? "badge published" : "badge" }, article.isPublished ? "Published" : "Draft")),
+          h("td", {}, formatDate(article.updated_at)),
+          h(
+            "td",
+            { class: "actions" },
+            article.isPublished
+              ? h("button", { type: "button", class: "secondary", onclick: setPublished(article, false) }, "Unpublish")
+              : h("button", { type: "button", class: "secondary", onclick: setPublished(article, true) }, "Publish"),
+            " ",
+            h(
+              "button",
+              {
+                type: "button",
+                class: "danger",
+                onclick: () =>
+                  confirmed("Move “" + article.title + "” to the trash?", async () => {
+                    await api("DELETE", "articles/" + article.id);
+                    notify("Article moved to the trash");
+                    route();
+                  }),
+              },
+              "Delete",
+            ),
+          ),
+        ),
+      ),
+    ),
+  );
+}
+
+async function articleView(id) {
+  const article = id === "new" ? { title: "", author: "", isPublished: false } : (await api("GET", "articles/" + id)).article;
+
+  const form = h(
+    "form",
+    { class: "card" },
+    field("Title", h("input", { type: "text", name: "title", value: article.title, required: true })),
+    field("Author", h("input", { type: "text", name: "author", value: article.author })),
+    field("Slug", h("input", { type: "text", name: "slug", value: article.slug || "", spellcheck: "false" })),
+    field("Tags", h("input", { type: "text", name: "tags", value: (article.tags || []).join(", "), placeholder: "go, tutorials" })),
+    field("Content", h("textarea", { name: "content" }, article.content || "")),
+    field("Publish at", h("input", { type: "datetime-local", name: "publish_at", value: toLocalInput(article.publish_at) })),
+    field("Unpublish at", h("input", { type: "datetime-local", name: "expires_at", value: toLocalInput(article.expires_at) })),
+    h("label", { class: "inline" }, h("input", { type: "checkbox", name: "isPublished", checked: article.isPublished }), "Published"),
+    h("p", { class: "error", hidden: true }),
+    h("button", { type: "submit" }, "Save"),
+  );
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const data = new FormData(form);
+    const body = {
+      title: data.get("title"),
+      author: data.get("author"),
+      slug: data.get("slug") || undefined,
+      tags: data
+        .get("tags")
+        .split(",")
+        .map((tag) => tag.trim())
+        .filter(Boolean),
+      content: data.get("content"),
+      publish_at: fromLocalInput(data.get("publish_at")),
+      expires_at: fromLocalInput(data.get("expires_at")),
+      isPublished: data.get("isPublished") === "on",
+    };
+
+    const error = form.querySelector(".error");
+    error.hidden = true;
+    try {
+      if (id === "new") {
+        const created = await api("POST", "articles", body);
+        notify("Article created");
+        location.hash = "#/articles/" + created.article.id;
+      } else {
+        body.version = article.version;
+        const updated = await api("PUT", "articles/" + id, body);
+        article.version = updated.article.version;
+        notify("Article saved");
+      }
+    } catch (err) {
+      error.textContent =
+        err.body && err.body.current_version ? "The article was updated in the meantime (version " + err.body.current_version + "), reload it to see the changes." : err.message;
+      error.hidden = false;
+    }
+  });
+
+  show(toolbar(id === "new" ? "New article" : "Edit article", h("a", { href: "#/articles" }, "Back to the articles")), form);
+}
+
+async function pagesView() {
+  const { pages } = await api("GET", "pages");
+
+  show(
+    toolbar("Pages", h("a", { href: "#/pages/new" }, h("button", { type: "button" }, "New page"))),
+    table(
+      ["Title", "Path", "Status", ""],
+      (pages || []).map((page) =>
+        h(
+          "tr",
+          {},
+          h("td", {}, h("a", { href: "#/pages/" + page.id }, page.title)),
+          h("td", {}, page.path),
+          h("td", {}, h("span", { class: page.isPublished ? "badge published" : "badge" }, page.isPublished ? "Published" : "Draft")),
+          h(
+            "td",
+            { class: "actions" },
+            h(
+              "button",
+              {
+                type: "button",
+                class: "danger",
+                onclick: () =>
+                  confirmed("Delete the page “" + page.title + "”?", async () => {
+                    await api("DELETE", "pages/" + page.id);
+                    notify("Page deleted");
+                    route();
+                  }),
+              },
+              "Delete",
+            ),
+          ),
+        ),
+      ),
+    ),
+  );
+}
+
+async function pageView(id) {
+  const page = id === "new" ? { path: "/", title: "", isPublished: false } : (await api("GET", "pages/" + id)).page;
+
+  const form = h(
+    "form",
+    { class: "card" },
+    field("Title", h("input", { type: "text", name: "title", value: page.title, required: true })),
+    field("Path", h("input", { type: "text", name: "path", value: page.path, required: true, spellcheck: "false" })),
+    field("Content", h("textarea", { name: "content" }, page.content || "")),
+    h("label", { class: "inline" }, h("input", { type: "checkbox", name: "isPublished", checked: page.isPublished }), "Published"),
+    h("p", { class: "error", hidden: true }),
+    h("button", { type: "submit" }, "Save"),
+  );
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const data = new FormData(form);
+    const body = {
+      title: data.get("title"),
+      path: data.get("path"),
+      content: data.get("content"),
+      isPublished: data.get("isPublished") === "on",
+    };
+
+    const error = form.querySelector(".error");
+    error.hidden = true;
+    try {
+      if (id === "new") {
+        const created = await api("POST", "pages", body);
+        notify("Page created");
+        location.hash = "#/pages/" + created.page.id;
+      } else {
+        await api("PUT", "pages/" + id, body);
+        notify("Page saved");
+      }
+    } catch (err) {
+      error.textContent = err.message;
+      error.hidden = false;
+    }
+  });
+
+  show(toolbar(id === "new" ? "New page" : "Edit page", h("a", { href: "#/pages" }, "Back to the pages")), form);
+}
+
+async function commentsView() {
+  const { comments } = await api("GET", "comments");
+
+  show(
+    toolbar("Comments"),
+    table(
+      ["Author", "Comment", "Article", "Posted", ""],
+      (comments || []).map((comment) =>
+        h(
+          "tr",
+          {},
+          h("td", {}, comment.name, h("br"), h("small", { class: "muted" }, comment.email)),
+          h("td", {}, comment.content),
+          h("td", {}, h("a", { href: "#/articles/" + comment.article_id }, "View article")),
+          h("td", {}, formatDate(comment.created_at)),
+          h(
+            "td",
+            { class: "actions" },
+            h(
+              "button",
+              {
+                type: "button",
+                class: "danger",
+                onclick: () =>
+                  confirmed("Delete the comment of " + comment.name + "?", async () => {
+                    await api("DELETE", "comments/" + comment.id);
+                    notify("Comment deleted");
+                    route();
+                  }),
+              },
+              "Delete",
+            ),
+          ),
+        ),
+      ),
+    ),
+  );
+}
+
+async function usersView(query) {
+  const number = Number(query.get("page")) || 1;
+  const { users, meta } = await api("GET", "users?page[number]=" + number + "&page[size]=25");
+
+  const pageLink = (n, label) => (n >= 1 && n <= meta.pages ? h("a", { href: "#/users?page=" + n }, label) : h("span", { class: "muted" }, label));
+
+  show(
+    toolbar("Users"),
+    table(
+      ["Name", "Email", "Role", "Joined"],
+      (users || []).map((user) =>
+        h("tr", {}, h("td", {}, user.name), h("td", {}, user.email), h("td", {}, user.role), h("td", {}, formatDate(user.created_at))),
+      ),
+    ),
+    h(
+      "div",
+      { class: "pager" },
+      pageLink(number - 1, "Previous"),
+      h("span", {}, "Page " + meta.page + " of " + Math.max(meta.pages, 1) + " (" + meta.total + " users)"),
+      pageLink(number + 1, "Next"),
+    ),
+  );
+}
+
+async function settingsView() {
+  const { settings } = await api("GET", "settings");
+  const isAdmin = state.session.root || state.session.role === "admin";
+
+  const form = h(
+    "form",
+    { class: "card" },
+    field("Title", h("input", { type: "text", name: "title", value: settings.title })),
+    field("Description", h("input", { type: "text", name: "description", value: settings.description })),
+    field("Default locale", h("input", { type: "text", name: "default_locale", value: settings.default_locale, placeholder: "en" })),
+    field("Timezone", h("input", { type: "text", name: "timezone", value: settings.timezone, placeholder: "Europe/Berlin" })),
+    field(
+      "Comments",
+      h(
+        "select",
+        { name: "comment_policy" },
+        ["open", "closed"].map((policy) => h("option", { value: policy, selected: settings.comment_policy === policy }, policy)),
+      ),
+    ),
+    h("label", { class: "inline" }, h("input", { type: "checkbox", name: "require_review", checked: settings.require_review }), "Require an editorial review before publishing"),
+    h("p", { class: "error", hidden: true }),
+    isAdmin ? h("button", { type: "submit" }, "Save") : h("p", { class: "muted" }, "Only the admins can update the settings."),
+  );
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const data = new FormData(form);
+    const error = form.querySelector(".error");
+    error.hidden = true;
+    try {
+      await api("PATCH", "settings", {
+        title: data.get("title"),
+        description: data.get("description"),
+        default_locale: data.get("default_locale"),
+        timezone: data.get("timezone"),
+        comment_policy: data.get("comment_policy"),
+        require_review: data.get("require_review") === "on",
+      });
+      notify("Settings saved");
+    } catch (err) {
+      error.textContent = err.message;
+      error.hidden = false;
+    }
+  });
+
+  show(toolbar("Settings"), form);
+}
+
+/* Routing and session */
+
+const routes = [
+  [/^\/$/, dashboardView],
+  [/^\/articles$/, articlesView],
+  [/^\/articles\/([\w-]+)$/, articleView],
+  [/^\/pages$/, pagesView],
+  [/^\/pages\/([\w-]+)$/, pageView],
+  [/^\/comments$/, commentsView],
+  [/^\/users$/, usersView],
+  [/^\/settings$/, settingsView],
+];
+
+async function route() {
+  const [path, search] = (location.hash.slice(1) || "/").split("?");
+  const query = new URLSearchParams(search);
+
+  for (const link of document.querySelectorAll("#nav a")) {
+    const target = link.getAttribute("href").slice(1);
+    link.classList.toggle("active", target === "/" ? path === "/" : path.startsWith(target));
+  }
+
+  for (const [pattern, view] of routes) {
+    const match = path.match(pattern);
+    if (match) {
+      try {
+        await (match[1] ? view(match[1], query) : view(query));
+      } catch (err) {
+        if (state.session) {
+          showError(err);
+        }
+      }
+      return;
+    }
+  }
+
+  show(h("p", { class: "muted" }, "Page not found."));
+}
+
+function signInView() {
+  const form = document.getElementById("signin").content.firstElementChild.cloneNode(true);
+  form.elements.site.value = state.site;
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const error = form.querySelector(".error");
+    error.hidden = true;
+
+    state.site = form.elements.site.value.trim();
+    try {
+      const { session } = await api("POST", "session", undefined, {
+        Authorization: "Bearer " + form.elements.key.value.trim(),
+      });
+      localStorage.setItem("burz.site", state.site);
+      state.session = session;
+      render();
+    } catch (err) {
+      error.textContent = err.status === 401 ? "Invalid API key." : err.status === 403 ? "This API key is not valid for this site." : err.message;
+      error.hidden = false;
+    }
+  });
+
+  show(form);
+  form.elements.key.focus();
+}
+
+function render() {
+  const signedIn = state.session !== null;
+  document.getElementById("nav").hidden = !signedIn;
+  document.getElementById("account").hidden = !signedIn;
+
+  if (!signedIn) {
+    signInView();
+    return;
+  }
+
+  const role = state.session.root ? "root" : state.session.role;
+  document.getElementById("whoami").textContent = (state.site ? state.site + " · " : "") + role;
+  route();
+}
+
+async function boot() {
+  try {
+    const { session } = await api("GET", "session");
+    state.session = session;
+  } catch (err) {
+    state.session = null;
+  }
+
+  document.getElementById("signout").addEventListener("click", async () => {
+    try {
+      await api("DELETE", "session");
+    } finally {
+      state.session = null;
+      render();
+    }
+  });
+  window.addEventListener("hashchange", () => state.session && route());
+
+  render();
+}
+
+boot();
@@ -18,26 +18,35 @@ Functions:
 package api
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	chi "github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 
 	"github.com/Weburz/burzcontent/server/internal/api/handlers"
+	"github.com/Weburz/burzcontent/server/internal/api/middleware"
 	"github.com/Weburz/burzcontent/server/internal/api/routes"
+	"github.com/Weburz/burzcontent/server/internal/config"
 )
 
 /*
-API represents the configuration of the HTTP server, including its router
+API represents the configuration of the HTTP server, including its routers
 and other components like database and configuration that could be added later.
 
 Fields:
   - Router: The router (of type *chi.Mux) used for routing HTTP requests to handlers.
     It is based on the chi router, which provides a fast and lightweight way to handle
-    routing for RESTful APIs and other HTTP requests.
+    routing for RESTful APIs and other HTTP requests. It serves the public API, and
+    the management API under `/admin` unless the latter has a port of its own.
+  - AdminRouter: The router serving the management API.
+  - Config: The configuration settings (ports, environment, etc.) of the server.
+  - Handlers: The handlers of the routes, whose services also run the background
+    jobs of the server (e.g. purging the trash, see `jobs`).
 
 Future Enhancements:
   - Additional fields like Db and config can be added to this struct to include a
@@ -50,73 +59,260 @@ Example Usage:
 	}
 */
 type API struct {
-	Router *chi.Mux
+	Router      *chi.Mux
+	AdminRouter *chi.Mux
+	Config      *config.Config
+	Handlers    *handlers.Handlers
 }
 
 /*
-NewAPI creates a new instance of the API server with the given handlers.
+NewAPI creates a new instance of the API server with the given configuration and
+handlers.
 
 This function performs the following steps:
 
- 1. Initializes a new router using `chi.NewRouter()` for routing HTTP requests.
- 2. Adds middleware to the router, such as the `Logger` middleware for logging HTTP
+ 1. Initializes two new routers using `chi.NewRouter()`, one for the public API and
+    one for the management API.
+ 2. Adds middleware to the routers, such as the `RequestID` middleware identifying each
+    request, the `Trace` middleware continuing the trace of each request, the `Recover`
+    middleware recovering from the panics of the handlers and of the other middleware,
+    the `RequestLogger` middleware storing the logger of the handlers in the context of
+    each request (and logging the requests with their statistics), the `StripSlashes`
+    middleware routing the paths with a trailing slash (e.g. `/articles/`) like the ones
+    without, the `AccessLog` middleware writing the access log (if configured) of the
+    requests picked by the configured sampler (see `Config.NewLogSampler`), the `Head`
+    middleware discarding the body of the responses to the `HEAD` requests (but keeping
+    its length), served by the content routes without side effects only, the
+    `JSONNaming` middleware naming the fields of the JSON documents in the convention
+    asked for by each request (or in the configured one, see `Config.NewJSONNaming`),
+    the `InjectFaults` middleware injecting the configured faults outside of production
+    (see `Config.NewFaultInjector`), the `GeoRestrict` middleware locating the country of
+    each request and applying its access rules (if a MaxMind DB file is configured, see
+    `Config.NewGeoRules`) and the `LoadShedder` middleware limiting the concurrent
+    requests (whose budgets are shared by both APIs).
+ 3. Sets up the server's routes by calling `routes.SetupRoutes()`, where the routes are
+    defined based on the provided handlers.
+ 4. Mounts the management API under `/admin` on the public router, unless it is
+    configured to be served on a port of its own.
+ 5. Answers the requests which do not match any route (see `handlers.NotFound`), and
+    the ones whose method is not allowed on their route with the methods the route
+    allows (see `handlers.MethodNotAllowed`), which also answers the `OPTIONS`
     requests.
- 3. Sets up the server's routes by calling `routes.SetupRoutes(router, h)`, where the
-    routes are defined based on the provided handlers.
- 4. Returns a pointer to an `API` instance, which contains the configured router.
+ 6. Returns a pointer to an `API` instance, which contains the configured routers.
 
 The returned `API` instance is ready to handle incoming HTTP requests, with the routes
-and middleware set up according to the provided handlers.
+and middleware set up according to the provided handlers. An error is returned if the
+restrictions of the countries are configured but can not be set up (e.g. the MaxMind DB
+file can not be read), rather than serving the restricted content to every country.
 
 Example:
   - This function can be used to create a new API instance with custom request handlers
     for various routes.
 */
-func NewAPI(h *handlers.Handlers) *API {
-	// Initialise a new `Router` object
+func NewAPI(cfg *config.Config, h *handlers.Handlers) (*API, error) {
+	// Initialise the `Router` objects of the public and the management APIs
 	router := chi.NewRouter()
+	adminRouter := chi.NewRouter()
 
-	// Register the in-built logger
-	router.Use(middleware.Logger)
+	// Limit the concurrent requests to the configured ceilings
+	shedder := middleware.LoadShedder(cfg.MaxReadRequests, cfg.MaxWriteRequests)
+
+	// Inject the configured faults, which are never injected in production
+	faults, err := cfg.NewFaultInjector()
+	if err != nil {
+		log.Printf("No fault will be injected: %v", err)
+	} else if faults != nil {
+		log.Printf("Injecting faults into the responses: %s", cfg.Chaos)
+	}
+
+	// Log a share of the requests to the busy routes, as configured
+	sampler, err := cfg.NewLogSampler()
+	if err != nil {
+		log.Printf("Every request will be logged: %v", err)
+	}
+
+	// Write the access log in the Combined Log Format, if configured
+	accessLog, err := cfg.NewAccessLog()
+	if err != nil {
+		log.Printf("No access log will be written: %v", err)
+	}
+
+	// Locate the country of each request, and restrict the access of the countries as
+	// configured, refusing to serve the restricted content everywhere otherwise
+	geoDB, err := cfg.NewGeoDatabase()
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate the countries of the clients: %w", err)
+	}
+
+	geoRules, err := cfg.NewGeoRules()
+	if err != nil {
+		return nil, fmt.Errorf("unable to restrict the countries: %w", err)
+	}
+
+	// Name the fields of the JSON documents in the convention the clients ask for, or
+	// in the configured one
+	jsonNaming, err := cfg.NewJSONNaming()
+	if err != nil {
+		log.Printf("The JSON fields will be named as declared: %v", err)
+	}
+
+	// The management API inherits the middleware of the public router when mounted
+	// on it
+	base := []*chi.Mux{router}
+	if cfg.AdminPort != "" {
+		base = append(base, adminRouter)
+	}
+
+	for _, r := range base {
+		// Identify each request, in the logs and in the problems answering it
+		r.Use(chimiddleware.RequestID)
+
+		// Continue the trace of each request, so that its logs can be joined with it
+		r.Use(middleware.Trace)
+
+		// Recover from (and report) the panics of the handlers and of the middleware
+		r.Use(middleware.Recover(h.Logger))
+
+		// Hand the logger down to each request, with the ID of the request attached,
+		// and log the requests picked by the sampler once served
+		r.Use(middleware.RequestLogger(h.Logger, sampler))
+
+		// Route the paths with a trailing slash like the ones without, rather than
+		// redirecting them (which would lose the bodies of the requests)
+		r.Use(chimiddleware.StripSlashes)
+
+		// Write the access log as well, for the tooling which only ingests such logs
+		if accessLog != nil {
+			r.Use(middleware.AccessLog(accessLog, h.Clock, sampler))
+		}
+
+		// Discard the bodies of the responses to the HEAD requests, once logged as such
+		// (the routes without side effects serve them with their GET handler)
+		r.Use(middleware.Head)
+
+		// Name the fields of the JSON responses (and request bodies) in the convention
+		// the client asks for
+		r.Use(middleware.JSONNaming(jsonNaming))
+
+		// Delay and fail the responses as configured, to try the clients out
+		if faults != nil {
+			r.Use(middleware.InjectFaults(faults))
+		}
+
+		// Reject the requests of the blocked countries (and the write requests of the
+		// read-only ones), for the content licensed for some countries only
+		if geoDB != nil {
+			r.Use(middleware.GeoRestrict(geoDB, geoRules))
+		}
+
+		// Shed the excess load with the budgets shared by both APIs
+		r.Use(shedder)
+	}
+
+	// Answer the requests which are not routed with problems, before the routes are set
+	// up so that the subrouters inherit the handler (the public routes answer them with
+	// the redirects of their site first)
+	router.NotFound(handlers.NotFound)
+	adminRouter.NotFound(handlers.NotFound)
 
 	// Setup the routes (aka the API endpoints) to receive HTTP requests on
-	routes.SetupRoutes(router, h)
+	cacheMaxAge := time.Duration(cfg.CacheMaxAge) * time.Second
+	routes.SetupRoutes(router, adminRouter, h, cacheMaxAge)
+
+	if cfg.AdminPort == "" {
+		router.Mount("/admin", adminRouter)
+	}
+
+	// Answer the requests whose method is not allowed (with the methods of their route)
+	// with problems once every route is set up, so that the subrouters inherit the
+	// handler
+	for _, r := range base {
+		r.MethodNotAllowed(handlers.MethodNotAllowed(r))
+	}
 
 	// Return an instance of the `API` struct
 	return &API{
-		Router: router,
-	}
+		Router:      router,
+		AdminRouter: adminRouter,
+		Config:      cfg,
+		Handlers:    h,
+	}, nil
 }
 
 /*
-Run sets up all middleware and routes for the server.
+NewDebugRouter creates the router serving the `net/http/pprof` profiling endpoints and
+the metrics of the server.
 
-This function is responsible for:
-  - Mounting the middleware to be used in the server, such as logging middleware.
-  - Mounting the HTTP request handlers for various routes.
-
-Currently, it mounts the following:
-  - `middleware.Logger`: A middleware that logs all incoming HTTP requests.
-  - `"/"` route: A handler function (`HelloWorld`) that responds to requests made to the
-    root path.
+The profiling endpoints are mounted under `/debug` (e.g. `/debug/pprof/heap`) and the
+metrics (see `Handlers.Metrics`) under `/metrics`, in the text format of Prometheus.
+They are guarded by the `middleware.RequireToken` middleware, so every request has to
+carry the configured debug token as a bearer token (e.g. the `authorization` of the
+scrape configuration of Prometheus). The router is meant to be served on a separate
+port which is not exposed to the public internet.
 
-This function can be expanded in the future to add more middleware or routes to the
-server.
+Example:
+  - curl -H "Authorization: Bearer $DEBUG_TOKEN" \
+    http://localhost:6060/debug/pprof/heap > heap.out
 */
+func NewDebugRouter(token string, metrics http.Handler) *chi.Mux {
+	router := chi.NewRouter()
 
+	router.Use(chimiddleware.Logger)
+	router.Use(middleware.RequireToken(token))
+	router.Mount("/debug", chimiddleware.Profiler())
+	router.Method(http.MethodGet, "/metrics", metrics)
+
+	return router
+}
+
+/*
+Run starts the HTTP server(s) and blocks until the API server stops.
+
+This function is responsible for:
+  - Starting the management API server in the background, if a port of its own is
+    configured.
+  - Starting the debug server serving the pprof endpoints and the metrics in the
+    background, if a debug port is configured. The debug server is not started without
+    a debug token.
+  - Starting the workers of the task queue of the server, which run the slow work of
+    the services (e.g. sending the emails) in the background.
+  - Starting the recurring jobs of the server in the background (e.g. purging the
+    trash or unpublishing the expired articles, see `jobs`), on their configured
+    schedules.
+  - Starting the API server on the configured port.
+
+Any error other than `http.ErrServerClosed` returned by the API server is logged and
+returned to the caller.
+*/
 func (a *API) Run() error {
+	// Start the management API server (if separate) in the background
+	if a.Config.AdminPort != "" {
+		go a.runAdmin()
+	}
+
+	// Start the debug server (if enabled) in the background
+	if a.Config.DebugPort != "" {
+		if a.Config.DebugToken == "" {
+			log.Printf("DEBUG_TOKEN is not set, the debug server will not be started")
+		} else {
+			go a.runDebug()
+		}
+	}
+
+	// Run the background tasks and the recurring jobs
+	a.Handlers.TaskHandler.Queue.Start(context.Background())
+	a.startJobs()
+
 	// Set up the HTTP server
 	srv := http.Server{
-		// TODO: Remove the hardcoded port number later down the line
-		Addr:         ":8000",
+		Addr:         ":" + a.Config.Port,
 		Handler:      a.Router,
 		IdleTimeout:  time.Minute,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
 
-	// TODO: Remove the hardcoded port number later down the line
-	log.Printf("Starting the server at [::]:%s", "8000")
+	log.Printf("Starting the server at [::]:%s", a.Config.Port)
 
 	// Start the server
 	err := srv.ListenAndServe()
@@ -127,3 +323,41 @@ func (a *API) Run() error {
 
 	return nil
 }
+
+// runAdmin serves the management API on the configured admin port.
+func (a *API) runAdmin() {
+	srv := http.Server{
+		Addr:         ":" + a.Config.AdminPort,
+		Handler:      a.AdminRouter,
+		IdleTimeout:  time.Minute,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	log.Printf("Starting the management server at [::]:%s", a.Config.AdminPort)
+
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Error starting management server: %v", err)
+	}
+}
+
+// runDebug serves the pprof endpoints and the metrics on the configured debug port.
+func (a *API) runDebug() {
+	// The write timeout has to be long enough to collect CPU profiles and traces,
+	// which default to 30 seconds
+	srv := http.Server{
+		Addr:         ":" + a.Config.DebugPort,
+		Handler:      NewDebugRouter(a.Config.DebugToken, a.Handlers.Metrics),
+		IdleTimeout:  time.Minute,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 2 * time.Minute,
+	}
+
+	log.Printf("Starting the debug server at [::]:%s", a.Config.DebugPort)
+
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Error starting debug server: %v", err)
+	}
+}
@@ -0,0 +1,663 @@
+package api_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api"
+	"github.com/Weburz/burzcontent/server/internal/api/handlers"
+	"github.com/Weburz/burzcontent/server/internal/config"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/testutils"
+)
+
+// rootAPIKey is the root API key of the servers of the benchmarks.
+const rootAPIKey = "root"
+
+// newServer creates a server seeded with the sample data, whose management API is
+// mounted under `/admin`.
+func newServer(tb testing.TB) *api.API {
+	tb.Helper()
+
+	cfg := config.NewConfig()
+	cfg.AdminPort = ""
+
+	h, _ := testutils.NewDeterministicHandlers(
+		1,
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		handlers.Options{RootAPIKey: rootAPIKey},
+	)
+
+	server, err := api.NewAPI(cfg, h)
+	if err != nil {
+		tb.Fatalf("Unable to create the server: %v", err)
+	}
+
+	return server
+}
+
+// newRequest builds a request to the default site of the sample data.
+func newRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Host = "localhost"
+
+	return req
+}
+
+// newAdminRequest builds a request to the management API, authenticated with the root
+// API key.
+func newAdminRequest(method, target, body string) *http.Request {
+	req := newRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+rootAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req
+}
+
+// firstArticleID returns the ID of the first (published) article of the sample data.
+func firstArticleID(tb testing.TB, server *api.API) string {
+	tb.Helper()
+
+	req := newAdminRequest(http.MethodGet, "/admin/articles", "")
+	var response struct {
+		Articles []struct {
+			ID string `json:"id"`
+		} `json:"articles"`
+	}
+	rr := testutils.ExecuteRequest(req, server.Router)
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil ||
+		len(response.Articles) == 0 {
+		tb.Fatalf("Unable to fetch the articles: %v", err)
+	}
+
+	return response.Articles[0].ID
+}
+
+// TestCreateUser checks the full response to the creation of a user, whose identifier
+// and times are the next ones of the deterministic server.
+func TestCreateUser(t *testing.T) {
+	server := newServer(t)
+	body := `{"name": "Jane Doe", "email": "jane@example.com", "role": "editor"}`
+
+	req := newAdminRequest(http.MethodPost, "/admin/users", body)
+	rr := testutils.ExecuteRequest(req, server.Router)
+
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	expected := `{"user":{` +
+		`"id":"00000000-0000-7001-8000-00000000000f",` +
+		`"site_id":"00000000-0000-7001-8000-000000000001",` +
+		`"name":"Jane Doe","email":"jane@example.com","role":"editor",` +
+		`"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z",` +
+		`"digest_opt_out":false}}`
+	if actual := strings.TrimSpace(rr.Body.String()); actual != expected {
+		t.Errorf("Expected the body %s. Got %s\n", expected, actual)
+	}
+}
+
+// TestHead checks that the HEAD requests get the headers of the GET responses of the
+// content routes without their body, and are not allowed on the other routes.
+func TestHead(t *testing.T) {
+	server := newServer(t)
+	id := firstArticleID(t, server)
+
+	for _, target := range []string{"/articles", "/articles/" + id, "/feed.xml"} {
+		get := testutils.ExecuteRequest(
+			newRequest(http.MethodGet, target, ""),
+			server.Router,
+		)
+		head := testutils.ExecuteRequest(
+			newRequest(http.MethodHead, target, ""),
+			server.Router,
+		)
+
+		testutils.CheckResponseCode(t, http.StatusOK, head.Code)
+		if head.Body.Len() != 0 {
+			t.Errorf("HEAD %s: expected no body. Got %q\n", target, head.Body)
+		}
+		length := strconv.Itoa(get.Body.Len())
+		if actual := head.Header().Get("Content-Length"); actual != length {
+			t.Errorf("HEAD %s: expected the length %s. Got %q\n",
+				target, length, actual)
+		}
+		for _, header := range []string{"ETag", "Last-Modified", "Content-Type"} {
+			if head.Header().Get(header) != get.Header().Get(header) {
+				t.Errorf("HEAD %s: expected the %s %q. Got %q\n", target, header,
+					get.Header().Get(header), head.Header().Get(header))
+			}
+		}
+	}
+
+	// The one-time share links are revoked by the GET requests only
+	req := newAdminRequest(
+		http.MethodPost,
+		"/admin/articles/"+id+"/share",
+		`{"one_time": true}`,
+	)
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	var link struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&link); err != nil {
+		t.Fatalf("Unable to decode the share link: %v", err)
+	}
+
+	for _, request := range []struct {
+		method string
+		code   int
+	}{
+		{http.MethodHead, http.StatusMethodNotAllowed},
+		{http.MethodGet, http.StatusOK},
+		{http.MethodGet, http.StatusNotFound},
+	} {
+		req := newRequest(request.method, "/share/"+link.Token, "")
+		rr := testutils.ExecuteRequest(req, server.Router)
+		testutils.CheckResponseCode(t, request.code, rr.Code)
+	}
+}
+
+// TestNotFound checks that the paths which are not routed are redirected as configured
+// for the site, and are otherwise not found whatever the method of the request.
+func TestNotFound(t *testing.T) {
+	server := newServer(t)
+
+	req := newAdminRequest(
+		http.MethodPost,
+		"/admin/redirects",
+		`{"source": "/old", "target": "https://example.com/new", "status_code": 301}`,
+	)
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	for _, request := range []struct {
+		method string
+		target string
+		code   int
+	}{
+		{http.MethodGet, "/old", http.StatusMovedPermanently},
+		{http.MethodPost, "/old", http.StatusNotFound},
+		{http.MethodGet, "/nonexistent", http.StatusNotFound},
+		{http.MethodPost, "/nonexistent", http.StatusNotFound},
+		{http.MethodOptions, "/nonexistent", http.StatusNotFound},
+		{http.MethodPost, "/tags", http.StatusMethodNotAllowed},
+		{http.MethodOptions, "/tags", http.StatusNoContent},
+	} {
+		req := newRequest(request.method, request.target, "")
+		rr := testutils.ExecuteRequest(req, server.Router)
+		testutils.CheckResponseCode(t, request.code, rr.Code)
+	}
+}
+
+// TestIncludeAuthor checks that the author of an article is included by the ID of its
+// author, even once renamed.
+func TestIncludeAuthor(t *testing.T) {
+	server := newServer(t)
+	id := firstArticleID(t, server)
+	authorID := "00000000-0000-7001-8000-000000000003"
+
+	req := newAdminRequest(
+		http.MethodPut,
+		"/admin/users/"+authorID,
+		`{"name": "John Smith", "email": "john.doe@example.com", "role": "author"}`,
+	)
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	req = newRequest(http.MethodGet, "/articles/"+id+"?include=author", "")
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Included []struct {
+			Type       string `json:"type"`
+			ID         string `json:"id"`
+			Attributes struct {
+				Name string `json:"name"`
+			} `json:"attributes"`
+		} `json:"included"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Unable to decode the article: %v", err)
+	}
+
+	if len(response.Included) != 1 || response.Included[0].ID != authorID ||
+		response.Included[0].Attributes.Name != "John Smith" {
+		t.Errorf("Expected the author %s (John Smith). Got %+v\n",
+			authorID, response.Included)
+	}
+}
+
+// TestUpdateArticle checks that the updated article is returned along with the number
+// of its comments.
+func TestUpdateArticle(t *testing.T) {
+	server := newServer(t)
+	id := firstArticleID(t, server)
+
+	req := newAdminRequest(
+		http.MethodPut,
+		"/admin/articles/"+id,
+		`{"title": "Go Programming", "author": "John Doe", "isPublished": true, `+
+			`"version": 1}`,
+	)
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	var response struct {
+		Article struct {
+			Title        string `json:"title"`
+			Version      int    `json:"version"`
+			CommentCount int    `json:"comment_count"`
+		} `json:"article"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Unable to decode the article: %v", err)
+	}
+
+	article := response.Article
+	if article.Title != "Go Programming" || article.Version != 2 ||
+		article.CommentCount != 4 {
+		t.Errorf("Expected the version 2 of the article with 4 comments. Got %+v\n",
+			article)
+	}
+}
+
+// TestPatchArticle checks that a merge patch only updates the fields it holds, and that
+// it must hold the version of the article it was made from.
+func TestPatchArticle(t *testing.T) {
+	server := newServer(t)
+	id := firstArticleID(t, server)
+
+	type article struct {
+		Title       string `json:"title"`
+		Author      string `json:"author"`
+		IsPublished bool   `json:"isPublished"`
+		Version     int    `json:"version"`
+	}
+	var before, after struct {
+		Article article `json:"article"`
+	}
+
+	rr := testutils.ExecuteRequest(
+		newAdminRequest(http.MethodGet, "/admin/articles/"+id, ""),
+		server.Router,
+	)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+	if err := json.NewDecoder(rr.Body).Decode(&before); err != nil {
+		t.Fatalf("Unable to decode the article: %v", err)
+	}
+
+	req := newAdminRequest(http.MethodPatch, "/admin/articles/"+id, `{"title": "Go"}`)
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusUnprocessableEntity, rr.Code)
+
+	req = newAdminRequest(
+		http.MethodPatch,
+		"/admin/articles/"+id,
+		`{"title": "Go", "version": 1}`,
+	)
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+	if err := json.NewDecoder(rr.Body).Decode(&after); err != nil {
+		t.Fatalf("Unable to decode the article: %v", err)
+	}
+
+	expected := before.Article
+	expected.Title = "Go"
+	expected.Version = 2
+	if after.Article != expected {
+		t.Errorf("Expected the patched article %+v. Got %+v\n", expected, after.Article)
+	}
+}
+
+// TestRevisionDiff checks that every version of the seeded and the created articles can
+// be compared with their first one.
+func TestRevisionDiff(t *testing.T) {
+	server := newServer(t)
+
+	req := newAdminRequest(
+		http.MethodPost,
+		"/admin/articles",
+		`{"title": "Go Generics", "author": "John Doe", "isPublished": false}`,
+	)
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	var created struct {
+		Article struct {
+			ID string `json:"id"`
+		} `json:"article"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("Unable to decode the article: %v", err)
+	}
+
+	for _, id := range []string{firstArticleID(t, server), created.Article.ID} {
+		req := newAdminRequest(
+			http.MethodPut,
+			"/admin/articles/"+id,
+			`{"title": "Renamed", "author": "John Doe", "version": 1}`,
+		)
+		rr := testutils.ExecuteRequest(req, server.Router)
+		testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+		req = newAdminRequest(
+			http.MethodGet,
+			"/admin/articles/"+id+"/diff?from=1&to=2",
+			"",
+		)
+		rr = testutils.ExecuteRequest(req, server.Router)
+		testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+	}
+}
+
+// TestAddComment checks that the Markdown of the comments is stored as is, and that
+// only the HTML rendered from it is sanitized.
+func TestAddComment(t *testing.T) {
+	server := newServer(t)
+	id := firstArticleID(t, server)
+
+	tests := []struct {
+		content  string
+		rendered string
+	}{
+		{"> quoted", "<blockquote>"},
+		{"`x<y` > quote", "<code>x&lt;y</code> &gt; quote"},
+		{"Hi<script>alert(1)</script>", "<p>Hi</p>"},
+	}
+
+	for _, test := range tests {
+		body, _ := json.Marshal(map[string]string{
+			"name":    "Jane Doe",
+			"email":   "jane@example.com",
+			"content": test.content,
+		})
+		req := newAdminRequest(
+			http.MethodPost,
+			"/admin/comments/article/"+id,
+			string(body),
+		)
+		rr := testutils.ExecuteRequest(req, server.Router)
+		testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+		var response struct {
+			Comment struct {
+				Content      string `json:"content"`
+				RenderedHTML string `json:"rendered_html"`
+			} `json:"comment"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Unable to decode the comment: %v", err)
+		}
+
+		if response.Comment.Content != test.content {
+			t.Errorf("Expected the content %q. Got %q\n",
+				test.content, response.Comment.Content)
+		}
+		if !strings.Contains(response.Comment.RenderedHTML, test.rendered) {
+			t.Errorf("Expected the HTML of %q to hold %q. Got %q\n",
+				test.content, test.rendered, response.Comment.RenderedHTML)
+		}
+	}
+}
+
+// TestRestoreFromAnotherSite checks that the backup of a site can be restored into
+// another site while the first one still exists, leaving its content as it was.
+func TestRestoreFromAnotherSite(t *testing.T) {
+	server := newServer(t)
+
+	req := newAdminRequest(http.MethodPost, "/admin/sites", `{"name":"B","slug":"b"}`)
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusCreated, rr.Code)
+
+	req = newAdminRequest(http.MethodPost, "/admin/backup", "")
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+
+	req = newAdminRequest(http.MethodPost, "/admin/s/b/restore", rr.Body.String())
+	req.Header.Set("Content-Type", "application/zip")
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+
+	ids := map[string]bool{}
+	for _, prefix := range []string{"/admin", "/admin/s/b"} {
+		req = newAdminRequest(http.MethodGet, prefix+"/users", "")
+		rr = testutils.ExecuteRequest(req, server.Router)
+		testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+
+		var response struct {
+			Users []struct {
+				ID string `json:"id"`
+			} `json:"users"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Unable to decode the users: %v", err)
+		}
+
+		if len(response.Users) != 3 {
+			t.Errorf("Expected 3 users in %s. Got %d\n", prefix, len(response.Users))
+		}
+		for _, user := range response.Users {
+			ids[user.ID] = true
+		}
+	}
+
+	if len(ids) != 6 {
+		t.Errorf("Expected the restored users to be given new IDs. Got %d IDs\n",
+			len(ids))
+	}
+}
+
+// TestBackupEncrypted checks that the backups and the exports hold the email addresses
+// encrypted, as they are stored, and that the backups are restored with them.
+func TestBackupEncrypted(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	keyring, err := encryption.ParseKeys("test:" + key)
+	if err != nil {
+		t.Fatalf("Unable to parse the key: %v", err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.AdminPort = ""
+	store := repository.NewMemoryStoreWith(
+		ids.NewSequential(1),
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		keyring,
+	)
+	server, err := api.NewAPI(cfg, handlers.NewHandlers(store, handlers.Options{
+		RootAPIKey: rootAPIKey,
+	}))
+	if err != nil {
+		t.Fatalf("Unable to create the server: %v", err)
+	}
+	const email = "somraj.saha@weburz.com"
+
+	req := newAdminRequest(http.MethodGet, "/admin/export?format=ndjson", "")
+	rr := testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+	if strings.Contains(rr.Body.String(), email) {
+		t.Errorf("Expected the export to hold no plain email address\n")
+	}
+
+	req = newAdminRequest(http.MethodPost, "/admin/backup", "")
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+	archive := rr.Body.String()
+
+	data := []byte(archive)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Unable to read the backup archive: %v", err)
+	}
+	for _, file := range zr.File {
+		f, _ := file.Open()
+		content, _ := io.ReadAll(f)
+		if strings.Contains(string(content), email) {
+			t.Errorf("Expected %s to hold no plain email address\n", file.Name)
+		}
+	}
+
+	req = newAdminRequest(http.MethodPost, "/admin/restore", archive)
+	req.Header.Set("Content-Type", "application/zip")
+	rr = testutils.ExecuteRequest(req, server.Router)
+	testutils.CheckResponseCode(t, http.StatusOK, rr.Code)
+
+	req = newAdminRequest(http.MethodGet, "/admin/users", "")
+	rr = testutils.ExecuteRequest(req, server.Router)
+	if !strings.Contains(rr.Body.String(), `"email":"`+email+`"`) {
+		t.Errorf("Expected the restored users to hold %s. Got %s\n", email, rr.Body)
+	}
+}
+
+// TestGeoRestrictInvalid checks that the server does not start when the restrictions
+// of the countries are configured but can not be set up.
+func TestGeoRestrictInvalid(t *testing.T) {
+	tests := map[string]func(cfg *config.Config){
+		"missing database": func(cfg *config.Config) {
+			cfg.GeoIPDatabase = filepath.Join(t.TempDir(), "missing.mmdb")
+		},
+		"rules without database": func(cfg *config.Config) {
+			cfg.GeoRules = "KP=block"
+		},
+	}
+
+	for name, configure := range tests {
+		cfg := config.NewConfig()
+		configure(cfg)
+
+		h, _ := testutils.NewDeterministicHandlers(
+			1,
+			time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			handlers.Options{},
+		)
+		if _, err := api.NewAPI(cfg, h); err == nil {
+			t.Errorf("%s: expected an error. Got none\n", name)
+		}
+	}
+}
+
+// BenchmarkGetPublishedArticles measures the serialization of a full page of articles.
+func BenchmarkGetPublishedArticles(b *testing.B) {
+	server := newServer(b)
+	for i := range 100 {
+		body := fmt.Sprintf(
+			`{"title": "Article %d", "author": "John Doe", "isPublished": true, `+
+				`"content": "<p>The content of the article %d.</p>", "tags": ["go"]}`,
+			i, i,
+		)
+		req := newAdminRequest(http.MethodPost, "/admin/articles", body)
+		rr := testutils.ExecuteRequest(req, server.Router)
+		if rr.Code != http.StatusCreated {
+			b.Fatalf("Unable to create an article: %d %s", rr.Code, rr.Body)
+		}
+	}
+
+	testutils.BenchmarkRequest(b, server.Router, http.StatusOK, func() *http.Request {
+		return newRequest(http.MethodGet, "/articles?page%5Bsize%5D=100", "")
+	})
+}
+
+// BenchmarkCreateArticleValidation measures the decoding and the validation of an
+// article, which is rejected so that the store does not grow between iterations.
+func BenchmarkCreateArticleValidation(b *testing.B) {
+	server := newServer(b)
+	body := `{"title": "Go", "author": "John Doe", "slug": "Not-Lowercase", ` +
+		`"tags": ["go", "programming"], "publish_at": "2026-03-29T09:30"}`
+
+	testutils.BenchmarkRequest(
+		b,
+		server.Router,
+		http.StatusUnprocessableEntity,
+		func() *http.Request {
+			return newAdminRequest(http.MethodPost, "/admin/articles", body)
+		},
+	)
+}
+
+// BenchmarkMiddlewareChain measures the middleware run on every request, in front of
+// one of the cheapest handlers.
+func BenchmarkMiddlewareChain(b *testing.B) {
+	server := newServer(b)
+
+	testutils.BenchmarkRequest(b, server.Router, http.StatusOK, func() *http.Request {
+		return newRequest(http.MethodGet, "/settings", "")
+	})
+}
+
+// fuzzPayload fuzzes the bodies of the requests to the target of the management API,
+// which must only be answered with one of the given status codes, and with a
+// `400 Bad Request` if they are not a single JSON document.
+func fuzzPayload(f *testing.F, target string, seeds []string, statuses ...int) {
+	server := newServer(f)
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		req := newAdminRequest(http.MethodPost, target, body)
+		rr := testutils.ExecuteRequest(req, server.Router)
+
+		if !json.Valid([]byte(body)) {
+			testutils.CheckResponseCode(t, http.StatusBadRequest, rr.Code)
+		} else if !slices.Contains(statuses, rr.Code) {
+			t.Errorf("POST %s %q: unexpected response code %d: %s",
+				target, body, rr.Code, rr.Body)
+		}
+	})
+}
+
+// FuzzCreateArticle fuzzes the articles created with the management API.
+func FuzzCreateArticle(f *testing.F) {
+	fuzzPayload(f, "/admin/articles", []string{
+		`{"title": "Go", "author": "John Doe", "isPublished": true}`,
+		`{"title": "Go", "author": "John Doe", "slug": "Not-Lowercase"}`,
+		`{"title": "Go", "tags": [""], "publish_at": "2026-03-29T09:30"}`,
+		`{"title": "Go", "content": "<p>[youtube dQw4w9WgXcQ]</p>"}`,
+		`{"title": 1}`,
+		`{"titel": "Go"}`,
+		`{"title": "Go"} {}`,
+		`{"title": "Go"`,
+	}, http.StatusCreated, http.StatusBadRequest, http.StatusUnprocessableEntity)
+}
+
+// FuzzCreateUser fuzzes the users created with the management API.
+func FuzzCreateUser(f *testing.F) {
+	fuzzPayload(f, "/admin/users", []string{
+		`{"name": "Jane Doe", "email": "jane@example.com", "role": "editor"}`,
+		`{"name": "Jane", "email": "jane@example.com"}`,
+		`{"name": "Jane Doe", "email": "jane", "role": "owner"}`,
+		`{"name": "Jane Doe", "email": "jane@example.com", "website": "javascript:1"}`,
+		`{"name": null}`,
+		`[]`,
+	}, http.StatusCreated, http.StatusBadRequest, http.StatusUnprocessableEntity)
+}
+
+// FuzzAddComment fuzzes the comments added to an article with the management API.
+func FuzzAddComment(f *testing.F) {
+	// The sample data being the same on every server, so is the ID of its articles
+	id := firstArticleID(f, newServer(f))
+
+	fuzzPayload(f, "/admin/comments/article/"+id, []string{
+		`{"name": "Jane Doe", "email": "jane@example.com", "content": "Great!"}`,
+		`{"name": "Jane", "email": "jane@example.com", "content": "<script></script>"}`,
+		`{"name": "Jane Doe", "email": "jane", "content": ""}`,
+		`{"name": "Jane Doe", "email": "jane@example.com", "content": "@john-doe hi"}`,
+		`{"content": ["Great!"]}`,
+	}, http.StatusCreated, http.StatusBadRequest, http.StatusUnprocessableEntity)
+}
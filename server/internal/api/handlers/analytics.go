@@ -0,0 +1,210 @@
+/*
+Package handlers defines various request handlers, including the first-party analytics
+of a site.
+
+The `AnalyticsHandler` in this file collects the pages viewed by the readers of a site
+and reports the audience of its articles and of each country.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+const (
+	// defaultTopArticles is the number of articles reported when none is requested.
+	defaultTopArticles = 10
+
+	// maxTopArticles is the largest number of articles which can be reported.
+	maxTopArticles = 100
+
+	// maxPeriod is the longest period the audience of the articles can be reported for.
+	maxPeriod = 365 * 24 * time.Hour
+)
+
+// AnalyticsHandler handles HTTP requests related to the analytics of a site.
+type AnalyticsHandler struct {
+	AnalyticsService services.AnalyticsService
+}
+
+// NewAnalyticsHandler creates and initializes a new instance of AnalyticsHandler.
+func NewAnalyticsHandler(analyticsService services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		AnalyticsService: analyticsService,
+	}
+}
+
+/*
+RecordPageView handles HTTP requests to record a page viewed by a reader of the site.
+
+No cookie is set: the reader is identified by an anonymous hash of the truncated IP
+address and the user agent of the request (see `models.PageView`).
+
+Example:
+  - Request: POST /analytics/pageview with a body like `{"article_id":
+    "123e4567-e89b-12d3-a456-426614174000", "path": "/articles/hello",
+    "referrer": "https://news.example.com/"}`
+  - Response: HTTP 204 No Content.
+
+The page views showing the variant of an experiment record the exposure of the reader
+to it, with the keys of the experiment and of the variant (e.g. `{"path": "/",
+"experiment": "homepage-headline", "variant": "b"}`), see `ExperimentHandler`.
+
+Error Handling:
+  - If the request body is malformed, the function responds with a 400 status.
+  - If the page view fails the validation, the function responds with a 422 status.
+  - If the article does not exist or is not published, the function responds with a
+    404 status.
+  - If the experiment or the variant does not exist, the function responds with a 422
+    status.
+*/
+func (ar *AnalyticsHandler) RecordPageView(w http.ResponseWriter, r *http.Request) {
+	var view models.PageView
+	if err := decodeJSON(r, &view); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(view); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	err := ar.AnalyticsService.RecordPageView(
+		r.Context(), view, hostOnly(r.RemoteAddr), r.UserAgent(),
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article not found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrUnknownVariant) {
+		http.Error(w, "Unknown experiment variant", http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to record page view", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+GetTopArticles handles HTTP requests to report the most viewed articles of the site
+over a period.
+
+The period is given by the `period` query parameter as a number of days or hours (e.g.
+`7d` or `24h`, 7 days by default and up to 365 days) and the number of articles by the
+`limit` query parameter (10 by default and up to 100).
+
+Example:
+  - Request: GET /analytics/articles/top?period=30d&limit=5
+  - Response: HTTP 200 OK with a body like `{"articles": [{"article_id": "...",
+    "title": "Hello World", "views": 42, "visitors": 17}]}`
+
+Error Handling:
+  - If the period or the limit is invalid, the function responds with a 400 status.
+*/
+func (ar *AnalyticsHandler) GetTopArticles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	period, err := parsePeriod(query.Get("period"))
+	if err != nil {
+		http.Error(w, "Invalid period", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultTopArticles
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > maxTopArticles {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	articles, err := ar.AnalyticsService.GetTopArticles(r.Context(), period, limit)
+	if err != nil {
+		serverError(w, r, "Failed to fetch top articles", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	response := map[string][]models.TopArticle{"articles": articles}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetCountries handles HTTP requests to report the audience of the site by country over
+a period, the countries being located from the IP addresses of the readers (see
+`middleware.GeoRestrict`).
+
+The period is given by the `period` query parameter, like for `GetTopArticles`.
+
+Example:
+  - Request: GET /analytics/countries?period=30d
+  - Response: HTTP 200 OK with a body like `{"countries": [{"country": "DE",
+    "views": 42, "visitors": 17}]}`
+
+Error Handling:
+  - If the period is invalid, the function responds with a 400 status.
+*/
+func (ar *AnalyticsHandler) GetCountries(w http.ResponseWriter, r *http.Request) {
+	period, err := parsePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(w, "Invalid period", http.StatusBadRequest)
+		return
+	}
+
+	countries, err := ar.AnalyticsService.GetCountries(r.Context(), period)
+	if err != nil {
+		serverError(w, r, "Failed to fetch countries", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	response := map[string][]models.CountryAudience{"countries": countries}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+// parsePeriod parses a period given as a number of days or hours (e.g. `7d` or `24h`),
+// defaulting to 7 days when empty.
+func parsePeriod(value string) (time.Duration, error) {
+	if value == "" {
+		return 7 * 24 * time.Hour, nil
+	}
+
+	unit := time.Hour
+	n, found := strings.CutSuffix(value, "d")
+	if found {
+		unit = 24 * time.Hour
+	} else {
+		n, found = strings.CutSuffix(value, "h")
+	}
+
+	count, err := strconv.Atoi(n)
+	if !found || err != nil || count < 1 || time.Duration(count) > maxPeriod/unit {
+		return 0, errors.New("invalid period")
+	}
+
+	return time.Duration(count) * unit, nil
+}
@@ -0,0 +1,214 @@
+/*
+Package handlers defines various request handlers, including the management of the API
+keys and the usage reports of the sites.
+
+The `APIKeyHandler` in this file handles issuing, listing, rotating and revoking the
+API keys of a site, while the `UsageHandler` reports the usage of a site against its
+quota.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// APIKeyHandler handles HTTP requests related to the API keys of a site.
+type APIKeyHandler struct {
+	APIKeyService services.APIKeyService
+}
+
+// NewAPIKeyHandler creates and initializes a new instance of APIKeyHandler.
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService: apiKeyService,
+	}
+}
+
+/*
+GetAllAPIKeys handles HTTP requests to retrieve the API keys of the site.
+
+The response contains a JSON array of API keys under the key "api_keys". Only the
+prefix of each key is disclosed, never the key itself.
+*/
+func (kr *APIKeyHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := kr.APIKeyService.GetAllAPIKeys(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch API keys", err)
+		return
+	}
+
+	response := map[string][]models.APIKey{
+		"api_keys": keys,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+CreateAPIKey handles HTTP requests to issue a new API key for the site.
+
+Example:
+  - When a POST request is made to `/keys` with a JSON payload (e.g.,
+    `{"name": "Frontend", "role": "editor"}`), this function will issue a new API key
+    and respond with a 201 status along with the API key in the response body. The
+    plain text key is returned under the key "key" by this response only.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails, the function responds with a 422 status.
+  - If the API key can not be issued, the function responds with a 500 status.
+*/
+func (kr *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	validate := validator.New()
+
+	var newKey models.APIKey
+	if err := decodeJSON(r, &newKey); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(newKey); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	key, plain, err := kr.APIKeyService.CreateAPIKey(
+		r.Context(),
+		newKey.Name,
+		newKey.Role,
+		newKey.UserID,
+	)
+	if err != nil {
+		serverError(w, r, "Unable to issue API key", err)
+		return
+	}
+
+	response := map[string]any{
+		"api_key": key,
+		"key":     plain,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+RotateAPIKey handles HTTP requests to replace the key of an API key of the site, e.g.
+when it leaked.
+
+Example:
+  - When a POST request is made to `/keys/{id}/rotate`, this function will generate a
+    new key for the API key and respond with a 200 status along with the API key in
+    the response body. The new plain text key is returned under the key "key" by this
+    response only, the former key being revoked.
+
+Error Handling:
+  - If the API key ID is not a valid UUID, the function responds with a 400 status.
+  - If the API key does not exist, the function responds with a 404 status.
+  - If the API key can not be rotated, the function responds with a 500 status.
+*/
+func (kr *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := params.UUID(r.Context(), "id")
+
+	key, plain, err := kr.APIKeyService.RotateAPIKey(r.Context(), keyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "API key Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to rotate API key", err)
+		return
+	}
+
+	response := map[string]any{
+		"api_key": key,
+		"key":     plain,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+DeleteAPIKey handles HTTP requests to revoke an API key of the site.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the API key ID is not a valid UUID and a 404 status if the API key does not
+exist.
+*/
+func (kr *APIKeyHandler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := params.UUID(r.Context(), "id")
+
+	err := kr.APIKeyService.DeleteAPIKey(r.Context(), keyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "API key Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to revoke API key", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UsageHandler handles HTTP requests related to the usage reports of a site.
+type UsageHandler struct {
+	UsageService services.UsageService
+}
+
+// NewUsageHandler creates and initializes a new instance of UsageHandler.
+func NewUsageHandler(usageService services.UsageService) *UsageHandler {
+	return &UsageHandler{
+		UsageService: usageService,
+	}
+}
+
+/*
+GetUsage handles HTTP requests to retrieve the usage report of the site.
+
+The response contains the number of requests served (and rate-limited) per day, the
+storage used by the content of the site and the quota of the site under the key
+"usage", which is meant to be consumed by the billing system.
+*/
+func (ur *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	usage, err := ur.UsageService.GetUsage(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch usage", err)
+		return
+	}
+
+	response := map[string]models.Usage{
+		"usage": usage,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
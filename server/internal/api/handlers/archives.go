@@ -0,0 +1,122 @@
+/*
+Package handlers defines various request handlers, including the archives of a site.
+
+The `ArchiveHandler` in this file serves the archives of the published articles of a
+site by month, so that blogs can render classic archive pages. The months are those of
+the time zone configured in the settings of the site.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	chi "github.com/go-chi/chi/v5"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+)
+
+// ArchiveHandler handles HTTP requests related to the archives of a site.
+type ArchiveHandler struct {
+	ArticleService services.ArticleService
+}
+
+// NewArchiveHandler creates and initializes a new instance of ArchiveHandler.
+func NewArchiveHandler(articleService services.ArticleService) *ArchiveHandler {
+	return &ArchiveHandler{
+		ArticleService: articleService,
+	}
+}
+
+/*
+GetArchives handles HTTP requests to retrieve the months articles were published in,
+newest first, with the number of articles published in each of them.
+
+Example:
+  - Request: GET /archives
+  - Response: HTTP 200 OK with e.g. `{"archives": [{"year": 2025, "month": 3, "count":
+    4}, ...]}`.
+*/
+func (ar *ArchiveHandler) GetArchives(w http.ResponseWriter, r *http.Request) {
+	months, err := ar.ArticleService.GetArchives(r.Context())
+	if err != nil {
+		serverError(w, r, "Failed to fetch archives", err)
+		return
+	}
+
+	response := map[string][]models.ArchiveMonth{
+		"archives": months,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetArchive handles HTTP requests to retrieve the articles published in a month, newest
+first, paged with the `page[number]` and `page[size]` query parameters.
+
+Example:
+  - Request: GET /archives/2025/03?page[size]=10
+  - Response: HTTP 200 OK with the articles under the key "articles" and the
+    description of the page under the key "meta", along with a `Link` header pointing
+    to the neighbouring pages.
+
+Error Handling:
+  - If the year or the month is invalid, the function responds with a 404 status.
+  - If the page parameters are invalid, the function responds with a 400 status.
+*/
+func (ar *ArchiveHandler) GetArchive(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(chi.URLParam(r, "year"))
+	if err != nil || year < 1 || year > 9999 {
+		http.Error(w, "Archive Not Found", http.StatusNotFound)
+		return
+	}
+
+	month, err := strconv.Atoi(chi.URLParam(r, "month"))
+	if err != nil || month < 1 || month > 12 {
+		http.Error(w, "Archive Not Found", http.StatusNotFound)
+		return
+	}
+
+	page, err := pagination.ParsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid page parameters", http.StatusBadRequest)
+		return
+	}
+
+	articles, total, err := ar.ArticleService.GetArchive(
+		r.Context(),
+		year,
+		time.Month(month),
+		page,
+	)
+	if err != nil {
+		serverError(w, r, "Failed to fetch articles", err)
+		return
+	}
+
+	meta := pagination.NewMeta(page, total)
+	response := map[string]any{
+		"articles": articles,
+		"meta":     meta,
+	}
+
+	w.Header().Set("Link", meta.Links(r.URL))
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
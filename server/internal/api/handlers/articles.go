@@ -11,13 +11,16 @@ It includes the following key functionalities:
 
 Each handler ensures that proper HTTP status codes are returned along with
 appropriate JSON responses. The package also handles error scenarios, such as
-invalid requests, failed UUID generation, and JSON encoding failures.
+invalid requests and JSON encoding failures.
 */
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	chi "github.com/go-chi/chi/v5"
 	validator "github.com/go-playground/validator/v10"
@@ -25,6 +28,10 @@ import (
 
 	"github.com/Weburz/burzcontent/server/internal/api/models"
 	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/markdown"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
 )
 
 /*
@@ -46,7 +53,9 @@ external services, such as models and validators, to handle article data
 and validation.
 */
 type ArticleHandler struct {
-	ArticleServer services.ArticleService
+	ArticleServer  services.ArticleService
+	UserService    services.UserService
+	CommentService services.CommentService
 }
 
 /*
@@ -56,15 +65,24 @@ This function initializes and returns a new `ArticleHandler` struct, which can b
 used to manage article-related operations such as creating, reading, updating,
 and deleting articles.
 
+The user and comment services are used to include the author and the comments of an
+article in its response, on request.
+
 Returns:
   - *ArticleHandler: A new instance of `ArticleHandler`.
 
 Example:
   - Call `NewArticleHandler()` to create a new `ArticleHandler` instance.
 */
-func NewArticleHandler(articleService services.ArticleService) *ArticleHandler {
+func NewArticleHandler(
+	articleService services.ArticleService,
+	userService services.UserService,
+	commentService services.CommentService,
+) *ArticleHandler {
 	return &ArticleHandler{
-		ArticleServer: articleService,
+		ArticleServer:  articleService,
+		UserService:    userService,
+		CommentService: commentService,
 	}
 }
 
@@ -73,12 +91,8 @@ GetAllArticles handles the retrieval of all articles.
 
 This function performs the following actions:
 
- 1. Generates a new article ID using `uuid.NewV7()`. In this implementation, the
-    same ID is used for all the sample articles (this could be a mistake as each
-    article should likely have a unique ID).
- 2. Creates a list of sample articles with predefined titles, authors, and
-    publication statuses.
- 3. Encodes the list of articles into a JSON response and sends it back to the
+ 1. Fetches the articles of the site through the article service.
+ 2. Encodes the list of articles into a JSON response and sends it back to the
     client with a status of `200 OK`.
 
 The response JSON object contains an array of articles, each with the following
@@ -113,12 +127,10 @@ Example Response:
 	  ]
 	}
 
-If the UUID generation or JSON encoding fails, the function returns a
-corresponding error message with an appropriate HTTP status.
+If the JSON encoding fails, the function returns a corresponding error message with
+an appropriate HTTP status.
 
 Possible Errors:
-  - If the UUID generation fails, a `500 Internal Server Error` is returned with
-    the message "Unable to generate the Article ID".
   - If JSON encoding fails, a `500 Internal Server Error` is returned with the
     message "Unable to encode JSON".
 
@@ -127,9 +139,9 @@ Example:
   - Response: HTTP 200 OK with a JSON body containing a list of articles.
 */
 func (ar *ArticleHandler) GetAllArticles(w http.ResponseWriter, r *http.Request) {
-	articles, err := ar.ArticleServer.GetAllArticles()
+	articles, err := ar.ArticleServer.GetAllArticles(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to fetch all articles", http.StatusInternalServerError)
+		serverError(w, r, "Failed to fetch all articles", err)
 		return
 	}
 
@@ -142,7 +154,7 @@ func (ar *ArticleHandler) GetAllArticles(w http.ResponseWriter, r *http.Request)
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -152,16 +164,23 @@ GetArticleByID handles the retrieval of a single article by its ID.
 
 This function performs the following actions:
 
- 1. Retrieves the article ID from the URL path parameter using
-    `chi.URLParam(r, "id")`.
- 2. Attempts to parse the ID into a UUID using `uuid.Parse()`. If the parsing
-    fails, it returns a `404 Not Found` error with the message "Article ID Not
-    Found".
+ 1. Retrieves the article ID parsed from the URL path parameter by the
+    `UUIDParams` middleware, which rejects the malformed IDs with a `400 Bad
+    Request` error.
+ 2. Looks the article up by its ID. If no article has the ID, it returns a `404
+    Not Found` error with the message "Article ID Not Found".
  3. Creates a sample article with predefined title, author, and publication
     status.
  4. Encodes the article into a JSON response and sends it back to the client with
     a status of `200 OK`.
 
+The response carries a `Link` header pointing to the canonical URL of the article,
+which is built with the verified custom domain of the site if it has one.
+
+The resources related to the article are included in the response, under the key
+"included", when they are listed in the `include` query parameter (see
+`writeArticle`).
+
 The response JSON object contains the article with the following structure:
   - `ID`: The unique identifier of the article.
   - `Title`: The title of the article.
@@ -180,40 +199,35 @@ Example Response:
 	}
 
 Possible Errors:
-  - If the article ID is not found or cannot be parsed, a `404 Not Found` error is
-    returned with the message "Article ID Not Found".
+  - If the `include` query parameter lists an unknown relationship, a `400 Bad
+    Request` error is returned with the message "Invalid include parameter".
+  - If the article ID is not a valid UUID, a `400 Bad Request` error is returned.
+  - If the article ID is not found, a `404 Not Found` error is returned with the
+    message "Article ID Not Found".
   - If JSON encoding fails, a `500 Internal Server Error` is returned with the
     message "Unable to encode JSON".
 
 Example:
-  - Request: GET /articles/{id}
-  - Response: HTTP 200 OK with a JSON body containing the requested article.
+  - Request: GET /articles/{id}?include=author,comments
+  - Response: HTTP 200 OK with a JSON body containing the requested article, along
+    with its author and comments.
 */
 func (ar *ArticleHandler) GetArticleByID(w http.ResponseWriter, r *http.Request) {
-	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
-	if err != nil {
-		http.Error(w, "Article ID Not Found", http.StatusNotFound)
+	include, ok := parseInclude(r, articleRelationships)
+	if !ok {
+		http.Error(w, "Invalid include parameter", http.StatusBadRequest)
 		return
 	}
 
-	article, err := ar.ArticleServer.GetArticleByID(articleID)
+	articleID := params.UUID(r.Context(), "id")
+
+	article, err := ar.ArticleServer.GetArticleByID(r.Context(), articleID)
 	if err != nil {
 		http.Error(w, "Article Not Found", http.StatusNotFound)
 		return
 	}
 
-	response := map[string]models.Article{
-		"article": article,
-	}
-
-	w.Header().Set("Content-Type", "application/vnd.api+json")
-	w.WriteHeader(http.StatusOK)
-
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
-		return
-	}
+	ar.writeArticle(w, r, article, include)
 }
 
 /*
@@ -225,9 +239,8 @@ This function performs the following actions:
  2. Validates the new article using the `validator` package. If validation fails,
     it returns a `422 Unprocessable Entity` error with the message "Request validation
     failed".
- 3. Generates a new UUID for the article ID using `uuid.NewV7()`. If the UUID
-    generation fails, it returns a `500 Internal Server Error` with the message
-    "Failed to generate the Article ID".
+ 3. Generates a new ID for the article through the article service (see
+    `services.IDGenerator`).
  4. Creates a new article with the given title and author, and sets the article's
     publication status to `false`.
  5. Encodes the newly created article into a JSON response and returns it to the
@@ -255,8 +268,9 @@ Possible Errors:
     is returned with the message "Invalid request body".
   - If the request validation fails, a `422 Unprocessable Entity` error is returned
     with the message "Request validation failed".
-  - If UUID generation fails, a `500 Internal Server Error` is returned with the
-    message "Failed to generate the Article ID".
+  - If the article is published while the site requires the articles to be reviewed,
+    a `409 Conflict` error is returned with the message "Article not approved for
+    publication".
   - If JSON encoding fails, a `500 Internal Server Error` is returned with the
     message "Unable to encode JSON".
 
@@ -269,13 +283,8 @@ func (ar *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request)
 	validate := validator.New()
 
 	var newArticle models.Article
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&newArticle); err != nil {
-		http.Error(
-			w,
-			"Invalid request body",
-			http.StatusBadRequest,
-		)
+	if err := decodeJSON(r, &newArticle); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -285,12 +294,17 @@ func (ar *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request)
 	}
 
 	article, err := ar.ArticleServer.CreateArticle(
+		r.Context(),
 		newArticle.Title,
 		newArticle.Author,
 		newArticle.IsPublished,
+		newArticle.ArticleBody,
 	)
-	if err != nil {
-		http.Error(w, "Failed to create article", http.StatusInternalServerError)
+	if errors.Is(err, services.ErrNotApproved) {
+		http.Error(w, "Article not approved for publication", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Failed to create article", err)
 		return
 	}
 
@@ -303,7 +317,7 @@ func (ar *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request)
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -319,11 +333,14 @@ This function performs the following actions:
  2. Decodes the incoming request body into the `updatedArticle` object. If
     decoding fails, it returns a `400 Bad Request` error with the message "Invalid
     Request Body".
- 3. Retrieves and parses the article ID from the URL path parameter using
-    `chi.URLParam(r, "id")`. If the ID is invalid or missing, it returns a `404
-    Not Found` error with the message "Article ID Not Found".
+ 3. Retrieves the article ID parsed from the URL path parameter by the
+    `UUIDParams` middleware. If no article has the ID, it returns a `404 Not Found`
+    error with the message "Article ID Not Found".
  4. Updates the article with the parsed ID, and the updated title, author, and
-    publication status.
+    publication status, provided that the article is still at the version given in
+    the request body (which is required). If it was updated in the meantime, it
+    returns a `409 Conflict` response holding the current article and its version,
+    so that the client can merge its changes into them and try again.
  5. Encodes the updated article into a JSON response and sends it back to the
     client with a status of `201 Created`.
 
@@ -332,49 +349,72 @@ The response JSON object contains the updated article with the following structu
   - `Title`: The updated title of the article.
   - `Author`: The updated author of the article.
   - `Published`: The updated publication status of the article.
+  - `Version`: The new version of the article.
 
 Possible Errors:
   - If the request body validation fails, a `422 Unprocessable Entity` error is
     returned with the message "Request body validation failed".
+  - If the request body has no version, a `422 Unprocessable Entity` error is
+    returned with the message "Article version is required".
+  - If the article is not at the given version anymore, a `409 Conflict` error is
+    returned with a JSON body holding the `current_version` and the current
+    `article`.
+  - If the article is published while the site requires the articles to be reviewed
+    and it was not approved as is, a `409 Conflict` error is returned with the
+    message "Article not approved for publication".
   - If the request body is invalid or cannot be decoded, a `400 Bad Request` error
     is returned with the message "Invalid Request Body".
-  - If the article ID is not found or cannot be parsed, a `404 Not Found` error is
-    returned with the message "Article ID Not Found".
+  - If the article ID is not a valid UUID, a `400 Bad Request` error is returned.
+  - If the article ID is not found, a `404 Not Found` error is returned with the
+    message "Article ID Not Found".
   - If JSON encoding fails, a `500 Internal Server Error` is returned with the
     message "Unable to encode JSON".
 
 Example:
   - Request: PUT /articles/{id}
-  - Request Body: JSON object with updated title, author, and publication status.
+  - Request Body: JSON object with the version the update was made from, and the
+    updated title, author, and publication status.
   - Response: HTTP 201 Created with a JSON body containing the updated article.
 */
 func (ar *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 	var updatedArticle models.Article
+	if err := decodeJSON(r, &updatedArticle); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	validate := validator.New()
 	if err := validate.Struct(updatedArticle); err != nil {
 		http.Error(w, "Request body validation failed", http.StatusUnprocessableEntity)
 		return
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&updatedArticle); err != nil {
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+	if updatedArticle.Version == 0 {
+		http.Error(w, "Article version is required", http.StatusUnprocessableEntity)
 		return
 	}
 
-	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
-	if err != nil {
-		http.Error(w, "Article ID Not Found", http.StatusNotFound)
-		return
-	}
+	articleID := params.UUID(r.Context(), "id")
 
 	article, err := ar.ArticleServer.UpdateArticle(
+		r.Context(),
 		articleID,
+		updatedArticle.Version,
 		updatedArticle.Title,
 		updatedArticle.Author,
 		updatedArticle.IsPublished,
+		updatedArticle.ArticleBody,
 	)
-	if err != nil {
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrStale) {
+		ar.writeVersionConflict(w, r, articleID)
+		return
+	} else if errors.Is(err, services.ErrNotApproved) {
+		http.Error(w, "Article not approved for publication", http.StatusConflict)
+		return
+	} else if err != nil {
 		http.Error(w, "Unable to update article", http.StatusBadRequest)
 		return
 	}
@@ -388,7 +428,63 @@ func (ar *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request)
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+PatchArticle handles HTTP requests to partially update an existing article with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`). The patch must hold the `version` of the article it was made from, as
+the body of a `PUT` does, or a `422 Unprocessable Entity` error is returned.
+
+Example:
+  - Request: PATCH /articles/{id}
+  - Request Body: `{"version": 3, "title": "New title"}`
+  - Response: the response of `UpdateArticle` to the patched article.
+*/
+func (ar *ArticleHandler) PatchArticle(w http.ResponseWriter, r *http.Request) {
+	mergePatch(
+		w,
+		r,
+		"Article",
+		ar.ArticleServer.GetArticleByID,
+		ar.UpdateArticle,
+		"version",
+	)
+}
+
+// writeVersionConflict answers an update of the article identified by id made from a
+// stale version of it with a `409 Conflict` response holding the current article and
+// its version.
+func (ar *ArticleHandler) writeVersionConflict(
+	w http.ResponseWriter,
+	r *http.Request,
+	id uuid.UUID,
+) {
+	article, err := ar.ArticleServer.GetArticleByID(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Deleted in the meantime
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch article", err)
+		return
+	}
+
+	response := map[string]any{
+		"error":           "Article was updated in the meantime",
+		"current_version": article.Version,
+		"article":         article,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusConflict)
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -398,32 +494,430 @@ DeleteArticle handles the deletion of an article.
 
 This function performs the following actions:
 
- 1. Retrieves and parses the article ID from the URL path parameter using
-    `chi.URLParam(r, "id")`. If the ID is invalid or missing, it returns a
-    `404 Not Found` error with the message "Article ID Not Found".
- 2. If the article ID is valid, it returns an empty response with a `204 No
-    Content` status indicating the article was successfully deleted.
+ 1. Retrieves the article ID parsed from the URL path parameter by the
+    `UUIDParams` middleware. If no article has the ID, it returns a `404 Not Found`
+    error with the message "Article ID Not Found".
+ 2. If the article ID is valid, it moves the article to the trash and returns an
+    empty response with a `204 No Content` status indicating the article was
+    successfully deleted. The article can be restored from the trash until it is
+    purged (see `RestoreArticle` and `PurgeArticle`).
 
 Possible Errors:
-  - If the article ID is not found or cannot be parsed, a `404 Not Found` error is
-    returned with the message "Article ID Not Found".
+  - If the article ID is not a valid UUID, a `400 Bad Request` error is returned.
+  - If the article ID is not found, a `404 Not Found` error is returned with the
+    message "Article ID Not Found".
 
 Example:
   - Request: DELETE /articles/{id}
   - Response: HTTP 204 No Content, indicating successful deletion.
 */
 func (ar *ArticleHandler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
-	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	articleID := params.UUID(r.Context(), "id")
+
+	err := ar.ArticleServer.DeleteArticle(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to delete article", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+PublishArticles handles the publication of a batch of articles at once, e.g. of
+reviewed drafts.
+
+The request body holds the IDs of the articles under the key "ids" (up to 100). Each
+article is published on its own: the response JSON object holds the outcome for each
+article under the key "results", in the order of the request, along with an HTTP 200
+(OK) status code even if some articles could not be published (e.g. the articles which
+were not approved, if the site requires the articles to be reviewed).
+
+Example:
+  - Request: POST /articles/publish with a body like `{"ids": ["...", "..."]}`
+  - Response: HTTP 200 OK with a body like `{"results": [{"id": "...", "success":
+    true, "article": {...}}, {"id": "...", "success": false, "error": "article not
+    found"}]}`
+
+Possible Errors:
+  - If the request body is invalid, a `400 Bad Request` error is returned.
+  - If the request validation fails (e.g. no or too many IDs), a `422 Unprocessable
+    Entity` error is returned.
+*/
+func (ar *ArticleHandler) PublishArticles(w http.ResponseWriter, r *http.Request) {
+	ar.setPublished(w, r, true)
+}
+
+// UnpublishArticles handles the unpublication of a batch of articles at once, like
+// `PublishArticles` does for their publication.
+func (ar *ArticleHandler) UnpublishArticles(w http.ResponseWriter, r *http.Request) {
+	ar.setPublished(w, r, false)
+}
+
+// setPublished publishes (or unpublishes) the batch of articles of the request.
+func (ar *ArticleHandler) setPublished(
+	w http.ResponseWriter,
+	r *http.Request,
+	isPublished bool,
+) {
+	var batch models.BulkArticles
+	if err := decodeJSON(r, &batch); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(batch); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	results, err := ar.ArticleServer.SetPublished(r.Context(), batch.IDs, isPublished)
 	if err != nil {
-		http.Error(w, "Article ID Not Found", http.StatusNotFound)
+		serverError(w, r, "Failed to update articles", err)
 		return
 	}
 
-	if err = ar.ArticleServer.DeleteArticle(articleID); err != nil {
-		http.Error(w, "Failed to delete article", http.StatusBadRequest)
+	response := map[string][]models.BulkResult{
+		"results": results,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetTrashedArticles handles the retrieval of the articles in the trash.
+
+The response JSON object contains an array of articles under the key "articles", like
+`GetAllArticles` does, each of which holds when it was moved to the trash under the
+key "deleted_at".
+
+Example:
+  - Request: GET /articles/trash
+  - Response: HTTP 200 OK with a JSON body containing the trashed articles.
+*/
+func (ar *ArticleHandler) GetTrashedArticles(w http.ResponseWriter, r *http.Request) {
+	articles, err := ar.ArticleServer.GetTrashedArticles(r.Context())
+	if err != nil {
+		serverError(w, r, "Failed to fetch trash", err)
+		return
+	}
+
+	response := map[string][]models.Article{
+		"articles": articles,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+RestoreArticle handles the restoration of an article from the trash.
+
+Example:
+  - Request: POST /articles/{id}/restore
+  - Response: HTTP 200 OK with a JSON body containing the restored article.
+
+Possible Errors:
+  - If the article ID cannot be parsed or the article is not in the trash, a `404
+    Not Found` error is returned.
+*/
+func (ar *ArticleHandler) RestoreArticle(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	article, err := ar.ArticleServer.RestoreArticle(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found in Trash", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Failed to restore article", err)
 		return
 	}
 
+	response := map[string]models.Article{
+		"article": article,
+	}
+
 	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+PurgeArticle handles the permanent deletion of an article from the trash.
+
+Only the articles in the trash can be purged, the others have to be deleted (i.e.
+moved to the trash) first.
+
+Example:
+  - Request: DELETE /articles/{id}/purge
+  - Response: HTTP 204 No Content, indicating successful deletion.
+
+Possible Errors:
+  - If the article ID cannot be parsed or the article is not in the trash, a `404
+    Not Found` error is returned.
+*/
+func (ar *ArticleHandler) PurgeArticle(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	err := ar.ArticleServer.PurgeArticle(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found in Trash", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Failed to purge article", err)
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
+
+/*
+GetPublishedArticles handles the retrieval of the published articles, on the public
+API.
+
+The response JSON object contains an array of articles under the key "articles", like
+`GetAllArticles` does, but the drafts (unpublished articles) are left out. Its
+`Last-Modified` header holds when the latest of the articles was last updated.
+
+Example:
+  - Request: GET /articles
+  - Response: HTTP 200 OK with a JSON body containing the published articles.
+*/
+func (ar *ArticleHandler) GetPublishedArticles(w http.ResponseWriter, r *http.Request) {
+	articles, err := ar.ArticleServer.GetPublishedArticles(r.Context())
+	if err != nil {
+		serverError(w, r, "Failed to fetch all articles", err)
+		return
+	}
+
+	response := map[string][]models.Article{
+		"articles": articles,
+	}
+
+	setLastModified(w, articles...)
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetPublishedArticleByID handles the retrieval of a single published article by its ID,
+on the public API.
+
+It behaves like `GetArticleByID`, except that the drafts (unpublished articles) are
+reported as not found so that they are never disclosed to anonymous readers.
+*/
+func (ar *ArticleHandler) GetPublishedArticleByID(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	include, ok := parseInclude(r, articleRelationships)
+	if !ok {
+		http.Error(w, "Invalid include parameter", http.StatusBadRequest)
+		return
+	}
+
+	articleID := params.UUID(r.Context(), "id")
+
+	article, err := ar.ArticleServer.GetArticleByID(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && !article.IsPublished {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch article data", err)
+		return
+	}
+
+	ar.writeArticle(w, r, article, include)
+}
+
+/*
+GetPublishedArticleByShortID handles the retrieval of a single published article by its
+short ID (e.g. `GET /a/Xk9Qz2Lp`), on the public API.
+
+It behaves like `GetPublishedArticleByID`, the short ID being an alias of the ID which
+is better suited to sharing the article. The `Link` header of the response still
+points to the canonical URL of the article, made with its ID.
+*/
+func (ar *ArticleHandler) GetPublishedArticleByShortID(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	include, ok := parseInclude(r, articleRelationships)
+	if !ok {
+		http.Error(w, "Invalid include parameter", http.StatusBadRequest)
+		return
+	}
+
+	shortID := chi.URLParam(r, "shortID")
+
+	article, err := ar.ArticleServer.GetArticleByShortID(r.Context(), shortID)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && !article.IsPublished {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch article data", err)
+		return
+	}
+
+	ar.writeArticle(w, r, article, include)
+}
+
+/*
+writeArticle writes the article, along with the resources related to it along the
+given relationships (see `parseInclude`) under the key "included":
+
+  - author: The public profile of the author of the article, if they are a user of
+    the site.
+  - comments: The comments made on the article. The email addresses of the commenters
+    are personal data, which are only disclosed to the editors (and admins).
+  - tags: The tags of the article.
+
+The response carries a `Link` header pointing to the canonical URL of the article, and
+a `Last-Modified` header holding when it was last updated.
+*/
+func (ar *ArticleHandler) writeArticle(
+	w http.ResponseWriter,
+	r *http.Request,
+	article models.Article,
+	include []string,
+) {
+	response := map[string]any{
+		"article": article,
+	}
+
+	if len(include) > 0 {
+		included, err := ar.included(r.Context(), article, include)
+		if err != nil {
+			serverError(w, r, "Unable to fetch related resources", err)
+			return
+		}
+
+		response["included"] = included
+	}
+
+	canonical := siteURL(r, "/articles/"+article.ID.String())
+	w.Header().Set("Link", "<"+canonical+">; rel=\"canonical\"")
+	setLastModified(w, article)
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+// included returns the resources related to the article along the relationships, in
+// order.
+func (ar *ArticleHandler) included(
+	ctx context.Context,
+	article models.Article,
+	relationships []string,
+) ([]models.Resource, error) {
+	included := []models.Resource{}
+
+	for _, relationship := range relationships {
+		switch relationship {
+		case "author":
+			user, err := ar.author(ctx, article)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			included = append(included, models.Resource{
+				Type:       "authors",
+				ID:         user.ID.String(),
+				Attributes: models.AuthorOf(user),
+			})
+		case "comments":
+			comments, err := ar.CommentService.GetCommentsFromArticle(ctx, article.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			principal, ok := auth.FromContext(ctx)
+			disclose := ok && principal.HasRole(auth.RoleEditor)
+			for _, comment := range comments {
+				var attributes any = models.PublicCommentOf(comment)
+				if disclose {
+					attributes = comment
+				}
+
+				included = append(included, models.Resource{
+					Type:       "comments",
+					ID:         comment.ID.String(),
+					Attributes: attributes,
+				})
+			}
+		case "tags":
+			for _, tag := range article.Tags {
+				included = append(included, models.Resource{
+					Type:       "tags",
+					ID:         tag,
+					Attributes: map[string]string{"name": tag},
+				})
+			}
+		}
+	}
+
+	return included, nil
+}
+
+// author returns the user who authored the article, identified by the ID of its author,
+// or by its name for the articles whose author has no ID (e.g. imported ones).
+func (ar *ArticleHandler) author(
+	ctx context.Context,
+	article models.Article,
+) (models.User, error) {
+	if article.AuthorID != nil {
+		return ar.UserService.GetUserByID(ctx, *article.AuthorID)
+	}
+
+	user, err := ar.UserService.GetUserBySlug(ctx, markdown.Slugify(article.Author))
+	if err == nil && user.Name != article.Author {
+		return models.User{}, repository.ErrNotFound
+	}
+
+	return user, err
+}
+
+// setLastModified sets the `Last-Modified` header of the response to when the latest of
+// the articles was last updated, unless there is no article.
+func setLastModified(w http.ResponseWriter, articles ...models.Article) {
+	var latest time.Time
+	for _, article := range articles {
+		if article.UpdatedAt.After(latest) {
+			latest = article.UpdatedAt
+		}
+	}
+
+	if !latest.IsZero() {
+		w.Header().Set("Last-Modified", latest.UTC().Format(http.TimeFormat))
+	}
+}
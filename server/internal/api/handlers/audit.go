@@ -0,0 +1,54 @@
+/*
+Package handlers defines various request handlers, including the audit log of the
+management API.
+
+The `AuditHandler` in this file reports the write requests made to the management API
+of a site.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// AuditHandler handles HTTP requests related to the audit log of a site.
+type AuditHandler struct {
+	AuditService services.AuditService
+}
+
+// NewAuditHandler creates and initializes a new instance of AuditHandler.
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		AuditService: auditService,
+	}
+}
+
+/*
+GetAuditLog handles HTTP requests to retrieve the audit log of the site.
+
+The response contains a JSON array of audit entries, oldest first, under the key
+"audit" along with an HTTP 200 (OK) status code.
+*/
+func (ar *AuditHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := ar.AuditService.GetAuditLog(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch audit log", err)
+		return
+	}
+
+	response := map[string][]models.AuditEntry{
+		"audit": entries,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
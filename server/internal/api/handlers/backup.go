@@ -0,0 +1,252 @@
+/*
+Package handlers defines various request handlers, including the backups of a site.
+
+The `BackupHandler` in this file creates the backup archives of a site and restores a
+site from such an archive. A backup archive is a ZIP file holding:
+  - `manifest.json`: The manifest of the backup (see `models.BackupManifest`), which
+    holds the checksum of every other file of the archive.
+  - `snapshot/users.json`, `snapshot/articles.json` and `snapshot/comments.json`: The
+    resources of the site.
+  - `media/manifest.json`: The list of the media files of the site.
+*/
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// manifestFile is the name of the manifest of a backup archive.
+const manifestFile = "manifest.json"
+
+// BackupHandler handles HTTP requests related to the backups of a site.
+type BackupHandler struct {
+	BackupService services.BackupService
+	MaxBackupSize int64 // The largest backup archive which can be restored, in bytes
+}
+
+// NewBackupHandler creates and initializes a new instance of BackupHandler, restoring
+// the backup archives up to the given size (in bytes).
+func NewBackupHandler(
+	backupService services.BackupService,
+	maxBackupSize int64,
+) *BackupHandler {
+	return &BackupHandler{
+		BackupService: backupService,
+		MaxBackupSize: maxBackupSize,
+	}
+}
+
+// backupFiles returns the files of the archive of the backup, other than its manifest,
+// along with their content.
+func backupFiles(backup *models.Backup) map[string]any {
+	return map[string]any{
+		"snapshot/users.json":    &backup.Users,
+		"snapshot/articles.json": &backup.Articles,
+		"snapshot/comments.json": &backup.Comments,
+		"media/manifest.json":    &backup.Media,
+	}
+}
+
+/*
+Backup handles HTTP requests to create a backup archive of the site.
+
+Example:
+  - Request: POST /admin/backup
+  - Response: HTTP 200 OK with an `application/zip` body.
+*/
+func (br *BackupHandler) Backup(w http.ResponseWriter, r *http.Request) {
+	backup, err := br.BackupService.Backup(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to back up the site", err)
+		return
+	}
+
+	// Build the archive in memory so that a failure can still be reported properly
+	var buf bytes.Buffer
+	if err := WriteBackupArchive(&buf, backup); err != nil {
+		serverError(w, r, "Unable to back up the site", err)
+		return
+	}
+
+	filename := BackupArchiveName(backup.Manifest)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(buf.Bytes())
+}
+
+// WriteBackupArchive writes the backup archive of the backup to w, e.g. to store the
+// backups taken by the scheduled jobs of the server.
+func WriteBackupArchive(w io.Writer, backup models.Backup) error {
+	archive := zip.NewWriter(w)
+	write := func(name string, data []byte) error {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: backup.Manifest.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = file.Write(data)
+		return err
+	}
+
+	backup.Manifest.Checksums = make(map[string]string)
+	for name, content := range backupFiles(&backup) {
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		backup.Manifest.Checksums[name] = hex.EncodeToString(sum[:])
+
+		if err := write(name, data); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(backup.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := write(manifestFile, manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// BackupArchiveName returns the name of the backup archive of the manifest, e.g.
+// "backup-default-20261016T030000Z.zip".
+func BackupArchiveName(manifest models.BackupManifest) string {
+	return fmt.Sprintf(
+		"backup-%s-%s.zip",
+		manifest.SiteSlug,
+		manifest.CreatedAt.Format("20060102T150405Z"),
+	)
+}
+
+/*
+Restore handles HTTP requests to restore the site from a backup archive, replacing its
+current content.
+
+The archive is either the body of the request or the `file` field of a
+`multipart/form-data` body (named with the `.zip` extension), and can not exceed the
+configured size (256 MiB by default). Its progress is reported on
+the events stream of the site (see `EventHandler.Stream`).
+
+Example:
+  - Request: POST /admin/restore
+  - Response: HTTP 200 OK with the manifest of the restored backup.
+
+Error Handling:
+  - If the archive is missing, too large or not a zip archive, the function responds
+    with the details of the problem and a 400, 413 or 415 status (see `readUpload`).
+  - If the archive is malformed or does not match the checksums of its manifest, the
+    function responds with a 400 status.
+  - If the backup can not be restored (e.g. its version is not supported), the
+    function responds with a 422 status.
+*/
+func (br *BackupHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	file, ok := readUpload(w, r, uploadKind{
+		name:       "backup archive",
+		types:      []string{"application/zip"},
+		extensions: []string{".zip"},
+		maxSize:    br.MaxBackupSize,
+	})
+	if !ok {
+		return
+	}
+
+	backup, err := readBackup(bytes.NewReader(file))
+	if err != nil {
+		http.Error(w, "Invalid backup archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = br.BackupService.Restore(r.Context(), backup)
+	if errors.Is(err, services.ErrInvalidBackup) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to restore the site", err)
+		return
+	}
+
+	response := map[string]models.BackupManifest{
+		"manifest": backup.Manifest,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+// readBackup reads a backup archive, verifying the checksum of each of its files.
+func readBackup(r io.Reader) (models.Backup, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return models.Backup{}, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return models.Backup{}, err
+	}
+
+	read := func(name string) ([]byte, error) {
+		file, err := archive.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return io.ReadAll(file)
+	}
+
+	var backup models.Backup
+	manifest, err := read(manifestFile)
+	if err != nil {
+		return models.Backup{}, err
+	} else if err := json.Unmarshal(manifest, &backup.Manifest); err != nil {
+		return models.Backup{}, fmt.Errorf("%s: %w", manifestFile, err)
+	}
+
+	for name, content := range backupFiles(&backup) {
+		data, err := read(name)
+		if err != nil {
+			return models.Backup{}, err
+		}
+
+		sum := sha256.Sum256(data)
+		if backup.Manifest.Checksums[name] != hex.EncodeToString(sum[:]) {
+			return models.Backup{}, fmt.Errorf("%s: checksum mismatch", name)
+		}
+
+		if err := json.Unmarshal(data, content); err != nil {
+			return models.Backup{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return backup, nil
+}
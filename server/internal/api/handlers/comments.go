@@ -6,7 +6,9 @@ including:
   - Retrieving all comments (`GetComments`)
   - Adding a new comment (`AddComment`)
   - Removing an existing comment (`RemoveComment`)
-  - (Planned) Retrieving comments for a specific article (`GetCommentsFromArticle`)
+  - Retrieving comments for a specific article (`GetCommentsFromArticle`)
+  - Retrieving the mentions of the user of the request in the comments (`GetMentions`)
+  - Issuing the challenges the anonymous commenters have to solve (`GetChallenge`)
 
 The `CommentHandler` struct defines methods that handle HTTP requests related to
 comments.
@@ -18,12 +20,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	validator "github.com/go-playground/validator/v10"
 
 	"github.com/Weburz/burzcontent/server/internal/api/models"
 	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/challenge"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
 )
 
 /*
@@ -37,30 +44,40 @@ the CommentService.
 Fields:
 
 	CommentService (services.CommentService): A service for managing comments.
+	Challenges (challenge.Verifier): The verifier of the challenges the anonymous
+	    commenters have to solve.
 */
 type CommentHandler struct {
 	CommentService services.CommentService
+	Challenges     challenge.Verifier
 }
 
 /*
 NewCommentHandler creates and returns a new instance of CommentHandler.
 
 This function initializes a new CommentHandler object, providing it with the given
-CommentService to handle comment-related operations. It serves as a constructor for the
+CommentService to handle comment-related operations and with the verifier of the
+challenges of the anonymous commenters. It serves as a constructor for the
 CommentHandler type.
 
 Parameters:
 
 	commentService (services.CommentService): The service to be used for comment
 	    operations.
+	challenges (challenge.Verifier): The verifier of the challenges of the anonymous
+	    commenters.
 
 Returns:
 
 	*CommentHandler: A pointer to a newly created CommentHandler instance.
 */
-func NewCommentHandler(commentService services.CommentService) *CommentHandler {
+func NewCommentHandler(
+	commentService services.CommentService,
+	challenges challenge.Verifier,
+) *CommentHandler {
 	return &CommentHandler{
 		CommentService: commentService,
+		Challenges:     challenges,
 	}
 }
 
@@ -87,9 +104,9 @@ HTTP Status Codes:
     or encoding the response.
 */
 func (cr *CommentHandler) GetAllComments(w http.ResponseWriter, r *http.Request) {
-	comments, err := cr.CommentService.GetAllComments()
+	comments, err := cr.CommentService.GetAllComments(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Unable to fetch comments", err)
 		return
 	}
 
@@ -100,7 +117,7 @@ func (cr *CommentHandler) GetAllComments(w http.ResponseWriter, r *http.Request)
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -111,9 +128,12 @@ article.
 
 This method interacts with the CommentService to fetch the comments associated with
 an article. If successful, it returns the comments in a JSON format with a "comments"
-key. If any error occurs while retrieving the comments or encoding the response,
-it returns an appropriate error message with an HTTP status code of 500 (Internal
-Server Error).
+key. The email addresses of the commenters are personal data, which are only disclosed
+to the editors (and admins): the other readers are served the public view of the
+comments (see `models.PublicComment`), and the anonymous ones only the comments of the
+published articles. If any error occurs while retrieving the comments or encoding the
+response, it returns an appropriate error message with an HTTP status code of 500
+(Internal Server Error).
 
 Parameters:
 
@@ -126,6 +146,9 @@ Returns:
 
 HTTP Status Codes:
   - 200 (OK): If the comments are successfully retrieved and returned.
+  - 400 (Bad Request): If the article ID is not a valid UUID.
+  - 404 (Not Found): If the article does not exist, or is not published and the
+    reader is anonymous.
   - 500 (Internal Server Error): If there is an error while retrieving comments
     or encoding the response.
 */
@@ -133,17 +156,35 @@ func (cr *CommentHandler) GetCommentsFromArticle(
 	w http.ResponseWriter,
 	r *http.Request,
 ) {
-	comments, err := cr.CommentService.GetCommentsFromArticle()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	articleID := params.UUID(r.Context(), "id")
+
+	comments, err := cr.CommentService.GetCommentsFromArticle(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch comments", err)
 		return
 	}
 
-	response := map[string][]models.Comment{"comments": comments}
+	var response map[string]any
+	if principal, ok := auth.FromContext(r.Context()); ok &&
+		principal.HasRole(auth.RoleEditor) {
+		response = map[string]any{"comments": comments}
+	} else {
+		views := make([]models.PublicComment, len(comments))
+		for i, comment := range comments {
+			views[i] = models.PublicCommentOf(comment)
+		}
+		response = map[string]any{"comments": views}
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -153,9 +194,14 @@ AddCommentToArticle handles HTTP requests to add a new comment to an article.
 
 This method receives a new comment in JSON format, validates it, and then uses
 the CommentService to add the comment. If the comment is successfully added,
-it returns the newly created comment in a JSON format with a "comment" key.
-If any error occurs during the process, it returns an appropriate error message
-with the corresponding HTTP status code.
+it returns the newly created comment in a JSON format with a "comment" key. The
+users mentioned in the comment (e.g. "@jane-doe") and the subscribers of the article
+are notified of it, and the consent of the commenter is recorded along with the IP
+address the request was made from. The anonymous comments, made through the public API
+once the challenge of the commenter is verified (see `middleware.RequireChallenge`),
+can only be made on the published articles and are filtered for spam. If any error
+occurs during the process, it returns an appropriate error message with the
+corresponding HTTP status code.
 
 Parameters:
 
@@ -168,16 +214,22 @@ Returns:
 
 HTTP Status Codes:
   - 201 (Created): If the comment is successfully added.
-  - 400 (Bad Request): If there is an error decoding the request body.
-  - 422 (Unprocessable Entity): If the comment fails validation.
+  - 400 (Bad Request): If the article ID is not a valid UUID or there is an error
+    decoding the request body.
+  - 403 (Forbidden): If the comments of the site are closed.
+  - 404 (Not Found): If the article does not exist (or, for an anonymous comment, is
+    not published).
+  - 422 (Unprocessable Entity): If the comment fails validation, or if the anonymous
+    comment is considered as spam.
   - 500 (Internal Server Error): If there is an error while adding the comment
     or encoding the response.
 */
 func (cr *CommentHandler) AddCommentToArticle(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
 	var newComment models.Comment
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&newComment); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := decodeJSON(r, &newComment); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -190,12 +242,24 @@ func (cr *CommentHandler) AddCommentToArticle(w http.ResponseWriter, r *http.Req
 
 	// Create a comment instance
 	comment, err := cr.CommentService.AddCommentToArticle(
+		r.Context(),
+		articleID,
 		newComment.Name,
 		newComment.Email,
 		newComment.Content,
+		hostOnly(r.RemoteAddr),
 	)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if errors.Is(err, services.ErrCommentsClosed) {
+		http.Error(w, "Comments are closed", http.StatusForbidden)
+		return
+	} else if errors.Is(err, services.ErrSpam) {
+		http.Error(w, "Comment considered as spam", http.StatusUnprocessableEntity)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, err.Error(), err)
 		return
 	}
 
@@ -211,7 +275,7 @@ func (cr *CommentHandler) AddCommentToArticle(w http.ResponseWriter, r *http.Req
 	// Encode the response appropriately before sending it to the client
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encoder JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encoder JSON", err)
 		return
 	}
 }
@@ -235,17 +299,104 @@ Returns:
 
 HTTP Status Codes:
   - 204 (No Content): If the comment is successfully deleted.
+  - 400 (Bad Request): If the comment ID is not a valid UUID.
+  - 404 (Not Found): If the comment does not exist.
   - 500 (Internal Server Error): If there is an error while deleting the comment.
 */
 func (cr *CommentHandler) DeleteCommentFromArticle(
 	w http.ResponseWriter,
 	r *http.Request,
 ) {
-	if err := cr.CommentService.DeleteCommentFromArticle(); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	commentID := params.UUID(r.Context(), "id")
+
+	err := cr.CommentService.DeleteCommentFromArticle(r.Context(), commentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Comment Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, err.Error(), err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/vnd.api+json")
 	w.WriteHeader(http.StatusNoContent)
 }
+
+/*
+GetMentions handles HTTP requests to retrieve the mentions of the user of the request
+in the comments (e.g. "@jane-doe"), newest first.
+
+This method interacts with the CommentService to fetch the mentions of the user owning
+the API key of the request. If successful, it returns the mentions in a JSON format
+with a "mentions" key.
+
+Parameters:
+
+	w (http.ResponseWriter): The HTTP response writer used to send the response.
+	r (*http.Request): The HTTP request containing information about the request.
+
+Returns:
+
+	None: Writes the response directly to the HTTP client.
+
+HTTP Status Codes:
+  - 200 (OK): If the mentions are successfully retrieved and returned.
+  - 403 (Forbidden): If the API key of the request is not owned by any user.
+  - 500 (Internal Server Error): If there is an error while retrieving the mentions
+    or encoding the response.
+*/
+func (cr *CommentHandler) GetMentions(w http.ResponseWriter, r *http.Request) {
+	mentions, err := cr.CommentService.GetMentions(r.Context())
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if err != nil {
+		serverError(w, r, err.Error(), err)
+		return
+	}
+
+	response := map[string][]models.Mention{"mentions": mentions}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, err.Error(), err)
+		return
+	}
+}
+
+/*
+GetChallenge handles HTTP requests to issue a challenge to an anonymous commenter,
+whose solution it presents in the `X-Challenge-Response` header of its comment (see
+the `challenge` package): a proof of work issued by the server, or the provider and
+the site key of the CAPTCHA the frontend renders.
+
+Example:
+  - When a GET request is made to `/comments/challenge`, this function responds with
+    a 200 status and the challenge (e.g. `{"challenge": {"provider": "pow", "token":
+    "...", "difficulty": 16, "expires_at": "..."}}`), which is never cached.
+
+Error Handling:
+  - If the challenge can not be issued, the function responds with a 500 status.
+*/
+func (cr *CommentHandler) GetChallenge(w http.ResponseWriter, r *http.Request) {
+	issued, err := cr.Challenges.Issue()
+	if err != nil {
+		serverError(w, r, "Unable to issue challenge", err)
+		return
+	}
+
+	response := map[string]challenge.Challenge{
+		"challenge": issued,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
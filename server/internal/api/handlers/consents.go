@@ -0,0 +1,115 @@
+/*
+Package handlers defines various request handlers, including the consents of the
+visitors to the processing of their personal data.
+
+The `ConsentHandler` in this file looks up the consents given with an email address
+and withdraws them, on request of the visitor (GDPR article 7).
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// ConsentHandler handles HTTP requests related to the consents of the visitors of a
+// site.
+type ConsentHandler struct {
+	ConsentService services.ConsentService
+}
+
+// NewConsentHandler creates and initializes a new instance of ConsentHandler.
+func NewConsentHandler(consentService services.ConsentService) *ConsentHandler {
+	return &ConsentHandler{
+		ConsentService: consentService,
+	}
+}
+
+/*
+GetConsents handles HTTP requests to retrieve the consents given with an email address
+to the site, withdrawn or not.
+
+Example:
+  - Request: GET /consents?email=jane@example.com
+  - Response: HTTP 200 OK with the consents under the key "consents", e.g.
+    `{"consents": [{"purpose": "comments", "policy_version": "2025-06", ...}]}`.
+
+Error Handling:
+  - If the email address is missing or invalid, the function responds with a 422
+    status.
+*/
+func (ch *ConsentHandler) GetConsents(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if err := validator.New().Var(email, "required,email"); err != nil {
+		http.Error(w, "Invalid email address", http.StatusUnprocessableEntity)
+		return
+	}
+
+	consents, err := ch.ConsentService.GetConsents(r.Context(), email)
+	if err != nil {
+		serverError(w, r, "Unable to fetch consents", err)
+		return
+	}
+
+	response := map[string][]models.Consent{
+		"consents": consents,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+WithdrawConsents handles HTTP requests to withdraw the consents given with an email
+address to the site, on request of the visitor, which anonymizes the comments made
+with the email address.
+
+Example:
+  - Request: POST /consents/withdraw with a body like `{"email": "jane@example.com"}`
+  - Response: HTTP 200 OK with the number of consents withdrawn and of comments
+    anonymized under the key "withdrawal", e.g. `{"withdrawal": {"consents": 2,
+    "comments": 2}}`.
+
+Error Handling:
+  - If the request body is malformed, the function responds with a 400 status.
+  - If the email address is missing or invalid, the function responds with a 422
+    status.
+*/
+func (ch *ConsentHandler) WithdrawConsents(w http.ResponseWriter, r *http.Request) {
+	var req models.ConsentRequest
+	if err := decodeJSON(r, &req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		http.Error(w, "Invalid email address", http.StatusUnprocessableEntity)
+		return
+	}
+
+	withdrawal, err := ch.ConsentService.WithdrawConsents(r.Context(), req.Email)
+	if err != nil {
+		serverError(w, r, "Unable to withdraw consents", err)
+		return
+	}
+
+	response := map[string]models.ConsentWithdrawal{
+		"withdrawal": withdrawal,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Package handlers defines various request handlers, including the contact form of a
+site.
+
+The `ContactHandler` in this file forwards the messages sent by the visitors of a site
+through its contact form to the administrators of the site.
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// ContactHandler handles HTTP requests related to the contact form of a site.
+type ContactHandler struct {
+	ContactService services.ContactService
+}
+
+// NewContactHandler creates and initializes a new instance of ContactHandler.
+func NewContactHandler(contactService services.ContactService) *ContactHandler {
+	return &ContactHandler{
+		ContactService: contactService,
+	}
+}
+
+/*
+SendContactMessage handles HTTP requests to send a message through the contact form of
+the site.
+
+The message is forwarded by email to the administrators of the site, in the
+background. The messages whose honeypot field (`website`) is filled in are silently
+dropped, so that the spam bots can not tell them apart from the accepted ones.
+
+Example:
+  - Request: POST /contact with a body like `{"name": "Jane Doe", "email":
+    "jane@example.com", "message": "Hello there!"}`
+  - Response: HTTP 202 Accepted.
+
+Error Handling:
+  - If the request body is malformed, the function responds with a 400 status.
+  - If the message fails the validation or holds too many links, the function
+    responds with a 422 status.
+  - If the site has no administrator to forward the message to, the function responds
+    with a 503 status.
+*/
+func (cr *ContactHandler) SendContactMessage(w http.ResponseWriter, r *http.Request) {
+	var msg models.ContactMessage
+	if err := decodeJSON(r, &msg); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(msg); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	err := cr.ContactService.SendContactMessage(r.Context(), msg)
+	switch {
+	case errors.Is(err, services.ErrSpam) && msg.Website != "":
+		// Pretend the message was accepted
+	case errors.Is(err, services.ErrSpam):
+		http.Error(w, "Message considered as spam", http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, services.ErrNoRecipient):
+		http.Error(w, "Contact form unavailable", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		serverError(w, r, "Unable to send message", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
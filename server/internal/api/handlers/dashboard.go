@@ -0,0 +1,55 @@
+/*
+Package handlers defines various request handlers, including the dashboard of a site.
+
+The `DashboardHandler` in this file serves the summary of a site shown on the home
+screen of the administration interface, in a single request.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// DashboardHandler handles HTTP requests related to the dashboard of a site.
+type DashboardHandler struct {
+	DashboardService services.DashboardService
+}
+
+// NewDashboardHandler creates and initializes a new instance of DashboardHandler.
+func NewDashboardHandler(dashboardService services.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		DashboardService: dashboardService,
+	}
+}
+
+/*
+GetDashboard handles HTTP requests to summarize the site: the number of its articles
+(published and drafts), users and comments (in total, there being no moderation
+queue), its latest write requests and its most viewed articles over the last 7 days.
+
+Example:
+  - Request: GET /dashboard
+  - Response: HTTP 200 OK with a body like `{"dashboard": {"counts":
+    {"published_articles": 3, "draft_articles": 1, "users": 2, "total_comments": 5},
+    "recent_activity": [...], "top_articles": [...]}}`
+*/
+func (dr *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, err := dr.DashboardService.GetDashboard(r.Context())
+	if err != nil {
+		serverError(w, r, "Failed to fetch dashboard", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	response := map[string]models.Dashboard{"dashboard": dashboard}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
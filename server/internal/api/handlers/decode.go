@@ -0,0 +1,123 @@
+/*
+Package handlers defines various request handlers, including the decoding of the JSON
+bodies of their requests.
+*/
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Weburz/burzcontent/server/internal/localtime"
+	"github.com/Weburz/burzcontent/server/internal/naming"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+/*
+decodeJSON strictly decodes the JSON body of the request into v.
+
+Unlike a plain `json.Decoder`, it rejects the fields which v does not hold (so that a
+typo such as "titel" is reported rather than silently ignored) and the bodies holding
+more than one JSON document. The fields named in the naming convention the client asked
+for (e.g. "createdAt" in camelCase, see `middleware.JSONNaming`) are matched to the
+fields of v beforehand (see `naming.Match`), and the times given without a UTC offset
+(e.g. "2026-03-29T09:30") are taken in the time zone of the site (see
+`localtime.Localize`). The errors it returns describe the problem, naming the
+offending field if any, and are meant to be sent back to the client, e.g.:
+  - unknown field "titel"
+  - field "isPublished" must be a bool, not a string
+  - malformed JSON at offset 17
+*/
+func decodeJSON(r *http.Request, v any) error {
+	var value any
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return describeJSONError(err)
+	}
+	if err := decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		return errors.New("the body must hold a single JSON document")
+	}
+
+	// Match the fields named in the convention of the client to the fields of v
+	t := reflect.TypeOf(v)
+	if naming.FromContext(r.Context()) != naming.Default {
+		value = naming.Match(value, t)
+	}
+	value = localtime.Localize(value, t, tenant.Location(r.Context()))
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	decoder = json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return describeJSONError(err)
+	}
+
+	return nil
+}
+
+// describeJSONError returns the description of the error decoding a JSON document.
+func describeJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var sizeErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Errorf(
+			"field %q must be %s, not a %s",
+			typeErr.Field,
+			jsonType(typeErr.Type),
+			typeErr.Value,
+		)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("the body must be %s", jsonType(typeErr.Type))
+	case errors.As(err, &sizeErr):
+		return fmt.Errorf("the body exceeds %d bytes", sizeErr.Limit)
+	case errors.Is(err, io.EOF):
+		return errors.New("the body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("malformed JSON: unexpected end of the body")
+	}
+
+	// The decoder reports the unknown fields as `json: unknown field "name"`
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Errorf("unknown field %s", field)
+	}
+
+	return err
+}
+
+// jsonType returns the name of the JSON type the Go type is decoded from, e.g. "an
+// object".
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a bool"
+	case reflect.String:
+		return "a string"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/naming"
+)
+
+// FuzzDecodeJSON decodes arbitrary bodies into the article, user and comment payloads,
+// in every naming convention, which must never panic and must reject every body which
+// is not a single JSON document.
+func FuzzDecodeJSON(f *testing.F) {
+	seeds := []string{
+		`{"title": "Go", "author": "John Doe", "isPublished": true}`,
+		`{"title": "Go", "tags": ["go", "programming"], "publish_at": "2026-03-29T09:30"}`,
+		`{"name": "Jane Doe", "email": "jane@example.com", "role": "editor"}`,
+		`{"name": "Jane", "email": "jane@example.com", "content": "Hi @john-doe"}`,
+		`{"isPublished": "yes"}`,
+		`{"titel": "Go"}`,
+		`{"createdAt": "2026-03-29T09:30:00Z", "profile": {"socialLinks": {"x": 1}}}`,
+		`[{"title": "Go"}]`,
+		`{} {}`,
+		`{"title": "Go"`,
+		`null`,
+		``,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	conventions := []naming.Convention{naming.Default, naming.CamelCase, naming.SnakeCase}
+	targets := []func() any{
+		func() any { return &models.Article{} },
+		func() any { return &models.User{} },
+		func() any { return &models.Comment{} },
+	}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		for _, convention := range conventions {
+			for _, target := range targets {
+				r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+				r = r.WithContext(naming.NewContext(r.Context(), convention))
+
+				v := target()
+				err := decodeJSON(r, v)
+				if err == nil && !json.Valid([]byte(body)) {
+					t.Errorf("decodeJSON(%q) into %T accepted an invalid body", body, v)
+				} else if err != nil && err.Error() == "" {
+					t.Errorf("decodeJSON(%q) into %T failed without a description",
+						body, v)
+				}
+			}
+		}
+	})
+}
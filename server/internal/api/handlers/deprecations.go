@@ -0,0 +1,64 @@
+/*
+Package handlers defines various request handlers, including the usage report of the
+deprecated routes and fields of the APIs.
+
+The `DeprecationHandler` in this file reports which consumers still use the deprecated
+routes and fields, so that they can be retired once nobody depends on them anymore.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/deprecation"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// DeprecationHandler handles HTTP requests related to the deprecated routes and fields
+// of the APIs.
+type DeprecationHandler struct {
+	Registry *deprecation.Registry
+}
+
+// NewDeprecationHandler creates and initializes a new instance of DeprecationHandler.
+func NewDeprecationHandler(registry *deprecation.Registry) *DeprecationHandler {
+	return &DeprecationHandler{
+		Registry: registry,
+	}
+}
+
+/*
+GetDeprecations handles HTTP requests to retrieve the deprecated routes and fields of
+the APIs, along with their usage by each consumer (API key) of the site since the
+server started. The root API key gets their usage on every site.
+
+Example:
+  - Request: GET /deprecations
+  - Response: HTTP 200 OK with a JSON body containing the deprecated routes and fields
+    under the key "deprecations", e.g. `{"deprecations": [{"name": "PUT
+    /articles/new", "since": "...", "successor": "POST /articles", "usage":
+    [{"site_id": "...", "consumer": "<API key ID>", "count": 42, "last_used_at":
+    "..."}]}]}`.
+*/
+func (dh *DeprecationHandler) GetDeprecations(w http.ResponseWriter, r *http.Request) {
+	var reports []deprecation.Report
+	if principal, ok := auth.FromContext(r.Context()); ok && principal.Root {
+		reports = dh.Registry.Report()
+	} else {
+		reports = dh.Registry.Report(tenant.SiteID(r.Context()))
+	}
+
+	response := map[string][]deprecation.Report{
+		"deprecations": reports,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
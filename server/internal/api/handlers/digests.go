@@ -0,0 +1,98 @@
+/*
+Package handlers defines various request handlers, including the digests of the
+comments emailed to the authors.
+
+The `DigestHandler` in this file lets the users opt out of (or back into) the daily
+digest of the comments on their articles, which is sent by a scheduled job of the
+server. The setting is personal: it requires an API key owned by a user.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// DigestHandler handles HTTP requests related to the digests of the comments emailed
+// to the authors.
+type DigestHandler struct {
+	DigestService services.DigestService
+}
+
+// NewDigestHandler creates and initializes a new instance of DigestHandler.
+func NewDigestHandler(digestService services.DigestService) *DigestHandler {
+	return &DigestHandler{
+		DigestService: digestService,
+	}
+}
+
+/*
+OptOut handles HTTP requests to opt the user of the request out of the daily digest of
+the comments on their articles.
+
+Example:
+  - Request: POST /digest/opt-out
+  - Response: HTTP 200 OK with a JSON body containing the user under the key "user",
+    whose `digest_opt_out` is true.
+
+Error Handling:
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the user no longer exists, the function responds with a 404 status.
+*/
+func (dh *DigestHandler) OptOut(w http.ResponseWriter, r *http.Request) {
+	dh.setOptOut(w, r, true)
+}
+
+/*
+OptIn handles HTTP requests to opt the user of the request back into the daily digest
+of the comments on their articles.
+
+Example:
+  - Request: POST /digest/opt-in
+  - Response: HTTP 200 OK with a JSON body containing the user under the key "user",
+    whose `digest_opt_out` is false.
+
+Error Handling:
+  - The function responds like `OptOut` does.
+*/
+func (dh *DigestHandler) OptIn(w http.ResponseWriter, r *http.Request) {
+	dh.setOptOut(w, r, false)
+}
+
+// setOptOut handles a request opting the user of the request out of the digest, or
+// back into it.
+func (dh *DigestHandler) setOptOut(
+	w http.ResponseWriter,
+	r *http.Request,
+	optOut bool,
+) {
+	user, err := dh.DigestService.SetDigestOptOut(r.Context(), optOut)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to update user", err)
+		return
+	}
+
+	response := map[string]models.User{
+		"user": user,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Package handlers defines various request handlers, including the encryption at rest
+of the personal data of the sites.
+
+The `EncryptionHandler` in this file encrypts the personal data of a site again with
+the primary encryption key, once the keys are rotated.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// EncryptionHandler handles HTTP requests related to the encryption of the personal
+// data of a site.
+type EncryptionHandler struct {
+	EncryptionService services.EncryptionService
+}
+
+// NewEncryptionHandler creates and initializes a new instance of EncryptionHandler.
+func NewEncryptionHandler(
+	encryptionService services.EncryptionService,
+) *EncryptionHandler {
+	return &EncryptionHandler{
+		EncryptionService: encryptionService,
+	}
+}
+
+/*
+RotateKeys handles HTTP requests to encrypt the personal data of the site again with
+the primary encryption key, once a new primary key is configured (see
+`Config.NewKeyring`).
+
+Example:
+  - Request: POST /encryption/rotate
+  - Response: HTTP 200 OK with the number of resources encrypted again under the key
+    "rotation", e.g. `{"rotation": {"users": 3, "comments": 12, "consents": 12}}`.
+
+Error Handling:
+  - If the personal data can not be encrypted, the function responds with a 500
+    status.
+*/
+func (eh *EncryptionHandler) RotateKeys(w http.ResponseWriter, r *http.Request) {
+	rotation, err := eh.EncryptionService.RotateKeys(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to rotate encryption keys", err)
+		return
+	}
+
+	response := map[string]models.KeyRotation{
+		"rotation": rotation,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
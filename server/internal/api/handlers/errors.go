@@ -0,0 +1,56 @@
+/*
+Package handlers defines various request handlers, including the handling of their
+unexpected errors.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/errreport"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+/*
+serverError answers the request with the message and a 500 (Internal Server Error)
+status, after logging (with the logger of the request) and reporting the unexpected
+error which caused it (see the `errreport` package).
+
+Every handler answers its unexpected errors through this function, so that none of
+them goes unnoticed.
+*/
+func serverError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	logger.FromContext(r.Context()).ErrorContext(
+		r.Context(),
+		message,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"error", err,
+	)
+	errreport.Report(r, err)
+
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// writeProblem answers the request with the details of the problem (RFC 9457), whose
+// title is the text of the status code, along with the unique identifier of the
+// request.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problem := models.Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: chimiddleware.GetReqID(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(problem)
+}
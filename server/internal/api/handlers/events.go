@@ -0,0 +1,93 @@
+/*
+Package handlers defines various request handlers, including the events stream of a
+site.
+
+The `EventHandler` in this file streams the events of the site (tenant) a request is
+resolved to, such as the progress of a restore, as Server-Sent Events.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/events"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// heartbeatInterval is the interval between two heartbeats of an events stream, which
+// keep the idle connections open through proxies.
+const heartbeatInterval = 15 * time.Second
+
+// EventHandler handles HTTP requests related to the events of a site.
+type EventHandler struct {
+	Broker *events.Broker
+}
+
+// NewEventHandler creates and initializes a new instance of EventHandler.
+func NewEventHandler(broker *events.Broker) *EventHandler {
+	return &EventHandler{
+		Broker: broker,
+	}
+}
+
+/*
+Stream handles HTTP requests to stream the events of the site as Server-Sent Events,
+until the client disconnects.
+
+Each event is sent with its type as the event name and its JSON encoding as the data.
+Only the events published after the stream is opened are sent. The events include the
+progress of the restores (`restore.*`), the publication and the expiry of the articles
+(`article.published` and `article.expired`), the users starting and stopping to edit
+the articles (`article.locked` and `article.unlocked`), the comments approved or
+rejected as spam (`comment.approved` and `comment.rejected`), the webmentions awaiting
+moderation (`webmention.received`), the failed deliveries of the outbound webhooks
+(`webhook.failed`) and the changes of the settings of the site (`settings.updated`).
+
+Example:
+  - Request: GET /admin/events
+  - Response: HTTP 200 OK with a `text/event-stream` body, e.g. `event:
+    restore.progress` and `data: {"type": "restore.progress", "site_id": "...",
+    "data": {...}, "at": "..."}` lines for each event.
+*/
+func (er *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// The stream outlives the write timeout of the server
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	stream, unsubscribe := er.Broker.Subscribe(tenant.SiteID(r.Context()))
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+		case event := <-stream:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Package handlers defines various request handlers, including the A/B experiments of a
+site.
+
+The `ExperimentHandler` in this file handles the management of the experiments of a
+site (e.g. testing the headlines of an article), the assignment of its visitors to
+their variants and the report of their results. The frontends record the variants they
+show through the analytics collector (see `AnalyticsHandler.RecordPageView`).
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// maxVisitorLength is the maximum length of the identifier of a visitor.
+const maxVisitorLength = 256
+
+// ExperimentHandler handles HTTP requests related to the A/B experiments of a site.
+type ExperimentHandler struct {
+	ExperimentService services.ExperimentService
+}
+
+// NewExperimentHandler creates and initializes a new instance of ExperimentHandler.
+func NewExperimentHandler(
+	experimentService services.ExperimentService,
+) *ExperimentHandler {
+	return &ExperimentHandler{
+		ExperimentService: experimentService,
+	}
+}
+
+/*
+GetAssignments handles HTTP requests to bucket a visitor into the active experiments of
+the site.
+
+The visitor is identified by the `visitor` query parameter, an opaque identifier kept
+by the frontend (e.g. in a first-party cookie), and is always bucketed into the same
+variants. The response is not cached, so that the experiments started or stopped are
+picked up at once.
+
+Example:
+  - Request: GET /experiments/assignments?visitor=3f9c2a
+  - Response: HTTP 200 OK with a body like `{"assignments": [{"experiment":
+    "homepage-headline", "article_id": "...", "variant": "b", "value": "Hello,
+    World!"}]}`
+
+Error Handling:
+  - If the visitor is missing or too long, the function responds with a 400 status.
+*/
+func (eh *ExperimentHandler) GetAssignments(w http.ResponseWriter, r *http.Request) {
+	visitor := r.URL.Query().Get("visitor")
+	if visitor == "" || len(visitor) > maxVisitorLength {
+		http.Error(w, "Invalid visitor", http.StatusBadRequest)
+		return
+	}
+
+	assignments, err := eh.ExperimentService.GetAssignments(r.Context(), visitor)
+	if err != nil {
+		serverError(w, r, "Unable to assign experiments", err)
+		return
+	}
+
+	response := map[string][]models.Assignment{
+		"assignments": assignments,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetAllExperiments handles HTTP requests to retrieve the list of experiments of the
+site.
+
+The response contains a JSON array of experiments under the key "experiments" along
+with an HTTP 200 (OK) status code.
+*/
+func (eh *ExperimentHandler) GetAllExperiments(w http.ResponseWriter, r *http.Request) {
+	experiments, err := eh.ExperimentService.GetAllExperiments(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch experiments", err)
+		return
+	}
+
+	response := map[string][]models.Experiment{
+		"experiments": experiments,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetExperimentByID handles HTTP requests to retrieve an experiment by its ID.
+
+Error Handling:
+  - If the experiment ID is not a valid UUID, the function responds with a 400 status.
+  - If the experiment does not exist, the function responds with a 404 status.
+*/
+func (eh *ExperimentHandler) GetExperimentByID(w http.ResponseWriter, r *http.Request) {
+	experimentID := params.UUID(r.Context(), "id")
+
+	experiment, err := eh.ExperimentService.GetExperimentByID(
+		r.Context(),
+		experimentID,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Experiment Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch experiment data", err)
+		return
+	}
+
+	writeExperiment(w, r, http.StatusOK, experiment)
+}
+
+/*
+CreateExperiment handles HTTP requests to create a new experiment.
+
+Example:
+  - When a POST request is made to `/experiments` with a JSON payload (e.g.,
+    `{"key": "homepage-headline", "name": "Homepage headline", "article_id": "...",
+    "variants": [{"key": "a", "value": "Hello World"}, {"key": "b", "value":
+    "Hello, World!"}], "active": true}`), this function will create the experiment and
+    respond with a 201 status along with the experiment data in the response body.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails, the function responds with a 422 status.
+  - If the key is already taken, the function responds with a 409 status.
+*/
+func (eh *ExperimentHandler) CreateExperiment(w http.ResponseWriter, r *http.Request) {
+	var newExperiment models.Experiment
+	if err := decodeJSON(r, &newExperiment); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(newExperiment); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	experiment, err := eh.ExperimentService.CreateExperiment(
+		r.Context(),
+		newExperiment,
+	)
+	if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Experiment key already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process experiment data", err)
+		return
+	}
+
+	writeExperiment(w, r, http.StatusCreated, experiment)
+}
+
+/*
+UpdateExperiment handles HTTP requests to update the key, name, article, variants and
+state of an existing experiment, e.g. to stop it with `"active": false`.
+
+Changing the variants or their weights reshuffles the visitors between the variants.
+
+Error Handling:
+  - If the experiment ID is not a valid UUID or the request body is invalid, the
+    function responds with a 400 status.
+  - If the experiment does not exist, the function responds with a 404 status.
+  - If the key is already taken by another experiment, the function responds with a
+    409 status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (eh *ExperimentHandler) UpdateExperiment(w http.ResponseWriter, r *http.Request) {
+	experimentID := params.UUID(r.Context(), "id")
+
+	var updatedExperiment models.Experiment
+	if err := decodeJSON(r, &updatedExperiment); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(updatedExperiment); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	experiment, err := eh.ExperimentService.UpdateExperiment(
+		r.Context(),
+		experimentID,
+		updatedExperiment,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Experiment Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Experiment key already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process experiment data", err)
+		return
+	}
+
+	writeExperiment(w, r, http.StatusOK, experiment)
+}
+
+/*
+PatchExperiment handles HTTP requests to partially update an existing experiment with
+a JSON merge patch (RFC 7396), leaving the fields the patch does not hold unchanged
+(see `mergePatch`).
+
+Example:
+  - Request: PATCH /experiments/{id}
+  - Request Body: `{"name": "Headline"}`
+  - Response: the response of `UpdateExperiment` to the patched experiment.
+*/
+func (eh *ExperimentHandler) PatchExperiment(w http.ResponseWriter, r *http.Request) {
+	mergePatch(
+		w,
+		r,
+		"Experiment",
+		eh.ExperimentService.GetExperimentByID,
+		eh.UpdateExperiment,
+	)
+}
+
+/*
+DeleteExperiment handles HTTP requests to delete an experiment by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the experiment ID is not a valid UUID and a 404 status if the experiment
+does not exist.
+*/
+func (eh *ExperimentHandler) DeleteExperiment(w http.ResponseWriter, r *http.Request) {
+	experimentID := params.UUID(r.Context(), "id")
+
+	err := eh.ExperimentService.DeleteExperiment(r.Context(), experimentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Experiment Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete experiment", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+GetResults handles HTTP requests to report the audience of the variants of an
+experiment, from the exposures recorded through the analytics collector since the
+experiment was created.
+
+Example:
+  - Request: GET /experiments/{id}/results
+  - Response: HTTP 200 OK with a body like `{"results": [{"variant": "a",
+    "exposures": 120, "visitors": 87}, {"variant": "b", "exposures": 131,
+    "visitors": 90}]}`
+
+Error Handling:
+  - If the experiment ID is not a valid UUID, the function responds with a 400 status.
+  - If the experiment does not exist, the function responds with a 404 status.
+*/
+func (eh *ExperimentHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	experimentID := params.UUID(r.Context(), "id")
+
+	results, err := eh.ExperimentService.GetResults(r.Context(), experimentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Experiment Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch experiment results", err)
+		return
+	}
+
+	response := map[string][]models.VariantResult{
+		"results": results,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+// writeExperiment writes the JSON encoding of the experiment under the key
+// "experiment" with the given status code.
+func writeExperiment(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	experiment models.Experiment,
+) {
+	response := map[string]models.Experiment{
+		"experiment": experiment,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
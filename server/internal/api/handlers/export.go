@@ -0,0 +1,185 @@
+/*
+Package handlers defines various request handlers, including the content export of a
+site.
+
+The `ExportHandler` in this file streams a complete dump of the content of a site, for
+backup and migration purposes.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/errreport"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+// ExportHandler handles HTTP requests related to the content export of a site.
+type ExportHandler struct {
+	ExportService services.ExportService
+}
+
+// NewExportHandler creates and initializes a new instance of ExportHandler.
+func NewExportHandler(exportService services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		ExportService: exportService,
+	}
+}
+
+/*
+Export handles HTTP requests to export the content (users, articles and comments) of
+the site.
+
+The records are streamed as they are read, either as a single JSON document (the
+default) or as newline-delimited JSON (NDJSON) when the `format` query parameter is
+`ndjson`:
+  - JSON: `{"records": [{"type": "user", "id": "...", "data": {...}}, ...],
+    "next_cursor": "..."}`.
+  - NDJSON: one record per line, followed by a `{"type": "cursor", "next_cursor":
+    "..."}` line if the export is not complete.
+
+Large sites can be exported in chunks by setting the `limit` query parameter: the
+export then ends with a `next_cursor`, which is passed as the `cursor` query parameter
+of the next request to resume the export from. The cursor is empty once the export is
+complete.
+
+Example:
+  - Request: GET /admin/export?format=ndjson&limit=500
+  - Response: HTTP 200 OK with an `application/x-ndjson` body.
+
+Error Handling:
+  - If the limit is not a positive integer or the cursor is malformed, the function
+    responds with a 400 status.
+*/
+func (er *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if query.Has("limit") {
+		n, err := strconv.Atoi(query.Get("limit"))
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+
+		limit = n
+	}
+
+	ndjson := query.Get("format") == "ndjson"
+	stream := &exportStream{w: w, ndjson: ndjson}
+	flusher, _ := w.(http.Flusher)
+
+	next, err := er.ExportService.Export(
+		r.Context(),
+		query.Get("cursor"),
+		limit,
+		func(record models.ExportRecord) error {
+			if err := stream.write(record); err != nil {
+				return err
+			}
+
+			// Flush the response regularly so that the export is actually streamed
+			if flusher != nil && stream.written%100 == 0 {
+				flusher.Flush()
+			}
+
+			return nil
+		},
+	)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	} else if err != nil && stream.written == 0 {
+		serverError(w, r, "Unable to export content", err)
+		return
+	} else if err != nil {
+		// The status is already sent, the truncated export is detected by the client
+		// since it lacks its closing cursor
+		logger.FromContext(r.Context()).ErrorContext(
+			r.Context(), "Unable to export content", "error", err,
+		)
+		errreport.Report(r, err)
+		return
+	}
+
+	_ = stream.close(next)
+}
+
+// exportStream writes the records of an export to the response as they are emitted.
+type exportStream struct {
+	w       http.ResponseWriter
+	ndjson  bool
+	written int
+}
+
+// begin writes the headers of the response and the opening of the JSON document.
+func (es *exportStream) begin() error {
+	if es.ndjson {
+		es.w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		es.w.Header().Set("Content-Type", "application/vnd.api+json")
+	}
+
+	es.w.WriteHeader(http.StatusOK)
+
+	if !es.ndjson {
+		_, err := es.w.Write([]byte(`{"records":[`))
+		return err
+	}
+
+	return nil
+}
+
+// write writes the record to the response, beginning the response if needed.
+func (es *exportStream) write(record models.ExportRecord) error {
+	if es.written == 0 {
+		if err := es.begin(); err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case es.ndjson:
+		body = append(body, '\n')
+	case es.written > 0:
+		body = append([]byte{','}, body...)
+	}
+
+	es.written++
+	_, err = es.w.Write(body)
+
+	return err
+}
+
+// close writes the cursor the export resumes from and the closing of the JSON document.
+func (es *exportStream) close(next string) error {
+	if es.written == 0 {
+		if err := es.begin(); err != nil {
+			return err
+		}
+	}
+
+	cursor, _ := json.Marshal(next)
+	if es.ndjson {
+		if next == "" {
+			return nil
+		}
+
+		line := `{"type":"cursor","next_cursor":` + string(cursor) + "}\n"
+		_, err := es.w.Write([]byte(line))
+		return err
+	}
+
+	_, err := es.w.Write([]byte(`],"next_cursor":` + string(cursor) + "}\n"))
+	return err
+}
@@ -0,0 +1,193 @@
+/*
+Package handlers defines various request handlers, including the feeds of a site.
+
+The `FeedHandler` in this file serves the RSS feed and the sitemap of the site (tenant)
+a request is resolved to. Every URL they list is canonical, i.e. built with the
+verified custom domain of the site if it has one (see `models.Site.URL`).
+*/
+package handlers
+
+import (
+	"encoding/xml"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// FeedHandler handles HTTP requests for the RSS feed and the sitemap of a site.
+type FeedHandler struct {
+	ArticleService services.ArticleService
+	SitemapService services.SitemapService
+}
+
+// NewFeedHandler creates and initializes a new instance of FeedHandler.
+func NewFeedHandler(
+	articleService services.ArticleService,
+	sitemapService services.SitemapService,
+) *FeedHandler {
+	return &FeedHandler{
+		ArticleService: articleService,
+		SitemapService: sitemapService,
+	}
+}
+
+// rss is the root element of an RSS 2.0 document.
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel is the channel of an RSS 2.0 document, i.e. the site.
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Language    string    `xml:"language"`
+	Items       []rssItem `xml:"item"`
+}
+
+// rssItem is an item of an RSS 2.0 channel, i.e. a published article.
+type rssItem struct {
+	Title  string `xml:"title"`
+	Link   string `xml:"link"`
+	GUID   string `xml:"guid"`
+	Author string `xml:"author,omitempty"`
+}
+
+// urlSet is the root element of a sitemap document.
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is an entry of a sitemap document.
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+/*
+GetFeed handles HTTP requests for the RSS 2.0 feed of the site, which lists its
+published articles. The channel of the feed is described with the title, the
+description and the default locale of the settings of the site, and its
+`Last-Modified` header holds when the latest of its articles was last updated.
+
+Example:
+  - Request: GET /feed.xml
+  - Response: HTTP 200 OK with an `application/rss+xml` body.
+*/
+func (fr *FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	articles, err := fr.ArticleService.GetPublishedArticles(r.Context())
+	if err != nil {
+		serverError(w, r, "Failed to fetch all articles", err)
+		return
+	}
+
+	site, _ := tenant.FromContext(r.Context())
+	settings := site.EffectiveSettings()
+	feed := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       settings.Title,
+			Link:        siteURL(r, "/"),
+			Description: settings.Description,
+			Language:    settings.DefaultLocale,
+		},
+	}
+	if feed.Channel.Description == "" {
+		feed.Channel.Description = "The latest articles published on " + settings.Title
+	}
+
+	for _, article := range articles {
+		link := siteURL(r, "/articles/"+article.ID.String())
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:  article.Title,
+			Link:   link,
+			GUID:   link,
+			Author: article.Author,
+		})
+	}
+
+	setLastModified(w, articles...)
+	writeXML(w, "application/rss+xml", feed)
+}
+
+/*
+GetSitemap handles HTTP requests for the sitemap of the site, which lists the home page,
+the published articles and the published pages of the site. The sitemap is regenerated
+periodically in the background (see `services.SitemapService`), hence the content
+published in the meantime is listed after a while.
+
+Example:
+  - Request: GET /sitemap.xml
+  - Response: HTTP 200 OK with an `application/xml` body.
+*/
+func (fr *FeedHandler) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	paths, err := fr.SitemapService.GetSitemap(r.Context())
+	if err != nil {
+		serverError(w, r, "Failed to generate the sitemap", err)
+		return
+	}
+
+	sitemap := urlSet{URLs: make([]sitemapURL, 0, len(paths))}
+	for _, path := range paths {
+		sitemap.URLs = append(sitemap.URLs, sitemapURL{Loc: siteURL(r, path)})
+	}
+
+	writeXML(w, "application/xml", sitemap)
+}
+
+// writeXML writes the XML encoding of v, preceded by the XML header, with an HTTP 200
+// (OK) status code.
+func writeXML(w http.ResponseWriter, contentType string, v any) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, "Unable to encode XML", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
+
+/*
+siteURL returns the canonical URL of the path on the site the request is resolved to.
+
+Sites without any hostname nor verified custom domain are only reachable through the
+`/s/{site}` path prefix, hence their URLs are built with the host of the request and
+that prefix instead. The port of the request is kept when it is made to the primary
+host of the site itself.
+*/
+func siteURL(r *http.Request, path string) string {
+	site, _ := tenant.FromContext(r.Context())
+	if host := site.PrimaryHost(); host != "" && host != hostOnly(r.Host) {
+		return site.URL(path)
+	} else if host != "" {
+		// Keep the port of the request, e.g. when served on localhost:8000
+		canonical, _ := url.Parse(site.URL(path))
+		canonical.Host = r.Host
+		return canonical.String()
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + "/s/" + site.Slug + path
+}
+
+// hostOnly returns the host without the port (if any) of a `host[:port]` string.
+func hostOnly(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return host
+}
@@ -0,0 +1,126 @@
+/*
+Package handlers defines various request handlers, including the feature flags of a
+site.
+
+The `FlagHandler` in this file reports the feature flags of a site and lets the admins
+enable or disable them on the site, e.g. to roll a feature out to a few sites first.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	chi "github.com/go-chi/chi/v5"
+
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/flags"
+)
+
+// FlagHandler handles HTTP requests related to the feature flags of a site.
+type FlagHandler struct {
+	FlagService services.FlagService
+}
+
+// NewFlagHandler creates and initializes a new instance of FlagHandler.
+func NewFlagHandler(flagService services.FlagService) *FlagHandler {
+	return &FlagHandler{
+		FlagService: flagService,
+	}
+}
+
+/*
+GetFlags handles HTTP requests to retrieve the feature flags as evaluated for the
+request, along with the source of their value ("default", "config", "site" or
+"request").
+
+Example:
+  - Request: GET /flags
+  - Response: HTTP 200 OK with the flags under the key "flags", e.g. `{"flags":
+    [{"name": "comments", "description": "...", "default": true, "enabled": false,
+    "source": "site"}]}`.
+*/
+func (fh *FlagHandler) GetFlags(w http.ResponseWriter, r *http.Request) {
+	response := map[string][]flags.State{
+		"flags": fh.FlagService.GetFlags(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+SetFlag handles HTTP requests to enable or disable a feature flag on the site,
+overriding the configuration of the server.
+
+Example:
+  - Request: PUT /flags/{name} with a JSON body like `{"enabled": false}`
+  - Response: HTTP 200 OK with the flag as evaluated for the request under the key
+    "flag".
+
+Error Handling:
+  - If the request body is invalid or has no `enabled` field, the function responds
+    with a 400 status.
+  - If the flag is not defined, the function responds with a 404 status.
+*/
+func (fh *FlagHandler) SetFlag(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	} else if body.Enabled == nil {
+		http.Error(w, "Invalid Request Body: missing enabled", http.StatusBadRequest)
+		return
+	}
+
+	fh.setFlag(w, r, body.Enabled)
+}
+
+/*
+ResetFlag handles HTTP requests to reset a feature flag on the site, which then takes
+the value of the configuration of the server.
+
+Example:
+  - Request: DELETE /flags/{name}
+  - Response: HTTP 200 OK with the flag as evaluated for the request under the key
+    "flag".
+
+Error Handling:
+  - If the flag is not defined, the function responds with a 404 status.
+*/
+func (fh *FlagHandler) ResetFlag(w http.ResponseWriter, r *http.Request) {
+	fh.setFlag(w, r, nil)
+}
+
+// setFlag handles a request setting (or resetting, if enabled is nil) the value of a
+// feature flag on the site.
+func (fh *FlagHandler) setFlag(w http.ResponseWriter, r *http.Request, enabled *bool) {
+	state, err := fh.FlagService.SetFlag(r.Context(), chi.URLParam(r, "name"), enabled)
+	if errors.Is(err, flags.ErrUnknownFlag) {
+		http.Error(w, "Feature Flag Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to update feature flag", err)
+		return
+	}
+
+	response := map[string]flags.State{
+		"flag": state,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -10,13 +10,160 @@ and process requests routed to different endpoints.
 */
 package handlers
 
-import "github.com/Weburz/burzcontent/server/internal/api/services"
+import (
+	"log/slog"
+	"net"
+	"time"
 
-// Handlers holds the handler instances for the various resources in the application.
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/challenge"
+	"github.com/Weburz/burzcontent/server/internal/deprecation"
+	"github.com/Weburz/burzcontent/server/internal/events"
+	"github.com/Weburz/burzcontent/server/internal/flags"
+	"github.com/Weburz/burzcontent/server/internal/hooks"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/metrics"
+	"github.com/Weburz/burzcontent/server/internal/preview"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/sanitize"
+	"github.com/Weburz/burzcontent/server/internal/scheduler"
+	"github.com/Weburz/burzcontent/server/internal/session"
+	"github.com/Weburz/burzcontent/server/internal/shortcode"
+	"github.com/Weburz/burzcontent/server/internal/webhook"
+	"github.com/Weburz/burzcontent/server/internal/webmention"
+)
+
+// Handlers holds the handler instances for the various resources in the application,
+// along with the clock of their services, which the routes and the jobs also go by,
+// the logger of the requests and the registry of the metrics of the events of the
+// sites (see `services.EventMetrics`).
 type Handlers struct {
-	UserHandler    *UserHandler
-	ArticleHandler *ArticleHandler
-	CommentHandler *CommentHandler
+	SiteHandler         *SiteHandler
+	APIKeyHandler       *APIKeyHandler
+	UsageHandler        *UsageHandler
+	UserHandler         *UserHandler
+	ArticleHandler      *ArticleHandler
+	CommentHandler      *CommentHandler
+	FeedHandler         *FeedHandler
+	AuditHandler        *AuditHandler
+	ExportHandler       *ExportHandler
+	ImportHandler       *ImportHandler
+	BackupHandler       *BackupHandler
+	EventHandler        *EventHandler
+	ContactHandler      *ContactHandler
+	AnalyticsHandler    *AnalyticsHandler
+	DashboardHandler    *DashboardHandler
+	RedirectHandler     *RedirectHandler
+	ShareLinkHandler    *ShareLinkHandler
+	PageHandler         *PageHandler
+	MenuHandler         *MenuHandler
+	SettingsHandler     *SettingsHandler
+	TemplateHandler     *TemplateHandler
+	TagHandler          *TagHandler
+	ArchiveHandler      *ArchiveHandler
+	SearchHandler       *SearchHandler
+	RevisionHandler     *RevisionHandler
+	ReviewHandler       *ReviewHandler
+	SubscriptionHandler *SubscriptionHandler
+	EditLockHandler     *EditLockHandler
+	PreviewHandler      *PreviewHandler
+	WebmentionHandler   *WebmentionHandler
+	DeprecationHandler  *DeprecationHandler
+	FlagHandler         *FlagHandler
+	ExperimentHandler   *ExperimentHandler
+	JobHandler          *JobHandler
+	TaskHandler         *TaskHandler
+	DigestHandler       *DigestHandler
+	RetentionHandler    *RetentionHandler
+	EncryptionHandler   *EncryptionHandler
+	ConsentHandler      *ConsentHandler
+	PolicyHandler       *PolicyHandler
+	HookHandler         *HookHandler
+	WebhookHandler      *WebhookHandler
+	SessionHandler      *SessionHandler
+	Clock               services.Clock
+	Logger              *slog.Logger
+	Metrics             *metrics.Registry
+}
+
+/*
+Options holds the settings used to initialize the handlers and their services.
+
+Fields:
+  - DefaultSite: The slug of the site serving the hostnames which are not mapped to
+    any site (such requests are rejected if it is empty).
+  - RootAPIKey: The API key granted the admin role on every site (disabled if empty).
+  - DefaultQuota: The quota applied to the sites which do not override it.
+  - Mailer: The mailer sending the emails (which are only logged if nil).
+  - Tasks: The queue running the background tasks of the services (an in-memory queue
+    identifying, stamping and logging its tasks like the services do their resources
+    and requests, if nil), which the server starts.
+  - ShareLinkMaxLifetime: The maximum lifetime of the links sharing the articles (7
+    days if zero).
+  - UploadLimits: The maximum sizes of the files uploaded to the management API (see
+    `UploadLimits` for the defaults).
+  - PreviewSecret: The key signing the tokens of the previews of the articles (a
+    random key if empty, the previews not surviving a restart).
+  - IDs: The generator of the unique identifiers of the new resources (version 7
+    UUIDs if nil). The tests use an `ids.Sequential` generator instead, for the
+    identifiers to be predictable.
+  - Clock: The clock the resources are stamped with when created and updated, and
+    the recurring jobs are scheduled by (the system clock if nil). The tests use a `services.FixedClock` instead, for the
+    times to be predictable (see `testutils.NewDeterministicHandlers`).
+  - ArticleSanitizer: The sanitizer of the HTML content of the articles (the article
+    policy of the `sanitize` package if nil).
+  - CommentSanitizer: The sanitizer of the HTML of the comments (the comment policy of
+    the `sanitize` package if nil).
+  - Shortcodes: The expander of the shortcodes of the articles into their embeds (the
+    built-in shortcodes of the `shortcode` package, sanitized by its embed policy, if
+    nil). Custom embeds are registered with a `shortcode.Registry` passed instead.
+  - WebmentionClient: The client verifying the received webmentions and sending the
+    webmentions of the articles (a `webmention.Client` timing out after 10 seconds if
+    nil).
+  - FeatureFlags: The values of the feature flags on every site, by name (see
+    `flags.Parse`), unless a site or a request sets its own (their default value if
+    not set).
+  - Logger: The logger of the requests, which the handlers log their unexpected errors
+    with, and of the recurring jobs (the default logger of the slog package if nil).
+  - Retention: The retention policy of the revisions of the articles and of the audit
+    log (every revision and entry is kept if zero).
+  - HookSecrets: The secrets of the integrations sending inbound webhooks, by name
+    (see `hooks.ParseSecrets`), the hooks of the other integrations being rejected.
+  - HookActions: The jobs run by the events of the integrations (see
+    `hooks.ParseActions`).
+  - WebhookClient: The client posting the events of the sites to their outbound
+    webhooks (a `webhook.Client` timing out after 10 seconds if nil).
+  - SessionLifetime: The lifetime of the browser sessions of the management API, e.g.
+    of the admin interface (12 hours if zero).
+  - CommentChallenge: The challenge the anonymous commenters have to solve (a proof
+    of work issued by the server, signed with a random key, if nil).
+*/
+type Options struct {
+	DefaultSite          string
+	RootAPIKey           string
+	DefaultQuota         models.SiteQuota
+	Mailer               mailer.Mailer
+	Tasks                *queue.Queue
+	ShareLinkMaxLifetime time.Duration
+	UploadLimits         UploadLimits
+	PreviewSecret        string
+	IDs                  services.IDGenerator
+	Clock                services.Clock
+	ArticleSanitizer     services.Sanitizer
+	CommentSanitizer     services.Sanitizer
+	Shortcodes           services.ShortcodeExpander
+	WebmentionClient     services.WebmentionClient
+	FeatureFlags         map[string]bool
+	Logger               *slog.Logger
+	Retention            models.RetentionPolicy
+	HookSecrets          map[string]string
+	HookActions          hooks.Actions
+	WebhookClient        services.WebhookClient
+	SessionLifetime      time.Duration
+	CommentChallenge     challenge.Verifier
 }
 
 /*
@@ -24,20 +171,333 @@ NewHandlers creates and initializes a new Handlers instance.
 
 This function performs the following steps:
 
- 1. Creates a new `UserHandler` instance by calling `NewUserHandler()`.
- 2. Returns a new `Handlers` instance that contains the `UserHandler`.
+ 1. Creates the services of every resource, backed by the repositories of the given
+    store and configured with the given options (e.g. assigning the identifiers of
+    the new resources with the given generator), and the broker of the events of the
+    sites.
+ 2. Returns a new `Handlers` instance that contains the handler of every resource.
 
 This function provides an easy way to initialize all the handlers needed
 for the application, including user-related handlers.
 */
-func NewHandlers() *Handlers {
-	userService := services.NewUserService()
-	articleService := services.NewArticleService()
-	commentService := services.NewCommentService()
+func NewHandlers(store *repository.Store, opts Options) *Handlers {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.Mailer == nil {
+		opts.Mailer = mailer.LogMailer{Logger: opts.Logger}
+	}
+	if opts.ShareLinkMaxLifetime <= 0 {
+		opts.ShareLinkMaxLifetime = 7 * 24 * time.Hour
+	}
+	if opts.UploadLimits.Bundle <= 0 {
+		opts.UploadLimits.Bundle = 8 << 20
+	}
+	if opts.UploadLimits.Import <= 0 {
+		opts.UploadLimits.Import = 64 << 20
+	}
+	if opts.UploadLimits.Backup <= 0 {
+		opts.UploadLimits.Backup = 256 << 20
+	}
+	if opts.IDs == nil {
+		opts.IDs = ids.UUIDv7{}
+	}
+	if opts.Clock == nil {
+		opts.Clock = services.SystemClock{}
+	}
+	if opts.Tasks == nil {
+		opts.Tasks = queue.New(
+			queue.NewMemoryBackend(10000),
+			queue.Options{IDs: opts.IDs, Clock: opts.Clock, Logger: opts.Logger},
+		)
+	}
+	if opts.ArticleSanitizer == nil {
+		opts.ArticleSanitizer = sanitize.ArticlePolicy()
+	}
+	if opts.CommentSanitizer == nil {
+		opts.CommentSanitizer = sanitize.CommentPolicy()
+	}
+	if opts.Shortcodes == nil {
+		opts.Shortcodes = shortcode.Default(sanitize.EmbedPolicy())
+	}
+	if opts.WebmentionClient == nil {
+		opts.WebmentionClient = webmention.NewClient(10*time.Second, "BurzContent")
+	}
+	if opts.WebhookClient == nil {
+		opts.WebhookClient = webhook.NewClient(10*time.Second, "BurzContent")
+	}
+	if opts.SessionLifetime <= 0 {
+		opts.SessionLifetime = 12 * time.Hour
+	}
+	if opts.CommentChallenge == nil {
+		opts.CommentChallenge = challenge.NewProofOfWork(
+			nil, challenge.DefaultDifficulty, opts.Clock,
+		)
+	}
+
+	broker := events.NewBroker(opts.Clock)
+	jobs := scheduler.New(opts.Logger, opts.Clock)
+	siteService := services.NewSiteService(
+		store.Sites,
+		opts.DefaultSite,
+		net.DefaultResolver,
+		opts.IDs,
+		opts.Clock,
+	)
+	apiKeyService := services.NewAPIKeyService(store.APIKeys, opts.RootAPIKey, opts.IDs)
+	usageService := services.NewUsageService(store, opts.DefaultQuota, opts.Clock)
+	userService := services.NewUserService(
+		store.Users,
+		store.Articles,
+		store.Comments,
+		store.Consents,
+		opts.IDs,
+		opts.Clock,
+	)
+	webmentionService := services.NewWebmentionService(
+		store.Webmentions,
+		store.Articles,
+		opts.WebmentionClient,
+		opts.Tasks,
+		broker,
+		opts.IDs,
+		opts.Clock,
+	)
+	articleService := services.NewArticleService(
+		store.Articles,
+		store.Comments,
+		store.Users,
+		store.Revisions,
+		store.Reviews,
+		store.Subscriptions,
+		store.Webmentions,
+		broker,
+		webmentionService,
+		opts.ArticleSanitizer,
+		opts.Shortcodes,
+		opts.IDs,
+		opts.Clock,
+	)
+	auditService := services.NewAuditService(store.Audit, opts.IDs, opts.Clock)
+	exportService := services.NewExportService(store)
+	importService := services.NewImportService(
+		store,
+		opts.ArticleSanitizer,
+		opts.CommentSanitizer,
+		opts.Shortcodes,
+		opts.IDs,
+		opts.Clock,
+	)
+	backupService := services.NewBackupService(store, broker, opts.IDs, opts.Clock)
+	templateService := services.NewTemplateService(
+		store.Templates,
+		opts.IDs,
+		opts.Clock,
+	)
+	subscriptionService := services.NewSubscriptionService(
+		store.Subscriptions,
+		store.Notifications,
+		store.Articles,
+		store.Users,
+		opts.Mailer,
+		templateService,
+		broker,
+		opts.IDs,
+		opts.Clock,
+	)
+	commentService := services.NewCommentService(
+		store.Comments,
+		store.Articles,
+		store.Users,
+		store.Mentions,
+		store.Consents,
+		subscriptionService,
+		broker,
+		opts.CommentSanitizer,
+		opts.IDs,
+		opts.Clock,
+	)
+	digestService := services.NewDigestService(
+		store.Users,
+		store.Articles,
+		store.Comments,
+		opts.Mailer,
+		templateService,
+		opts.Clock,
+	)
+	contactService := services.NewContactService(
+		store.Users,
+		opts.Mailer,
+		templateService,
+	)
+	analyticsService := services.NewAnalyticsService(
+		store.Analytics,
+		store.Articles,
+		store.Experiments,
+		opts.IDs,
+		opts.Clock,
+	)
+	dashboardService := services.NewDashboardService(store, analyticsService)
+	redirectService := services.NewRedirectService(store.Redirects, opts.IDs)
+	experimentService := services.NewExperimentService(
+		store.Experiments,
+		store.Analytics,
+		opts.IDs,
+		opts.Clock,
+	)
+	shareLinkService := services.NewShareLinkService(
+		store.ShareLinks,
+		store.Articles,
+		opts.ShareLinkMaxLifetime,
+		opts.IDs,
+		opts.Clock,
+	)
+	previewService := services.NewPreviewService(
+		shareLinkService,
+		store.Articles,
+		preview.NewSigner([]byte(opts.PreviewSecret)),
+		opts.Clock,
+	)
+	pageService := services.NewPageService(store.Pages, opts.IDs, opts.Clock)
+	sitemapService := services.NewSitemapService(
+		articleService,
+		pageService,
+		opts.Clock,
+	)
+	menuService := services.NewMenuService(
+		store.Menus,
+		store.Articles,
+		store.Pages,
+		opts.IDs,
+	)
+	settingsService := services.NewSettingsService(store.Sites, broker)
+	revisionService := services.NewRevisionService(store.Revisions, store.Articles)
+	retentionService := services.NewRetentionService(
+		store.Revisions,
+		store.Audit,
+		opts.Retention,
+		opts.Clock,
+	)
+	encryptionService := services.NewEncryptionService(
+		store.Users,
+		store.Comments,
+		store.Consents,
+	)
+	consentService := services.NewConsentService(
+		store.Consents,
+		store.Comments,
+		opts.Clock,
+	)
+	policyService := services.NewPolicyService(
+		store.Policies,
+		store.Users,
+		opts.IDs,
+		opts.Clock,
+	)
+	hookService := services.NewHookService(
+		opts.HookActions,
+		jobs,
+		broker,
+		opts.IDs,
+		opts.Clock,
+	)
+	webhookService := services.NewWebhookService(
+		store.Webhooks,
+		store.Deliveries,
+		store.Sites,
+		store.Users,
+		opts.WebhookClient,
+		opts.Tasks,
+		opts.Mailer,
+		templateService,
+		broker,
+		opts.IDs,
+		opts.Clock,
+	)
+	broker.Observe(webhookService.Dispatch)
+	registry := metrics.NewRegistry()
+	broker.Observe(services.NewEventMetrics(registry, opts.Tasks).Observe)
+	reviewService := services.NewReviewService(
+		store.Reviews,
+		store.Articles,
+		store.Users,
+		opts.Mailer,
+		templateService,
+		broker,
+		opts.IDs,
+		opts.Clock,
+	)
+	flagService := services.NewFlagService(store.Sites, flags.New(opts.FeatureFlags))
+	editLockService := services.NewEditLockService(
+		store.EditLocks,
+		store.Articles,
+		store.Users,
+		broker,
+		opts.IDs,
+		opts.Clock,
+	)
 
 	return &Handlers{
-		UserHandler:    NewUserHandler(userService),
-		ArticleHandler: NewArticleHandler(articleService),
-		CommentHandler: NewCommentHandler(commentService),
+		SiteHandler:         NewSiteHandler(siteService),
+		APIKeyHandler:       NewAPIKeyHandler(apiKeyService),
+		UsageHandler:        NewUsageHandler(usageService),
+		UserHandler:         NewUserHandler(userService),
+		CommentHandler:      NewCommentHandler(commentService, opts.CommentChallenge),
+		FeedHandler:         NewFeedHandler(articleService, sitemapService),
+		AuditHandler:        NewAuditHandler(auditService),
+		ExportHandler:       NewExportHandler(exportService),
+		EventHandler:        NewEventHandler(broker),
+		ContactHandler:      NewContactHandler(contactService),
+		AnalyticsHandler:    NewAnalyticsHandler(analyticsService),
+		DashboardHandler:    NewDashboardHandler(dashboardService),
+		RedirectHandler:     NewRedirectHandler(redirectService),
+		ShareLinkHandler:    NewShareLinkHandler(shareLinkService),
+		PageHandler:         NewPageHandler(pageService),
+		MenuHandler:         NewMenuHandler(menuService),
+		SettingsHandler:     NewSettingsHandler(settingsService),
+		TagHandler:          NewTagHandler(articleService),
+		ArchiveHandler:      NewArchiveHandler(articleService),
+		SearchHandler:       NewSearchHandler(articleService),
+		RevisionHandler:     NewRevisionHandler(revisionService),
+		ReviewHandler:       NewReviewHandler(reviewService),
+		SubscriptionHandler: NewSubscriptionHandler(subscriptionService),
+		EditLockHandler:     NewEditLockHandler(editLockService),
+		PreviewHandler:      NewPreviewHandler(previewService),
+		WebmentionHandler:   NewWebmentionHandler(webmentionService),
+		DeprecationHandler:  NewDeprecationHandler(deprecation.NewRegistry(opts.Clock)),
+		FlagHandler:         NewFlagHandler(flagService),
+		ExperimentHandler:   NewExperimentHandler(experimentService),
+		JobHandler:          NewJobHandler(jobs),
+		TaskHandler:         NewTaskHandler(opts.Tasks),
+		DigestHandler:       NewDigestHandler(digestService),
+		RetentionHandler:    NewRetentionHandler(retentionService),
+		EncryptionHandler:   NewEncryptionHandler(encryptionService),
+		ConsentHandler:      NewConsentHandler(consentService),
+		PolicyHandler:       NewPolicyHandler(policyService),
+		HookHandler:         NewHookHandler(hookService, opts.HookSecrets, opts.Clock),
+		WebhookHandler:      NewWebhookHandler(webhookService),
+		Clock:               opts.Clock,
+		Logger:              opts.Logger,
+		Metrics:             registry,
+		ImportHandler: NewImportHandler(
+			importService,
+			opts.UploadLimits.Import,
+		),
+		BackupHandler: NewBackupHandler(
+			backupService,
+			opts.UploadLimits.Backup,
+		),
+		TemplateHandler: NewTemplateHandler(
+			templateService,
+			opts.UploadLimits.Bundle,
+		),
+		ArticleHandler: NewArticleHandler(
+			articleService,
+			userService,
+			commentService,
+		),
+		SessionHandler: NewSessionHandler(
+			session.New(opts.SessionLifetime, opts.Clock),
+		),
 	}
 }
@@ -0,0 +1,110 @@
+/*
+Package handlers defines various request handlers, including the inbound webhooks of
+a site.
+
+The `HookHandler` in this file receives the events notified by the external services
+(e.g. GitHub or Stripe), verifying their signature, and runs their actions.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	chi "github.com/go-chi/chi/v5"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/hooks"
+)
+
+// maxHookSize is the maximum size of the body of a received hook, in bytes.
+const maxHookSize = 1 << 20
+
+// HookHandler handles HTTP requests related to the inbound webhooks of a site.
+type HookHandler struct {
+	HookService services.HookService
+	Secrets     map[string]string // The secrets of the integrations, by name
+	Clock       services.Clock
+}
+
+// NewHookHandler creates and initializes a new instance of HookHandler, verifying the
+// hooks of the integrations with the given secrets.
+func NewHookHandler(
+	hookService services.HookService,
+	secrets map[string]string,
+	clock services.Clock,
+) *HookHandler {
+	return &HookHandler{
+		HookService: hookService,
+		Secrets:     secrets,
+		Clock:       clock,
+	}
+}
+
+/*
+ReceiveHook handles the hooks sent to the site by the external services, on the public
+API.
+
+The hook is authenticated by its HMAC-SHA256 signature, made with the secret of its
+integration (see `hooks.Verify`), and its event then runs the jobs it translates into
+(see `hooks.ParseActions`) in the background.
+
+Example:
+  - Request: POST /hooks/github with the `X-GitHub-Event: push` and the
+    `X-Hub-Signature-256: sha256=<hex>` headers.
+  - Response: HTTP 202 Accepted with the hook under the key "hook", along with the
+    outcome of its jobs, e.g. `{"hook": {"integration": "github", "event": "push",
+    "actions": [{"job": "regenerate-sitemaps", "status": "started"}], ...}}`.
+
+Error Handling:
+  - If the integration has no secret, the function responds with a 404 status.
+  - If the body exceeds 1 MiB, the function responds with a 413 status.
+  - If the signature is missing or invalid, the function responds with a 401 status.
+*/
+func (hh *HookHandler) ReceiveHook(w http.ResponseWriter, r *http.Request) {
+	integration := strings.ToLower(chi.URLParam(r, "integration"))
+
+	secret, ok := hh.Secrets[integration]
+	if !ok {
+		http.Error(w, "Unknown integration", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHookSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "Hook too large", http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = hooks.Verify(integration, secret, r.Header, body, hh.Clock.Now())
+	if err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	hook, err := hh.HookService.ReceiveHook(r.Context(), models.Hook{
+		Integration: integration,
+		Event:       hooks.Event(r.Header, body),
+	})
+	if err != nil {
+		serverError(w, r, "Unable to receive hook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusAccepted)
+
+	response := map[string]models.Hook{"hook": hook}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
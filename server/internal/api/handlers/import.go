@@ -0,0 +1,113 @@
+/*
+Package handlers defines various request handlers, including the content import of a
+site.
+
+The `ImportHandler` in this file imports the content exported by other content
+management systems, such as WordPress, into a site.
+*/
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/wxr"
+)
+
+// ImportHandler handles HTTP requests related to the content import of a site.
+type ImportHandler struct {
+	ImportService services.ImportService
+	MaxImportSize int64 // The largest file which can be imported, in bytes
+}
+
+// NewImportHandler creates and initializes a new instance of ImportHandler, importing
+// the files up to the given size (in bytes).
+func NewImportHandler(
+	importService services.ImportService,
+	maxImportSize int64,
+) *ImportHandler {
+	return &ImportHandler{
+		ImportService: importService,
+		MaxImportSize: maxImportSize,
+	}
+}
+
+/*
+ImportWordPress handles HTTP requests to import a WordPress export (WXR file) into the
+site.
+
+The WXR file is either the body of the request or the `file` field of a
+`multipart/form-data` body (named with the `.xml` or `.wxr` extension), and can not
+exceed the configured size (64 MiB by default). When the `dry_run` query
+parameter is `true`, nothing is stored but the report of the import is returned all
+the same, so that it can be reviewed beforehand.
+
+Example:
+  - Request: POST /admin/import/wordpress?dry_run=true
+  - Response: HTTP 200 OK with the report of the import, e.g. `{"report": {"dry_run":
+    true, "created": {"users": 2, "articles": 10, "comments": 25}, "skipped": [...]}}`
+    (HTTP 201 Created if the content was imported).
+
+Error Handling:
+  - If the `dry_run` query parameter is not a boolean or the file is not a valid WXR
+    file, the function responds with a 400 status.
+  - If the file is missing, too large or not an XML document, the function responds
+    with the details of the problem and a 400, 413 or 415 status (see `readUpload`).
+  - If the import fails midway, the function responds with a 500 status. The content
+    imported until then is kept, and importing the file again resumes the import.
+*/
+func (ir *ImportHandler) ImportWordPress(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid dry_run parameter", http.StatusBadRequest)
+			return
+		}
+
+		dryRun = b
+	}
+
+	// The WXR files without an XML declaration are sniffed as plain text
+	file, ok := readUpload(w, r, uploadKind{
+		name:       "WXR file",
+		types:      []string{"text/xml", "application/xml", "text/plain"},
+		extensions: []string{".xml", ".wxr"},
+		maxSize:    ir.MaxImportSize,
+	})
+	if !ok {
+		return
+	}
+
+	export, err := wxr.Parse(bytes.NewReader(file))
+	if err != nil {
+		http.Error(w, "Invalid WXR file", http.StatusBadRequest)
+		return
+	}
+
+	report, err := ir.ImportService.ImportWordPress(r.Context(), export, dryRun)
+	if err != nil {
+		serverError(w, r, "Unable to import WXR file", err)
+		return
+	}
+
+	response := map[string]models.ImportReport{
+		"report": report,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	if dryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
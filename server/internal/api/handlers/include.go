@@ -0,0 +1,49 @@
+/*
+Package handlers defines various request handlers, including the parsing of the
+relationships to include in their responses.
+*/
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxIncludeDepth is the maximum number of relationships a path of the `include` query
+// parameter may go through (e.g. "comments" goes through one, "comments.author" two).
+const maxIncludeDepth = 1
+
+// articleRelationships lists the relationships of the articles which can be included in
+// their responses.
+var articleRelationships = []string{"author", "comments", "tags"}
+
+/*
+parseInclude parses the comma-separated relationship paths of the `include` query
+parameter (e.g. `?include=author,comments`), following JSON:API. The paths are returned
+in order without duplicates, or nil if the parameter is not set.
+
+It reports false if a path goes deeper than `maxIncludeDepth` or is not one of the
+given relationships.
+*/
+func parseInclude(r *http.Request, relationships []string) ([]string, bool) {
+	param := r.URL.Query().Get("include")
+	if param == "" {
+		return nil, true
+	}
+
+	var paths []string
+	for _, path := range strings.Split(param, ",") {
+		path = strings.TrimSpace(path)
+		if strings.Count(path, ".")+1 > maxIncludeDepth ||
+			!slices.Contains(relationships, path) {
+			return nil, false
+		}
+
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, true
+}
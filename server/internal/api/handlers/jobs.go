@@ -0,0 +1,52 @@
+/*
+Package handlers defines various request handlers, including the scheduled jobs of the
+server.
+
+The `JobHandler` in this file reports the status of the recurring jobs of the server
+(e.g. publishing the scheduled articles or backing up the sites) and of their last run.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/scheduler"
+)
+
+// JobHandler handles HTTP requests related to the scheduled jobs of the server.
+type JobHandler struct {
+	Scheduler *scheduler.Scheduler
+}
+
+// NewJobHandler creates and initializes a new instance of JobHandler.
+func NewJobHandler(scheduler *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{
+		Scheduler: scheduler,
+	}
+}
+
+/*
+GetJobs handles HTTP requests to retrieve the scheduled jobs of the server, along with
+the status of their last run since the server started.
+
+Example:
+  - Request: GET /jobs
+  - Response: HTTP 200 OK with the jobs under the key "jobs", e.g. `{"jobs": [{"name":
+    "backup", "description": "...", "schedule": "0 3 * * *", "enabled": true,
+    "running": false, "next_run_at": "...", "last_run_at": "...",
+    "last_duration_ms": 120, "runs": 1, "failures": 0, "skipped": 0}]}`.
+*/
+func (jh *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	response := map[string][]scheduler.Status{
+		"jobs": jh.Scheduler.Status(),
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Package handlers defines various request handlers, including the edit locks of the
+articles.
+
+The `EditLockHandler` in this file lets the editors lock the articles their users are
+editing, keep the locks alive with heartbeats and release them, so that the other
+users can be warned that an article is being edited (e.g. "Jane Doe is currently
+editing this article"). The locks are personal: they require an API key owned by a
+user.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// EditLockHandler handles HTTP requests related to the edit locks of the articles.
+type EditLockHandler struct {
+	EditLockService services.EditLockService
+}
+
+// NewEditLockHandler creates and initializes a new instance of EditLockHandler.
+func NewEditLockHandler(editLockService services.EditLockService) *EditLockHandler {
+	return &EditLockHandler{
+		EditLockService: editLockService,
+	}
+}
+
+/*
+GetLock handles HTTP requests to retrieve the lock of an article, telling who is
+editing it.
+
+Example:
+  - Request: GET /articles/{id}/lock
+  - Response: HTTP 200 OK with a JSON body containing the lock under the key "lock".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist or nobody is editing it, the function responds with
+    a 404 status.
+*/
+func (lh *EditLockHandler) GetLock(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	lock, err := lh.EditLockService.GetLock(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Lock Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch lock", err)
+		return
+	}
+
+	writeLock(w, r, http.StatusOK, lock)
+}
+
+/*
+Lock handles HTTP requests to lock an article for the user of the request, who starts
+editing it. The lock expires unless it is renewed with heartbeats; locking an article
+the user already locked renews the lock.
+
+Example:
+  - Request: POST /articles/{id}/lock
+  - Response: HTTP 200 OK with a JSON body containing the lock under the key "lock".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the article does not exist, the function responds with a 404 status.
+  - If another user is editing the article, the function responds with a 409 status
+    and a JSON body containing their lock under the key "lock".
+*/
+func (lh *EditLockHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	lock, err := lh.EditLockService.Lock(r.Context(), articleID)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, services.ErrLocked) {
+		writeLock(w, r, http.StatusConflict, lock)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to lock article", err)
+		return
+	}
+
+	writeLock(w, r, http.StatusOK, lock)
+}
+
+/*
+Heartbeat handles HTTP requests to renew the lock of an article held by the user of
+the request, who is still editing it.
+
+Example:
+  - Request: POST /articles/{id}/lock/heartbeat
+  - Response: HTTP 200 OK with a JSON body containing the lock under the key "lock".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the article does not exist or its lock expired, the function responds with a
+    404 status (the article has to be locked again).
+  - If another user is editing the article, the function responds with a 409 status
+    and a JSON body containing their lock under the key "lock".
+*/
+func (lh *EditLockHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	lock, err := lh.EditLockService.Heartbeat(r.Context(), articleID)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, services.ErrLocked) {
+		writeLock(w, r, http.StatusConflict, lock)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Lock Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to renew lock", err)
+		return
+	}
+
+	writeLock(w, r, http.StatusOK, lock)
+}
+
+/*
+Release handles HTTP requests to release the lock of an article held by the user of
+the request, who stopped editing it. It is a POST request, so that the editors can
+send it with `navigator.sendBeacon` when their page is closed.
+
+Example:
+  - Request: POST /articles/{id}/lock/release
+  - Response: HTTP 204 No Content.
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the article does not exist or nobody is editing it, the function responds with
+    a 404 status.
+  - If another user is editing the article, the function responds with a 409 status.
+*/
+func (lh *EditLockHandler) Release(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	err := lh.EditLockService.Release(r.Context(), articleID)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, services.ErrLocked) {
+		http.Error(w, "Article locked by another user", http.StatusConflict)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Lock Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to release lock", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeLock answers the request with the lock under the key "lock" and the given
+// status, along with an error telling who is editing the article if it is a conflict.
+func writeLock(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	lock models.EditLock,
+) {
+	response := map[string]any{
+		"lock": lock,
+	}
+	if status == http.StatusConflict {
+		response["error"] = lock.UserName + " is currently editing this article"
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
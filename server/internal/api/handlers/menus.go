@@ -0,0 +1,253 @@
+/*
+Package handlers defines various request handlers, including the navigation menus of a
+site.
+
+The `MenuHandler` in this file handles the management of the navigation menus of a site
+and serves them to its frontends by their handle, so that the frontends render the
+navigation of the site from the API instead of hardcoding it.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	chi "github.com/go-chi/chi/v5"
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// MenuHandler handles HTTP requests related to the navigation menus of a site.
+type MenuHandler struct {
+	MenuService services.MenuService
+}
+
+// NewMenuHandler creates and initializes a new instance of MenuHandler.
+func NewMenuHandler(menuService services.MenuService) *MenuHandler {
+	return &MenuHandler{
+		MenuService: menuService,
+	}
+}
+
+/*
+GetAllMenus handles HTTP requests to retrieve the list of menus of the site, along with
+every entry of the menus.
+
+The response contains a JSON array of menus under the key "menus" along with an HTTP
+200 (OK) status code.
+*/
+func (mr *MenuHandler) GetAllMenus(w http.ResponseWriter, r *http.Request) {
+	menus, err := mr.MenuService.GetAllMenus(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch menus", err)
+		return
+	}
+
+	writeMenus(w, r, menus)
+}
+
+/*
+GetMenuByID handles HTTP requests to retrieve a menu by its ID.
+
+Error Handling:
+  - If the menu ID is not a valid UUID, the function responds with a 400 status.
+  - If the menu does not exist, the function responds with a 404 status.
+*/
+func (mr *MenuHandler) GetMenuByID(w http.ResponseWriter, r *http.Request) {
+	menuID := params.UUID(r.Context(), "id")
+
+	menu, err := mr.MenuService.GetMenuByID(r.Context(), menuID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Menu Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch menu data", err)
+		return
+	}
+
+	writeMenu(w, r, http.StatusOK, menu)
+}
+
+/*
+GetPublishedMenu handles HTTP requests to retrieve a menu by its handle, as served to
+the readers, i.e. without the entries linking to unpublished content.
+
+Example:
+  - Request: GET /menus/main
+  - Response: HTTP 200 OK with the menu under the key "menu", or HTTP 404 Not Found if
+    no such menu exists.
+*/
+func (mr *MenuHandler) GetPublishedMenu(w http.ResponseWriter, r *http.Request) {
+	menu, err := mr.MenuService.GetPublishedMenu(
+		r.Context(),
+		chi.URLParam(r, "handle"),
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Menu Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch menu data", err)
+		return
+	}
+
+	writeMenu(w, r, http.StatusOK, menu)
+}
+
+/*
+CreateMenu handles HTTP requests to create a new menu.
+
+Example:
+  - When a POST request is made to `/menus` with a JSON payload (e.g.,
+    `{"handle": "main", "name": "Main", "items": [{"label": "About", "page_id":
+    "..."}, {"label": "GitHub", "url": "https://github.com/Weburz"}]}`), this
+    function will create the menu and respond with a 201 status along with the menu
+    data in the response body.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the handle is already taken by another menu, the function responds with a 409
+    status.
+  - If the request validation fails or an entry of the menu is invalid, the function
+    responds with a 422 status.
+*/
+func (mr *MenuHandler) CreateMenu(w http.ResponseWriter, r *http.Request) {
+	var newMenu models.Menu
+	if err := decodeJSON(r, &newMenu); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(newMenu); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	menu, err := mr.MenuService.CreateMenu(r.Context(), newMenu)
+	if errors.Is(err, services.ErrInvalidMenuItem) {
+		http.Error(w, "Invalid Menu Item", http.StatusUnprocessableEntity)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Menu handle already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process menu data", err)
+		return
+	}
+
+	writeMenu(w, r, http.StatusCreated, menu)
+}
+
+/*
+UpdateMenu handles HTTP requests to update the handle, name and entries of an existing
+menu.
+
+Error Handling:
+  - If the menu ID is not a valid UUID or the request body is invalid, the function
+    responds with a 400 status.
+  - If the menu does not exist, the function responds with a 404 status.
+  - If the handle is already taken by another menu, the function responds with a 409
+    status.
+  - If the request validation fails or an entry of the menu is invalid, the function
+    responds with a 422 status.
+*/
+func (mr *MenuHandler) UpdateMenu(w http.ResponseWriter, r *http.Request) {
+	menuID := params.UUID(r.Context(), "id")
+
+	var updatedMenu models.Menu
+	if err := decodeJSON(r, &updatedMenu); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(updatedMenu); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	menu, err := mr.MenuService.UpdateMenu(r.Context(), menuID, updatedMenu)
+	if errors.Is(err, services.ErrInvalidMenuItem) {
+		http.Error(w, "Invalid Menu Item", http.StatusUnprocessableEntity)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Menu Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Menu handle already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process menu data", err)
+		return
+	}
+
+	writeMenu(w, r, http.StatusCreated, menu)
+}
+
+/*
+PatchMenu handles HTTP requests to partially update an existing menu with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`).
+
+Example:
+  - Request: PATCH /menus/{id}
+  - Request Body: `{"name": "Footer"}`
+  - Response: the response of `UpdateMenu` to the patched menu.
+*/
+func (mr *MenuHandler) PatchMenu(w http.ResponseWriter, r *http.Request) {
+	mergePatch(w, r, "Menu", mr.MenuService.GetMenuByID, mr.UpdateMenu)
+}
+
+/*
+DeleteMenu handles HTTP requests to delete a menu by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the menu ID is not a valid UUID and a 404 status if the menu does not exist.
+*/
+func (mr *MenuHandler) DeleteMenu(w http.ResponseWriter, r *http.Request) {
+	menuID := params.UUID(r.Context(), "id")
+
+	err := mr.MenuService.DeleteMenu(r.Context(), menuID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Menu Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete menu", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMenus writes the JSON encoding of the menus under the key "menus" with an HTTP
+// 200 (OK) status code.
+func writeMenus(w http.ResponseWriter, r *http.Request, menus []models.Menu) {
+	response := map[string][]models.Menu{
+		"menus": menus,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+// writeMenu writes the JSON encoding of the menu under the key "menu" with the given
+// status code.
+func writeMenu(w http.ResponseWriter, r *http.Request, status int, menu models.Menu) {
+	response := map[string]models.Menu{
+		"menu": menu,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Package handlers defines various request handlers, including the static pages of a
+site.
+
+The `PageHandler` in this file handles the management of the static pages of a site
+(e.g. about, contact or legal pages) and serves the published ones to the readers by
+their path.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	chi "github.com/go-chi/chi/v5"
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// PageHandler handles HTTP requests related to the static pages of a site.
+type PageHandler struct {
+	PageService services.PageService
+}
+
+// NewPageHandler creates and initializes a new instance of PageHandler.
+func NewPageHandler(pageService services.PageService) *PageHandler {
+	return &PageHandler{
+		PageService: pageService,
+	}
+}
+
+/*
+GetAllPages handles HTTP requests to retrieve the list of pages of the site, whether
+published or not.
+
+The response contains a JSON array of pages under the key "pages" along with an HTTP
+200 (OK) status code.
+*/
+func (pr *PageHandler) GetAllPages(w http.ResponseWriter, r *http.Request) {
+	pages, err := pr.PageService.GetAllPages(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch pages", err)
+		return
+	}
+
+	writePages(w, r, pages)
+}
+
+/*
+GetPublishedPages handles HTTP requests to retrieve the list of published pages of the
+site, e.g. to build its navigation.
+
+The response contains a JSON array of pages under the key "pages" along with an HTTP
+200 (OK) status code.
+*/
+func (pr *PageHandler) GetPublishedPages(w http.ResponseWriter, r *http.Request) {
+	pages, err := pr.PageService.GetPublishedPages(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch pages", err)
+		return
+	}
+
+	writePages(w, r, pages)
+}
+
+/*
+GetPageByID handles HTTP requests to retrieve a page by its ID.
+
+Error Handling:
+  - If the page ID is not a valid UUID, the function responds with a 400 status.
+  - If the page does not exist, the function responds with a 404 status.
+*/
+func (pr *PageHandler) GetPageByID(w http.ResponseWriter, r *http.Request) {
+	pageID := params.UUID(r.Context(), "id")
+
+	page, err := pr.PageService.GetPageByID(r.Context(), pageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Page Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch page data", err)
+		return
+	}
+
+	writePage(w, r, http.StatusOK, page)
+}
+
+/*
+GetPublishedPageByPath handles HTTP requests to retrieve a published page by its path,
+e.g. `/pages/legal/privacy` serves the page whose path is "/legal/privacy".
+
+The function responds with a 404 status if no such page is published.
+*/
+func (pr *PageHandler) GetPublishedPageByPath(w http.ResponseWriter, r *http.Request) {
+	page, err := pr.PageService.GetPublishedPageByPath(
+		r.Context(),
+		"/"+chi.URLParam(r, "*"),
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Page Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch page data", err)
+		return
+	}
+
+	writePage(w, r, http.StatusOK, page)
+}
+
+/*
+CreatePage handles HTTP requests to create a new page.
+
+Example:
+  - When a POST request is made to `/pages` with a JSON payload (e.g.,
+    `{"path": "/legal/privacy", "title": "Privacy Policy", "isPublished": false}`),
+    this function will create the page and respond with a 201 status along with the
+    page data in the response body.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the path is already taken by another page, the function responds with a 409
+    status.
+  - If the request validation fails or the path is invalid, the function responds
+    with a 422 status.
+*/
+func (pr *PageHandler) CreatePage(w http.ResponseWriter, r *http.Request) {
+	var newPage models.Page
+	if err := decodeJSON(r, &newPage); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(newPage); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	page, err := pr.PageService.CreatePage(r.Context(), newPage)
+	if errors.Is(err, services.ErrInvalidPath) {
+		http.Error(w, "Invalid Page Path", http.StatusUnprocessableEntity)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Page path already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process page data", err)
+		return
+	}
+
+	writePage(w, r, http.StatusCreated, page)
+}
+
+/*
+UpdatePage handles HTTP requests to update the path, title, content and publication
+status of an existing page.
+
+Error Handling:
+  - If the page ID is not a valid UUID or the request body is invalid, the function
+    responds with a 400 status.
+  - If the page does not exist, the function responds with a 404 status.
+  - If the path is already taken by another page, the function responds with a 409
+    status.
+  - If the request validation fails or the path is invalid, the function responds
+    with a 422 status.
+*/
+func (pr *PageHandler) UpdatePage(w http.ResponseWriter, r *http.Request) {
+	pageID := params.UUID(r.Context(), "id")
+
+	var updatedPage models.Page
+	if err := decodeJSON(r, &updatedPage); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(updatedPage); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	page, err := pr.PageService.UpdatePage(r.Context(), pageID, updatedPage)
+	if errors.Is(err, services.ErrInvalidPath) {
+		http.Error(w, "Invalid Page Path", http.StatusUnprocessableEntity)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Page Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Page path already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process page data", err)
+		return
+	}
+
+	writePage(w, r, http.StatusCreated, page)
+}
+
+/*
+PatchPage handles HTTP requests to partially update an existing page with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`).
+
+Example:
+  - Request: PATCH /pages/{id}
+  - Request Body: `{"title": "About us"}`
+  - Response: the response of `UpdatePage` to the patched page.
+*/
+func (pr *PageHandler) PatchPage(w http.ResponseWriter, r *http.Request) {
+	mergePatch(w, r, "Page", pr.PageService.GetPageByID, pr.UpdatePage)
+}
+
+/*
+DeletePage handles HTTP requests to delete a page by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the page ID is not a valid UUID and a 404 status if the page does not exist.
+*/
+func (pr *PageHandler) DeletePage(w http.ResponseWriter, r *http.Request) {
+	pageID := params.UUID(r.Context(), "id")
+
+	err := pr.PageService.DeletePage(r.Context(), pageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Page Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete page", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePages writes the JSON encoding of the pages under the key "pages" with an HTTP
+// 200 (OK) status code.
+func writePages(w http.ResponseWriter, r *http.Request, pages []models.Page) {
+	response := map[string][]models.Page{
+		"pages": pages,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+// writePage writes the JSON encoding of the page under the key "page" with the given
+// status code.
+func writePage(w http.ResponseWriter, r *http.Request, status int, page models.Page) {
+	response := map[string]models.Page{
+		"page": page,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Package handlers defines various request handlers, including the partial updates of the
+resources with a `PATCH`.
+
+The `PATCH` requests of the resources carry a JSON merge patch (RFC 7396): the fields it
+holds replace the fields of the stored resource, the `null` ones remove them and the
+nested objects are merged in turn, e.g. `{"title": "New title"}` only updates the title
+of an article. The patched resource is then validated and saved like the body of a
+`PUT`.
+*/
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/naming"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+/*
+mergePatch applies the JSON merge patch of the request to the resource identified by
+the `{id}` of its path, and hands the patched resource to update as the body of the
+request, e.g. `UpdateArticle`.
+
+The resource is fetched with get, and named by name in the errors (e.g. "Article Not
+Found"). The fields listed in required must be given by the patch rather than taken
+from the stored resource, e.g. the `version` of an article the update was made from.
+
+Error Handling:
+  - If the patch is not a JSON object, a `400 Bad Request` error is returned.
+  - If a required field is missing, a `422 Unprocessable Entity` error is returned,
+    e.g. "Article version is required".
+  - If the resource does not exist, a `404 Not Found` error is returned.
+  - Otherwise the errors are those of update, e.g. when the patched resource is
+    invalid.
+*/
+func mergePatch[T any](
+	w http.ResponseWriter,
+	r *http.Request,
+	name string,
+	get func(ctx context.Context, id uuid.UUID) (T, error),
+	update http.HandlerFunc,
+	required ...string,
+) {
+	var patch any
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil {
+		http.Error(
+			w,
+			"Invalid Request Body: "+describeJSONError(err).Error(),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	if err := decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		http.Error(
+			w,
+			"Invalid Request Body: the body must hold a single JSON document",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	// Match the fields named in the convention of the client to those of the resource
+	fields, ok := naming.Match(patch, reflect.TypeFor[T]()).(map[string]any)
+	if !ok {
+		http.Error(
+			w,
+			"Invalid Request Body: the body must be an object",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	for _, field := range required {
+		if value, ok := fields[field]; !ok || value == nil {
+			http.Error(
+				w,
+				fmt.Sprintf("%s %s is required", name, field),
+				http.StatusUnprocessableEntity,
+			)
+			return
+		}
+	}
+
+	resource, err := get(r.Context(), params.UUID(r.Context(), "id"))
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, name+" Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch "+name, err)
+		return
+	}
+
+	var stored any
+	body, err := json.Marshal(resource)
+	if err == nil {
+		decoder = json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		err = decoder.Decode(&stored)
+	}
+	if err == nil {
+		body, err = json.Marshal(mergeJSON(stored, fields))
+	}
+	if err != nil {
+		serverError(w, r, "Unable to patch "+name, err)
+		return
+	}
+
+	patched := r.Clone(r.Context())
+	patched.Body = io.NopCloser(bytes.NewReader(body))
+	patched.ContentLength = int64(len(body))
+	update(w, patched)
+}
+
+// mergeJSON returns the target with the JSON merge patch applied to it, as described by
+// RFC 7396.
+func mergeJSON(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	merged, ok := target.(map[string]any)
+	if !ok {
+		merged = make(map[string]any, len(fields))
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = mergeJSON(merged[key], value)
+		}
+	}
+
+	return merged
+}
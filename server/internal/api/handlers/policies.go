@@ -0,0 +1,195 @@
+/*
+Package handlers defines various request handlers, including the policies of the sites
+(e.g. their terms of service).
+
+The `PolicyHandler` in this file handles publishing the versions of the policy of a
+site, accepting the current version, listing the users who did not accept it and
+querying the history of the acceptances.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// PolicyHandler handles HTTP requests related to the policies of a site.
+type PolicyHandler struct {
+	PolicyService services.PolicyService
+}
+
+// NewPolicyHandler creates and initializes a new instance of PolicyHandler.
+func NewPolicyHandler(policyService services.PolicyService) *PolicyHandler {
+	return &PolicyHandler{
+		PolicyService: policyService,
+	}
+}
+
+/*
+GetPolicies handles HTTP requests to retrieve the published versions of the policy of
+the site.
+
+The response contains a JSON array of policies under the key "policies", in the order
+of publication, the last one being the current one.
+*/
+func (ph *PolicyHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := ph.PolicyService.GetPolicies(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch policies", err)
+		return
+	}
+
+	writePolicyResponse(w, r, http.StatusOK, map[string][]models.Policy{
+		"policies": policies,
+	})
+}
+
+/*
+PublishPolicy handles HTTP requests to publish a new version of the policy of the
+site, which becomes the current one and has to be accepted by every user.
+
+Example:
+  - When a POST request is made to `/policies` with a JSON payload (e.g.,
+    `{"version": "2025-06", "title": "Terms of Service", "require_acceptance":
+    true}`), this function will publish the policy and respond with a 201 status
+    along with the policy under the key "policy" and the number of users who have to
+    accept it under the key "pending_users".
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails, the function responds with a 422 status.
+  - If the version is already published, the function responds with a 409 status.
+*/
+func (ph *PolicyHandler) PublishPolicy(w http.ResponseWriter, r *http.Request) {
+	var newPolicy models.Policy
+	if err := decodeJSON(r, &newPolicy); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(newPolicy); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	policy, pending, err := ph.PolicyService.PublishPolicy(r.Context(), newPolicy)
+	if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Policy version already published", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to publish policy", err)
+		return
+	}
+
+	writePolicyResponse(w, r, http.StatusCreated, map[string]any{
+		"policy":        policy,
+		"pending_users": pending,
+	})
+}
+
+/*
+GetPendingUsers handles HTTP requests to retrieve the users of the site who did not
+accept its current policy.
+
+The response contains a JSON array of users under the key "users", empty if the site
+has no policy.
+*/
+func (ph *PolicyHandler) GetPendingUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := ph.PolicyService.GetPendingUsers(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch users", err)
+		return
+	}
+
+	writePolicyResponse(w, r, http.StatusOK, map[string][]models.User{
+		"users": users,
+	})
+}
+
+/*
+AcceptPolicy handles HTTP requests to accept the current policy of the site on behalf
+of the user of the request.
+
+Example:
+  - Request: POST /policies/accept
+  - Response: HTTP 201 Created with the acceptance under the key "acceptance".
+
+Error Handling:
+  - If the API key of the request is not owned by any user, the function responds
+    with a 403 status.
+  - If the site has no policy, the function responds with a 404 status.
+*/
+func (ph *PolicyHandler) AcceptPolicy(w http.ResponseWriter, r *http.Request) {
+	acceptance, err := ph.PolicyService.AcceptPolicy(r.Context())
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Policy Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to accept policy", err)
+		return
+	}
+
+	writePolicyResponse(w, r, http.StatusCreated, map[string]models.PolicyAcceptance{
+		"acceptance": acceptance,
+	})
+}
+
+/*
+GetAcceptances handles HTTP requests to retrieve the history of the acceptances of the
+policies of the site, in the order they were made.
+
+The acceptances of a user or of a policy only are retrieved with the `user_id` and the
+`policy_id` query parameters, e.g. `GET /policies/acceptances?user_id=...`. The
+response contains a JSON array of acceptances under the key "acceptances".
+
+Error Handling:
+  - If a query parameter is not a valid UUID, the function responds with a 400
+    status.
+*/
+func (ph *PolicyHandler) GetAcceptances(w http.ResponseWriter, r *http.Request) {
+	var ids [2]uuid.UUID
+	for i, name := range []string{"user_id", "policy_id"} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+
+		id, err := uuid.Parse(value)
+		if err != nil {
+			http.Error(w, "Invalid "+name, http.StatusBadRequest)
+			return
+		}
+		ids[i] = id
+	}
+
+	acceptances, err := ph.PolicyService.GetAcceptances(r.Context(), ids[0], ids[1])
+	if err != nil {
+		serverError(w, r, "Unable to fetch acceptances", err)
+		return
+	}
+
+	writePolicyResponse(w, r, http.StatusOK, map[string][]models.PolicyAcceptance{
+		"acceptances": acceptances,
+	})
+}
+
+// writePolicyResponse writes the response of the policies with the given status.
+func writePolicyResponse(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Package handlers defines various request handlers, including the previews of the draft
+articles on the headless frontends.
+
+The `PreviewHandler` in this file serves the preview handshake of the frontends (e.g.
+the Draft Mode of Next.js or an Astro middleware) and the articles they preview. A
+typical Next.js integration goes as follows:
+
+ 1. The editor opens `/api/draft?token=<share link token>` on the frontend.
+ 2. The route handler of the frontend posts the token to `/preview`, enables the Draft
+    Mode and stores the preview token of the response in a cookie of its own.
+ 3. The route handler redirects the editor to the page of the article (e.g. by its
+    slug), whose rendering fetches the article from `/preview/articles/{id}` with the
+    preview token while the Draft Mode is enabled.
+
+The frontends calling the API from the browser can rely on the preview cookie set by
+the handshake instead.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/preview"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// PreviewCookie is the name of the cookie holding the preview token, set by the
+// preview handshake.
+const PreviewCookie = "burzcontent_preview"
+
+// PreviewHandler handles HTTP requests related to the previews of the articles.
+type PreviewHandler struct {
+	PreviewService services.PreviewService
+}
+
+// NewPreviewHandler creates and initializes a new instance of PreviewHandler.
+func NewPreviewHandler(previewService services.PreviewService) *PreviewHandler {
+	return &PreviewHandler{
+		PreviewService: previewService,
+	}
+}
+
+/*
+StartPreview handles the preview handshake of the frontends, on the public API,
+exchanging the token of a link sharing an article for a short-lived preview token.
+
+The preview token is returned in the response, within the URL of the previewed article,
+and in the `burzcontent_preview` cookie scoped to the preview routes. The response is
+never cached.
+
+Example:
+  - Request: POST /preview with a body like `{"token": "<share link token>"}`
+  - Response: HTTP 200 OK with a JSON body containing the preview under the key
+    "preview", e.g. `{"preview": {"article_id": "...", "slug": "my-draft", "token":
+    "...", "url": "/preview/articles/...?token=...", "expires_at": "..."}}`.
+
+Error Handling:
+  - If the request body is malformed, the function responds with a 400 status.
+  - If the token is missing, the function responds with a 422 status.
+  - If the share link is unknown, expired, revoked or already used, the function
+    responds with a 404 status.
+*/
+func (ph *PreviewHandler) StartPreview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	var req models.PreviewRequest
+	if err := decodeJSON(r, &req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	p, err := ph.PreviewService.StartPreview(r.Context(), req.Token)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Share Link Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to start preview", err)
+		return
+	}
+
+	// The preview routes are mounted next to the handshake, whatever the prefix of the
+	// site (e.g. `/s/{site}`)
+	base := strings.TrimSuffix(r.URL.Path, "/")
+	p.URL = base + "/articles/" + p.ArticleID.String() + "?" + url.Values{
+		"token": {p.Token},
+	}.Encode()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     PreviewCookie,
+		Value:    p.Token,
+		Path:     base,
+		Expires:  p.ExpiresAt,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	response := map[string]models.Preview{
+		"preview": p,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetPreviewArticle handles HTTP requests for a previewed article, on the public API,
+whether it is published or not. The preview token is read from the `token` query
+parameter, or from the preview cookie if there is none.
+
+The response is never cached nor indexed.
+
+Example:
+  - Request: GET /preview/articles/{id}?token=<preview token>
+  - Response: HTTP 200 OK with a JSON body containing the article under the key
+    "article".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the preview token is missing, invalid, expired or previews another article, the
+    function responds with a 401 status.
+  - If the article does not exist, the function responds with a 404 status.
+*/
+func (ph *PreviewHandler) GetPreviewArticle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	token := r.URL.Query().Get("token")
+	if cookie, err := r.Cookie(PreviewCookie); token == "" && err == nil {
+		token = cookie.Value
+	}
+
+	articleID := params.UUID(r.Context(), "id")
+
+	article, err := ph.PreviewService.GetPreviewArticle(r.Context(), articleID, token)
+	if errors.Is(err, preview.ErrInvalidToken) {
+		http.Error(w, "Invalid or expired preview token", http.StatusUnauthorized)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch previewed article", err)
+		return
+	}
+
+	response := map[string]models.Article{
+		"article": article,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
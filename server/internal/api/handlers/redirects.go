@@ -0,0 +1,220 @@
+/*
+Package handlers defines various request handlers, including the redirects of a site.
+
+The `RedirectHandler` in this file handles the management of the redirects served by a
+site, e.g. when the slug of an article changes or when content is migrated from an old
+site. The redirects themselves are served by the `middleware.Redirects` middleware.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// RedirectHandler handles HTTP requests related to the redirects of a site.
+type RedirectHandler struct {
+	RedirectService services.RedirectService
+}
+
+// NewRedirectHandler creates and initializes a new instance of RedirectHandler.
+func NewRedirectHandler(redirectService services.RedirectService) *RedirectHandler {
+	return &RedirectHandler{
+		RedirectService: redirectService,
+	}
+}
+
+/*
+GetAllRedirects handles HTTP requests to retrieve the list of redirects of the site.
+
+The response contains a JSON array of redirects under the key "redirects" along with
+an HTTP 200 (OK) status code.
+*/
+func (rr *RedirectHandler) GetAllRedirects(w http.ResponseWriter, r *http.Request) {
+	redirects, err := rr.RedirectService.GetAllRedirects(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch redirects", err)
+		return
+	}
+
+	response := map[string][]models.Redirect{
+		"redirects": redirects,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetRedirectByID handles HTTP requests to retrieve a redirect by its ID.
+
+Error Handling:
+  - If the redirect ID is not a valid UUID, the function responds with a 400 status.
+  - If the redirect does not exist, the function responds with a 404 status.
+*/
+func (rr *RedirectHandler) GetRedirectByID(w http.ResponseWriter, r *http.Request) {
+	redirectID := params.UUID(r.Context(), "id")
+
+	redirect, err := rr.RedirectService.GetRedirectByID(r.Context(), redirectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Redirect Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch redirect data", err)
+		return
+	}
+
+	writeRedirect(w, r, http.StatusOK, redirect)
+}
+
+/*
+CreateRedirect handles HTTP requests to create a new redirect.
+
+Example:
+  - When a POST request is made to `/redirects` with a JSON payload (e.g.,
+    `{"source": "/old-post", "target": "/articles/new-post", "status_code": 301}`),
+    this function will create the redirect and respond with a 201 status along with
+    the redirect data in the response body.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails, the function responds with a 422 status.
+  - If the source is already redirected, the function responds with a 409 status.
+*/
+func (rr *RedirectHandler) CreateRedirect(w http.ResponseWriter, r *http.Request) {
+	var newRedirect models.Redirect
+	if err := decodeJSON(r, &newRedirect); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(newRedirect); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	redirect, err := rr.RedirectService.CreateRedirect(r.Context(), newRedirect)
+	if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Source already redirected", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process redirect data", err)
+		return
+	}
+
+	writeRedirect(w, r, http.StatusCreated, redirect)
+}
+
+/*
+UpdateRedirect handles HTTP requests to update the source, target and status code of
+an existing redirect.
+
+Error Handling:
+  - If the redirect ID is not a valid UUID or the request body is invalid, the
+    function responds with a 400 status.
+  - If the redirect does not exist, the function responds with a 404 status.
+  - If the source is already redirected by another redirect, the function responds
+    with a 409 status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (rr *RedirectHandler) UpdateRedirect(w http.ResponseWriter, r *http.Request) {
+	redirectID := params.UUID(r.Context(), "id")
+
+	var updatedRedirect models.Redirect
+	if err := decodeJSON(r, &updatedRedirect); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(updatedRedirect); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	redirect, err := rr.RedirectService.UpdateRedirect(
+		r.Context(),
+		redirectID,
+		updatedRedirect,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Redirect Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Source already redirected", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process redirect data", err)
+		return
+	}
+
+	writeRedirect(w, r, http.StatusCreated, redirect)
+}
+
+/*
+PatchRedirect handles HTTP requests to partially update an existing redirect with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`).
+
+Example:
+  - Request: PATCH /redirects/{id}
+  - Request Body: `{"status_code": 301}`
+  - Response: the response of `UpdateRedirect` to the patched redirect.
+*/
+func (rr *RedirectHandler) PatchRedirect(w http.ResponseWriter, r *http.Request) {
+	mergePatch(w, r, "Redirect", rr.RedirectService.GetRedirectByID, rr.UpdateRedirect)
+}
+
+/*
+DeleteRedirect handles HTTP requests to delete a redirect by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the redirect ID is not a valid UUID and a 404 status if the redirect does
+not exist.
+*/
+func (rr *RedirectHandler) DeleteRedirect(w http.ResponseWriter, r *http.Request) {
+	redirectID := params.UUID(r.Context(), "id")
+
+	err := rr.RedirectService.DeleteRedirect(r.Context(), redirectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Redirect Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete redirect", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRedirect writes the JSON encoding of the redirect under the key "redirect" with
+// the given status code.
+func writeRedirect(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	redirect models.Redirect,
+) {
+	response := map[string]models.Redirect{
+		"redirect": redirect,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
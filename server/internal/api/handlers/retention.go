@@ -0,0 +1,61 @@
+/*
+Package handlers defines various request handlers, including the retention policy of
+the sites.
+
+The `RetentionHandler` in this file reports what the retention policy would remove
+from a site: the revisions of the articles but their latest ones and the entries of
+the audit log past their retention period. The policy itself is applied by a scheduled
+job of the server.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// RetentionHandler handles HTTP requests related to the retention policy of a site.
+type RetentionHandler struct {
+	RetentionService services.RetentionService
+}
+
+// NewRetentionHandler creates and initializes a new instance of RetentionHandler.
+func NewRetentionHandler(retentionService services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		RetentionService: retentionService,
+	}
+}
+
+/*
+GetRetention handles HTTP requests to report what the retention policy would remove
+from the site if it was applied now (a dry run, nothing is removed).
+
+Example:
+  - Request: GET /retention
+  - Response: HTTP 200 OK with the report under the key "retention", e.g.
+    `{"retention": {"policy": {"revisions_per_article": 100, "audit_log_days": 365},
+    "dry_run": true, "revisions": 3, "articles": [{"article_id": "...", "revisions":
+    3, "numbers": [1, 2, 3]}], "audit_entries": 42, "audit_cutoff": "..."}}`.
+*/
+func (rh *RetentionHandler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	report, err := rh.RetentionService.GetRetentionReport(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to report retention", err)
+		return
+	}
+
+	response := map[string]models.RetentionReport{
+		"retention": report,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Package handlers defines various request handlers, including the editorial review of
+the articles.
+
+The `ReviewHandler` in this file handles the review of the articles: submitting an
+article for review, assigning it a reviewer, and approving it or requesting changes
+on it. The sites may require the articles to be approved before they are published
+(see the `require_review` setting).
+*/
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	validator "github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// ReviewHandler handles HTTP requests related to the review of the articles.
+type ReviewHandler struct {
+	ReviewService services.ReviewService
+}
+
+// NewReviewHandler creates and initializes a new instance of ReviewHandler.
+func NewReviewHandler(reviewService services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		ReviewService: reviewService,
+	}
+}
+
+/*
+GetReview handles HTTP requests to retrieve the review of an article, along with the
+decisions made on it.
+
+Example:
+  - Request: GET /articles/{id}/review
+  - Response: HTTP 200 OK with a JSON body containing the review under the key
+    "review".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist or was never submitted for review, the function
+    responds with a 404 status.
+*/
+func (rh *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	review, err := rh.ReviewService.GetReview(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Review Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch review", err)
+		return
+	}
+
+	writeReview(w, r, review)
+}
+
+/*
+SubmitForReview handles HTTP requests to submit an article for review, either for the
+first time or again once the requested changes are made.
+
+Example:
+  - Request: POST /articles/{id}/submit-for-review
+  - Response: HTTP 200 OK with a JSON body containing the pending review under the
+    key "review".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist, the function responds with a 404 status.
+  - If the article is already pending review, the function responds with a 409
+    status.
+*/
+func (rh *ReviewHandler) SubmitForReview(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	review, err := rh.ReviewService.SubmitForReview(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrReviewState) {
+		http.Error(w, "Article already pending review", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to submit article for review", err)
+		return
+	}
+
+	writeReview(w, r, review)
+}
+
+/*
+AssignReviewer handles HTTP requests to assign a user to review an article submitted
+for review, replacing its previous reviewer if any. Only the reviewer (or an admin)
+can then decide on the article.
+
+Example:
+  - Request: POST /articles/{id}/reviewer with a body like `{"reviewer_id": "..."}`
+  - Response: HTTP 200 OK with a JSON body containing the review under the key
+    "review".
+
+Error Handling:
+  - If the article ID is not a valid UUID or the request body is invalid, the
+    function responds with a 400 status.
+  - If the article does not exist or was never submitted for review, the function
+    responds with a 404 status.
+  - If the request validation fails or the reviewer does not exist, the function
+    responds with a 422 status.
+*/
+func (rh *ReviewHandler) AssignReviewer(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	var req models.ReviewerAssignment
+	if err := decodeJSON(r, &req); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	review, err := rh.ReviewService.AssignReviewer(
+		r.Context(),
+		articleID,
+		req.ReviewerID,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Review Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrReviewerNotFound) {
+		http.Error(w, "Reviewer not found", http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to assign reviewer", err)
+		return
+	}
+
+	writeReview(w, r, review)
+}
+
+/*
+ApproveArticle handles HTTP requests to approve an article pending review for
+publication, at its current version, with an optional comment. The author of the
+article is notified of the decision.
+
+Example:
+  - Request: POST /articles/{id}/approve with a body like `{"comment": "Great read"}`
+  - Response: HTTP 200 OK with a JSON body containing the approved review under the
+    key "review".
+
+Error Handling:
+  - If the article ID is not a valid UUID or the request body is invalid, the
+    function responds with a 400 status.
+  - If the principal is neither the reviewer of the article nor an admin, the
+    function responds with a 403 status.
+  - If the article does not exist or was never submitted for review, the function
+    responds with a 404 status.
+  - If the article is not pending review, the function responds with a 409 status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (rh *ReviewHandler) ApproveArticle(w http.ResponseWriter, r *http.Request) {
+	rh.decide(w, r, rh.ReviewService.Approve, false)
+}
+
+/*
+RequestChanges handles HTTP requests to request changes on an article pending review,
+explained by a comment. The author of the article is notified of the decision, and
+has to submit the article for review again once changed.
+
+Example:
+  - Request: POST /articles/{id}/request-changes with a body like `{"comment":
+    "Please add a conclusion"}`
+  - Response: HTTP 200 OK with a JSON body containing the review under the key
+    "review".
+
+Error Handling:
+  - The function responds like `ApproveArticle` does, and with a 422 status if the
+    comment is missing.
+*/
+func (rh *ReviewHandler) RequestChanges(w http.ResponseWriter, r *http.Request) {
+	rh.decide(w, r, rh.ReviewService.RequestChanges, true)
+}
+
+// decide handles a request deciding on an article pending review with the decide
+// function of the review service, rejecting the requests without any comment if
+// requireComment is set.
+func (rh *ReviewHandler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(context.Context, uuid.UUID, string) (models.Review, error),
+	requireComment bool,
+) {
+	articleID := params.UUID(r.Context(), "id")
+
+	var req models.ReviewDecision
+	if err := decodeJSON(r, &req); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if requireComment && strings.TrimSpace(req.Comment) == "" {
+		http.Error(w, "Comment is required", http.StatusUnprocessableEntity)
+		return
+	}
+
+	review, err := decide(r.Context(), articleID, req.Comment)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Review Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrNotReviewer) {
+		http.Error(w, "Not the reviewer of the article", http.StatusForbidden)
+		return
+	} else if errors.Is(err, services.ErrReviewState) {
+		http.Error(w, "Article not pending review", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to review article", err)
+		return
+	}
+
+	writeReview(w, r, review)
+}
+
+// writeReview answers the request with the review, under the key "review".
+func writeReview(w http.ResponseWriter, r *http.Request, review models.Review) {
+	response := map[string]models.Review{
+		"review": review,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
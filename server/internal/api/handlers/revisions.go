@@ -0,0 +1,198 @@
+/*
+Package handlers defines various request handlers, including the revisions of the
+articles.
+
+The `RevisionHandler` in this file serves the revisions recorded each time an article
+is created or updated, along with the changes each of them made to the article field
+by field (e.g. for the editor to show that the title changed and 2 paragraphs were
+added), and compares any two revisions of an article.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	chi "github.com/go-chi/chi/v5"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// RevisionHandler handles HTTP requests related to the revisions of the articles.
+type RevisionHandler struct {
+	RevisionService services.RevisionService
+}
+
+// NewRevisionHandler creates and initializes a new instance of RevisionHandler.
+func NewRevisionHandler(revisionService services.RevisionService) *RevisionHandler {
+	return &RevisionHandler{
+		RevisionService: revisionService,
+	}
+}
+
+/*
+GetRevisions handles HTTP requests to retrieve the revisions of an article, oldest
+first, each of them holding the article as of the revision and the changes it made.
+
+Example:
+  - Request: GET /articles/{id}/revisions
+  - Response: HTTP 200 OK with a JSON body containing the revisions under the key
+    "revisions".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist, the function responds with a 404 status.
+*/
+func (rh *RevisionHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	revisions, err := rh.RevisionService.GetRevisions(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch revisions", err)
+		return
+	}
+
+	response := map[string][]models.Revision{
+		"revisions": revisions,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetRevisionChanges handles HTTP requests to retrieve the changes made to an article by
+one of its revisions, identified by its number (the version of the article it made).
+Each change holds the old and new values of the field along with a summary of the
+change.
+
+Example:
+  - Request: GET /articles/{id}/revisions/2/changes
+  - Response: HTTP 200 OK with a JSON body containing the changes under the key
+    "changes", e.g. `{"field": "title", "old": "Go", "new": "Go Basics", "summary":
+    "Title changed"}`.
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article or the revision does not exist, the function responds with a 404
+    status.
+*/
+func (rh *RevisionHandler) GetRevisionChanges(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	number, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil || number < 1 {
+		http.Error(w, "Revision Not Found", http.StatusNotFound)
+		return
+	}
+
+	changes, err := rh.RevisionService.GetRevisionChanges(
+		r.Context(),
+		articleID,
+		number,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Revision Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch revision changes", err)
+		return
+	}
+
+	response := map[string][]models.FieldChange{
+		"changes": changes,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetRevisionDiff handles HTTP requests to compare two revisions of an article, given by
+the `from` and `to` query parameters. The differences are reported field by field and,
+if the `unified` query parameter is true, as text in the unified format of `diff -u`
+(the metadata of the article being compared before its content).
+
+Example:
+  - Request: GET /articles/{id}/diff?from=1&to=3&unified=true
+  - Response: HTTP 200 OK with a JSON body containing the differences under the key
+    "diff", e.g. `{"article_id": "...", "from": 1, "to": 3, "changes": [...],
+    "unified": "--- revision 1\n+++ revision 3\n@@ -1,3 +1,3 @@\n..."}`.
+
+Error Handling:
+  - If the article ID is not a valid UUID, a revision number is missing or invalid, or
+    the `unified` query parameter is not a boolean, the function responds with a 400
+    status.
+  - If the article or any of the revisions does not exist, the function responds with
+    a 404 status.
+*/
+func (rh *RevisionHandler) GetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+	query := r.URL.Query()
+
+	var numbers [2]int
+	for i, name := range []string{"from", "to"} {
+		number, err := strconv.Atoi(query.Get(name))
+		if err != nil || number < 1 {
+			http.Error(w, "Invalid "+name+" parameter", http.StatusBadRequest)
+			return
+		}
+		numbers[i] = number
+	}
+
+	unified := false
+	if value := query.Get("unified"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid unified parameter", http.StatusBadRequest)
+			return
+		}
+
+		unified = b
+	}
+
+	diff, err := rh.RevisionService.DiffRevisions(
+		r.Context(),
+		articleID,
+		numbers[0],
+		numbers[1],
+		unified,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Revision Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to compare revisions", err)
+		return
+	}
+
+	response := map[string]models.RevisionDiff{
+		"diff": diff,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Package handlers defines various request handlers, including the answers to the
+requests which do not match any route, or whose method is not allowed on the route
+they match.
+*/
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	chi "github.com/go-chi/chi/v5"
+)
+
+// routeMethods lists the methods the routes are registered for, in the order they are
+// listed in the `Allow` header.
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// NotFound answers the requests which do not match any route with a `404 Not Found`
+// problem (see `models.Problem`).
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(
+		w,
+		r,
+		http.StatusNotFound,
+		fmt.Sprintf("No resource found at %s", r.URL.Path),
+	)
+}
+
+/*
+MethodNotAllowed returns the handler answering the requests whose path matches a route
+of the router but whose method does not, along with an `Allow` header listing the
+methods the route allows:
+  - The `OPTIONS` requests are answered with a `204 No Content` response, so that the
+    clients can discover the methods of every route.
+  - The other requests are answered with a `405 Method Not Allowed` problem (see
+    `models.Problem`).
+
+The full path of the requests is matched against the routes of the router, which
+therefore has to be the root router the server serves (the handler is inherited by its
+subrouters). The routes are collected on the first request, once they are all set up.
+
+Example:
+
+	router.MethodNotAllowed(handlers.MethodNotAllowed(router))
+*/
+func MethodNotAllowed(router chi.Routes) http.HandlerFunc {
+	var once sync.Once
+	var routes *chi.Mux
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			routes = flattenRoutes(router)
+		})
+
+		path := r.URL.RawPath
+		if path == "" {
+			path = r.URL.Path
+		}
+
+		var allowed []string
+		for _, method := range routeMethods {
+			if routes.Match(chi.NewRouteContext(), method, path) {
+				allowed = append(allowed, method)
+			}
+		}
+		allowed = append(allowed, http.MethodOptions)
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeProblem(
+			w,
+			r,
+			http.StatusMethodNotAllowed,
+			fmt.Sprintf("The %s method is not allowed on %s", r.Method, r.URL.Path),
+		)
+	}
+}
+
+/*
+flattenRoutes returns a router holding every route of the router and of its subrouters
+under their full pattern, which (unlike the router itself) only matches a path with
+the methods actually routed for it: the subrouters mounted on the router match every
+method.
+
+The routes registered at the root of a subrouter (e.g. `/articles/`) are also
+registered without their trailing slash, since they serve both paths.
+*/
+func flattenRoutes(router chi.Routes) *chi.Mux {
+	routes := chi.NewRouter()
+	noop := func(http.ResponseWriter, *http.Request) {}
+
+	_ = chi.Walk(router, func(
+		method, route string,
+		_ http.Handler,
+		_ ...func(http.Handler) http.Handler,
+	) error {
+		if !slices.Contains(routeMethods, method) {
+			return nil
+		}
+
+		routes.MethodFunc(method, route, noop)
+		trimmed := strings.TrimSuffix(route, "/")
+		if trimmed != route && trimmed != "" {
+			routes.MethodFunc(method, trimmed, noop)
+		}
+
+		return nil
+	})
+
+	return routes
+}
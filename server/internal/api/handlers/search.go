@@ -0,0 +1,101 @@
+/*
+Package handlers defines various request handlers, including the search of a site.
+
+The `SearchHandler` in this file searches the published articles of a site, returning
+the matches of the search along with snippets of the content of the articles and the
+facets of the articles found, so that the search interfaces do not have to match the
+articles themselves.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/search"
+)
+
+// SearchHandler handles HTTP requests related to the search of a site.
+type SearchHandler struct {
+	ArticleService services.ArticleService
+}
+
+// NewSearchHandler creates and initializes a new instance of SearchHandler.
+func NewSearchHandler(articleService services.ArticleService) *SearchHandler {
+	return &SearchHandler{
+		ArticleService: articleService,
+	}
+}
+
+/*
+Search handles HTTP requests to search the published articles of the site, the most
+relevant first, paged with the `page[number]` and `page[size]` query parameters.
+
+Query Parameters:
+  - `q`: The text searched for, each word of which has to match (as a prefix) a word
+    of the title, the content, the tags or the author of the articles.
+  - `tag`: The tag the articles have to be classified with, if any.
+  - `author`: The author the articles have to be written by, if any.
+
+The response contains the articles found under the key "results", each along with its
+score, the matches of the search in its title, author and tags (by their offsets in
+UTF-16 code units) and a snippet of its content (HTML-escaped, with the matches wrapped
+in `<mark>` elements), the number of articles found with each tag and author under the
+key "facets" and the description of the page under the key "meta".
+
+Example:
+  - Request: GET /search?q=go+basics&tag=tutorial
+  - Response: HTTP 200 OK with e.g. `{"results": [{"article": {...}, "score": 7,
+    "matches": {"title": [{"start": 0, "end": 2}, ...]}, "snippet": "Learn the
+    <mark>basics</mark> of..."}], "facets": {"tags": [{"value": "go", "count": 3}],
+    "authors": [...]}, "meta": {...}}`, along with a `Link` header pointing to the
+    neighbouring pages.
+
+Error Handling:
+  - If the `q` query parameter holds no word, the function responds with a 400 status.
+  - If the page parameters are invalid, the function responds with a 400 status.
+*/
+func (sh *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	query := models.SearchQuery{
+		Text:   params.Get("q"),
+		Tag:    params.Get("tag"),
+		Author: params.Get("author"),
+	}
+	if len(search.Terms(query.Text)) == 0 {
+		http.Error(w, "Missing search query", http.StatusBadRequest)
+		return
+	}
+
+	page, err := pagination.ParsePage(params)
+	if err != nil {
+		http.Error(w, "Invalid page parameters", http.StatusBadRequest)
+		return
+	}
+
+	results, total, facets, err := sh.ArticleService.Search(r.Context(), query, page)
+	if err != nil {
+		serverError(w, r, "Failed to search articles", err)
+		return
+	}
+
+	meta := pagination.NewMeta(page, total)
+	response := map[string]any{
+		"results": results,
+		"facets":  facets,
+		"meta":    meta,
+	}
+
+	w.Header().Set("Link", meta.Links(r.URL))
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Package handlers defines various request handlers, including the browser sessions of
+the management API.
+
+The `SessionHandler` in this file opens, describes and closes the sessions through which
+the embedded admin interface (see the `adminui` package) authenticates its requests
+with a cookie rather than with an API key.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/session"
+)
+
+// SessionHandler handles HTTP requests related to the browser sessions of the
+// management API.
+type SessionHandler struct {
+	Sessions *session.Store
+}
+
+// NewSessionHandler creates and initializes a new instance of SessionHandler.
+func NewSessionHandler(sessions *session.Store) *SessionHandler {
+	return &SessionHandler{
+		Sessions: sessions,
+	}
+}
+
+/*
+CreateSession handles HTTP requests to open a browser session with the API key the
+request is authenticated with, e.g. when signing in to the admin interface.
+
+The token of the session is set in an `HttpOnly` cookie, which authenticates the
+following requests of the browser (see `middleware.Authenticate`), and its CSRF token,
+which its write requests have to present in the `X-CSRF-Token` header, is returned in
+the response. The session previously opened by the browser, if any, is closed.
+
+Example:
+  - Request: POST /session with the `Authorization: Bearer <API key>` header.
+  - Response: HTTP 201 Created with the session under the key "session", e.g.
+    `{"session": {"role": "editor", "csrf_token": "...", "expires_at": "...", ...}}`,
+    along with the `Set-Cookie` header.
+
+Error Handling:
+  - If the request is not authenticated with an API key, the function responds with a
+    400 status.
+*/
+func (sh *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	key := auth.KeyFromHeader(r.Header)
+	if key == "" {
+		http.Error(w, "An API key is required to sign in", http.StatusBadRequest)
+		return
+	}
+
+	if cookie, err := r.Cookie(session.CookieName); err == nil {
+		sh.Sessions.Delete(cookie.Value)
+	}
+
+	token, s, err := sh.Sessions.Create(key)
+	if err != nil {
+		serverError(w, r, "Unable to open session", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     session.CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  s.ExpiresAt,
+		HttpOnly: true,
+		Secure:   isSecure(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	writeSession(w, r, http.StatusCreated, s)
+}
+
+/*
+GetSession handles HTTP requests to describe the browser session the request is
+authenticated with, e.g. when the admin interface is reloaded, along with its CSRF
+token.
+
+Error Handling:
+  - If the request does not present the cookie of an open session, the function
+    responds with a 404 status.
+*/
+func (sh *SessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(session.CookieName)
+	if err != nil {
+		http.Error(w, "Session Not Found", http.StatusNotFound)
+		return
+	}
+
+	s, ok := sh.Sessions.Get(cookie.Value)
+	if !ok {
+		http.Error(w, "Session Not Found", http.StatusNotFound)
+		return
+	}
+
+	writeSession(w, r, http.StatusOK, s)
+}
+
+/*
+DeleteSession handles HTTP requests to close the browser session the request is
+authenticated with, e.g. when signing out of the admin interface, and to clear its
+cookie.
+
+The function responds with an HTTP 204 (No Content) status code, whether a session was
+open or not.
+*/
+func (sh *SessionHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(session.CookieName); err == nil {
+		sh.Sessions.Delete(cookie.Value)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     session.CookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecure(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSession writes the JSON encoding of the session, described with the principal
+// the request is authenticated as, under the key "session" with the given status code.
+func writeSession(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	s session.Session,
+) {
+	principal, _ := auth.FromContext(r.Context())
+
+	response := map[string]models.Session{
+		"session": {
+			SiteID:    principal.SiteID,
+			UserID:    principal.UserID,
+			Role:      principal.Role,
+			Root:      principal.Root,
+			CSRFToken: s.CSRFToken,
+			ExpiresAt: s.ExpiresAt,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+// isSecure reports whether the request was made over HTTPS, to the server or to the
+// reverse proxy in front of it.
+func isSecure(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,104 @@
+/*
+Package handlers defines various request handlers, including the settings of a site.
+
+The `SettingsHandler` in this file serves and updates the site-wide configuration of a
+site, e.g. its title, its default locale or its comment policy.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// SettingsHandler handles HTTP requests related to the settings of a site.
+type SettingsHandler struct {
+	SettingsService services.SettingsService
+}
+
+// NewSettingsHandler creates and initializes a new instance of SettingsHandler.
+func NewSettingsHandler(settingsService services.SettingsService) *SettingsHandler {
+	return &SettingsHandler{
+		SettingsService: settingsService,
+	}
+}
+
+/*
+GetSettings handles HTTP requests to retrieve the settings of the site, with the
+default value of every field which is not set.
+
+Example:
+  - Request: GET /settings
+  - Response: HTTP 200 OK with the settings under the key "settings".
+*/
+func (sr *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := sr.SettingsService.GetSettings(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch settings", err)
+		return
+	}
+
+	writeSettings(w, r, http.StatusOK, settings)
+}
+
+/*
+UpdateSettings handles HTTP requests to update the settings of the site. Only the
+fields present in the request body are updated, an empty title resetting the title of
+the site to its name.
+
+Example:
+  - When a PATCH request is made to `/settings` with a JSON payload (e.g.,
+    `{"comment_policy": "closed", "timezone": "Europe/Berlin"}`), this function will
+    update the settings and respond with a 201 status along with the effective
+    settings in the response body.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails (e.g. an unknown time zone), the function
+    responds with a 422 status.
+*/
+func (sr *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var patch models.SiteSettingsPatch
+	if err := decodeJSON(r, &patch); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(patch); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	settings, err := sr.SettingsService.UpdateSettings(r.Context(), patch)
+	if err != nil {
+		serverError(w, r, "Unable to update settings", err)
+		return
+	}
+
+	writeSettings(w, r, http.StatusCreated, settings)
+}
+
+// writeSettings writes the JSON encoding of the settings under the key "settings" with
+// the given status code.
+func writeSettings(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	settings models.SiteSettings,
+) {
+	response := map[string]models.SiteSettings{
+		"settings": settings,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
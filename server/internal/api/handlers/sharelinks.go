@@ -0,0 +1,205 @@
+/*
+Package handlers defines various request handlers, including the share links of the
+articles.
+
+The `ShareLinkHandler` in this file handles the management of the expiring links
+sharing an article (typically a draft) with readers who can not access the management
+API, and serves the articles shared by these links on the public API.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	chi "github.com/go-chi/chi/v5"
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// ShareLinkHandler handles HTTP requests related to the share links of the articles.
+type ShareLinkHandler struct {
+	ShareLinkService services.ShareLinkService
+}
+
+// NewShareLinkHandler creates and initializes a new instance of ShareLinkHandler.
+func NewShareLinkHandler(shareLinkService services.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		ShareLinkService: shareLinkService,
+	}
+}
+
+/*
+GetShareLinks handles HTTP requests to retrieve the outstanding share links of an
+article, i.e. the links which are neither expired nor revoked.
+
+Example:
+  - Request: GET /articles/{id}/share
+  - Response: HTTP 200 OK with a JSON body containing the share links under the key
+    "share_links".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist, the function responds with a 404 status.
+*/
+func (lr *ShareLinkHandler) GetShareLinks(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	links, err := lr.ShareLinkService.GetShareLinks(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch share links", err)
+		return
+	}
+
+	response := map[string][]models.ShareLink{
+		"share_links": links,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+CreateShareLink handles HTTP requests to create a new link sharing an article.
+
+Example:
+  - When a POST request is made to `/articles/{id}/share` with a JSON payload (e.g.,
+    `{"expires_in": 86400, "one_time": true}`), this function will create the link and
+    respond with a 201 status along with the link in the response body. The plain
+    text token of the link is returned under the key "token" by this response only;
+    the article is then served on the public API at `/share/{token}`.
+
+Error Handling:
+  - If the article ID is not a valid UUID or the request body is invalid, the
+    function responds with a 400 status.
+  - If the article does not exist, the function responds with a 404 status.
+  - If the request validation fails or the lifetime exceeds the maximum lifetime of
+    the share links, the function responds with a 422 status.
+*/
+func (lr *ShareLinkHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	var req models.ShareLinkRequest
+	if err := decodeJSON(r, &req); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	link, token, err := lr.ShareLinkService.CreateShareLink(
+		r.Context(),
+		articleID,
+		time.Duration(req.ExpiresIn)*time.Second,
+		req.OneTime,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrLifetimeExceeded) {
+		http.Error(w, "Lifetime exceeds the maximum", http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to create share link", err)
+		return
+	}
+
+	response := map[string]any{
+		"share_link": link,
+		"token":      token,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+RevokeShareLink handles HTTP requests to revoke a share link of an article.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the article or link ID is not a valid UUID and a 404 status if the link does
+not exist.
+*/
+func (lr *ShareLinkHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	linkID := params.UUID(r.Context(), "linkID")
+
+	err := lr.ShareLinkService.RevokeShareLink(r.Context(), articleID, linkID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Share Link Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to revoke share link", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+GetSharedArticle handles HTTP requests for the article shared by a link, on the public
+API, whether the article is published or not.
+
+The response is never cached nor indexed, since the link may expire or be used only
+once.
+
+Example:
+  - Request: GET /share/{token}
+  - Response: HTTP 200 OK with a JSON body containing the article under the key
+    "article".
+
+Error Handling:
+  - If the link is unknown, expired, revoked or already used, the function responds
+    with a 404 status.
+*/
+func (lr *ShareLinkHandler) GetSharedArticle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	article, err := lr.ShareLinkService.GetSharedArticle(
+		r.Context(),
+		chi.URLParam(r, "token"),
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Share Link Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch shared article", err)
+		return
+	}
+
+	response := map[string]models.Article{
+		"article": article,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,381 @@
+/*
+Package handlers defines various request handlers, including site-related operations.
+
+The `SiteHandler` in this file handles the management of the sites (tenants) served by
+the deployment, such as creating a new site, mapping new hostnames to an existing one
+or verifying the ownership of its custom domains.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	chi "github.com/go-chi/chi/v5"
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// SiteHandler handles HTTP requests related to the sites served by the deployment.
+type SiteHandler struct {
+	SiteService services.SiteService
+}
+
+/*
+NewSiteHandler creates and initializes a new instance of SiteHandler.
+
+This function returns a new `SiteHandler` instance, which is ready to handle
+site-related HTTP requests.
+*/
+func NewSiteHandler(siteService services.SiteService) *SiteHandler {
+	return &SiteHandler{
+		SiteService: siteService,
+	}
+}
+
+/*
+GetAllSites handles HTTP requests to retrieve the list of sites.
+
+The response contains a JSON array of sites under the key "sites" along with an HTTP
+200 (OK) status code. If the sites can not be fetched or encoded, an HTTP 500 (Internal
+Server Error) status is returned instead.
+*/
+func (sr *SiteHandler) GetAllSites(w http.ResponseWriter, r *http.Request) {
+	sites, err := sr.SiteService.GetAllSites(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch sites", err)
+		return
+	}
+
+	response := map[string][]models.Site{
+		"sites": sites,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetSiteByID handles HTTP requests to retrieve a site by its ID.
+
+Error Handling:
+  - If the site ID is not a valid UUID, the function responds with a 400 status.
+  - If the site does not exist, the function responds with a 404 status.
+  - If the site can not be fetched or encoded, the function responds with a 500
+    status.
+*/
+func (sr *SiteHandler) GetSiteByID(w http.ResponseWriter, r *http.Request) {
+	siteID := params.UUID(r.Context(), "id")
+
+	site, err := sr.SiteService.GetSiteByID(r.Context(), siteID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Site Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch site data", err)
+		return
+	}
+
+	response := map[string]models.Site{
+		"site": site,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+CreateSite handles HTTP requests to create a new site.
+
+Example:
+  - When a POST request is made to `/sites` with a JSON payload (e.g.,
+    `{"name": "Weburz Blog", "slug": "weburz", "hostnames": ["blog.weburz.com"]}`),
+    this function will create the site and respond with a 201 status along with the
+    site data in the response body.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails, the function responds with a 422 status.
+  - If the slug or one of the hostnames is already taken by another site, the
+    function responds with a 409 status.
+  - If the site can not be created, the function responds with a 500 status.
+*/
+func (sr *SiteHandler) CreateSite(w http.ResponseWriter, r *http.Request) {
+	validate := validator.New()
+
+	var newSite models.Site
+	if err := decodeJSON(r, &newSite); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(newSite); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	site, err := sr.SiteService.CreateSite(
+		r.Context(),
+		newSite.Name,
+		newSite.Slug,
+		newSite.Hostnames,
+		newSite.Quota,
+	)
+	if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Site slug or hostname already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process site data", err)
+		return
+	}
+
+	response := map[string]models.Site{
+		"site": site,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusCreated)
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+UpdateSite handles HTTP requests to update the name, slug and hostnames of an existing
+site.
+
+Error Handling:
+  - If the site ID is not a valid UUID or the request body is invalid, the function
+    responds with a 400 status.
+  - If the site does not exist, the function responds with a 404 status.
+  - If the slug or one of the hostnames is already taken by another site, the
+    function responds with a 409 status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (sr *SiteHandler) UpdateSite(w http.ResponseWriter, r *http.Request) {
+	validate := validator.New()
+
+	siteID := params.UUID(r.Context(), "id")
+
+	var updatedSite models.Site
+	if err := decodeJSON(r, &updatedSite); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(updatedSite); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	site, err := sr.SiteService.UpdateSite(
+		r.Context(),
+		siteID,
+		updatedSite.Name,
+		updatedSite.Slug,
+		updatedSite.Hostnames,
+		updatedSite.Quota,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Site Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Site slug or hostname already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process site data", err)
+		return
+	}
+
+	response := map[string]models.Site{
+		"site": site,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusCreated)
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+PatchSite handles HTTP requests to partially update an existing site with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`).
+
+Example:
+  - Request: PATCH /sites/{id}
+  - Request Body: `{"name": "Weburz"}`
+  - Response: the response of `UpdateSite` to the patched site.
+*/
+func (sr *SiteHandler) PatchSite(w http.ResponseWriter, r *http.Request) {
+	mergePatch(w, r, "Site", sr.SiteService.GetSiteByID, sr.UpdateSite)
+}
+
+/*
+DeleteSite handles HTTP requests to delete a site by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the site ID is not a valid UUID and a 404 status if the site does not exist.
+*/
+func (sr *SiteHandler) DeleteSite(w http.ResponseWriter, r *http.Request) {
+	siteID := params.UUID(r.Context(), "id")
+
+	err := sr.SiteService.DeleteSite(r.Context(), siteID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Site Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete site data", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+AddDomain handles HTTP requests to map a new custom domain to a site.
+
+Example:
+  - When a POST request is made to `/sites/{id}/domains` with a JSON payload (e.g.,
+    `{"name": "blog.weburz.com"}`), this function will map the (unverified) domain
+    to the site and respond with a 201 status along with the domain in the response
+    body. Its `verification_token` has to be published in a
+    `_burzcontent.blog.weburz.com` TXT record as
+    `burzcontent-verification=<token>` before verifying the domain.
+
+Error Handling:
+  - If the site ID is not a valid UUID or the request body is invalid, the function
+    responds with a 400 status.
+  - If the site does not exist, the function responds with a 404 status.
+  - If the domain is already mapped to a site, the function responds with a 409
+    status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (sr *SiteHandler) AddDomain(w http.ResponseWriter, r *http.Request) {
+	validate := validator.New()
+
+	siteID := params.UUID(r.Context(), "id")
+
+	var newDomain models.Domain
+	if err := decodeJSON(r, &newDomain); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validate.Struct(newDomain); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	domain, err := sr.SiteService.AddDomain(r.Context(), siteID, newDomain.Name)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Site Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Domain already taken", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process domain data", err)
+		return
+	}
+
+	response := map[string]models.Domain{
+		"domain": domain,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+VerifyDomain handles HTTP requests to verify the ownership of a custom domain of a site
+through its DNS TXT record. Once verified, the domain resolves to the site and is used
+to build the canonical URLs of the site.
+
+Error Handling:
+  - If the site ID is not a valid UUID, the function responds with a 400 status.
+  - If the site does not exist or the domain is not mapped to it, the function
+    responds with a 404 status.
+  - If the TXT record of the domain does not hold the verification token, the
+    function responds with a 422 status.
+*/
+func (sr *SiteHandler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	siteID := params.UUID(r.Context(), "id")
+
+	domain, err := sr.SiteService.VerifyDomain(
+		r.Context(),
+		siteID,
+		chi.URLParam(r, "domain"),
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Domain Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrDomainNotVerified) {
+		http.Error(w, "Domain ownership not verified", http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to verify domain", err)
+		return
+	}
+
+	response := map[string]models.Domain{
+		"domain": domain,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+RemoveDomain handles HTTP requests to unmap a custom domain from a site.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the site ID is not a valid UUID and a 404 status if the site does not exist
+or the domain is not mapped to it.
+*/
+func (sr *SiteHandler) RemoveDomain(w http.ResponseWriter, r *http.Request) {
+	siteID := params.UUID(r.Context(), "id")
+
+	err := sr.SiteService.RemoveDomain(r.Context(), siteID, chi.URLParam(r, "domain"))
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Domain Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete domain data", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusNoContent)
+}
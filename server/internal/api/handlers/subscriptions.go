@@ -0,0 +1,267 @@
+/*
+Package handlers defines various request handlers, including the subscriptions of the
+users to the comments of the articles.
+
+The `SubscriptionHandler` in this file lets the users follow the comments of the
+articles, list and mute their subscriptions, and list the in-app notifications they
+were sent of the new comments. Subscriptions are personal: they require an API key
+owned by a user.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// SubscriptionHandler handles HTTP requests related to the subscriptions of the users
+// to the comments of the articles and to their notifications.
+type SubscriptionHandler struct {
+	SubscriptionService services.SubscriptionService
+}
+
+// NewSubscriptionHandler creates and initializes a new instance of SubscriptionHandler.
+func NewSubscriptionHandler(
+	subscriptionService services.SubscriptionService,
+) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		SubscriptionService: subscriptionService,
+	}
+}
+
+/*
+Subscribe handles HTTP requests to subscribe the user of the request to the comments of
+an article, notifying them (in-app and by email) of every new comment on it. Subscribing
+to an article already followed returns the existing subscription.
+
+Example:
+  - Request: POST /articles/{id}/subscribe
+  - Response: HTTP 200 OK with a JSON body containing the subscription under the key
+    "subscription".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the article does not exist, the function responds with a 404 status.
+*/
+func (sh *SubscriptionHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	subscription, err := sh.SubscriptionService.Subscribe(r.Context(), articleID)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to subscribe to article", err)
+		return
+	}
+
+	writeSubscription(w, r, subscription)
+}
+
+/*
+Unsubscribe handles HTTP requests to unsubscribe the user of the request from the
+comments of an article.
+
+Example:
+  - Request: DELETE /articles/{id}/subscribe
+  - Response: HTTP 204 No Content.
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the user does not follow the article, the function responds with a 404 status.
+*/
+func (sh *SubscriptionHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	err := sh.SubscriptionService.Unsubscribe(r.Context(), articleID)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Subscription Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to unsubscribe from article", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+GetSubscriptions handles HTTP requests to retrieve the subscriptions of the user of the
+request, muted or not.
+
+Example:
+  - Request: GET /subscriptions
+  - Response: HTTP 200 OK with a JSON body containing the subscriptions under the key
+    "subscriptions".
+
+Error Handling:
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+*/
+func (sh *SubscriptionHandler) GetSubscriptions(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	subscriptions, err := sh.SubscriptionService.GetSubscriptions(r.Context())
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch subscriptions", err)
+		return
+	}
+
+	response := map[string][]models.Subscription{
+		"subscriptions": subscriptions,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+MuteSubscription handles HTTP requests to mute a subscription of the user of the
+request: they are no longer notified of the new comments on the article, but still
+follow it.
+
+Example:
+  - Request: POST /subscriptions/{id}/mute
+  - Response: HTTP 200 OK with a JSON body containing the subscription under the key
+    "subscription".
+
+Error Handling:
+  - If the subscription ID is not a valid UUID, the function responds with a 400
+    status.
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+  - If the user has no such subscription, the function responds with a 404 status.
+*/
+func (sh *SubscriptionHandler) MuteSubscription(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	sh.setMuted(w, r, true)
+}
+
+/*
+UnmuteSubscription handles HTTP requests to unmute a subscription of the user of the
+request, who is notified of the new comments on the article again.
+
+Example:
+  - Request: POST /subscriptions/{id}/unmute
+  - Response: HTTP 200 OK with a JSON body containing the subscription under the key
+    "subscription".
+
+Error Handling:
+  - The function responds like `MuteSubscription` does.
+*/
+func (sh *SubscriptionHandler) UnmuteSubscription(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	sh.setMuted(w, r, false)
+}
+
+// setMuted handles a request muting or unmuting a subscription of the user of the
+// request.
+func (sh *SubscriptionHandler) setMuted(
+	w http.ResponseWriter,
+	r *http.Request,
+	muted bool,
+) {
+	id := params.UUID(r.Context(), "id")
+
+	subscription, err := sh.SubscriptionService.SetMuted(r.Context(), id, muted)
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Subscription Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to update subscription", err)
+		return
+	}
+
+	writeSubscription(w, r, subscription)
+}
+
+/*
+GetNotifications handles HTTP requests to retrieve the in-app notifications of the user
+of the request, newest first, e.g. of the new comments on the articles they follow.
+
+Example:
+  - Request: GET /notifications
+  - Response: HTTP 200 OK with a JSON body containing the notifications under the key
+    "notifications".
+
+Error Handling:
+  - If the API key of the request is not owned by any user, the function responds with
+    a 403 status.
+*/
+func (sh *SubscriptionHandler) GetNotifications(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	notifications, err := sh.SubscriptionService.GetNotifications(r.Context())
+	if errors.Is(err, services.ErrNoUser) {
+		http.Error(w, "API key not owned by any user", http.StatusForbidden)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch notifications", err)
+		return
+	}
+
+	response := map[string][]models.Notification{
+		"notifications": notifications,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+// writeSubscription answers the request with the subscription, under the key
+// "subscription".
+func writeSubscription(
+	w http.ResponseWriter,
+	r *http.Request,
+	subscription models.Subscription,
+) {
+	response := map[string]models.Subscription{
+		"subscription": subscription,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Package handlers defines various request handlers, including the tags of a site.
+
+The `TagHandler` in this file serves the tags the published articles of a site are
+classified with, e.g. to render a tag cloud.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+)
+
+// TagHandler handles HTTP requests related to the tags of a site.
+type TagHandler struct {
+	ArticleService services.ArticleService
+}
+
+// NewTagHandler creates and initializes a new instance of TagHandler.
+func NewTagHandler(articleService services.ArticleService) *TagHandler {
+	return &TagHandler{
+		ArticleService: articleService,
+	}
+}
+
+/*
+GetTags handles HTTP requests to retrieve the tags of the published articles of the
+site, sorted by decreasing number of articles.
+
+The response contains a JSON array of tag names under the key "tags". When the
+`include_counts` query parameter is `true`, the array holds the tags along with their
+counts instead, and how much they grew over the trending window given by the `window`
+query parameter (e.g. `30d` or `24h`, 7 days by default).
+
+Example:
+  - Request: GET /tags?include_counts=true&window=30d
+  - Response: HTTP 200 OK with e.g. `{"tags": [{"name": "go", "count": 12, "delta":
+    3}, ...]}`.
+
+Error Handling:
+  - If the `include_counts` query parameter is not a boolean or the window is invalid,
+    the function responds with a 400 status.
+*/
+func (tr *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	includeCounts := false
+	if value := query.Get("include_counts"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid include_counts parameter", http.StatusBadRequest)
+			return
+		}
+
+		includeCounts = b
+	}
+
+	window, err := parsePeriod(query.Get("window"))
+	if err != nil {
+		http.Error(w, "Invalid window", http.StatusBadRequest)
+		return
+	}
+
+	tags, err := tr.ArticleService.GetTags(r.Context(), window)
+	if err != nil {
+		serverError(w, r, "Failed to fetch tags", err)
+		return
+	}
+
+	var response any = map[string][]models.Tag{"tags": tags}
+	if !includeCounts {
+		names := make([]string, len(tags))
+		for i, tag := range tags {
+			names[i] = tag.Name
+		}
+
+		response = map[string][]string{"tags": names}
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
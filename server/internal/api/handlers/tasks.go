@@ -0,0 +1,145 @@
+/*
+Package handlers defines various request handlers, including the background task queue
+of the server.
+
+The `TaskHandler` in this file reports the state of the queue running the slow work of
+the server in the background (e.g. sending the emails or the webmentions of the
+articles), and manages its dead-letter list: the tasks which kept failing, which can be
+retried or discarded.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+)
+
+// TaskHandler handles HTTP requests related to the background tasks of the server.
+type TaskHandler struct {
+	Queue *queue.Queue
+}
+
+// NewTaskHandler creates and initializes a new instance of TaskHandler.
+func NewTaskHandler(tasks *queue.Queue) *TaskHandler {
+	return &TaskHandler{
+		Queue: tasks,
+	}
+}
+
+/*
+GetStats handles HTTP requests to retrieve the state of the task queue of the server.
+
+Example:
+  - Request: GET /tasks
+  - Response: HTTP 200 OK with the state under the key "tasks", e.g. `{"tasks":
+    {"workers": 4, "pending": 0, "running": 1, "retrying": 2, "dead": 1,
+    "succeeded": 120, "failed": 7}}`.
+*/
+func (th *TaskHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := th.Queue.Stats(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch task queue state", err)
+		return
+	}
+
+	response := map[string]queue.Stats{
+		"tasks": stats,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetDeadTasks handles HTTP requests to retrieve the dead-letter list of the task queue,
+most recent first.
+
+Example:
+  - Request: GET /tasks/dead
+  - Response: HTTP 200 OK with the tasks under the key "tasks", e.g. `{"tasks":
+    [{"id": "...", "kind": "email.send", "payload": {...}, "attempts": 5,
+    "enqueued_at": "...", "last_error": "...", "failed_at": "..."}]}`.
+*/
+func (th *TaskHandler) GetDeadTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := th.Queue.DeadLetters(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch dead tasks", err)
+		return
+	}
+
+	response := map[string][]queue.Task{
+		"tasks": tasks,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+RetryTask handles HTTP requests to move a task of the dead-letter list back to the
+queue, to be run again as a new task would.
+
+The function responds with the task under the key "task" and an HTTP 202 (Accepted)
+status code on success, a 400 status if the task ID is not a valid UUID and a 404
+status if the task is not in the dead-letter list.
+*/
+func (th *TaskHandler) RetryTask(w http.ResponseWriter, r *http.Request) {
+	taskID := params.UUID(r.Context(), "id")
+
+	task, err := th.Queue.Retry(r.Context(), taskID)
+	if errors.Is(err, queue.ErrNotFound) {
+		http.Error(w, "Task Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to retry task", err)
+		return
+	}
+
+	response := map[string]queue.Task{
+		"task": task,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+DiscardTask handles HTTP requests to remove a task from the dead-letter list for good.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the task ID is not a valid UUID and a 404 status if the task is not in the
+dead-letter list.
+*/
+func (th *TaskHandler) DiscardTask(w http.ResponseWriter, r *http.Request) {
+	taskID := params.UUID(r.Context(), "id")
+
+	err := th.Queue.Discard(r.Context(), taskID)
+	if errors.Is(err, queue.ErrNotFound) {
+		http.Error(w, "Task Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to discard task", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,202 @@
+/*
+Package handlers defines various request handlers, including the template bundles of a
+site.
+
+The `TemplateHandler` in this file handles the versions of the templates a site
+overrides the default templates of the server with, e.g. to brand the emails it sends:
+their upload, their activation and the rollback to a previous version.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// TemplateHandler handles HTTP requests related to the template bundles of a site.
+type TemplateHandler struct {
+	TemplateService services.TemplateService
+	MaxBundleSize   int64 // The largest template bundle which can be uploaded, in bytes
+}
+
+// NewTemplateHandler creates and initializes a new instance of TemplateHandler,
+// accepting the template bundles up to the given size (in bytes).
+func NewTemplateHandler(
+	templateService services.TemplateService,
+	maxBundleSize int64,
+) *TemplateHandler {
+	return &TemplateHandler{
+		TemplateService: templateService,
+		MaxBundleSize:   maxBundleSize,
+	}
+}
+
+/*
+GetAllBundles handles HTTP requests to retrieve the list of template bundles of the
+site, in version order.
+
+The response contains a JSON array of bundles under the key "bundles" along with an
+HTTP 200 (OK) status code.
+*/
+func (tr *TemplateHandler) GetAllBundles(w http.ResponseWriter, r *http.Request) {
+	bundles, err := tr.TemplateService.GetAllBundles(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch template bundles", err)
+		return
+	}
+
+	response := map[string][]models.TemplateBundle{
+		"bundles": bundles,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+UploadBundle handles HTTP requests to upload a new version of the templates of the
+site, which is not used until it is activated.
+
+The bundle is a zip archive of templates named after the default templates they
+override (e.g. "layout.html" or "contact.txt"), either the body of the request or the
+`file` field of a `multipart/form-data` body (named with the `.zip` extension), and can
+not exceed the configured size (8 MiB by default). The `description` query parameter
+describes the version.
+
+Example:
+  - Request: POST /admin/templates?description=Rebranding
+  - Response: HTTP 201 Created with the bundle under the key "bundle", e.g.
+    `{"bundle": {"version": 3, "files": ["layout.html"], "active": false, ...}}`.
+
+Error Handling:
+  - If the archive is missing, too large or not a zip archive, the function responds
+    with the details of the problem and a 400, 413 or 415 status (see `readUpload`).
+  - If the archive is not a zip archive of valid templates overriding the default
+    templates, the function responds with a 422 status.
+*/
+func (tr *TemplateHandler) UploadBundle(w http.ResponseWriter, r *http.Request) {
+	archive, ok := readUpload(w, r, uploadKind{
+		name:       "template bundle",
+		types:      []string{"application/zip"},
+		extensions: []string{".zip"},
+		maxSize:    tr.MaxBundleSize,
+	})
+	if !ok {
+		return
+	}
+
+	bundle, err := tr.TemplateService.UploadBundle(
+		r.Context(),
+		archive,
+		r.URL.Query().Get("description"),
+	)
+	if errors.Is(err, services.ErrInvalidBundle) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to upload template bundle", err)
+		return
+	}
+
+	writeBundle(w, r, http.StatusCreated, &bundle)
+}
+
+/*
+ActivateBundle handles HTTP requests to make a template bundle the one used by the
+site, in place of the bundle active until then (if any).
+
+Error Handling:
+  - If the bundle ID is not a valid UUID, the function responds with a 400 status.
+  - If the bundle does not exist, the function responds with a 404 status.
+*/
+func (tr *TemplateHandler) ActivateBundle(w http.ResponseWriter, r *http.Request) {
+	bundleID := params.UUID(r.Context(), "id")
+
+	bundle, err := tr.TemplateService.ActivateBundle(r.Context(), bundleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Template Bundle Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to activate template bundle", err)
+		return
+	}
+
+	writeBundle(w, r, http.StatusOK, &bundle)
+}
+
+/*
+Rollback handles HTTP requests to roll the templates of the site back to the version
+preceding the active template bundle.
+
+The response holds the bundle activated under the key "bundle", which is null when the
+site rolled back to the default templates. The function responds with a 409 status if
+the site already uses the default templates.
+*/
+func (tr *TemplateHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	bundle, err := tr.TemplateService.Rollback(r.Context())
+	if errors.Is(err, services.ErrNoActiveBundle) {
+		http.Error(w, "No active template bundle", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to roll back template bundles", err)
+		return
+	}
+
+	writeBundle(w, r, http.StatusOK, bundle)
+}
+
+/*
+DeleteBundle handles HTTP requests to delete a template bundle by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the bundle ID is not a valid UUID, a 404 status if the bundle does not exist
+and a 409 status if the bundle is the active one.
+*/
+func (tr *TemplateHandler) DeleteBundle(w http.ResponseWriter, r *http.Request) {
+	bundleID := params.UUID(r.Context(), "id")
+
+	err := tr.TemplateService.DeleteBundle(r.Context(), bundleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Template Bundle Not Found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, services.ErrBundleActive) {
+		http.Error(w, "Template bundle is active", http.StatusConflict)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete template bundle", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeBundle writes the JSON encoding of the template bundle (null if nil) under the
+// key "bundle" with the given status code.
+func writeBundle(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	bundle *models.TemplateBundle,
+) {
+	response := map[string]*models.TemplateBundle{
+		"bundle": bundle,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
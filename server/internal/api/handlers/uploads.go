@@ -0,0 +1,213 @@
+/*
+Package handlers defines various request handlers, including the validation of the
+files uploaded to them.
+
+The files uploaded to the management API (template bundles, WXR files and backup
+archives) are validated before being processed: their type is sniffed from their
+content rather than trusted from the client, they can not exceed the size limit of
+their kind (see `UploadLimits`) and their name, if given, has to bear one of the
+extensions of their kind. The executables and the SVG images holding scripts are
+rejected whatever the kind of the upload. The rejected uploads are answered with the
+details of the problem (RFC 9457).
+*/
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// executableMagics lists the signatures the executables start with (PE, ELF and
+// Mach-O binaries, and scripts).
+var executableMagics = [][]byte{
+	[]byte("MZ"),
+	[]byte("\x7fELF"),
+	[]byte("\xfe\xed\xfa\xce"),
+	[]byte("\xfe\xed\xfa\xcf"),
+	[]byte("\xce\xfa\xed\xfe"),
+	[]byte("\xcf\xfa\xed\xfe"),
+	[]byte("\xca\xfe\xba\xbe"),
+	[]byte("#!"),
+}
+
+// svgScriptPattern matches the scripts of an SVG image: its `<script>` elements, its
+// event handlers and its `javascript:` URLs.
+var svgScriptPattern = regexp.MustCompile(`(?i)<script|\son[a-z]+\s*=|javascript:`)
+
+/*
+UploadLimits holds the maximum sizes of the files uploaded to the management API, in
+bytes.
+
+Fields:
+  - Bundle: The maximum size of the template bundles (8 MiB if zero).
+  - Import: The maximum size of the imported files, e.g. the WXR files (64 MiB if
+    zero).
+  - Backup: The maximum size of the backup archives (256 MiB if zero).
+*/
+type UploadLimits struct {
+	Bundle int64
+	Import int64
+	Backup int64
+}
+
+/*
+uploadKind describes a kind of uploaded file.
+
+Fields:
+  - name: The name of the kind, e.g. "template bundle".
+  - types: The media types sniffed from the content the files may have.
+  - extensions: The extensions the names of the files may bear, e.g. ".zip".
+  - maxSize: The maximum size of the files, in bytes.
+*/
+type uploadKind struct {
+	name       string
+	types      []string
+	extensions []string
+	maxSize    int64
+}
+
+/*
+readUpload reads the file of the given kind uploaded with the request, either its body
+or the `file` field of its `multipart/form-data` body, and validates it.
+
+If the file is missing or invalid, the request is answered with the details of the
+problem and false is returned:
+  - If the file is missing or can not be read, with a 400 status.
+  - If the file is larger than the maximum size of its kind, with a 413 status.
+  - If the file is an executable or an SVG image holding scripts, if its sniffed type
+    is not one of its kind, or if its name does not bear one of the extensions of its
+    kind, with a 415 status.
+*/
+func readUpload(
+	w http.ResponseWriter,
+	r *http.Request,
+	kind uploadKind,
+) ([]byte, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, kind.maxSize)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		part, header, err := r.FormFile("file")
+		if err != nil {
+			uploadError(w, r, kind, err, "Missing "+kind.name)
+			return nil, false
+		}
+		defer part.Close()
+
+		ext := strings.ToLower(filepath.Ext(header.Filename))
+		if header.Filename != "" && !slices.Contains(kind.extensions, ext) {
+			writeProblem(w, r, http.StatusUnsupportedMediaType, fmt.Sprintf(
+				"The %s must be named with one of the extensions %s, not %q",
+				kind.name, strings.Join(kind.extensions, ", "), header.Filename,
+			))
+			return nil, false
+		}
+
+		file = part
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil || len(data) == 0 {
+		uploadError(w, r, kind, err, "Missing "+kind.name)
+		return nil, false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	switch {
+	case isExecutable(data):
+		writeProblem(
+			w,
+			r,
+			http.StatusUnsupportedMediaType,
+			"Executable files are not accepted",
+		)
+		return nil, false
+	case isScriptedSVG(data):
+		writeProblem(
+			w,
+			r,
+			http.StatusUnsupportedMediaType,
+			"SVG images holding scripts are not accepted",
+		)
+		return nil, false
+	case !slices.Contains(kind.types, mediaType):
+		writeProblem(w, r, http.StatusUnsupportedMediaType, fmt.Sprintf(
+			"The %s must be of type %s, not %s",
+			kind.name, strings.Join(kind.types, " or "), mediaType,
+		))
+		return nil, false
+	}
+
+	return data, true
+}
+
+// uploadError answers the request with the details of the failure to read the upload,
+// i.e. that it is too large or, otherwise, the given detail.
+func uploadError(
+	w http.ResponseWriter,
+	r *http.Request,
+	kind uploadKind,
+	err error,
+	detail string,
+) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf(
+			"The %s can not exceed %d bytes", kind.name, kind.maxSize,
+		))
+		return
+	}
+
+	writeProblem(w, r, http.StatusBadRequest, detail)
+}
+
+// isExecutable reports whether the data is an executable, from its signature.
+func isExecutable(data []byte) bool {
+	return slices.ContainsFunc(executableMagics, func(magic []byte) bool {
+		return bytes.HasPrefix(data, magic)
+	})
+}
+
+// isScriptedSVG reports whether the data is an SVG image, i.e. an XML document whose
+// root element is `<svg>`, holding scripts.
+func isScriptedSVG(data []byte) bool {
+	rest := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	for {
+		rest = bytes.TrimSpace(rest)
+
+		var end []byte
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end = []byte("-->")
+		case bytes.HasPrefix(rest, []byte("<?")), bytes.HasPrefix(rest, []byte("<!")):
+			end = []byte(">")
+		}
+		if end == nil {
+			break
+		}
+
+		i := bytes.Index(rest, end)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(end):]
+	}
+
+	isSVG := len(rest) > 4 && strings.EqualFold(string(rest[:4]), "<svg") &&
+		(rest[4] == '>' || rest[4] == '/' || isSpace(rest[4]))
+
+	return isSVG && svgScriptPattern.Match(data)
+}
+
+// isSpace reports whether c is an XML whitespace character.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
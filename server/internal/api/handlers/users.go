@@ -2,15 +2,22 @@
 Package handlers defines various request handlers, including user-related operations.
 
 This package provides handlers for processing HTTP requests. The `UserHandler`
-in this file handles user-related operations, such as retrieving a list of users.
+in this file handles user-related operations, such as retrieving a list of users or
+the public profile of an author.
 The handlers are used by the application to define specific routes for user requests
 and send responses with appropriate data or error messages.
 */
 package handlers
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	chi "github.com/go-chi/chi/v5"
 	validator "github.com/go-playground/validator/v10"
@@ -18,6 +25,10 @@ import (
 
 	"github.com/Weburz/burzcontent/server/internal/api/models"
 	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
 )
 
 // UserHandler handles HTTP requests related to users, including retrieving user data.
@@ -38,38 +49,77 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 }
 
 /*
-GetAllUsers handles HTTP requests to retrieve a list of users.
+GetAllUsers handles HTTP requests to retrieve a page of users.
 
 This function performs the following steps:
 
- 1. Generates a new user ID using `uuid.NewV7()`. If it fails, it logs the error
-    and returns an HTTP error response.
- 2. Creates a hardcoded list of two users, with each user having a unique ID,
-    name, and email address.
- 3. Responds with the user data in a JSON format under the key "users".
- 4. Sets the `Content-Type` header to `application/vnd.api+json` and returns an
-    HTTP 200 status code if successful. If encoding the JSON fails, it returns
-    an HTTP error response.
-
-The response will contain a JSON array of users, each represented by their ID,
-name, and email address.
+ 1. Parses the listing parameters from the query string:
+    - `q`: The text the name of the users has to contain (admins also search the
+    email addresses of the users).
+    - `filter[role]`: The role the users have to be granted.
+    - `sort`: The order of the users, either `name`, `email`, `role`, `created_at` or
+    `updated_at`, prefixed by `-` to sort in descending order.
+    - `page[number]` and `page[size]`: The page of users to return (see the
+    `pagination` package).
+ 2. Retrieves the matching page of users from the user service.
+ 3. Responds with the user data in a JSON format under the key "users", along with a
+    description of the page under the key "meta" and a `Link` header pointing to the
+    neighbouring pages.
+
+Example:
+  - Request: GET /users?q=doe&filter[role]=author&sort=-name&page[size]=50
+  - Response: HTTP 200 OK with a JSON body containing the page of users.
+
+Error Handling:
+  - If a listing parameter is invalid, the function responds with a 400 status.
+  - If the users can not be fetched or encoded, the function responds with a 500
+    status.
 */
 func (ur *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := ur.UserService.GetAllUsers()
+	query := r.URL.Query()
+
+	page, err := pagination.ParsePage(query)
+	if err != nil {
+		http.Error(w, "Invalid page parameters", http.StatusBadRequest)
+		return
+	}
+
+	sort, err := pagination.ParseSort(query, repository.UserSortFields...)
 	if err != nil {
-		http.Error(w, "Unable to fetch users", http.StatusInternalServerError)
+		http.Error(w, "Invalid sort parameter", http.StatusBadRequest)
+		return
+	}
+
+	role := auth.Role(query.Get("filter[role]"))
+	if role != "" && !slices.Contains(auth.Roles, role) {
+		http.Error(w, "Invalid role filter", http.StatusBadRequest)
+		return
+	}
+
+	users, total, err := ur.UserService.GetAllUsers(r.Context(), repository.UserQuery{
+		Search: query.Get("q"),
+		Role:   role,
+		Sort:   sort,
+		Page:   page,
+	})
+	if err != nil {
+		serverError(w, r, "Unable to fetch users", err)
+		return
 	}
 
-	response := map[string][]models.User{
+	meta := pagination.NewMeta(page, total)
+	response := map[string]any{
 		"users": users,
+		"meta":  meta,
 	}
 
+	w.Header().Set("Link", meta.Links(r.URL))
 	w.Header().Set("Content-Type", "application/vnd.api+json")
 	w.WriteHeader(http.StatusOK)
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -79,10 +129,10 @@ GetUserByID handles HTTP requests to retrieve a user's information by their ID.
 
 This function performs the following steps:
 
- 1. Retrieves the user ID from the URL parameter `id` using `chi.URLParam(r, "id")`.
- 2. Attempts to parse the user ID using `uuid.Parse()`. If parsing fails, an error
-    response is returned with an HTTP 404 (Not Found) status, indicating that the user
-    ID could not be found or is invalid.
+ 1. Retrieves the user ID parsed from the URL parameter `id` by the `UUIDParams`
+    middleware, which rejects the malformed IDs with an HTTP 400 (Bad Request)
+    status.
+ 2. Responds with an HTTP 404 (Not Found) status if no user has the ID.
  3. Creates a mock `User` object with the parsed user ID, name, and email.
  4. Responds with the user data in a JSON format and a HTTP 200 (OK) status code,
     indicating that the user data has been successfully retrieved.
@@ -102,15 +152,14 @@ Note: The user retrieval process in this function is mocked; no actual user data
 fetched from a database or persistent storage.
 */
 func (ur *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
-	userID, err := uuid.Parse(chi.URLParam(r, "id"))
-	if err != nil {
-		http.Error(w, "Invalid User ID", http.StatusBadRequest)
-		return
-	}
+	userID := params.UUID(r.Context(), "id")
 
-	user, err := ur.UserService.GetUserByID(userID)
-	if err != nil {
-		http.Error(w, "Unable to fetch user data", http.StatusInternalServerError)
+	user, err := ur.UserService.GetUserByID(r.Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch user data", err)
 		return
 	}
 
@@ -122,7 +171,7 @@ func (ur *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
@@ -132,10 +181,10 @@ UpdateUser handles HTTP requests to update an existing user's information.
 
 This function performs the following steps:
 
- 1. Retrieves the user ID from the URL parameter `id` using `chi.URLParam(r, "id")`.
- 2. Attempts to parse the user ID using `uuid.Parse()`. If parsing fails, an error
-    response is returned with an HTTP 404 (Not Found) status, indicating that the user
-    ID could not be found or is invalid.
+ 1. Retrieves the user ID parsed from the URL parameter `id` by the `UUIDParams`
+    middleware, which rejects the malformed IDs with an HTTP 400 (Bad Request)
+    status.
+ 2. Responds with an HTTP 404 (Not Found) status if no user has the ID.
  3. Decodes the incoming request body into an updated `models.User` object, which
     contains the new user details (name, email).
  4. Validates the decoded user data using the `validator` package. If validation fails,
@@ -145,7 +194,7 @@ This function performs the following steps:
     (Created) status code, indicating the update was successful.
 
 Example:
-  - When a PUT request is made to `/users/{id}/edit` with a valid user ID in the URL
+  - When a PUT request is made to `/users/{id}` with a valid user ID in the URL
     and updated user data in the request body
     (e.g., `{"name": "Jane Doe", "email": "jane.doe@example.com"}`), this function will
     update the user information and return the updated user data in the response.
@@ -166,16 +215,11 @@ in a database or persistent storage.
 func (ur *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	validate := validator.New()
 
-	userID, err := uuid.Parse(chi.URLParam(r, "id"))
-	if err != nil {
-		http.Error(w, "Invalid User ID", http.StatusBadRequest)
-		return
-	}
+	userID := params.UUID(r.Context(), "id")
 
 	var updatedUser models.User
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&updatedUser); err != nil {
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+	if err := decodeJSON(r, &updatedUser); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -184,9 +228,19 @@ func (ur *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := ur.UserService.UpdateUser(userID, updatedUser.Name, updatedUser.Email)
-	if err != nil {
-		http.Error(w, "Unable to process user data", http.StatusInternalServerError)
+	user, err := ur.UserService.UpdateUser(
+		r.Context(),
+		userID,
+		updatedUser.Name,
+		updatedUser.Email,
+		updatedUser.Role,
+		updatedUser.Profile,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process user data", err)
 		return
 	}
 
@@ -199,11 +253,25 @@ func (ur *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 		return
 	}
 }
 
+/*
+PatchUser handles HTTP requests to partially update an existing user with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`).
+
+Example:
+  - Request: PATCH /users/{id}
+  - Request Body: `{"role": "editor"}`
+  - Response: the response of `UpdateUser` to the patched user.
+*/
+func (ur *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	mergePatch(w, r, "User", ur.UserService.GetUserByID, ur.UpdateUser)
+}
+
 /*
 CreateUser handles HTTP requests to create a new user.
 
@@ -212,24 +280,22 @@ This function performs the following steps:
  1. Decodes the incoming request body into a `models.User` object.
  2. Validates the decoded user data using the `validator` package. If validation fails,
     an HTTP 422 (Unprocessable Entity) status is returned along with an error message.
- 3. Attempts to generate a new user ID using `uuid.NewV7()`. If ID generation fails,
-    an HTTP 500 (Internal Server Error) is returned.
+ 3. Generates a new user ID through the user service (see `services.IDGenerator`).
  4. Creates a new `User` object with the generated user ID, name, and email.
  5. Returns a JSON response with the newly created user, including their ID, along with
     a HTTP 201 (Created) status code.
 
 Example:
-  - When a POST request is made to `/users/new` with a valid user JSON payload (e.g.,
+  - When a POST request is made to `/users` with a valid user JSON payload (e.g.,
     `{"name": "Jane Doe", "email": "jane.doe@example.com"}`), this function will create
     a new user, assign them a unique ID, and respond with a 201 status along with the
     user data in the response body.
 
 Error Handling:
   - If the request body is invalid or cannot be parsed, the function responds with a
-    500 status and an error message.
+    400 status and an error message.
   - If the request validation fails, the function responds with a 422 status and an
     error message indicating validation failure.
-  - If the user ID generation fails, the function responds with a 500 status.
 
 Note: The user creation process in this function is mocked; no actual user is stored
 in a database or persistent storage.
@@ -238,9 +304,8 @@ func (ur *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	validate := validator.New()
 
 	var newUser models.User
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&newUser); err != nil {
-		http.Error(w, "Invalid Request Body", http.StatusInternalServerError)
+	if err := decodeJSON(r, &newUser); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -249,9 +314,15 @@ func (ur *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := ur.UserService.CreateUser(newUser.Name, newUser.Email)
+	user, err := ur.UserService.CreateUser(
+		r.Context(),
+		newUser.Name,
+		newUser.Email,
+		newUser.Role,
+		newUser.Profile,
+	)
 	if err != nil {
-		http.Error(w, "Unable to process user data", http.StatusInternalServerError)
+		serverError(w, r, "Unable to process user data", err)
 		return
 	}
 
@@ -264,7 +335,7 @@ func (ur *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		http.Error(w, "Unable to encode JSON", http.StatusInternalServerError)
+		serverError(w, r, "Unable to encode JSON", err)
 	}
 }
 
@@ -273,30 +344,297 @@ DeleteUser handles HTTP requests to delete a user by their ID.
 
 This function performs the following steps:
 
- 1. Retrieves the user ID from the URL parameter `id` using `chi.URLParam(r, "id")`.
- 2. Attempts to parse the user ID using `uuid.Parse()`. If parsing fails, an error
-    response is returned with an HTTP 404 (Not Found) status, indicating that the
-    user ID could not be found or is invalid.
- 3. Creates a mock `User` object with the parsed user ID, name, and email.
- 4. Prints a message to the server log indicating that the user has been deleted.
- 5. Responds with an HTTP 204 (No Content) status code, indicating successful
+ 1. Retrieves the user ID parsed from the URL parameter `id` by the `UUIDParams`
+    middleware, which rejects the malformed IDs with an HTTP 400 (Bad Request)
+    status.
+ 2. Responds with an HTTP 404 (Not Found) status if no user has the ID.
+ 3. Deletes the user through the user service, which anonymizes the comments made by
+    the user rather than orphaning them (see `services.UserServiceImpl.DeleteUser`).
+ 4. Responds with an HTTP 204 (No Content) status code, indicating successful
     deletion, though no content is returned in the response body.
 
-Note: In this implementation, the user deletion is mocked, and no actual user is deleted
-from a database or persistent storage.
+Example:
+  - Request: DELETE /users/{id}
+  - Response: HTTP 204 No Content.
 */
 func (ur *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	userID := params.UUID(r.Context(), "id")
+
+	err := ur.UserService.DeleteUser(r.Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete user data", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+GetAuthorByID handles HTTP requests to retrieve the public profile of a user by their
+ID or by the slug of their name (e.g. "jane-doe").
+
+Unlike `GetUserByID`, the response only contains the public-safe fields of the user
+(their ID, name, slug, bio, avatar URL, website and social links) under the key
+"author", never their email address, hence the route is meant to be served to
+anonymous readers.
+
+Example:
+  - Request: GET /authors/{id}
+  - Response: HTTP 200 OK with a JSON body containing the requested author.
+
+Error Handling:
+  - If the user does not exist, the function responds with a 404 status.
+*/
+func (ur *UserHandler) GetAuthorByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := ur.author(w, r)
+	if !ok {
+		return
+	}
+
+	response := map[string]models.Author{
+		"author": models.AuthorOf(user),
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetAuthorArticles handles HTTP requests to retrieve the published articles of an
+author, identified by their ID or by the slug of their name, newest first (see
+`articleQuery` for the listing parameters).
+
+Example:
+  - Request: GET /authors/jane-doe/articles?page[number]=2
+  - Response: HTTP 200 OK with the articles under the key "articles" and the
+    description of the page under the key "meta".
+
+Error Handling:
+  - If a listing parameter is invalid, the function responds with a 400 status.
+  - If the user does not exist, the function responds with a 404 status.
+*/
+func (ur *UserHandler) GetAuthorArticles(w http.ResponseWriter, r *http.Request) {
+	query, ok := articleQuery(w, r)
+	if !ok {
+		return
+	}
+	query.PublishedOnly = true
+
+	user, ok := ur.author(w, r)
+	if !ok {
+		return
+	}
+
+	ur.writeArticlesOf(w, r, user.ID, query)
+}
+
+/*
+GetUserArticles handles HTTP requests to retrieve every article of a user by their ID,
+whether published or not, newest first (see `articleQuery` for the listing
+parameters).
+
+Example:
+  - Request: GET /users/{id}/articles?filter[updated_since]=2025-01-01T00:00:00Z
+  - Response: HTTP 200 OK with the articles under the key "articles" and the
+    description of the page under the key "meta".
+
+Error Handling:
+  - If the user ID is not a valid UUID or a listing parameter is invalid, the
+    function responds with a 400 status.
+  - If the user does not exist, the function responds with a 404 status.
+*/
+func (ur *UserHandler) GetUserArticles(w http.ResponseWriter, r *http.Request) {
+	userID := params.UUID(r.Context(), "id")
+
+	query, ok := articleQuery(w, r)
+	if !ok {
+		return
+	}
+
+	ur.writeArticlesOf(w, r, userID, query)
+}
+
+/*
+articleQuery parses the parameters of a listing of articles from the query string:
+  - `sort`: The order of the articles, either `title`, `created_at`, `updated_at` or
+    `published_at`, prefixed by `-` to sort in descending order.
+  - `filter[updated_since]`: The time (RFC 3339) the articles were last updated at or
+    after.
+  - `page[number]` and `page[size]`: The page of articles to return (see the
+    `pagination` package).
+
+The error response is written if it returns false.
+*/
+func articleQuery(
+	w http.ResponseWriter,
+	r *http.Request,
+) (repository.ArticleQuery, bool) {
+	values := r.URL.Query()
+
+	page, err := pagination.ParsePage(values)
 	if err != nil {
-		http.Error(w, "User ID Not Found", http.StatusNotFound)
+		http.Error(w, "Invalid page parameters", http.StatusBadRequest)
+		return repository.ArticleQuery{}, false
 	}
 
-	err = ur.UserService.DeleteUser(userID)
+	sort, err := pagination.ParseSort(values, repository.ArticleSortFields...)
 	if err != nil {
-		http.Error(w, "Unable to delete user data", http.StatusInternalServerError)
+		http.Error(w, "Invalid sort parameter", http.StatusBadRequest)
+		return repository.ArticleQuery{}, false
+	}
+
+	var since time.Time
+	if value := values.Get("filter[updated_since]"); value != "" {
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "Invalid updated_since filter", http.StatusBadRequest)
+			return repository.ArticleQuery{}, false
+		}
+	}
+
+	return repository.ArticleQuery{UpdatedSince: since, Sort: sort, Page: page}, true
+}
+
+// author fetches the user identified by the `id` URL parameter, holding either their
+// ID or the slug of their name. The error response is written if it returns false.
+func (ur *UserHandler) author(
+	w http.ResponseWriter,
+	r *http.Request,
+) (models.User, bool) {
+	var user models.User
+	var err error
+
+	param := chi.URLParam(r, "id")
+	if userID, parseErr := uuid.Parse(param); parseErr == nil {
+		user, err = ur.UserService.GetUserByID(r.Context(), userID)
+	} else {
+		user, err = ur.UserService.GetUserBySlug(r.Context(), param)
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Author Not Found", http.StatusNotFound)
+		return models.User{}, false
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch author data", err)
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+// writeArticlesOf writes the page of the articles of the user identified by userID
+// matching the query, along with the description of the page and the links to the
+// neighbouring pages.
+func (ur *UserHandler) writeArticlesOf(
+	w http.ResponseWriter,
+	r *http.Request,
+	userID uuid.UUID,
+	query repository.ArticleQuery,
+) {
+	articles, total, err := ur.UserService.GetArticlesOfUser(r.Context(), userID, query)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch articles", err)
 		return
 	}
 
+	meta := pagination.NewMeta(query.Page, total)
+	response := map[string]any{
+		"articles": articles,
+		"meta":     meta,
+	}
+
+	w.Header().Set("Link", meta.Links(r.URL))
 	w.Header().Set("Content-Type", "application/vnd.api+json")
-	w.WriteHeader(http.StatusNoContent)
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+/*
+ExportUser handles HTTP requests to export every piece of data of a user by their ID,
+on request of the user (GDPR).
+
+The response is a ZIP archive holding the machine-readable (JSON) data of the user:
+  - `user.json`: The user, including their profile.
+  - `articles.json`: The articles authored by the user.
+  - `comments.json`: The comments made by the user.
+  - `consents.json`: The consents given by the user along with their comments.
+  - `export.json`: The whole export in a single document, along with its date.
+
+Example:
+  - Request: POST /users/{id}/export
+  - Response: HTTP 200 OK with an `application/zip` body, served as an attachment.
+
+Error Handling:
+  - If the user ID is not a valid UUID, the function responds with a 400 status.
+  - If the user does not exist, the function responds with a 404 status.
+  - If the data can not be exported, the function responds with a 500 status.
+*/
+func (ur *UserHandler) ExportUser(w http.ResponseWriter, r *http.Request) {
+	userID := params.UUID(r.Context(), "id")
+
+	export, err := ur.UserService.ExportUser(r.Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to export user data", err)
+		return
+	}
+
+	// Build the archive in memory so that a failure can still be reported properly
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, data := range map[string]any{
+		"user.json":     export.User,
+		"articles.json": export.Articles,
+		"comments.json": export.Comments,
+		"consents.json": export.Consents,
+		"export.json":   export,
+	} {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err == nil {
+			encoder := json.NewEncoder(file)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(data)
+		}
+
+		if err != nil {
+			serverError(w, r, "Unable to export user data", err)
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		serverError(w, r, "Unable to export user data", err)
+		return
+	}
+
+	filename := fmt.Sprintf(
+		"user-%s-%s.zip",
+		userID,
+		export.ExportedAt.Format("20060102"),
+	)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(buf.Bytes())
 }
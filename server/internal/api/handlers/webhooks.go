@@ -0,0 +1,349 @@
+/*
+Package handlers defines various request handlers, including the outbound webhooks of a
+site.
+
+The `WebhookHandler` in this file handles the management of the webhooks of a site,
+i.e. of the endpoints its events are posted to, signed with their secret, along with
+their delivery log and the replay of their deliveries.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	validator "github.com/go-playground/validator/v10"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// WebhookHandler handles HTTP requests related to the outbound webhooks of a site.
+type WebhookHandler struct {
+	WebhookService services.WebhookService
+}
+
+// NewWebhookHandler creates and initializes a new instance of WebhookHandler.
+func NewWebhookHandler(webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		WebhookService: webhookService,
+	}
+}
+
+/*
+GetAllWebhooks handles HTTP requests to retrieve the webhooks of the site.
+
+The response contains a JSON array of webhooks under the key "webhooks", along with
+their failures. The secrets of the webhooks are never disclosed.
+*/
+func (wh *WebhookHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := wh.WebhookService.GetAllWebhooks(r.Context())
+	if err != nil {
+		serverError(w, r, "Unable to fetch webhooks", err)
+		return
+	}
+
+	response := map[string][]models.Webhook{
+		"webhooks": webhooks,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+GetWebhookByID handles HTTP requests to retrieve a webhook by its ID.
+
+Error Handling:
+  - If the webhook ID is not a valid UUID, the function responds with a 400 status.
+  - If the webhook does not exist, the function responds with a 404 status.
+*/
+func (wh *WebhookHandler) GetWebhookByID(w http.ResponseWriter, r *http.Request) {
+	webhookID := params.UUID(r.Context(), "id")
+
+	webhook, err := wh.WebhookService.GetWebhookByID(r.Context(), webhookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webhook Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch webhook data", err)
+		return
+	}
+
+	writeWebhook(w, r, http.StatusOK, webhook, "")
+}
+
+/*
+CreateWebhook handles HTTP requests to create a new webhook, posting the events of the
+given types (or patterns, e.g. "article.*") to its URL, or every event if none is
+given.
+
+Example:
+  - When a POST request is made to `/webhooks` with a JSON payload (e.g.,
+    `{"url": "https://example.com/hooks/burz", "events": ["article.*"]}`), this
+    function will create the webhook and respond with a 201 status along with the
+    webhook in the response body. The secret the events are signed with is returned
+    under the key "secret" by this response only.
+
+Error Handling:
+  - If the request body is invalid, the function responds with a 400 status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (wh *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var newWebhook models.Webhook
+	if err := decodeJSON(r, &newWebhook); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(newWebhook); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	webhook, secret, err := wh.WebhookService.CreateWebhook(r.Context(), newWebhook)
+	if err != nil {
+		serverError(w, r, "Unable to process webhook data", err)
+		return
+	}
+
+	writeWebhook(w, r, http.StatusCreated, webhook, secret)
+}
+
+/*
+UpdateWebhook handles HTTP requests to update the URL, events and state of an existing
+webhook, e.g. to enable it again with `"enabled": true` once its endpoint is fixed,
+which resets its failures.
+
+Error Handling:
+  - If the webhook ID is not a valid UUID or the request body is invalid, the function
+    responds with a 400 status.
+  - If the webhook does not exist, the function responds with a 404 status.
+  - If the request validation fails, the function responds with a 422 status.
+*/
+func (wh *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := params.UUID(r.Context(), "id")
+
+	var updatedWebhook models.Webhook
+	if err := decodeJSON(r, &updatedWebhook); err != nil {
+		http.Error(w, "Invalid Request Body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validator.New().Struct(updatedWebhook); err != nil {
+		http.Error(w, "Request validation failed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	webhook, err := wh.WebhookService.UpdateWebhook(
+		r.Context(),
+		webhookID,
+		updatedWebhook,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webhook Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to process webhook data", err)
+		return
+	}
+
+	writeWebhook(w, r, http.StatusOK, webhook, "")
+}
+
+/*
+PatchWebhook handles HTTP requests to partially update an existing webhook with a JSON
+merge patch (RFC 7396), leaving the fields the patch does not hold unchanged (see
+`mergePatch`).
+
+Example:
+  - Request: PATCH /webhooks/{id}
+  - Request Body: `{"enabled": true}`
+  - Response: the response of `UpdateWebhook` to the patched webhook.
+*/
+func (wh *WebhookHandler) PatchWebhook(w http.ResponseWriter, r *http.Request) {
+	mergePatch(w, r, "Webhook", wh.WebhookService.GetWebhookByID, wh.UpdateWebhook)
+}
+
+/*
+RotateSecret handles HTTP requests to replace the secret of a webhook of the site, e.g.
+when it leaked.
+
+Example:
+  - When a POST request is made to `/webhooks/{id}/rotate`, this function will
+    generate a new secret for the webhook and respond with a 200 status along with the
+    webhook in the response body. The new secret is returned under the key "secret" by
+    this response only, the events being signed with it from then on.
+
+Error Handling:
+  - If the webhook ID is not a valid UUID, the function responds with a 400 status.
+  - If the webhook does not exist, the function responds with a 404 status.
+*/
+func (wh *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	webhookID := params.UUID(r.Context(), "id")
+
+	webhook, secret, err := wh.WebhookService.RotateSecret(r.Context(), webhookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webhook Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to rotate webhook secret", err)
+		return
+	}
+
+	writeWebhook(w, r, http.StatusOK, webhook, secret)
+}
+
+/*
+DeleteWebhook handles HTTP requests to delete a webhook by its ID.
+
+The function responds with an HTTP 204 (No Content) status code on success, a 400
+status if the webhook ID is not a valid UUID and a 404 status if the webhook does not
+exist.
+*/
+func (wh *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := params.UUID(r.Context(), "id")
+
+	err := wh.WebhookService.DeleteWebhook(r.Context(), webhookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webhook Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete webhook", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*
+GetDeliveries handles HTTP requests to retrieve the delivery log of a webhook, i.e. the
+attempts to post the events to its endpoint, most recent first, paged with the
+`page[number]` and `page[size]` query parameters.
+
+Example:
+  - Request: GET /webhooks/{id}/deliveries?page[size]=10
+  - Response: HTTP 200 OK with the attempts under the key "deliveries" (e.g.
+    `{"deliveries": [{"delivery_id": "...", "event": "article.published",
+    "payload": {...}, "attempt": 2, "status_code": 503, "error": "...",
+    "latency_ms": 87, ...}]}`) and the description of the page under the key "meta",
+    along with a `Link` header pointing to the neighbouring pages.
+
+Error Handling:
+  - If the webhook ID is not a valid UUID or the page parameters are invalid, the
+    function responds with a 400 status.
+  - If the webhook does not exist, the function responds with a 404 status.
+*/
+func (wh *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID := params.UUID(r.Context(), "id")
+
+	page, err := pagination.ParsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid page parameters", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, total, err := wh.WebhookService.GetDeliveries(
+		r.Context(),
+		webhookID,
+		page,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webhook Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch webhook deliveries", err)
+		return
+	}
+
+	meta := pagination.NewMeta(page, total)
+	response := map[string]any{
+		"deliveries": deliveries,
+		"meta":       meta,
+	}
+
+	w.Header().Set("Link", meta.Links(r.URL))
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+/*
+ReplayDelivery handles HTTP requests to post the event of a delivery attempt to its
+webhook again, e.g. once its endpoint is fixed, whether the webhook is enabled or not.
+
+The event is posted at once, with the same payload and delivery ID, and the new
+attempt is logged whatever its outcome.
+
+Example:
+  - Request: POST /deliveries/{id}/replay
+  - Response: HTTP 201 Created with the new attempt under the key "delivery", e.g.
+    `{"delivery": {"delivery_id": "...", "attempt": 1, "replay": true,
+    "status_code": 200, "latency_ms": 42, ...}}`, the endpoint having failed again if
+    it holds an error.
+
+Error Handling:
+  - If the delivery ID is not a valid UUID, the function responds with a 400 status.
+  - If the delivery (or its webhook) does not exist, the function responds with a 404
+    status.
+*/
+func (wh *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryID := params.UUID(r.Context(), "id")
+
+	delivery, err := wh.WebhookService.ReplayDelivery(r.Context(), deliveryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Delivery Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to replay delivery", err)
+		return
+	}
+
+	response := map[string]models.WebhookDelivery{
+		"delivery": delivery,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
+
+// writeWebhook writes the JSON encoding of the webhook under the key "webhook" with
+// the given status code, along with its secret under the key "secret" if not empty.
+func writeWebhook(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	webhook models.Webhook,
+	secret string,
+) {
+	response := map[string]any{
+		"webhook": webhook,
+	}
+	if secret != "" {
+		response["secret"] = secret
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Package handlers defines various request handlers, including the webmentions of the
+articles.
+
+The `WebmentionHandler` in this file receives the webmentions sent to the articles of
+the sites (see https://www.w3.org/TR/webmention/), serves the approved ones to the
+readers and lets the editors moderate them. The frontends advertise the endpoint
+receiving the webmentions on the pages of the articles, e.g. with a
+`<link rel="webmention" href="https://api.weburz.com/webmention">` element.
+*/
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/params"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// maxWebmentionSize is the maximum size of the body of a received webmention, in bytes.
+const maxWebmentionSize = 16 << 10
+
+// WebmentionHandler handles HTTP requests related to the webmentions of the articles.
+type WebmentionHandler struct {
+	WebmentionService services.WebmentionService
+}
+
+// NewWebmentionHandler creates and initializes a new instance of WebmentionHandler.
+func NewWebmentionHandler(
+	webmentionService services.WebmentionService,
+) *WebmentionHandler {
+	return &WebmentionHandler{
+		WebmentionService: webmentionService,
+	}
+}
+
+/*
+ReceiveWebmention handles the webmentions sent to the articles, on the public API.
+
+The webmention is accepted once its target is found to be a published article of the
+site, and verified in the background: it is only stored, pending moderation, if its
+source links to its target.
+
+Example:
+  - Request: POST /webmention with a `application/x-www-form-urlencoded` body like
+    `source=https://example.com/post&target=https://blog.weburz.com/articles/{id}`
+  - Response: HTTP 202 Accepted.
+
+Error Handling:
+  - If the body is malformed, the source or target is not an HTTP(S) URL or the
+    target is not a published article of the site, the function responds with a 400
+    status.
+*/
+func (wh *WebmentionHandler) ReceiveWebmention(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebmentionSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := wh.WebmentionService.ReceiveWebmention(
+		r.Context(),
+		r.PostForm.Get("source"),
+		r.PostForm.Get("target"),
+	)
+	if errors.Is(err, services.ErrInvalidWebmention) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to receive webmention", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+/*
+GetApprovedWebmentions handles HTTP requests to retrieve the approved webmentions of a
+published article, on the public API.
+
+Example:
+  - Request: GET /webmentions/article/{id}
+  - Response: HTTP 200 OK with a JSON body containing the webmentions under the key
+    "webmentions".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist or is not published, the function responds with a
+    404 status.
+*/
+func (wh *WebmentionHandler) GetApprovedWebmentions(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	articleID := params.UUID(r.Context(), "id")
+
+	webmentions, err := wh.WebmentionService.GetApprovedWebmentions(
+		r.Context(),
+		articleID,
+	)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch webmentions", err)
+		return
+	}
+
+	writeWebmentions(w, r, webmentions)
+}
+
+/*
+GetWebmentions handles HTTP requests to retrieve every webmention of an article,
+whatever its moderation status.
+
+Example:
+  - Request: GET /articles/{id}/webmentions
+  - Response: HTTP 200 OK with a JSON body containing the webmentions under the key
+    "webmentions".
+
+Error Handling:
+  - If the article ID is not a valid UUID, the function responds with a 400 status.
+  - If the article does not exist, the function responds with a 404 status.
+*/
+func (wh *WebmentionHandler) GetWebmentions(w http.ResponseWriter, r *http.Request) {
+	articleID := params.UUID(r.Context(), "id")
+
+	webmentions, err := wh.WebmentionService.GetWebmentions(r.Context(), articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Article Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to fetch webmentions", err)
+		return
+	}
+
+	writeWebmentions(w, r, webmentions)
+}
+
+/*
+ApproveWebmention handles HTTP requests to approve a webmention, which is then
+displayed to the readers along with its article.
+
+Example:
+  - Request: POST /webmentions/{id}/approve
+  - Response: HTTP 200 OK with a JSON body containing the webmention under the key
+    "webmention".
+
+Error Handling:
+  - If the webmention ID is not a valid UUID, the function responds with a 400 status.
+  - If the webmention does not exist, the function responds with a 404 status.
+*/
+func (wh *WebmentionHandler) ApproveWebmention(w http.ResponseWriter, r *http.Request) {
+	wh.moderate(w, r, models.WebmentionApproved)
+}
+
+/*
+RejectWebmention handles HTTP requests to reject a webmention, which is then hidden
+from the readers.
+
+Example:
+  - Request: POST /webmentions/{id}/reject
+  - Response: HTTP 200 OK with a JSON body containing the webmention under the key
+    "webmention".
+
+Error Handling:
+  - The function responds like `ApproveWebmention` does.
+*/
+func (wh *WebmentionHandler) RejectWebmention(w http.ResponseWriter, r *http.Request) {
+	wh.moderate(w, r, models.WebmentionRejected)
+}
+
+/*
+DeleteWebmention handles HTTP requests to delete a webmention. It is received again
+(and verified) if its source sends it again.
+
+Example:
+  - Request: DELETE /webmentions/{id}
+  - Response: HTTP 204 No Content.
+
+Error Handling:
+  - If the webmention ID is not a valid UUID, the function responds with a 400 status.
+  - If the webmention does not exist, the function responds with a 404 status.
+*/
+func (wh *WebmentionHandler) DeleteWebmention(w http.ResponseWriter, r *http.Request) {
+	id := params.UUID(r.Context(), "id")
+
+	err := wh.WebmentionService.DeleteWebmention(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webmention Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to delete webmention", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// moderate handles a request setting the moderation status of a webmention.
+func (wh *WebmentionHandler) moderate(
+	w http.ResponseWriter,
+	r *http.Request,
+	status string,
+) {
+	id := params.UUID(r.Context(), "id")
+
+	webmention, err := wh.WebmentionService.ModerateWebmention(r.Context(), id, status)
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Webmention Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		serverError(w, r, "Unable to moderate webmention", err)
+		return
+	}
+
+	response := map[string]models.Webmention{
+		"webmention": webmention,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
+
+// writeWebmentions answers the request with the webmentions, under the key
+// "webmentions".
+func writeWebmentions(
+	w http.ResponseWriter,
+	r *http.Request,
+	webmentions []models.Webmention,
+) {
+	response := map[string][]models.Webmention{
+		"webmentions": webmentions,
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		serverError(w, r, "Unable to encode JSON", err)
+		return
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/handlers"
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+	"github.com/Weburz/burzcontent/server/internal/scheduler"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+/*
+jobs returns the recurring jobs of the server, with their default schedule:
+  - publish-scheduled: Publishes the articles whose publication is scheduled, every
+    minute.
+  - unpublish-expired: Unpublishes the expired articles, every minute.
+  - purge-trash: Purges the articles whose retention period in the trash is over,
+    every hour (disabled if the retention period is zero).
+  - regenerate-sitemaps: Regenerates the sitemaps of the sites, every 15 minutes.
+  - backup: Writes a backup archive of every site to the backup directory, every day
+    at 03:00 UTC (disabled if no backup directory is configured).
+  - prune: Applies the retention policy, removing the revisions of the articles but
+    their latest ones and the entries of the audit log past their retention period,
+    every day at 04:30 UTC (disabled if every revision and entry is kept).
+  - comment-digest: Emails each author the digest of the comments on their articles,
+    every day at 08:00 UTC.
+
+Their runs are delayed by a random jitter of up to the configured duration, they go by
+the clock of the services (e.g. to tell which articles are due for publication) and
+they log through the logger held by their context (see `logger.FromContext`).
+*/
+func (a *API) jobs() []scheduler.Job {
+	jitter := time.Duration(a.Config.JobJitter) * time.Second
+	articles := a.Handlers.ArticleHandler.ArticleServer
+	clock := a.Handlers.Clock
+
+	return []scheduler.Job{
+		{
+			Name:        "publish-scheduled",
+			Description: "Publishes the articles whose publication is scheduled",
+			Schedule:    "* * * * *",
+			Enabled:     true,
+			Jitter:      jitter,
+			Run: a.forEachSite(func(ctx context.Context, site models.Site) error {
+				published, err := articles.PublishScheduled(ctx, clock.Now())
+				if published > 0 {
+					logger.FromContext(ctx).InfoContext(
+						ctx, "Published scheduled articles",
+						"count", published,
+						"site", site.Slug,
+					)
+				}
+
+				return err
+			}),
+		},
+		{
+			Name:        "unpublish-expired",
+			Description: "Unpublishes the expired articles",
+			Schedule:    "* * * * *",
+			Enabled:     true,
+			Jitter:      jitter,
+			Run: func(ctx context.Context) error {
+				unpublished, err := articles.UnpublishExpired(ctx, clock.Now())
+				if unpublished > 0 {
+					logger.FromContext(ctx).InfoContext(
+						ctx, "Unpublished expired articles", "count", unpublished,
+					)
+				}
+
+				return err
+			},
+		},
+		{
+			Name:        "purge-trash",
+			Description: "Purges the articles whose retention period is over",
+			Schedule:    "0 * * * *",
+			Enabled:     a.Config.TrashRetentionDays > 0,
+			Jitter:      jitter,
+			Run: func(ctx context.Context) error {
+				retention := time.Duration(a.Config.TrashRetentionDays) * 24 * time.Hour
+
+				purged, err := articles.PurgeTrash(ctx, clock.Now().Add(-retention))
+				if purged > 0 {
+					logger.FromContext(ctx).InfoContext(
+						ctx, "Purged articles from the trash", "count", purged,
+					)
+				}
+
+				return err
+			},
+		},
+		{
+			Name:        "regenerate-sitemaps",
+			Description: "Regenerates the sitemaps of the sites",
+			Schedule:    "*/15 * * * *",
+			Enabled:     true,
+			Jitter:      jitter,
+			Run: a.forEachSite(func(ctx context.Context, site models.Site) error {
+				_, err := a.Handlers.FeedHandler.SitemapService.RegenerateSitemap(ctx)
+				return err
+			}),
+		},
+		{
+			Name:        "backup",
+			Description: "Writes a backup archive of every site to the backup dir",
+			Schedule:    "0 3 * * *",
+			Enabled:     a.Config.BackupDir != "",
+			Jitter:      jitter,
+			Run:         a.forEachSite(a.backupSite),
+		},
+		{
+			Name:        "prune",
+			Description: "Removes the old revisions and audit entries",
+			Schedule:    "30 4 * * *",
+			Enabled:     a.Config.RevisionLimit > 0 || a.Config.AuditRetentionDays > 0,
+			Jitter:      jitter,
+			Run:         a.prune,
+		},
+		{
+			Name:        "comment-digest",
+			Description: "Emails the authors the digest of their new comments",
+			Schedule:    "0 8 * * *",
+			Enabled:     true,
+			Jitter:      jitter,
+			Run: a.forEachSite(func(ctx context.Context, site models.Site) error {
+				digests := a.Handlers.DigestHandler.DigestService
+
+				// The authors never sent any digest get the comments of the last day
+				sent, err := digests.SendDigests(ctx, clock.Now().Add(-24*time.Hour))
+				if sent > 0 {
+					logger.FromContext(ctx).InfoContext(
+						ctx, "Sent comment digests", "count", sent, "site", site.Slug,
+					)
+				}
+
+				return err
+			}),
+		},
+	}
+}
+
+// startJobs registers the recurring jobs of the server on the scheduler of the
+// handlers, overrides their settings with the configured ones and starts them.
+func (a *API) startJobs() {
+	ctx := context.Background()
+	jobs := a.Handlers.JobHandler.Scheduler
+
+	for _, job := range a.jobs() {
+		if err := jobs.Add(job); err != nil {
+			a.Handlers.Logger.ErrorContext(
+				ctx, "Unable to schedule job", "job", job.Name, "error", err,
+			)
+		}
+	}
+
+	if err := jobs.Configure(a.Config.Jobs); err != nil {
+		a.Handlers.Logger.WarnContext(
+			ctx, "Jobs will run on their default schedule", "error", err,
+		)
+	}
+
+	jobs.Start(ctx)
+}
+
+// forEachSite returns a job running fn for every site, with a context holding the
+// site. The job goes on with the other sites when fn fails for one of them, and fails
+// with the first error.
+func (a *API) forEachSite(
+	fn func(ctx context.Context, site models.Site) error,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sites, err := a.Handlers.SiteHandler.SiteService.GetAllSites(ctx)
+		if err != nil {
+			return err
+		}
+
+		var first error
+		for _, site := range sites {
+			err := fn(tenant.NewContext(ctx, site), site)
+			if err != nil && first == nil {
+				first = fmt.Errorf("site %s: %w", site.Slug, err)
+			}
+		}
+
+		return first
+	}
+}
+
+// backupSite writes a backup archive of the site held by the context to the backup
+// directory (see `handlers.BackupArchiveName` for the name of the archives).
+func (a *API) backupSite(ctx context.Context, site models.Site) error {
+	backup, err := a.Handlers.BackupHandler.BackupService.Backup(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(a.Config.BackupDir, 0o750); err != nil {
+		return fmt.Errorf("unable to create backup directory: %w", err)
+	}
+
+	// Write the archive under a temporary name, so that an incomplete archive is never
+	// mistaken for a backup
+	name := handlers.BackupArchiveName(backup.Manifest)
+	path := filepath.Join(a.Config.BackupDir, name)
+	file, err := os.CreateTemp(a.Config.BackupDir, ".backup-*")
+	if err != nil {
+		return fmt.Errorf("unable to create backup archive: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	err = handlers.WriteBackupArchive(file, backup)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write backup archive: %w", err)
+	}
+
+	logger.FromContext(ctx).InfoContext(
+		ctx, "Backed up site", "site", site.Slug, "path", path,
+	)
+
+	return nil
+}
+
+// prune applies the retention policy to every site, then to the audit log of the
+// requests managing the sites themselves, which belongs to no site.
+func (a *API) prune(ctx context.Context) error {
+	retention := a.Handlers.RetentionHandler.RetentionService
+
+	err := a.forEachSite(func(ctx context.Context, site models.Site) error {
+		report, err := retention.ApplyRetention(ctx)
+		if report.Revisions > 0 || report.AuditEntries > 0 {
+			logger.FromContext(ctx).InfoContext(
+				ctx, "Pruned revisions and audit entries",
+				"revisions", report.Revisions,
+				"audit_entries", report.AuditEntries,
+				"site", site.Slug,
+			)
+		}
+
+		return err
+	})(ctx)
+
+	report, auditErr := retention.ApplyRetention(ctx)
+	if report.AuditEntries > 0 {
+		logger.FromContext(ctx).InfoContext(
+			ctx, "Pruned audit entries of the sites",
+			"audit_entries", report.AuditEntries,
+		)
+	}
+	if err == nil {
+		err = auditErr
+	}
+
+	return err
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+// clfTime is the layout of the times of the Combined Log Format.
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+/*
+AccessLog returns a middleware which writes a line to w for each request, in the
+Combined Log Format of Apache, for the log tooling which only ingests such access logs,
+e.g.:
+
+	192.0.2.1 - - [16/Oct/2026:08:00:00 +0000] "GET /tags HTTP/1.1" 200 64 "-" "curl"
+
+The lines are written once the responses are sent, stamped with the time the requests
+were received (as told by the clock), for the requests picked by the sampler only (see
+`logger.Sampler`), every request being picked by a nil sampler. The requests picked are
+the ones the `RequestLogger` middleware logs, if it runs before. Their fields which are
+not known (the identity and the user of the client, and the missing headers) are
+written as `-`, quoted for the headers. The lines are written whole, one at a time,
+so that concurrent requests do not interleave them.
+
+Example:
+
+	r.Use(middleware.AccessLog(file, services.SystemClock{}, nil))
+*/
+func AccessLog(
+	w io.Writer,
+	clock clock.Clock,
+	sampler *logger.Sampler,
+) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			received := clock.Now()
+			ww := chimiddleware.NewWrapResponseWriter(rw, r.ProtoMajor)
+
+			defer func() {
+				status := max(ww.Status(), http.StatusOK)
+				if !sampler.Sample(r.Context(), r.Method, r.URL.Path, status) {
+					return
+				}
+
+				line := fmt.Sprintf(
+					"%s - - [%s] \"%s %s %s\" %d %d %s %s\n",
+					clientIP(r),
+					received.Format(clfTime),
+					r.Method,
+					r.URL.RequestURI(),
+					r.Proto,
+					status,
+					ww.BytesWritten(),
+					clfQuote(r.Referer()),
+					clfQuote(r.UserAgent()),
+				)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				_, _ = io.WriteString(w, line)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// clfQuote quotes a header of the Combined Log Format, written as `-` if it is empty.
+func clfQuote(value string) string {
+	if value == "" {
+		value = "-"
+	}
+
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// AuditRecorder records the entries of the audit log.
+type AuditRecorder interface {
+	RecordAudit(ctx context.Context, entry models.AuditEntry) error
+}
+
+/*
+Audit returns a middleware which records every write request (i.e. every request but
+the `GET`, `HEAD` and `OPTIONS` ones) in the audit log of the site it was made to,
+along with the API key it was authenticated with and the status of its response.
+
+The middleware has to run after the `Authenticate` middleware. Failing to record an
+entry does not fail the request, the error is logged instead.
+*/
+func Audit(recorder AuditRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isReadMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			principal, _ := auth.FromContext(r.Context())
+			entry := models.AuditEntry{
+				SiteID: tenant.SiteID(r.Context()),
+				KeyID:  principal.KeyID,
+				Role:   string(principal.Role),
+				Method: r.Method,
+				Path:   r.URL.Path,
+				Status: ww.Status(),
+			}
+
+			ctx := context.WithoutCancel(r.Context())
+			if err := recorder.RecordAudit(ctx, entry); err != nil {
+				logger.FromContext(ctx).ErrorContext(
+					ctx, "Unable to record audit entry", "error", err,
+				)
+			}
+		})
+	}
+}
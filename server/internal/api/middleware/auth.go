@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+	"github.com/Weburz/burzcontent/server/internal/session"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+/*
+RequireToken returns a middleware which only lets requests carrying the given bearer
+token through to the next handler.
+
+The token is expected in the `Authorization` header using the `Bearer` scheme. The
+comparison is done in constant time to avoid leaking the token through timing
+attacks. Requests without a valid token are rejected with a `401 Unauthorized`
+response.
+
+An empty token rejects every request, so that a missing configuration value can never
+expose the guarded endpoints.
+
+Example:
+
+	r.With(middleware.RequireToken(cfg.DebugToken)).Mount("/debug", profiler)
+*/
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" ||
+				subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="burzcontent"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authenticator authenticates the API key presented by a request.
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (auth.Principal, error)
+}
+
+/*
+Authenticate returns a middleware which authenticates the API key presented by a
+request and stores the resulting principal in the request context (see the `auth`
+package), attaching the API key and the user it is owned by (if any) to the logger of
+the request (see the `logger` package).
+
+The API key is read from the `Authorization` header using the `Bearer` scheme, or from
+the `X-API-Key` header. Failing that, the request is authenticated with the API key of
+the session whose token its session cookie holds, if sessions is not nil (see the
+`session` package), in which case the write requests are rejected with a `403
+Forbidden` response unless they present the CSRF token of the session in the
+`X-CSRF-Token` header. Requests without an API key (or an open session) are let
+through anonymously, so that the public routes keep working; the routes requiring a
+principal are guarded by `RequireRole`.
+
+The middleware has to run after the `Tenant` middleware: requests presenting an
+unknown API key are rejected with a `401 Unauthorized` response and requests
+presenting the API key of another site with a `403 Forbidden` response.
+*/
+func Authenticate(
+	authenticator Authenticator,
+	sessions *session.Store,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := auth.KeyFromHeader(r.Header)
+
+			// Fall back on the API key of the session of the browser, whose write
+			// requests have to present its CSRF token
+			var cookie *http.Cookie
+			if key == "" && sessions != nil {
+				cookie, _ = r.Cookie(session.CookieName)
+			}
+			if cookie != nil {
+				s, ok := sessions.Get(cookie.Value)
+				if ok && !isReadMethod(r.Method) &&
+					!s.CheckCSRF(r.Header.Get(session.CSRFHeader)) {
+					http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+					return
+				}
+				key = s.Key
+			}
+
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), key)
+			if err != nil {
+				// Close the session whose API key was revoked (or rotated)
+				if cookie != nil {
+					sessions.Delete(cookie.Value)
+				}
+
+				w.Header().Set("WWW-Authenticate", `Bearer realm="burzcontent"`)
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				return
+			}
+
+			if !principal.CanAccessSite(tenant.SiteID(r.Context())) {
+				http.Error(w, "API key not valid for this site", http.StatusForbidden)
+				return
+			}
+
+			ctx := auth.NewContext(r.Context(), principal)
+			if principal.KeyID != uuid.Nil {
+				ctx = logger.With(ctx, "key_id", principal.KeyID)
+			}
+			if principal.UserID != uuid.Nil {
+				ctx = logger.With(ctx, "user_id", principal.UserID)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+/*
+RequireRole returns a middleware which only lets the requests made by a principal
+granted one of the roles through to the next handler.
+
+Anonymous requests are rejected with a `401 Unauthorized` response and requests made by
+a principal lacking the roles with a `403 Forbidden` response.
+
+Example:
+
+	r.With(middleware.RequireRole(auth.RoleAdmin)).Get("/usage", h.GetUsage)
+*/
+func RequireRole(roles ...auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="burzcontent"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !principal.HasRole(roles...) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
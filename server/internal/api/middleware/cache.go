@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+/*
+Cache returns a middleware which lets the clients and the shared caches (CDNs, reverse
+proxies) cache the successful responses to the read requests for maxAge, and
+revalidate them cheaply afterwards.
+
+The `200 OK` responses are buffered to compute their `ETag` and are served with a
+`Cache-Control: public` header, unless the handler set its own. Requests carrying an
+`If-None-Match` header matching the `ETag` are answered with a body-less
+`304 Not Modified` response. The middleware is only meant for the public API, whose
+responses do not depend on the identity of the caller.
+
+Example:
+
+	r.Use(middleware.Cache(5 * time.Minute))
+*/
+func Cache(maxAge time.Duration) func(http.Handler) http.Handler {
+	cacheControl := fmt.Sprintf(
+		"public, max-age=%d, stale-while-revalidate=%d",
+		int(maxAge.Seconds()),
+		int(maxAge.Seconds()),
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferedWriter{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+
+			if buf.status != http.StatusOK {
+				w.WriteHeader(buf.status)
+				_, _ = w.Write(buf.body.Bytes())
+				return
+			}
+
+			sum := sha256.Sum256(buf.body.Bytes())
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+			w.Header().Set("ETag", etag)
+			if w.Header().Get("Cache-Control") == "" {
+				w.Header().Set("Cache-Control", cacheControl)
+			}
+
+			if r.Header.Get("If-None-Match") == etag {
+				logger.SetCacheStatus(r.Context(), "hit")
+				w.Header().Del("Content-Type")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			logger.SetCacheStatus(r.Context(), "miss")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(buf.body.Bytes())
+		})
+	}
+}
+
+// bufferedWriter is an `http.ResponseWriter` buffering the status and the body of a
+// response, while writing its headers straight to the underlying header map.
+type bufferedWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) WriteHeader(status int) {
+	if !bw.wroteHeader {
+		bw.status = status
+		bw.wroteHeader = true
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	bw.wroteHeader = true
+	return bw.body.Write(b)
+}
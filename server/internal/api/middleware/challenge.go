@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/challenge"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+/*
+RequireChallenge returns a middleware which only lets the requests presenting the
+solution of a challenge of the verifier in their `X-Challenge-Response` header through
+to the next handler (see the `challenge` package), so that the bots are turned away
+before the handler (and its spam filter) runs.
+
+Requests without a solution, or with a wrong one, are rejected with a `403 Forbidden`
+response, and requests whose solution could not be verified (e.g. the provider of the
+CAPTCHAs being unreachable) with a `503 Service Unavailable` response.
+
+Example:
+
+	r.With(middleware.RequireChallenge(verifier)).
+		Post("/comments/article/{id}", h.CommentHandler.AddCommentToArticle)
+*/
+func RequireChallenge(verifier challenge.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			solution := r.Header.Get(challenge.ResponseHeader)
+			if solution == "" {
+				http.Error(w, "Challenge response required", http.StatusForbidden)
+				return
+			}
+
+			ctx := r.Context()
+			err := verifier.Verify(ctx, solution, clientIP(r))
+			if errors.Is(err, challenge.ErrFailed) {
+				http.Error(w, "Challenge failed", http.StatusForbidden)
+				return
+			} else if err != nil {
+				logger.FromContext(ctx).WarnContext(
+					ctx, "Unable to verify challenge", "error", err,
+				)
+				http.Error(
+					w,
+					"Unable to verify challenge",
+					http.StatusServiceUnavailable,
+				)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/chaos"
+)
+
+/*
+InjectFaults returns a middleware which injects the faults drawn by the injector into
+the responses, to try the retries of the clients out against an unreliable server. It
+is only meant for the environments other than production.
+
+The responses to the requests matched by a rule are delayed by the latency drawn (the
+delay being cut short if the client goes away), then the requests failed by the rule
+are answered with its status code (along with a `Retry-After` header for the 503 and
+429 statuses), without reaching the next handlers. The responses carry an
+`X-Injected-Fault` header describing the fault, e.g. "latency=250ms" or "latency=0s,
+status=503", so that the injected faults can be told from the real ones.
+
+Example:
+
+	r.Use(middleware.InjectFaults(chaos.New(rules)))
+*/
+func InjectFaults(injector *chaos.Injector) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fault, ok := injector.Fault(r.Method, r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := "latency=" + fault.Latency.String()
+			if fault.Status != 0 {
+				header += ", status=" + strconv.Itoa(fault.Status)
+			}
+			w.Header().Set("X-Injected-Fault", header)
+
+			if fault.Latency > 0 {
+				timer := time.NewTimer(fault.Latency)
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+
+			if fault.Status != 0 {
+				if fault.Status == http.StatusServiceUnavailable ||
+					fault.Status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "1")
+				}
+				http.Error(w, "Injected Fault", fault.Status)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/ratelimit"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+/*
+ClientRateLimit returns a middleware which rate limits the requests made to each site
+by each client (identified by its IP address) to limit requests per window of the
+limiter.
+
+It protects the endpoints open to anonymous clients, such as the contact form, from
+abuse. Requests exceeding the limit are rejected with a `429 Too Many Requests`
+response along with a `Retry-After` header. The responses carry the rate limit headers
+of the client (see `setRateLimitHeaders`), which override the ones of the site since
+the limit of the client is the tighter one. The middleware has to run after the
+`Tenant` middleware.
+
+Example:
+
+	limiter := ratelimit.New(time.Hour, services.SystemClock{})
+	r.With(middleware.ClientRateLimit(limiter, 5)).
+		Post("/contact", h.ContactHandler.SendContactMessage)
+*/
+func ClientRateLimit(
+	limiter *ratelimit.Limiter,
+	limit int,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := tenant.SiteID(r.Context()).String() + "/" + clientIP(r)
+
+			result := limiter.Allow(key, limit)
+			setRateLimitHeaders(w, result)
+			if !result.Allowed {
+				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the IP address of the client of the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/deprecation"
+)
+
+/*
+Deprecated returns a middleware which flags the responses of the named deprecated
+route with its `Deprecation` and `Sunset` headers, and counts its usage by the consumer
+making the request (see `deprecation.Registry.Flag`), so that the clients still calling
+it can notice it and move to its successor.
+
+The route has to be registered with the registry beforehand: Deprecated panics
+otherwise, so that a misspelled name is caught when the routes are set up.
+
+Example:
+
+	r.With(middleware.Deprecated(registry, "POST /articles/{id}/edit")).
+		Post("/{id}/edit", h.ArticleHandler.UpdateArticle)
+*/
+func Deprecated(
+	registry *deprecation.Registry,
+	name string,
+) func(http.Handler) http.Handler {
+	if _, ok := registry.Notice(name); !ok {
+		panic(fmt.Sprintf("deprecated route %q is not registered", name))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			registry.Flag(w, r, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/flags"
+)
+
+/*
+RequestFlags is a middleware which lets the authenticated requests ask for the values
+of the feature flags they are evaluated with, through their `X-Feature-Flags` header
+(e.g. `X-Feature-Flags: webmentions=on`), e.g. to try a feature out before enabling it
+on the site. The header of the anonymous requests is ignored.
+
+The middleware has to run after the `Authenticate` middleware: requests asking for
+undefined flags or malformed values are rejected with a `400 Bad Request` response.
+*/
+func RequestFlags(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("X-Feature-Flags")
+		if _, ok := auth.FromContext(r.Context()); !ok || header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		values, err := flags.Parse(header)
+		if err != nil {
+			http.Error(
+				w,
+				"Invalid X-Feature-Flags header: "+err.Error(),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(flags.NewContext(r.Context(), values)))
+	})
+}
+
+// FlagEvaluator evaluates the feature flags for a request.
+type FlagEvaluator interface {
+	Enabled(ctx context.Context, name string) bool
+}
+
+/*
+RequireFlag returns a middleware which only lets the requests for which the named
+feature flag is enabled through to the next handler, answering the other ones with a
+`404 Not Found` response as if the routes did not exist.
+
+Example:
+
+	r.With(middleware.RequireFlag(h.FlagHandler.FlagService, flags.Comments)).
+		Get("/comments/article/{id}", h.CommentHandler.GetCommentsFromArticle)
+*/
+func RequireFlag(evaluator FlagEvaluator, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !evaluator.Enabled(r.Context(), name) {
+				http.Error(w, "Not Found", http.StatusNotFound)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/Weburz/burzcontent/server/internal/geoip"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+/*
+GeoRestrict returns a middleware which locates the country of each request from the IP
+address of its client in the database, holds it in the context of the request (see
+`geoip.NewContext`) and applies the access rules of the country: the requests of the
+blocked countries, and the write requests of the read-only ones, are rejected with a
+`451 Unavailable For Legal Reasons` response.
+
+The requests whose country is unknown (e.g. made from a private network), or can not be
+located, are let through.
+
+Example:
+
+	db, _ := geoip.Open("/var/lib/GeoIP/GeoLite2-Country.mmdb")
+	rules, _ := geoip.ParseRules("KP=block, DE=read-only")
+	r.Use(middleware.GeoRestrict(db, rules))
+*/
+func GeoRestrict(db *geoip.DB, rules geoip.Rules) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			var country string
+			if addr, err := netip.ParseAddr(clientIP(r)); err == nil {
+				if country, err = db.Country(addr); err != nil {
+					logger.FromContext(ctx).WarnContext(
+						ctx, "Unable to locate client", "error", err,
+					)
+				}
+			}
+
+			switch rules.Mode(country) {
+			case geoip.ModeBlock:
+				http.Error(
+					w,
+					"Unavailable in your country",
+					http.StatusUnavailableForLegalReasons,
+				)
+				return
+			case geoip.ModeReadOnly:
+				if !isReadMethod(r.Method) {
+					http.Error(
+						w,
+						"Read-only in your country",
+						http.StatusUnavailableForLegalReasons,
+					)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(geoip.NewContext(ctx, country)))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+)
+
+/*
+Head discards the body of the responses to the `HEAD` requests, so that the clients
+(e.g. the CDNs and the link validators) get the headers of the full response without
+fetching it: its `ETag`, its `Last-Modified` header and an accurate `Content-Length`
+header, counted from the discarded body.
+
+The `HEAD` requests are only served by the routes registered for them, i.e. the `GET`
+routes whose handler has no side effect (e.g. the published articles, but not the
+one-time share links), which serve both methods. The other routes answer them with a
+`405 Method Not Allowed` problem.
+
+The middleware has to be registered on the root routers, so that the middleware
+registered after it (e.g. `Cache`, computing the `ETag` of the responses) sees their
+full body.
+
+Example:
+
+	router.Use(middleware.Head)
+	router.Get("/articles", h.ArticleHandler.GetPublishedArticles)
+	router.Head("/articles", h.ArticleHandler.GetPublishedArticles)
+*/
+func Head(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		hw := &headWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(hw, r)
+		hw.writeHeader()
+	})
+}
+
+// headWriter is an `http.ResponseWriter` discarding the body of a response while
+// counting its length, and holding its headers back until it is complete (or flushed)
+// so that they carry its length.
+type headWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	sent        bool
+	length      int
+}
+
+func (hw *headWriter) WriteHeader(status int) {
+	if !hw.wroteHeader {
+		hw.status = status
+		hw.wroteHeader = true
+	}
+}
+
+func (hw *headWriter) Write(b []byte) (int, error) {
+	hw.wroteHeader = true
+	hw.length += len(b)
+
+	return len(b), nil
+}
+
+// Flush sends the headers of the response without waiting for it to be complete, e.g.
+// for the event streams, whose length is unknown.
+func (hw *headWriter) Flush() {
+	if !hw.sent {
+		hw.sent = true
+		hw.ResponseWriter.WriteHeader(hw.status)
+	}
+
+	if flusher, ok := hw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// writeHeader sends the headers of the complete response, with the length of its body
+// unless the handler set it or the status does not allow a body.
+func (hw *headWriter) writeHeader() {
+	if hw.sent {
+		return
+	}
+	hw.sent = true
+
+	bodyless := hw.status < http.StatusOK ||
+		hw.status == http.StatusNoContent ||
+		hw.status == http.StatusNotModified
+	if !bodyless && hw.Header().Get("Content-Length") == "" {
+		hw.Header().Set("Content-Length", strconv.Itoa(hw.length))
+	}
+
+	hw.ResponseWriter.WriteHeader(hw.status)
+}
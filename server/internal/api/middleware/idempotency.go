@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/idempotency"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// maxIdempotencyKeyLength is the maximum length of the `Idempotency-Key` header.
+const maxIdempotencyKeyLength = 255
+
+/*
+Idempotent returns a middleware which makes the write requests carrying an
+`Idempotency-Key` header idempotent: the retries of a request made with the same key
+are answered with the response to the first request, along with an
+`Idempotent-Replayed: true` header, instead of being processed again (e.g. creating
+the same article twice). The keys are scoped to the site and to the API key making the
+request.
+
+A key can only be reused for the very same request (method, URL and body); it is
+rejected with a `422 Unprocessable Entity` response otherwise, and with a `409
+Conflict` response while the first request is still being processed. The responses
+with a 5xx status are not recorded, so that the request can be retried.
+
+The requests without any key are processed as usual. The middleware has to run after
+the `Tenant` and `Authenticate` middleware.
+
+Example:
+
+	store := idempotency.New(24*time.Hour, services.SystemClock{})
+	r.With(middleware.Idempotent(store)).Post("/", h.ArticleHandler.CreateArticle)
+*/
+func Idempotent(store *idempotency.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || isReadMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				http.Error(w, "Invalid Idempotency-Key header", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "Unable to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key = tenant.SiteID(r.Context()).String() + "/" + key
+			if principal, ok := auth.FromContext(r.Context()); ok {
+				key = principal.KeyID.String() + "/" + key
+			}
+
+			digest := sha256.New()
+			_, _ = io.WriteString(digest, r.Method+" "+r.URL.RequestURI()+"\n")
+			_, _ = digest.Write(body)
+
+			response, err := store.Begin(key, hex.EncodeToString(digest.Sum(nil)))
+			switch {
+			case errors.Is(err, idempotency.ErrInProgress):
+				http.Error(
+					w,
+					"A request with the same idempotency key is in progress",
+					http.StatusConflict,
+				)
+				return
+			case errors.Is(err, idempotency.ErrMismatch):
+				http.Error(
+					w,
+					"Idempotency key already used for another request",
+					http.StatusUnprocessableEntity,
+				)
+				return
+			case response != nil:
+				replay(w, response)
+				return
+			}
+
+			// The key is released if the handler panics, so that the request can be
+			// retried
+			recorded := false
+			defer func() {
+				if !recorded {
+					store.Abort(key)
+				}
+			}()
+
+			buf := &bufferedWriter{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(buf, r)
+
+			if buf.status < http.StatusInternalServerError {
+				store.Complete(key, idempotency.Response{
+					Status: buf.status,
+					Header: w.Header().Clone(),
+					Body:   bytes.Clone(buf.body.Bytes()),
+				})
+				recorded = true
+			}
+
+			w.WriteHeader(buf.status)
+			_, _ = w.Write(buf.body.Bytes())
+		})
+	}
+}
+
+// replay writes the recorded response. The headers already set by the middleware
+// running beforehand (e.g. the rate limit headers) are kept rather than replayed.
+func replay(w http.ResponseWriter, response *idempotency.Response) {
+	for name, values := range response.Header {
+		if _, ok := w.Header()[name]; !ok {
+			w.Header()[name] = values
+		}
+	}
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(response.Status)
+	_, _ = w.Write(response.Body)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+/*
+LoadShedder returns a middleware which limits the number of requests processed
+concurrently and sheds the excess load with a `503 Service Unavailable` response.
+
+Requests are split in two separate budgets, so that a burst of expensive writes can
+not starve the cheap reads (and vice-versa):
+  - Reads: `GET`, `HEAD` and `OPTIONS` requests, limited by maxReads.
+  - Writes: every other request method, limited by maxWrites.
+
+A limit of zero (or less) disables the respective budget. The middleware never queues
+requests; when a budget is exhausted the request is rejected straight away along with
+a `Retry-After` header, which protects the database from piling up work during traffic
+spikes.
+
+Example:
+
+	r.Use(middleware.LoadShedder(cfg.MaxReadRequests, cfg.MaxWriteRequests))
+*/
+func LoadShedder(maxReads, maxWrites int) func(http.Handler) http.Handler {
+	reads := newSemaphore(maxReads)
+	writes := newSemaphore(maxWrites)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sem := writes
+			if isReadMethod(r.Method) {
+				sem = reads
+			}
+
+			if !sem.tryAcquire() {
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Server is overloaded", http.StatusServiceUnavailable)
+				return
+			}
+			defer sem.release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isReadMethod reports whether the HTTP method does not modify any resource.
+func isReadMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// semaphore is a non-blocking counting semaphore; a nil semaphore is unlimited.
+type semaphore chan struct{}
+
+// newSemaphore returns a semaphore with the given capacity, or nil if the capacity
+// is not positive.
+func newSemaphore(capacity int) semaphore {
+	if capacity <= 0 {
+		return nil
+	}
+
+	return make(semaphore, capacity)
+}
+
+// tryAcquire acquires a slot without blocking and reports whether it succeeded.
+func (s semaphore) tryAcquire() bool {
+	if s == nil {
+		return true
+	}
+
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot previously acquired with tryAcquire.
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
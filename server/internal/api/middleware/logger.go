@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+/*
+RequestLogger returns a middleware which stores the logger in the context of each
+request (see the `logger` package), with the ID of the request attached, so that the
+lines logged while serving a request can be told apart. The `Authenticate` middleware
+attaches the API key and the user making the request later on.
+
+Once a request picked by the sampler (see `logger.Sampler`) is served, it is logged
+along with the attributes the latency dashboards are built from (the requests being
+sampled once, alike by every log of the server, see `logger.NewStatsContext`):
+  - status: The HTTP status code of the response.
+  - bytes: The size of the body of the response.
+  - duration: The time spent serving the request.
+  - store_time: The time spent in the lookups of the store (see `logger.Stats`).
+  - cache: Whether the response was served from the caches of the clients ("hit",
+    answered with `304 Not Modified`) or not ("miss"), for the cached routes only.
+
+The requests answered with a server error are logged at the error level, the others at
+the info level. The middleware has to run after the `RequestID` middleware of chi.
+
+Example:
+
+	r.Use(chimiddleware.RequestID)
+	r.Use(middleware.RequestLogger(h.Logger, nil))
+*/
+func RequestLogger(
+	baseLog *slog.Logger,
+	sampler *logger.Sampler,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := chimiddleware.GetReqID(r.Context())
+			requestLog := baseLog.With("request_id", requestID)
+			ctx := logger.NewContext(r.Context(), requestLog)
+			ctx, stats := logger.NewStatsContext(ctx)
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := max(ww.Status(), http.StatusOK)
+			if !sampler.Sample(ctx, r.Method, r.URL.Path, status) {
+				return
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+				slog.Duration("store_time", stats.StoreTime()),
+			}
+			if cache := stats.CacheStatus(); cache != "" {
+				attrs = append(attrs, slog.String("cache", cache))
+			}
+
+			requestLog.LogAttrs(ctx, level, "request", attrs...)
+		})
+	}
+}
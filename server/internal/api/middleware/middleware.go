@@ -0,0 +1,12 @@
+/*
+Package middleware provides the HTTP middleware used by the API server.
+
+The middleware in this package complements the ones shipped with the
+`github.com/go-chi/chi/v5/middleware` package and covers the concerns which are
+specific to BurzContent, such as guarding the internal endpoints behind an access
+token.
+
+Each middleware follows the standard `func(http.Handler) http.Handler` signature so
+that it can be registered on a chi router with `Use()` or `With()`.
+*/
+package middleware
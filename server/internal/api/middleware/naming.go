@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Weburz/burzcontent/server/internal/naming"
+)
+
+// verbatimJSONFields lists the fields of the responses whose objects are kept as is
+// when their fields are renamed, their keys being data rather than the names of
+// fields: the payloads of the tasks and of the webhook deliveries, the usage indexed by
+// day, the checksums of the backups indexed by file, the feature flags of the sites
+// indexed by name and the social links of the users indexed by network.
+var verbatimJSONFields = []string{
+	"payload",
+	"requests",
+	"rate_limited",
+	"checksums",
+	"flags",
+	"social_links",
+}
+
+/*
+JSONNaming returns a middleware which names the fields of the JSON responses (and of
+the JSON request bodies) in the naming convention the client asks for with the
+`profile` parameter of its `Accept` header, e.g. `Accept: application/vnd.api+json;
+profile="camelCase"` or `profile="snake_case"` (see the `naming` package), or in the
+given fallback convention if it asks for none. The `profile="default"` parameter asks
+for the fields as the models declare them, whatever the fallback.
+
+The responses whose fields are renamed are buffered, and carry the convention in the
+`profile` parameter of their `Content-Type` header, while the other ones (e.g. the
+event streams) are written through. The convention is stored in the context of the
+request, for the handlers to match the fields of the request bodies whichever
+convention they follow (see `naming.Match`). Every response varies on the `Accept`
+header, for the shared caches to keep both representations apart.
+
+Example:
+
+	r.Use(middleware.JSONNaming(naming.CamelCase))
+*/
+func JSONNaming(fallback naming.Convention) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+
+			convention := acceptedConvention(r.Header.Get("Accept"), fallback)
+			if convention == naming.Default {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Revalidate the responses against the entity tags of the representations
+			// in the default convention, which the `Cache` middleware computes
+			suffix := "-" + string(convention) + `"`
+			if etag := r.Header.Get("If-None-Match"); strings.HasSuffix(etag, suffix) {
+				r.Header.Set("If-None-Match", strings.TrimSuffix(etag, suffix)+`"`)
+			}
+
+			nw := &namingWriter{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+				suffix:         suffix,
+			}
+			ctx := naming.NewContext(r.Context(), convention)
+			next.ServeHTTP(nw, r.WithContext(ctx))
+
+			if !nw.buffering {
+				return
+			}
+
+			body := nw.body.Bytes()
+			renamed, err := naming.Rewrite(body, convention, verbatimJSONFields)
+			if err == nil {
+				body = renamed
+				contentType := withProfile(w.Header().Get("Content-Type"), convention)
+				w.Header().Set("Content-Type", contentType)
+				nw.tagETag()
+			}
+
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(nw.status)
+			_, _ = w.Write(body)
+		})
+	}
+}
+
+// acceptedConvention returns the naming convention asked for by the `profile` parameter
+// of the media types of an `Accept` header, or fallback if none is.
+func acceptedConvention(accept string, fallback naming.Convention) naming.Convention {
+	for mediaType := range strings.SplitSeq(accept, ",") {
+		_, params, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			continue
+		}
+
+		// The profile parameter holds a space-separated list of profiles, among which
+		// "default" asks for the fields as the models declare them
+		for profile := range strings.FieldsSeq(params["profile"]) {
+			if convention, err := naming.Parse(profile); err == nil {
+				return convention
+			}
+		}
+	}
+
+	return fallback
+}
+
+// withProfile returns the media type of a `Content-Type` header with the naming
+// convention in its `profile` parameter.
+func withProfile(contentType string, convention naming.Convention) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	params["profile"] = string(convention)
+
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// namingWriter is an `http.ResponseWriter` buffering the JSON responses, for their
+// fields to be renamed, and writing the other ones through.
+type namingWriter struct {
+	http.ResponseWriter
+	suffix      string // The suffix of the entity tags of the renamed responses
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (nw *namingWriter) WriteHeader(status int) {
+	if nw.wroteHeader {
+		return
+	}
+	nw.wroteHeader = true
+	nw.status = status
+
+	mediaType, _, _ := mime.ParseMediaType(nw.Header().Get("Content-Type"))
+	nw.buffering = status != http.StatusNoContent &&
+		status != http.StatusNotModified &&
+		(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+	if !nw.buffering {
+		// The revalidated responses stand for the renamed representations
+		if status == http.StatusNotModified {
+			nw.tagETag()
+		}
+		nw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// tagETag appends the suffix of the renamed representations to the entity tag of the
+// response, if it has one.
+func (nw *namingWriter) tagETag() {
+	if etag := nw.Header().Get("ETag"); strings.HasSuffix(etag, `"`) {
+		nw.Header().Set("ETag", strings.TrimSuffix(etag, `"`)+nw.suffix)
+	}
+}
+
+func (nw *namingWriter) Write(b []byte) (int, error) {
+	if !nw.wroteHeader {
+		nw.WriteHeader(http.StatusOK)
+	}
+	if nw.buffering {
+		return nw.body.Write(b)
+	}
+
+	return nw.ResponseWriter.Write(b)
+}
+
+// Flush flushes the responses written through, e.g. the event streams.
+func (nw *namingWriter) Flush() {
+	if flusher, ok := nw.ResponseWriter.(http.Flusher); ok && !nw.buffering {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/params"
+)
+
+/*
+UUIDParams returns a middleware which parses the named URL parameters of the matched
+route as UUIDs and stores them in the request context (see the `params` package), so
+that the handlers do not have to.
+
+Requests whose parameter is not a valid UUID are rejected with a `400 Bad Request`
+response. The parameters the matched route does not hold are skipped. The URL
+parameters are only known once the route is matched, hence the middleware has to be
+registered for the routes themselves (e.g. with `With` or within a `Group`), not on
+the router mounting them.
+
+Example:
+
+	r.With(middleware.UUIDParams("id")).Get("/{id}", h.PageHandler.GetPageByID)
+*/
+func UUIDParams(names ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			for _, name := range names {
+				value := chi.URLParam(r, name)
+				if value == "" {
+					continue
+				}
+
+				id, err := uuid.Parse(value)
+				if err != nil {
+					message := fmt.Sprintf(
+						"Invalid %s parameter: %q is not a UUID", name, value,
+					)
+					http.Error(w, message, http.StatusBadRequest)
+					return
+				}
+
+				ctx = params.NewContext(ctx, name, id)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+)
+
+// PolicyChecker tells whether a user has to accept the current policy of a site, like
+// `services.PolicyService` does.
+type PolicyChecker interface {
+	PendingPolicy(ctx context.Context, userID uuid.UUID) (models.Policy, bool, error)
+}
+
+/*
+RequirePolicyAcceptance returns a middleware which rejects the requests modifying the
+content of a site made by the users who did not accept the current policy of the site
+(e.g. its terms of service), if it requires an acceptance, with a `403 Forbidden`
+response.
+
+Read requests, the requests made with API keys which are not owned by any user (e.g.
+the root API key) and the requests to the paths ending with one of exemptPaths (e.g.
+the ones accepting the policy, or opening the session of the admin interface) are
+always let through. The middleware has to run after the
+`Tenant` and `Authenticate` middlewares.
+
+Example:
+
+	r.Use(middleware.RequirePolicyAcceptance(h.PolicyHandler.PolicyService,
+		"/policies/accept", "/session"))
+*/
+func RequirePolicyAcceptance(
+	checker PolicyChecker,
+	exemptPaths ...string,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := auth.FromContext(r.Context())
+			exempt := slices.ContainsFunc(exemptPaths, func(path string) bool {
+				return strings.HasSuffix(r.URL.Path, path)
+			})
+			if isReadMethod(r.Method) || principal.UserID == uuid.Nil || exempt {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy, pending, err := checker.PendingPolicy(r.Context(), principal.UserID)
+			if err != nil {
+				http.Error(
+					w,
+					"Unable to check policy acceptance",
+					http.StatusInternalServerError,
+				)
+				return
+			}
+
+			if pending {
+				http.Error(
+					w,
+					"The policy "+policy.Version+" has to be accepted first",
+					http.StatusForbidden,
+				)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
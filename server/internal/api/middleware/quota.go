@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/ratelimit"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// UsageTracker provides the quotas of the sites and records their usage.
+type UsageTracker interface {
+	Quota(site models.Site) models.SiteQuota
+	RecordRequest(ctx context.Context, siteID uuid.UUID, rateLimited bool) error
+	StorageUsage(ctx context.Context, siteID uuid.UUID) (int64, error)
+}
+
+/*
+SiteRateLimit returns a middleware which rate limits the requests made to each site
+according to the `RequestsPerMinute` quota of the site.
+
+Every request is counted in the usage of the site, whether it is served or rejected.
+Requests exceeding the quota are rejected with a `429 Too Many Requests` response along
+with a `Retry-After` header. Every response carries the rate limit headers of the site
+(see `setRateLimitHeaders`), so that the clients can back off before being rejected.
+
+The middleware has to run after the `Tenant` middleware.
+*/
+func SiteRateLimit(
+	limiter *ratelimit.Limiter,
+	tracker UsageTracker,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			site, _ := tenant.FromContext(r.Context())
+			quota := tracker.Quota(site)
+
+			result := limiter.Allow(site.ID.String(), quota.RequestsPerMinute)
+			_ = tracker.RecordRequest(r.Context(), site.ID, !result.Allowed)
+
+			setRateLimitHeaders(w, result)
+			if !result.Allowed {
+				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+/*
+setRateLimitHeaders sets the headers describing the rate limit the request was subject
+to, unless the rate limiting is disabled:
+  - X-RateLimit-Limit: The maximum number of requests allowed per window.
+  - X-RateLimit-Remaining: The number of requests which can still be made right away.
+  - X-RateLimit-Reset: The number of seconds after which the limit is fully reset.
+  - Retry-After: The number of seconds after which a rejected request may be retried.
+*/
+func setRateLimitHeaders(w http.ResponseWriter, result ratelimit.Result) {
+	if result.Limit <= 0 {
+		return
+	}
+
+	seconds := func(d time.Duration) string {
+		return strconv.Itoa(int(math.Ceil(d.Seconds())))
+	}
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", seconds(result.Reset))
+
+	if !result.Allowed {
+		w.Header().Set("Retry-After", seconds(max(result.RetryAfter, time.Second)))
+	}
+}
+
+/*
+StorageQuota returns a middleware which rejects the requests modifying the content of a
+site once the site uses up its `StorageBytes` quota, with a `507 Insufficient Storage`
+response.
+
+Read requests (and deletions, which free storage) are always let through. The
+middleware has to run after the `Tenant` middleware.
+*/
+func StorageQuota(tracker UsageTracker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isReadMethod(r.Method) || r.Method == http.MethodDelete {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			site, _ := tenant.FromContext(r.Context())
+			quota := tracker.Quota(site)
+
+			used, err := tracker.StorageUsage(r.Context(), site.ID)
+			if err != nil {
+				http.Error(w, "Unable to measure storage", http.StatusInternalServerError)
+				return
+			}
+
+			if quota.StorageBytes > 0 && used >= quota.StorageBytes {
+				http.Error(w, "Storage quota exceeded", http.StatusInsufficientStorage)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Weburz/burzcontent/server/internal/errreport"
+)
+
+/*
+Recover returns a middleware which recovers from the panics of the next handlers and
+middleware, so that a panic only fails the request which caused it.
+
+The panic is logged with the logger, along with the ID of the request and the stack
+trace, and reported (see the `errreport` package), and the request is answered with a
+500 (Internal Server Error) status. The `http.ErrAbortHandler` panics, which abort the
+response on purpose, are let through. The middleware has to run right after the
+`RequestID` middleware of chi, so that the panics of the other middleware are
+recovered as well.
+
+Example:
+
+	r.Use(chimiddleware.RequestID)
+	r.Use(middleware.Recover(h.Logger))
+*/
+func Recover(baseLog *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				err, ok := recovered.(error)
+				if ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+
+				baseLog.ErrorContext(
+					r.Context(),
+					"Panic serving request",
+					"request_id", chimiddleware.GetReqID(r.Context()),
+					"method", r.Method,
+					"url", r.URL.String(),
+					"panic", recovered,
+					"stack", string(debug.Stack()),
+				)
+				errreport.ReportPanic(r, recovered)
+
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
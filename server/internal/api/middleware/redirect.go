@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	chi "github.com/go-chi/chi/v5"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// RedirectResolver resolves the redirect of a path of the site held by the context.
+type RedirectResolver interface {
+	ResolveRedirect(ctx context.Context, path string) (models.Redirect, error)
+}
+
+/*
+Redirects returns a middleware which serves the redirects configured for the site a
+request is resolved to, so that the old URLs of the content keep working when its
+slugs change or when it is migrated from an old site.
+
+Only the `GET` and `HEAD` requests are redirected, the others (and the paths which are
+not redirected) are passed on to the next handler. The paths are matched within the
+site: for a site served under the `/s/{site}` path prefix, the prefix is stripped
+before matching and prepended to the target when it is a path of the site. The
+middleware has to run after the `Tenant` middleware.
+*/
+func Redirects(resolver RedirectResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The path within the site, without the `/s/{site}` prefix (if any)
+			path := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx.RoutePath != "" {
+				path = rctx.RoutePath
+			}
+
+			redirect, err := resolver.ResolveRedirect(r.Context(), path)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			target := redirect.Target
+			if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") {
+				target = strings.TrimSuffix(r.URL.Path, path) + target
+			}
+
+			http.Redirect(w, r, target, redirect.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	chi "github.com/go-chi/chi/v5"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// SiteResolver resolves the site (the tenant) a request is meant for.
+type SiteResolver interface {
+	ResolveByHostname(ctx context.Context, hostname string) (models.Site, error)
+	ResolveBySlug(ctx context.Context, slug string) (models.Site, error)
+}
+
+/*
+Tenant returns a middleware which resolves the site a request is meant for and stores
+it in the request context (see the `tenant` package).
+
+The site is resolved as follows:
+  - By path prefix, if the matched route holds a `{site}` URL parameter (e.g.
+    `/s/weburz/articles`), using the slug of the site.
+  - By hostname otherwise, using the `Host` header of the request (without the port).
+
+Requests which can not be resolved to any site are rejected with a `404 Not Found`
+response, so that they never reach the handlers.
+*/
+func Tenant(resolver SiteResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				site models.Site
+				err  error
+			)
+
+			if slug := chi.URLParam(r, "site"); slug != "" {
+				site, err = resolver.ResolveBySlug(r.Context(), slug)
+			} else {
+				site, err = resolver.ResolveByHostname(r.Context(), hostname(r))
+			}
+
+			if err != nil {
+				http.Error(w, "Site Not Found", http.StatusNotFound)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), site)))
+		})
+	}
+}
+
+// hostname returns the host of the request without the port.
+func hostname(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		return r.Host
+	}
+
+	return host
+}
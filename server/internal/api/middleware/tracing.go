@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Weburz/burzcontent/server/internal/tracing"
+)
+
+/*
+Trace returns a middleware which stores the span context of each request in its
+context (see the `tracing` package), so that the records logged within the request
+carry the IDs of its trace.
+
+The request continues the trace of its `traceparent` header, in a span of its own, or
+starts a new trace if the header is missing or invalid. The middleware has to run
+before the `RequestLogger` middleware, so that the request is logged with its trace.
+
+Example:
+
+	r.Use(middleware.Trace)
+	r.Use(middleware.RequestLogger(h.Logger, nil))
+*/
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc, ok := tracing.Parse(r.Header.Get("traceparent"))
+		if !ok {
+			sc = tracing.SpanContext{TraceID: tracing.NewTraceID()}
+		}
+		sc.SpanID = tracing.NewSpanID()
+
+		next.ServeHTTP(w, r.WithContext(tracing.NewContext(r.Context(), sc)))
+	})
+}
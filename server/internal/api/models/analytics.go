@@ -0,0 +1,81 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `PageView` struct that represents a page view recorded by the first-party
+    analytics of a site.
+  - The `TopArticle` struct that represents the audience of an article over a period.
+  - The `CountryAudience` struct that represents the audience of a site in a country.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+PageView represents a page of a site viewed by a reader.
+
+No personal data is recorded: the reader is only identified by an anonymous visitor
+hash, derived from the truncated IP address and the user agent of the reader with a
+salt rotated daily, so that the readers can be counted within a day but never tracked
+across days.
+
+Fields:
+  - ID: The unique identifier for the page view (UUID).
+  - SiteID: The unique identifier of the site the page belongs to (UUID).
+  - ArticleID: The unique identifier of the viewed article, if the page is one.
+  - Path: The path of the viewed page.
+  - Referrer: The host of the page the reader came from, if any.
+  - Experiment: The key of the experiment the page exposed the reader to, if any.
+  - Variant: The key of the variant of the experiment the reader was exposed to.
+  - Country: The ISO 3166-1 alpha-2 code of the country of the reader, if located.
+  - Visitor: The anonymous visitor hash of the reader.
+  - At: When the page was viewed.
+*/
+type PageView struct {
+	ID         uuid.UUID  `json:"id"`
+	SiteID     uuid.UUID  `json:"site_id"`
+	ArticleID  *uuid.UUID `json:"article_id,omitempty"`
+	Path       string     `json:"path"                 validate:"required_without=ArticleID,max=2048"`
+	Referrer   string     `json:"referrer,omitempty"   validate:"max=2048"`
+	Experiment string     `json:"experiment,omitempty" validate:"required_with=Variant,max=64"`
+	Variant    string     `json:"variant,omitempty"    validate:"required_with=Experiment,max=64"`
+	Country    string     `json:"country,omitempty"`
+	Visitor    string     `json:"-"`
+	At         time.Time  `json:"at"`
+}
+
+/*
+TopArticle represents the audience of an article over a period.
+
+Fields:
+  - ArticleID: The unique identifier of the article (UUID).
+  - Title: The title of the article.
+  - Views: The number of times the article was viewed.
+  - Visitors: The number of unique (daily) visitors of the article.
+*/
+type TopArticle struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Title     string    `json:"title"`
+	Views     int       `json:"views"`
+	Visitors  int       `json:"visitors"`
+}
+
+/*
+CountryAudience represents the audience of a site in a country over a period.
+
+Fields:
+  - Country: The ISO 3166-1 alpha-2 code of the country, empty for the readers whose
+    country is unknown.
+  - Views: The number of pages viewed from the country.
+  - Visitors: The number of unique (daily) visitors from the country.
+*/
+type CountryAudience struct {
+	Country  string `json:"country"`
+	Views    int    `json:"views"`
+	Visitors int    `json:"visitors"`
+}
@@ -0,0 +1,51 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `APIKey` struct that represents an API key scoped to a site.
+  - The `Usage` struct that represents the usage report of a site.
+*/
+
+package models
+
+import "github.com/google/uuid"
+
+/*
+APIKey represents an API key used to authenticate requests made to a single site.
+
+Fields:
+  - ID: The unique identifier for the API key (UUID).
+  - SiteID: The unique identifier of the site the API key is scoped to (UUID).
+  - UserID: The unique identifier of the user owning the API key (UUID), if any.
+  - Name: A human-readable name describing what the API key is used for.
+  - Prefix: The first characters of the API key, used to identify it.
+  - Role: The role granted to the requests authenticated with the API key.
+  - Hash: The SHA-256 digest of the API key, which is never serialized.
+*/
+type APIKey struct {
+	ID     uuid.UUID `json:"id"`
+	SiteID uuid.UUID `json:"site_id"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"    validate:"required"`
+	Prefix string    `json:"prefix"`
+	Role   string    `json:"role"    validate:"required,oneof=admin editor author"`
+	Hash   string    `json:"-"`
+}
+
+/*
+Usage represents the usage report of a site, used for billing purposes.
+
+Fields:
+  - SiteID: The unique identifier of the site (UUID).
+  - Requests: The number of requests served, indexed by day (YYYY-MM-DD).
+  - RateLimited: The number of requests rejected by the rate limiter, indexed by day.
+  - StorageBytes: The storage used by the content of the site, in bytes.
+  - Quota: The quotas of the site the usage is measured against.
+*/
+type Usage struct {
+	SiteID       uuid.UUID        `json:"site_id"`
+	Requests     map[string]int64 `json:"requests"`
+	RateLimited  map[string]int64 `json:"rate_limited"`
+	StorageBytes int64            `json:"storage_bytes"`
+	Quota        SiteQuota        `json:"quota"`
+}
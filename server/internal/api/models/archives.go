@@ -0,0 +1,24 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `ArchiveMonth` struct that represents a month of the archives of a site, i.e.
+    a month articles were published in.
+*/
+
+package models
+
+/*
+ArchiveMonth represents a month of the archives of a site, in the time zone of the
+site.
+
+Fields:
+  - Year: The year of the month (e.g. 2025).
+  - Month: The month of the year, from 1 (January) to 12 (December).
+  - Count: The number of articles published in the month.
+*/
+type ArchiveMonth struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Count int `json:"count"`
+}
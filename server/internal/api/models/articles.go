@@ -3,25 +3,122 @@ Package models provides data structures related to the entities in the system.
 
 It includes:
   - The `Article` struct that represents an article with fields for its unique ID,
-    title, author, and publication status.
+    title, author, body, and publication status.
+  - The `ArticleBody` struct that holds the slug, the content, the tags and the
+    publication and expiry dates of an article.
+  - The `BulkArticles` and `BulkResult` structs that represent an operation applied
+    to a batch of articles and its outcome for each of them.
 */
 
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 /*
 Article represents an article with its associated data.
 
 Fields:
   - ID: The unique identifier for the article (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ShortID: The short alias of the ID within the site (8 base62 characters), under
+    which the article can be shared (e.g. `/a/Xk9Qz2Lp`), if it has one.
   - Title: The title of the article.
   - Author: The author of the article.
+  - AuthorID: The unique identifier of the user who authored the article (UUID), if
+    the author is a user of the site. It is kept when the user is renamed.
   - Published: A boolean indicating if the article is published.
+  - DeletedAt: When the article was moved to the trash, if it is there.
+  - CreatedAt: When the article was created.
+  - UpdatedAt: When the article was last updated (when it was created if never).
+  - Version: The version of the article, starting at 1 and incremented on each update.
+    The updates have to give the version they were made from, so that they do not
+    overwrite the updates made in the meantime (optimistic locking).
+  - CommentCount: The number of comments made on the article (all of them, there
+    being no moderation of the comments), which is computed when the article is served
+    rather than stored.
+  - RenderedHTML: The content of the article rendered for its readers, i.e. with its
+    shortcodes (e.g. `[youtube dQw4w9WgXcQ]`) expanded into their (sanitized) embeds.
+  - ArticleBody: The slug, content, tags and publication date of the article, whose
+    fields are inlined in the JSON representation of the article.
 */
 type Article struct {
-	ID          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Author      string    `json:"author"`
-	IsPublished bool      `json:"isPublished"`
+	ID           uuid.UUID  `json:"id"`
+	SiteID       uuid.UUID  `json:"site_id"`
+	ShortID      string     `json:"short_id,omitempty"`
+	Title        string     `json:"title"`
+	Author       string     `json:"author"`
+	AuthorID     *uuid.UUID `json:"author_id,omitempty"`
+	IsPublished  bool       `json:"isPublished"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Version      int        `json:"version"`
+	CommentCount int        `json:"comment_count"`
+	RenderedHTML string     `json:"rendered_html,omitempty"`
+	ArticleBody
+}
+
+/*
+ArticleBody holds the body of an article, every field of which is optional.
+
+Fields:
+  - Slug: The URL-friendly identifier of the article within its site (e.g.
+    "go-programming-basics").
+  - Content: The (HTML) content of the article.
+  - Tags: The tags the article is classified with.
+  - PublishedAt: When the article was first published, if ever. It is set when the
+    article is first published unless given, e.g. when importing existing content.
+  - ExpiresAt: When the article is automatically unpublished, if ever (e.g. for a
+    promotion or an event announcement). It has to be moved or cleared to publish the
+    article again once expired.
+  - PublishAt: When the unpublished article is automatically published, if ever
+    (scheduled publishing). It is cleared once the article is published.
+
+The dates are stored in UTC, but given in the time zone of the site (see
+`SiteSettings.Timezone`), in which the dates given without a UTC offset (e.g.
+"2026-03-29T09:30") are taken too.
+*/
+type ArticleBody struct {
+	Slug        string     `json:"slug,omitempty"         validate:"omitempty,max=200,lowercase"`
+	Content     string     `json:"content,omitempty"`
+	Tags        []string   `json:"tags,omitempty"         validate:"dive,required,max=64"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+}
+
+// AuthoredBy reports whether the article was authored by the user identified by id.
+func (a Article) AuthoredBy(id uuid.UUID) bool {
+	return a.AuthorID != nil && *a.AuthorID == id
+}
+
+/*
+BulkArticles represents a batch of articles an operation (e.g. publishing them) is
+applied to.
+
+Fields:
+  - IDs: The unique identifiers of the articles, up to 100.
+*/
+type BulkArticles struct {
+	IDs []uuid.UUID `json:"ids" validate:"required,min=1,max=100"`
+}
+
+/*
+BulkResult represents the outcome of an operation applied to an article of a batch.
+
+Fields:
+  - ID: The unique identifier of the article (UUID).
+  - Success: Whether the operation succeeded for the article.
+  - Error: Why the operation failed for the article, if it did.
+  - Article: The article after the operation, if it succeeded.
+*/
+type BulkResult struct {
+	ID      uuid.UUID `json:"id"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+	Article *Article  `json:"article,omitempty"`
 }
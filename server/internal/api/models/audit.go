@@ -0,0 +1,41 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `AuditEntry` struct that represents a request made to the management API.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+AuditEntry represents a write request made to the management API, recorded for
+accountability purposes.
+
+Fields:
+  - ID: The unique identifier for the entry (UUID).
+  - SiteID: The unique identifier of the site the request was made to (UUID), nil for
+    the requests managing the sites themselves.
+  - KeyID: The unique identifier of the API key the request was authenticated with
+    (UUID), nil for the root API key.
+  - Role: The role granted to the API key.
+  - Method: The HTTP method of the request.
+  - Path: The path of the request.
+  - Status: The HTTP status code of the response.
+  - At: When the request was served.
+*/
+type AuditEntry struct {
+	ID     uuid.UUID `json:"id"`
+	SiteID uuid.UUID `json:"site_id"`
+	KeyID  uuid.UUID `json:"key_id"`
+	Role   string    `json:"role"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Status int       `json:"status"`
+	At     time.Time `json:"at"`
+}
@@ -0,0 +1,72 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Backup` struct that represents a backup of the content of a site.
+  - The `BackupManifest` struct that describes a backup.
+  - The `BackupMedia` struct that represents a media file listed by a backup.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackupVersion is the version of the format of the backups created by the server.
+const BackupVersion = 1
+
+/*
+Backup represents a backup of the content of a site, i.e. a snapshot of its resources
+along with the manifest of its media files.
+
+Fields:
+  - Manifest: The description of the backup.
+  - Users: The users of the site.
+  - Articles: The articles of the site.
+  - Comments: The comments of the site.
+  - Media: The media files of the site, which are listed but not embedded.
+*/
+type Backup struct {
+	Manifest BackupManifest
+	Users    []User
+	Articles []Article
+	Comments []Comment
+	Media    []BackupMedia
+}
+
+/*
+BackupManifest describes a backup.
+
+Fields:
+  - Version: The version of the format of the backup (see `BackupVersion`).
+  - CreatedAt: When the backup was created.
+  - SiteID: The unique identifier of the backed up site (UUID).
+  - SiteSlug: The slug of the backed up site.
+  - Counts: The number of resources of each type in the backup.
+  - Checksums: The SHA-256 checksum (hex) of each file of the backup archive.
+*/
+type BackupManifest struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	SiteID    uuid.UUID         `json:"site_id"`
+	SiteSlug  string            `json:"site_slug"`
+	Counts    map[string]int    `json:"counts"`
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
+/*
+BackupMedia represents a media file listed by a backup.
+
+Fields:
+  - Path: The path of the file in the media storage.
+  - Size: The size of the file, in bytes.
+  - SHA256: The SHA-256 checksum (hex) of the file.
+*/
+type BackupMedia struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
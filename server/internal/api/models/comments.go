@@ -4,24 +4,101 @@ Package models provides data structures related to entities in the system.
 It includes:
   - The `Comment` struct that represents a comment made by a user on an article,
     including fields for the unique ID, name, email, and content of the comment.
+  - The `PublicComment` struct that represents the public view of a comment, as
+    served to anonymous readers.
+  - The `Mention` struct that represents a user mentioned in a comment (e.g.
+    "@jane-doe").
 */
 
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 /*
 Comment represents a user comment on an article.
 
 Fields:
   - ID: The unique identifier for the comment (UUID).
+  - SiteID: The unique identifier of the site the comment belongs to (UUID).
+  - ArticleID: The unique identifier of the article the comment was made on (UUID).
   - Name: The name of the person who made the comment.
   - Email: The email address of the person who made the comment.
-  - Content: The text content of the comment.
+  - Content: The text content of the comment, in Markdown (see `markdown.ToHTML` for
+    the supported subset).
+  - RenderedHTML: The content of the comment rendered to (sanitized) HTML.
+  - CreatedAt: When the comment was made.
+  - UpdatedAt: When the comment was last updated, e.g. when it was anonymized (when it
+    was made if never).
 */
 type Comment struct {
-	ID      uuid.UUID `json:"id"`
-	Name    string    `json:"name"`
-	Email   string    `json:"email"`
-	Content string    `json:"content"`
+	ID           uuid.UUID `json:"id"`
+	SiteID       uuid.UUID `json:"site_id"`
+	ArticleID    uuid.UUID `json:"article_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Content      string    `json:"content"`
+	RenderedHTML string    `json:"rendered_html"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+/*
+PublicComment represents the public view of a comment, as served to anonymous readers.
+Unlike `Comment`, it never discloses the email address of the commenter.
+
+Fields:
+  - ID: The unique identifier for the comment (UUID).
+  - ArticleID: The unique identifier of the article the comment was made on (UUID).
+  - Name: The name of the person who made the comment.
+  - Content: The text content of the comment, in Markdown.
+  - RenderedHTML: The content of the comment rendered to (sanitized) HTML.
+  - CreatedAt: When the comment was made.
+  - UpdatedAt: When the comment was last updated (when it was made if never).
+*/
+type PublicComment struct {
+	ID           uuid.UUID `json:"id"`
+	ArticleID    uuid.UUID `json:"article_id"`
+	Name         string    `json:"name"`
+	Content      string    `json:"content"`
+	RenderedHTML string    `json:"rendered_html"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PublicCommentOf returns the public view of the comment.
+func PublicCommentOf(comment Comment) PublicComment {
+	return PublicComment{
+		ID:           comment.ID,
+		ArticleID:    comment.ArticleID,
+		Name:         comment.Name,
+		Content:      comment.Content,
+		RenderedHTML: comment.RenderedHTML,
+		CreatedAt:    comment.CreatedAt,
+		UpdatedAt:    comment.UpdatedAt,
+	}
+}
+
+/*
+Mention represents a user mentioned in a comment, by the slug of their name prefixed
+with "@" (e.g. "@jane-doe" for "Jane Doe").
+
+Fields:
+  - ID: The unique identifier for the mention (UUID).
+  - SiteID: The unique identifier of the site the comment belongs to (UUID).
+  - CommentID: The unique identifier of the comment the user is mentioned in (UUID).
+  - ArticleID: The unique identifier of the article the comment was made on (UUID).
+  - UserID: The unique identifier of the mentioned user (UUID).
+  - CreatedAt: When the user was mentioned.
+*/
+type Mention struct {
+	ID        uuid.UUID `json:"id"`
+	SiteID    uuid.UUID `json:"site_id"`
+	CommentID uuid.UUID `json:"comment_id"`
+	ArticleID uuid.UUID `json:"article_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
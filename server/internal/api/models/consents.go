@@ -0,0 +1,79 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Consent` struct that represents the consent of a visitor to the processing of
+    the personal data they submitted, e.g. along with a comment.
+  - The `ConsentRequest` struct that represents a request about the consents of a
+    visitor.
+  - The `ConsentWithdrawal` struct that represents the outcome of the withdrawal of
+    the consents of a visitor.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The purposes of the consents of the visitors.
+const (
+	ConsentComments = "comments" // The personal data submitted with a comment
+)
+
+/*
+Consent represents the consent of a visitor to the processing of the personal data
+they submitted to a site, recorded as the proof of their consent (GDPR article 7).
+
+Fields:
+  - ID: The unique identifier for the consent (UUID).
+  - SiteID: The unique identifier of the site the data was submitted to (UUID).
+  - Email: The email address of the visitor.
+  - Purpose: What the data was submitted for, e.g. "comments".
+  - ResourceID: The unique identifier of the resource the data was submitted with,
+    e.g. the comment (UUID).
+  - PolicyVersion: The version of the privacy policy of the site the visitor consented
+    to (see `SiteSettings`), empty if the site has none.
+  - IP: The IP address the data was submitted from, erased once the consent is
+    withdrawn.
+  - GivenAt: When the visitor consented.
+  - WithdrawnAt: When the visitor withdrew their consent, if they did.
+*/
+type Consent struct {
+	ID            uuid.UUID  `json:"id"`
+	SiteID        uuid.UUID  `json:"site_id"`
+	Email         string     `json:"email"`
+	Purpose       string     `json:"purpose"`
+	ResourceID    uuid.UUID  `json:"resource_id"`
+	PolicyVersion string     `json:"policy_version"`
+	IP            string     `json:"ip,omitempty"`
+	GivenAt       time.Time  `json:"given_at"`
+	WithdrawnAt   *time.Time `json:"withdrawn_at,omitempty"`
+}
+
+/*
+ConsentRequest represents a request about the consents of a visitor, e.g. to withdraw
+them.
+
+Fields:
+  - Email: The email address the consents were given with.
+*/
+type ConsentRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+/*
+ConsentWithdrawal represents the outcome of the withdrawal of the consents of a
+visitor.
+
+Fields:
+  - Consents: The number of consents withdrawn.
+  - Comments: The number of comments anonymized, i.e. stripped of the name and the
+    email address of the visitor.
+*/
+type ConsentWithdrawal struct {
+	Consents int `json:"consents"`
+	Comments int `json:"comments"`
+}
@@ -0,0 +1,27 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `ContactMessage` struct that represents a message sent through the contact
+    form of a site.
+*/
+
+package models
+
+/*
+ContactMessage represents a message sent by a visitor through the contact form of a
+site.
+
+Fields:
+  - Name: The name of the visitor.
+  - Email: The email address of the visitor, which the replies are sent to.
+  - Message: The message itself.
+  - Website: A honeypot field, hidden from the visitors by the contact forms, which
+    only the spam bots fill in.
+*/
+type ContactMessage struct {
+	Name    string `json:"name"    validate:"required,max=100"`
+	Email   string `json:"email"   validate:"required,email,max=254"`
+	Message string `json:"message" validate:"required,min=10,max=5000"`
+	Website string `json:"website"`
+}
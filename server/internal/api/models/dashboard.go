@@ -0,0 +1,45 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Dashboard` struct that represents the summary of a site shown on the home
+    screen of the administration interface.
+  - The `DashboardCounts` struct that represents the number of resources of a site.
+*/
+
+package models
+
+/*
+Dashboard represents the summary of a site.
+
+Fields:
+  - Counts: The number of resources of the site.
+  - RecentActivity: The latest write requests made to the management API of the site,
+    latest first.
+  - TopArticles: The most viewed articles of the site over the last 7 days.
+*/
+type Dashboard struct {
+	Counts         DashboardCounts `json:"counts"`
+	RecentActivity []AuditEntry    `json:"recent_activity"`
+	TopArticles    []TopArticle    `json:"top_articles"`
+}
+
+/*
+DashboardCounts represents the number of resources of a site.
+
+The comments are published as soon as they are made (there is no moderation queue),
+hence none of them is pending and the total number of comments of the site is counted
+instead.
+
+Fields:
+  - PublishedArticles: The number of published articles.
+  - DraftArticles: The number of articles which are not published.
+  - Users: The number of users.
+  - TotalComments: The total number of comments.
+*/
+type DashboardCounts struct {
+	PublishedArticles int `json:"published_articles"`
+	DraftArticles     int `json:"draft_articles"`
+	Users             int `json:"users"`
+	TotalComments     int `json:"total_comments"`
+}
@@ -0,0 +1,24 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `KeyRotation` struct that represents the personal data of a site encrypted
+    again with the primary encryption key.
+*/
+
+package models
+
+/*
+KeyRotation represents the personal data of a site encrypted again with the primary
+encryption key, after the keys were rotated (see the `encryption` package).
+
+Fields:
+  - Users: The number of users whose personal data was encrypted again.
+  - Comments: The number of comments whose personal data was encrypted again.
+  - Consents: The number of consents whose personal data was encrypted again.
+*/
+type KeyRotation struct {
+	Users    int `json:"users"`
+	Comments int `json:"comments"`
+	Consents int `json:"consents"`
+}
@@ -0,0 +1,93 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Experiment` struct that represents an A/B experiment of a site, e.g. testing
+    the headlines of an article.
+  - The `Assignment` struct that represents the variant of an experiment a visitor is
+    bucketed into.
+  - The `VariantResult` struct that represents the audience of a variant of an
+    experiment.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Experiment represents an A/B experiment of a site, whose visitors are bucketed into its
+variants.
+
+Fields:
+  - ID: The unique identifier for the experiment (UUID).
+  - SiteID: The unique identifier of the site running the experiment (UUID).
+  - Key: The key the frontends refer to the experiment by (e.g. "homepage-headline"),
+    unique within the site.
+  - Name: The human-readable name of the experiment.
+  - ArticleID: The unique identifier of the article the experiment is about, if any
+    (e.g. the article whose headlines are tested).
+  - Variants: The variants of the experiment, at least two, the first one being the
+    control.
+  - Active: Whether the visitors are bucketed into the experiment.
+  - CreatedAt: When the experiment was created.
+*/
+type Experiment struct {
+	ID        uuid.UUID  `json:"id"`
+	SiteID    uuid.UUID  `json:"site_id"`
+	Key       string     `json:"key"                  validate:"required,max=64,lowercase,excludesall= /"`
+	Name      string     `json:"name"                 validate:"required,max=200"`
+	ArticleID *uuid.UUID `json:"article_id,omitempty"`
+	Variants  []Variant  `json:"variants"             validate:"min=2,max=10,unique=Key,dive"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+/*
+Variant represents a variant of an experiment.
+
+Fields:
+  - Key: The key of the variant (e.g. "b"), unique within the experiment.
+  - Value: The content the frontends render for the variant, e.g. a headline.
+  - Weight: The share of the visitors bucketed into the variant, relative to the
+    weights of the other variants (1 if zero).
+*/
+type Variant struct {
+	Key    string `json:"key"    validate:"required,max=64,excludesall= /"`
+	Value  string `json:"value"  validate:"max=2048"`
+	Weight int    `json:"weight" validate:"min=0,max=1000"`
+}
+
+/*
+Assignment represents the variant of an active experiment a visitor is bucketed into.
+
+Fields:
+  - Experiment: The key of the experiment.
+  - ArticleID: The unique identifier of the article the experiment is about, if any.
+  - Variant: The key of the variant.
+  - Value: The content of the variant, e.g. a headline.
+*/
+type Assignment struct {
+	Experiment string     `json:"experiment"`
+	ArticleID  *uuid.UUID `json:"article_id,omitempty"`
+	Variant    string     `json:"variant"`
+	Value      string     `json:"value"`
+}
+
+/*
+VariantResult represents the audience of a variant of an experiment, from the
+exposures recorded by the analytics collector.
+
+Fields:
+  - Variant: The key of the variant.
+  - Exposures: The number of times the variant was shown.
+  - Visitors: The number of unique (daily) visitors the variant was shown to.
+*/
+type VariantResult struct {
+	Variant   string `json:"variant"`
+	Exposures int    `json:"exposures"`
+	Visitors  int    `json:"visitors"`
+}
@@ -0,0 +1,27 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `ExportRecord` struct that represents a resource of a site in a content
+    export.
+*/
+
+package models
+
+import "github.com/google/uuid"
+
+/*
+ExportRecord represents a single resource of a site in a content export, used for
+backup and migration purposes.
+
+Fields:
+  - Type: The type of the resource ("user", "article" or "comment").
+  - ID: The unique identifier of the resource (UUID).
+  - Data: The resource itself, as served by the API but with its personal data (e.g.
+    the email addresses) encrypted as it is stored.
+*/
+type ExportRecord struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	Data any       `json:"data"`
+}
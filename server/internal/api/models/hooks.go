@@ -0,0 +1,59 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Hook` struct that represents an event notified by an external service through
+    an inbound webhook.
+  - The `HookAction` struct that represents a job run by such an event.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The statuses of the actions of the inbound webhooks.
+const (
+	HookActionStarted = "started"
+	HookActionSkipped = "skipped"
+	HookActionFailed  = "failed"
+)
+
+/*
+Hook represents an event notified to a site by an external service (e.g. GitHub)
+through an inbound webhook, once its signature is verified.
+
+Fields:
+  - ID: The unique identifier for the hook (UUID).
+  - SiteID: The unique identifier of the site the hook was sent to (UUID).
+  - Integration: The name of the integration of the external service, e.g. "github".
+  - Event: The type of the event, e.g. "push", if known.
+  - Actions: The jobs run by the event.
+  - ReceivedAt: When the hook was received.
+*/
+type Hook struct {
+	ID          uuid.UUID    `json:"id"`
+	SiteID      uuid.UUID    `json:"site_id"`
+	Integration string       `json:"integration"`
+	Event       string       `json:"event"`
+	Actions     []HookAction `json:"actions"`
+	ReceivedAt  time.Time    `json:"received_at"`
+}
+
+/*
+HookAction represents a job run by an event notified through an inbound webhook.
+
+Fields:
+  - Job: The name of the job, e.g. "regenerate-sitemaps".
+  - Status: Whether the job was started, skipped (its previous run still going) or
+    failed to start (e.g. because it is disabled).
+  - Error: Why the job failed to start, if it did.
+*/
+type HookAction struct {
+	Job    string `json:"job"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,44 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `ImportReport` struct that represents the outcome of a content import.
+*/
+
+package models
+
+/*
+ImportReport represents the outcome of a content import (e.g. from a WordPress export).
+
+Fields:
+  - DryRun: Whether the import was only simulated, in which case nothing was stored.
+  - Created: The number of resources created (or which would have been created) per
+    type.
+  - Skipped: The items of the import which were not imported, along with the reason.
+*/
+type ImportReport struct {
+	DryRun  bool         `json:"dry_run"`
+	Created ImportCounts `json:"created"`
+	Skipped []ImportSkip `json:"skipped"`
+}
+
+// ImportCounts holds the number of resources of each type of an import.
+type ImportCounts struct {
+	Users    int `json:"users"`
+	Articles int `json:"articles"`
+	Comments int `json:"comments"`
+}
+
+/*
+ImportSkip represents an item of an import which was not imported.
+
+Fields:
+  - Type: The type of the item (e.g. "author", "post", "page" or "comment").
+  - Ref: A human-readable reference to the item in the imported file.
+  - Reason: Why the item was not imported.
+*/
+type ImportSkip struct {
+	Type   string `json:"type"`
+	Ref    string `json:"ref"`
+	Reason string `json:"reason"`
+}
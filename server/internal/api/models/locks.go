@@ -0,0 +1,42 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `EditLock` struct that represents a user editing an article, which the other
+    users are warned of.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+EditLock represents a user editing an article of a site. The lock is advisory: it lets
+the editors warn the other users that the article is being edited (e.g. "Jane Doe is
+currently editing this article"), the updates of the article being guarded against
+overwrites by its version anyway.
+
+The lock expires unless the user renews it (by sending heartbeats) before it does.
+
+Fields:
+  - ID: The unique identifier for the lock (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the locked article (UUID).
+  - UserID: The unique identifier of the user editing the article (UUID).
+  - UserName: The name of the user editing the article.
+  - AcquiredAt: When the user started editing the article.
+  - ExpiresAt: When the lock expires, unless it is renewed.
+*/
+type EditLock struct {
+	ID         uuid.UUID `json:"id"`
+	SiteID     uuid.UUID `json:"site_id"`
+	ArticleID  uuid.UUID `json:"article_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,60 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Menu` struct that represents a navigation menu of a site (e.g. the main or the
+    footer navigation), rendered by the frontends of the site.
+  - The `MenuItem` struct that represents an entry of a menu, which may hold nested
+    entries.
+*/
+
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+/*
+Menu represents a navigation menu of a site.
+
+The frontends fetch the menus by their handle (e.g. "main" or "footer") rather than by
+their ID, so that the navigation can be edited without redeploying them.
+
+Fields:
+  - ID: The unique identifier for the menu (UUID).
+  - SiteID: The unique identifier of the site the menu belongs to (UUID).
+  - Handle: The handle of the menu (e.g. "main"), unique within the site.
+  - Name: The human-readable name of the menu.
+  - Items: The entries of the menu, in display order.
+*/
+type Menu struct {
+	ID     uuid.UUID  `json:"id"`
+	SiteID uuid.UUID  `json:"site_id"`
+	Handle string     `json:"handle"  validate:"required,max=64,lowercase,excludesall= /"`
+	Name   string     `json:"name"    validate:"required,max=100"`
+	Items  []MenuItem `json:"items"   validate:"max=100,dive"`
+}
+
+/*
+MenuItem represents an entry of a navigation menu.
+
+An entry links to exactly one of an article of the site, a page of the site or an
+external URL. Its `Href` is resolved by the API from the current path of the article or
+page it references, so that the menus follow the content they link to.
+
+Fields:
+  - Label: The text of the entry.
+  - ArticleID: The unique identifier of the article the entry links to, if any.
+  - PageID: The unique identifier of the page the entry links to, if any.
+  - URL: The external URL the entry links to, if any.
+  - Href: The resolved link of the entry (read-only).
+  - Items: The nested entries of the entry, in display order.
+*/
+type MenuItem struct {
+	Label     string     `json:"label"                validate:"required,max=100"`
+	ArticleID *uuid.UUID `json:"article_id,omitempty"`
+	PageID    *uuid.UUID `json:"page_id,omitempty"`
+	URL       string     `json:"url,omitempty"        validate:"omitempty,url"`
+	Href      string     `json:"href,omitempty"`
+	Items     []MenuItem `json:"items,omitempty"      validate:"max=100,dive"`
+}
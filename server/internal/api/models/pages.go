@@ -0,0 +1,41 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Page` struct that represents a static page of a site (e.g. about, contact or
+    legal pages), which unlike an article has neither author nor comments.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Page represents a static page of a site.
+
+The pages are organized in a hierarchy through their paths, e.g. "/legal" and
+"/legal/privacy".
+
+Fields:
+  - ID: The unique identifier for the page (UUID).
+  - SiteID: The unique identifier of the site the page belongs to (UUID).
+  - Path: The path of the page within the site (e.g. "/about/team"), unique within
+    the site.
+  - Title: The title of the page.
+  - Content: The (HTML) content of the page.
+  - IsPublished: Whether the page is published, i.e. served on the public API.
+  - PublishedAt: When the page was first published, if ever.
+*/
+type Page struct {
+	ID          uuid.UUID  `json:"id"`
+	SiteID      uuid.UUID  `json:"site_id"`
+	Path        string     `json:"path"                   validate:"required,startswith=/,max=512,lowercase"`
+	Title       string     `json:"title"                  validate:"required,max=200"`
+	Content     string     `json:"content,omitempty"`
+	IsPublished bool       `json:"isPublished"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
@@ -0,0 +1,62 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Policy` struct that represents a version of the terms of service (or of any
+    other policy) the users of a site have to accept.
+  - The `PolicyAcceptance` struct that represents the acceptance of a policy by a user.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Policy represents a published version of the terms of service (or of any other policy)
+of a site, which its users have to accept. The last published version is the current
+one, and the users who did not accept it are flagged (see `User.AcceptedPolicy`).
+
+Fields:
+  - ID: The unique identifier for the policy (UUID).
+  - SiteID: The unique identifier of the site the policy belongs to (UUID).
+  - Version: The version of the policy, unique within the site (e.g. "2025-06").
+  - Title: The title of the policy, e.g. "Terms of Service".
+  - URL: The HTTP(S) URL the text of the policy is published at, if any.
+  - RequireAcceptance: Whether the users have to accept the policy before they can
+    modify the content of the site again.
+  - PublishedAt: When the policy was published.
+*/
+type Policy struct {
+	ID                uuid.UUID `json:"id"`
+	SiteID            uuid.UUID `json:"site_id"`
+	Version           string    `json:"version"            validate:"required,max=50"`
+	Title             string    `json:"title"              validate:"required,max=200"`
+	URL               string    `json:"url,omitempty"      validate:"omitempty,http_url"`
+	RequireAcceptance bool      `json:"require_acceptance"`
+	PublishedAt       time.Time `json:"published_at"`
+}
+
+/*
+PolicyAcceptance represents the acceptance of a version of a policy by a user of a
+site, the acceptances of a user making up their history.
+
+Fields:
+  - ID: The unique identifier for the acceptance (UUID).
+  - SiteID: The unique identifier of the site the policy belongs to (UUID).
+  - PolicyID: The unique identifier of the accepted policy (UUID).
+  - Version: The version of the accepted policy.
+  - UserID: The unique identifier of the user who accepted the policy (UUID).
+  - AcceptedAt: When the user accepted the policy.
+*/
+type PolicyAcceptance struct {
+	ID         uuid.UUID `json:"id"`
+	SiteID     uuid.UUID `json:"site_id"`
+	PolicyID   uuid.UUID `json:"policy_id"`
+	Version    string    `json:"version"`
+	UserID     uuid.UUID `json:"user_id"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
@@ -0,0 +1,51 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Preview` struct that represents the preview of a draft article by a headless
+    frontend.
+  - The `PreviewRequest` struct that represents the handshake starting a preview.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Preview represents the preview of an article, typically a draft, by a headless frontend
+(e.g. in the Draft Mode of Next.js). It is the payload of the preview handshake, which
+the frontend uses to redirect the reader to the article and to fetch it until the
+preview expires.
+
+Fields:
+  - ArticleID: The unique identifier of the previewed article (UUID).
+  - Slug: The slug of the previewed article, if any, e.g. to redirect the reader to
+    its page.
+  - Token: The short-lived token granting access to the article, sent back in the
+    `token` query parameter (or the preview cookie) when fetching it.
+  - URL: The URL of the public API serving the article to the holder of the token,
+    token included.
+  - ExpiresAt: When the token expires.
+*/
+type Preview struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Slug      string    `json:"slug,omitempty"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+/*
+PreviewRequest represents the handshake starting the preview of an article.
+
+Fields:
+  - Token: The token of a link sharing the article (see `ShareLink`), which doubles as
+    its preview token.
+*/
+type PreviewRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,32 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Problem` struct that represents the details of an error answering a request,
+    as defined by RFC 9457.
+*/
+
+package models
+
+/*
+Problem represents the details of an error answering a request, served with the
+`application/problem+json` media type (RFC 9457).
+
+Fields:
+  - Type: The URI identifying the type of the problem ("about:blank" when the status
+    code is self-explanatory).
+  - Title: The short summary of the type of the problem.
+  - Status: The HTTP status code of the response.
+  - Detail: The explanation of this occurrence of the problem.
+  - Instance: The path of the request the problem occurred on.
+  - RequestID: The unique identifier of the request, to be quoted when reporting the
+    problem.
+*/
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
@@ -0,0 +1,32 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Redirect` struct that represents a redirect from a path of a site to another
+    location, e.g. after the slug of an article changed.
+*/
+
+package models
+
+import "github.com/google/uuid"
+
+/*
+Redirect represents a redirect served by a site.
+
+Fields:
+  - ID: The unique identifier for the redirect (UUID).
+  - SiteID: The unique identifier of the site serving the redirect (UUID).
+  - Source: The path of the site which is redirected (e.g. "/old-post"), unique within
+    the site.
+  - Target: The location the path is redirected to, either a path of the site (e.g.
+    "/articles/new-post") or an absolute URL.
+  - StatusCode: The status code of the redirect, either 301 (permanent, the default)
+    or 302 (temporary).
+*/
+type Redirect struct {
+	ID         uuid.UUID `json:"id"`
+	SiteID     uuid.UUID `json:"site_id"`
+	Source     string    `json:"source"      validate:"required,startswith=/,max=2048"`
+	Target     string    `json:"target"      validate:"required,uri,nefield=Source,max=2048"`
+	StatusCode int       `json:"status_code" validate:"omitempty,oneof=301 302"`
+}
@@ -0,0 +1,24 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Resource` struct that represents a resource related to another one, as
+    included in the response serving the latter (see the `include` query parameter).
+*/
+
+package models
+
+/*
+Resource represents a resource included in a response along with the resource it is
+related to, in the `included` section of the response (as defined by JSON:API).
+
+Fields:
+  - Type: The type of the resource (e.g. "authors", "comments" or "tags").
+  - ID: The unique identifier of the resource within its type.
+  - Attributes: The representation of the resource.
+*/
+type Resource struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Attributes any    `json:"attributes"`
+}
@@ -0,0 +1,72 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `RetentionPolicy` struct that represents how long the revisions of the articles
+    and the audit log of a site are kept.
+  - The `RetentionReport` struct that represents what the retention policy removes, or
+    would remove, from a site.
+  - The `ArticleRetention` struct that represents the revisions of an article removed
+    by the retention policy.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+RetentionPolicy represents how long the revisions of the articles and the audit log of
+the sites are kept, to bound the storage they take.
+
+Fields:
+  - RevisionsPerArticle: The number of latest revisions kept per article, every
+    revision being kept when 0.
+  - AuditLogDays: The number of days the entries of the audit log are kept, every
+    entry being kept when 0.
+*/
+type RetentionPolicy struct {
+	RevisionsPerArticle int `json:"revisions_per_article"`
+	AuditLogDays        int `json:"audit_log_days"`
+}
+
+/*
+RetentionReport represents what the retention policy removes from a site, or would
+remove from it when the policy is not applied (a dry run).
+
+Fields:
+  - Policy: The retention policy.
+  - DryRun: Whether the policy was not applied, nothing having been removed.
+  - Revisions: The number of revisions removed.
+  - Articles: The articles whose revisions were removed, in the order they were first
+    revised.
+  - AuditEntries: The number of entries of the audit log removed.
+  - AuditCutoff: The time before which the entries of the audit log are removed, null
+    if every entry is kept.
+*/
+type RetentionReport struct {
+	Policy       RetentionPolicy    `json:"policy"`
+	DryRun       bool               `json:"dry_run"`
+	Revisions    int                `json:"revisions"`
+	Articles     []ArticleRetention `json:"articles"`
+	AuditEntries int                `json:"audit_entries"`
+	AuditCutoff  *time.Time         `json:"audit_cutoff,omitempty"`
+}
+
+/*
+ArticleRetention represents the revisions of an article removed by the retention
+policy.
+
+Fields:
+  - ArticleID: The unique identifier of the article (UUID).
+  - Revisions: The number of revisions removed.
+  - Numbers: The numbers of the revisions removed, oldest first.
+*/
+type ArticleRetention struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Revisions int       `json:"revisions"`
+	Numbers   []int     `json:"numbers"`
+}
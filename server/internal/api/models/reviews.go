@@ -0,0 +1,95 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Review` struct that represents the editorial review of an article, which the
+    sites may require before the articles are published.
+  - The `ReviewComment` struct that represents a decision of a reviewer on an article.
+  - The `ReviewerAssignment` and `ReviewDecision` structs that represent the requests
+    assigning a reviewer to an article and deciding on it.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The statuses of the review of an article.
+const (
+	ReviewPending          = "pending"           // Submitted and waiting for a decision
+	ReviewChangesRequested = "changes_requested" // To be changed and submitted again
+	ReviewApproved         = "approved"          // Approved for publication
+)
+
+/*
+Review represents the editorial review of an article of a site, which starts when the
+article is first submitted for review.
+
+Fields:
+  - ID: The unique identifier for the review (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the reviewed article (UUID).
+  - Status: The status of the review, either "pending", "changes_requested" or
+    "approved".
+  - ReviewerID: The unique identifier of the user assigned to review the article, if
+    any (UUID).
+  - ApprovedVersion: The version of the article which was approved, if it was. Only
+    this version can be published when the site requires the articles to be reviewed.
+  - SubmittedAt: When the article was last submitted for review.
+  - UpdatedAt: When the review was last updated.
+  - Comments: The decisions made on the article, oldest first.
+*/
+type Review struct {
+	ID              uuid.UUID       `json:"id"`
+	SiteID          uuid.UUID       `json:"site_id"`
+	ArticleID       uuid.UUID       `json:"article_id"`
+	Status          string          `json:"status"`
+	ReviewerID      *uuid.UUID      `json:"reviewer_id,omitempty"`
+	ApprovedVersion int             `json:"approved_version,omitempty"`
+	SubmittedAt     time.Time       `json:"submitted_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Comments        []ReviewComment `json:"comments"`
+}
+
+/*
+ReviewComment represents a decision made on an article under review.
+
+Fields:
+  - Status: The decision, either "approved" or "changes_requested".
+  - Content: The comment of the reviewer on the article, if any.
+  - UserID: The unique identifier of the user who made the decision, unless it was
+    made with an API key not owned by any user (UUID).
+  - Version: The version of the article the decision was made on.
+  - CreatedAt: When the decision was made.
+*/
+type ReviewComment struct {
+	Status    string     `json:"status"`
+	Content   string     `json:"content,omitempty"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+/*
+ReviewerAssignment represents the assignment of a reviewer to an article.
+
+Fields:
+  - ReviewerID: The unique identifier of the user reviewing the article (UUID).
+*/
+type ReviewerAssignment struct {
+	ReviewerID uuid.UUID `json:"reviewer_id" validate:"required"`
+}
+
+/*
+ReviewDecision represents the decision of a reviewer on an article.
+
+Fields:
+  - Comment: The comment of the reviewer on the article, which is required to request
+    changes.
+*/
+type ReviewDecision struct {
+	Comment string `json:"comment" validate:"max=5000"`
+}
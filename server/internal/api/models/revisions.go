@@ -0,0 +1,80 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Revision` struct that represents a version of an article, recorded each time
+    the article is created or updated.
+  - The `FieldChange` struct that represents the change of a field of an article
+    between two of its versions.
+  - The `RevisionDiff` struct that represents the differences between two revisions
+    of an article.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Revision represents a version of an article of a site, along with the changes made to
+the previous version.
+
+Fields:
+  - ID: The unique identifier for the revision (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the article (UUID).
+  - Number: The number of the revision, which is the version of the article.
+  - CreatedAt: When the revision was made.
+  - Changes: The fields changed by the revision, in the order of the fields of the
+    article (every field set when the article was created for its first revision).
+  - Article: The article as of the revision.
+*/
+type Revision struct {
+	ID        uuid.UUID     `json:"id"`
+	SiteID    uuid.UUID     `json:"site_id"`
+	ArticleID uuid.UUID     `json:"article_id"`
+	Number    int           `json:"number"`
+	CreatedAt time.Time     `json:"created_at"`
+	Changes   []FieldChange `json:"changes"`
+	Article   Article       `json:"article"`
+}
+
+/*
+FieldChange represents the change of a field of an article between two versions.
+
+Fields:
+  - Field: The name of the field, as serialized (e.g. "title" or "content").
+  - Old: The value of the field before the change (null if it was not set).
+  - New: The value of the field after the change (null if it was cleared).
+  - Summary: A human readable summary of the change (e.g. "Title changed" or "2
+    paragraphs added").
+*/
+type FieldChange struct {
+	Field   string `json:"field"`
+	Old     any    `json:"old"`
+	New     any    `json:"new"`
+	Summary string `json:"summary"`
+}
+
+/*
+RevisionDiff represents the differences between two revisions of an article.
+
+Fields:
+  - ArticleID: The unique identifier of the article (UUID).
+  - From: The number of the revision compared from.
+  - To: The number of the revision compared to.
+  - Changes: The fields which differ between the revisions, in the order of the fields
+    of the article.
+  - Unified: The differences between the revisions in the unified format of `diff -u`,
+    the metadata of the article being compared before its content, if requested.
+*/
+type RevisionDiff struct {
+	ArticleID uuid.UUID     `json:"article_id"`
+	From      int           `json:"from"`
+	To        int           `json:"to"`
+	Changes   []FieldChange `json:"changes"`
+	Unified   string        `json:"unified,omitempty"`
+}
@@ -0,0 +1,92 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `SearchQuery` struct that represents a search of the published articles of a
+    site.
+  - The `SearchResult` struct that represents an article found by a search, along
+    with its matches and a snippet of its content.
+  - The `SearchFacets` and `Facet` structs that represent how many articles found by
+    a search are classified with each tag and written by each author.
+*/
+
+package models
+
+import "github.com/Weburz/burzcontent/server/internal/search"
+
+/*
+SearchQuery represents a search of the published articles of a site.
+
+Fields:
+  - Text: The text searched for, each word of which has to match a word of the title,
+    the content, the tags or the author of the articles (see the `search` package).
+  - Tag: The tag the articles have to be classified with, if any.
+  - Author: The author the articles have to be written by, if any.
+*/
+type SearchQuery struct {
+	Text   string
+	Tag    string
+	Author string
+}
+
+/*
+SearchResult represents an article found by a search.
+
+Fields:
+  - Article: The article found.
+  - Score: The relevance of the article, the matches of the title weighing more than
+    the ones of the tags, which weigh more than the ones of the author and the content.
+  - Matches: The matches of the search in the title, the author and the tags of the
+    article.
+  - Snippet: The passage of the content of the article (as plain text) around its
+    first match, HTML-escaped with the matches wrapped in `<mark>` elements (see
+    `search.Snippet`).
+*/
+type SearchResult struct {
+	Article Article       `json:"article"`
+	Score   int           `json:"score"`
+	Matches SearchMatches `json:"matches"`
+	Snippet string        `json:"snippet"`
+}
+
+/*
+SearchMatches represents the matches of a search in the fields of an article, by their
+offsets in UTF-16 code units (see `search.Match`), for the search interfaces to
+highlight them.
+
+Fields:
+  - Title: The matches in the title of the article.
+  - Author: The matches in the author of the article.
+  - Tags: The matches in the tags of the article, by tag.
+*/
+type SearchMatches struct {
+	Title  []search.Match            `json:"title,omitempty"`
+	Author []search.Match            `json:"author,omitempty"`
+	Tags   map[string][]search.Match `json:"tags,omitempty"`
+}
+
+/*
+SearchFacets represents how many articles found by a search (across every page of the
+results) are classified with each tag and written by each author, for the search
+interfaces to narrow the search down.
+
+Fields:
+  - Tags: The tags of the articles found, by decreasing count.
+  - Authors: The authors of the articles found, by decreasing count.
+*/
+type SearchFacets struct {
+	Tags    []Facet `json:"tags"`
+	Authors []Facet `json:"authors"`
+}
+
+/*
+Facet represents a value of a facet of the articles found by a search.
+
+Fields:
+  - Value: The value, e.g. the name of a tag.
+  - Count: The number of articles found with the value.
+*/
+type Facet struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
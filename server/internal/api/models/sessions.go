@@ -0,0 +1,39 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Session` struct that represents a browser session of the management API,
+    e.g. of the embedded admin interface.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+)
+
+/*
+Session represents a browser session of the management API, opened with an API key
+whose principal it is authenticated as.
+
+Fields:
+  - SiteID: The unique identifier of the site the API key is scoped to (UUID).
+  - UserID: The unique identifier of the user owning the API key (UUID), if any.
+  - Role: The role granted to the API key.
+  - Root: Whether the session was opened with the root API key of the deployment.
+  - CSRFToken: The token the write requests of the session have to present in the
+    `X-CSRF-Token` header.
+  - ExpiresAt: When the session expires.
+*/
+type Session struct {
+	SiteID    uuid.UUID `json:"site_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      auth.Role `json:"role"`
+	Root      bool      `json:"root"`
+	CSRFToken string    `json:"csrf_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
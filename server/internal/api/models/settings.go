@@ -0,0 +1,86 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `SiteSettings` struct that represents the site-wide configuration of a site,
+    such as its title or its comment policy.
+  - The `SiteSettingsPatch` struct that represents a partial update of the settings of
+    a site.
+*/
+
+package models
+
+// The comment policies of a site.
+const (
+	CommentsOpen   = "open"   // Comments can be added to the articles
+	CommentsClosed = "closed" // No comment can be added to the articles
+)
+
+/*
+SiteSettings represents the site-wide configuration of a site, which the feeds, the
+comments and the frontends of the site follow. An empty field means the default value
+applies (see `Site.EffectiveSettings`).
+
+Fields:
+  - Title: The title of the site, its name by default.
+  - Description: The description of the site, e.g. the tagline of its feed.
+  - DefaultLocale: The BCP 47 language tag of the content of the site, "en" by
+    default.
+  - CommentPolicy: Whether comments can be added to the articles ("open", the
+    default) or not ("closed").
+  - Timezone: The IANA time zone the dates of the site are rendered in, and its
+    articles scheduled in, "UTC" by default.
+  - RequireReview: Whether the articles have to be approved by a reviewer before they
+    are published (see `Review`), false by default.
+  - PrivacyPolicy: The version of the privacy policy of the site (e.g. "2025-06"),
+    recorded along with the consents of the visitors submitting personal data (see
+    `Consent`).
+*/
+type SiteSettings struct {
+	Title         string `json:"title"          validate:"max=200"`
+	Description   string `json:"description"    validate:"max=500"`
+	DefaultLocale string `json:"default_locale" validate:"omitempty,bcp47_language_tag"`
+	CommentPolicy string `json:"comment_policy" validate:"omitempty,oneof=open closed"`
+	Timezone      string `json:"timezone"       validate:"omitempty,timezone"`
+	RequireReview bool   `json:"require_review"`
+	PrivacyPolicy string `json:"privacy_policy" validate:"max=50"`
+}
+
+// SiteSettingsPatch represents a partial update of the settings of a site, where the
+// fields which are nil are left unchanged.
+type SiteSettingsPatch struct {
+	Title         *string `json:"title"          validate:"omitempty,max=200"`
+	Description   *string `json:"description"    validate:"omitempty,max=500"`
+	DefaultLocale *string `json:"default_locale" validate:"omitempty,bcp47_language_tag"`
+	CommentPolicy *string `json:"comment_policy" validate:"omitempty,oneof=open closed"`
+	Timezone      *string `json:"timezone"       validate:"omitempty,timezone"`
+	RequireReview *bool   `json:"require_review"`
+	PrivacyPolicy *string `json:"privacy_policy" validate:"omitempty,max=50"`
+}
+
+// Apply returns the settings updated with the fields of the patch which are not nil.
+func (p SiteSettingsPatch) Apply(settings SiteSettings) SiteSettings {
+	if p.Title != nil {
+		settings.Title = *p.Title
+	}
+	if p.Description != nil {
+		settings.Description = *p.Description
+	}
+	if p.DefaultLocale != nil {
+		settings.DefaultLocale = *p.DefaultLocale
+	}
+	if p.CommentPolicy != nil {
+		settings.CommentPolicy = *p.CommentPolicy
+	}
+	if p.Timezone != nil {
+		settings.Timezone = *p.Timezone
+	}
+	if p.RequireReview != nil {
+		settings.RequireReview = *p.RequireReview
+	}
+	if p.PrivacyPolicy != nil {
+		settings.PrivacyPolicy = *p.PrivacyPolicy
+	}
+
+	return settings
+}
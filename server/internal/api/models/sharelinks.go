@@ -0,0 +1,55 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `ShareLink` struct that represents an expiring link sharing an article, e.g. a
+    draft, with readers who can not access the management API.
+  - The `ShareLinkRequest` struct that represents the settings of a new share link.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+ShareLink represents an expiring link sharing an article of a site.
+
+The token of the link is only returned once, when the link is created; the link only
+stores its digest.
+
+Fields:
+  - ID: The unique identifier for the share link (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the shared article (UUID).
+  - Prefix: The first characters of the token, used to identify the link.
+  - OneTime: Whether the link is revoked as soon as it is used.
+  - CreatedAt: When the link was created.
+  - ExpiresAt: When the link expires.
+  - Hash: The SHA-256 digest of the token, which is never serialized.
+*/
+type ShareLink struct {
+	ID        uuid.UUID `json:"id"`
+	SiteID    uuid.UUID `json:"site_id"`
+	ArticleID uuid.UUID `json:"article_id"`
+	Prefix    string    `json:"prefix"`
+	OneTime   bool      `json:"one_time"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Hash      string    `json:"-"`
+}
+
+/*
+ShareLinkRequest represents the settings of a new share link.
+
+Fields:
+  - ExpiresIn: The lifetime of the link, in seconds (the maximum lifetime if unset).
+  - OneTime: Whether the link is revoked as soon as it is used.
+*/
+type ShareLinkRequest struct {
+	ExpiresIn int  `json:"expires_in" validate:"min=0"`
+	OneTime   bool `json:"one_time"`
+}
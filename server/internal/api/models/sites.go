@@ -0,0 +1,130 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Site` struct that represents a site (or workspace) served by the deployment,
+    which every other resource (articles, users, comments, etc.) is scoped to.
+*/
+
+package models
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Site represents a site (a tenant) served by the deployment.
+
+Fields:
+  - ID: The unique identifier for the site (UUID).
+  - Name: The human-readable name of the site.
+  - Slug: The URL-safe identifier used to resolve the site by path prefix
+    (e.g. `/s/{slug}/articles`).
+  - Hostnames: The hostnames used to resolve the site from the `Host` header of a
+    request.
+  - Quota: The rate limit and storage quota of the site.
+  - Domains: The custom domains mapped to the site, which are only used to resolve
+    the site once their ownership is verified.
+  - Settings: The site-wide configuration of the site.
+  - Flags: The values of the feature flags set on the site, by name, which override
+    the ones of the configuration of the server (see the `flags` package).
+*/
+type Site struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"            validate:"required"`
+	Slug      string          `json:"slug"            validate:"required,lowercase"`
+	Hostnames []string        `json:"hostnames"       validate:"dive,hostname"`
+	Quota     SiteQuota       `json:"quota"`
+	Domains   []Domain        `json:"domains"`
+	Settings  SiteSettings    `json:"settings"`
+	Flags     map[string]bool `json:"flags,omitempty"`
+}
+
+// EffectiveSettings returns the settings of the site with the default value of every
+// field which is not set.
+func (s Site) EffectiveSettings() SiteSettings {
+	settings := s.Settings
+	if settings.Title == "" {
+		settings.Title = s.Name
+	}
+	if settings.DefaultLocale == "" {
+		settings.DefaultLocale = "en"
+	}
+	if settings.CommentPolicy == "" {
+		settings.CommentPolicy = CommentsOpen
+	}
+	if settings.Timezone == "" {
+		settings.Timezone = "UTC"
+	}
+
+	return settings
+}
+
+/*
+PrimaryHost returns the host the canonical URLs of the site are built with: the first
+verified custom domain if any, else the first hostname of the site.
+*/
+func (s Site) PrimaryHost() string {
+	for _, domain := range s.Domains {
+		if domain.Verified {
+			return domain.Name
+		}
+	}
+
+	if len(s.Hostnames) > 0 {
+		return s.Hostnames[0]
+	}
+
+	return ""
+}
+
+/*
+URL returns the canonical (absolute) URL of the path on the site, e.g.
+`https://blog.weburz.com/articles/{id}`. Sites served on a loopback hostname use the
+`http` scheme, every other site uses `https`.
+*/
+func (s Site) URL(path string) string {
+	host := s.PrimaryHost()
+
+	scheme := "https"
+	if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+		scheme = "http"
+	}
+
+	return (&url.URL{Scheme: scheme, Host: host, Path: path}).String()
+}
+
+/*
+Domain represents a custom domain mapped to a site.
+
+The ownership of the domain is verified with a DNS TXT record named
+`_burzcontent.<domain>` holding the value `burzcontent-verification=<token>`.
+
+Fields:
+  - Name: The fully qualified domain name (e.g. "blog.weburz.com").
+  - VerificationToken: The token expected in the TXT record of the domain.
+  - Verified: Whether the ownership of the domain was verified.
+  - VerifiedAt: When the ownership of the domain was verified.
+*/
+type Domain struct {
+	Name              string     `json:"name"               validate:"required,fqdn"`
+	VerificationToken string     `json:"verification_token"`
+	Verified          bool       `json:"verified"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+}
+
+/*
+SiteQuota represents the limits a site is subject to. A zero value means the default
+limit configured for the deployment applies.
+
+Fields:
+  - RequestsPerMinute: The maximum number of requests served per minute.
+  - StorageBytes: The maximum storage used by the content of the site, in bytes.
+*/
+type SiteQuota struct {
+	RequestsPerMinute int   `json:"requests_per_minute" validate:"gte=0"`
+	StorageBytes      int64 `json:"storage_bytes"       validate:"gte=0"`
+}
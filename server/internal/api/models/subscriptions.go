@@ -0,0 +1,74 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Subscription` struct that represents a user following the comments of an
+    article.
+  - The `Notification` struct that represents an in-app notification of a user, e.g.
+    of a new comment on an article they follow.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The kinds of the notifications of the users.
+const (
+	NotificationComment = "comment" // A new comment on a followed article
+	NotificationMention = "mention" // A mention of the user in a new comment
+)
+
+/*
+Subscription represents a user following the comments of an article of a site, who is
+notified (in-app and by email) of every new comment on the article unless the
+subscription is muted.
+
+Fields:
+  - ID: The unique identifier for the subscription (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the followed article (UUID).
+  - UserID: The unique identifier of the following user (UUID).
+  - Muted: Whether the notifications of the subscription are muted, the user still
+    following the article.
+  - CreatedAt: When the user subscribed to the article.
+  - UpdatedAt: When the subscription was last updated, e.g. muted (when it was
+    created if never).
+*/
+type Subscription struct {
+	ID        uuid.UUID `json:"id"`
+	SiteID    uuid.UUID `json:"site_id"`
+	ArticleID uuid.UUID `json:"article_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Muted     bool      `json:"muted"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+/*
+Notification represents an in-app notification of a user of a site.
+
+Fields:
+  - ID: The unique identifier for the notification (UUID).
+  - SiteID: The unique identifier of the site the user belongs to (UUID).
+  - UserID: The unique identifier of the notified user (UUID).
+  - Kind: The kind of the notification, either "comment" or "mention".
+  - ArticleID: The unique identifier of the article the notification is about (UUID).
+  - CommentID: The unique identifier of the comment the notification is about, if
+    any (UUID).
+  - Message: A human-readable summary of the notification.
+  - CreatedAt: When the notification was made.
+*/
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	SiteID    uuid.UUID  `json:"site_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Kind      string     `json:"kind"`
+	ArticleID uuid.UUID  `json:"article_id"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+}
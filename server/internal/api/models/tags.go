@@ -0,0 +1,24 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Tag` struct that represents a tag of the published articles of a site, along
+    with how many articles it classifies.
+*/
+
+package models
+
+/*
+Tag represents a tag the published articles of a site are classified with.
+
+Fields:
+  - Name: The name of the tag.
+  - Count: The number of published articles tagged with the tag.
+  - Delta: The number of those articles published within the trending window, i.e.
+    how much the count grew over the window.
+*/
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Delta int    `json:"delta"`
+}
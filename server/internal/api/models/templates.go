@@ -0,0 +1,50 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `TemplateBundle` struct that represents a version of the templates a site
+    overrides the default templates of the server with, e.g. to brand its emails.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+TemplateBundle represents an uploaded version of the templates of a site.
+
+A bundle is a zip archive of templates named after the default templates they override
+(e.g. "layout.html" or "invite.txt"), the default templates being used for the files
+it does not hold. At most one bundle of a site is active at a time; the previous
+versions are kept so that the site can roll back to them.
+
+Fields:
+  - ID: The unique identifier for the bundle (UUID).
+  - SiteID: The unique identifier of the site the bundle belongs to (UUID).
+  - Version: The version of the bundle, incremented with every upload to the site.
+  - Description: A description of the bundle, e.g. what changed in this version.
+  - Files: The names of the templates held by the bundle.
+  - Size: The size of the archive of the bundle, in bytes.
+  - Checksum: The SHA-256 digest (hex) of the archive of the bundle.
+  - Active: Whether the bundle is the one used by the site.
+  - CreatedAt: When the bundle was uploaded.
+  - ActivatedAt: When the bundle was last activated, if ever.
+  - Archive: The zip archive of the bundle, which is never serialized.
+*/
+type TemplateBundle struct {
+	ID          uuid.UUID  `json:"id"`
+	SiteID      uuid.UUID  `json:"site_id"`
+	Version     int        `json:"version"`
+	Description string     `json:"description,omitempty"`
+	Files       []string   `json:"files"`
+	Size        int64      `json:"size"`
+	Checksum    string     `json:"checksum"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+	Archive     []byte     `json:"-"`
+}
@@ -3,25 +3,127 @@ Package models provides the data structures and definitions related to the user
 
 It includes:
   - The `User` struct that represents a user in the system with fields for the unique
-    ID, name, and email.
-  - Validation tags for ensuring that the `Name` field is at least 5 characters long and
-    that the `Email` field is a valid email address.
+    ID, name, email and public profile.
+  - The `Profile` struct holding the public profile of a user (bio, avatar, website
+    and social links).
+  - The `Author` struct that represents the public view of a user, which never
+    discloses their email address.
+  - The `UserExport` struct holding every piece of data of a user, exported on request
+    of the user (GDPR).
+  - Validation tags for ensuring that the `Name` field is at least 5 characters long,
+    that the `Email` field is a valid email address and that the profile links are
+    valid HTTP(S) URLs.
 */
 
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/markdown"
+)
 
 /*
 User represents the structure of a User entity.
 
 Fields:
   - ID: A unique identifier for the user (UUID).
+  - SiteID: The unique identifier of the site the user belongs to (UUID).
   - Name: The user's name, which must be at least 5 characters long.
   - Email: The user's email address, which must be in a valid email format.
+  - Role: The user's role within the site ("admin", "editor" or "author"), which
+    defaults to "author".
+  - CreatedAt: When the user was created.
+  - UpdatedAt: When the user was last updated (when they were created if never).
+  - DigestOptOut: Whether the user opted out of the daily digest of the comments on
+    their articles.
+  - DigestSentAt: When the user was last sent the digest of the comments on their
+    articles, if ever.
+  - AcceptedPolicy: The version of the last policy of the site the user accepted (see
+    `Policy`), the user being flagged if it is not the current one.
+  - Profile: The user's public profile, whose fields are inlined in the JSON
+    representation of the user.
 */
 type User struct {
-	ID    uuid.UUID `json:"id"`
-	Name  string    `json:"name"  validate:"required,min=5"`
-	Email string    `json:"email" validate:"required,email"`
+	ID        uuid.UUID `json:"id"`
+	SiteID    uuid.UUID `json:"site_id"`
+	Name      string    `json:"name"       validate:"required,min=5"`
+	Email     string    `json:"email"      validate:"required,email"`
+	Role      auth.Role `json:"role"       validate:"omitempty,oneof=admin editor author"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	DigestOptOut bool       `json:"digest_opt_out"`
+	DigestSentAt *time.Time `json:"digest_sent_at,omitempty"`
+
+	AcceptedPolicy string `json:"accepted_policy,omitempty"`
+	Profile
+}
+
+/*
+Profile represents the public profile of a user, every field of which is optional.
+
+Fields:
+  - Bio: A short biography of the user, up to 1000 characters long.
+  - AvatarURL: The HTTP(S) URL of the user's avatar.
+  - Website: The HTTP(S) URL of the user's website.
+  - SocialLinks: The HTTP(S) URLs of the user's social profiles, keyed by the
+    (lowercase, alphanumeric) name of the network, e.g. "github" or "mastodon". Up
+    to 10 social links are accepted.
+*/
+type Profile struct {
+	Bio         string            `json:"bio,omitempty"          validate:"max=1000"`
+	AvatarURL   string            `json:"avatar_url,omitempty"   validate:"omitempty,http_url"`
+	Website     string            `json:"website,omitempty"      validate:"omitempty,http_url"`
+	SocialLinks map[string]string `json:"social_links,omitempty" validate:"max=10,dive,keys,alphanum,lowercase,endkeys,http_url"`
+}
+
+/*
+Author represents the public view of a user, as served to anonymous readers. Unlike
+`User`, it never discloses the email address of the user.
+
+Fields:
+  - ID: The unique identifier of the user (UUID).
+  - Name: The user's name.
+  - Slug: The URL-friendly form of the user's name (e.g. "jane-doe"), which
+    identifies the author in the public URLs along with their ID.
+  - Profile: The user's public profile.
+*/
+type Author struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug"`
+	Profile
+}
+
+// AuthorOf returns the public view of the user.
+func AuthorOf(user User) Author {
+	return Author{
+		ID:      user.ID,
+		Name:    user.Name,
+		Slug:    markdown.Slugify(user.Name),
+		Profile: user.Profile,
+	}
+}
+
+/*
+UserExport holds every piece of data of a user, as exported on request of the user
+(right of access and to data portability, GDPR articles 15 and 20).
+
+Fields:
+  - User: The user, including their profile.
+  - Articles: The articles authored by the user.
+  - Comments: The comments made by the user (i.e. with their email address).
+  - Consents: The consents given by the user along with their comments.
+  - ExportedAt: When the data was exported.
+*/
+type UserExport struct {
+	User       User      `json:"user"`
+	Articles   []Article `json:"articles"`
+	Comments   []Comment `json:"comments"`
+	Consents   []Consent `json:"consents"`
+	ExportedAt time.Time `json:"exported_at"`
 }
@@ -0,0 +1,88 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Webhook` struct that represents an endpoint the events of a site are posted
+    to, e.g. by an integrator keeping a search index up to date.
+  - The `WebhookDelivery` struct that represents an attempt to post an event to a
+    webhook, kept to debug the events its endpoint missed.
+*/
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+Webhook represents an outbound webhook of a site, i.e. an endpoint its events are
+posted to, signed with the secret of the endpoint.
+
+Fields:
+  - ID: The unique identifier for the webhook (UUID).
+  - SiteID: The unique identifier of the site whose events are posted (UUID).
+  - URL: The HTTP(S) URL of the endpoint.
+  - Events: The types of the events posted to the endpoint (e.g. "article.published"),
+    every event being posted if empty.
+  - Secret: The secret the requests are signed with, which is never serialized.
+  - Enabled: Whether the events are posted to the endpoint. A webhook whose endpoint
+    keeps failing is disabled automatically, until it is enabled again.
+  - Failures: The number of consecutive failed attempts to post an event, reset by
+    the first successful one.
+  - FailingSince: When the first of the consecutive failed attempts was made, if any.
+  - LastError: Why the last attempt failed, if it did.
+  - DisabledAt: When the webhook was disabled automatically, if it was.
+  - CreatedAt: When the webhook was created.
+  - UpdatedAt: When the webhook was last updated.
+*/
+type Webhook struct {
+	ID           uuid.UUID  `json:"id"`
+	SiteID       uuid.UUID  `json:"site_id"`
+	URL          string     `json:"url"                     validate:"required,http_url,max=2048"`
+	Events       []string   `json:"events"                  validate:"max=50,dive,required,max=64"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	Failures     int        `json:"failures"`
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+/*
+WebhookDelivery represents an attempt to post an event to the endpoint of a webhook.
+
+Fields:
+  - ID: The unique identifier for the attempt (UUID).
+  - SiteID: The unique identifier of the site of the webhook (UUID).
+  - WebhookID: The unique identifier of the webhook (UUID).
+  - DeliveryID: The unique identifier of the delivery of the event to the webhook
+    (UUID), sent in the `X-Burz-Delivery` header, which its retries and replays share.
+  - Event: The type of the event.
+  - Payload: The JSON body posted to the endpoint.
+  - Attempt: The number of the attempt among the attempts of the delivery, starting at
+    1 (a replay being its own first attempt).
+  - Replay: Whether the attempt replays the delivery on request.
+  - StatusCode: The status code of the response of the endpoint, if it responded.
+  - Error: Why the attempt failed, if it did.
+  - LatencyMS: The time the endpoint took to respond (or to fail), in milliseconds.
+  - AttemptedAt: When the attempt was made.
+*/
+type WebhookDelivery struct {
+	ID          uuid.UUID       `json:"id"`
+	SiteID      uuid.UUID       `json:"site_id"`
+	WebhookID   uuid.UUID       `json:"webhook_id"`
+	DeliveryID  uuid.UUID       `json:"delivery_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempt     int             `json:"attempt"`
+	Replay      bool            `json:"replay"`
+	StatusCode  int             `json:"status_code,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	LatencyMS   int64           `json:"latency_ms"`
+	AttemptedAt time.Time       `json:"attempted_at"`
+}
@@ -0,0 +1,53 @@
+/*
+Package models provides data structures related to the entities in the system.
+
+It includes:
+  - The `Webmention` struct that represents a page of another site linking to an
+    article, which it notified of the link with a webmention.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The moderation statuses of the received webmentions.
+const (
+	WebmentionPending  = "pending"  // Verified and waiting for moderation
+	WebmentionApproved = "approved" // Displayed along with the article
+	WebmentionRejected = "rejected" // Hidden from the readers
+)
+
+/*
+Webmention represents a webmention received by an article of a site, i.e. a page of
+another site linking to the article (see https://www.w3.org/TR/webmention/).
+
+The webmentions are only stored once their source was verified to link to their
+target, and are only displayed to the readers once approved.
+
+Fields:
+  - ID: The unique identifier for the webmention (UUID).
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the mentioned article (UUID).
+  - Source: The URL of the page linking to the article.
+  - Target: The URL of the article the page links to.
+  - Title: The title of the page linking to the article, if any.
+  - Status: The moderation status of the webmention, either "pending", "approved" or
+    "rejected".
+  - CreatedAt: When the webmention was first received.
+  - UpdatedAt: When the webmention was last received or moderated.
+*/
+type Webmention struct {
+	ID        uuid.UUID `json:"id"`
+	SiteID    uuid.UUID `json:"site_id"`
+	ArticleID uuid.UUID `json:"article_id"`
+	Source    string    `json:"source"`
+	Target    string    `json:"target"`
+	Title     string    `json:"title,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
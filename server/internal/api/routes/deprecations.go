@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/deprecation"
+)
+
+// aliasesDeprecatedAt is the date the routes mixing verbs into their paths (e.g.
+// `POST /articles/{id}/edit`) were deprecated at, in favour of their RESTful
+// successors (e.g. `PUT /articles/{id}`).
+var aliasesDeprecatedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+/*
+deprecations lists the deprecated routes and fields of the APIs, named after their
+method and their path relative to the root of their API (e.g. "PUT /articles/new").
+
+The routes are flagged by the `Deprecated` middleware under their name, and the fields
+by their handlers (see `deprecation.Registry.Flag`). A route or field is retired once
+its `Sunset` date is set, announced to its consumers and passed, and the usage reported
+by `GET /deprecations` shows that no consumer still depends on it.
+*/
+var deprecations = []deprecation.Notice{
+	alias("PUT /sites/new", "POST /sites"),
+	alias("POST /sites/{id}/edit", "PUT /sites/{id}"),
+	alias("DELETE /sites/{id}/delete", "DELETE /sites/{id}"),
+	alias("PUT /sites/{id}/domains/new", "POST /sites/{id}/domains"),
+	alias(
+		"DELETE /sites/{id}/domains/{domain}/delete",
+		"DELETE /sites/{id}/domains/{domain}",
+	),
+	alias("PUT /templates/new", "POST /templates"),
+	alias("DELETE /templates/{id}/delete", "DELETE /templates/{id}"),
+	alias("PUT /keys/new", "POST /keys"),
+	alias("DELETE /keys/{id}/delete", "DELETE /keys/{id}"),
+	alias("PUT /users/new", "POST /users"),
+	alias("POST /users/{id}/edit", "PUT /users/{id}"),
+	alias("DELETE /users/{id}/delete", "DELETE /users/{id}"),
+	alias("PUT /articles/new", "POST /articles"),
+	alias("POST /articles/{id}/edit", "PUT /articles/{id}"),
+	alias("DELETE /articles/{id}/delete", "DELETE /articles/{id}"),
+	alias("PUT /articles/{id}/share/new", "POST /articles/{id}/share"),
+	alias(
+		"DELETE /articles/{id}/share/{linkID}/delete",
+		"DELETE /articles/{id}/share/{linkID}",
+	),
+	alias("POST /comments/article/{id}/new", "POST /comments/article/{id}"),
+	alias("DELETE /comments/{id}/delete", "DELETE /comments/{id}"),
+	alias("DELETE /webmentions/{id}/delete", "DELETE /webmentions/{id}"),
+	alias("PUT /pages/new", "POST /pages"),
+	alias("POST /pages/{id}/edit", "PUT /pages/{id}"),
+	alias("DELETE /pages/{id}/delete", "DELETE /pages/{id}"),
+	alias("PUT /menus/new", "POST /menus"),
+	alias("POST /menus/{id}/edit", "PUT /menus/{id}"),
+	alias("DELETE /menus/{id}/delete", "DELETE /menus/{id}"),
+	alias("PUT /redirects/new", "POST /redirects"),
+	alias("POST /redirects/{id}/edit", "PUT /redirects/{id}"),
+	alias("DELETE /redirects/{id}/delete", "DELETE /redirects/{id}"),
+}
+
+// alias returns the notice of a route mixing a verb into its path, deprecated in favour
+// of the successor route.
+func alias(name, successor string) deprecation.Notice {
+	return deprecation.Notice{
+		Name:      name,
+		Since:     aliasesDeprecatedAt,
+		Successor: successor,
+	}
+}
@@ -5,55 +5,692 @@ This package is responsible for setting up the routes and their associated handl
 for various HTTP endpoints. The routes are mapped to functions defined in the
 handlers package, which process incoming requests and generate appropriate responses.
 
-The main function in this package, `SetupRoutes`, configures the application's
-routes and binds them to specific handlers for resource management, such as
-user-related routes.
+The routes are split in two APIs, served by separate routers with their own middleware
+stacks:
+  - The public API, which serves the published content of the sites to anonymous
+    readers. It is read-only (except for the contact form, the anonymous comments, the
+    analytics collector, the preview handshake and the inbound webhooks) and its
+    responses are heavily cached.
+  - The management API, which serves every operation on the sites and their content.
+    Each request has to be authenticated with an API key (or with the cookie of a
+    browser session opened with one, e.g. by the embedded admin interface) and every
+    write request is recorded in the audit log of its site.
+
+The main function in this package, `SetupRoutes`, configures both routers. Every
+resource except the sites themselves is scoped to the site (tenant) the request is
+resolved to.
 */
 package routes
 
 import (
+	"net/http"
+	"time"
+
 	chi "github.com/go-chi/chi/v5"
 
+	"github.com/Weburz/burzcontent/server/internal/adminui"
 	"github.com/Weburz/burzcontent/server/internal/api/handlers"
+	"github.com/Weburz/burzcontent/server/internal/api/middleware"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/flags"
+	"github.com/Weburz/burzcontent/server/internal/idempotency"
+	"github.com/Weburz/burzcontent/server/internal/ratelimit"
 )
 
+// contactMessagesPerHour is the number of messages a client can send through the
+// contact form of a site per hour.
+const contactMessagesPerHour = 5
+
+// pageViewsPerMinute is the number of page views a client can record on a site per
+// minute.
+const pageViewsPerMinute = 60
+
+// idempotencyWindow is the duration for which the responses to the requests made with
+// an idempotency key are replayed.
+const idempotencyWindow = 24 * time.Hour
+
+// uuidParams lists the URL parameters holding the UUID of a resource, which are
+// validated before the request reaches its handler.
+var uuidParams = []string{"id", "linkID"}
+
 /*
-SetupRoutes sets up the application's HTTP routes and maps them to their corresponding
-handlers.
+SetupRoutes sets up the application's HTTP routes on the public and the management
+routers and maps them to their corresponding handlers.
 
 This function performs the following steps:
 
- 1. Configures the `/users` route using `r.Route("/users", ...)` for handling
-    user-related requests.
- 2. Binds the `GET` method for the `/users` route to the `GetUsers` method of
-    the `UserHandler` defined in the `handlers.Handlers` instance.
+ 1. Mounts the public content routes (settings, articles and their short links,
+    shared and previewed articles, tags, archives, pages, menus, comments (and the
+    anonymous comments, once the challenge of the commenter is solved), webmentions,
+    authors, feeds, contact form, analytics, experiment assignments and inbound
+    webhooks) on the public router, whose responses may be cached for cacheMaxAge,
+    along with the redirects configured for each site. The published articles, their
+    short links and the feeds answer the `HEAD` requests as well, for the CDNs and
+    the link validators (see `middleware.Head`).
+ 2. Serves the embedded admin interface at the root of the management router (see the
+    `adminui` package), and configures the `/sites` route of the management router,
+    for managing the sites (tenants) of the deployment and their custom domains with
+    the root API key, and the `/jobs` route reporting the status of the scheduled jobs
+    of the server and the `/tasks` route managing its background task queue.
+ 3. Mounts the management content routes (browser session, dashboard, settings, users,
+    articles and their revisions, reviews, edit locks and webmentions, comments and
+    the consents of the commenters, subscriptions, notifications and comment digests,
+    pages, menus, redirects, analytics, experiments, policies and their acceptances,
+    API keys, usage, audit log, export, import, backups, events, outbound webhooks and
+    their deliveries, deprecations, feature flags and template bundles) on the
+    management router. The write requests of the users who did not accept the current
+    policy of the site are rejected if it requires an acceptance.
+
+On both routers, the content routes are mounted twice: at the root of the router,
+resolving the site of each request from its hostname, and under the `/s/{site}` path
+prefix, resolving the site of each request from the slug in the path (e.g.
+`/s/weburz/articles`). Both APIs share the rate limit of each site.
+
+The UUID parameters of the routes (e.g. the `{id}` of `/articles/{id}`) are validated
+by the `UUIDParams` middleware, which rejects the malformed ones with a 400 status.
 
-The routes are now ready to process incoming requests related to users.
+The resources of the management API are created with a `POST` to their collection
+(e.g. `POST /articles`), replaced with a `PUT` to their path (e.g.
+`PUT /articles/{id}`), partially updated with a JSON merge patch (RFC 7396) sent as a
+`PATCH` to their path and deleted with a `DELETE` to their path. The former routes
+mixing verbs into their paths (`PUT /new`, `POST /{id}/edit` and
+`DELETE /{id}/delete`) are kept as aliases. The deprecated routes are listed in
+`deprecations`, and their responses carry a `Deprecation` header, and a `Sunset` header
+once their removal is scheduled (see the `deprecation` package).
+
+The routes are now ready to process incoming requests related to every resource.
 */
-func SetupRoutes(r *chi.Mux, h *handlers.Handlers) {
+func SetupRoutes(
+	public, admin chi.Router,
+	h *handlers.Handlers,
+	cacheMaxAge time.Duration,
+) {
+	limiter := ratelimit.New(time.Minute, h.Clock)
+	tenant := middleware.Tenant(h.SiteHandler.SiteService)
+	contactLimiter := ratelimit.New(time.Hour, h.Clock)
+	idempotent := middleware.Idempotent(idempotency.New(idempotencyWindow, h.Clock))
+	ids := middleware.UUIDParams(uuidParams...)
+	authenticate := middleware.Authenticate(
+		h.APIKeyHandler.APIKeyService,
+		h.SessionHandler.Sessions,
+	)
+
+	// Flag the deprecated routes, which are all registered in deprecations.go
+	h.DeprecationHandler.Registry.Register(deprecations...)
+	deprecated := func(name string) func(http.Handler) http.Handler {
+		return middleware.Deprecated(h.DeprecationHandler.Registry, name)
+	}
+
+	// Mount the public content routes for the sites resolved by hostname and by path
+	// prefix
+	public.Group(func(r chi.Router) {
+		r.Use(tenant)
+		setupPublicRoutes(r, h, limiter, contactLimiter, ids, cacheMaxAge)
+	})
+	public.Route("/s/{site}", func(r chi.Router) {
+		r.Use(tenant)
+		setupPublicRoutes(r, h, limiter, contactLimiter, ids, cacheMaxAge)
+	})
+
+	// Serve the embedded admin interface at the root of the management API, whose
+	// requests are authenticated by its session
+	admin.Get("/", adminui.Index)
+	admin.Get("/assets/*", adminui.Assets)
+
+	// Mount all handlers related to the sites, which only the root API key can manage
+	admin.Route("/sites", func(r chi.Router) {
+		r.Use(authenticate)
+		r.Use(middleware.RequireRole(auth.RoleAdmin))
+		r.Use(middleware.Audit(h.AuditHandler.AuditService))
+
+		r.Get("/", h.SiteHandler.GetAllSites)
+		r.Post("/", h.SiteHandler.CreateSite)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.SiteHandler.GetSiteByID)
+			r.Put("/{id}", h.SiteHandler.UpdateSite)
+			r.Patch("/{id}", h.SiteHandler.PatchSite)
+			r.Delete("/{id}", h.SiteHandler.DeleteSite)
+		})
+
+		r.Route("/{id}/domains", func(r chi.Router) {
+			r.Use(ids)
+
+			r.Post("/", h.SiteHandler.AddDomain)
+			r.Post("/{domain}/verify", h.SiteHandler.VerifyDomain)
+			r.Delete("/{domain}", h.SiteHandler.RemoveDomain)
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("PUT /sites/new")).Put("/new", h.SiteHandler.CreateSite)
+		r.With(deprecated("POST /sites/{id}/edit"), ids).
+			Post("/{id}/edit", h.SiteHandler.UpdateSite)
+		r.With(deprecated("DELETE /sites/{id}/delete"), ids).
+			Delete("/{id}/delete", h.SiteHandler.DeleteSite)
+		r.With(deprecated("PUT /sites/{id}/domains/new"), ids).
+			Put("/{id}/domains/new", h.SiteHandler.AddDomain)
+		r.With(deprecated("DELETE /sites/{id}/domains/{domain}/delete"), ids).
+			Delete("/{id}/domains/{domain}/delete", h.SiteHandler.RemoveDomain)
+	})
+
+	// Mount the status of the scheduled jobs of the server, which only the root API key
+	// can see
+	admin.Route("/jobs", func(r chi.Router) {
+		r.Use(authenticate)
+		r.Use(middleware.RequireRole(auth.RoleAdmin))
+
+		r.Get("/", h.JobHandler.GetJobs)
+	})
+
+	// Mount the background task queue of the server and its dead-letter list, which
+	// only the root API key can manage
+	admin.Route("/tasks", func(r chi.Router) {
+		r.Use(authenticate)
+		r.Use(middleware.RequireRole(auth.RoleAdmin))
+
+		r.Get("/", h.TaskHandler.GetStats)
+		r.Get("/dead", h.TaskHandler.GetDeadTasks)
+		r.With(ids).Post("/dead/{id}/retry", h.TaskHandler.RetryTask)
+		r.With(ids).Delete("/dead/{id}", h.TaskHandler.DiscardTask)
+	})
+
+	// Mount the management content routes for the sites resolved by hostname and by
+	// path prefix
+	admin.Group(func(r chi.Router) {
+		r.Use(tenant)
+		setupAdminRoutes(r, h, limiter, idempotent, ids, deprecated)
+	})
+	admin.Route("/s/{site}", func(r chi.Router) {
+		r.Use(tenant)
+		setupAdminRoutes(r, h, limiter, idempotent, ids, deprecated)
+	})
+}
+
+// setupPublicRoutes mounts the read-only routes of the published content of a site,
+// its anonymous comments, its contact form, its analytics collector, its experiment
+// assignments and its inbound webhooks, the site having to be resolved by the `Tenant`
+// middleware beforehand. The IDs of the routes are validated by ids.
+func setupPublicRoutes(
+	r chi.Router,
+	h *handlers.Handlers,
+	limiter, contactLimiter *ratelimit.Limiter,
+	ids func(http.Handler) http.Handler,
+	cacheMaxAge time.Duration,
+) {
+	r.Use(middleware.SiteRateLimit(limiter, h.UsageHandler.UsageService))
+	r.Use(middleware.Cache(cacheMaxAge))
+	r.Use(middleware.Redirects(h.RedirectHandler.RedirectService))
+
+	comments := middleware.RequireFlag(h.FlagHandler.FlagService, flags.Comments)
+	webmentions := middleware.RequireFlag(h.FlagHandler.FlagService, flags.Webmentions)
+
+	// Mount the published articles and their comments
+	r.Route("/articles", func(r chi.Router) {
+		getAndHead(r, "/", h.ArticleHandler.GetPublishedArticles)
+		getAndHead(r.With(ids), "/{id}", h.ArticleHandler.GetPublishedArticleByID)
+	})
+	r.With(comments, ids).
+		Get("/comments/article/{id}", h.CommentHandler.GetCommentsFromArticle)
+
+	// Mount the anonymous comments, made once the challenge of the commenter is solved
+	r.With(comments).Get("/comments/challenge", h.CommentHandler.GetChallenge)
+	r.With(comments, ids, middleware.RequireChallenge(h.CommentHandler.Challenges)).
+		Post("/comments/article/{id}", h.CommentHandler.AddCommentToArticle)
+
+	// Mount the receiver of the webmentions sent to the published articles, and their
+	// approved webmentions
+	r.Group(func(r chi.Router) {
+		r.Use(webmentions)
+
+		r.Post("/webmention", h.WebmentionHandler.ReceiveWebmention)
+		r.With(ids).Get(
+			"/webmentions/article/{id}",
+			h.WebmentionHandler.GetApprovedWebmentions,
+		)
+	})
+
+	// Mount the short links of the published articles, aliasing their IDs
+	getAndHead(r, "/a/{shortID}", h.ArticleHandler.GetPublishedArticleByShortID)
+
+	// Mount the tags and the monthly archives of the published articles
+	r.Get("/tags", h.TagHandler.GetTags)
+	r.Get("/archives", h.ArchiveHandler.GetArchives)
+	r.Get("/archives/{year}/{month}", h.ArchiveHandler.GetArchive)
+
+	// Mount the search of the published articles
+	r.Get("/search", h.SearchHandler.Search)
+
+	// Mount the published static pages, served by their (hierarchical) path
+	r.Get("/pages", h.PageHandler.GetPublishedPages)
+	r.Get("/pages/*", h.PageHandler.GetPublishedPageByPath)
+
+	// Mount the settings of the site, e.g. its title and its default locale
+	r.Get("/settings", h.SettingsHandler.GetSettings)
+
+	// Mount the navigation menus, fetched by their handle
+	r.Get("/menus/{handle}", h.MenuHandler.GetPublishedMenu)
+
+	// Mount the articles shared through expiring links, whether published or not
+	r.Get("/share/{token}", h.ShareLinkHandler.GetSharedArticle)
+
+	// Mount the previews of the articles by the headless frontends, started with the
+	// token of a share link
+	r.Route("/preview", func(r chi.Router) {
+		r.Post("/", h.PreviewHandler.StartPreview)
+		r.With(ids).Get("/articles/{id}", h.PreviewHandler.GetPreviewArticle)
+	})
+
+	// Mount the public profiles of the users and their published articles, by ID or
+	// by slug
+	r.Get("/authors/{id}", h.UserHandler.GetAuthorByID)
+	r.Get("/authors/{id}/articles", h.UserHandler.GetAuthorArticles)
+
+	// Mount the feeds of the site
+	getAndHead(r, "/feed.xml", h.FeedHandler.GetFeed)
+	getAndHead(r, "/sitemap.xml", h.FeedHandler.GetSitemap)
+
+	// Mount the contact form, limited to a few messages per client and hour
+	r.With(middleware.ClientRateLimit(contactLimiter, contactMessagesPerHour)).
+		Post("/contact", h.ContactHandler.SendContactMessage)
+
+	// Mount the analytics collector, limited to a reasonable rate of page views per
+	// client
+	r.With(middleware.ClientRateLimit(limiter, pageViewsPerMinute)).
+		Post("/analytics/pageview", h.AnalyticsHandler.RecordPageView)
+
+	// Mount the receiver of the inbound webhooks of the external services, which are
+	// authenticated by their signature
+	r.Post("/hooks/{integration}", h.HookHandler.ReceiveHook)
+
+	// Mount the assignments of the visitors to the variants of the A/B experiments,
+	// whose exposures are recorded by the analytics collector
+	r.Get("/experiments/assignments", h.ExperimentHandler.GetAssignments)
+
+	// Answer the paths which are not routed at all through the middleware of the site,
+	// so that its redirects are served for them (whatever their method, the other
+	// requests being answered with a `404 Not Found`)
+	r.NotFound(handlers.NotFound)
+}
+
+// getAndHead routes the GET and the HEAD requests to the pattern to the handler, which
+// must not have any side effect (see `middleware.Head`).
+func getAndHead(r chi.Router, pattern string, handler http.HandlerFunc) {
+	r.Get(pattern, handler)
+	r.Head(pattern, handler)
+}
+
+// setupAdminRoutes mounts the management routes of the resources scoped to a site,
+// which has to be resolved by the `Tenant` middleware beforehand. The routes creating
+// the articles and the comments accept an idempotency key, through idempotent, the IDs
+// of the routes are validated by ids and the deprecated routes are flagged by the
+// middleware returned by deprecated for their name.
+func setupAdminRoutes(
+	r chi.Router,
+	h *handlers.Handlers,
+	limiter *ratelimit.Limiter,
+	idempotent, ids func(http.Handler) http.Handler,
+	deprecated func(name string) func(http.Handler) http.Handler,
+) {
+	r.Use(middleware.SiteRateLimit(limiter, h.UsageHandler.UsageService))
+	r.Use(middleware.Authenticate(
+		h.APIKeyHandler.APIKeyService,
+		h.SessionHandler.Sessions,
+	))
+	r.Use(middleware.RequireRole(auth.Roles...))
+	r.Use(middleware.RequestFlags)
+	r.Use(middleware.RequirePolicyAcceptance(
+		h.PolicyHandler.PolicyService,
+		"/policies/accept",
+		"/session",
+	))
+	r.Use(middleware.StorageQuota(h.UsageHandler.UsageService))
+	r.Use(middleware.Audit(h.AuditHandler.AuditService))
+
+	comments := middleware.RequireFlag(h.FlagHandler.FlagService, flags.Comments)
+	webmentions := middleware.RequireFlag(h.FlagHandler.FlagService, flags.Webmentions)
+
+	// Mount all handlers related to the API keys, the usage, the audit log, the
+	// retention policy, the encryption keys, the consents of the visitors, the policies
+	// of the site and their acceptances, the export, the import, the backups, the
+	// events, the outbound webhooks and their deliveries, the usage of the deprecated
+	// routes, the feature flags and the template bundles of the site
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(auth.RoleAdmin))
+
+		r.Get("/usage", h.UsageHandler.GetUsage)
+		r.Get("/audit", h.AuditHandler.GetAuditLog)
+		r.Get("/retention", h.RetentionHandler.GetRetention)
+		r.Post("/encryption/rotate", h.EncryptionHandler.RotateKeys)
+		r.Route("/consents", func(r chi.Router) {
+			r.Get("/", h.ConsentHandler.GetConsents)
+			r.Post("/withdraw", h.ConsentHandler.WithdrawConsents)
+		})
+		r.Route("/policies", func(r chi.Router) {
+			r.Get("/", h.PolicyHandler.GetPolicies)
+			r.Post("/", h.PolicyHandler.PublishPolicy)
+			r.Get("/pending", h.PolicyHandler.GetPendingUsers)
+			r.Get("/acceptances", h.PolicyHandler.GetAcceptances)
+		})
+		r.Get("/export", h.ExportHandler.Export)
+		r.Post("/import/wordpress", h.ImportHandler.ImportWordPress)
+		r.Post("/backup", h.BackupHandler.Backup)
+		r.Post("/restore", h.BackupHandler.Restore)
+		r.Get("/events", h.EventHandler.Stream)
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", h.WebhookHandler.GetAllWebhooks)
+			r.Post("/", h.WebhookHandler.CreateWebhook)
+			r.Group(func(r chi.Router) {
+				r.Use(ids)
+
+				r.Get("/{id}", h.WebhookHandler.GetWebhookByID)
+				r.Put("/{id}", h.WebhookHandler.UpdateWebhook)
+				r.Patch("/{id}", h.WebhookHandler.PatchWebhook)
+				r.Delete("/{id}", h.WebhookHandler.DeleteWebhook)
+				r.Post("/{id}/rotate", h.WebhookHandler.RotateSecret)
+				r.Get("/{id}/deliveries", h.WebhookHandler.GetDeliveries)
+			})
+		})
+		r.With(ids).Post("/deliveries/{id}/replay", h.WebhookHandler.ReplayDelivery)
+		r.Get("/deprecations", h.DeprecationHandler.GetDeprecations)
+		r.Route("/flags", func(r chi.Router) {
+			r.Get("/", h.FlagHandler.GetFlags)
+			r.Put("/{name}", h.FlagHandler.SetFlag)
+			r.Delete("/{name}", h.FlagHandler.ResetFlag)
+		})
+		r.Route("/templates", func(r chi.Router) {
+			r.Get("/", h.TemplateHandler.GetAllBundles)
+			r.Post("/", h.TemplateHandler.UploadBundle)
+			r.Post("/rollback", h.TemplateHandler.Rollback)
+			r.With(ids).Post("/{id}/activate", h.TemplateHandler.ActivateBundle)
+			r.With(ids).Delete("/{id}", h.TemplateHandler.DeleteBundle)
+
+			// Keep the deprecated aliases of the routes
+			r.With(deprecated("PUT /templates/new")).
+				Put("/new", h.TemplateHandler.UploadBundle)
+			r.With(deprecated("DELETE /templates/{id}/delete"), ids).
+				Delete("/{id}/delete", h.TemplateHandler.DeleteBundle)
+		})
+		r.Route("/keys", func(r chi.Router) {
+			r.Get("/", h.APIKeyHandler.GetAllAPIKeys)
+			r.Post("/", h.APIKeyHandler.CreateAPIKey)
+			r.With(ids).Delete("/{id}", h.APIKeyHandler.DeleteAPIKey)
+			r.With(ids).Post("/{id}/rotate", h.APIKeyHandler.RotateAPIKey)
+
+			// Keep the deprecated aliases of the routes
+			r.With(deprecated("PUT /keys/new")).
+				Put("/new", h.APIKeyHandler.CreateAPIKey)
+			r.With(deprecated("DELETE /keys/{id}/delete"), ids).
+				Delete("/{id}/delete", h.APIKeyHandler.DeleteAPIKey)
+		})
+	})
+
+	// Mount the acceptance of the current policy of the site, which every user has to
+	// be able to make
+	r.Post("/policies/accept", h.PolicyHandler.AcceptPolicy)
+
+	// Mount the browser session of the admin interface, opened with an API key, which
+	// every user has to be able to open and close
+	r.Route("/session", func(r chi.Router) {
+		r.Get("/", h.SessionHandler.GetSession)
+		r.Post("/", h.SessionHandler.CreateSession)
+		r.Delete("/", h.SessionHandler.DeleteSession)
+	})
+
+	// Mount the dashboard of the site
+	r.Get("/dashboard", h.DashboardHandler.GetDashboard)
+
+	// Mount the settings of the site, which only an admin can update
+	r.Get("/settings", h.SettingsHandler.GetSettings)
+	r.With(middleware.RequireRole(auth.RoleAdmin)).
+		Patch("/settings", h.SettingsHandler.UpdateSettings)
+
 	// Mount all handlers related to the users
 	r.Route("/users", func(r chi.Router) {
 		r.Get("/", h.UserHandler.GetAllUsers)
-		r.Put("/new", h.UserHandler.CreateUser)
-		r.Get("/{id}", h.UserHandler.GetUserByID)
-		r.Post("/{id}/edit", h.UserHandler.UpdateUser)
-		r.Delete("/{id}/delete", h.UserHandler.DeleteUser)
+		r.Post("/", h.UserHandler.CreateUser)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.UserHandler.GetUserByID)
+			r.Get("/{id}/articles", h.UserHandler.GetUserArticles)
+			r.Put("/{id}", h.UserHandler.UpdateUser)
+			r.Patch("/{id}", h.UserHandler.PatchUser)
+			r.Delete("/{id}", h.UserHandler.DeleteUser)
+
+			// The data of a user can only be exported by an admin
+			r.With(middleware.RequireRole(auth.RoleAdmin)).
+				Post("/{id}/export", h.UserHandler.ExportUser)
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("PUT /users/new")).Put("/new", h.UserHandler.CreateUser)
+		r.With(deprecated("POST /users/{id}/edit"), ids).
+			Post("/{id}/edit", h.UserHandler.UpdateUser)
+		r.With(deprecated("DELETE /users/{id}/delete"), ids).
+			Delete("/{id}/delete", h.UserHandler.DeleteUser)
 	})
 
 	// Mount all handlers related to the articles
 	r.Route("/articles", func(r chi.Router) {
 		r.Get("/", h.ArticleHandler.GetAllArticles)
-		r.Put("/new", h.ArticleHandler.CreateArticle)
-		r.Get("/{id}", h.ArticleHandler.GetArticleByID)
-		r.Post("/{id}/edit", h.ArticleHandler.UpdateArticle)
-		r.Delete("/{id}/delete", h.ArticleHandler.DeleteArticle)
+		r.With(idempotent).Post("/", h.ArticleHandler.CreateArticle)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.ArticleHandler.GetArticleByID)
+			r.Put("/{id}", h.ArticleHandler.UpdateArticle)
+			r.Patch("/{id}", h.ArticleHandler.PatchArticle)
+			r.Delete("/{id}", h.ArticleHandler.DeleteArticle)
+			r.Post("/{id}/restore", h.ArticleHandler.RestoreArticle)
+			r.Delete("/{id}/purge", h.ArticleHandler.PurgeArticle)
+			r.Get("/{id}/diff", h.RevisionHandler.GetRevisionDiff)
+
+			// Mount the editorial review of the article, on which only the editors
+			// (and the admins) can decide
+			r.Get("/{id}/review", h.ReviewHandler.GetReview)
+			r.Post("/{id}/submit-for-review", h.ReviewHandler.SubmitForReview)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireRole(auth.RoleEditor))
+
+				r.Post("/{id}/reviewer", h.ReviewHandler.AssignReviewer)
+				r.Post("/{id}/approve", h.ReviewHandler.ApproveArticle)
+				r.Post("/{id}/request-changes", h.ReviewHandler.RequestChanges)
+			})
+
+			// Mount the subscription of the user to the comments of the article
+			r.Post("/{id}/subscribe", h.SubscriptionHandler.Subscribe)
+			r.Delete("/{id}/subscribe", h.SubscriptionHandler.Unsubscribe)
+
+			// Mount the lock of the article, telling who is editing it
+			r.Get("/{id}/lock", h.EditLockHandler.GetLock)
+			r.Post("/{id}/lock", h.EditLockHandler.Lock)
+			r.Post("/{id}/lock/heartbeat", h.EditLockHandler.Heartbeat)
+			r.Post("/{id}/lock/release", h.EditLockHandler.Release)
+
+			// Mount the webmentions received by the article, whatever their status
+			r.With(webmentions).
+				Get("/{id}/webmentions", h.WebmentionHandler.GetWebmentions)
+		})
+
+		// Mount the bulk operations on the articles
+		r.Post("/publish", h.ArticleHandler.PublishArticles)
+		r.Post("/unpublish", h.ArticleHandler.UnpublishArticles)
+
+		// Mount the trash the deleted articles are moved to (and restored or purged
+		// from, by ID)
+		r.Get("/trash", h.ArticleHandler.GetTrashedArticles)
+
+		// Mount the links sharing the article, e.g. a draft to review
+		r.Route("/{id}/share", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(ids)
+
+				r.Get("/", h.ShareLinkHandler.GetShareLinks)
+				r.Post("/", h.ShareLinkHandler.CreateShareLink)
+				r.Delete("/{linkID}", h.ShareLinkHandler.RevokeShareLink)
+			})
+		})
+
+		// Mount the revisions recorded each time the article is created or updated
+		r.Route("/{id}/revisions", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(ids)
+
+				r.Get("/", h.RevisionHandler.GetRevisions)
+				r.Get("/{rev}/changes", h.RevisionHandler.GetRevisionChanges)
+			})
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("PUT /articles/new"), idempotent).
+			Put("/new", h.ArticleHandler.CreateArticle)
+		r.With(deprecated("POST /articles/{id}/edit"), ids).
+			Post("/{id}/edit", h.ArticleHandler.UpdateArticle)
+		r.With(deprecated("DELETE /articles/{id}/delete"), ids).
+			Delete("/{id}/delete", h.ArticleHandler.DeleteArticle)
+		r.With(deprecated("PUT /articles/{id}/share/new"), ids).
+			Put("/{id}/share/new", h.ShareLinkHandler.CreateShareLink)
+		r.With(deprecated("DELETE /articles/{id}/share/{linkID}/delete"), ids).
+			Delete("/{id}/share/{linkID}/delete", h.ShareLinkHandler.RevokeShareLink)
 	})
 
 	// Mount all handlers related to the comments
 	r.Route("/comments", func(r chi.Router) {
+		r.Use(comments)
+
 		r.Get("/", h.CommentHandler.GetAllComments)
-		r.Get("/article/{id}", h.CommentHandler.GetCommentsFromArticle)
-		r.Post("/article/{id}/new", h.CommentHandler.AddCommentToArticle)
-		r.Delete("/{id}/delete", h.CommentHandler.DeleteCommentFromArticle)
+		r.Get("/mentions", h.CommentHandler.GetMentions)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/article/{id}", h.CommentHandler.GetCommentsFromArticle)
+			r.With(idempotent).
+				Post("/article/{id}", h.CommentHandler.AddCommentToArticle)
+			r.Delete("/{id}", h.CommentHandler.DeleteCommentFromArticle)
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("POST /comments/article/{id}/new"), ids, idempotent).
+			Post("/article/{id}/new", h.CommentHandler.AddCommentToArticle)
+		r.With(deprecated("DELETE /comments/{id}/delete"), ids).
+			Delete("/{id}/delete", h.CommentHandler.DeleteCommentFromArticle)
+	})
+
+	// Mount the subscriptions of the user to the comments of the articles and their
+	// in-app notifications
+	r.Route("/subscriptions", func(r chi.Router) {
+		r.Get("/", h.SubscriptionHandler.GetSubscriptions)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Post("/{id}/mute", h.SubscriptionHandler.MuteSubscription)
+			r.Post("/{id}/unmute", h.SubscriptionHandler.UnmuteSubscription)
+		})
+	})
+	r.Get("/notifications", h.SubscriptionHandler.GetNotifications)
+
+	// Mount the opt-out of the user from the digest of the comments on their articles
+	r.Post("/digest/opt-out", h.DigestHandler.OptOut)
+	r.Post("/digest/opt-in", h.DigestHandler.OptIn)
+
+	// Mount the moderation of the webmentions received by the articles
+	r.Route("/webmentions", func(r chi.Router) {
+		r.Use(webmentions)
+
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Post("/{id}/approve", h.WebmentionHandler.ApproveWebmention)
+			r.Post("/{id}/reject", h.WebmentionHandler.RejectWebmention)
+			r.Delete("/{id}", h.WebmentionHandler.DeleteWebmention)
+
+			// Keep the deprecated alias of the route
+			r.With(deprecated("DELETE /webmentions/{id}/delete")).
+				Delete("/{id}/delete", h.WebmentionHandler.DeleteWebmention)
+		})
+	})
+
+	// Mount all handlers related to the static pages
+	r.Route("/pages", func(r chi.Router) {
+		r.Get("/", h.PageHandler.GetAllPages)
+		r.Post("/", h.PageHandler.CreatePage)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.PageHandler.GetPageByID)
+			r.Put("/{id}", h.PageHandler.UpdatePage)
+			r.Patch("/{id}", h.PageHandler.PatchPage)
+			r.Delete("/{id}", h.PageHandler.DeletePage)
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("PUT /pages/new")).Put("/new", h.PageHandler.CreatePage)
+		r.With(deprecated("POST /pages/{id}/edit"), ids).
+			Post("/{id}/edit", h.PageHandler.UpdatePage)
+		r.With(deprecated("DELETE /pages/{id}/delete"), ids).
+			Delete("/{id}/delete", h.PageHandler.DeletePage)
+	})
+
+	// Mount all handlers related to the navigation menus
+	r.Route("/menus", func(r chi.Router) {
+		r.Get("/", h.MenuHandler.GetAllMenus)
+		r.Post("/", h.MenuHandler.CreateMenu)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.MenuHandler.GetMenuByID)
+			r.Put("/{id}", h.MenuHandler.UpdateMenu)
+			r.Patch("/{id}", h.MenuHandler.PatchMenu)
+			r.Delete("/{id}", h.MenuHandler.DeleteMenu)
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("PUT /menus/new")).Put("/new", h.MenuHandler.CreateMenu)
+		r.With(deprecated("POST /menus/{id}/edit"), ids).
+			Post("/{id}/edit", h.MenuHandler.UpdateMenu)
+		r.With(deprecated("DELETE /menus/{id}/delete"), ids).
+			Delete("/{id}/delete", h.MenuHandler.DeleteMenu)
+	})
+
+	// Mount all handlers related to the redirects
+	r.Route("/redirects", func(r chi.Router) {
+		r.Get("/", h.RedirectHandler.GetAllRedirects)
+		r.Post("/", h.RedirectHandler.CreateRedirect)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.RedirectHandler.GetRedirectByID)
+			r.Put("/{id}", h.RedirectHandler.UpdateRedirect)
+			r.Patch("/{id}", h.RedirectHandler.PatchRedirect)
+			r.Delete("/{id}", h.RedirectHandler.DeleteRedirect)
+		})
+
+		// Keep the deprecated aliases of the routes
+		r.With(deprecated("PUT /redirects/new")).
+			Put("/new", h.RedirectHandler.CreateRedirect)
+		r.With(deprecated("POST /redirects/{id}/edit"), ids).
+			Post("/{id}/edit", h.RedirectHandler.UpdateRedirect)
+		r.With(deprecated("DELETE /redirects/{id}/delete"), ids).
+			Delete("/{id}/delete", h.RedirectHandler.DeleteRedirect)
+	})
+
+	// Mount all handlers related to the analytics
+	r.Get("/analytics/articles/top", h.AnalyticsHandler.GetTopArticles)
+	r.Get("/analytics/countries", h.AnalyticsHandler.GetCountries)
+
+	// Mount all handlers related to the A/B experiments and their results
+	r.Route("/experiments", func(r chi.Router) {
+		r.Get("/", h.ExperimentHandler.GetAllExperiments)
+		r.Post("/", h.ExperimentHandler.CreateExperiment)
+		r.Group(func(r chi.Router) {
+			r.Use(ids)
+
+			r.Get("/{id}", h.ExperimentHandler.GetExperimentByID)
+			r.Put("/{id}", h.ExperimentHandler.UpdateExperiment)
+			r.Patch("/{id}", h.ExperimentHandler.PatchExperiment)
+			r.Delete("/{id}", h.ExperimentHandler.DeleteExperiment)
+			r.Get("/{id}/results", h.ExperimentHandler.GetResults)
+		})
 	})
 }
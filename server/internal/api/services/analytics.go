@@ -0,0 +1,301 @@
+/*
+Package services provides operations for the first-party analytics of the sites.
+
+The primary interface, `AnalyticsService`, defines methods to record the pages viewed
+by the readers of a site and to report the audience of its articles and of each
+country. The `AnalyticsServiceImpl` struct provides the concrete implementation of
+these methods.
+
+The analytics are privacy-friendly: no cookie is involved and neither the IP address
+nor the user agent of the readers is stored, only their country (see
+`models.PageView`).
+*/
+package services
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/geoip"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// AnalyticsService defines the methods for the first-party analytics of the sites.
+type AnalyticsService interface {
+	// RecordPageView records the page viewed by the reader of the given IP address and
+	// user agent.
+	RecordPageView(
+		ctx context.Context,
+		view models.PageView,
+		ip, userAgent string,
+	) error
+
+	// GetTopArticles reports the most viewed articles of the site over the period.
+	GetTopArticles(
+		ctx context.Context,
+		period time.Duration,
+		limit int,
+	) ([]models.TopArticle, error)
+
+	// GetCountries reports the audience of the site by country over the period.
+	GetCountries(
+		ctx context.Context,
+		period time.Duration,
+	) ([]models.CountryAudience, error)
+}
+
+// AnalyticsServiceImpl is the concrete implementation of the AnalyticsService
+// interface.
+type AnalyticsServiceImpl struct {
+	views       repository.AnalyticsRepository
+	articles    repository.ArticleRepository
+	experiments repository.ExperimentRepository
+	ids         IDGenerator
+	clock       Clock
+
+	mu      sync.Mutex
+	saltDay string
+	salt    []byte
+}
+
+// NewAnalyticsService creates and returns a new instance of AnalyticsServiceImpl
+// storing the page views in the given repository, stamped with the time told by the
+// given clock.
+func NewAnalyticsService(
+	views repository.AnalyticsRepository,
+	articles repository.ArticleRepository,
+	experiments repository.ExperimentRepository,
+	ids IDGenerator,
+	clock Clock,
+) *AnalyticsServiceImpl {
+	return &AnalyticsServiceImpl{
+		views:       views,
+		articles:    articles,
+		experiments: experiments,
+		ids:         ids,
+		clock:       clock,
+	}
+}
+
+/*
+RecordPageView records the page viewed by a reader of the site held by the context.
+
+Only the path of the page and the host of the referrer are kept, without their query
+strings, along with the country of the reader if it was located (see
+`middleware.GeoRestrict`). If the page is an article, `repository.ErrNotFound` is
+returned (wrapped) if the article does not exist or is not published. If the page
+exposed the reader to the variant of an experiment, `ErrUnknownVariant` is returned
+(wrapped) if the experiment or the variant does not exist.
+*/
+func (as *AnalyticsServiceImpl) RecordPageView(
+	ctx context.Context,
+	view models.PageView,
+	ip, userAgent string,
+) error {
+	siteID := tenant.SiteID(ctx)
+
+	if view.ArticleID != nil {
+		article, err := as.articles.Get(ctx, siteID, *view.ArticleID)
+		if err == nil && !article.IsPublished {
+			err = repository.ErrNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to fetch article %s: %w", *view.ArticleID, err)
+		}
+	}
+
+	if view.Experiment != "" {
+		err := checkExposure(ctx, as.experiments, view.Experiment, view.Variant)
+		if err != nil {
+			return err
+		}
+	}
+
+	viewID := as.ids.NewID()
+
+	now := as.clock.Now()
+	view.ID = viewID
+	view.SiteID = siteID
+	view.At = now
+	view.Visitor = as.visitor(now, siteID, ip, userAgent)
+	view.Country = geoip.FromContext(ctx)
+
+	if u, err := url.Parse(view.Path); err == nil {
+		view.Path = u.Path
+	}
+
+	if u, err := url.Parse(view.Referrer); err == nil {
+		view.Referrer = u.Hostname()
+	} else {
+		view.Referrer = ""
+	}
+
+	if err := as.views.Create(ctx, view); err != nil {
+		return fmt.Errorf("unable to record page view: %w", err)
+	}
+
+	return nil
+}
+
+/*
+GetTopArticles reports the most viewed articles of the site held by the context over
+the period (e.g. the last 7 days), most viewed first, up to limit articles.
+
+The articles deleted since they were viewed are left out of the report.
+*/
+func (as *AnalyticsServiceImpl) GetTopArticles(
+	ctx context.Context,
+	period time.Duration,
+	limit int,
+) ([]models.TopArticle, error) {
+	siteID := tenant.SiteID(ctx)
+
+	views, err := as.views.ListSince(ctx, siteID, as.clock.Now().Add(-period))
+	if err != nil {
+		return []models.TopArticle{}, fmt.Errorf("unable to fetch page views: %w", err)
+	}
+
+	stats := make(map[uuid.UUID]*models.TopArticle)
+	visitors := make(map[uuid.UUID]map[string]bool)
+	for _, view := range views {
+		if view.ArticleID == nil {
+			continue
+		}
+
+		id := *view.ArticleID
+		if stats[id] == nil {
+			stats[id] = &models.TopArticle{ArticleID: id}
+			visitors[id] = make(map[string]bool)
+		}
+
+		stats[id].Views++
+		visitors[id][view.Visitor] = true
+	}
+
+	top := make([]models.TopArticle, 0, len(stats))
+	for id, stat := range stats {
+		article, err := as.articles.Get(ctx, siteID, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return []models.TopArticle{}, fmt.Errorf(
+				"unable to fetch article %s: %w", id, err,
+			)
+		}
+
+		stat.Title = article.Title
+		stat.Visitors = len(visitors[id])
+		top = append(top, *stat)
+	}
+
+	slices.SortFunc(top, func(a, b models.TopArticle) int {
+		return cmp.Or(
+			cmp.Compare(b.Views, a.Views),
+			cmp.Compare(b.Visitors, a.Visitors),
+			cmp.Compare(a.Title, b.Title),
+		)
+	})
+
+	return top[:min(limit, len(top))], nil
+}
+
+/*
+GetCountries reports the audience of the site held by the context by country over the
+period (e.g. the last 7 days), the countries with the most page views first. The page
+views whose country is unknown are reported under an empty country.
+*/
+func (as *AnalyticsServiceImpl) GetCountries(
+	ctx context.Context,
+	period time.Duration,
+) ([]models.CountryAudience, error) {
+	siteID := tenant.SiteID(ctx)
+
+	views, err := as.views.ListSince(ctx, siteID, as.clock.Now().Add(-period))
+	if err != nil {
+		return []models.CountryAudience{}, fmt.Errorf(
+			"unable to fetch page views: %w", err,
+		)
+	}
+
+	stats := make(map[string]*models.CountryAudience)
+	visitors := make(map[string]map[string]bool)
+	for _, view := range views {
+		if stats[view.Country] == nil {
+			stats[view.Country] = &models.CountryAudience{Country: view.Country}
+			visitors[view.Country] = make(map[string]bool)
+		}
+
+		stats[view.Country].Views++
+		visitors[view.Country][view.Visitor] = true
+	}
+
+	countries := make([]models.CountryAudience, 0, len(stats))
+	for country, stat := range stats {
+		stat.Visitors = len(visitors[country])
+		countries = append(countries, *stat)
+	}
+
+	slices.SortFunc(countries, func(a, b models.CountryAudience) int {
+		return cmp.Or(
+			cmp.Compare(b.Views, a.Views),
+			cmp.Compare(b.Visitors, a.Visitors),
+			cmp.Compare(a.Country, b.Country),
+		)
+	})
+
+	return countries, nil
+}
+
+// visitor returns the anonymous visitor hash of the reader, derived from its
+// truncated IP address and user agent with the salt of the day.
+func (as *AnalyticsServiceImpl) visitor(
+	now time.Time,
+	siteID uuid.UUID,
+	ip, userAgent string,
+) string {
+	as.mu.Lock()
+	if day := now.Format(time.DateOnly); day != as.saltDay {
+		as.saltDay = day
+		as.salt = []byte(rand.Text())
+	}
+	salt := as.salt
+	as.mu.Unlock()
+
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(siteID[:])
+	h.Write([]byte(truncateIP(ip)))
+	h.Write([]byte(userAgent))
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// truncateIP returns the network of the IP address, i.e. the address without its last
+// octet for IPv4 and without its last 80 bits for IPv6.
+func truncateIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,189 @@
+/*
+Package services provides operations for managing the API keys of the sites.
+
+The primary interface, `APIKeyService`, defines methods to issue, list, rotate and
+revoke the API keys of a site, and to authenticate the API key presented by a request.
+The `APIKeyServiceImpl` struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrInvalidAPIKey is returned when a request presents an unknown API key.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService defines the methods for API key management and authentication.
+type APIKeyService interface {
+	// GetAllAPIKeys retrieves every API key of the site.
+	GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error)
+
+	// CreateAPIKey issues a new API key with the given name and role, returning the
+	// stored API key along with the plain text key (which is never stored).
+	CreateAPIKey(
+		ctx context.Context,
+		name, role string,
+		userID uuid.UUID,
+	) (models.APIKey, string, error)
+
+	// RotateAPIKey replaces the key of the API key identified by its unique ID,
+	// returning the stored API key along with the new plain text key.
+	RotateAPIKey(ctx context.Context, id uuid.UUID) (models.APIKey, string, error)
+
+	// DeleteAPIKey revokes the API key identified by its unique ID.
+	DeleteAPIKey(ctx context.Context, id uuid.UUID) error
+
+	// Authenticate returns the principal authenticated by the plain text key.
+	Authenticate(ctx context.Context, key string) (auth.Principal, error)
+}
+
+/*
+APIKeyServiceImpl is the concrete implementation of the APIKeyService interface.
+
+Besides the API keys of the sites, it authenticates the root API key of the deployment
+(if configured), which is granted the admin role on every site.
+*/
+type APIKeyServiceImpl struct {
+	keys    repository.APIKeyRepository
+	rootKey string
+	ids     IDGenerator
+}
+
+/*
+NewAPIKeyService creates and returns a new instance of APIKeyServiceImpl backed by the
+given API key repository. The root API key is disabled when rootKey is empty.
+*/
+func NewAPIKeyService(
+	keys repository.APIKeyRepository,
+	rootKey string,
+	ids IDGenerator,
+) *APIKeyServiceImpl {
+	return &APIKeyServiceImpl{keys: keys, rootKey: rootKey, ids: ids}
+}
+
+// GetAllAPIKeys retrieves every API key of the site held by the context.
+func (ks *APIKeyServiceImpl) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := ks.keys.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.APIKey{}, fmt.Errorf("unable to fetch API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+/*
+CreateAPIKey issues a new API key for the site held by the context.
+
+The plain text key is only returned once, by this method; the repository only stores
+its digest.
+*/
+func (ks *APIKeyServiceImpl) CreateAPIKey(
+	ctx context.Context,
+	name, role string,
+	userID uuid.UUID,
+) (models.APIKey, string, error) {
+	keyID := ks.ids.NewID()
+
+	plain, prefix, err := auth.GenerateKey()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	key := models.APIKey{
+		ID:     keyID,
+		SiteID: tenant.SiteID(ctx),
+		UserID: userID,
+		Name:   name,
+		Prefix: prefix,
+		Role:   role,
+		Hash:   auth.HashKey(plain),
+	}
+
+	if err := ks.keys.Create(ctx, key); err != nil {
+		return models.APIKey{}, "", fmt.Errorf("unable to create API key: %w", err)
+	}
+
+	return key, plain, nil
+}
+
+/*
+RotateAPIKey replaces the key of the API key of the site held by the context with a
+newly generated one, wrapping `repository.ErrNotFound` if no such API key exists.
+
+The API key keeps its ID, name, owner and role, while the former key stops
+authenticating the requests right away. The new plain text key is only returned once,
+by this method.
+*/
+func (ks *APIKeyServiceImpl) RotateAPIKey(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.APIKey, string, error) {
+	key, err := ks.keys.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf(
+			"unable to fetch API key %s: %w", id, err,
+		)
+	}
+
+	plain, prefix, err := auth.GenerateKey()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	key.Prefix = prefix
+	key.Hash = auth.HashKey(plain)
+
+	if err := ks.keys.Update(ctx, key); err != nil {
+		return models.APIKey{}, "", fmt.Errorf(
+			"unable to rotate API key %s: %w", id, err,
+		)
+	}
+
+	return key, plain, nil
+}
+
+// DeleteAPIKey revokes the API key of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such API key exists.
+func (ks *APIKeyServiceImpl) DeleteAPIKey(ctx context.Context, id uuid.UUID) error {
+	if err := ks.keys.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete API key %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the principal authenticated by the plain text key, or
+// `ErrInvalidAPIKey` if the key is unknown.
+func (ks *APIKeyServiceImpl) Authenticate(
+	ctx context.Context,
+	key string,
+) (auth.Principal, error) {
+	if ks.rootKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(ks.rootKey)) == 1 {
+		return auth.Principal{Role: auth.RoleAdmin, Root: true}, nil
+	}
+
+	apiKey, err := ks.keys.GetByHash(ctx, auth.HashKey(key))
+	if errors.Is(err, repository.ErrNotFound) {
+		return auth.Principal{}, ErrInvalidAPIKey
+	} else if err != nil {
+		return auth.Principal{}, fmt.Errorf("unable to authenticate API key: %w", err)
+	}
+
+	return auth.Principal{
+		KeyID:  apiKey.ID,
+		SiteID: apiKey.SiteID,
+		UserID: apiKey.UserID,
+		Role:   auth.Role(apiKey.Role),
+	}, nil
+}
@@ -11,16 +11,27 @@ The package provides the following key functionalities:
 
   - GetAllArticles: Retrieves a list of all articles available in the system.
   - GetArticleByID: Fetches an article based on its unique identifier.
+  - GetArticleByShortID: Fetches an article based on its short ID, the alias of its
+    unique identifier used to share it.
   - CreateArticle: Creates a new article by providing a title, author, and publication
     status.
   - UpdateArticle: Updates the details of an existing article, including title, author
     and publication status.
-  - DeleteArticle: Removes an article from the system using its unique identifier.
+  - DeleteArticle: Moves an article to the trash using its unique identifier.
+  - GetTrashedArticles, RestoreArticle and PurgeArticle: Manage the trash, from which
+    the articles can be restored until they are purged (see also `PurgeTrash`).
+  - GetTags: Lists the tags of the published articles along with their counts.
+  - GetArchives and GetArchive: Serve the archives of the published articles by month.
+  - Search: Searches the published articles, locating the matches and counting the
+    facets of the articles found.
 
 This package is designed to handle typical CRUD (Create, Read, Update, Delete)
 operations for articles, allowing the system to manage article data in a flexible
 manner.
 
+Every operation is scoped to the site (tenant) held by the context passed to it, see
+the `tenant` package.
+
 The package also includes a constructor function, `NewArticleService`, which initializes
 and returns an instance of `ArticleServiceImpl` that implements the `ArticleService`
 interface.
@@ -28,11 +39,23 @@ interface.
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/search"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
 )
 
 /*
@@ -46,222 +69,1240 @@ and deleting articles by their ID.
 type ArticleService interface {
 	// GetAllArticles retrieves all the articles in the system.
 	// It returns a slice of Article models and an error if any occurs.
-	GetAllArticles() ([]models.Article, error)
+	GetAllArticles(ctx context.Context) ([]models.Article, error)
+
+	// GetPublishedArticles retrieves the published articles in the system.
+	// It returns a slice of Article models and an error if any occurs.
+	GetPublishedArticles(ctx context.Context) ([]models.Article, error)
 
 	// GetArticleByID fetches a specific article by its unique ID.
 	// It returns the Article model and an error if the article could not be found.
-	GetArticleByID(id uuid.UUID) (models.Article, error)
+	GetArticleByID(ctx context.Context, id uuid.UUID) (models.Article, error)
+
+	// GetArticleByShortID fetches a specific article by its short ID.
+	// It returns the Article model and an error if the article could not be found.
+	GetArticleByShortID(ctx context.Context, shortID string) (models.Article, error)
 
 	// CreateArticle creates a new article with the specified title, author, and
 	// publication status.
 	// It returns the newly created article model and an error if any occurs.
-	CreateArticle(title, author string, isPublished bool) (models.Article, error)
+	CreateArticle(
+		ctx context.Context,
+		title, author string,
+		isPublished bool,
+		body models.ArticleBody,
+	) (models.Article, error)
 
 	// UpdateArticle updates an existing article based on its ID.
-	// The method accepts a unique ID, new title, new author, and publication status for
-	// the update.
+	// The method accepts a unique ID, the version the update is made from, new title,
+	// new author, and publication status for the update.
 	// It returns the updated article and an error if any occurs.
 	UpdateArticle(
+		ctx context.Context,
 		id uuid.UUID,
+		version int,
 		title, author string,
 		isPublished bool,
+		body models.ArticleBody,
 	) (models.Article, error)
 
-	// DeleteArticle removes an article from the system using its unique ID.
+	// SetPublished publishes (or unpublishes) a batch of articles, reporting the
+	// outcome for each of them.
+	SetPublished(
+		ctx context.Context,
+		ids []uuid.UUID,
+		isPublished bool,
+	) ([]models.BulkResult, error)
+
+	// DeleteArticle moves an article to the trash using its unique ID.
 	// It returns an error if the article could not be deleted (e.g., if it doesn't
 	// exist).
-	DeleteArticle(id uuid.UUID) error
+	DeleteArticle(ctx context.Context, id uuid.UUID) error
+
+	// GetTrashedArticles retrieves the articles in the trash.
+	GetTrashedArticles(ctx context.Context) ([]models.Article, error)
+
+	// RestoreArticle restores an article from the trash using its unique ID.
+	RestoreArticle(ctx context.Context, id uuid.UUID) (models.Article, error)
+
+	// PurgeArticle removes an article from the trash for good using its unique ID.
+	PurgeArticle(ctx context.Context, id uuid.UUID) error
+
+	// PurgeTrash removes the articles (of every site) trashed before the given time
+	// for good, returning how many were purged.
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+
+	// UnpublishExpired unpublishes the articles (of every site) which expired at the
+	// given time, returning how many were unpublished.
+	UnpublishExpired(ctx context.Context, at time.Time) (int, error)
+
+	// PublishScheduled publishes the articles of the site whose publication was
+	// scheduled at or before the given time, returning how many were published.
+	PublishScheduled(ctx context.Context, at time.Time) (int, error)
+
+	// GetTags retrieves the tags of the published articles, with their counts and how
+	// much they grew over the trending window.
+	GetTags(ctx context.Context, window time.Duration) ([]models.Tag, error)
+
+	// GetArchives retrieves the months articles were published in, with their counts.
+	GetArchives(ctx context.Context) ([]models.ArchiveMonth, error)
+
+	// GetArchive retrieves a page of the articles published in the month, along with
+	// the number of articles published in the month.
+	GetArchive(
+		ctx context.Context,
+		year int,
+		month time.Month,
+		page pagination.Page,
+	) ([]models.Article, int, error)
+
+	// Search retrieves a page of the published articles matching the search query,
+	// along with the number of articles found and their facets.
+	Search(
+		ctx context.Context,
+		query models.SearchQuery,
+		page pagination.Page,
+	) ([]models.SearchResult, int, models.SearchFacets, error)
+}
+
+// ShortcodeExpander expands the shortcodes of the content of the articles into their
+// (sanitized) embeds, like `shortcode.Registry` does.
+type ShortcodeExpander interface {
+	Expand(content string) string
 }
 
 /*
 ArticleServiceImpl is the concrete implementation of the ArticleService interface.
-It provides the actual logic for interacting with the article data.
+It provides the actual logic for interacting with the article data, which is stored in
+the article repository.
 */
-type ArticleServiceImpl struct{}
+type ArticleServiceImpl struct {
+	articles      repository.ArticleRepository
+	comments      repository.CommentRepository
+	users         repository.UserRepository
+	revisions     repository.RevisionRepository
+	reviews       repository.ReviewRepository
+	subscriptions repository.SubscriptionRepository
+	webmentions   repository.WebmentionRepository
+	events        EventPublisher
+	publications  PublicationNotifier
+	sanitizer     Sanitizer
+	shortcodes    ShortcodeExpander
+	ids           IDGenerator
+	clock         Clock
+}
 
 /*
 NewArticleService creates and returns a new instance of ArticleServiceImpl,
-which implements the ArticleService interface.
+which implements the ArticleService interface using the given article repository and
+publishing the events of the articles (e.g. their expiry) with the given publisher. The
+comment repository is used to count the comments of the articles it serves, the user
+repository to identify the users who authored them (see `models.Article.AuthorID`), the
+revision repository to record a revision of the articles each time they are created or
+updated, and the review repository to only publish the approved articles of the sites
+requiring the articles to be reviewed. The revisions, the review, the subscriptions to
+the comments and the webmentions of the articles are purged along with them. The
+notifier is notified of each article published for the first time (e.g. to send its
+webmentions). The HTML content of the articles is sanitized by the given sanitizer
+before being stored, and rendered for their readers by expanding its shortcodes with
+the given expander. The new articles are assigned their IDs by the given generator,
+and the articles are stamped with the time told by the given clock.
 */
-func NewArticleService() *ArticleServiceImpl {
-	return &ArticleServiceImpl{}
+func NewArticleService(
+	articles repository.ArticleRepository,
+	comments repository.CommentRepository,
+	users repository.UserRepository,
+	revisions repository.RevisionRepository,
+	reviews repository.ReviewRepository,
+	subscriptions repository.SubscriptionRepository,
+	webmentions repository.WebmentionRepository,
+	events EventPublisher,
+	publications PublicationNotifier,
+	sanitizer Sanitizer,
+	shortcodes ShortcodeExpander,
+	ids IDGenerator,
+	clock Clock,
+) *ArticleServiceImpl {
+	return &ArticleServiceImpl{
+		articles:      articles,
+		comments:      comments,
+		users:         users,
+		revisions:     revisions,
+		reviews:       reviews,
+		subscriptions: subscriptions,
+		webmentions:   webmentions,
+		events:        events,
+		publications:  publications,
+		sanitizer:     sanitizer,
+		shortcodes:    shortcodes,
+		ids:           ids,
+		clock:         clock,
+	}
 }
 
 /*
 GetAllArticles retrieves a list of all articles available in the system.
 
-This method simulates fetching a collection of articles by generating a new
-unique `articleID` and then returning a hardcoded list of articles. Each article
-includes details such as ID, title, author, and publication status.
-
-If there is an error generating the article ID (which should be rare), the function
-returns an empty article with an error indicating the failure.
+This method fetches every article of the site held by the context from the article
+repository. Each article includes details such as ID, title, author, and publication
+status.
 
 Returns:
   - A slice of `models.Article` representing the articles in the system.
-  - An error, if there is an issue generating the article ID.
+  - An error, if there is an issue fetching the articles.
 */
-func (as *ArticleServiceImpl) GetAllArticles() ([]models.Article, error) {
-	articleID, err := uuid.NewV7()
+func (as *ArticleServiceImpl) GetAllArticles(
+	ctx context.Context,
+) ([]models.Article, error) {
+	articles, err := as.articles.List(ctx, tenant.SiteID(ctx))
 	if err != nil {
-		return []models.Article{
-				{}},
-			fmt.Errorf(
-				"Unable to generate Article ID: %w\n",
-				err,
-			)
+		return []models.Article{}, fmt.Errorf("unable to fetch articles: %w", err)
 	}
 
-	articles := []models.Article{
-		{
-			ID:          articleID,
-			Title:       "Go Programming Basics",
-			Author:      "John Doe",
-			IsPublished: true,
-		},
-		{
-			ID:          articleID,
-			Title:       "Advanced Go Techniques",
-			Author:      "Jane Smith",
-			IsPublished: false,
-		},
-		{
-			ID:          articleID,
-			Title:       "Understanding Go Concurrency",
-			Author:      "Alice Johnson",
-			IsPublished: true,
-		},
+	if err := countComments(ctx, as.comments, articles); err != nil {
+		return []models.Article{}, err
 	}
+	allInSiteZone(ctx, articles)
 
 	return articles, nil
 }
 
+/*
+GetPublishedArticles retrieves the published articles of the site held by the context,
+i.e. the articles which can be served to anonymous readers.
+
+Returns:
+  - A slice of `models.Article` representing the published articles.
+  - An error, if there is an issue fetching the articles.
+*/
+func (as *ArticleServiceImpl) GetPublishedArticles(
+	ctx context.Context,
+) ([]models.Article, error) {
+	articles, err := as.GetAllArticles(ctx)
+	if err != nil {
+		return []models.Article{}, err
+	}
+
+	return slices.DeleteFunc(articles, func(a models.Article) bool {
+		return !a.IsPublished
+	}), nil
+}
+
 /*
 GetArticleByID retrieves a specific article by its unique ID.
 
-This method simulates fetching an article based on the provided `id` by generating
-a new article with hardcoded values. If there is an error generating the article ID,
-it returns an empty article with no additional information.
+This method fetches the article identified by `id` from the site held by the context.
+If no such article exists, `repository.ErrNotFound` is returned (wrapped).
 
 Returns:
   - A `models.Article` representing the requested article.
-  - An error, which is nil in this case as it always succeeds unless there is an issue
-    with ID generation.
+  - An error, if the article could not be found or fetched.
 */
-func (as *ArticleServiceImpl) GetArticleByID(id uuid.UUID) (models.Article, error) {
-	articleID, err := uuid.NewV7()
+func (as *ArticleServiceImpl) GetArticleByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Article, error) {
+	article, err := as.articles.Get(ctx, tenant.SiteID(ctx), id)
 	if err != nil {
-		return models.Article{}, nil
+		return models.Article{}, fmt.Errorf("unable to fetch article %s: %w", id, err)
 	}
 
-	article := models.Article{
-		ID:          articleID,
-		Title:       "Go Programming Basics",
-		Author:      "John Doe",
-		IsPublished: true,
+	comments, err := as.comments.ListByArticle(ctx, article.SiteID, id)
+	if err != nil {
+		return models.Article{}, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+	article.CommentCount = len(comments)
+
+	return inSiteZone(ctx, article), nil
+}
+
+/*
+GetArticleByShortID retrieves a specific article by its short ID.
+
+This method fetches the article with the given short ID from the site held by the
+context. If no such article exists, `repository.ErrNotFound` is returned (wrapped).
+*/
+func (as *ArticleServiceImpl) GetArticleByShortID(
+	ctx context.Context,
+	shortID string,
+) (models.Article, error) {
+	article, err := as.articles.GetByShortID(ctx, tenant.SiteID(ctx), shortID)
+	if err != nil {
+		return models.Article{}, fmt.Errorf(
+			"unable to fetch article %s: %w", shortID, err,
+		)
 	}
 
-	return article, nil
+	return as.GetArticleByID(ctx, article.ID)
 }
 
 /*
 CreateArticle creates a new article with the given title, author, and publication
 status.
 
-This method generates a unique article ID, then creates an article with the provided
-title, author, and publication status. If there is an error generating the article ID,
-it returns an empty article with no additional information.
+This method generates a unique article ID and a short ID, then creates an article with
+the provided title, author, and publication status in the site held by the context. If
+the site requires the articles to be reviewed, the article can not be published
+before it is approved (`ErrNotApproved`).
 
 Parameters:
   - title: The title of the article.
   - author: The author of the article.
   - isPublished: A boolean indicating whether the article is published or not.
+  - body: The slug, content, tags and publication date of the article.
 
 Returns:
   - A `models.Article` representing the newly created article.
-  - An error, which is nil in this case as the method always succeeds unless there is an
-    issue with ID generation.
+  - An error, if the article can not be published or there is an issue storing it.
 */
 func (as *ArticleServiceImpl) CreateArticle(
+	ctx context.Context,
 	title, author string,
 	isPublished bool,
+	body models.ArticleBody,
 ) (models.Article, error) {
-	articleID, err := uuid.NewV7()
-	if err != nil {
-		return models.Article{}, nil
-	}
+	articleID := as.ids.NewID()
+	now := as.clock.Now()
+	body = inUTC(body)
+	body.Content = as.sanitizer.Sanitize(body.Content)
 
 	article := models.Article{
 		ID:          articleID,
+		SiteID:      tenant.SiteID(ctx),
 		Title:       title,
 		Author:      author,
 		IsPublished: isPublished,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Version:     1,
+		ArticleBody: body,
+	}
+	article.RenderedHTML = as.shortcodes.Expand(body.Content)
+	stampPublication(&article, now)
+
+	authorID, err := as.authorID(ctx, author)
+	if err != nil {
+		return models.Article{}, err
+	}
+	article.AuthorID = authorID
+
+	if err := as.checkApproval(ctx, models.Article{}, article); err != nil {
+		return models.Article{}, err
+	}
+
+	article, err = createArticle(ctx, as.articles, article)
+	if err != nil {
+		return models.Article{}, fmt.Errorf("unable to create article: %w", err)
 	}
 
-	return article, nil
+	if err := recordRevision(ctx, as.revisions, as.ids, models.Article{}, article); err != nil {
+		return models.Article{}, err
+	}
+	as.notifyPublished(ctx, models.Article{}, article)
+
+	created := []models.Article{article}
+	if err := countComments(ctx, as.comments, created); err != nil {
+		return models.Article{}, err
+	}
+
+	return inSiteZone(ctx, created[0]), nil
 }
 
 /*
 UpdateArticle updates the details of an existing article based on the provided ID.
 
-This method generates a new unique article ID and updates the article with the given
-title, author, and publication status. If there is an error generating the new article
-ID, it returns an empty article with no additional information.
+This method updates the article of the site held by the context with the given title,
+author, and publication status, and increments its version. If no such article exists,
+`repository.ErrNotFound` is returned (wrapped), and `repository.ErrStale` if the
+article is not at the given version anymore (i.e. it was updated in the meantime). If
+the site requires the articles to be reviewed, the article can only be published at
+the version which was approved, unchanged (`ErrNotApproved`).
 
 Parameters:
   - id: The unique identifier of the article to be updated.
+  - version: The version of the article the update was made from.
   - title: The new title of the article.
   - author: The new author of the article.
   - isPublished: The new publication status of the article.
+  - body: The new slug, content, tags and publication date of the article. The
+    publication date is kept if none is given.
 
 Returns:
-  - A `models.Article` representing the updated article.
-  - An error, which is nil in this case as the method always succeeds unless there is
-    an issue with ID generation.
+  - A `models.Article` representing the updated article, along with the number of its
+    comments.
+  - An error, if the article could not be found or updated.
 */
 func (as *ArticleServiceImpl) UpdateArticle(
+	ctx context.Context,
 	id uuid.UUID,
+	version int,
 	title, author string,
 	isPublished bool,
+	body models.ArticleBody,
 ) (models.Article, error) {
-	articleID, err := uuid.NewV7()
+	article, err := as.articles.Get(ctx, tenant.SiteID(ctx), id)
 	if err != nil {
-		return models.Article{}, nil
+		return models.Article{}, fmt.Errorf("unable to fetch article %s: %w", id, err)
 	}
 
-	article := models.Article{
-		ID:          articleID,
-		Title:       title,
-		Author:      author,
-		IsPublished: isPublished,
+	if article.Version != version {
+		return models.Article{}, fmt.Errorf(
+			"unable to update article %s: %w", id, repository.ErrStale,
+		)
+	}
+
+	body = inUTC(body)
+	if body.PublishedAt == nil {
+		body.PublishedAt = article.PublishedAt
+	}
+	body.Content = as.sanitizer.Sanitize(body.Content)
+
+	// The user who authored the article is only identified again if its author changed,
+	// so that the article is kept by the user if they are renamed
+	if author != article.Author {
+		article.AuthorID, err = as.authorID(ctx, author)
+		if err != nil {
+			return models.Article{}, err
+		}
+	}
+
+	previous := article
+	article.Title = title
+	article.Author = author
+	article.IsPublished = isPublished
+	article.ArticleBody = body
+	article.RenderedHTML = as.shortcodes.Expand(body.Content)
+	article.UpdatedAt = as.clock.Now()
+	article.Version++
+	stampPublication(&article, article.UpdatedAt)
+
+	if err := as.checkApproval(ctx, previous, article); err != nil {
+		return models.Article{}, err
+	}
+
+	if err := as.articles.Update(ctx, article); err != nil {
+		return models.Article{}, fmt.Errorf("unable to update article %s: %w", id, err)
+	}
+
+	if err := recordRevision(ctx, as.revisions, as.ids, previous, article); err != nil {
+		return models.Article{}, err
 	}
+	as.notifyPublished(ctx, previous, article)
 
-	return article, nil
+	updated := []models.Article{article}
+	if err := countComments(ctx, as.comments, updated); err != nil {
+		return models.Article{}, err
+	}
+
+	return inSiteZone(ctx, updated[0]), nil
 }
 
 /*
-DeleteArticle removes an article from the system based on the provided article ID.
+SetPublished publishes (or unpublishes, if isPublished is false) the articles of the
+site held by the context identified by ids, in order.
+
+Each article is handled on its own: an article which can not be found (or, if the
+site requires the articles to be reviewed, which was not approved) does not prevent
+the others from being published. The outcome for each article is reported, in
+the order of ids. An error is only returned if the operation could not be attempted at
+all.
+*/
+func (as *ArticleServiceImpl) SetPublished(
+	ctx context.Context,
+	ids []uuid.UUID,
+	isPublished bool,
+) ([]models.BulkResult, error) {
+	siteID := tenant.SiteID(ctx)
+	results := make([]models.BulkResult, 0, len(ids))
+
+	for _, id := range ids {
+		result := models.BulkResult{ID: id}
 
-This method parses the provided article ID and attempts to delete the article. If the
-ID is invalid, an error is returned indicating the issue. Once the article is
-successfully deleted, a confirmation message is logged. The article's details are
-printed to the console.
+		article, err := as.articles.Get(ctx, siteID, id)
+		if err == nil {
+			previous := article
+			article.IsPublished = isPublished
+			article.UpdatedAt = as.clock.Now()
+			article.Version++
+			stampPublication(&article, article.UpdatedAt)
+			err = as.checkApproval(ctx, previous, article)
+			if err == nil {
+				err = as.articles.Update(ctx, article)
+			}
+			if err == nil {
+				err = recordRevision(ctx, as.revisions, as.ids, previous, article)
+			}
+			if err == nil {
+				as.notifyPublished(ctx, previous, article)
+			}
+		}
+
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			result.Error = "article not found"
+		case errors.Is(err, repository.ErrStale):
+			result.Error = "article updated in the meantime"
+		case errors.Is(err, ErrNotApproved):
+			result.Error = "article not approved"
+		case err != nil:
+			return nil, fmt.Errorf("unable to update article %s: %w", id, err)
+		default:
+			result.Success = true
+			localized := inSiteZone(ctx, article)
+			result.Article = &localized
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+/*
+DeleteArticle moves an article to the trash based on the provided article ID.
+
+This method moves the article identified by `id` of the site held by the context to
+the trash, from which it can be restored until it is purged. If no such article exists
+(out of the trash), `repository.ErrNotFound` is returned (wrapped).
 
 Parameters:
   - id: The unique identifier of the article to be deleted.
 
 Returns:
-  - An error if the article ID is invalid or if any other issues arise during the
-    deletion process; nil if the deletion succeeds.
+  - An error if the article could not be found or deleted; nil if the deletion
+    succeeds.
 */
-func (as *ArticleServiceImpl) DeleteArticle(id uuid.UUID) error {
-	articleID, err := uuid.Parse(id.String())
+func (as *ArticleServiceImpl) DeleteArticle(ctx context.Context, id uuid.UUID) error {
+	err := as.articles.Trash(ctx, tenant.SiteID(ctx), id, as.clock.Now())
+	if err != nil {
+		return fmt.Errorf("unable to delete article %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// GetTrashedArticles retrieves the articles in the trash of the site held by the
+// context.
+func (as *ArticleServiceImpl) GetTrashedArticles(
+	ctx context.Context,
+) ([]models.Article, error) {
+	articles, err := as.articles.ListTrashed(ctx, tenant.SiteID(ctx))
 	if err != nil {
-		return fmt.Errorf("Invalid Article ID: %w\n", err)
+		return []models.Article{}, fmt.Errorf("unable to fetch trash: %w", err)
+	}
+	allInSiteZone(ctx, articles)
+
+	return articles, nil
+}
+
+// RestoreArticle restores an article from the trash of the site held by the context,
+// wrapping `repository.ErrNotFound` if no such article is in the trash.
+func (as *ArticleServiceImpl) RestoreArticle(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Article, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if err := as.articles.Restore(ctx, siteID, id); err != nil {
+		return models.Article{}, fmt.Errorf("unable to restore article %s: %w", id, err)
+	}
+
+	article, err := as.articles.Get(ctx, siteID, id)
+	if err != nil {
+		return models.Article{}, fmt.Errorf("unable to fetch article %s: %w", id, err)
+	}
+
+	return inSiteZone(ctx, article), nil
+}
+
+// PurgeArticle removes an article from the trash of the site held by the context for
+// good, wrapping `repository.ErrNotFound` if no such article is in the trash.
+func (as *ArticleServiceImpl) PurgeArticle(ctx context.Context, id uuid.UUID) error {
+	siteID := tenant.SiteID(ctx)
+
+	trashed, err := as.articles.ListTrashed(ctx, siteID)
+	if err != nil {
+		return fmt.Errorf("unable to fetch trash: %w", err)
+	}
+
+	found := slices.ContainsFunc(trashed, func(a models.Article) bool {
+		return a.ID == id
+	})
+	if !found {
+		return fmt.Errorf("unable to purge article %s: %w", id, repository.ErrNotFound)
+	}
+
+	if err := as.articles.Delete(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to purge article %s: %w", id, err)
 	}
 
-	article := models.Article{ID: articleID}
+	return as.purgeRelated(ctx, siteID, id)
+}
+
+// purgeRelated removes the resources related to the purged article of the site: its
+// revisions, its review, the subscriptions to its comments and its webmentions.
+func (as *ArticleServiceImpl) purgeRelated(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	if err := as.revisions.DeleteByArticle(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to purge revisions of article %s: %w", id, err)
+	}
+
+	if err := as.reviews.DeleteByArticle(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to purge review of article %s: %w", id, err)
+	}
+
+	if err := as.subscriptions.DeleteByArticle(ctx, siteID, id); err != nil {
+		return fmt.Errorf(
+			"unable to purge subscriptions to article %s: %w", id, err,
+		)
+	}
+
+	if err := as.webmentions.DeleteByArticle(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to purge webmentions of article %s: %w", id, err)
+	}
 
-	fmt.Printf("%v article is deleted!\n", article)
 	return nil
 }
+
+/*
+PurgeTrash removes the articles of every site which were moved to the trash before
+the given time for good, returning how many articles were purged.
+
+It is not scoped to any site: it is meant to be run periodically in the background to
+enforce the retention period of the trash.
+*/
+func (as *ArticleServiceImpl) PurgeTrash(
+	ctx context.Context,
+	before time.Time,
+) (int, error) {
+	articles, err := as.articles.ListTrashedBefore(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch trash: %w", err)
+	}
+
+	purged := 0
+	for _, article := range articles {
+		err := as.articles.Delete(ctx, article.SiteID, article.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Purged in the meantime
+			continue
+		} else if err != nil {
+			return purged, fmt.Errorf(
+				"unable to purge article %s: %w", article.ID, err,
+			)
+		}
+
+		if err := as.purgeRelated(ctx, article.SiteID, article.ID); err != nil {
+			return purged, err
+		}
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+/*
+UnpublishExpired unpublishes the published articles of every site which expired at
+the given time, returning how many articles were unpublished.
+
+An `article.expired` event holding the article is published to the site of each
+unpublished article. It is not scoped to any site: it is meant to be run periodically
+in the background to enforce the expiry dates of the articles.
+*/
+func (as *ArticleServiceImpl) UnpublishExpired(
+	ctx context.Context,
+	at time.Time,
+) (int, error) {
+	articles, err := as.articles.ListExpired(ctx, at)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch expired articles: %w", err)
+	}
+
+	unpublished := 0
+	for _, article := range articles {
+		previous := article
+		article.IsPublished = false
+		article.UpdatedAt = as.clock.Now()
+		article.Version++
+
+		err := as.articles.Update(ctx, article)
+		if errors.Is(err, repository.ErrNotFound) ||
+			errors.Is(err, repository.ErrStale) {
+			// Purged or updated in the meantime, in which case it is unpublished by the
+			// next run if it is still expired
+			continue
+		} else if err != nil {
+			return unpublished, fmt.Errorf(
+				"unable to unpublish article %s: %w", article.ID, err,
+			)
+		}
+
+		if err := recordRevision(ctx, as.revisions, as.ids, previous, article); err != nil {
+			return unpublished, err
+		}
+
+		as.events.Publish(article.SiteID, "article.expired", article)
+		unpublished++
+	}
+
+	return unpublished, nil
+}
+
+/*
+PublishScheduled publishes the unpublished articles of the site held by the context
+whose publication was scheduled at or before the given time (see
+`models.ArticleBody.PublishAt`), returning how many articles were published.
+
+An `article.published` event holding the article is published to the site for each
+published article. If the site requires the articles to be reviewed, the articles which
+were not approved are left unpublished, until they are. It is meant to be run
+periodically in the background for every site.
+*/
+func (as *ArticleServiceImpl) PublishScheduled(
+	ctx context.Context,
+	at time.Time,
+) (int, error) {
+	siteID := tenant.SiteID(ctx)
+
+	articles, err := as.articles.ListScheduled(ctx, siteID, at)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch scheduled articles: %w", err)
+	}
+
+	published := 0
+	for _, article := range articles {
+		previous := article
+		article.IsPublished = true
+		article.UpdatedAt = as.clock.Now()
+		article.Version++
+		stampPublication(&article, article.UpdatedAt)
+
+		err := as.checkApproval(ctx, previous, article)
+		if err == nil {
+			err = as.articles.Update(ctx, article)
+		}
+
+		if errors.Is(err, ErrNotApproved) ||
+			errors.Is(err, repository.ErrNotFound) ||
+			errors.Is(err, repository.ErrStale) {
+			// Not approved yet, or purged or updated in the meantime, in which case it
+			// is published by the next run if it is still scheduled
+			continue
+		} else if err != nil {
+			return published, fmt.Errorf(
+				"unable to publish article %s: %w", article.ID, err,
+			)
+		}
+
+		if err := recordRevision(ctx, as.revisions, as.ids, previous, article); err != nil {
+			return published, err
+		}
+		as.notifyPublished(ctx, previous, article)
+		published++
+	}
+
+	return published, nil
+}
+
+/*
+checkApproval returns `ErrNotApproved` if the article is published from its previous
+version (the zero value when the article is created) without approval, while the site
+held by the context requires the articles to be reviewed. The previous version has to
+be the approved one, and nothing but the publication of the article may change since.
+*/
+func (as *ArticleServiceImpl) checkApproval(
+	ctx context.Context,
+	previous, article models.Article,
+) error {
+	site, _ := tenant.FromContext(ctx)
+	if !site.EffectiveSettings().RequireReview || !article.IsPublished ||
+		previous.IsPublished {
+		return nil
+	}
+
+	unpublished := article
+	unpublished.IsPublished = previous.IsPublished
+	unpublished.PublishedAt = previous.PublishedAt
+	unpublished.PublishAt = previous.PublishAt
+	if len(articleChanges(previous, unpublished)) > 0 {
+		return ErrNotApproved
+	}
+
+	review, err := as.reviews.GetByArticle(ctx, previous.SiteID, previous.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotApproved
+	} else if err != nil {
+		return fmt.Errorf("unable to fetch review of article %s: %w", previous.ID, err)
+	}
+
+	if review.Status != models.ReviewApproved ||
+		review.ApprovedVersion != previous.Version {
+		return ErrNotApproved
+	}
+
+	return nil
+}
+
+/*
+recordRevision records the revision of the article made from its previous version
+(the zero value when the article was created), holding the changes of its fields and
+identified by the given generator.
+*/
+func recordRevision(
+	ctx context.Context,
+	revisions repository.RevisionRepository,
+	ids IDGenerator,
+	previous, article models.Article,
+) error {
+	revision := models.Revision{
+		ID:        ids.NewID(),
+		SiteID:    article.SiteID,
+		ArticleID: article.ID,
+		Number:    article.Version,
+		CreatedAt: article.UpdatedAt,
+		Changes:   articleChanges(previous, article),
+		Article:   article,
+	}
+
+	if err := revisions.Create(ctx, revision); err != nil {
+		return fmt.Errorf(
+			"unable to record revision %d of article %s: %w",
+			revision.Number,
+			article.ID,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// shortIDAlphabet is the (base62) alphabet of the short IDs of the articles.
+const shortIDAlphabet = "0123456789" +
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"abcdefghijklmnopqrstuvwxyz"
+
+// shortIDLength is the number of characters of the short IDs of the articles, which
+// makes for 62^8 (about 2*10^14) short IDs per site.
+const shortIDLength = 8
+
+// shortIDAttempts is the number of short IDs tried for a new article before giving up,
+// should they be taken already.
+const shortIDAttempts = 3
+
+// newShortID generates a new random short ID.
+func newShortID() string {
+	id := make([]byte, 0, shortIDLength)
+	buf := make([]byte, shortIDLength)
+
+	for len(id) < shortIDLength {
+		_, _ = rand.Read(buf)
+		for _, b := range buf {
+			// The bytes above the last multiple of the size of the alphabet are
+			// discarded, so that every character is as likely
+			if int(b) < 256-256%len(shortIDAlphabet) && len(id) < shortIDLength {
+				id = append(id, shortIDAlphabet[int(b)%len(shortIDAlphabet)])
+			}
+		}
+	}
+
+	return string(id)
+}
+
+/*
+authorID returns the ID of the user of the site held by the context named after the
+author: the user of the request if they are, or else the only user of the site who is,
+if any. The articles of the authors who are no (or an ambiguous) user are not
+attributed to any user, so that they are never exported as the data of another one.
+*/
+func (as *ArticleServiceImpl) authorID(
+	ctx context.Context,
+	author string,
+) (*uuid.UUID, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if principal, ok := auth.FromContext(ctx); ok && principal.UserID != uuid.Nil {
+		user, err := as.users.Get(ctx, siteID, principal.UserID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("unable to fetch user %s: %w", principal.UserID, err)
+		} else if err == nil && user.Name == author {
+			return &user.ID, nil
+		}
+	}
+
+	users, err := as.users.List(ctx, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	var id *uuid.UUID
+	for _, user := range users {
+		if user.Name != author {
+			continue
+		} else if id != nil {
+			return nil, nil
+		}
+
+		id = &user.ID
+	}
+
+	return id, nil
+}
+
+// createArticle stores the new article with a new short ID, which is generated again
+// in the unlikely case it is already taken.
+func createArticle(
+	ctx context.Context,
+	articles repository.ArticleRepository,
+	article models.Article,
+) (models.Article, error) {
+	for attempt := 1; ; attempt++ {
+		article.ShortID = newShortID()
+
+		err := articles.Create(ctx, article)
+		if errors.Is(err, repository.ErrConflict) && attempt < shortIDAttempts {
+			continue
+		} else if err != nil {
+			return models.Article{}, err
+		}
+
+		return article, nil
+	}
+}
+
+// notifyPublished publishes the "article.published" event of the article if it is
+// published from its previous version, and notifies the publication notifier of the
+// article if it is published for the first time, i.e. was never published before.
+func (as *ArticleServiceImpl) notifyPublished(
+	ctx context.Context,
+	previous, article models.Article,
+) {
+	if !article.IsPublished || previous.IsPublished {
+		return
+	}
+
+	as.events.Publish(article.SiteID, "article.published", article)
+	if previous.PublishedAt == nil {
+		as.publications.NotifyPublished(ctx, article)
+	}
+}
+
+// stampPublication records when the article is first published, at the given time,
+// and clears its scheduled publication once it is published.
+func stampPublication(article *models.Article, now time.Time) {
+	if article.IsPublished && article.PublishedAt == nil {
+		article.PublishedAt = &now
+	}
+
+	if article.IsPublished {
+		article.PublishAt = nil
+	}
+}
+
+/*
+GetTags retrieves the tags of the published articles of the site held by the context,
+along with the number of articles tagged with each of them and how many of those were
+published within the trending window (i.e. over the last window of time).
+
+The tags are sorted by decreasing count, then by name.
+*/
+func (as *ArticleServiceImpl) GetTags(
+	ctx context.Context,
+	window time.Duration,
+) ([]models.Tag, error) {
+	since := as.clock.Now().Add(-window)
+
+	tags, err := as.articles.CountTags(ctx, tenant.SiteID(ctx), since)
+	if err != nil {
+		return []models.Tag{}, fmt.Errorf("unable to count tags: %w", err)
+	}
+
+	slices.SortFunc(tags, func(a, b models.Tag) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return tags, nil
+}
+
+// GetArchives retrieves the months (in the time zone of the site held by the context)
+// articles of the site were published in, newest first, with the number of articles
+// published in each of them.
+func (as *ArticleServiceImpl) GetArchives(
+	ctx context.Context,
+) ([]models.ArchiveMonth, error) {
+	months, err := as.articles.CountByMonth(
+		ctx, tenant.SiteID(ctx), tenant.Location(ctx),
+	)
+	if err != nil {
+		return []models.ArchiveMonth{}, fmt.Errorf("unable to count articles: %w", err)
+	}
+
+	return months, nil
+}
+
+// GetArchive retrieves a page of the articles of the site held by the context which
+// were published in the month (in the time zone of the site), newest first, along with
+// the number of articles published in the month.
+func (as *ArticleServiceImpl) GetArchive(
+	ctx context.Context,
+	year int,
+	month time.Month,
+	page pagination.Page,
+) ([]models.Article, int, error) {
+	from := time.Date(year, month, 1, 0, 0, 0, 0, tenant.Location(ctx))
+
+	articles, total, err := as.articles.Query(
+		ctx,
+		tenant.SiteID(ctx),
+		repository.ArticleQuery{
+			PublishedOnly:   true,
+			PublishedFrom:   from,
+			PublishedBefore: from.AddDate(0, 1, 0),
+			Page:            page,
+		},
+	)
+	if err != nil {
+		return []models.Article{}, 0, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	if err := countComments(ctx, as.comments, articles); err != nil {
+		return []models.Article{}, 0, err
+	}
+	allInSiteZone(ctx, articles)
+
+	return articles, total, nil
+}
+
+// snippetLength is the length of the snippets of the content of the articles found by
+// a search, in characters.
+const snippetLength = 200
+
+// maxContentScore is the number of matches of the content of an article above which
+// the other matches do not make it more relevant.
+const maxContentScore = 10
+
+/*
+Search retrieves a page of the published articles of the site held by the context
+matching the search query, the most relevant first (the newest first among the equally
+relevant ones), along with the number of articles found and their facets, counted
+across every page.
+
+An article matches when each term of the text matches a word of its title, its
+content, its tags or its author (see the `search` package), and it is classified with
+the tag and written by the author of the query, if given.
+*/
+func (as *ArticleServiceImpl) Search(
+	ctx context.Context,
+	query models.SearchQuery,
+	page pagination.Page,
+) ([]models.SearchResult, int, models.SearchFacets, error) {
+	// Every published article is searched, newest first
+	articles, _, err := as.articles.Query(
+		ctx,
+		tenant.SiteID(ctx),
+		repository.ArticleQuery{
+			PublishedOnly: true,
+			Author:        query.Author,
+			Page:          pagination.Page{Number: 1, Size: math.MaxInt},
+		},
+	)
+	if err != nil {
+		return []models.SearchResult{}, 0, models.SearchFacets{},
+			fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	terms := search.Terms(query.Text)
+	results := []models.SearchResult{}
+	for _, article := range articles {
+		if query.Tag != "" && !slices.Contains(article.Tags, query.Tag) {
+			continue
+		}
+
+		if result, ok := matchArticle(article, terms); ok {
+			results = append(results, result)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b models.SearchResult) int {
+		return b.Score - a.Score
+	})
+
+	facets := countFacets(results)
+	total := len(results)
+	results = pagination.Slice(results, page)
+
+	found := make([]models.Article, len(results))
+	for i, result := range results {
+		found[i] = result.Article
+	}
+	if err := countComments(ctx, as.comments, found); err != nil {
+		return []models.SearchResult{}, 0, models.SearchFacets{}, err
+	}
+	allInSiteZone(ctx, found)
+	for i := range results {
+		results[i].Article = found[i]
+	}
+
+	return results, total, facets, nil
+}
+
+/*
+matchArticle returns the result of the search of the terms for the article, and
+reports whether each of the terms matches a word of its title, its content, its tags
+or its author.
+
+The matches of the title score 3 points each, the ones of the tags 2 points and the
+ones of the author and of the content (up to `maxContentScore` of them) 1 point.
+*/
+func matchArticle(
+	article models.Article,
+	terms []string,
+) (models.SearchResult, bool) {
+	content := search.PlainText(article.Content)
+	fields := append([]string{article.Title, article.Author, content}, article.Tags...)
+	for _, term := range terms {
+		if !slices.ContainsFunc(fields, func(field string) bool {
+			return search.Contains(field, term)
+		}) {
+			return models.SearchResult{}, false
+		}
+	}
+
+	result := models.SearchResult{
+		Article: article,
+		Matches: models.SearchMatches{
+			Title:  search.Find(article.Title, terms),
+			Author: search.Find(article.Author, terms),
+		},
+		Snippet: search.Snippet(content, terms, snippetLength),
+	}
+
+	tagMatches := 0
+	for _, tag := range article.Tags {
+		matches := search.Find(tag, terms)
+		if len(matches) == 0 {
+			continue
+		}
+
+		if result.Matches.Tags == nil {
+			result.Matches.Tags = make(map[string][]search.Match)
+		}
+		result.Matches.Tags[tag] = matches
+		tagMatches += len(matches)
+	}
+
+	result.Score = 3*len(result.Matches.Title) + 2*tagMatches +
+		len(result.Matches.Author) +
+		min(len(search.Find(content, terms)), maxContentScore)
+
+	return result, true
+}
+
+// countFacets counts the articles of the search results classified with each tag and
+// written by each author, by decreasing count, then by value.
+func countFacets(results []models.SearchResult) models.SearchFacets {
+	tags := make(map[string]int)
+	authors := make(map[string]int)
+	for _, result := range results {
+		for _, tag := range result.Article.Tags {
+			tags[tag]++
+		}
+		authors[result.Article.Author]++
+	}
+
+	return models.SearchFacets{Tags: facets(tags), Authors: facets(authors)}
+}
+
+// facets returns the facets of the counts of their values, by decreasing count, then
+// by value.
+func facets(counts map[string]int) []models.Facet {
+	facets := make([]models.Facet, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, models.Facet{Value: value, Count: count})
+	}
+
+	slices.SortFunc(facets, func(a, b models.Facet) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+
+		return strings.Compare(a.Value, b.Value)
+	})
+
+	return facets
+}
+
+/*
+countComments sets the number of comments made on each of the articles (of the site
+held by the context), counting the comments of the site in a single pass rather than
+once per article.
+
+There is no moderation of the comments: every stored comment is approved (the imports
+leave out the unapproved ones), so they are all counted.
+*/
+func countComments(
+	ctx context.Context,
+	comments repository.CommentRepository,
+	articles []models.Article,
+) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	counts, err := comments.CountByArticle(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return fmt.Errorf("unable to count comments: %w", err)
+	}
+
+	for i := range articles {
+		articles[i].CommentCount = counts[articles[i].ID]
+	}
+
+	return nil
+}
+
+// inUTC returns the body of an article with its dates in UTC, as they are stored.
+func inUTC(body models.ArticleBody) models.ArticleBody {
+	body.PublishedAt = inLocation(body.PublishedAt, time.UTC)
+	body.ExpiresAt = inLocation(body.ExpiresAt, time.UTC)
+	body.PublishAt = inLocation(body.PublishAt, time.UTC)
+
+	return body
+}
+
+// inSiteZone returns the article with the dates of its body (e.g. its scheduled
+// publication) in the time zone of the site held by the context, for the clients to
+// display them as they are.
+func inSiteZone(ctx context.Context, article models.Article) models.Article {
+	loc := tenant.Location(ctx)
+	article.PublishedAt = inLocation(article.PublishedAt, loc)
+	article.ExpiresAt = inLocation(article.ExpiresAt, loc)
+	article.PublishAt = inLocation(article.PublishAt, loc)
+
+	return article
+}
+
+// allInSiteZone sets the dates of the bodies of the articles in the time zone of the
+// site held by the context (see `inSiteZone`).
+func allInSiteZone(ctx context.Context, articles []models.Article) {
+	for i := range articles {
+		articles[i] = inSiteZone(ctx, articles[i])
+	}
+}
+
+// inLocation returns a copy of the time in the location, or nil if t is nil, so that
+// the times held by the repositories are never modified.
+func inLocation(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	in := t.In(loc)
+	return &in
+}
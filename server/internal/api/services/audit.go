@@ -0,0 +1,79 @@
+/*
+Package services provides operations for recording and reporting the audit log of the
+management API.
+
+The primary interface, `AuditService`, defines methods to record the write requests
+made to the management API and to report them per site. The `AuditServiceImpl` struct
+provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// AuditService defines the methods for recording and reporting the audit log.
+type AuditService interface {
+	// RecordAudit appends the entry to the audit log of the site it references.
+	RecordAudit(ctx context.Context, entry models.AuditEntry) error
+
+	// GetAuditLog retrieves the audit log of the site.
+	GetAuditLog(ctx context.Context) ([]models.AuditEntry, error)
+}
+
+// AuditServiceImpl implements the AuditService interface.
+type AuditServiceImpl struct {
+	audit repository.AuditRepository
+	ids   IDGenerator
+	clock Clock
+}
+
+// NewAuditService creates and returns a new instance of AuditServiceImpl backed by the
+// given audit repository, stamping the entries with the time told by the given clock.
+func NewAuditService(
+	audit repository.AuditRepository,
+	ids IDGenerator,
+	clock Clock,
+) *AuditServiceImpl {
+	return &AuditServiceImpl{audit: audit, ids: ids, clock: clock}
+}
+
+/*
+RecordAudit appends the entry to the audit log of the site referenced by its `SiteID`
+field, assigning it a new unique identifier and stamping it with the current time
+unless it already is.
+*/
+func (as *AuditServiceImpl) RecordAudit(
+	ctx context.Context,
+	entry models.AuditEntry,
+) error {
+	entryID := as.ids.NewID()
+
+	entry.ID = entryID
+	if entry.At.IsZero() {
+		entry.At = as.clock.Now()
+	}
+
+	if err := as.audit.Create(ctx, entry); err != nil {
+		return fmt.Errorf("unable to record audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLog retrieves the audit log of the site held by the context, oldest first.
+func (as *AuditServiceImpl) GetAuditLog(
+	ctx context.Context,
+) ([]models.AuditEntry, error) {
+	entries, err := as.audit.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.AuditEntry{}, fmt.Errorf("unable to fetch audit log: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,367 @@
+/*
+Package services provides operations for backing up and restoring the content of the
+sites.
+
+The primary interface, `BackupService`, defines methods to take a snapshot of the
+content of a site and to restore a site from such a snapshot. The `BackupServiceImpl`
+struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrInvalidBackup is returned when a backup can not be restored, e.g. because of an
+// unsupported version or of a comment referencing an article missing from the backup.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// EventPublisher publishes the events of the sites, like `events.Broker` does.
+type EventPublisher interface {
+	Publish(siteID uuid.UUID, kind string, data any)
+}
+
+// BackupService defines the methods for backing up and restoring the sites.
+type BackupService interface {
+	// Backup takes a snapshot of the content of the site.
+	Backup(ctx context.Context) (models.Backup, error)
+
+	// Restore replaces the content of the site with the content of the backup.
+	Restore(ctx context.Context, backup models.Backup) error
+}
+
+// BackupServiceImpl is the concrete implementation of the BackupService interface.
+type BackupServiceImpl struct {
+	store  *repository.Store
+	events EventPublisher
+	ids    IDGenerator
+	clock  Clock
+}
+
+// NewBackupService creates and returns a new instance of BackupServiceImpl backing up
+// the resources of the given store, reporting its progress to events, identifying the
+// resources restored from another site with the given generator and stamping the
+// backups with the time told by the given clock.
+func NewBackupService(
+	store *repository.Store,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *BackupServiceImpl {
+	return &BackupServiceImpl{store: store, events: events, ids: ids, clock: clock}
+}
+
+/*
+Backup takes a snapshot of the content (users, articles and comments) of the site held
+by the context, along with the manifest of its media files. The personal data of the
+snapshot is encrypted as it is stored (see `repository.Store.Keyring`), so that the
+backup archives do not hold it in plain text.
+
+The site stores no media files yet, hence the media manifest is always empty. A
+`backup.completed` event is published once the snapshot is taken.
+*/
+func (bs *BackupServiceImpl) Backup(ctx context.Context) (models.Backup, error) {
+	site, _ := tenant.FromContext(ctx)
+
+	users, err := bs.store.Users.List(ctx, site.ID)
+	if err != nil {
+		return models.Backup{}, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	articles, err := bs.store.Articles.List(ctx, site.ID)
+	if err != nil {
+		return models.Backup{}, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	comments, err := bs.store.Comments.List(ctx, site.ID)
+	if err != nil {
+		return models.Backup{}, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+
+	if err := sealEmails(bs.store.Keyring, users, userEmail); err != nil {
+		return models.Backup{}, fmt.Errorf("unable to encrypt users: %w", err)
+	}
+	if err := sealEmails(bs.store.Keyring, comments, commentEmail); err != nil {
+		return models.Backup{}, fmt.Errorf("unable to encrypt comments: %w", err)
+	}
+
+	backup := models.Backup{
+		Manifest: models.BackupManifest{
+			Version:   models.BackupVersion,
+			CreatedAt: bs.clock.Now(),
+			SiteID:    site.ID,
+			SiteSlug:  site.Slug,
+		},
+		Users:    users,
+		Articles: articles,
+		Comments: comments,
+		Media:    []models.BackupMedia{},
+	}
+	backup.Manifest.Counts = backupCounts(backup)
+
+	bs.events.Publish(site.ID, "backup.completed", backup.Manifest)
+
+	return backup, nil
+}
+
+/*
+Restore replaces the content of the site held by the context with the content of the
+backup, which may have been taken from another site.
+
+The backup is validated before anything is modified: `ErrInvalidBackup` is returned
+(wrapped) if its version is not supported, if it holds duplicate resources or if a
+comment references an article missing from the backup, or if its personal data can
+not be decrypted (e.g. its key was retired). The current users, articles
+(trashed or not) and comments of the site are then replaced with the ones of the
+backup, which are given new identifiers if it was taken from another site. The content
+of the site is left as it was if the restore fails.
+
+The progress of the restore is reported with `restore.started`, `restore.progress`
+(once each type of resources is restored) and `restore.completed` or `restore.failed`
+events.
+*/
+func (bs *BackupServiceImpl) Restore(ctx context.Context, backup models.Backup) error {
+	siteID := tenant.SiteID(ctx)
+
+	if err := validateBackup(backup); err != nil {
+		return err
+	}
+
+	// Decrypt the personal data of the copies of the resources, which the repositories
+	// encrypt again as they store them
+	backup.Users = slices.Clone(backup.Users)
+	if err := openEmails(bs.store.Keyring, backup.Users, userEmail); err != nil {
+		return fmt.Errorf("%w: unable to decrypt users: %v", ErrInvalidBackup, err)
+	}
+	backup.Comments = slices.Clone(backup.Comments)
+	if err := openEmails(bs.store.Keyring, backup.Comments, commentEmail); err != nil {
+		return fmt.Errorf("%w: unable to decrypt comments: %v", ErrInvalidBackup, err)
+	}
+
+	counts := backupCounts(backup)
+	bs.events.Publish(siteID, "restore.started", map[string]any{"counts": counts})
+
+	if err := bs.restore(ctx, siteID, backup); err != nil {
+		bs.events.Publish(siteID, "restore.failed", map[string]any{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	bs.events.Publish(siteID, "restore.completed", map[string]any{"counts": counts})
+
+	return nil
+}
+
+/*
+restore replaces the content of the site with the content of the backup, whose
+resources are given new identifiers if it was taken from another site (so that they
+do not clash with the ones of that site, which may still exist).
+
+The resources are built first and swapped in last, one type after the other: if a type
+can not be swapped in, the types already swapped in are put back, so that a failed
+restore leaves the content of the site as it was.
+*/
+func (bs *BackupServiceImpl) restore(
+	ctx context.Context,
+	siteID uuid.UUID,
+	backup models.Backup,
+) error {
+	if backup.Manifest.SiteID != siteID {
+		backup = bs.reidentify(backup)
+	}
+
+	users := make([]models.User, 0, len(backup.Users))
+	for _, user := range backup.Users {
+		user.SiteID = siteID
+		users = append(users, user)
+	}
+
+	articles := make([]models.Article, 0, len(backup.Articles))
+	for _, article := range backup.Articles {
+		article.SiteID = siteID
+		// The backups made before the articles were versioned restore them at the
+		// first version
+		article.Version = max(article.Version, 1)
+		articles = append(articles, article)
+	}
+
+	comments := make([]models.Comment, 0, len(backup.Comments))
+	for _, comment := range backup.Comments {
+		comment.SiteID = siteID
+		comments = append(comments, comment)
+	}
+
+	// The previous content is put back even if the request was canceled meanwhile
+	undoCtx := context.WithoutCancel(ctx)
+	var undo []func() error
+	rollback := func(err error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				err = errors.Join(err, fmt.Errorf("unable to roll back: %w", undoErr))
+			}
+		}
+		return err
+	}
+
+	steps := []struct {
+		kind  string
+		total int
+		swap  func() (func() error, error)
+	}{
+		{"user", len(users), func() (func() error, error) {
+			return swap(ctx, undoCtx, bs.store.Users.Replace, siteID, users)
+		}},
+		{"article", len(articles), func() (func() error, error) {
+			return swap(ctx, undoCtx, bs.store.Articles.Replace, siteID, articles)
+		}},
+		{"comment", len(comments), func() (func() error, error) {
+			return swap(ctx, undoCtx, bs.store.Comments.Replace, siteID, comments)
+		}},
+	}
+
+	for _, step := range steps {
+		put, err := step.swap()
+		if err != nil {
+			return rollback(fmt.Errorf("unable to restore %ss: %w", step.kind, err))
+		}
+		undo = append(undo, put)
+
+		bs.events.Publish(siteID, "restore.progress", map[string]any{
+			"type":  step.kind,
+			"done":  step.total,
+			"total": step.total,
+		})
+	}
+
+	return nil
+}
+
+/*
+reidentify returns the backup with new identifiers for its users, articles and
+comments, the references between them (the authors of the articles and the articles of
+the comments) following their new identifiers. The authors which are not users of the
+backup are dropped, since they are users of another site.
+*/
+func (bs *BackupServiceImpl) reidentify(backup models.Backup) models.Backup {
+	newIDs := make(map[uuid.UUID]uuid.UUID)
+	newID := func(id uuid.UUID) uuid.UUID {
+		newIDs[id] = bs.ids.NewID()
+		return newIDs[id]
+	}
+
+	users := make([]models.User, 0, len(backup.Users))
+	for _, user := range backup.Users {
+		user.ID = newID(user.ID)
+		users = append(users, user)
+	}
+
+	articles := make([]models.Article, 0, len(backup.Articles))
+	for _, article := range backup.Articles {
+		article.ID = newID(article.ID)
+		if article.AuthorID != nil {
+			if authorID, ok := newIDs[*article.AuthorID]; ok {
+				article.AuthorID = &authorID
+			} else {
+				article.AuthorID = nil
+			}
+		}
+		articles = append(articles, article)
+	}
+
+	comments := make([]models.Comment, 0, len(backup.Comments))
+	for _, comment := range backup.Comments {
+		comment.ID = newID(comment.ID)
+		comment.ArticleID = newIDs[comment.ArticleID]
+		comments = append(comments, comment)
+	}
+
+	backup.Users, backup.Articles, backup.Comments = users, articles, comments
+
+	return backup
+}
+
+// swap replaces the resources of the site with the given replace function of their
+// repository, and returns the function putting back the resources it replaced (with
+// the undo context).
+func swap[T any](
+	ctx, undoCtx context.Context,
+	replace func(context.Context, uuid.UUID, []T) ([]T, error),
+	siteID uuid.UUID,
+	rows []T,
+) (func() error, error) {
+	previous, err := replace(ctx, siteID, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		_, err := replace(undoCtx, siteID, previous)
+		return err
+	}, nil
+}
+
+// validateBackup checks that the backup can be restored, wrapping `ErrInvalidBackup`
+// otherwise.
+func validateBackup(backup models.Backup) error {
+	if v := backup.Manifest.Version; v != models.BackupVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBackup, v)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	unique := func(id uuid.UUID) error {
+		if seen[id] {
+			return fmt.Errorf("%w: duplicate resource %s", ErrInvalidBackup, id)
+		}
+		seen[id] = true
+		return nil
+	}
+
+	for _, user := range backup.Users {
+		if err := unique(user.ID); err != nil {
+			return err
+		}
+	}
+
+	articles := make(map[uuid.UUID]bool, len(backup.Articles))
+	for _, article := range backup.Articles {
+		if err := unique(article.ID); err != nil {
+			return err
+		}
+		articles[article.ID] = true
+	}
+
+	for _, comment := range backup.Comments {
+		if err := unique(comment.ID); err != nil {
+			return err
+		}
+
+		if !articles[comment.ArticleID] {
+			return fmt.Errorf(
+				"%w: comment %s references unknown article %s",
+				ErrInvalidBackup, comment.ID, comment.ArticleID,
+			)
+		}
+	}
+
+	return nil
+}
+
+// backupCounts returns the number of resources of each type of the backup.
+func backupCounts(backup models.Backup) map[string]int {
+	return map[string]int{
+		"users":    len(backup.Users),
+		"articles": len(backup.Articles),
+		"comments": len(backup.Comments),
+		"media":    len(backup.Media),
+	}
+}
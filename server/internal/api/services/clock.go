@@ -0,0 +1,50 @@
+/*
+Package services provides the operations of the resources of the system, which are
+stamped with the current time told by a `Clock`.
+*/
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+// Clock tells the current time, which the services stamp the resources they create
+// and update with.
+type Clock = clock.Clock
+
+// SystemClock is the Clock telling the current time of the system, in UTC.
+type SystemClock = clock.System
+
+/*
+FixedClock is a Clock telling a fixed time, which only moves when advanced, so that the
+times the resources are stamped with are known in advance (e.g. by the tests asserting
+the exact responses of the handlers). It is safe for concurrent use.
+*/
+type FixedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixedClock creates and returns a new FixedClock telling the given time, in UTC.
+func NewFixedClock(now time.Time) *FixedClock {
+	return &FixedClock{now: now.UTC()}
+}
+
+// Now returns the time told by the clock.
+func (c *FixedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the time told by the clock forward by d.
+func (c *FixedClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
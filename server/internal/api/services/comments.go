@@ -12,25 +12,70 @@ Key Components:
   - CommentServiceImpl: A struct that implements the CommentService interface.
   - NewCommentService: A constructor function to create a new CommentServiceImpl
     instance.
-  - GetAllComments: Retrieves all comments for an article.
-  - GetCommentsFromComment: Retrieves comments associated with a specific comment.
+  - GetAllComments: Retrieves all comments of the site.
+  - GetCommentsFromArticle: Retrieves comments associated with a specific article.
   - AddCommentToArticle: Adds a new comment to an article.
-  - DeleteCommentFromArticle: Removes a comment from an article (currently
-    unimplemented).
+  - DeleteCommentFromArticle: Removes a comment from an article.
+  - GetMentions: Retrieves the mentions of the user of the request in the comments.
 
 The functionality is primarily focused on handling comment-related operations, which
-can be extended or modified based on the requirements of the application.
+can be extended or modified based on the requirements of the application. Every
+operation is scoped to the site (tenant) held by the context passed to it.
 */
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 
 	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/markdown"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
 )
 
+// ErrCommentsClosed is returned when a comment is added to an article of a site whose
+// comment policy is "closed".
+var ErrCommentsClosed = errors.New("comments are closed")
+
+// maxCommentLinks is the number of links above which an anonymous comment is spam.
+const maxCommentLinks = 2
+
+// maxMentions is the number of distinct users a comment can mention, the further
+// mentions being ignored.
+const maxMentions = 10
+
+// mentionPattern matches the mentions of the users in a comment, i.e. the slug of
+// their name prefixed with "@" (e.g. "@jane-doe"). A mention can not follow a letter,
+// a digit or a dot, so that the email addresses are not taken for mentions.
+var mentionPattern = regexp.MustCompile(
+	`(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}]+(?:-[\p{L}\p{N}]+)*)`,
+)
+
+// Sanitizer sanitizes the HTML of the user-generated content, like the policies of the
+// `sanitize` package do.
+type Sanitizer interface {
+	Sanitize(s string) string
+}
+
+// CommentNotifier notifies the subscribers of an article of a new comment on it, and
+// the users mentioned in the comment, like `SubscriptionService` does.
+type CommentNotifier interface {
+	NotifyComment(
+		ctx context.Context,
+		article models.Article,
+		comment models.Comment,
+		mentioned []models.User,
+	)
+}
+
 /*
 CommentService defines the methods for managing comments in the system.
 
@@ -41,133 +86,153 @@ provide the logic for each of the following methods:
 Methods:
 
 	GetAllComments(): Retrieves all the comments.
-	GetCommentsFromArticle(): Retrieves comments associated with a specific article.
-	AddComment(name, email, content string): Adds a new comment.
-	DeleteComment(): Deletes a comment.
+	GetCommentsFromArticle(articleID): Retrieves comments associated with an article.
+	AddCommentToArticle(articleID, name, email, content, ip): Adds a new comment.
+	DeleteCommentFromArticle(id): Deletes a comment.
+	GetMentions(): Retrieves the mentions of the user of the request.
 */
 type CommentService interface {
-	GetAllComments() ([]models.Comment, error)
-	GetCommentsFromArticle() ([]models.Comment, error)
-	AddCommentToArticle(name, email, content string) (*models.Comment, error)
-	DeleteCommentFromArticle() error
+	GetAllComments(ctx context.Context) ([]models.Comment, error)
+	GetCommentsFromArticle(
+		ctx context.Context,
+		articleID uuid.UUID,
+	) ([]models.Comment, error)
+	AddCommentToArticle(
+		ctx context.Context,
+		articleID uuid.UUID,
+		name, email, content, ip string,
+	) (*models.Comment, error)
+	DeleteCommentFromArticle(ctx context.Context, id uuid.UUID) error
+	GetMentions(ctx context.Context) ([]models.Mention, error)
 }
 
 /*
 CommentServiceImpl is a struct that implements the CommentService interface.
 
 This struct is used to manage operations related to comments, such as adding, deleting
-and retrieving comments. The struct itself does not contain any fields but serves as the
-concrete implementation for the methods defined in the CommentService interface.
+and retrieving comments. It stores the comments in the comment repository, looks up
+the commented articles in the article repository, records the users mentioned in the
+comments (looked up in the user repository) in the mention repository and the consents
+of the commenters in the consent repository, and notifies the subscribers of the
+articles and the mentioned users of the new comments with the notifier. The content of
+the comments is sanitized before being stored.
 */
-type CommentServiceImpl struct{}
+type CommentServiceImpl struct {
+	comments  repository.CommentRepository
+	articles  repository.ArticleRepository
+	users     repository.UserRepository
+	mentions  repository.MentionRepository
+	consents  repository.ConsentRepository
+	notifier  CommentNotifier
+	events    EventPublisher
+	sanitizer Sanitizer
+	ids       IDGenerator
+	clock     Clock
+}
 
 /*
 NewCommentService creates and returns a new instance of CommentServiceImpl.
 
-This function initializes a new CommentServiceImpl object and returns it as a pointer.
-It serves as a constructor for the CommentServiceImpl type.
+This function initializes a new CommentServiceImpl object backed by the given
+repositories and notifying the subscribers of the articles and the mentioned users of
+the new comments with the given notifier, and returns it as a pointer. The comments
+approved (i.e. stored, there being no moderation) and the ones rejected as spam are
+published as events with the given publisher. The HTML rendered from the Markdown of
+the new comments is sanitized by the given sanitizer. It serves as a constructor for the
+CommentServiceImpl type.
 
 Returns:
 
 	*CommentServiceImpl: A pointer to a newly created CommentServiceImpl instance.
 */
-func NewCommentService() *CommentServiceImpl {
-	return &CommentServiceImpl{}
+func NewCommentService(
+	comments repository.CommentRepository,
+	articles repository.ArticleRepository,
+	users repository.UserRepository,
+	mentions repository.MentionRepository,
+	consents repository.ConsentRepository,
+	notifier CommentNotifier,
+	events EventPublisher,
+	sanitizer Sanitizer,
+	ids IDGenerator,
+	clock Clock,
+) *CommentServiceImpl {
+	return &CommentServiceImpl{
+		comments:  comments,
+		articles:  articles,
+		users:     users,
+		mentions:  mentions,
+		consents:  consents,
+		notifier:  notifier,
+		events:    events,
+		sanitizer: sanitizer,
+		ids:       ids,
+		clock:     clock,
+	}
 }
 
 /*
-GetAllComments retrieves all the comments for an article.
-
-This function simulates the retrieval of all comments by generating a new comment ID
-using uuid.NewV7(). It creates a list of pre-defined comments with unique names, emails,
-and content. If there is an error while generating the comment ID, it returns an empty
-slice of comments and the error.
+GetAllComments retrieves all the comments of the site held by the context.
 
 Returns:
 
-	[]models.Comment: A slice of pre-defined comments.
-	error: An error if there was an issue generating the comment ID.
+	[]models.Comment: A slice of the comments.
+	error: An error if there was an issue fetching the comments.
 */
-func (cs *CommentServiceImpl) GetAllComments() ([]models.Comment, error) {
-	commentID, err := uuid.NewV7()
+func (cs *CommentServiceImpl) GetAllComments(
+	ctx context.Context,
+) ([]models.Comment, error) {
+	comments, err := cs.comments.List(ctx, tenant.SiteID(ctx))
 	if err != nil {
-		return []models.Comment{}, fmt.Errorf("%w", err)
-	}
-
-	comments := []models.Comment{
-		{
-			ID:      commentID,
-			Name:    "John Doe",
-			Email:   "john.doe@example.com",
-			Content: "This is a great article",
-		},
-		{
-			ID:      commentID,
-			Name:    "Jane Smith",
-			Email:   "jane.smith@example.com",
-			Content: "I found this article really helpful, thanks!",
-		},
-		{
-			ID:      commentID,
-			Name:    "Alice Johnson",
-			Email:   "alice.johnson@example.com",
-			Content: "Interesting perspective, I learned a lot!",
-		},
-		{
-			ID:      commentID,
-			Name:    "Somraj Saha",
-			Email:   "somraj.saha@weburz.com",
-			Content: "This is a test comment for experimental purposes ONLY!",
-		},
+		return []models.Comment{}, fmt.Errorf("unable to fetch comments: %w", err)
 	}
 
 	return comments, nil
 }
 
 /*
-GetCommentsFromComment retrieves a list of comments for a given article.
+GetCommentsFromArticle retrieves a list of comments for a given article.
+
+Parameters:
 
-This function simulates the retrieval of comments by generating a new comment ID using
-uuid.NewV7(). It creates a list of pre-defined comments with unique names, emails, and
-content. If there is an error while generating the comment ID, it returns an empty slice
-of comments and the error.
+	articleID (uuid.UUID): The unique identifier of the article.
 
 Returns:
 
-	[]models.Comment: A slice of pre-defined comments.
-	error: An error if there was an issue generating the comment ID.
+	[]models.Comment: A slice of the comments made on the article.
+	error: An error if the article does not exist within the site held by the context
+	    (wrapping `repository.ErrNotFound`) or the comments could not be fetched.
+
+The anonymous readers (i.e. whose context holds no principal) are only served the
+comments of the published articles, the other articles being reported as not found.
 */
-func (cs *CommentServiceImpl) GetCommentsFromArticle() ([]models.Comment, error) {
-	commentID, err := uuid.NewV7()
+func (cs *CommentServiceImpl) GetCommentsFromArticle(
+	ctx context.Context,
+	articleID uuid.UUID,
+) ([]models.Comment, error) {
+	siteID := tenant.SiteID(ctx)
+
+	article, err := cs.articles.Get(ctx, siteID, articleID)
+	if err != nil {
+		return []models.Comment{}, fmt.Errorf(
+			"unable to fetch article %s: %w",
+			articleID,
+			err,
+		)
+	}
+
+	// The anonymous readers are only served the published articles
+	if _, ok := auth.FromContext(ctx); !ok && !article.IsPublished {
+		return []models.Comment{}, fmt.Errorf(
+			"unable to fetch article %s: %w",
+			articleID,
+			repository.ErrNotFound,
+		)
+	}
+
+	comments, err := cs.comments.ListByArticle(ctx, siteID, articleID)
 	if err != nil {
-		return []models.Comment{}, fmt.Errorf("%w", err)
-	}
-
-	comments := []models.Comment{
-		{
-			ID:      commentID,
-			Name:    "John Doe",
-			Email:   "john.doe@example.com",
-			Content: "This is a great article",
-		},
-		{
-			ID:      commentID,
-			Name:    "Jane Smith",
-			Email:   "jane.smith@example.com",
-			Content: "I found this article really helpful, thanks!",
-		},
-		{
-			ID:      commentID,
-			Name:    "Alice Johnson",
-			Email:   "alice.johnson@example.com",
-			Content: "Interesting perspective, I learned a lot!",
-		},
-		{
-			ID:      commentID,
-			Name:    "Somraj Saha",
-			Email:   "somraj.saha@weburz.com",
-			Content: "This is a test comment for experimental purposes ONLY!",
-		},
+		return []models.Comment{}, fmt.Errorf("unable to fetch comments: %w", err)
 	}
 
 	return comments, nil
@@ -176,50 +241,248 @@ func (cs *CommentServiceImpl) GetCommentsFromArticle() ([]models.Comment, error)
 /*
 AddCommentToArticle adds a new comment to an article.
 
-This function generates a new unique comment ID using uuid.NewV7() and then  creates a
-new comment object with the provided name, email, and content. If there is an error
-while generating the comment ID, it returns an empty comment object and the error.
+This function generates a new unique comment ID with the ID generator of the service
+and then creates a new comment object with the provided name, email, and content on
+the given article, the Markdown content being stored as is and rendered to HTML
+stripped of the markup the sanitizer of the service does not allow (e.g. `<script>`
+tags).
+The users mentioned in the comment (e.g. "@jane-doe") are recorded, the mentions of the
+handles which are not the slug of any user of the site being ignored, and the
+subscribers of the article and the mentioned users are then notified of the comment.
+The consent of the commenter to the processing of their name and email address is
+recorded along with the version of the privacy policy of the site and their IP
+address (see `models.Consent`). The anonymous comments (i.e. whose context holds no
+principal) can only be made on the published articles, and are considered as spam
+(`ErrSpam`) if they hold too many links. A `comment.approved` event holding the comment
+is published once it is stored, and a `comment.rejected` event once it is rejected as
+spam.
 
 Parameters:
 
+	articleID (uuid.UUID): The unique identifier of the commented article.
 	name (string): The name of the commenter.
 	email (string): The email of the commenter.
 	content (string): The content of the comment.
+	ip (string): The IP address of the commenter.
 
 Returns:
 
 	*models.Comment: The newly created comment with the generated ID.
-	error: An error if there was an issue generating the comment ID.
+	error: An error if the comments of the site held by the context are closed
+	    (`ErrCommentsClosed`), if the article does not exist within the site (wrapping
+	    `repository.ErrNotFound`), if the anonymous comment is spam (wrapping
+	    `ErrSpam`) or the comment could not be created.
 */
 func (cs *CommentServiceImpl) AddCommentToArticle(
-	name, email, content string,
+	ctx context.Context,
+	articleID uuid.UUID,
+	name, email, content, ip string,
 ) (*models.Comment, error) {
-	commentID, err := uuid.NewV7()
+	siteID := tenant.SiteID(ctx)
+
+	site, _ := tenant.FromContext(ctx)
+	if site.EffectiveSettings().CommentPolicy == models.CommentsClosed {
+		return &models.Comment{}, ErrCommentsClosed
+	}
+
+	article, err := cs.articles.Get(ctx, siteID, articleID)
 	if err != nil {
-		return &models.Comment{}, fmt.Errorf("%w", err)
+		return &models.Comment{}, fmt.Errorf(
+			"unable to fetch article %s: %w",
+			articleID,
+			err,
+		)
+	}
+
+	// The anonymous commenters are only served the published articles
+	if _, ok := auth.FromContext(ctx); !ok {
+		if !article.IsPublished {
+			return &models.Comment{}, fmt.Errorf(
+				"unable to fetch article %s: %w",
+				articleID,
+				repository.ErrNotFound,
+			)
+		} else if len(linkPattern.FindAllString(content, -1)) > maxCommentLinks {
+			cs.events.Publish(siteID, "comment.rejected", map[string]any{
+				"article_id": articleID,
+				"reason":     "too many links",
+			})
+			return &models.Comment{}, fmt.Errorf("%w: too many links", ErrSpam)
+		}
 	}
 
+	commentID := cs.ids.NewID()
+	now := cs.clock.Now()
+
 	comment := &models.Comment{
-		ID:      commentID,
-		Name:    name,
-		Email:   email,
-		Content: content,
+		ID:           commentID,
+		SiteID:       siteID,
+		ArticleID:    articleID,
+		Name:         name,
+		Email:        email,
+		Content:      content,
+		RenderedHTML: renderComment(content, cs.sanitizer),
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
+	if err := cs.comments.Create(ctx, *comment); err != nil {
+		return &models.Comment{}, fmt.Errorf("unable to create comment: %w", err)
+	}
+
+	consent := models.Consent{
+		ID:            cs.ids.NewID(),
+		SiteID:        siteID,
+		Email:         email,
+		Purpose:       models.ConsentComments,
+		ResourceID:    commentID,
+		PolicyVersion: site.Settings.PrivacyPolicy,
+		IP:            ip,
+		GivenAt:       now,
+	}
+	if err := cs.consents.Create(ctx, consent); err != nil {
+		return &models.Comment{}, fmt.Errorf("unable to record consent: %w", err)
+	}
+
+	mentioned, err := cs.recordMentions(ctx, *comment)
+	if err != nil {
+		return &models.Comment{}, err
+	}
+
+	cs.notifier.NotifyComment(ctx, article, *comment, mentioned)
+	cs.events.Publish(siteID, "comment.approved", comment)
+
 	return comment, nil
 }
 
 /*
 DeleteCommentFromArticle removes a comment from an article.
 
-This function currently does not implement any logic for deleting a comment. It is
-intended to be used in the context of a comment service, where the deletion of a
-comment from an article would be handled in the future.
+Parameters:
+
+	id (uuid.UUID): The unique identifier of the comment.
 
 Returns:
 
-	nil: Always returns nil as there is no implementation yet.
+	error: An error if the comment does not exist within the site held by the context
+	    (wrapping `repository.ErrNotFound`) or could not be deleted.
 */
-func (cs *CommentServiceImpl) DeleteCommentFromArticle() error {
+func (cs *CommentServiceImpl) DeleteCommentFromArticle(
+	ctx context.Context,
+	id uuid.UUID,
+) error {
+	siteID := tenant.SiteID(ctx)
+
+	if err := cs.comments.Delete(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to delete comment %s: %w", id, err)
+	}
+
+	if err := cs.mentions.DeleteByComment(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to delete mentions of comment %s: %w", id, err)
+	}
+
 	return nil
 }
+
+/*
+GetMentions retrieves the mentions of the user of the request in the comments of the
+site held by the context, newest first.
+
+Returns:
+
+	[]models.Mention: A slice of the mentions of the user.
+	error: `ErrNoUser` if the API key of the request is not owned by any user, or an
+	    error if the mentions could not be fetched.
+*/
+func (cs *CommentServiceImpl) GetMentions(
+	ctx context.Context,
+) ([]models.Mention, error) {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return []models.Mention{}, err
+	}
+
+	mentions, err := cs.mentions.ListByUser(ctx, tenant.SiteID(ctx), userID)
+	if err != nil {
+		return []models.Mention{}, fmt.Errorf("unable to fetch mentions: %w", err)
+	}
+	slices.Reverse(mentions)
+
+	return mentions, nil
+}
+
+// recordMentions records the users of the site mentioned in the new comment, and
+// returns them in the order they are first mentioned.
+func (cs *CommentServiceImpl) recordMentions(
+	ctx context.Context,
+	comment models.Comment,
+) ([]models.User, error) {
+	handles := mentionedHandles(comment.Content)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	users, err := cs.users.List(ctx, comment.SiteID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	// The first user created wins when several users share a slug, like
+	// `UserService.GetUserBySlug` does
+	bySlug := make(map[string]models.User, len(users))
+	for _, user := range users {
+		slug := markdown.Slugify(user.Name)
+		if _, ok := bySlug[slug]; !ok {
+			bySlug[slug] = user
+		}
+	}
+
+	var mentioned []models.User
+	for _, handle := range handles {
+		user, ok := bySlug[handle]
+		if !ok {
+			continue
+		}
+
+		mention := models.Mention{
+			ID:        cs.ids.NewID(),
+			SiteID:    comment.SiteID,
+			CommentID: comment.ID,
+			ArticleID: comment.ArticleID,
+			UserID:    user.ID,
+			CreatedAt: comment.CreatedAt,
+		}
+		if err := cs.mentions.Create(ctx, mention); err != nil {
+			return nil, fmt.Errorf("unable to record mention: %w", err)
+		}
+
+		mentioned = append(mentioned, user)
+	}
+
+	return mentioned, nil
+}
+
+// renderComment renders the Markdown content of a comment to HTML, sanitized by the
+// sanitizer.
+func renderComment(content string, sanitizer Sanitizer) string {
+	return sanitizer.Sanitize(markdown.ToHTML(content))
+}
+
+// mentionedHandles returns the distinct handles mentioned in the content of a comment
+// (e.g. "jane-doe" for "@Jane-Doe"), lowercased, up to `maxMentions` of them.
+func mentionedHandles(content string) []string {
+	var handles []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		handle := strings.ToLower(match[1])
+		if slices.Contains(handles, handle) {
+			continue
+		}
+
+		handles = append(handles, handle)
+		if len(handles) == maxMentions {
+			break
+		}
+	}
+
+	return handles
+}
@@ -0,0 +1,129 @@
+/*
+Package services provides operations for the consents of the visitors to the
+processing of the personal data they submit to the sites.
+
+The primary interface, `ConsentService`, defines the methods used to look up the
+consents given with an email address and to withdraw them, which anonymizes the data
+they covered. The `ConsentServiceImpl` struct provides the concrete implementation of
+these methods.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ConsentService defines the methods for managing the consents of the visitors.
+type ConsentService interface {
+	// GetConsents retrieves the consents given with the email address.
+	GetConsents(ctx context.Context, email string) ([]models.Consent, error)
+
+	// WithdrawConsents withdraws the consents given with the email address and
+	// anonymizes the personal data they covered.
+	WithdrawConsents(
+		ctx context.Context,
+		email string,
+	) (models.ConsentWithdrawal, error)
+}
+
+// ConsentServiceImpl is the concrete implementation of the ConsentService interface.
+type ConsentServiceImpl struct {
+	consents repository.ConsentRepository
+	comments repository.CommentRepository
+	clock    Clock
+}
+
+// NewConsentService creates and returns a new instance of ConsentServiceImpl backed
+// by the given repositories.
+func NewConsentService(
+	consents repository.ConsentRepository,
+	comments repository.CommentRepository,
+	clock Clock,
+) *ConsentServiceImpl {
+	return &ConsentServiceImpl{consents: consents, comments: comments, clock: clock}
+}
+
+// GetConsents retrieves the consents given with the email address (whatever its case)
+// to the site held by the context, withdrawn or not.
+func (cs *ConsentServiceImpl) GetConsents(
+	ctx context.Context,
+	email string,
+) ([]models.Consent, error) {
+	consents, err := cs.consents.ListByEmail(ctx, tenant.SiteID(ctx), email)
+	if err != nil {
+		return []models.Consent{}, fmt.Errorf("unable to fetch consents: %w", err)
+	}
+
+	return consents, nil
+}
+
+/*
+WithdrawConsents withdraws the consents given with the email address (whatever its
+case) to the site held by the context, and anonymizes the comments made with it, like
+the comments of the deleted users are (see `UserServiceImpl.DeleteUser`).
+
+The consents are kept as the proof of the consents given before their withdrawal, but
+stripped of the IP address they were given from. Withdrawing the consents again
+anonymizes the comments made since.
+*/
+func (cs *ConsentServiceImpl) WithdrawConsents(
+	ctx context.Context,
+	email string,
+) (models.ConsentWithdrawal, error) {
+	siteID := tenant.SiteID(ctx)
+	now := cs.clock.Now()
+
+	consents, err := cs.consents.ListByEmail(ctx, siteID, email)
+	if err != nil {
+		return models.ConsentWithdrawal{}, fmt.Errorf(
+			"unable to fetch consents: %w", err,
+		)
+	}
+
+	var withdrawal models.ConsentWithdrawal
+	for _, consent := range consents {
+		if consent.WithdrawnAt != nil {
+			continue
+		}
+
+		consent.IP = ""
+		consent.WithdrawnAt = &now
+
+		if err := cs.consents.Update(ctx, consent); err != nil {
+			return withdrawal, fmt.Errorf(
+				"unable to withdraw consent %s: %w", consent.ID, err,
+			)
+		}
+		withdrawal.Consents++
+	}
+
+	comments, err := cs.comments.List(ctx, siteID)
+	if err != nil {
+		return withdrawal, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+
+	for _, comment := range comments {
+		if !strings.EqualFold(comment.Email, email) {
+			continue
+		}
+
+		comment.Name = AnonymousName
+		comment.Email = ""
+		comment.UpdatedAt = now
+
+		if err := cs.comments.Update(ctx, comment); err != nil {
+			return withdrawal, fmt.Errorf(
+				"unable to anonymize comment %s: %w", comment.ID, err,
+			)
+		}
+		withdrawal.Comments++
+	}
+
+	return withdrawal, nil
+}
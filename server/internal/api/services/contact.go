@@ -0,0 +1,130 @@
+/*
+Package services provides operations for forwarding the messages sent through the
+contact form of the sites.
+
+The primary interface, `ContactService`, defines the method used to forward a message
+to the administrators of a site. The `ContactServiceImpl` struct provides the concrete
+implementation of this method.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+var (
+	// ErrSpam is returned when a contact message (or an anonymous comment) is
+	// considered as spam.
+	ErrSpam = errors.New("message considered as spam")
+
+	// ErrNoRecipient is returned when a site has no administrator to forward its
+	// contact messages to.
+	ErrNoRecipient = errors.New("no recipient for contact messages")
+)
+
+// maxContactLinks is the number of links above which a contact message is spam.
+const maxContactLinks = 3
+
+// linkPattern matches the links of a contact message or of an anonymous comment.
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+// ContactService defines the methods for forwarding the contact messages of the sites.
+type ContactService interface {
+	// SendContactMessage forwards the contact message to the administrators of the
+	// site.
+	SendContactMessage(ctx context.Context, msg models.ContactMessage) error
+}
+
+// TemplateProvider provides the templates a site overrides the default templates of
+// the emails with, like `TemplateService` does.
+type TemplateProvider interface {
+	ActiveTemplates(ctx context.Context) (fs.FS, error)
+}
+
+// ContactServiceImpl is the concrete implementation of the ContactService interface.
+type ContactServiceImpl struct {
+	users     repository.UserRepository
+	mailer    mailer.Mailer
+	templates TemplateProvider
+}
+
+// NewContactService creates and returns a new instance of ContactServiceImpl sending
+// the messages to the users of the given repository with the given mailer, rendered
+// with the templates of the site.
+func NewContactService(
+	users repository.UserRepository,
+	mailer mailer.Mailer,
+	templates TemplateProvider,
+) *ContactServiceImpl {
+	return &ContactServiceImpl{users: users, mailer: mailer, templates: templates}
+}
+
+/*
+SendContactMessage forwards the contact message to the administrators of the site held
+by the context, with the visitor as the reply-to address.
+
+`ErrSpam` is returned (wrapped) if the honeypot field of the message is filled in or if
+the message holds too many links, and `ErrNoRecipient` if the site has no
+administrator.
+*/
+func (cs *ContactServiceImpl) SendContactMessage(
+	ctx context.Context,
+	msg models.ContactMessage,
+) error {
+	if msg.Website != "" {
+		return fmt.Errorf("%w: honeypot filled in", ErrSpam)
+	} else if len(linkPattern.FindAllString(msg.Message, -1)) > maxContactLinks {
+		return fmt.Errorf("%w: too many links", ErrSpam)
+	}
+
+	site, _ := tenant.FromContext(ctx)
+	admins, _, err := cs.users.Query(ctx, site.ID, repository.UserQuery{
+		Role: auth.RoleAdmin,
+		Page: pagination.Page{Number: 1, Size: pagination.MaxSize},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	var to []string
+	for _, admin := range admins {
+		to = append(to, admin.Email)
+	}
+
+	if len(to) == 0 {
+		return ErrNoRecipient
+	}
+
+	templates, err := cs.templates.ActiveTemplates(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch templates: %w", err)
+	}
+
+	email, err := mailer.RenderWith(templates, "contact", to, map[string]string{
+		"SiteName": site.Name,
+		"Name":     msg.Name,
+		"Email":    msg.Email,
+		"Message":  msg.Message,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to render contact message: %w", err)
+	}
+	email.ReplyTo = msg.Email
+
+	if err := cs.mailer.Send(ctx, email); err != nil {
+		return fmt.Errorf("unable to send contact message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Package services provides operations for summarizing the sites.
+
+The primary interface, `DashboardService`, defines the method used to summarize a site
+on the home screen of the administration interface. The `DashboardServiceImpl` struct
+provides the concrete implementation of this method.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+const (
+	// dashboardActivity is the number of audit entries reported by the dashboard.
+	dashboardActivity = 10
+
+	// dashboardTopArticles is the number of articles reported by the dashboard.
+	dashboardTopArticles = 5
+
+	// dashboardPeriod is the period the audience of the articles is reported for.
+	dashboardPeriod = 7 * 24 * time.Hour
+)
+
+// DashboardService defines the methods for summarizing the sites.
+type DashboardService interface {
+	// GetDashboard summarizes the site.
+	GetDashboard(ctx context.Context) (models.Dashboard, error)
+}
+
+// DashboardServiceImpl is the concrete implementation of the DashboardService
+// interface.
+type DashboardServiceImpl struct {
+	store     *repository.Store
+	analytics AnalyticsService
+}
+
+// NewDashboardService creates and returns a new instance of DashboardServiceImpl
+// summarizing the resources of the given store and their audience.
+func NewDashboardService(
+	store *repository.Store,
+	analytics AnalyticsService,
+) *DashboardServiceImpl {
+	return &DashboardServiceImpl{store: store, analytics: analytics}
+}
+
+/*
+GetDashboard summarizes the site held by the context: the number of its resources, its
+latest write requests (latest first) and its most viewed articles over the last 7
+days.
+*/
+func (ds *DashboardServiceImpl) GetDashboard(
+	ctx context.Context,
+) (models.Dashboard, error) {
+	siteID := tenant.SiteID(ctx)
+	var dashboard models.Dashboard
+
+	articles, err := ds.store.Articles.List(ctx, siteID)
+	if err != nil {
+		return models.Dashboard{}, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	for _, article := range articles {
+		if article.IsPublished {
+			dashboard.Counts.PublishedArticles++
+		} else {
+			dashboard.Counts.DraftArticles++
+		}
+	}
+
+	users, err := ds.store.Users.List(ctx, siteID)
+	if err != nil {
+		return models.Dashboard{}, fmt.Errorf("unable to fetch users: %w", err)
+	}
+	dashboard.Counts.Users = len(users)
+
+	comments, err := ds.store.Comments.List(ctx, siteID)
+	if err != nil {
+		return models.Dashboard{}, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+	dashboard.Counts.TotalComments = len(comments)
+
+	entries, err := ds.store.Audit.List(ctx, siteID)
+	if err != nil {
+		return models.Dashboard{}, fmt.Errorf("unable to fetch audit log: %w", err)
+	}
+
+	// The audit log is ordered oldest first
+	dashboard.RecentActivity = make([]models.AuditEntry, 0, dashboardActivity)
+	for _, entry := range slices.Backward(entries) {
+		if len(dashboard.RecentActivity) == dashboardActivity {
+			break
+		}
+
+		dashboard.RecentActivity = append(dashboard.RecentActivity, entry)
+	}
+
+	dashboard.TopArticles, err = ds.analytics.GetTopArticles(
+		ctx, dashboardPeriod, dashboardTopArticles,
+	)
+	if err != nil {
+		return models.Dashboard{}, err
+	}
+
+	return dashboard, nil
+}
@@ -0,0 +1,279 @@
+/*
+Package services provides operations for the digests of the comments emailed to the
+authors.
+
+The primary interface, `DigestService`, defines methods for the users to opt out of (or
+back into) the digest of the comments on their articles, and to send the digests of a
+site. The `DigestServiceImpl` struct provides the concrete implementation of these
+methods.
+
+A digest lists the comments made on the articles of an author since their last digest
+(the comments of the author themself excepted), grouped by article. It is sent by a
+scheduled job of the server (see `SendDigests`), and only to the authors who received
+new comments.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// DigestService defines the methods for the digests of the comments emailed to the
+// authors.
+type DigestService interface {
+	// SetDigestOptOut opts the user of the request out of the digest of the comments
+	// on their articles, or back into it.
+	SetDigestOptOut(ctx context.Context, optOut bool) (models.User, error)
+
+	// SendDigests emails the digest of the comments made on their articles since
+	// their last digest to the authors of the site, returning the number of digests
+	// sent.
+	SendDigests(ctx context.Context, since time.Time) (int, error)
+}
+
+// DigestServiceImpl is the concrete implementation of the DigestService interface.
+type DigestServiceImpl struct {
+	users     repository.UserRepository
+	articles  repository.ArticleRepository
+	comments  repository.CommentRepository
+	mailer    mailer.Mailer
+	templates TemplateProvider
+	clock     Clock
+}
+
+// NewDigestService creates and returns a new instance of DigestServiceImpl backed by
+// the given repositories, emailing the digests with the given mailer (rendering them
+// with the templates of the site) and stamping them with the time told by the given
+// clock.
+func NewDigestService(
+	users repository.UserRepository,
+	articles repository.ArticleRepository,
+	comments repository.CommentRepository,
+	mailer mailer.Mailer,
+	templates TemplateProvider,
+	clock Clock,
+) *DigestServiceImpl {
+	return &DigestServiceImpl{
+		users:     users,
+		articles:  articles,
+		comments:  comments,
+		mailer:    mailer,
+		templates: templates,
+		clock:     clock,
+	}
+}
+
+// digestArticle holds an article and the comments listed for it by a digest.
+type digestArticle struct {
+	ID       uuid.UUID
+	Title    string
+	URL      string
+	Comments []digestComment
+}
+
+// digestComment holds a comment listed by a digest.
+type digestComment struct {
+	Author  string
+	Content string
+}
+
+/*
+SetDigestOptOut opts the user of the request out of the digest of the comments on their
+articles, or back into it.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user, and
+`repository.ErrNotFound` (wrapped) if the user no longer exists.
+*/
+func (ds *DigestServiceImpl) SetDigestOptOut(
+	ctx context.Context,
+	optOut bool,
+) (models.User, error) {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := ds.users.Get(ctx, tenant.SiteID(ctx), userID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("unable to fetch user %s: %w", userID, err)
+	}
+
+	user.DigestOptOut = optOut
+	user.UpdatedAt = ds.clock.Now()
+
+	if err := ds.users.Update(ctx, user); err != nil {
+		return models.User{}, fmt.Errorf("unable to update user %s: %w", userID, err)
+	}
+
+	return user, nil
+}
+
+/*
+SendDigests emails the digest of the comments made on their articles to the authors of
+the site held by the context (i.e. the users named after the author of the articles),
+returning the number of digests sent.
+
+The digest of an author lists the comments made since their last digest, or since the
+given time if it is more recent (e.g. for the authors never sent any digest). The
+authors who opted out of the digest or who did not receive any new comment are not
+sent any. The other authors are still sent their digest if the digest of one of them
+can not be sent, and the first error is returned.
+*/
+func (ds *DigestServiceImpl) SendDigests(
+	ctx context.Context,
+	since time.Time,
+) (int, error) {
+	site, _ := tenant.FromContext(ctx)
+
+	// The comments made while the digests are sent are left for the next digests
+	now := ds.clock.Now()
+
+	users, err := ds.users.List(ctx, site.ID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	articles, err := ds.articles.List(ctx, site.ID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	comments, err := ds.comments.List(ctx, site.ID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+
+	// The default templates are used if the templates of the site can not be fetched
+	templates, err := ds.templates.ActiveTemplates(ctx)
+	if err != nil {
+		templates = nil
+	}
+
+	var sent int
+	var first error
+	for _, user := range users {
+		if user.DigestOptOut {
+			continue
+		}
+
+		after := since
+		if user.DigestSentAt != nil && user.DigestSentAt.After(after) {
+			after = *user.DigestSentAt
+		}
+
+		digest, count := ds.digestOf(site, user, articles, comments, after, now)
+		if count == 0 {
+			continue
+		}
+
+		err := ds.send(ctx, templates, site, user, digest, count, now)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+
+		sent++
+	}
+
+	return sent, first
+}
+
+// digestOf returns the articles of the user with the comments made on them after the
+// given time and until the other, and the number of those comments.
+func (ds *DigestServiceImpl) digestOf(
+	site models.Site,
+	user models.User,
+	articles []models.Article,
+	comments []models.Comment,
+	after, until time.Time,
+) ([]digestArticle, int) {
+	var digest []digestArticle
+	var count int
+
+	for _, comment := range comments {
+		if !comment.CreatedAt.After(after) || comment.CreatedAt.After(until) ||
+			strings.EqualFold(comment.Email, user.Email) {
+			continue
+		}
+
+		i := slices.IndexFunc(articles, func(a models.Article) bool {
+			return a.ID == comment.ArticleID
+		})
+		if i < 0 || !articles[i].AuthoredBy(user.ID) {
+			continue
+		}
+		article := articles[i]
+
+		j := slices.IndexFunc(digest, func(d digestArticle) bool {
+			return d.ID == article.ID
+		})
+		if j < 0 {
+			digest = append(digest, digestArticle{
+				ID:    article.ID,
+				Title: article.Title,
+				URL:   site.URL("/articles/" + article.ID.String()),
+			})
+			j = len(digest) - 1
+		}
+
+		digest[j].Comments = append(digest[j].Comments, digestComment{
+			Author:  comment.Name,
+			Content: comment.Content,
+		})
+		count++
+	}
+
+	return digest, count
+}
+
+// send emails the digest to the user, and records that it was sent at the given time.
+func (ds *DigestServiceImpl) send(
+	ctx context.Context,
+	templates fs.FS,
+	site models.Site,
+	user models.User,
+	digest []digestArticle,
+	count int,
+	now time.Time,
+) error {
+	email, err := mailer.RenderWith(
+		templates,
+		"comment_digest",
+		[]string{user.Email},
+		map[string]any{
+			"SiteName": site.Name,
+			"Name":     user.Name,
+			"Count":    count,
+			"Articles": digest,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to render digest of user %s: %w", user.ID, err)
+	}
+
+	if err := ds.mailer.Send(ctx, email); err != nil {
+		return fmt.Errorf("unable to send digest to user %s: %w", user.ID, err)
+	}
+
+	user.DigestSentAt = &now
+
+	if err := ds.users.Update(ctx, user); err != nil {
+		return fmt.Errorf("unable to update user %s: %w", user.ID, err)
+	}
+
+	return nil
+}
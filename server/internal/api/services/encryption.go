@@ -0,0 +1,116 @@
+/*
+Package services provides operations for the encryption at rest of the personal data
+of the sites.
+
+The primary interface, `EncryptionService`, defines a method to encrypt the personal
+data of a site again with the primary encryption key once the keys are rotated, so
+that the former keys can be retired. The `EncryptionServiceImpl` struct provides the
+concrete implementation of this method.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// EncryptionService defines the methods for the encryption of the personal data.
+type EncryptionService interface {
+	// RotateKeys encrypts the personal data of the site again with the primary key,
+	// reporting what was encrypted again.
+	RotateKeys(ctx context.Context) (models.KeyRotation, error)
+}
+
+// EncryptionServiceImpl is the concrete implementation of the EncryptionService
+// interface.
+type EncryptionServiceImpl struct {
+	users    repository.UserRepository
+	comments repository.CommentRepository
+	consents repository.ConsentRepository
+}
+
+// NewEncryptionService creates and returns a new instance of EncryptionServiceImpl
+// backed by the given repositories.
+func NewEncryptionService(
+	users repository.UserRepository,
+	comments repository.CommentRepository,
+	consents repository.ConsentRepository,
+) *EncryptionServiceImpl {
+	return &EncryptionServiceImpl{users: users, comments: comments, consents: consents}
+}
+
+/*
+RotateKeys encrypts the personal data of the site held by the context again with the
+primary key, the data stored in plain text or encrypted with a former key. The data
+already encrypted with the primary key is left alone, hence the rotation can be run
+again (e.g. once interrupted).
+*/
+func (es *EncryptionServiceImpl) RotateKeys(
+	ctx context.Context,
+) (models.KeyRotation, error) {
+	siteID := tenant.SiteID(ctx)
+
+	users, err := es.users.ReEncrypt(ctx, siteID)
+	if err != nil {
+		return models.KeyRotation{}, fmt.Errorf("unable to encrypt users: %w", err)
+	}
+
+	comments, err := es.comments.ReEncrypt(ctx, siteID)
+	if err != nil {
+		return models.KeyRotation{}, fmt.Errorf("unable to encrypt comments: %w", err)
+	}
+
+	consents, err := es.consents.ReEncrypt(ctx, siteID)
+	if err != nil {
+		return models.KeyRotation{}, fmt.Errorf("unable to encrypt consents: %w", err)
+	}
+
+	return models.KeyRotation{Users: users, Comments: comments, Consents: consents}, nil
+}
+
+// sealEmails encrypts the email addresses of the resources (given by the email
+// function) with the keyring, as the repositories store them, so that the backups and
+// the exports of the sites hold them encrypted too.
+func sealEmails[T any](
+	keyring *encryption.Keyring,
+	rows []T,
+	email func(*T) *string,
+) error {
+	for i := range rows {
+		sealed, err := keyring.Encrypt(*email(&rows[i]))
+		if err != nil {
+			return err
+		}
+		*email(&rows[i]) = sealed
+	}
+
+	return nil
+}
+
+// openEmails decrypts the email addresses of the resources (given by the email
+// function) encrypted by `sealEmails`.
+func openEmails[T any](
+	keyring *encryption.Keyring,
+	rows []T,
+	email func(*T) *string,
+) error {
+	for i := range rows {
+		plain, err := keyring.Decrypt(*email(&rows[i]))
+		if err != nil {
+			return err
+		}
+		*email(&rows[i]) = plain
+	}
+
+	return nil
+}
+
+// userEmail and commentEmail return the email address of a user and of a comment, for
+// `sealEmails` and `openEmails`.
+func userEmail(u *models.User) *string       { return &u.Email }
+func commentEmail(c *models.Comment) *string { return &c.Email }
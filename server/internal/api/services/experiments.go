@@ -0,0 +1,335 @@
+/*
+Package services provides operations for managing the A/B experiments of the sites.
+
+The primary interface, `ExperimentService`, defines methods to manage the experiments
+of a site (e.g. testing the headlines of an article), to bucket its visitors into their
+variants and to report the audience of the variants. The `ExperimentServiceImpl` struct
+provides the concrete implementation of these methods.
+
+The visitors are bucketed deterministically, from a hash of their identifier and of the
+experiment, so that a visitor is shown the same variant on every visit without the
+assignments being stored. The frontends record the variants they show through the
+analytics collector (see `models.PageView`), which the results are computed from.
+*/
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrUnknownVariant is returned when an exposure refers to an experiment or a variant
+// which does not exist.
+var ErrUnknownVariant = errors.New("unknown experiment variant")
+
+// ExperimentService defines the methods for managing the A/B experiments of the sites.
+type ExperimentService interface {
+	// GetAllExperiments retrieves every experiment of the site.
+	GetAllExperiments(ctx context.Context) ([]models.Experiment, error)
+
+	// GetExperimentByID fetches an experiment by its unique ID.
+	GetExperimentByID(ctx context.Context, id uuid.UUID) (models.Experiment, error)
+
+	// CreateExperiment creates a new experiment of the site.
+	CreateExperiment(
+		ctx context.Context,
+		experiment models.Experiment,
+	) (models.Experiment, error)
+
+	// UpdateExperiment updates the key, name, article, variants and state of an
+	// experiment.
+	UpdateExperiment(
+		ctx context.Context,
+		id uuid.UUID,
+		experiment models.Experiment,
+	) (models.Experiment, error)
+
+	// DeleteExperiment removes an experiment identified by its unique ID.
+	DeleteExperiment(ctx context.Context, id uuid.UUID) error
+
+	// GetAssignments buckets a visitor into the active experiments of the site.
+	GetAssignments(ctx context.Context, visitor string) ([]models.Assignment, error)
+
+	// GetResults reports the audience of the variants of an experiment.
+	GetResults(ctx context.Context, id uuid.UUID) ([]models.VariantResult, error)
+}
+
+// ExperimentServiceImpl is the concrete implementation of the ExperimentService
+// interface.
+type ExperimentServiceImpl struct {
+	experiments repository.ExperimentRepository
+	views       repository.AnalyticsRepository
+	ids         IDGenerator
+	clock       Clock
+}
+
+// NewExperimentService creates and returns a new instance of ExperimentServiceImpl
+// backed by the given experiment repository, reporting the results from the page
+// views of the given analytics repository.
+func NewExperimentService(
+	experiments repository.ExperimentRepository,
+	views repository.AnalyticsRepository,
+	ids IDGenerator,
+	clock Clock,
+) *ExperimentServiceImpl {
+	return &ExperimentServiceImpl{
+		experiments: experiments,
+		views:       views,
+		ids:         ids,
+		clock:       clock,
+	}
+}
+
+// GetAllExperiments retrieves every experiment of the site held by the context.
+func (es *ExperimentServiceImpl) GetAllExperiments(
+	ctx context.Context,
+) ([]models.Experiment, error) {
+	experiments, err := es.experiments.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.Experiment{}, fmt.Errorf(
+			"unable to fetch experiments: %w", err,
+		)
+	}
+
+	return experiments, nil
+}
+
+// GetExperimentByID fetches an experiment of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such experiment exists.
+func (es *ExperimentServiceImpl) GetExperimentByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Experiment, error) {
+	experiment, err := es.experiments.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Experiment{}, fmt.Errorf(
+			"unable to fetch experiment %s: %w", id, err,
+		)
+	}
+
+	return experiment, nil
+}
+
+/*
+CreateExperiment creates a new experiment in the site held by the context. The
+variants without a weight are given a weight of 1.
+
+`repository.ErrConflict` is returned (wrapped) if the key is already taken.
+*/
+func (es *ExperimentServiceImpl) CreateExperiment(
+	ctx context.Context,
+	experiment models.Experiment,
+) (models.Experiment, error) {
+	experimentID := es.ids.NewID()
+
+	experiment.ID = experimentID
+	experiment.SiteID = tenant.SiteID(ctx)
+	experiment.CreatedAt = es.clock.Now()
+	normalizeVariants(experiment.Variants)
+
+	if err := es.experiments.Create(ctx, experiment); err != nil {
+		return models.Experiment{}, fmt.Errorf("unable to create experiment: %w", err)
+	}
+
+	return experiment, nil
+}
+
+/*
+UpdateExperiment updates the key, name, article, variants and state of an existing
+experiment of the site held by the context.
+
+`repository.ErrNotFound` is returned (wrapped) if no such experiment exists, or
+`repository.ErrConflict` if the key is already taken by another experiment.
+*/
+func (es *ExperimentServiceImpl) UpdateExperiment(
+	ctx context.Context,
+	id uuid.UUID,
+	experiment models.Experiment,
+) (models.Experiment, error) {
+	existing, err := es.GetExperimentByID(ctx, id)
+	if err != nil {
+		return models.Experiment{}, err
+	}
+
+	experiment.ID = existing.ID
+	experiment.SiteID = existing.SiteID
+	experiment.CreatedAt = existing.CreatedAt
+	normalizeVariants(experiment.Variants)
+
+	if err := es.experiments.Update(ctx, experiment); err != nil {
+		return models.Experiment{}, fmt.Errorf(
+			"unable to update experiment %s: %w", id, err,
+		)
+	}
+
+	return experiment, nil
+}
+
+// DeleteExperiment removes an experiment of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such experiment exists.
+func (es *ExperimentServiceImpl) DeleteExperiment(
+	ctx context.Context,
+	id uuid.UUID,
+) error {
+	if err := es.experiments.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete experiment %s: %w", id, err)
+	}
+
+	return nil
+}
+
+/*
+GetAssignments buckets the visitor into every active experiment of the site held by
+the context, in proportion to the weights of their variants.
+
+The same visitor is always bucketed into the same variant of an experiment, as long as
+its variants and their weights do not change.
+*/
+func (es *ExperimentServiceImpl) GetAssignments(
+	ctx context.Context,
+	visitor string,
+) ([]models.Assignment, error) {
+	experiments, err := es.GetAllExperiments(ctx)
+	if err != nil {
+		return []models.Assignment{}, err
+	}
+
+	assignments := make([]models.Assignment, 0, len(experiments))
+	for _, experiment := range experiments {
+		if !experiment.Active {
+			continue
+		}
+
+		variant := bucket(experiment, visitor)
+		assignments = append(assignments, models.Assignment{
+			Experiment: experiment.Key,
+			ArticleID:  experiment.ArticleID,
+			Variant:    variant.Key,
+			Value:      variant.Value,
+		})
+	}
+
+	return assignments, nil
+}
+
+/*
+GetResults reports the audience of every variant of an experiment of the site held by
+the context, from the exposures recorded since the experiment was created, in the
+order of the variants.
+
+`repository.ErrNotFound` is returned (wrapped) if no such experiment exists.
+*/
+func (es *ExperimentServiceImpl) GetResults(
+	ctx context.Context,
+	id uuid.UUID,
+) ([]models.VariantResult, error) {
+	experiment, err := es.GetExperimentByID(ctx, id)
+	if err != nil {
+		return []models.VariantResult{}, err
+	}
+
+	views, err := es.views.ListSince(ctx, experiment.SiteID, experiment.CreatedAt)
+	if err != nil {
+		return []models.VariantResult{}, fmt.Errorf(
+			"unable to fetch page views: %w", err,
+		)
+	}
+
+	results := make([]models.VariantResult, len(experiment.Variants))
+	visitors := make([]map[string]bool, len(experiment.Variants))
+	for i, variant := range experiment.Variants {
+		results[i].Variant = variant.Key
+		visitors[i] = make(map[string]bool)
+	}
+
+	for _, view := range views {
+		if view.Experiment != experiment.Key {
+			continue
+		}
+
+		i := slices.IndexFunc(results, func(r models.VariantResult) bool {
+			return r.Variant == view.Variant
+		})
+		if i < 0 {
+			continue
+		}
+
+		results[i].Exposures++
+		visitors[i][view.Visitor] = true
+	}
+
+	for i := range results {
+		results[i].Visitors = len(visitors[i])
+	}
+
+	return results, nil
+}
+
+/*
+checkExposure returns an error wrapping `ErrUnknownVariant` unless the site held by
+the context has an experiment with the given key and variant, which a page view can
+then be an exposure to.
+*/
+func checkExposure(
+	ctx context.Context,
+	experiments repository.ExperimentRepository,
+	key, variant string,
+) error {
+	experiment, err := experiments.GetByKey(ctx, tenant.SiteID(ctx), key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: experiment %q", ErrUnknownVariant, key)
+	} else if err != nil {
+		return fmt.Errorf("unable to fetch experiment %q: %w", key, err)
+	}
+
+	if !slices.ContainsFunc(experiment.Variants, func(v models.Variant) bool {
+		return v.Key == variant
+	}) {
+		return fmt.Errorf("%w: variant %q of %q", ErrUnknownVariant, variant, key)
+	}
+
+	return nil
+}
+
+// bucket returns the variant of the experiment the visitor is bucketed into, picked
+// from a hash of the experiment and the visitor in proportion to the weights.
+func bucket(experiment models.Experiment, visitor string) models.Variant {
+	h := sha256.New()
+	h.Write(experiment.ID[:])
+	h.Write([]byte(visitor))
+	sum := binary.BigEndian.Uint64(h.Sum(nil))
+
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+
+	point := int(sum % uint64(total))
+	for _, variant := range experiment.Variants {
+		if point < variant.Weight {
+			return variant
+		}
+		point -= variant.Weight
+	}
+
+	return experiment.Variants[len(experiment.Variants)-1]
+}
+
+// normalizeVariants gives a weight of 1 to the variants without a weight.
+func normalizeVariants(variants []models.Variant) {
+	for i := range variants {
+		if variants[i].Weight == 0 {
+			variants[i].Weight = 1
+		}
+	}
+}
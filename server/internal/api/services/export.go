@@ -0,0 +1,200 @@
+/*
+Package services provides operations for exporting the content of the sites.
+
+The primary interface, `ExportService`, defines the method used to export every
+resource of a site in chunks, each of which ends with a cursor the next chunk resumes
+from. The `ExportServiceImpl` struct provides the concrete implementation of this
+method.
+*/
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrInvalidCursor is returned when the cursor an export resumes from is malformed.
+var ErrInvalidCursor = errors.New("invalid export cursor")
+
+// ExportService defines the methods for exporting the content of the sites.
+type ExportService interface {
+	// Export emits the records of the site following the cursor, up to limit records,
+	// and returns the cursor the next chunk resumes from (empty once done).
+	Export(
+		ctx context.Context,
+		cursor string,
+		limit int,
+		emit func(models.ExportRecord) error,
+	) (string, error)
+}
+
+// ExportServiceImpl is the concrete implementation of the ExportService interface.
+type ExportServiceImpl struct {
+	store *repository.Store
+}
+
+// NewExportService creates and returns a new instance of ExportServiceImpl exporting
+// the resources of the given store.
+func NewExportService(store *repository.Store) *ExportServiceImpl {
+	return &ExportServiceImpl{store: store}
+}
+
+// exportTypes lists the types of the exported resources, in the order of the export.
+var exportTypes = []string{"user", "article", "comment"}
+
+/*
+Export emits the records of the site held by the context, calling emit for each of
+them, and returns the cursor the next chunk of the export resumes from.
+
+The records are emitted by type (users, articles and then comments) and by unique
+identifier, which makes the order of the export stable across chunks. The export
+starts over if the cursor is empty and emits every remaining record if limit is zero
+(or less). The personal data of the records is encrypted as it is stored (see
+`repository.Store.Keyring`). The returned cursor is empty once every record is emitted. The export stops
+at the first error returned by emit.
+
+`ErrInvalidCursor` is returned (wrapped) if the cursor is malformed.
+*/
+func (es *ExportServiceImpl) Export(
+	ctx context.Context,
+	cursor string,
+	limit int,
+	emit func(models.ExportRecord) error,
+) (string, error) {
+	start, after, err := decodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	emitted := 0
+	for i := start; i < len(exportTypes); i++ {
+		records, err := es.records(ctx, exportTypes[i])
+		if err != nil {
+			return "", err
+		}
+
+		for _, record := range records {
+			if i == start && bytes.Compare(record.ID[:], after[:]) <= 0 {
+				continue
+			}
+
+			if limit > 0 && emitted == limit {
+				return encodeCursor(exportTypes[i], after), nil
+			}
+
+			if err := emit(record); err != nil {
+				return "", err
+			}
+
+			after = record.ID
+			emitted++
+		}
+
+		after = uuid.Nil
+	}
+
+	return "", nil
+}
+
+// records returns the records of the given type of the site held by the context,
+// sorted by unique identifier.
+func (es *ExportServiceImpl) records(
+	ctx context.Context,
+	kind string,
+) ([]models.ExportRecord, error) {
+	siteID := tenant.SiteID(ctx)
+
+	var records []models.ExportRecord
+	switch kind {
+	case "user":
+		users, err := es.store.Users.List(ctx, siteID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch users: %w", err)
+		}
+		if err := sealEmails(es.store.Keyring, users, userEmail); err != nil {
+			return nil, fmt.Errorf("unable to encrypt users: %w", err)
+		}
+
+		records = toRecords(kind, users, func(u models.User) uuid.UUID { return u.ID })
+	case "article":
+		articles, err := es.store.Articles.List(ctx, siteID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch articles: %w", err)
+		}
+
+		records = toRecords(kind, articles, func(a models.Article) uuid.UUID {
+			return a.ID
+		})
+	case "comment":
+		comments, err := es.store.Comments.List(ctx, siteID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch comments: %w", err)
+		}
+		if err := sealEmails(es.store.Keyring, comments, commentEmail); err != nil {
+			return nil, fmt.Errorf("unable to encrypt comments: %w", err)
+		}
+
+		records = toRecords(kind, comments, func(c models.Comment) uuid.UUID {
+			return c.ID
+		})
+	}
+
+	slices.SortFunc(records, func(a, b models.ExportRecord) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return records, nil
+}
+
+// toRecords wraps the resources of the given type in export records.
+func toRecords[T any](
+	kind string,
+	rows []T,
+	id func(T) uuid.UUID,
+) []models.ExportRecord {
+	records := make([]models.ExportRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, models.ExportRecord{Type: kind, ID: id(row), Data: row})
+	}
+
+	return records
+}
+
+// encodeCursor returns the opaque cursor resuming an export after the record of the
+// given type and unique identifier.
+func encodeCursor(kind string, after uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + after.String()))
+}
+
+// decodeCursor returns the index of the type and the unique identifier of the record
+// an export resumes after.
+func decodeCursor(cursor string) (int, uuid.UUID, error) {
+	if cursor == "" {
+		return 0, uuid.Nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("unable to decode cursor: %w", ErrInvalidCursor)
+	}
+
+	kind, id, _ := strings.Cut(string(raw), ":")
+	after, err := uuid.Parse(id)
+	i := slices.Index(exportTypes, kind)
+	if err != nil || i < 0 {
+		return 0, uuid.Nil, fmt.Errorf("unable to decode cursor: %w", ErrInvalidCursor)
+	}
+
+	return i, after, nil
+}
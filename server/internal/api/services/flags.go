@@ -0,0 +1,97 @@
+/*
+Package services provides operations for managing the feature flags of the sites.
+
+The primary interface, `FlagService`, defines methods to evaluate the feature flags of
+a site and to set or reset their value on the site. The `FlagServiceImpl` struct
+provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/Weburz/burzcontent/server/internal/flags"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// FlagService defines the methods for managing the feature flags of the sites.
+type FlagService interface {
+	// Enabled reports whether a feature flag is enabled for the request.
+	Enabled(ctx context.Context, name string) bool
+
+	// GetFlags evaluates every feature flag for the request.
+	GetFlags(ctx context.Context) []flags.State
+
+	// SetFlag sets the value of a feature flag on the site, or resets it if nil.
+	SetFlag(ctx context.Context, name string, enabled *bool) (flags.State, error)
+}
+
+// FlagServiceImpl is the concrete implementation of the FlagService interface.
+type FlagServiceImpl struct {
+	sites repository.SiteRepository
+	flags *flags.Set
+}
+
+// NewFlagService creates and returns a new instance of FlagServiceImpl backed by the
+// given site repository, evaluating the feature flags with the given set.
+func NewFlagService(sites repository.SiteRepository, set *flags.Set) *FlagServiceImpl {
+	return &FlagServiceImpl{sites: sites, flags: set}
+}
+
+// Enabled reports whether the named feature flag is enabled for the request the context
+// belongs to, on the site held by the context.
+func (fs *FlagServiceImpl) Enabled(ctx context.Context, name string) bool {
+	return fs.flags.Enabled(ctx, name)
+}
+
+// GetFlags evaluates every feature flag for the request the context belongs to, on
+// the site held by the context.
+func (fs *FlagServiceImpl) GetFlags(ctx context.Context) []flags.State {
+	return fs.flags.Evaluate(ctx)
+}
+
+/*
+SetFlag sets the value of the named feature flag on the site held by the context, or
+resets it (so that the flag takes the value of the configuration of the server) if
+enabled is nil, and returns the state of the flag for the request.
+
+An error wrapping `flags.ErrUnknownFlag` is returned if the flag is not defined.
+*/
+func (fs *FlagServiceImpl) SetFlag(
+	ctx context.Context,
+	name string,
+	enabled *bool,
+) (flags.State, error) {
+	flag, ok := flags.Lookup(name)
+	if !ok {
+		return flags.State{}, fmt.Errorf("%w: %q", flags.ErrUnknownFlag, name)
+	}
+
+	siteID := tenant.SiteID(ctx)
+	site, err := fs.sites.Get(ctx, siteID)
+	if err != nil {
+		return flags.State{}, fmt.Errorf("unable to fetch site %s: %w", siteID, err)
+	}
+
+	site.Flags = maps.Clone(site.Flags)
+	if enabled == nil {
+		delete(site.Flags, flag.Name)
+	} else {
+		if site.Flags == nil {
+			site.Flags = make(map[string]bool)
+		}
+		site.Flags[flag.Name] = *enabled
+	}
+
+	if err := fs.sites.Update(ctx, site); err != nil {
+		return flags.State{}, fmt.Errorf(
+			"unable to update feature flags of site %s: %w", siteID, err,
+		)
+	}
+
+	state, _ := fs.flags.State(tenant.NewContext(ctx, site), flag.Name)
+	return state, nil
+}
@@ -0,0 +1,91 @@
+/*
+Package services provides operations for the inbound webhooks of the sites.
+
+The primary interface, `HookService`, defines the method translating the events
+notified by the external services (e.g. GitHub or Stripe) into the internal actions of
+the server. The `HookServiceImpl` struct provides the concrete implementation of this
+method.
+*/
+package services
+
+import (
+	"context"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/hooks"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// JobTrigger runs the jobs of the server on demand, like `scheduler.Scheduler` does.
+type JobTrigger interface {
+	Trigger(name string) (bool, error)
+}
+
+// HookService defines the methods for handling the inbound webhooks of a site.
+type HookService interface {
+	// ReceiveHook runs the actions of the event notified by the verified hook.
+	ReceiveHook(ctx context.Context, hook models.Hook) (models.Hook, error)
+}
+
+// HookServiceImpl is the concrete implementation of the HookService interface.
+type HookServiceImpl struct {
+	actions hooks.Actions
+	jobs    JobTrigger
+	events  EventPublisher
+	ids     IDGenerator
+	clock   Clock
+}
+
+// NewHookService creates and returns a new instance of HookServiceImpl running the
+// given actions with jobs and publishing the received hooks to events.
+func NewHookService(
+	actions hooks.Actions,
+	jobs JobTrigger,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *HookServiceImpl {
+	return &HookServiceImpl{
+		actions: actions,
+		jobs:    jobs,
+		events:  events,
+		ids:     ids,
+		clock:   clock,
+	}
+}
+
+/*
+ReceiveHook runs the jobs the event notified to the site held by the context by the
+hook (whose signature is verified beforehand) translates into (see
+`hooks.ParseActions`), and returns the hook along with the outcome of each job.
+
+The jobs run in the background: a job which fails to start (e.g. because it is
+disabled) is reported as failed, but does not fail the hook. A `hook.received` event
+is published once the jobs are started.
+*/
+func (hs *HookServiceImpl) ReceiveHook(
+	ctx context.Context,
+	hook models.Hook,
+) (models.Hook, error) {
+	hook.ID = hs.ids.NewID()
+	hook.SiteID = tenant.SiteID(ctx)
+	hook.ReceivedAt = hs.clock.Now()
+	hook.Actions = []models.HookAction{}
+
+	for _, job := range hs.actions.Jobs(hook.Integration, hook.Event) {
+		action := models.HookAction{Job: job, Status: models.HookActionStarted}
+
+		started, err := hs.jobs.Trigger(job)
+		if err != nil {
+			action.Status, action.Error = models.HookActionFailed, err.Error()
+		} else if !started {
+			action.Status = models.HookActionSkipped
+		}
+
+		hook.Actions = append(hook.Actions, action)
+	}
+
+	hs.events.Publish(hook.SiteID, "hook.received", hook)
+
+	return hook, nil
+}
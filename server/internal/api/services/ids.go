@@ -0,0 +1,11 @@
+/*
+Package services provides the operations of the resources of the system, which are
+assigned their unique identifiers by an `IDGenerator`.
+*/
+package services
+
+import "github.com/Weburz/burzcontent/server/internal/ids"
+
+// IDGenerator generates the unique identifiers of the new resources, like `ids.UUIDv7`
+// and `ids.ULID` do.
+type IDGenerator = ids.Generator
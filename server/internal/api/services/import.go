@@ -0,0 +1,294 @@
+/*
+Package services provides operations for importing content from other systems.
+
+The primary interface, `ImportService`, defines methods to import the content exported
+by other content management systems, such as WordPress, into a site. The
+`ImportServiceImpl` struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+	"github.com/Weburz/burzcontent/server/internal/wxr"
+)
+
+// ImportService defines the methods for importing content into the sites.
+type ImportService interface {
+	// ImportWordPress imports the content of a WordPress export into the site and
+	// reports what was imported and what was skipped.
+	ImportWordPress(
+		ctx context.Context,
+		export *wxr.Export,
+		dryRun bool,
+	) (models.ImportReport, error)
+}
+
+// ImportServiceImpl is the concrete implementation of the ImportService interface.
+type ImportServiceImpl struct {
+	users            repository.UserRepository
+	articles         repository.ArticleRepository
+	comments         repository.CommentRepository
+	revisions        repository.RevisionRepository
+	articleSanitizer Sanitizer
+	commentSanitizer Sanitizer
+	shortcodes       ShortcodeExpander
+	ids              IDGenerator
+	clock            Clock
+}
+
+// NewImportService creates and returns a new instance of ImportServiceImpl storing the
+// imported content in the repositories of the given store, the HTML of the articles
+// and of the comments being sanitized by the given sanitizers, and the shortcodes of
+// the articles being expanded by the given expander.
+func NewImportService(
+	store *repository.Store,
+	articleSanitizer, commentSanitizer Sanitizer,
+	shortcodes ShortcodeExpander,
+	ids IDGenerator,
+	clock Clock,
+) *ImportServiceImpl {
+	return &ImportServiceImpl{
+		users:            store.Users,
+		articles:         store.Articles,
+		comments:         store.Comments,
+		revisions:        store.Revisions,
+		articleSanitizer: articleSanitizer,
+		commentSanitizer: commentSanitizer,
+		shortcodes:       shortcodes,
+		ids:              ids,
+		clock:            clock,
+	}
+}
+
+/*
+ImportWordPress imports the content of a WordPress export (WXR file) into the site
+held by the context.
+
+The WordPress resources are mapped as follows:
+  - Authors: Users with the author role, matched to the existing users by email
+    address.
+  - Posts: Articles, whose tags are the categories and the tags of the post, recorded
+    with their first revision. Only the published posts are published.
+  - Comments: Comments of the article of their post. The pending, spam and trashed
+    comments are skipped, as well as the pingbacks and trackbacks.
+
+The HTML of the posts and of the comments is sanitized like the HTML of the articles
+and of the comments created through the API.
+
+The pages, attachments and other item types have no counterpart and are skipped. The
+import is idempotent: the posts whose slug is already used by an article of the site
+are skipped along with their comments, so that an export can be imported again after
+a failure. Nothing is stored when dryRun is true, but the report is the same.
+*/
+func (is *ImportServiceImpl) ImportWordPress(
+	ctx context.Context,
+	export *wxr.Export,
+	dryRun bool,
+) (models.ImportReport, error) {
+	siteID := tenant.SiteID(ctx)
+	now := is.clock.Now()
+	report := models.ImportReport{DryRun: dryRun, Skipped: []models.ImportSkip{}}
+
+	users, err := is.users.List(ctx, siteID)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	articles, err := is.articles.List(ctx, siteID)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	// Map the authors to the users of the site, creating the missing ones
+	names := make(map[string]string, len(export.Channel.Authors))
+	authorIDs := make(map[string]uuid.UUID, len(export.Channel.Authors))
+	for _, author := range export.Channel.Authors {
+		name := cmp.Or(author.DisplayName, author.Login)
+		names[author.Login] = name
+
+		skip := func(reason string) {
+			report.Skipped = append(report.Skipped, models.ImportSkip{
+				Type:   "author",
+				Ref:    author.Login,
+				Reason: reason,
+			})
+		}
+
+		if author.Email == "" {
+			skip("the author has no email address")
+			continue
+		}
+
+		if i := slices.IndexFunc(users, func(u models.User) bool {
+			return strings.EqualFold(u.Email, author.Email)
+		}); i >= 0 {
+			authorIDs[author.Login] = users[i].ID
+			skip("a user with the same email address already exists")
+			continue
+		}
+
+		user := models.User{
+			ID:        is.ids.NewID(),
+			SiteID:    siteID,
+			Name:      name,
+			Email:     author.Email,
+			Role:      auth.RoleAuthor,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if !dryRun {
+			if err := is.users.Create(ctx, user); err != nil {
+				return report, fmt.Errorf("unable to create user: %w", err)
+			}
+		}
+
+		users = append(users, user)
+		authorIDs[author.Login] = user.ID
+		report.Created.Users++
+	}
+
+	// Map the posts to articles and their comments to comments
+	for _, item := range export.Channel.Items {
+		ref := fmt.Sprintf("%d %q", item.PostID, item.Title)
+		skip := func(reason string) {
+			report.Skipped = append(report.Skipped, models.ImportSkip{
+				Type:   cmp.Or(item.PostType, "item"),
+				Ref:    ref,
+				Reason: reason,
+			})
+		}
+
+		switch {
+		case item.PostType != "post":
+			skip(fmt.Sprintf("%q items are not supported", item.PostType))
+			continue
+		case item.Status == "trash" || item.Status == "auto-draft":
+			skip(fmt.Sprintf("the post is in the %q status", item.Status))
+			continue
+		case item.PostName != "" && slices.ContainsFunc(
+			articles,
+			func(a models.Article) bool { return a.Slug == item.PostName },
+		):
+			skip("an article with the same slug already exists")
+			continue
+		}
+
+		article := models.Article{
+			ID:          is.ids.NewID(),
+			SiteID:      siteID,
+			Title:       item.Title,
+			Author:      cmp.Or(names[item.Creator], item.Creator),
+			IsPublished: item.Status == "publish",
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Version:     1,
+			ArticleBody: models.ArticleBody{
+				Slug:    strings.ToLower(item.PostName),
+				Content: is.articleSanitizer.Sanitize(item.Content),
+				Tags:    wordPressTags(item.Categories),
+			},
+		}
+		article.RenderedHTML = is.shortcodes.Expand(article.Content)
+		if id, ok := authorIDs[item.Creator]; ok {
+			article.AuthorID = &id
+		}
+		if published, ok := item.Published(); ok {
+			// The articles keep the date they were written on
+			article.CreatedAt = published
+			if article.IsPublished {
+				article.PublishedAt = &published
+			}
+		}
+
+		if !dryRun {
+			article, err = createArticle(ctx, is.articles, article)
+			if err != nil {
+				return report, fmt.Errorf("unable to create article: %w", err)
+			}
+
+			err = recordRevision(ctx, is.revisions, is.ids, models.Article{}, article)
+			if err != nil {
+				return report, err
+			}
+		}
+
+		articles = append(articles, article)
+		report.Created.Articles++
+
+		for _, comment := range item.Comments {
+			skip := func(reason string) {
+				report.Skipped = append(report.Skipped, models.ImportSkip{
+					Type:   "comment",
+					Ref:    fmt.Sprintf("%d on post %s", comment.ID, ref),
+					Reason: reason,
+				})
+			}
+
+			if comment.IsPingback() {
+				skip("pingbacks and trackbacks are not supported")
+				continue
+			} else if !comment.IsApproved() {
+				skip("the comment is not approved")
+				continue
+			}
+
+			created, ok := comment.Published()
+			if !ok {
+				created = now
+			}
+
+			if !dryRun {
+				content := is.commentSanitizer.Sanitize(comment.Content)
+				if err := is.comments.Create(ctx, models.Comment{
+					ID:        is.ids.NewID(),
+					SiteID:    siteID,
+					ArticleID: article.ID,
+					Name:      comment.Author,
+					Email:     comment.AuthorEmail,
+					Content:   content,
+					RenderedHTML: renderComment(
+						content,
+						is.commentSanitizer,
+					),
+					CreatedAt: created,
+					UpdatedAt: now,
+				}); err != nil {
+					return report, fmt.Errorf("unable to create comment: %w", err)
+				}
+			}
+
+			report.Created.Comments++
+		}
+	}
+
+	return report, nil
+}
+
+// wordPressTags returns the tags of an article out of the categories and the tags of
+// a WordPress post, without duplicates.
+func wordPressTags(categories []wxr.Category) []string {
+	var tags []string
+	for _, category := range categories {
+		if category.Domain != "category" && category.Domain != "post_tag" {
+			continue
+		}
+
+		tag := strings.TrimSpace(category.Name)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
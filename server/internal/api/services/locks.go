@@ -0,0 +1,282 @@
+/*
+Package services provides operations for locking the articles being edited.
+
+The primary interface, `EditLockService`, defines methods for the users to lock an
+article while they edit it, to keep the lock alive with heartbeats, and to release it,
+so that the editors can warn the other users that the article is being edited instead
+of letting them lose their work to a version conflict. The `EditLockServiceImpl` struct
+provides the concrete implementation of these methods.
+
+The locks are advisory (they do not prevent anyone from updating the article) and
+personal: they are held by the user owning the API key of the request. A lock expires
+unless it is renewed within `EditLockTTL`. Acquiring and releasing a lock publish the
+`article.locked` and `article.unlocked` events, which let the editors track who is
+editing what without polling.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// EditLockTTL is the duration after which a lock which was not renewed expires. The
+// editors should send heartbeats a few times within this duration.
+const EditLockTTL = 2 * time.Minute
+
+// ErrLocked is returned when an article is locked by another user than the user of
+// the request.
+var ErrLocked = errors.New("article locked by another user")
+
+// EditLockService defines the methods for locking the articles being edited.
+type EditLockService interface {
+	// GetLock retrieves the lock of the article, telling who is editing it.
+	GetLock(ctx context.Context, articleID uuid.UUID) (models.EditLock, error)
+
+	// Lock locks the article for the user of the request, who starts editing it.
+	Lock(ctx context.Context, articleID uuid.UUID) (models.EditLock, error)
+
+	// Heartbeat renews the lock of the article held by the user of the request.
+	Heartbeat(ctx context.Context, articleID uuid.UUID) (models.EditLock, error)
+
+	// Release releases the lock of the article held by the user of the request.
+	Release(ctx context.Context, articleID uuid.UUID) error
+}
+
+// EditLockServiceImpl is the concrete implementation of the EditLockService interface.
+type EditLockServiceImpl struct {
+	mu       sync.Mutex // Serializes the changes of the locks, so that each is unique
+	locks    repository.EditLockRepository
+	articles repository.ArticleRepository
+	users    repository.UserRepository
+	events   EventPublisher
+	ids      IDGenerator
+	clock    Clock
+}
+
+// NewEditLockService creates and returns a new instance of EditLockServiceImpl backed
+// by the given repositories and publishing the changes of the locks with the given
+// publisher. The new locks are assigned their IDs by the given generator, and expire
+// according to the time told by the given clock.
+func NewEditLockService(
+	locks repository.EditLockRepository,
+	articles repository.ArticleRepository,
+	users repository.UserRepository,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *EditLockServiceImpl {
+	return &EditLockServiceImpl{
+		locks:    locks,
+		articles: articles,
+		users:    users,
+		events:   events,
+		ids:      ids,
+		clock:    clock,
+	}
+}
+
+/*
+GetLock retrieves the lock of the article of the site held by the context, telling who
+is editing it.
+
+`repository.ErrNotFound` (wrapped) is returned if no such article exists or if nobody
+is editing it (i.e. its lock expired or was released).
+*/
+func (ls *EditLockServiceImpl) GetLock(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.EditLock, error) {
+	if _, err := ls.articles.Get(ctx, tenant.SiteID(ctx), articleID); err != nil {
+		return models.EditLock{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	return ls.activeLock(ctx, articleID)
+}
+
+/*
+Lock locks the article of the site held by the context for the user of the request,
+who starts editing it, until the lock expires or is released. Locking an article the
+user already locked renews the lock, and an expired lock of another user is taken
+over.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user,
+`ErrLocked` along with the lock of the other user if another user is editing the
+article, and `repository.ErrNotFound` (wrapped) if no such article exists.
+*/
+func (ls *EditLockServiceImpl) Lock(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.EditLock, error) {
+	siteID := tenant.SiteID(ctx)
+
+	userID, err := userOf(ctx)
+	if err != nil {
+		return models.EditLock{}, err
+	}
+
+	if _, err := ls.articles.Get(ctx, siteID, articleID); err != nil {
+		return models.EditLock{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	user, err := ls.users.Get(ctx, siteID, userID)
+	if err != nil {
+		return models.EditLock{}, fmt.Errorf("unable to fetch user %s: %w", userID, err)
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	lock, err := ls.activeLock(ctx, articleID)
+	if err == nil {
+		if lock.UserID != userID {
+			return lock, ErrLocked
+		}
+
+		return ls.renew(ctx, lock)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return models.EditLock{}, err
+	}
+
+	// Take over the expired lock, if any
+	if err := ls.locks.DeleteByArticle(ctx, siteID, articleID); err != nil {
+		return models.EditLock{}, fmt.Errorf("unable to release lock: %w", err)
+	}
+
+	now := ls.clock.Now()
+	lock = models.EditLock{
+		ID:         ls.ids.NewID(),
+		SiteID:     siteID,
+		ArticleID:  articleID,
+		UserID:     userID,
+		UserName:   user.Name,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(EditLockTTL),
+	}
+
+	if err := ls.locks.Create(ctx, lock); err != nil {
+		return models.EditLock{}, fmt.Errorf("unable to create lock: %w", err)
+	}
+
+	ls.events.Publish(siteID, "article.locked", map[string]any{"lock": lock})
+
+	return lock, nil
+}
+
+/*
+Heartbeat renews the lock of the article of the site held by the context, which the
+user of the request holds, for another `EditLockTTL`.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user,
+`ErrLocked` along with the lock of the other user if another user is editing the
+article, and `repository.ErrNotFound` (wrapped) if no such article exists or if its
+lock expired (in which case the article has to be locked again).
+*/
+func (ls *EditLockServiceImpl) Heartbeat(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.EditLock, error) {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return models.EditLock{}, err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	lock, err := ls.activeLock(ctx, articleID)
+	if err != nil {
+		return models.EditLock{}, err
+	} else if lock.UserID != userID {
+		return lock, ErrLocked
+	}
+
+	return ls.renew(ctx, lock)
+}
+
+/*
+Release releases the lock of the article of the site held by the context, which the
+user of the request holds, once they stopped editing it.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user,
+`ErrLocked` if another user is editing the article, and `repository.ErrNotFound`
+(wrapped) if no such article exists or if nobody is editing it.
+*/
+func (ls *EditLockServiceImpl) Release(ctx context.Context, articleID uuid.UUID) error {
+	siteID := tenant.SiteID(ctx)
+
+	userID, err := userOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	lock, err := ls.activeLock(ctx, articleID)
+	if err != nil {
+		return err
+	} else if lock.UserID != userID {
+		return ErrLocked
+	}
+
+	if err := ls.locks.DeleteByArticle(ctx, siteID, articleID); err != nil {
+		return fmt.Errorf("unable to release lock: %w", err)
+	}
+
+	ls.events.Publish(siteID, "article.unlocked", map[string]any{
+		"article_id": articleID,
+		"user_id":    userID,
+	})
+
+	return nil
+}
+
+// activeLock returns the lock of the article of the site held by the context, or
+// `repository.ErrNotFound` (wrapped) if the article is not locked or its lock expired.
+func (ls *EditLockServiceImpl) activeLock(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.EditLock, error) {
+	lock, err := ls.locks.GetByArticle(ctx, tenant.SiteID(ctx), articleID)
+	if err != nil {
+		return models.EditLock{}, fmt.Errorf(
+			"unable to fetch lock of article %s: %w", articleID, err,
+		)
+	}
+
+	if !ls.clock.Now().Before(lock.ExpiresAt) {
+		return models.EditLock{}, fmt.Errorf(
+			"lock of article %s expired: %w", articleID, repository.ErrNotFound,
+		)
+	}
+
+	return lock, nil
+}
+
+// renew extends the lock for another `EditLockTTL`.
+func (ls *EditLockServiceImpl) renew(
+	ctx context.Context,
+	lock models.EditLock,
+) (models.EditLock, error) {
+	lock.ExpiresAt = ls.clock.Now().Add(EditLockTTL)
+
+	if err := ls.locks.Update(ctx, lock); err != nil {
+		return models.EditLock{}, fmt.Errorf("unable to renew lock: %w", err)
+	}
+
+	return lock, nil
+}
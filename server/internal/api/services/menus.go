@@ -0,0 +1,352 @@
+/*
+Package services provides operations for managing the navigation menus of the sites.
+
+The primary interface, `MenuService`, defines methods to manage the navigation menus of
+a site and to serve them to its frontends, with the links of their entries resolved
+from the articles and pages they reference. The `MenuServiceImpl` struct provides the
+concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// maxMenuDepth is the maximum number of levels of the entries of a menu.
+const maxMenuDepth = 3
+
+// ErrInvalidMenuItem is returned when an entry of a menu does not link to exactly one
+// existing article, page or external URL, or when the entries are nested too deeply.
+var ErrInvalidMenuItem = errors.New("invalid menu item")
+
+// MenuService defines the methods for managing the navigation menus of the sites.
+type MenuService interface {
+	// GetAllMenus retrieves every menu of the site.
+	GetAllMenus(ctx context.Context) ([]models.Menu, error)
+
+	// GetMenuByID fetches a menu by its unique ID.
+	GetMenuByID(ctx context.Context, id uuid.UUID) (models.Menu, error)
+
+	// GetPublishedMenu fetches a menu by its handle, as served to the readers.
+	GetPublishedMenu(ctx context.Context, handle string) (models.Menu, error)
+
+	// CreateMenu creates a new menu of the site.
+	CreateMenu(ctx context.Context, menu models.Menu) (models.Menu, error)
+
+	// UpdateMenu updates the handle, name and entries of a menu.
+	UpdateMenu(
+		ctx context.Context,
+		id uuid.UUID,
+		menu models.Menu,
+	) (models.Menu, error)
+
+	// DeleteMenu removes a menu identified by its unique ID.
+	DeleteMenu(ctx context.Context, id uuid.UUID) error
+}
+
+// MenuServiceImpl is the concrete implementation of the MenuService interface.
+type MenuServiceImpl struct {
+	menus    repository.MenuRepository
+	articles repository.ArticleRepository
+	pages    repository.PageRepository
+	ids      IDGenerator
+}
+
+// NewMenuService creates and returns a new instance of MenuServiceImpl backed by the
+// given menu repository, resolving the links of the entries with the given article
+// and page repositories.
+func NewMenuService(
+	menus repository.MenuRepository,
+	articles repository.ArticleRepository,
+	pages repository.PageRepository,
+	ids IDGenerator,
+) *MenuServiceImpl {
+	return &MenuServiceImpl{menus: menus, articles: articles, pages: pages, ids: ids}
+}
+
+// GetAllMenus retrieves every menu of the site held by the context.
+func (ms *MenuServiceImpl) GetAllMenus(ctx context.Context) ([]models.Menu, error) {
+	menus, err := ms.menus.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.Menu{}, fmt.Errorf("unable to fetch menus: %w", err)
+	}
+
+	targets, err := ms.targets(ctx)
+	if err != nil {
+		return []models.Menu{}, err
+	}
+
+	for i := range menus {
+		menus[i].Items = targets.resolve(menus[i].Items, false)
+	}
+
+	return menus, nil
+}
+
+// GetMenuByID fetches a menu of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such menu exists.
+func (ms *MenuServiceImpl) GetMenuByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Menu, error) {
+	menu, err := ms.menus.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Menu{}, fmt.Errorf("unable to fetch menu %s: %w", id, err)
+	}
+
+	targets, err := ms.targets(ctx)
+	if err != nil {
+		return models.Menu{}, err
+	}
+
+	menu.Items = targets.resolve(menu.Items, false)
+
+	return menu, nil
+}
+
+/*
+GetPublishedMenu fetches a menu of the site held by the context by its handle (whatever
+its case), wrapping `repository.ErrNotFound` if no such menu exists.
+
+The entries linking to an article or a page which is not published (or no longer
+exists) are left out of the menu, along with their nested entries.
+*/
+func (ms *MenuServiceImpl) GetPublishedMenu(
+	ctx context.Context,
+	handle string,
+) (models.Menu, error) {
+	menu, err := ms.menus.GetByHandle(ctx, tenant.SiteID(ctx), strings.ToLower(handle))
+	if err != nil {
+		return models.Menu{}, fmt.Errorf("unable to fetch menu %q: %w", handle, err)
+	}
+
+	targets, err := ms.targets(ctx)
+	if err != nil {
+		return models.Menu{}, err
+	}
+
+	menu.Items = targets.resolve(menu.Items, true)
+
+	return menu, nil
+}
+
+/*
+CreateMenu creates a new menu in the site held by the context.
+
+`ErrInvalidMenuItem` is returned (wrapped) if an entry does not link to exactly one
+existing article, page or external URL, or if the entries are nested deeper than three
+levels, and `repository.ErrConflict` (wrapped) if the handle is already taken by
+another menu.
+*/
+func (ms *MenuServiceImpl) CreateMenu(
+	ctx context.Context,
+	menu models.Menu,
+) (models.Menu, error) {
+	targets, err := ms.targets(ctx)
+	if err != nil {
+		return models.Menu{}, err
+	}
+
+	if err := targets.check(menu.Items, 1); err != nil {
+		return models.Menu{}, fmt.Errorf("unable to create menu: %w", err)
+	}
+
+	menuID := ms.ids.NewID()
+
+	menu.ID = menuID
+	menu.SiteID = tenant.SiteID(ctx)
+	menu.Items = targets.resolve(menu.Items, false)
+
+	if err := ms.menus.Create(ctx, withoutHrefs(menu)); err != nil {
+		return models.Menu{}, fmt.Errorf("unable to create menu: %w", err)
+	}
+
+	return menu, nil
+}
+
+/*
+UpdateMenu updates the handle, name and entries of an existing menu of the site held by
+the context.
+
+`ErrInvalidMenuItem` is returned (wrapped) if an entry is invalid (see `CreateMenu`),
+`repository.ErrNotFound` (wrapped) if no such menu exists and `repository.ErrConflict`
+(wrapped) if the handle is already taken by another menu.
+*/
+func (ms *MenuServiceImpl) UpdateMenu(
+	ctx context.Context,
+	id uuid.UUID,
+	menu models.Menu,
+) (models.Menu, error) {
+	existing, err := ms.menus.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Menu{}, fmt.Errorf("unable to fetch menu %s: %w", id, err)
+	}
+
+	targets, err := ms.targets(ctx)
+	if err != nil {
+		return models.Menu{}, err
+	}
+
+	if err := targets.check(menu.Items, 1); err != nil {
+		return models.Menu{}, fmt.Errorf("unable to update menu %s: %w", id, err)
+	}
+
+	existing.Handle = menu.Handle
+	existing.Name = menu.Name
+	existing.Items = targets.resolve(menu.Items, false)
+
+	if err := ms.menus.Update(ctx, withoutHrefs(existing)); err != nil {
+		return models.Menu{}, fmt.Errorf("unable to update menu %s: %w", id, err)
+	}
+
+	return existing, nil
+}
+
+// DeleteMenu removes a menu of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such menu exists.
+func (ms *MenuServiceImpl) DeleteMenu(ctx context.Context, id uuid.UUID) error {
+	if err := ms.menus.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete menu %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// menuTargets holds the articles and pages of a site the entries of its menus may
+// link to.
+type menuTargets struct {
+	articles map[uuid.UUID]models.Article
+	pages    map[uuid.UUID]models.Page
+}
+
+// targets returns the articles and pages of the site held by the context.
+func (ms *MenuServiceImpl) targets(ctx context.Context) (menuTargets, error) {
+	siteID := tenant.SiteID(ctx)
+
+	articles, err := ms.articles.List(ctx, siteID)
+	if err != nil {
+		return menuTargets{}, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	pages, err := ms.pages.List(ctx, siteID)
+	if err != nil {
+		return menuTargets{}, fmt.Errorf("unable to fetch pages: %w", err)
+	}
+
+	targets := menuTargets{
+		articles: make(map[uuid.UUID]models.Article, len(articles)),
+		pages:    make(map[uuid.UUID]models.Page, len(pages)),
+	}
+	for _, article := range articles {
+		targets.articles[article.ID] = article
+	}
+	for _, page := range pages {
+		targets.pages[page.ID] = page
+	}
+
+	return targets, nil
+}
+
+// check returns `ErrInvalidMenuItem` if one of the entries, found at the
+// given depth, or of their nested entries is invalid.
+func (mt menuTargets) check(items []models.MenuItem, depth int) error {
+	for _, item := range items {
+		targets := 0
+		if item.ArticleID != nil {
+			if _, ok := mt.articles[*item.ArticleID]; !ok {
+				return ErrInvalidMenuItem
+			}
+			targets++
+		}
+		if item.PageID != nil {
+			if _, ok := mt.pages[*item.PageID]; !ok {
+				return ErrInvalidMenuItem
+			}
+			targets++
+		}
+		if item.URL != "" {
+			targets++
+		}
+
+		if targets != 1 {
+			return ErrInvalidMenuItem
+		}
+
+		if len(item.Items) > 0 && depth == maxMenuDepth {
+			return ErrInvalidMenuItem
+		}
+
+		if err := mt.check(item.Items, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve returns a copy of the entries with their links resolved, leaving out the
+// entries linking to content which no longer exists (or which is not published if
+// published is true).
+func (mt menuTargets) resolve(
+	items []models.MenuItem,
+	published bool,
+) []models.MenuItem {
+	resolved := make([]models.MenuItem, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.ArticleID != nil:
+			article, ok := mt.articles[*item.ArticleID]
+			if !ok || (published && !article.IsPublished) {
+				continue
+			}
+			item.Href = "/articles/" + article.ID.String()
+		case item.PageID != nil:
+			page, ok := mt.pages[*item.PageID]
+			if !ok || (published && !page.IsPublished) {
+				continue
+			}
+			item.Href = "/pages" + page.Path
+		default:
+			item.Href = item.URL
+		}
+
+		item.Items = mt.resolve(item.Items, published)
+		if len(item.Items) == 0 {
+			item.Items = nil
+		}
+		resolved = append(resolved, item)
+	}
+
+	return resolved
+}
+
+// withoutHrefs returns a copy of the menu without the resolved links of its entries,
+// which are never stored.
+func withoutHrefs(menu models.Menu) models.Menu {
+	menu.Items = clearHrefs(menu.Items)
+	return menu
+}
+
+// clearHrefs returns a copy of the entries without their resolved links.
+func clearHrefs(items []models.MenuItem) []models.MenuItem {
+	cleared := make([]models.MenuItem, len(items))
+	for i, item := range items {
+		item.Href = ""
+		item.Items = clearHrefs(item.Items)
+		if len(item.Items) == 0 {
+			item.Items = nil
+		}
+		cleared[i] = item
+	}
+
+	return cleared
+}
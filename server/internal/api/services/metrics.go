@@ -0,0 +1,110 @@
+/*
+Package services provides the metrics of the business events of the sites.
+
+The `EventMetrics` struct counts the events of the sites (e.g. the articles published
+or the comments rejected as spam) in the metrics registry of the server, so that the
+dashboards reflect the editorial activity of the sites and not only their traffic. It
+also exposes the depth of the task queue, collected when the metrics are scraped.
+*/
+package services
+
+import (
+	"context"
+
+	"github.com/Weburz/burzcontent/server/internal/events"
+	"github.com/Weburz/burzcontent/server/internal/metrics"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+)
+
+// EventMetrics counts the events of the sites in a metrics registry.
+type EventMetrics struct {
+	events          *metrics.Counter
+	published       *metrics.Counter
+	comments        *metrics.Counter
+	webhookFailures *metrics.Counter
+}
+
+/*
+NewEventMetrics creates and returns a new EventMetrics registering its counters in the
+given registry, along with the gauges of the depth of the given task queue:
+  - burzcontent_events_total{site, type}: The events published, by site and type.
+  - burzcontent_articles_published_total{site}: The articles published.
+  - burzcontent_comments_total{site, status}: The comments "approved" or "rejected" as
+    spam.
+  - burzcontent_webhook_failures_total{site}: The failed attempts to deliver an event
+    to an outbound webhook.
+  - burzcontent_queue_tasks_pending, burzcontent_queue_tasks_running,
+    burzcontent_queue_tasks_retrying and burzcontent_queue_tasks_dead: The tasks of
+    the task queue waiting to run, running, waiting to be retried and dead-lettered.
+
+The sites are labelled by their ID.
+*/
+func NewEventMetrics(registry *metrics.Registry, tasks *queue.Queue) *EventMetrics {
+	em := &EventMetrics{
+		events: registry.Counter(
+			"burzcontent_events_total",
+			"The number of events published, by site and type.",
+			"site", "type",
+		),
+		published: registry.Counter(
+			"burzcontent_articles_published_total",
+			"The number of articles published, by site.",
+			"site",
+		),
+		comments: registry.Counter(
+			"burzcontent_comments_total",
+			"The number of comments approved or rejected as spam, by site and status.",
+			"site", "status",
+		),
+		webhookFailures: registry.Counter(
+			"burzcontent_webhook_failures_total",
+			"The number of failed attempts to deliver an event to a webhook, by site.",
+			"site",
+		),
+	}
+
+	gauges := []struct {
+		state string
+		help  string
+		count func(queue.Stats) int
+	}{
+		{"pending", "waiting to run", func(s queue.Stats) int { return s.Pending }},
+		{"running", "running", func(s queue.Stats) int { return s.Running }},
+		{"retrying", "to retry", func(s queue.Stats) int { return s.Retrying }},
+		{"dead", "dead-lettered", func(s queue.Stats) int { return s.Dead }},
+	}
+	for _, gauge := range gauges {
+		registry.GaugeFunc(
+			"burzcontent_queue_tasks_"+gauge.state,
+			"The number of tasks of the task queue "+gauge.help+".",
+			func() float64 {
+				stats, err := tasks.Stats(context.Background())
+				if err != nil {
+					return 0
+				}
+
+				return float64(gauge.count(stats))
+			},
+		)
+	}
+
+	return em
+}
+
+// Observe counts the event in the metrics. It is meant to observe the events published
+// on the sites (see `events.Broker.Observe`).
+func (em *EventMetrics) Observe(event events.Event) {
+	site := event.SiteID.String()
+	em.events.Inc(site, event.Type)
+
+	switch event.Type {
+	case "article.published":
+		em.published.Inc(site)
+	case "comment.approved":
+		em.comments.Inc(site, "approved")
+	case "comment.rejected":
+		em.comments.Inc(site, "rejected")
+	case webhookFailedEvent:
+		em.webhookFailures.Inc(site)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Package services provides operations for managing the static pages of the sites.
+
+The primary interface, `PageService`, defines methods to manage the static pages of a
+site (e.g. about, contact or legal pages) through a draft/publish workflow, and to
+serve the published ones. The `PageServiceImpl` struct provides the concrete
+implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrInvalidPath is returned when the path of a page is not made of slug-like segments.
+var ErrInvalidPath = errors.New("invalid page path")
+
+// pagePath matches the valid paths of the pages, e.g. "/" or "/legal/privacy-policy".
+var pagePath = regexp.MustCompile(
+	`^/(` + pageSegment + `(/` + pageSegment + `)*)?$`,
+)
+
+// pageSegment matches a segment of the path of a page, e.g. "privacy-policy".
+const pageSegment = `[a-z0-9]+(-[a-z0-9]+)*`
+
+// PageService defines the methods for managing the static pages of the sites.
+type PageService interface {
+	// GetAllPages retrieves every page of the site.
+	GetAllPages(ctx context.Context) ([]models.Page, error)
+
+	// GetPublishedPages retrieves the published pages of the site.
+	GetPublishedPages(ctx context.Context) ([]models.Page, error)
+
+	// GetPageByID fetches a page by its unique ID.
+	GetPageByID(ctx context.Context, id uuid.UUID) (models.Page, error)
+
+	// GetPublishedPageByPath fetches a published page by its path.
+	GetPublishedPageByPath(ctx context.Context, path string) (models.Page, error)
+
+	// CreatePage creates a new page of the site.
+	CreatePage(ctx context.Context, page models.Page) (models.Page, error)
+
+	// UpdatePage updates the path, title, content and publication status of a page.
+	UpdatePage(
+		ctx context.Context,
+		id uuid.UUID,
+		page models.Page,
+	) (models.Page, error)
+
+	// DeletePage removes a page identified by its unique ID.
+	DeletePage(ctx context.Context, id uuid.UUID) error
+}
+
+// PageServiceImpl is the concrete implementation of the PageService interface.
+type PageServiceImpl struct {
+	pages repository.PageRepository
+	ids   IDGenerator
+	clock Clock
+}
+
+// NewPageService creates and returns a new instance of PageServiceImpl backed by the
+// given page repository, stamping the publication of the pages with the time told by
+// the given clock.
+func NewPageService(
+	pages repository.PageRepository,
+	ids IDGenerator,
+	clock Clock,
+) *PageServiceImpl {
+	return &PageServiceImpl{pages: pages, ids: ids, clock: clock}
+}
+
+// GetAllPages retrieves every page of the site held by the context.
+func (ps *PageServiceImpl) GetAllPages(ctx context.Context) ([]models.Page, error) {
+	pages, err := ps.pages.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.Page{}, fmt.Errorf("unable to fetch pages: %w", err)
+	}
+
+	return pages, nil
+}
+
+// GetPublishedPages retrieves the published pages of the site held by the context,
+// i.e. the pages which can be served to anonymous readers.
+func (ps *PageServiceImpl) GetPublishedPages(
+	ctx context.Context,
+) ([]models.Page, error) {
+	pages, err := ps.GetAllPages(ctx)
+	if err != nil {
+		return []models.Page{}, err
+	}
+
+	return slices.DeleteFunc(pages, func(p models.Page) bool {
+		return !p.IsPublished
+	}), nil
+}
+
+// GetPageByID fetches a page of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such page exists.
+func (ps *PageServiceImpl) GetPageByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Page, error) {
+	page, err := ps.pages.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Page{}, fmt.Errorf("unable to fetch page %s: %w", id, err)
+	}
+
+	return page, nil
+}
+
+// GetPublishedPageByPath fetches a published page of the site held by the context by
+// its path, whatever its case, wrapping `repository.ErrNotFound` if no such page is
+// published.
+func (ps *PageServiceImpl) GetPublishedPageByPath(
+	ctx context.Context,
+	path string,
+) (models.Page, error) {
+	page, err := ps.pages.GetByPath(
+		ctx,
+		tenant.SiteID(ctx),
+		strings.ToLower(cleanPath(path)),
+	)
+	if err == nil && !page.IsPublished {
+		err = repository.ErrNotFound
+	}
+
+	if err != nil {
+		return models.Page{}, fmt.Errorf("unable to fetch page %q: %w", path, err)
+	}
+
+	return page, nil
+}
+
+/*
+CreatePage creates a new page in the site held by the context.
+
+`ErrInvalidPath` is returned if the path is not made of slug-like segments (e.g.
+"/legal/privacy-policy") and `repository.ErrConflict` (wrapped) if the path is already
+taken by another page.
+*/
+func (ps *PageServiceImpl) CreatePage(
+	ctx context.Context,
+	page models.Page,
+) (models.Page, error) {
+	page.Path = cleanPath(page.Path)
+	if !pagePath.MatchString(page.Path) {
+		return models.Page{}, ErrInvalidPath
+	}
+
+	pageID := ps.ids.NewID()
+
+	page.ID = pageID
+	page.SiteID = tenant.SiteID(ctx)
+	page.PublishedAt = nil
+	ps.stampPublication(&page)
+
+	if err := ps.pages.Create(ctx, page); err != nil {
+		return models.Page{}, fmt.Errorf("unable to create page: %w", err)
+	}
+
+	return page, nil
+}
+
+/*
+UpdatePage updates the path, title, content and publication status of an existing
+page of the site held by the context.
+
+`ErrInvalidPath` is returned if the path is not made of slug-like segments,
+`repository.ErrNotFound` (wrapped) if no such page exists and `repository.ErrConflict`
+(wrapped) if the path is already taken by another page.
+*/
+func (ps *PageServiceImpl) UpdatePage(
+	ctx context.Context,
+	id uuid.UUID,
+	page models.Page,
+) (models.Page, error) {
+	page.Path = cleanPath(page.Path)
+	if !pagePath.MatchString(page.Path) {
+		return models.Page{}, ErrInvalidPath
+	}
+
+	existing, err := ps.pages.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Page{}, fmt.Errorf("unable to fetch page %s: %w", id, err)
+	}
+
+	existing.Path = page.Path
+	existing.Title = page.Title
+	existing.Content = page.Content
+	existing.IsPublished = page.IsPublished
+	ps.stampPublication(&existing)
+
+	if err := ps.pages.Update(ctx, existing); err != nil {
+		return models.Page{}, fmt.Errorf("unable to update page %s: %w", id, err)
+	}
+
+	return existing, nil
+}
+
+// DeletePage removes a page of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such page exists.
+func (ps *PageServiceImpl) DeletePage(ctx context.Context, id uuid.UUID) error {
+	if err := ps.pages.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete page %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// stampPublication records when the page is first published.
+func (ps *PageServiceImpl) stampPublication(page *models.Page) {
+	if page.IsPublished && page.PublishedAt == nil {
+		now := ps.clock.Now()
+		page.PublishedAt = &now
+	}
+}
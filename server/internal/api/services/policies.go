@@ -0,0 +1,277 @@
+/*
+Package services provides operations for the policies of the sites (e.g. their terms of
+service) and their acceptance by the users.
+
+The primary interface, `PolicyService`, defines the methods used to publish the
+versions of the policy of a site, to accept the current version, to list the users
+who did not accept it and to query the history of the acceptances. The
+`PolicyServiceImpl` struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// PolicyService defines the methods for managing the policies of a site.
+type PolicyService interface {
+	// GetPolicies retrieves the published policies, in the order of publication.
+	GetPolicies(ctx context.Context) ([]models.Policy, error)
+
+	// PublishPolicy publishes a new version of the policy, which becomes the current
+	// one, and returns it along with the number of users who have to accept it.
+	PublishPolicy(ctx context.Context, policy models.Policy) (models.Policy, int, error)
+
+	// GetPendingUsers retrieves the users who did not accept the current policy.
+	GetPendingUsers(ctx context.Context) ([]models.User, error)
+
+	// AcceptPolicy records the acceptance of the current policy by the user of the
+	// request.
+	AcceptPolicy(ctx context.Context) (models.PolicyAcceptance, error)
+
+	// GetAcceptances retrieves the acceptances of the policies, optionally of a user
+	// or of a policy only.
+	GetAcceptances(
+		ctx context.Context,
+		userID, policyID uuid.UUID,
+	) ([]models.PolicyAcceptance, error)
+
+	// PendingPolicy returns the current policy if it has to be accepted by the user
+	// before they modify the content, and reports whether it does.
+	PendingPolicy(ctx context.Context, userID uuid.UUID) (models.Policy, bool, error)
+}
+
+// PolicyServiceImpl is the concrete implementation of the PolicyService interface.
+type PolicyServiceImpl struct {
+	policies repository.PolicyRepository
+	users    repository.UserRepository
+	ids      IDGenerator
+	clock    Clock
+}
+
+// NewPolicyService creates and returns a new instance of PolicyServiceImpl backed by
+// the given repositories.
+func NewPolicyService(
+	policies repository.PolicyRepository,
+	users repository.UserRepository,
+	ids IDGenerator,
+	clock Clock,
+) *PolicyServiceImpl {
+	return &PolicyServiceImpl{policies: policies, users: users, ids: ids, clock: clock}
+}
+
+// GetPolicies retrieves the policies published by the site held by the context, in
+// the order of publication, the last one being the current one.
+func (ps *PolicyServiceImpl) GetPolicies(ctx context.Context) ([]models.Policy, error) {
+	policies, err := ps.policies.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.Policy{}, fmt.Errorf("unable to fetch policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+/*
+PublishPolicy publishes a new version of the policy of the site held by the context,
+which becomes the current one, and returns it along with the number of users who have
+to accept it, i.e. every user of the site since none accepted it yet.
+
+The published versions can not be changed, a new version has to be published instead.
+`repository.ErrConflict` is returned (wrapped) if the version is already published.
+*/
+func (ps *PolicyServiceImpl) PublishPolicy(
+	ctx context.Context,
+	policy models.Policy,
+) (models.Policy, int, error) {
+	siteID := tenant.SiteID(ctx)
+
+	policies, err := ps.policies.List(ctx, siteID)
+	if err != nil {
+		return models.Policy{}, 0, fmt.Errorf("unable to fetch policies: %w", err)
+	}
+
+	if slices.ContainsFunc(policies, func(p models.Policy) bool {
+		return p.Version == policy.Version
+	}) {
+		return models.Policy{}, 0, fmt.Errorf(
+			"unable to publish policy %q: %w", policy.Version, repository.ErrConflict,
+		)
+	}
+
+	policy.ID = ps.ids.NewID()
+	policy.SiteID = siteID
+	policy.PublishedAt = ps.clock.Now()
+
+	if err := ps.policies.Create(ctx, policy); err != nil {
+		return models.Policy{}, 0, fmt.Errorf("unable to publish policy: %w", err)
+	}
+
+	users, err := ps.users.List(ctx, siteID)
+	if err != nil {
+		return models.Policy{}, 0, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	return policy, len(users), nil
+}
+
+// GetPendingUsers retrieves the users of the site held by the context who did not
+// accept its current policy, none if the site has no policy.
+func (ps *PolicyServiceImpl) GetPendingUsers(
+	ctx context.Context,
+) ([]models.User, error) {
+	siteID := tenant.SiteID(ctx)
+
+	current, err := ps.currentPolicy(ctx, siteID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return []models.User{}, nil
+	} else if err != nil {
+		return []models.User{}, err
+	}
+
+	users, err := ps.users.List(ctx, siteID)
+	if err != nil {
+		return []models.User{}, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	return slices.DeleteFunc(users, func(u models.User) bool {
+		return u.AcceptedPolicy == current.Version
+	}), nil
+}
+
+/*
+AcceptPolicy records the acceptance of the current policy of the site held by the
+context by the user of the request. Accepting the policy again records another
+acceptance.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user, and
+`repository.ErrNotFound` (wrapped) if the site has no policy.
+*/
+func (ps *PolicyServiceImpl) AcceptPolicy(
+	ctx context.Context,
+) (models.PolicyAcceptance, error) {
+	siteID := tenant.SiteID(ctx)
+
+	userID, err := userOf(ctx)
+	if err != nil {
+		return models.PolicyAcceptance{}, err
+	}
+
+	current, err := ps.currentPolicy(ctx, siteID)
+	if err != nil {
+		return models.PolicyAcceptance{}, err
+	}
+
+	user, err := ps.users.Get(ctx, siteID, userID)
+	if err != nil {
+		return models.PolicyAcceptance{}, fmt.Errorf(
+			"unable to fetch user %s: %w", userID, err,
+		)
+	}
+
+	acceptance := models.PolicyAcceptance{
+		ID:         ps.ids.NewID(),
+		SiteID:     siteID,
+		PolicyID:   current.ID,
+		Version:    current.Version,
+		UserID:     userID,
+		AcceptedAt: ps.clock.Now(),
+	}
+
+	if err := ps.policies.CreateAcceptance(ctx, acceptance); err != nil {
+		return models.PolicyAcceptance{}, fmt.Errorf(
+			"unable to record acceptance: %w", err,
+		)
+	}
+
+	user.AcceptedPolicy = current.Version
+	if err := ps.users.Update(ctx, user); err != nil {
+		return models.PolicyAcceptance{}, fmt.Errorf(
+			"unable to update user %s: %w", userID, err,
+		)
+	}
+
+	return acceptance, nil
+}
+
+// GetAcceptances retrieves the acceptances of the policies of the site held by the
+// context, in the order they were made, of the user and of the policy only unless
+// their ID is `uuid.Nil`.
+func (ps *PolicyServiceImpl) GetAcceptances(
+	ctx context.Context,
+	userID, policyID uuid.UUID,
+) ([]models.PolicyAcceptance, error) {
+	acceptances, err := ps.policies.ListAcceptances(
+		ctx,
+		tenant.SiteID(ctx),
+		func(a models.PolicyAcceptance) bool {
+			return (userID == uuid.Nil || a.UserID == userID) &&
+				(policyID == uuid.Nil || a.PolicyID == policyID)
+		},
+	)
+	if err != nil {
+		return []models.PolicyAcceptance{}, fmt.Errorf(
+			"unable to fetch acceptances: %w", err,
+		)
+	}
+
+	return acceptances, nil
+}
+
+// PendingPolicy returns the current policy of the site held by the context if it has
+// to be accepted before modifying the content and the user did not accept it, and
+// reports whether it does.
+func (ps *PolicyServiceImpl) PendingPolicy(
+	ctx context.Context,
+	userID uuid.UUID,
+) (models.Policy, bool, error) {
+	siteID := tenant.SiteID(ctx)
+
+	current, err := ps.currentPolicy(ctx, siteID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Policy{}, false, nil
+	} else if err != nil {
+		return models.Policy{}, false, err
+	}
+
+	if !current.RequireAcceptance {
+		return models.Policy{}, false, nil
+	}
+
+	user, err := ps.users.Get(ctx, siteID, userID)
+	if err != nil {
+		return models.Policy{}, false, fmt.Errorf(
+			"unable to fetch user %s: %w", userID, err,
+		)
+	}
+
+	return current, user.AcceptedPolicy != current.Version, nil
+}
+
+// currentPolicy returns the last policy published by the site, or
+// `repository.ErrNotFound` (wrapped) if it has none.
+func (ps *PolicyServiceImpl) currentPolicy(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (models.Policy, error) {
+	policies, err := ps.policies.List(ctx, siteID)
+	if err != nil {
+		return models.Policy{}, fmt.Errorf("unable to fetch policies: %w", err)
+	}
+
+	if len(policies) == 0 {
+		return models.Policy{}, fmt.Errorf(
+			"unable to fetch current policy: %w", repository.ErrNotFound,
+		)
+	}
+
+	return policies[len(policies)-1], nil
+}
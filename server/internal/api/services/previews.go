@@ -0,0 +1,138 @@
+/*
+Package services provides operations for previewing the draft articles on the headless
+frontends.
+
+The primary interface, `PreviewService`, defines the handshake exchanging the token of
+a share link for a short-lived preview token, and the retrieval of the previewed
+article with that token, whether it is published or not. The `PreviewServiceImpl`
+struct provides the concrete implementation of these methods.
+
+The preview tokens are signed (see the `preview` package) rather than stored, and are
+bound to a single article of a single site.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/preview"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// PreviewLifetime is the lifetime of the preview tokens, after which the frontends
+// have to make the handshake again.
+const PreviewLifetime = time.Hour
+
+// SharedArticleResolver resolves the articles shared by links, like
+// `ShareLinkService` does.
+type SharedArticleResolver interface {
+	GetSharedArticle(ctx context.Context, token string) (models.Article, error)
+}
+
+// PreviewService defines the methods for previewing the articles on the frontends.
+type PreviewService interface {
+	// StartPreview exchanges the token of a share link for a preview of its article.
+	StartPreview(ctx context.Context, token string) (models.Preview, error)
+
+	// GetPreviewArticle retrieves the article previewed with the preview token.
+	GetPreviewArticle(
+		ctx context.Context,
+		articleID uuid.UUID,
+		token string,
+	) (models.Article, error)
+}
+
+// PreviewServiceImpl is the concrete implementation of the PreviewService interface.
+type PreviewServiceImpl struct {
+	links    SharedArticleResolver
+	articles repository.ArticleRepository
+	signer   *preview.Signer
+	clock    Clock
+}
+
+// NewPreviewService creates and returns a new instance of PreviewServiceImpl resolving
+// the share links with links, signing the preview tokens with signer and expiring them
+// according to the time told by the given clock.
+func NewPreviewService(
+	links SharedArticleResolver,
+	articles repository.ArticleRepository,
+	signer *preview.Signer,
+	clock Clock,
+) *PreviewServiceImpl {
+	return &PreviewServiceImpl{
+		links:    links,
+		articles: articles,
+		signer:   signer,
+		clock:    clock,
+	}
+}
+
+/*
+StartPreview exchanges the token of a link sharing an article of the site held by the
+context for a preview of the article, whose token expires after `PreviewLifetime`. The
+URL of the preview is left to the caller.
+
+The one-time links are used up by the handshake. `repository.ErrNotFound` is returned
+(wrapped) if the token is unknown, expired or already used, or if the article no longer
+exists.
+*/
+func (ps *PreviewServiceImpl) StartPreview(
+	ctx context.Context,
+	token string,
+) (models.Preview, error) {
+	article, err := ps.links.GetSharedArticle(ctx, token)
+	if err != nil {
+		return models.Preview{}, err
+	}
+
+	expiresAt := ps.clock.Now().Add(PreviewLifetime).Truncate(time.Second)
+	return models.Preview{
+		ArticleID: article.ID,
+		Slug:      article.Slug,
+		Token: ps.signer.Sign(preview.Claims{
+			SiteID:    tenant.SiteID(ctx),
+			ArticleID: article.ID,
+			ExpiresAt: expiresAt,
+		}),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+/*
+GetPreviewArticle retrieves the article of the site held by the context previewed with
+the preview token, whether it is published or not.
+
+`preview.ErrInvalidToken` is returned (wrapped) if the token is invalid, expired or
+previews another article, and `repository.ErrNotFound` (wrapped) if the article no
+longer exists.
+*/
+func (ps *PreviewServiceImpl) GetPreviewArticle(
+	ctx context.Context,
+	articleID uuid.UUID,
+	token string,
+) (models.Article, error) {
+	siteID := tenant.SiteID(ctx)
+
+	claims, err := ps.signer.Verify(token, ps.clock.Now())
+	if err == nil && (claims.SiteID != siteID || claims.ArticleID != articleID) {
+		err = preview.ErrInvalidToken
+	}
+	if err != nil {
+		return models.Article{}, fmt.Errorf("unable to verify preview token: %w", err)
+	}
+
+	article, err := ps.articles.Get(ctx, siteID, articleID)
+	if err != nil {
+		return models.Article{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	return article, nil
+}
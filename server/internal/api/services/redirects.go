@@ -0,0 +1,187 @@
+/*
+Package services provides operations for managing the redirects of the sites.
+
+The primary interface, `RedirectService`, defines methods to manage the redirects
+served by a site, e.g. when the slug of an article changes or when content is migrated
+from an old site, and to resolve the redirect of a path. The `RedirectServiceImpl`
+struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// RedirectService defines the methods for managing the redirects of the sites.
+type RedirectService interface {
+	// GetAllRedirects retrieves every redirect of the site.
+	GetAllRedirects(ctx context.Context) ([]models.Redirect, error)
+
+	// GetRedirectByID fetches a redirect by its unique ID.
+	GetRedirectByID(ctx context.Context, id uuid.UUID) (models.Redirect, error)
+
+	// CreateRedirect creates a new redirect of the site.
+	CreateRedirect(
+		ctx context.Context,
+		redirect models.Redirect,
+	) (models.Redirect, error)
+
+	// UpdateRedirect updates the source, target and status code of a redirect.
+	UpdateRedirect(
+		ctx context.Context,
+		id uuid.UUID,
+		redirect models.Redirect,
+	) (models.Redirect, error)
+
+	// DeleteRedirect removes a redirect identified by its unique ID.
+	DeleteRedirect(ctx context.Context, id uuid.UUID) error
+
+	// ResolveRedirect returns the redirect of the path of the site.
+	ResolveRedirect(ctx context.Context, path string) (models.Redirect, error)
+}
+
+// RedirectServiceImpl is the concrete implementation of the RedirectService
+// interface.
+type RedirectServiceImpl struct {
+	redirects repository.RedirectRepository
+	ids       IDGenerator
+}
+
+// NewRedirectService creates and returns a new instance of RedirectServiceImpl backed
+// by the given redirect repository.
+func NewRedirectService(
+	redirects repository.RedirectRepository,
+	ids IDGenerator,
+) *RedirectServiceImpl {
+	return &RedirectServiceImpl{redirects: redirects, ids: ids}
+}
+
+// GetAllRedirects retrieves every redirect of the site held by the context.
+func (rs *RedirectServiceImpl) GetAllRedirects(
+	ctx context.Context,
+) ([]models.Redirect, error) {
+	redirects, err := rs.redirects.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.Redirect{}, fmt.Errorf("unable to fetch redirects: %w", err)
+	}
+
+	return redirects, nil
+}
+
+// GetRedirectByID fetches a redirect of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such redirect exists.
+func (rs *RedirectServiceImpl) GetRedirectByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Redirect, error) {
+	redirect, err := rs.redirects.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Redirect{}, fmt.Errorf("unable to fetch redirect %s: %w", id, err)
+	}
+
+	return redirect, nil
+}
+
+/*
+CreateRedirect creates a new redirect in the site held by the context, permanent
+unless its status code says otherwise.
+
+`repository.ErrConflict` is returned (wrapped) if the source is already redirected.
+*/
+func (rs *RedirectServiceImpl) CreateRedirect(
+	ctx context.Context,
+	redirect models.Redirect,
+) (models.Redirect, error) {
+	redirectID := rs.ids.NewID()
+
+	redirect.ID = redirectID
+	redirect.SiteID = tenant.SiteID(ctx)
+	normalizeRedirect(&redirect)
+
+	if err := rs.redirects.Create(ctx, redirect); err != nil {
+		return models.Redirect{}, fmt.Errorf("unable to create redirect: %w", err)
+	}
+
+	return redirect, nil
+}
+
+/*
+UpdateRedirect updates the source, target and status code of an existing redirect of
+the site held by the context.
+
+`repository.ErrNotFound` is returned (wrapped) if no such redirect exists, or
+`repository.ErrConflict` if the source is already redirected by another redirect.
+*/
+func (rs *RedirectServiceImpl) UpdateRedirect(
+	ctx context.Context,
+	id uuid.UUID,
+	redirect models.Redirect,
+) (models.Redirect, error) {
+	redirect.ID = id
+	redirect.SiteID = tenant.SiteID(ctx)
+	normalizeRedirect(&redirect)
+
+	if err := rs.redirects.Update(ctx, redirect); err != nil {
+		return models.Redirect{}, fmt.Errorf(
+			"unable to update redirect %s: %w", id, err,
+		)
+	}
+
+	return redirect, nil
+}
+
+// DeleteRedirect removes a redirect of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such redirect exists.
+func (rs *RedirectServiceImpl) DeleteRedirect(ctx context.Context, id uuid.UUID) error {
+	if err := rs.redirects.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete redirect %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// ResolveRedirect returns the redirect of the path of the site held by the context,
+// wrapping `repository.ErrNotFound` if the path is not redirected.
+func (rs *RedirectServiceImpl) ResolveRedirect(
+	ctx context.Context,
+	path string,
+) (models.Redirect, error) {
+	siteID := tenant.SiteID(ctx)
+
+	redirect, err := rs.redirects.GetBySource(ctx, siteID, cleanPath(path))
+	if err != nil {
+		return models.Redirect{}, fmt.Errorf(
+			"unable to resolve redirect %q: %w", path, err,
+		)
+	}
+
+	return redirect, nil
+}
+
+// normalizeRedirect cleans the source of the redirect and defaults its status code to
+// a permanent redirect.
+func normalizeRedirect(redirect *models.Redirect) {
+	redirect.Source = cleanPath(redirect.Source)
+	if redirect.StatusCode == 0 {
+		redirect.StatusCode = http.StatusMovedPermanently
+	}
+}
+
+// cleanPath returns the path without its trailing slash (if any), so that `/old` and
+// `/old/` are redirected alike.
+func cleanPath(path string) string {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	return path
+}
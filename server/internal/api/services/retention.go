@@ -0,0 +1,168 @@
+/*
+Package services provides operations for the retention policy of the sites.
+
+The primary interface, `RetentionService`, defines methods to report what the retention
+policy would remove from a site (a dry run) and to apply it, removing the revisions of
+the articles but their latest ones and the entries of the audit log past their
+retention period. The `RetentionServiceImpl` struct provides the concrete
+implementation of these methods.
+
+The policy is applied by a scheduled job of the server, to bound the storage growth of
+the busy sites.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// RetentionService defines the methods for the retention policy of the sites.
+type RetentionService interface {
+	// GetRetentionReport reports what the retention policy would remove from the site,
+	// without removing anything.
+	GetRetentionReport(ctx context.Context) (models.RetentionReport, error)
+
+	// ApplyRetention removes what the retention policy removes from the site,
+	// reporting what was removed.
+	ApplyRetention(ctx context.Context) (models.RetentionReport, error)
+}
+
+// RetentionServiceImpl is the concrete implementation of the RetentionService
+// interface.
+type RetentionServiceImpl struct {
+	revisions repository.RevisionRepository
+	audit     repository.AuditRepository
+	policy    models.RetentionPolicy
+	clock     Clock
+}
+
+// NewRetentionService creates and returns a new instance of RetentionServiceImpl
+// backed by the given repositories, applying the given policy with the time told by
+// the given clock.
+func NewRetentionService(
+	revisions repository.RevisionRepository,
+	audit repository.AuditRepository,
+	policy models.RetentionPolicy,
+	clock Clock,
+) *RetentionServiceImpl {
+	return &RetentionServiceImpl{
+		revisions: revisions,
+		audit:     audit,
+		policy:    policy,
+		clock:     clock,
+	}
+}
+
+/*
+GetRetentionReport reports what the retention policy would remove from the site held
+by the context if it was applied now, without removing anything.
+
+The report of a context holding no site covers the entries of the audit log of the
+requests managing the sites themselves.
+*/
+func (rs *RetentionServiceImpl) GetRetentionReport(
+	ctx context.Context,
+) (models.RetentionReport, error) {
+	siteID := tenant.SiteID(ctx)
+	report := rs.report(true)
+
+	if keep := rs.policy.RevisionsPerArticle; keep > 0 {
+		revisions, err := rs.revisions.ListPrunable(ctx, siteID, keep)
+		if err != nil {
+			return models.RetentionReport{}, fmt.Errorf(
+				"unable to fetch prunable revisions: %w", err,
+			)
+		}
+		reportRevisions(&report, revisions)
+	}
+
+	if report.AuditCutoff != nil {
+		entries, err := rs.audit.ListBefore(ctx, siteID, *report.AuditCutoff)
+		if err != nil {
+			return models.RetentionReport{}, fmt.Errorf(
+				"unable to fetch expired audit entries: %w", err,
+			)
+		}
+		report.AuditEntries = len(entries)
+	}
+
+	return report, nil
+}
+
+/*
+ApplyRetention removes the revisions of the articles of the site held by the context
+but their latest ones and the entries of its audit log past their retention period, as
+set by the retention policy, reporting what was removed. The changes and the
+comparisons of the removed revisions can not be retrieved anymore.
+
+A context holding no site applies the policy to the entries of the audit log of the
+requests managing the sites themselves.
+*/
+func (rs *RetentionServiceImpl) ApplyRetention(
+	ctx context.Context,
+) (models.RetentionReport, error) {
+	siteID := tenant.SiteID(ctx)
+	report := rs.report(false)
+
+	if keep := rs.policy.RevisionsPerArticle; keep > 0 {
+		revisions, err := rs.revisions.Prune(ctx, siteID, keep)
+		if err != nil {
+			return report, fmt.Errorf("unable to prune revisions: %w", err)
+		}
+		reportRevisions(&report, revisions)
+	}
+
+	if report.AuditCutoff != nil {
+		deleted, err := rs.audit.DeleteBefore(ctx, siteID, *report.AuditCutoff)
+		if err != nil {
+			return report, fmt.Errorf("unable to prune audit log: %w", err)
+		}
+		report.AuditEntries = deleted
+	}
+
+	return report, nil
+}
+
+// report returns an empty report of the retention policy, with the cutoff of the
+// audit log as of now.
+func (rs *RetentionServiceImpl) report(dryRun bool) models.RetentionReport {
+	report := models.RetentionReport{
+		Policy:   rs.policy,
+		DryRun:   dryRun,
+		Articles: []models.ArticleRetention{},
+	}
+
+	if days := rs.policy.AuditLogDays; days > 0 {
+		cutoff := rs.clock.Now().Add(-time.Duration(days) * 24 * time.Hour)
+		report.AuditCutoff = &cutoff
+	}
+
+	return report
+}
+
+// reportRevisions adds the removed revisions to the report, grouped by article.
+func reportRevisions(report *models.RetentionReport, revisions []models.Revision) {
+	for _, revision := range revisions {
+		i := slices.IndexFunc(report.Articles, func(a models.ArticleRetention) bool {
+			return a.ArticleID == revision.ArticleID
+		})
+		if i < 0 {
+			report.Articles = append(report.Articles, models.ArticleRetention{
+				ArticleID: revision.ArticleID,
+				Numbers:   []int{},
+			})
+			i = len(report.Articles) - 1
+		}
+
+		report.Articles[i].Revisions++
+		report.Articles[i].Numbers = append(report.Articles[i].Numbers, revision.Number)
+		report.Revisions++
+	}
+}
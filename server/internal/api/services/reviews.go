@@ -0,0 +1,382 @@
+/*
+Package services provides operations for the editorial review of the articles.
+
+The primary interface, `ReviewService`, defines methods to submit an article for
+review, to assign it a reviewer, and to approve it or request changes on it. The
+author of the article is notified of each decision, by email and with an
+`article.reviewed` event. The `ReviewServiceImpl` struct provides the concrete
+implementation of these methods.
+
+The sites requiring the articles to be reviewed (see `SiteSettings.RequireReview`)
+only publish the articles at the version which was approved, which the
+`ArticleService` enforces.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+var (
+	// ErrNotApproved is returned when an article is published without having been
+	// approved, while its site requires the articles to be reviewed.
+	ErrNotApproved = errors.New("article not approved for publication")
+
+	// ErrReviewState is returned when a review action is not allowed by the status of
+	// the review (e.g. approving an article which is not pending review).
+	ErrReviewState = errors.New("review action not allowed in the current status")
+
+	// ErrReviewerNotFound is returned when the user assigned to review an article does
+	// not exist within the site.
+	ErrReviewerNotFound = errors.New("reviewer not found")
+
+	// ErrNotReviewer is returned when a decision is made on an article by another user
+	// than its assigned reviewer (or an admin).
+	ErrNotReviewer = errors.New("not the reviewer of the article")
+)
+
+// ReviewService defines the methods for the editorial review of the articles.
+type ReviewService interface {
+	// GetReview retrieves the review of the article.
+	GetReview(ctx context.Context, articleID uuid.UUID) (models.Review, error)
+
+	// SubmitForReview submits the article for review.
+	SubmitForReview(ctx context.Context, articleID uuid.UUID) (models.Review, error)
+
+	// AssignReviewer assigns a user to review the article.
+	AssignReviewer(
+		ctx context.Context,
+		articleID, reviewerID uuid.UUID,
+	) (models.Review, error)
+
+	// Approve approves the article for publication, with an optional comment.
+	Approve(
+		ctx context.Context,
+		articleID uuid.UUID,
+		comment string,
+	) (models.Review, error)
+
+	// RequestChanges requests changes on the article, explained by the comment.
+	RequestChanges(
+		ctx context.Context,
+		articleID uuid.UUID,
+		comment string,
+	) (models.Review, error)
+}
+
+// ReviewServiceImpl is the concrete implementation of the ReviewService interface.
+type ReviewServiceImpl struct {
+	reviews   repository.ReviewRepository
+	articles  repository.ArticleRepository
+	users     repository.UserRepository
+	mailer    mailer.Mailer
+	templates TemplateProvider
+	events    EventPublisher
+	ids       IDGenerator
+	clock     Clock
+}
+
+/*
+NewReviewService creates and returns a new instance of ReviewServiceImpl backed by the
+given repositories, notifying the authors of the articles of the decisions with the
+given mailer (rendering the emails with the templates of the site) and publisher. The
+new reviews are assigned their IDs by the given generator, and the reviews are stamped
+with the time told by the given clock.
+*/
+func NewReviewService(
+	reviews repository.ReviewRepository,
+	articles repository.ArticleRepository,
+	users repository.UserRepository,
+	mailer mailer.Mailer,
+	templates TemplateProvider,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *ReviewServiceImpl {
+	return &ReviewServiceImpl{
+		reviews:   reviews,
+		articles:  articles,
+		users:     users,
+		mailer:    mailer,
+		templates: templates,
+		events:    events,
+		ids:       ids,
+		clock:     clock,
+	}
+}
+
+/*
+GetReview retrieves the review of the article of the site held by the context.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists or if it was
+never submitted for review.
+*/
+func (rs *ReviewServiceImpl) GetReview(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.Review, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if _, err := rs.articles.Get(ctx, siteID, articleID); err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	review, err := rs.reviews.GetByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch review of article %s: %w", articleID, err,
+		)
+	}
+
+	return review, nil
+}
+
+/*
+SubmitForReview submits the article of the site held by the context for review: its
+review is started if it was never submitted, or is pending again otherwise (e.g. once
+the requested changes are made), keeping its reviewer.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists, and
+`ErrReviewState` if the article is already pending review.
+*/
+func (rs *ReviewServiceImpl) SubmitForReview(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.Review, error) {
+	siteID := tenant.SiteID(ctx)
+	now := rs.clock.Now()
+
+	if _, err := rs.articles.Get(ctx, siteID, articleID); err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	review, err := rs.reviews.GetByArticle(ctx, siteID, articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		review = models.Review{
+			ID:          rs.ids.NewID(),
+			SiteID:      siteID,
+			ArticleID:   articleID,
+			Status:      models.ReviewPending,
+			SubmittedAt: now,
+			UpdatedAt:   now,
+			Comments:    []models.ReviewComment{},
+		}
+		if err := rs.reviews.Create(ctx, review); err != nil {
+			return models.Review{}, fmt.Errorf("unable to create review: %w", err)
+		}
+
+		return review, nil
+	} else if err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch review of article %s: %w", articleID, err,
+		)
+	}
+
+	if review.Status == models.ReviewPending {
+		return models.Review{}, ErrReviewState
+	}
+
+	review.Status = models.ReviewPending
+	review.ApprovedVersion = 0
+	review.SubmittedAt = now
+	review.UpdatedAt = now
+
+	if err := rs.reviews.Update(ctx, review); err != nil {
+		return models.Review{}, fmt.Errorf("unable to update review: %w", err)
+	}
+
+	return review, nil
+}
+
+/*
+AssignReviewer assigns the user of the site held by the context identified by
+reviewerID to review the article, replacing its previous reviewer if any.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists or if it was
+never submitted for review, and `ErrReviewerNotFound` if no such user exists.
+*/
+func (rs *ReviewServiceImpl) AssignReviewer(
+	ctx context.Context,
+	articleID, reviewerID uuid.UUID,
+) (models.Review, error) {
+	siteID := tenant.SiteID(ctx)
+
+	review, err := rs.GetReview(ctx, articleID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	_, err = rs.users.Get(ctx, siteID, reviewerID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Review{}, ErrReviewerNotFound
+	} else if err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch user %s: %w", reviewerID, err,
+		)
+	}
+
+	review.ReviewerID = &reviewerID
+	review.UpdatedAt = rs.clock.Now()
+
+	if err := rs.reviews.Update(ctx, review); err != nil {
+		return models.Review{}, fmt.Errorf("unable to update review: %w", err)
+	}
+
+	return review, nil
+}
+
+/*
+Approve approves the article of the site held by the context for publication at its
+current version, with an optional comment, and notifies its author.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists or if it was
+never submitted for review, `ErrReviewState` if it is not pending review, and
+`ErrNotReviewer` if the principal making the decision is neither its reviewer nor an
+admin.
+*/
+func (rs *ReviewServiceImpl) Approve(
+	ctx context.Context,
+	articleID uuid.UUID,
+	comment string,
+) (models.Review, error) {
+	return rs.decide(ctx, articleID, models.ReviewApproved, comment)
+}
+
+/*
+RequestChanges requests changes on the article of the site held by the context,
+explained by the comment, and notifies its author. The article has to be submitted for
+review again once changed.
+
+It returns the same errors as `Approve`.
+*/
+func (rs *ReviewServiceImpl) RequestChanges(
+	ctx context.Context,
+	articleID uuid.UUID,
+	comment string,
+) (models.Review, error) {
+	return rs.decide(ctx, articleID, models.ReviewChangesRequested, comment)
+}
+
+// decide records the decision (the new status of the review) made on the article
+// pending review, and notifies its author.
+func (rs *ReviewServiceImpl) decide(
+	ctx context.Context,
+	articleID uuid.UUID,
+	status, comment string,
+) (models.Review, error) {
+	siteID := tenant.SiteID(ctx)
+	now := rs.clock.Now()
+
+	article, err := rs.articles.Get(ctx, siteID, articleID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	review, err := rs.reviews.GetByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf(
+			"unable to fetch review of article %s: %w", articleID, err,
+		)
+	}
+
+	if review.Status != models.ReviewPending {
+		return models.Review{}, ErrReviewState
+	}
+
+	principal, _ := auth.FromContext(ctx)
+	if review.ReviewerID != nil && *review.ReviewerID != principal.UserID &&
+		!principal.HasRole(auth.RoleAdmin) {
+		return models.Review{}, ErrNotReviewer
+	}
+
+	decision := models.ReviewComment{
+		Status:    status,
+		Content:   comment,
+		Version:   article.Version,
+		CreatedAt: now,
+	}
+	if principal.UserID != uuid.Nil {
+		decision.UserID = &principal.UserID
+	}
+
+	review.Status = status
+	if status == models.ReviewApproved {
+		review.ApprovedVersion = article.Version
+	}
+	review.UpdatedAt = now
+	review.Comments = append(slices.Clone(review.Comments), decision)
+
+	if err := rs.reviews.Update(ctx, review); err != nil {
+		return models.Review{}, fmt.Errorf("unable to update review: %w", err)
+	}
+
+	rs.events.Publish(siteID, "article.reviewed", map[string]any{
+		"article": article,
+		"review":  review,
+	})
+	rs.notifyAuthor(ctx, article, decision)
+
+	return review, nil
+}
+
+// notifyAuthor emails the decision made on the article to its author, i.e. the users
+// of the site named after the author of the article (if any). The decision stands even
+// if the email can not be sent, the author being notified by the event anyway.
+func (rs *ReviewServiceImpl) notifyAuthor(
+	ctx context.Context,
+	article models.Article,
+	decision models.ReviewComment,
+) {
+	site, _ := tenant.FromContext(ctx)
+
+	users, err := rs.users.List(ctx, site.ID)
+	if err != nil {
+		return
+	}
+
+	templates, err := rs.templates.ActiveTemplates(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, user := range users {
+		if user.Name != article.Author {
+			continue
+		}
+
+		email, err := mailer.RenderWith(
+			templates,
+			"review_notification",
+			[]string{user.Email},
+			map[string]any{
+				"SiteName":     site.Name,
+				"Name":         user.Name,
+				"ArticleTitle": article.Title,
+				"Approved":     decision.Status == models.ReviewApproved,
+				"Comment":      decision.Content,
+			},
+		)
+		if err != nil {
+			return
+		}
+
+		_ = rs.mailer.Send(ctx, email)
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Package services provides operations for browsing the revisions of the articles.
+
+The primary interface, `RevisionService`, defines methods to list the revisions of an
+article, which are recorded by the `ArticleService` each time the article is created
+or updated, to retrieve the changes made by a revision field by field, and to compare
+two revisions. The `RevisionServiceImpl` struct provides the concrete implementation
+of these methods.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+	"github.com/Weburz/burzcontent/server/internal/textdiff"
+)
+
+// RevisionService defines the methods for browsing the revisions of the articles.
+type RevisionService interface {
+	// GetRevisions retrieves the revisions of the article, oldest first.
+	GetRevisions(ctx context.Context, articleID uuid.UUID) ([]models.Revision, error)
+
+	// GetRevisionChanges retrieves the changes made by a revision of the article.
+	GetRevisionChanges(
+		ctx context.Context,
+		articleID uuid.UUID,
+		number int,
+	) ([]models.FieldChange, error)
+
+	// DiffRevisions compares two revisions of the article, also in the unified format
+	// if requested.
+	DiffRevisions(
+		ctx context.Context,
+		articleID uuid.UUID,
+		from, to int,
+		unified bool,
+	) (models.RevisionDiff, error)
+}
+
+// RevisionServiceImpl is the concrete implementation of the RevisionService interface.
+type RevisionServiceImpl struct {
+	revisions repository.RevisionRepository
+	articles  repository.ArticleRepository
+}
+
+// NewRevisionService creates and returns a new instance of RevisionServiceImpl backed
+// by the given repositories.
+func NewRevisionService(
+	revisions repository.RevisionRepository,
+	articles repository.ArticleRepository,
+) *RevisionServiceImpl {
+	return &RevisionServiceImpl{
+		revisions: revisions,
+		articles:  articles,
+	}
+}
+
+/*
+GetRevisions retrieves the revisions of the article of the site held by the context,
+oldest first. The articles which were not created or updated through the API (e.g.
+imported ones) only have the revisions made since.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists.
+*/
+func (rs *RevisionServiceImpl) GetRevisions(
+	ctx context.Context,
+	articleID uuid.UUID,
+) ([]models.Revision, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if _, err := rs.articles.Get(ctx, siteID, articleID); err != nil {
+		return []models.Revision{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	revisions, err := rs.revisions.ListByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return []models.Revision{}, fmt.Errorf("unable to fetch revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+/*
+GetRevisionChanges retrieves the changes made by the revision of the article of the
+site held by the context with the given number, field by field.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article or revision exists.
+*/
+func (rs *RevisionServiceImpl) GetRevisionChanges(
+	ctx context.Context,
+	articleID uuid.UUID,
+	number int,
+) ([]models.FieldChange, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if _, err := rs.articles.Get(ctx, siteID, articleID); err != nil {
+		return []models.FieldChange{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	revision, err := rs.revisions.GetByNumber(ctx, siteID, articleID, number)
+	if err != nil {
+		return []models.FieldChange{}, fmt.Errorf(
+			"unable to fetch revision %d of article %s: %w", number, articleID, err,
+		)
+	}
+
+	return revision.Changes, nil
+}
+
+/*
+DiffRevisions compares the revisions of the article of the site held by the context
+with the given numbers, field by field and, if unified is set, line by line in the
+unified format (see `revisionLines`). The revisions can be compared in any order.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article or revision exists.
+*/
+func (rs *RevisionServiceImpl) DiffRevisions(
+	ctx context.Context,
+	articleID uuid.UUID,
+	from, to int,
+	unified bool,
+) (models.RevisionDiff, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if _, err := rs.articles.Get(ctx, siteID, articleID); err != nil {
+		return models.RevisionDiff{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	var revisions [2]models.Revision
+	for i, number := range []int{from, to} {
+		revision, err := rs.revisions.GetByNumber(ctx, siteID, articleID, number)
+		if err != nil {
+			return models.RevisionDiff{}, fmt.Errorf(
+				"unable to fetch revision %d of article %s: %w", number, articleID, err,
+			)
+		}
+		revisions[i] = revision
+	}
+
+	diff := models.RevisionDiff{
+		ArticleID: articleID,
+		From:      from,
+		To:        to,
+		Changes:   articleChanges(revisions[0].Article, revisions[1].Article),
+	}
+	if unified {
+		diff.Unified = textdiff.Unified(
+			fmt.Sprintf("revision %d", from),
+			fmt.Sprintf("revision %d", to),
+			revisionLines(revisions[0].Article),
+			revisionLines(revisions[1].Article),
+		)
+	}
+
+	return diff, nil
+}
+
+/*
+articleChanges returns the changes of the fields of an article between two of its
+versions, in the order of the fields of the article. The fields maintained by the
+system (e.g. `updated_at` or `version`) are left out.
+*/
+func articleChanges(from, to models.Article) []models.FieldChange {
+	changes := []models.FieldChange{}
+	add := func(field, label string, before, after any, changed bool) {
+		if !changed {
+			return
+		}
+
+		changes = append(changes, models.FieldChange{
+			Field:   field,
+			Old:     before,
+			New:     after,
+			Summary: label + " changed",
+		})
+	}
+
+	add("title", "Title", from.Title, to.Title, from.Title != to.Title)
+	add("author", "Author", from.Author, to.Author, from.Author != to.Author)
+	if from.IsPublished != to.IsPublished {
+		summary := "Unpublished"
+		if to.IsPublished {
+			summary = "Published"
+		}
+
+		changes = append(changes, models.FieldChange{
+			Field:   "isPublished",
+			Old:     from.IsPublished,
+			New:     to.IsPublished,
+			Summary: summary,
+		})
+	}
+	add("slug", "Slug", from.Slug, to.Slug, from.Slug != to.Slug)
+	if from.Content != to.Content {
+		changes = append(changes, models.FieldChange{
+			Field:   "content",
+			Old:     from.Content,
+			New:     to.Content,
+			Summary: contentSummary(from.Content, to.Content),
+		})
+	}
+	add("tags", "Tags", from.Tags, to.Tags, !slices.Equal(from.Tags, to.Tags))
+	add(
+		"published_at",
+		"Publication date",
+		from.PublishedAt,
+		to.PublishedAt,
+		!equalTimes(from.PublishedAt, to.PublishedAt),
+	)
+	add(
+		"expires_at",
+		"Expiry date",
+		from.ExpiresAt,
+		to.ExpiresAt,
+		!equalTimes(from.ExpiresAt, to.ExpiresAt),
+	)
+	add(
+		"publish_at",
+		"Scheduled publication date",
+		from.PublishAt,
+		to.PublishAt,
+		!equalTimes(from.PublishAt, to.PublishAt),
+	)
+
+	return changes
+}
+
+// revisionLines renders the article of a revision as lines of text to compare it with
+// another revision, i.e. a line for each field of its metadata (e.g. "title: Go
+// Basics"), followed by an empty line and the lines of its content.
+func revisionLines(article models.Article) []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+
+		return t.Format(time.RFC3339)
+	}
+
+	lines := []string{
+		"title: " + article.Title,
+		"author: " + article.Author,
+		"isPublished: " + strconv.FormatBool(article.IsPublished),
+		"slug: " + article.Slug,
+		"tags: " + strings.Join(article.Tags, ", "),
+		"published_at: " + formatTime(article.PublishedAt),
+		"expires_at: " + formatTime(article.ExpiresAt),
+		"publish_at: " + formatTime(article.PublishAt),
+		"",
+	}
+
+	return append(lines, textdiff.SplitLines(article.Content)...)
+}
+
+// paragraphSeparator separates the paragraphs of the content of an article, whether
+// it is written in HTML or in plain text (or Markdown).
+var paragraphSeparator = regexp.MustCompile(`(?i)\n\s*\n|</p>`)
+
+// contentSummary summarizes the change of the content of an article by the number of
+// paragraphs added and removed (an edited paragraph counting as both).
+func contentSummary(from, to string) string {
+	// The paragraphs of the new content which are not in the old one were added, and
+	// the remaining ones of the old content were removed
+	remaining := paragraphs(from)
+
+	added := 0
+	for _, p := range paragraphs(to) {
+		if i := slices.Index(remaining, p); i >= 0 {
+			remaining = slices.Delete(remaining, i, i+1)
+		} else {
+			added++
+		}
+	}
+	removed := len(remaining)
+
+	var parts []string
+	if added > 0 {
+		parts = append(parts, countParagraphs(added)+" added")
+	}
+	if removed > 0 {
+		parts = append(parts, countParagraphs(removed)+" removed")
+	}
+	if len(parts) == 0 {
+		// Only the spacing between the paragraphs changed
+		return "Content changed"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// paragraphs returns the (trimmed) non-empty paragraphs of the content of an article.
+func paragraphs(content string) []string {
+	var paragraphs []string
+	for _, p := range paragraphSeparator.Split(content, -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+
+	return paragraphs
+}
+
+// countParagraphs returns the given number of paragraphs in words (e.g. "1 paragraph"
+// or "2 paragraphs").
+func countParagraphs(n int) string {
+	if n == 1 {
+		return "1 paragraph"
+	}
+
+	return fmt.Sprintf("%d paragraphs", n)
+}
+
+// equalTimes reports whether two optional times are both unset or the same instant.
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
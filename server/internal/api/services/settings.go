@@ -0,0 +1,96 @@
+/*
+Package services provides operations for managing the settings of the sites.
+
+The primary interface, `SettingsService`, defines methods to read and update the
+site-wide configuration of a site. The `SettingsServiceImpl` struct provides the
+concrete implementation of these methods, which publishes a `settings.updated` event
+whenever the settings of a site change.
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// SettingsService defines the methods for managing the settings of the sites.
+type SettingsService interface {
+	// GetSettings retrieves the effective settings of the site.
+	GetSettings(ctx context.Context) (models.SiteSettings, error)
+
+	// UpdateSettings updates the fields of the settings of the site set by the patch.
+	UpdateSettings(
+		ctx context.Context,
+		patch models.SiteSettingsPatch,
+	) (models.SiteSettings, error)
+}
+
+// SettingsServiceImpl is the concrete implementation of the SettingsService interface.
+type SettingsServiceImpl struct {
+	sites  repository.SiteRepository
+	events EventPublisher
+}
+
+// NewSettingsService creates and returns a new instance of SettingsServiceImpl backed
+// by the given site repository, publishing the changes of the settings to events.
+func NewSettingsService(
+	sites repository.SiteRepository,
+	events EventPublisher,
+) *SettingsServiceImpl {
+	return &SettingsServiceImpl{sites: sites, events: events}
+}
+
+// GetSettings retrieves the settings of the site held by the context, with the default
+// value of every field which is not set.
+func (ss *SettingsServiceImpl) GetSettings(
+	ctx context.Context,
+) (models.SiteSettings, error) {
+	siteID := tenant.SiteID(ctx)
+
+	site, err := ss.sites.Get(ctx, siteID)
+	if err != nil {
+		return models.SiteSettings{}, fmt.Errorf(
+			"unable to fetch site %s: %w", siteID, err,
+		)
+	}
+
+	return site.EffectiveSettings(), nil
+}
+
+/*
+UpdateSettings updates the fields of the settings of the site held by the context which
+are set by the patch, and returns the effective settings of the site.
+
+A `settings.updated` event holding the effective settings is published to the site, so
+that the subscribers relying on the settings pick up the change.
+*/
+func (ss *SettingsServiceImpl) UpdateSettings(
+	ctx context.Context,
+	patch models.SiteSettingsPatch,
+) (models.SiteSettings, error) {
+	siteID := tenant.SiteID(ctx)
+
+	site, err := ss.sites.Get(ctx, siteID)
+	if err != nil {
+		return models.SiteSettings{}, fmt.Errorf(
+			"unable to fetch site %s: %w", siteID, err,
+		)
+	}
+
+	site.Settings = patch.Apply(site.Settings)
+
+	if err := ss.sites.Update(ctx, site); err != nil {
+		return models.SiteSettings{}, fmt.Errorf(
+			"unable to update settings of site %s: %w", siteID, err,
+		)
+	}
+
+	settings := site.EffectiveSettings()
+	ss.events.Publish(siteID, "settings.updated", settings)
+
+	return settings, nil
+}
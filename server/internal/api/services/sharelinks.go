@@ -0,0 +1,235 @@
+/*
+Package services provides operations for sharing the articles through expiring links.
+
+The primary interface, `ShareLinkService`, defines methods to create, list and revoke
+the links sharing an article (typically a draft) with readers who can not access the
+management API, and to resolve the article shared by a link. The
+`ShareLinkServiceImpl` struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrLifetimeExceeded is returned when the requested lifetime of a share link exceeds
+// the maximum lifetime of the share links.
+var ErrLifetimeExceeded = errors.New("share link lifetime exceeds the maximum")
+
+// ShareLinkService defines the methods for sharing the articles through links.
+type ShareLinkService interface {
+	// GetShareLinks retrieves the outstanding share links of the article.
+	GetShareLinks(ctx context.Context, articleID uuid.UUID) ([]models.ShareLink, error)
+
+	// CreateShareLink creates a new link sharing the article, returning the link along
+	// with its plain text token.
+	CreateShareLink(
+		ctx context.Context,
+		articleID uuid.UUID,
+		lifetime time.Duration,
+		oneTime bool,
+	) (models.ShareLink, string, error)
+
+	// RevokeShareLink revokes a share link of the article.
+	RevokeShareLink(ctx context.Context, articleID, id uuid.UUID) error
+
+	// GetSharedArticle returns the article shared by the link with the given token.
+	GetSharedArticle(ctx context.Context, token string) (models.Article, error)
+}
+
+// ShareLinkServiceImpl is the concrete implementation of the ShareLinkService
+// interface.
+type ShareLinkServiceImpl struct {
+	links       repository.ShareLinkRepository
+	articles    repository.ArticleRepository
+	maxLifetime time.Duration
+	ids         IDGenerator
+	clock       Clock
+}
+
+// NewShareLinkService creates and returns a new instance of ShareLinkServiceImpl
+// backed by the given repositories, whose links can not outlive maxLifetime and expire
+// as told by the given clock.
+func NewShareLinkService(
+	links repository.ShareLinkRepository,
+	articles repository.ArticleRepository,
+	maxLifetime time.Duration,
+	ids IDGenerator,
+	clock Clock,
+) *ShareLinkServiceImpl {
+	return &ShareLinkServiceImpl{
+		links:       links,
+		articles:    articles,
+		maxLifetime: maxLifetime,
+		ids:         ids,
+		clock:       clock,
+	}
+}
+
+/*
+GetShareLinks retrieves the outstanding share links of the article of the site held by
+the context, i.e. the links which are neither expired nor revoked.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists.
+*/
+func (ls *ShareLinkServiceImpl) GetShareLinks(
+	ctx context.Context,
+	articleID uuid.UUID,
+) ([]models.ShareLink, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if _, err := ls.articles.Get(ctx, siteID, articleID); err != nil {
+		return []models.ShareLink{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	links, err := ls.links.ListByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return []models.ShareLink{}, fmt.Errorf("unable to fetch share links: %w", err)
+	}
+
+	now := ls.clock.Now()
+	return slices.DeleteFunc(links, func(l models.ShareLink) bool {
+		return !now.Before(l.ExpiresAt)
+	}), nil
+}
+
+/*
+CreateShareLink creates a new link sharing the article of the site held by the
+context, which expires after the lifetime (the maximum lifetime if zero) and, if
+oneTime is set, as soon as it is used.
+
+The plain text token is only returned once, by this method; the repository only
+stores its digest. `ErrLifetimeExceeded` is returned if the lifetime exceeds the
+maximum lifetime and `repository.ErrNotFound` (wrapped) if no such article exists.
+*/
+func (ls *ShareLinkServiceImpl) CreateShareLink(
+	ctx context.Context,
+	articleID uuid.UUID,
+	lifetime time.Duration,
+	oneTime bool,
+) (models.ShareLink, string, error) {
+	if lifetime > ls.maxLifetime {
+		return models.ShareLink{}, "", ErrLifetimeExceeded
+	} else if lifetime <= 0 {
+		lifetime = ls.maxLifetime
+	}
+
+	siteID := tenant.SiteID(ctx)
+	if _, err := ls.articles.Get(ctx, siteID, articleID); err != nil {
+		return models.ShareLink{}, "", fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	linkID := ls.ids.NewID()
+
+	token := rand.Text()
+	now := ls.clock.Now()
+	link := models.ShareLink{
+		ID:        linkID,
+		SiteID:    siteID,
+		ArticleID: articleID,
+		Prefix:    token[:6],
+		OneTime:   oneTime,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+		Hash:      hashToken(token),
+	}
+
+	if err := ls.links.Create(ctx, link); err != nil {
+		return models.ShareLink{}, "", fmt.Errorf(
+			"unable to create share link: %w", err,
+		)
+	}
+
+	return link, token, nil
+}
+
+// RevokeShareLink revokes a share link of the article of the site held by the
+// context, wrapping `repository.ErrNotFound` if no such link exists.
+func (ls *ShareLinkServiceImpl) RevokeShareLink(
+	ctx context.Context,
+	articleID, id uuid.UUID,
+) error {
+	siteID := tenant.SiteID(ctx)
+
+	links, err := ls.links.ListByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return fmt.Errorf("unable to fetch share links: %w", err)
+	}
+
+	// The link has to share the article, not only to belong to the site
+	found := slices.ContainsFunc(links, func(l models.ShareLink) bool {
+		return l.ID == id
+	})
+	if !found {
+		return fmt.Errorf(
+			"unable to revoke share link %s: %w", id, repository.ErrNotFound,
+		)
+	}
+
+	if err := ls.links.Delete(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to revoke share link %s: %w", id, err)
+	}
+
+	return nil
+}
+
+/*
+GetSharedArticle returns the article of the site held by the context shared by the
+link with the given token, whether it is published or not.
+
+The one-time links are revoked by this method. `repository.ErrNotFound` is returned
+(wrapped) if the token is unknown, expired or already used, or if the article no
+longer exists.
+*/
+func (ls *ShareLinkServiceImpl) GetSharedArticle(
+	ctx context.Context,
+	token string,
+) (models.Article, error) {
+	siteID := tenant.SiteID(ctx)
+
+	link, err := ls.links.GetByHash(ctx, siteID, hashToken(token))
+	if err == nil && !ls.clock.Now().Before(link.ExpiresAt) {
+		// Purge the expired link on the way
+		_ = ls.links.Delete(ctx, siteID, link.ID)
+		err = repository.ErrNotFound
+	} else if err == nil && link.OneTime {
+		// Only the first of concurrent requests can delete the link
+		err = ls.links.Delete(ctx, siteID, link.ID)
+	}
+
+	if err != nil {
+		return models.Article{}, fmt.Errorf("unable to resolve share link: %w", err)
+	}
+
+	article, err := ls.articles.Get(ctx, siteID, link.ArticleID)
+	if err != nil {
+		return models.Article{}, fmt.Errorf(
+			"unable to fetch article %s: %w", link.ArticleID, err,
+		)
+	}
+
+	return article, nil
+}
+
+// hashToken returns the hex-encoded SHA-256 digest of the token of a share link.
+func hashToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,132 @@
+/*
+Package services provides operations for generating the sitemaps of the sites.
+
+The primary interface, `SitemapService`, defines methods to retrieve the paths listed
+by the sitemap of a site and to regenerate it. The `SitemapServiceImpl` struct provides
+the concrete implementation of these methods.
+
+The sitemaps are generated ahead of the requests for them, by the scheduled jobs of the
+server, rather than for every request: a sitemap is only generated on request if it was
+never generated or is older than `SitemapMaxAge` (e.g. if its job is disabled).
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// SitemapMaxAge is the age of a sitemap past which it is generated again on request.
+const SitemapMaxAge = time.Hour
+
+// SitemapService defines the methods for generating the sitemaps of the sites.
+type SitemapService interface {
+	// GetSitemap retrieves the paths listed by the sitemap of the site.
+	GetSitemap(ctx context.Context) ([]string, error)
+
+	// RegenerateSitemap generates the sitemap of the site again, returning the number
+	// of paths it lists.
+	RegenerateSitemap(ctx context.Context) (int, error)
+}
+
+// sitemap holds the paths listed by the sitemap of a site and when it was generated.
+type sitemap struct {
+	paths       []string
+	generatedAt time.Time
+}
+
+// SitemapServiceImpl is the concrete implementation of the SitemapService interface.
+type SitemapServiceImpl struct {
+	articles ArticleService
+	pages    PageService
+	clock    Clock
+
+	mu       sync.Mutex
+	sitemaps map[uuid.UUID]sitemap
+}
+
+// NewSitemapService creates and returns a new instance of SitemapServiceImpl listing
+// the published articles and pages of the given services.
+func NewSitemapService(
+	articles ArticleService,
+	pages PageService,
+	clock Clock,
+) *SitemapServiceImpl {
+	return &SitemapServiceImpl{
+		articles: articles,
+		pages:    pages,
+		clock:    clock,
+		sitemaps: make(map[uuid.UUID]sitemap),
+	}
+}
+
+/*
+GetSitemap retrieves the paths listed by the sitemap of the site held by the context:
+the home page, the published articles and the published pages of the site, as of the
+last time the sitemap was generated. The sitemap is generated if it never was or if it
+is older than `SitemapMaxAge`.
+*/
+func (ss *SitemapServiceImpl) GetSitemap(ctx context.Context) ([]string, error) {
+	siteID := tenant.SiteID(ctx)
+
+	ss.mu.Lock()
+	cached, ok := ss.sitemaps[siteID]
+	ss.mu.Unlock()
+
+	if ok && ss.clock.Now().Sub(cached.generatedAt) < SitemapMaxAge {
+		return cached.paths, nil
+	}
+
+	generated, err := ss.generate(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return generated.paths, nil
+}
+
+// RegenerateSitemap generates the sitemap of the site held by the context again,
+// returning the number of paths it lists.
+func (ss *SitemapServiceImpl) RegenerateSitemap(ctx context.Context) (int, error) {
+	generated, err := ss.generate(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(generated.paths), nil
+}
+
+// generate generates the sitemap of the site held by the context and caches it.
+func (ss *SitemapServiceImpl) generate(ctx context.Context) (sitemap, error) {
+	generated := sitemap{paths: []string{"/"}, generatedAt: ss.clock.Now()}
+
+	articles, err := ss.articles.GetPublishedArticles(ctx)
+	if err != nil {
+		return sitemap{}, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	for _, article := range articles {
+		generated.paths = append(generated.paths, "/articles/"+article.ID.String())
+	}
+
+	pages, err := ss.pages.GetPublishedPages(ctx)
+	if err != nil {
+		return sitemap{}, fmt.Errorf("unable to fetch pages: %w", err)
+	}
+
+	for _, page := range pages {
+		generated.paths = append(generated.paths, "/pages"+page.Path)
+	}
+
+	ss.mu.Lock()
+	ss.sitemaps[tenant.SiteID(ctx)] = generated
+	ss.mu.Unlock()
+
+	return generated, nil
+}
@@ -0,0 +1,354 @@
+/*
+Package services provides operations for managing the sites (tenants) served by the
+deployment.
+
+The primary interface, `SiteService`, defines methods to manage the sites and their
+custom domains, and to resolve the site a request is meant for, either from the
+hostname of the request or from the slug found in the path prefix of the request. The
+`SiteServiceImpl` struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+)
+
+// ErrDomainNotVerified is returned when the DNS TXT record of a custom domain does not
+// hold the expected verification token.
+var ErrDomainNotVerified = errors.New("domain ownership could not be verified")
+
+// TXTResolver looks up the DNS TXT records of a name, like `net.Resolver` does.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// SiteService defines the methods for site management and tenant resolution.
+type SiteService interface {
+	// GetAllSites retrieves every site of the deployment.
+	GetAllSites(ctx context.Context) ([]models.Site, error)
+
+	// GetSiteByID fetches a site by its unique ID.
+	GetSiteByID(ctx context.Context, id uuid.UUID) (models.Site, error)
+
+	// CreateSite creates a new site with the given name, slug, hostnames and quota.
+	CreateSite(
+		ctx context.Context,
+		name, slug string,
+		hostnames []string,
+		quota models.SiteQuota,
+	) (models.Site, error)
+
+	// UpdateSite updates the name, slug, hostnames and quota of an existing site.
+	UpdateSite(
+		ctx context.Context,
+		id uuid.UUID,
+		name, slug string,
+		hostnames []string,
+		quota models.SiteQuota,
+	) (models.Site, error)
+
+	// DeleteSite removes a site identified by its unique ID.
+	DeleteSite(ctx context.Context, id uuid.UUID) error
+
+	// AddDomain maps a new (unverified) custom domain to the site.
+	AddDomain(ctx context.Context, id uuid.UUID, name string) (models.Domain, error)
+
+	// VerifyDomain verifies the ownership of a custom domain of the site.
+	VerifyDomain(
+		ctx context.Context,
+		id uuid.UUID,
+		name string,
+	) (models.Domain, error)
+
+	// RemoveDomain unmaps a custom domain from the site.
+	RemoveDomain(ctx context.Context, id uuid.UUID, name string) error
+
+	// ResolveByHostname returns the site serving the hostname.
+	ResolveByHostname(ctx context.Context, hostname string) (models.Site, error)
+
+	// ResolveBySlug returns the site identified by the slug.
+	ResolveBySlug(ctx context.Context, slug string) (models.Site, error)
+}
+
+/*
+SiteServiceImpl is the concrete implementation of the SiteService interface.
+
+Requests for a hostname which is not mapped to any site are resolved to the fallback
+site (identified by its slug), unless the fallback is empty in which case such requests
+can not be resolved at all.
+*/
+type SiteServiceImpl struct {
+	sites    repository.SiteRepository
+	fallback string
+	resolver TXTResolver
+	ids      IDGenerator
+	clock    Clock
+}
+
+/*
+NewSiteService creates and returns a new instance of SiteServiceImpl backed by the
+given site repository, resolving unknown hostnames to the site identified by the
+fallback slug (disabled when empty) and verifying the custom domains with the given
+DNS resolver (usually `net.DefaultResolver`), at the time told by the given clock.
+*/
+func NewSiteService(
+	sites repository.SiteRepository,
+	fallback string,
+	resolver TXTResolver,
+	ids IDGenerator,
+	clock Clock,
+) *SiteServiceImpl {
+	return &SiteServiceImpl{
+		sites:    sites,
+		fallback: fallback,
+		resolver: resolver,
+		ids:      ids,
+		clock:    clock,
+	}
+}
+
+// GetAllSites retrieves every site of the deployment.
+func (ss *SiteServiceImpl) GetAllSites(ctx context.Context) ([]models.Site, error) {
+	sites, err := ss.sites.List(ctx)
+	if err != nil {
+		return []models.Site{}, fmt.Errorf("unable to fetch sites: %w", err)
+	}
+
+	return sites, nil
+}
+
+// GetSiteByID fetches a site by its unique ID, wrapping `repository.ErrNotFound` if no
+// such site exists.
+func (ss *SiteServiceImpl) GetSiteByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Site, error) {
+	site, err := ss.sites.Get(ctx, id)
+	if err != nil {
+		return models.Site{}, fmt.Errorf("unable to fetch site %s: %w", id, err)
+	}
+
+	return site, nil
+}
+
+// CreateSite creates a new site, wrapping `repository.ErrConflict` if its slug or one of
+// its hostnames is already taken by another site.
+func (ss *SiteServiceImpl) CreateSite(
+	ctx context.Context,
+	name, slug string,
+	hostnames []string,
+	quota models.SiteQuota,
+) (models.Site, error) {
+	siteID := ss.ids.NewID()
+
+	site := models.Site{
+		ID:        siteID,
+		Name:      name,
+		Slug:      slug,
+		Hostnames: hostnames,
+		Quota:     quota,
+	}
+
+	if err := ss.sites.Create(ctx, site); err != nil {
+		return models.Site{}, fmt.Errorf("unable to create site: %w", err)
+	}
+
+	return site, nil
+}
+
+// UpdateSite updates an existing site, wrapping `repository.ErrNotFound` if no such site
+// exists or `repository.ErrConflict` if the slug or a hostname is already taken.
+func (ss *SiteServiceImpl) UpdateSite(
+	ctx context.Context,
+	id uuid.UUID,
+	name, slug string,
+	hostnames []string,
+	quota models.SiteQuota,
+) (models.Site, error) {
+	site, err := ss.sites.Get(ctx, id)
+	if err != nil {
+		return models.Site{}, fmt.Errorf("unable to fetch site %s: %w", id, err)
+	}
+
+	site.Name = name
+	site.Slug = slug
+	site.Hostnames = hostnames
+	site.Quota = quota
+
+	if err := ss.sites.Update(ctx, site); err != nil {
+		return models.Site{}, fmt.Errorf("unable to update site %s: %w", id, err)
+	}
+
+	return site, nil
+}
+
+// DeleteSite removes a site, wrapping `repository.ErrNotFound` if no such site exists.
+func (ss *SiteServiceImpl) DeleteSite(ctx context.Context, id uuid.UUID) error {
+	if err := ss.sites.Delete(ctx, id); err != nil {
+		return fmt.Errorf("unable to delete site %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// ResolveByHostname returns the site serving the hostname, falling back to the
+// fallback site (if any) when the hostname is not mapped to any site.
+func (ss *SiteServiceImpl) ResolveByHostname(
+	ctx context.Context,
+	hostname string,
+) (models.Site, error) {
+	site, err := ss.sites.GetByHostname(ctx, hostname)
+	if errors.Is(err, repository.ErrNotFound) && ss.fallback != "" {
+		site, err = ss.sites.GetBySlug(ctx, ss.fallback)
+	}
+
+	if err != nil {
+		return models.Site{}, fmt.Errorf("unable to resolve site %q: %w", hostname, err)
+	}
+
+	return site, nil
+}
+
+// ResolveBySlug returns the site identified by the slug, whatever its case.
+func (ss *SiteServiceImpl) ResolveBySlug(
+	ctx context.Context,
+	slug string,
+) (models.Site, error) {
+	site, err := ss.sites.GetBySlug(ctx, strings.ToLower(slug))
+	if err != nil {
+		return models.Site{}, fmt.Errorf("unable to resolve site %q: %w", slug, err)
+	}
+
+	return site, nil
+}
+
+/*
+AddDomain maps a new custom domain to the site identified by id.
+
+The domain is not used to resolve the site until its ownership is verified (see
+`VerifyDomain`). The returned domain holds the token the owner has to publish in the
+`_burzcontent.<domain>` TXT record. `repository.ErrConflict` is returned (wrapped) if
+the domain is already mapped to a site.
+*/
+func (ss *SiteServiceImpl) AddDomain(
+	ctx context.Context,
+	id uuid.UUID,
+	name string,
+) (models.Domain, error) {
+	site, err := ss.sites.Get(ctx, id)
+	if err != nil {
+		return models.Domain{}, fmt.Errorf("unable to fetch site %s: %w", id, err)
+	}
+
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if indexDomain(site, name) >= 0 {
+		return models.Domain{}, fmt.Errorf(
+			"unable to add domain %q: %w", name, repository.ErrConflict,
+		)
+	}
+
+	domain := models.Domain{
+		Name:              name,
+		VerificationToken: rand.Text(),
+	}
+	site.Domains = append(site.Domains, domain)
+
+	if err := ss.sites.Update(ctx, site); err != nil {
+		return models.Domain{}, fmt.Errorf("unable to add domain %q: %w", name, err)
+	}
+
+	return domain, nil
+}
+
+/*
+VerifyDomain verifies the ownership of the custom domain of the site identified by id.
+
+The `_burzcontent.<domain>` TXT record of the domain is looked up and the domain is
+marked as verified if one of its values is `burzcontent-verification=<token>`,
+otherwise `ErrDomainNotVerified` is returned. `repository.ErrNotFound` is returned
+(wrapped) if the domain is not mapped to the site.
+*/
+func (ss *SiteServiceImpl) VerifyDomain(
+	ctx context.Context,
+	id uuid.UUID,
+	name string,
+) (models.Domain, error) {
+	site, err := ss.sites.Get(ctx, id)
+	if err != nil {
+		return models.Domain{}, fmt.Errorf("unable to fetch site %s: %w", id, err)
+	}
+
+	i := indexDomain(site, name)
+	if i < 0 {
+		return models.Domain{}, fmt.Errorf(
+			"unable to fetch domain %q: %w", name, repository.ErrNotFound,
+		)
+	}
+
+	domain := site.Domains[i]
+	if domain.Verified {
+		return domain, nil
+	}
+
+	records, err := ss.resolver.LookupTXT(ctx, "_burzcontent."+domain.Name)
+	expected := "burzcontent-verification=" + domain.VerificationToken
+	if err != nil || !slices.Contains(records, expected) {
+		return models.Domain{}, fmt.Errorf(
+			"unable to verify domain %q: %w", name, ErrDomainNotVerified,
+		)
+	}
+
+	now := ss.clock.Now()
+	domain.Verified = true
+	domain.VerifiedAt = &now
+	site.Domains[i] = domain
+
+	if err := ss.sites.Update(ctx, site); err != nil {
+		return models.Domain{}, fmt.Errorf("unable to verify domain %q: %w", name, err)
+	}
+
+	return domain, nil
+}
+
+// RemoveDomain unmaps the custom domain from the site identified by id, wrapping
+// `repository.ErrNotFound` if the domain is not mapped to the site.
+func (ss *SiteServiceImpl) RemoveDomain(
+	ctx context.Context,
+	id uuid.UUID,
+	name string,
+) error {
+	site, err := ss.sites.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to fetch site %s: %w", id, err)
+	}
+
+	i := indexDomain(site, name)
+	if i < 0 {
+		return fmt.Errorf("unable to fetch domain %q: %w", name, repository.ErrNotFound)
+	}
+
+	site.Domains = slices.Delete(site.Domains, i, i+1)
+
+	if err := ss.sites.Update(ctx, site); err != nil {
+		return fmt.Errorf("unable to remove domain %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// indexDomain returns the index of the custom domain of the site, or -1.
+func indexDomain(site models.Site, name string) int {
+	return slices.IndexFunc(site.Domains, func(d models.Domain) bool {
+		return strings.EqualFold(d.Name, name)
+	})
+}
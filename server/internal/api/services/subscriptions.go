@@ -0,0 +1,414 @@
+/*
+Package services provides operations for following the comments of the articles.
+
+The primary interface, `SubscriptionService`, defines methods for the users to
+subscribe to the comments of an article, to list and mute their subscriptions, and to
+list the in-app notifications they were sent. The `SubscriptionServiceImpl` struct
+provides the concrete implementation of these methods, and notifies the subscribers of
+an article of each new comment on it (see `CommentNotifier`), in-app and by email, along
+with the users mentioned in the comment.
+
+Subscriptions are personal: they are made by the user owning the API key of the
+request, the API keys not owned by any user being unable to follow any article.
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// ErrNoUser is returned when a personal operation (e.g. subscribing to an article) is
+// made with an API key which is not owned by any user.
+var ErrNoUser = errors.New("API key not owned by any user")
+
+// SubscriptionService defines the methods for following the comments of the articles.
+type SubscriptionService interface {
+	// Subscribe subscribes the user of the request to the comments of the article.
+	Subscribe(ctx context.Context, articleID uuid.UUID) (models.Subscription, error)
+
+	// Unsubscribe unsubscribes the user of the request from the comments of the
+	// article.
+	Unsubscribe(ctx context.Context, articleID uuid.UUID) error
+
+	// GetSubscriptions retrieves the subscriptions of the user of the request.
+	GetSubscriptions(ctx context.Context) ([]models.Subscription, error)
+
+	// SetMuted mutes or unmutes a subscription of the user of the request.
+	SetMuted(
+		ctx context.Context,
+		id uuid.UUID,
+		muted bool,
+	) (models.Subscription, error)
+
+	// GetNotifications retrieves the in-app notifications of the user of the request.
+	GetNotifications(ctx context.Context) ([]models.Notification, error)
+}
+
+// SubscriptionServiceImpl is the concrete implementation of the SubscriptionService
+// interface.
+type SubscriptionServiceImpl struct {
+	subscriptions repository.SubscriptionRepository
+	notifications repository.NotificationRepository
+	articles      repository.ArticleRepository
+	users         repository.UserRepository
+	mailer        mailer.Mailer
+	templates     TemplateProvider
+	events        EventPublisher
+	ids           IDGenerator
+	clock         Clock
+}
+
+/*
+NewSubscriptionService creates and returns a new instance of SubscriptionServiceImpl
+backed by the given repositories, emailing the subscribers with the given mailer
+(rendering the emails with the templates of the site) and publishing the mentions of
+the users with the given publisher. The new subscriptions and notifications are
+assigned their IDs by the given generator, and are stamped with the time told by the
+given clock.
+*/
+func NewSubscriptionService(
+	subscriptions repository.SubscriptionRepository,
+	notifications repository.NotificationRepository,
+	articles repository.ArticleRepository,
+	users repository.UserRepository,
+	mailer mailer.Mailer,
+	templates TemplateProvider,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *SubscriptionServiceImpl {
+	return &SubscriptionServiceImpl{
+		subscriptions: subscriptions,
+		notifications: notifications,
+		articles:      articles,
+		users:         users,
+		mailer:        mailer,
+		templates:     templates,
+		events:        events,
+		ids:           ids,
+		clock:         clock,
+	}
+}
+
+/*
+Subscribe subscribes the user of the request to the comments of the article of the site
+held by the context. The existing subscription of the user is returned as is if they
+already follow the article, whether muted or not.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user, and
+`repository.ErrNotFound` (wrapped) if no such article exists.
+*/
+func (ss *SubscriptionServiceImpl) Subscribe(
+	ctx context.Context,
+	articleID uuid.UUID,
+) (models.Subscription, error) {
+	siteID := tenant.SiteID(ctx)
+
+	userID, err := userOf(ctx)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	if _, err := ss.articles.Get(ctx, siteID, articleID); err != nil {
+		return models.Subscription{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	subscription, err := ss.subscriptionTo(ctx, articleID, userID)
+	if err == nil {
+		return subscription, nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return models.Subscription{}, err
+	}
+
+	now := ss.clock.Now()
+	subscription = models.Subscription{
+		ID:        ss.ids.NewID(),
+		SiteID:    siteID,
+		ArticleID: articleID,
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := ss.subscriptions.Create(ctx, subscription); err != nil {
+		return models.Subscription{}, fmt.Errorf(
+			"unable to create subscription: %w", err,
+		)
+	}
+
+	return subscription, nil
+}
+
+/*
+Unsubscribe unsubscribes the user of the request from the comments of the article of
+the site held by the context.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user, and
+`repository.ErrNotFound` (wrapped) if the user does not follow the article.
+*/
+func (ss *SubscriptionServiceImpl) Unsubscribe(
+	ctx context.Context,
+	articleID uuid.UUID,
+) error {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := ss.subscriptionTo(ctx, articleID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = ss.subscriptions.Delete(ctx, subscription.SiteID, subscription.ID)
+	if err != nil {
+		return fmt.Errorf("unable to delete subscription %s: %w", subscription.ID, err)
+	}
+
+	return nil
+}
+
+/*
+GetSubscriptions retrieves the subscriptions of the user of the request within the site
+held by the context, oldest first.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user.
+*/
+func (ss *SubscriptionServiceImpl) GetSubscriptions(
+	ctx context.Context,
+) ([]models.Subscription, error) {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return []models.Subscription{}, err
+	}
+
+	subscriptions, err := ss.subscriptions.ListByUser(ctx, tenant.SiteID(ctx), userID)
+	if err != nil {
+		return []models.Subscription{}, fmt.Errorf(
+			"unable to fetch subscriptions: %w", err,
+		)
+	}
+
+	return subscriptions, nil
+}
+
+/*
+SetMuted mutes or unmutes the subscription of the user of the request identified by id.
+The user of a muted subscription is not notified of the new comments on the article,
+but still follows it.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user, and
+`repository.ErrNotFound` (wrapped) if the user has no such subscription.
+*/
+func (ss *SubscriptionServiceImpl) SetMuted(
+	ctx context.Context,
+	id uuid.UUID,
+	muted bool,
+) (models.Subscription, error) {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	subscription, err := ss.subscriptions.Get(ctx, tenant.SiteID(ctx), id)
+	if err == nil && subscription.UserID != userID {
+		// The subscriptions of the other users are not disclosed
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return models.Subscription{}, fmt.Errorf(
+			"unable to fetch subscription %s: %w", id, err,
+		)
+	}
+
+	if subscription.Muted == muted {
+		return subscription, nil
+	}
+
+	subscription.Muted = muted
+	subscription.UpdatedAt = ss.clock.Now()
+
+	if err := ss.subscriptions.Update(ctx, subscription); err != nil {
+		return models.Subscription{}, fmt.Errorf(
+			"unable to update subscription %s: %w", id, err,
+		)
+	}
+
+	return subscription, nil
+}
+
+/*
+GetNotifications retrieves the in-app notifications of the user of the request within
+the site held by the context, newest first.
+
+`ErrNoUser` is returned if the API key of the request is not owned by any user.
+*/
+func (ss *SubscriptionServiceImpl) GetNotifications(
+	ctx context.Context,
+) ([]models.Notification, error) {
+	userID, err := userOf(ctx)
+	if err != nil {
+		return []models.Notification{}, err
+	}
+
+	notifications, err := ss.notifications.ListByUser(ctx, tenant.SiteID(ctx), userID)
+	if err != nil {
+		return []models.Notification{}, fmt.Errorf(
+			"unable to fetch notifications: %w", err,
+		)
+	}
+	slices.Reverse(notifications)
+
+	return notifications, nil
+}
+
+/*
+NotifyComment notifies the users mentioned in the new comment on the article of their
+mention, in-app and with a `comment.mentioned` event, and the subscribers of the article
+of the comment, in-app (unless they were mentioned in it) and by email. The subscribers
+who muted their subscription and the author of the comment (i.e. the user with its
+email address) are not notified.
+
+The notifications are best-effort: the comment stands even if its subscribers can not
+be notified.
+*/
+func (ss *SubscriptionServiceImpl) NotifyComment(
+	ctx context.Context,
+	article models.Article,
+	comment models.Comment,
+	mentioned []models.User,
+) {
+	site, _ := tenant.FromContext(ctx)
+
+	for _, user := range mentioned {
+		if user.Email == comment.Email {
+			continue
+		}
+
+		_ = ss.notifications.Create(ctx, models.Notification{
+			ID:        ss.ids.NewID(),
+			SiteID:    site.ID,
+			UserID:    user.ID,
+			Kind:      models.NotificationMention,
+			ArticleID: article.ID,
+			CommentID: &comment.ID,
+			Message: fmt.Sprintf(
+				"%s mentioned you on %q", comment.Name, article.Title,
+			),
+			CreatedAt: ss.clock.Now(),
+		})
+
+		ss.events.Publish(site.ID, "comment.mentioned", map[string]any{
+			"user_id": user.ID,
+			"comment": comment,
+		})
+	}
+
+	subscriptions, err := ss.subscriptions.ListByArticle(ctx, site.ID, article.ID)
+	if err != nil || len(subscriptions) == 0 {
+		return
+	}
+
+	// The default templates are used if the templates of the site can not be fetched
+	templates, err := ss.templates.ActiveTemplates(ctx)
+	if err != nil {
+		templates = nil
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.Muted {
+			continue
+		}
+
+		user, err := ss.users.Get(ctx, site.ID, subscription.UserID)
+		if err != nil || user.Email == comment.Email {
+			continue
+		}
+
+		// The mentioned users were already notified in-app of the comment
+		isUser := func(u models.User) bool { return u.ID == user.ID }
+		if !slices.ContainsFunc(mentioned, isUser) {
+			_ = ss.notifications.Create(ctx, models.Notification{
+				ID:        ss.ids.NewID(),
+				SiteID:    site.ID,
+				UserID:    user.ID,
+				Kind:      models.NotificationComment,
+				ArticleID: article.ID,
+				CommentID: &comment.ID,
+				Message: fmt.Sprintf(
+					"%s commented on %q", comment.Name, article.Title,
+				),
+				CreatedAt: ss.clock.Now(),
+			})
+		}
+
+		email, err := mailer.RenderWith(
+			templates,
+			"comment_notification",
+			[]string{user.Email},
+			map[string]string{
+				"SiteName":       site.Name,
+				"Name":           user.Name,
+				"ArticleTitle":   article.Title,
+				"CommentAuthor":  comment.Name,
+				"CommentContent": comment.Content,
+				"URL":            site.URL("/articles/" + article.ID.String()),
+			},
+		)
+		if err != nil {
+			continue
+		}
+
+		_ = ss.mailer.Send(ctx, email)
+	}
+}
+
+// subscriptionTo returns the subscription of the user to the article of the site held
+// by the context, wrapping `repository.ErrNotFound` if the user does not follow it.
+func (ss *SubscriptionServiceImpl) subscriptionTo(
+	ctx context.Context,
+	articleID, userID uuid.UUID,
+) (models.Subscription, error) {
+	subscriptions, err := ss.subscriptions.ListByUser(ctx, tenant.SiteID(ctx), userID)
+	if err != nil {
+		return models.Subscription{}, fmt.Errorf(
+			"unable to fetch subscriptions: %w", err,
+		)
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.ArticleID == articleID {
+			return subscription, nil
+		}
+	}
+
+	return models.Subscription{}, fmt.Errorf(
+		"unable to fetch subscription to article %s: %w",
+		articleID,
+		repository.ErrNotFound,
+	)
+}
+
+// userOf returns the unique identifier of the user owning the API key of the request,
+// or `ErrNoUser` if the key is not owned by any user.
+func userOf(ctx context.Context) (uuid.UUID, error) {
+	principal, _ := auth.FromContext(ctx)
+	if principal.UserID == uuid.Nil {
+		return uuid.Nil, ErrNoUser
+	}
+
+	return principal.UserID, nil
+}
@@ -0,0 +1,19 @@
+/*
+Package services provides the operations of the resources of the system, which run
+their slow work (e.g. sending the webmentions of the articles) in the background as
+tasks of a `TaskQueue`.
+*/
+package services
+
+import (
+	"context"
+
+	"github.com/Weburz/burzcontent/server/internal/queue"
+)
+
+// TaskQueue runs the tasks of the services in the background, like `queue.Queue`
+// does.
+type TaskQueue interface {
+	Handle(kind string, handler queue.HandlerFunc)
+	Enqueue(ctx context.Context, kind string, payload any) error
+}
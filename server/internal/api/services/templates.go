@@ -0,0 +1,299 @@
+/*
+Package services provides operations for managing the template bundles of the sites.
+
+The primary interface, `TemplateService`, defines methods to upload, list and activate
+the versions of the templates a site overrides the default templates of the server
+with (e.g. the templates of its emails), and to roll back to a previous version. The
+`TemplateServiceImpl` struct provides the concrete implementation of these methods.
+*/
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+var (
+	// ErrInvalidBundle is returned when an uploaded template bundle is not a zip
+	// archive of valid templates overriding the default templates.
+	ErrInvalidBundle = errors.New("invalid template bundle")
+
+	// ErrBundleActive is returned when the active template bundle of a site is
+	// deleted.
+	ErrBundleActive = errors.New("template bundle is active")
+
+	// ErrNoActiveBundle is returned when a site without any active template bundle
+	// rolls back its templates.
+	ErrNoActiveBundle = errors.New("no active template bundle")
+)
+
+// TemplateService defines the methods for managing the template bundles of the sites.
+type TemplateService interface {
+	// GetAllBundles retrieves every template bundle of the site.
+	GetAllBundles(ctx context.Context) ([]models.TemplateBundle, error)
+
+	// GetBundleByID fetches a template bundle by its unique ID.
+	GetBundleByID(ctx context.Context, id uuid.UUID) (models.TemplateBundle, error)
+
+	// UploadBundle stores a new version of the templates of the site.
+	UploadBundle(
+		ctx context.Context,
+		archive []byte,
+		description string,
+	) (models.TemplateBundle, error)
+
+	// ActivateBundle makes a template bundle the one used by the site.
+	ActivateBundle(ctx context.Context, id uuid.UUID) (models.TemplateBundle, error)
+
+	// Rollback activates the version preceding the active template bundle.
+	Rollback(ctx context.Context) (*models.TemplateBundle, error)
+
+	// DeleteBundle removes a template bundle identified by its unique ID.
+	DeleteBundle(ctx context.Context, id uuid.UUID) error
+
+	// ActiveTemplates returns the templates of the active bundle of the site.
+	ActiveTemplates(ctx context.Context) (fs.FS, error)
+}
+
+// TemplateServiceImpl is the concrete implementation of the TemplateService interface.
+type TemplateServiceImpl struct {
+	mu      sync.Mutex // Serializes the uploads, so that the versions remain unique
+	bundles repository.TemplateBundleRepository
+	ids     IDGenerator
+	clock   Clock
+}
+
+// NewTemplateService creates and returns a new instance of TemplateServiceImpl backed
+// by the given template bundle repository, stamping the bundles with the time told by
+// the given clock.
+func NewTemplateService(
+	bundles repository.TemplateBundleRepository,
+	ids IDGenerator,
+	clock Clock,
+) *TemplateServiceImpl {
+	return &TemplateServiceImpl{bundles: bundles, ids: ids, clock: clock}
+}
+
+// GetAllBundles retrieves every template bundle of the site held by the context, in
+// version order.
+func (ts *TemplateServiceImpl) GetAllBundles(
+	ctx context.Context,
+) ([]models.TemplateBundle, error) {
+	bundles, err := ts.bundles.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.TemplateBundle{}, fmt.Errorf(
+			"unable to fetch template bundles: %w", err,
+		)
+	}
+
+	return bundles, nil
+}
+
+// GetBundleByID fetches a template bundle of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such bundle exists.
+func (ts *TemplateServiceImpl) GetBundleByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.TemplateBundle, error) {
+	bundle, err := ts.bundles.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.TemplateBundle{}, fmt.Errorf(
+			"unable to fetch template bundle %s: %w", id, err,
+		)
+	}
+
+	return bundle, nil
+}
+
+/*
+UploadBundle stores the zip archive as the next version of the templates of the site
+held by the context. The bundle is not used until it is activated.
+
+`ErrInvalidBundle` is returned (wrapped) if the archive is not a zip archive, or if one
+of its files does not override a default template or is not a valid template.
+*/
+func (ts *TemplateServiceImpl) UploadBundle(
+	ctx context.Context,
+	archive []byte,
+	description string,
+) (models.TemplateBundle, error) {
+	templates, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return models.TemplateBundle{}, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	if err := mailer.ValidateTemplates(templates); err != nil {
+		return models.TemplateBundle{}, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	var files []string
+	for _, file := range templates.File {
+		if !file.FileInfo().IsDir() {
+			files = append(files, file.Name)
+		}
+	}
+
+	if len(files) == 0 {
+		return models.TemplateBundle{}, fmt.Errorf(
+			"%w: the archive holds no template", ErrInvalidBundle,
+		)
+	}
+
+	bundleID := ts.ids.NewID()
+
+	digest := sha256.Sum256(archive)
+	bundle := models.TemplateBundle{
+		ID:          bundleID,
+		SiteID:      tenant.SiteID(ctx),
+		Description: description,
+		Files:       files,
+		Size:        int64(len(archive)),
+		Checksum:    hex.EncodeToString(digest[:]),
+		CreatedAt:   ts.clock.Now(),
+		Archive:     archive,
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	bundles, err := ts.bundles.List(ctx, bundle.SiteID)
+	if err != nil {
+		return models.TemplateBundle{}, fmt.Errorf(
+			"unable to fetch template bundles: %w", err,
+		)
+	}
+
+	bundle.Version = 1
+	if len(bundles) > 0 {
+		bundle.Version = bundles[len(bundles)-1].Version + 1
+	}
+
+	if err := ts.bundles.Create(ctx, bundle); err != nil {
+		return models.TemplateBundle{}, fmt.Errorf(
+			"unable to create template bundle: %w", err,
+		)
+	}
+
+	return bundle, nil
+}
+
+// ActivateBundle makes the template bundle the one used by the site held by the
+// context, wrapping `repository.ErrNotFound` if no such bundle exists.
+func (ts *TemplateServiceImpl) ActivateBundle(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.TemplateBundle, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if err := ts.bundles.Activate(ctx, siteID, id, ts.clock.Now()); err != nil {
+		return models.TemplateBundle{}, fmt.Errorf(
+			"unable to activate template bundle %s: %w", id, err,
+		)
+	}
+
+	return ts.GetBundleByID(ctx, id)
+}
+
+/*
+Rollback activates the latest version preceding the active template bundle of the site
+held by the context, and returns it. When the active bundle is the first version, the
+site rolls back to the default templates and nil is returned.
+
+`ErrNoActiveBundle` is returned if the site already uses the default templates.
+*/
+func (ts *TemplateServiceImpl) Rollback(
+	ctx context.Context,
+) (*models.TemplateBundle, error) {
+	siteID := tenant.SiteID(ctx)
+
+	bundles, err := ts.bundles.List(ctx, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch template bundles: %w", err)
+	}
+
+	active := slices.IndexFunc(bundles, func(b models.TemplateBundle) bool {
+		return b.Active
+	})
+	if active < 0 {
+		return nil, ErrNoActiveBundle
+	}
+
+	// The first version rolls back to the default templates
+	var previous *models.TemplateBundle
+	id := uuid.Nil
+	if active > 0 {
+		previous = &bundles[active-1]
+		id = previous.ID
+	}
+
+	if err := ts.bundles.Activate(ctx, siteID, id, ts.clock.Now()); err != nil {
+		return nil, fmt.Errorf("unable to roll back template bundles: %w", err)
+	}
+
+	if previous == nil {
+		return nil, nil
+	}
+
+	bundle, err := ts.GetBundleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bundle, nil
+}
+
+// DeleteBundle removes a template bundle of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such bundle exists. The active bundle can not be
+// deleted (`ErrBundleActive`).
+func (ts *TemplateServiceImpl) DeleteBundle(ctx context.Context, id uuid.UUID) error {
+	bundle, err := ts.GetBundleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if bundle.Active {
+		return ErrBundleActive
+	}
+
+	if err := ts.bundles.Delete(ctx, bundle.SiteID, id); err != nil {
+		return fmt.Errorf("unable to delete template bundle %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// ActiveTemplates returns the templates of the active bundle of the site held by the
+// context, or nil if the site uses the default templates.
+func (ts *TemplateServiceImpl) ActiveTemplates(ctx context.Context) (fs.FS, error) {
+	bundle, err := ts.bundles.GetActive(ctx, tenant.SiteID(ctx))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to fetch active template bundle: %w", err)
+	}
+
+	templates, err := zip.NewReader(
+		bytes.NewReader(bundle.Archive),
+		int64(len(bundle.Archive)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read template bundle %s: %w", bundle.ID, err)
+	}
+
+	return templates, nil
+}
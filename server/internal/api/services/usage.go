@@ -0,0 +1,162 @@
+/*
+Package services provides operations for tracking the usage of the sites.
+
+The primary interface, `UsageService`, defines methods to record the requests served
+to a site, to measure the storage used by its content and to report its usage against
+its quota for billing purposes. The `UsageServiceImpl` struct provides the concrete
+implementation of these methods.
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// UsageService defines the methods for usage tracking and reporting.
+type UsageService interface {
+	// Quota returns the effective quota of the site, applying the defaults of the
+	// deployment to the limits the site does not override.
+	Quota(site models.Site) models.SiteQuota
+
+	// RecordRequest counts a request made to the site, which was either served or
+	// rejected by the rate limiter.
+	RecordRequest(ctx context.Context, siteID uuid.UUID, rateLimited bool) error
+
+	// StorageUsage returns the storage used by the content of the site, in bytes.
+	StorageUsage(ctx context.Context, siteID uuid.UUID) (int64, error)
+
+	// GetUsage returns the usage report of the site held by the context.
+	GetUsage(ctx context.Context) (models.Usage, error)
+}
+
+// UsageServiceImpl is the concrete implementation of the UsageService interface.
+type UsageServiceImpl struct {
+	usage    repository.UsageRepository
+	articles repository.ArticleRepository
+	comments repository.CommentRepository
+	defaults models.SiteQuota
+	clock    Clock
+}
+
+// NewUsageService creates and returns a new instance of UsageServiceImpl, applying the
+// given default quota to the sites which do not override it and counting the requests
+// on the day told by the given clock.
+func NewUsageService(
+	store *repository.Store,
+	defaults models.SiteQuota,
+	clock Clock,
+) *UsageServiceImpl {
+	return &UsageServiceImpl{
+		usage:    store.Usage,
+		articles: store.Articles,
+		comments: store.Comments,
+		defaults: defaults,
+		clock:    clock,
+	}
+}
+
+// Quota returns the effective quota of the site.
+func (us *UsageServiceImpl) Quota(site models.Site) models.SiteQuota {
+	quota := site.Quota
+	if quota.RequestsPerMinute == 0 {
+		quota.RequestsPerMinute = us.defaults.RequestsPerMinute
+	}
+
+	if quota.StorageBytes == 0 {
+		quota.StorageBytes = us.defaults.StorageBytes
+	}
+
+	return quota
+}
+
+// RecordRequest counts a request made to the site on the current (UTC) day.
+func (us *UsageServiceImpl) RecordRequest(
+	ctx context.Context,
+	siteID uuid.UUID,
+	rateLimited bool,
+) error {
+	day := us.clock.Now().Format(time.DateOnly)
+
+	if rateLimited {
+		return us.usage.AddRateLimited(ctx, siteID, day, 1)
+	}
+
+	return us.usage.AddRequests(ctx, siteID, day, 1)
+}
+
+/*
+StorageUsage returns the storage used by the content of the site, in bytes.
+
+The storage is measured as the size of the JSON representation of the articles
+(including the trashed ones) and the comments of the site, which is what the site
+would take in a document store.
+*/
+func (us *UsageServiceImpl) StorageUsage(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (int64, error) {
+	articles, err := us.articles.List(ctx, siteID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	// The trashed articles take storage until they are purged
+	trashed, err := us.articles.ListTrashed(ctx, siteID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch trash: %w", err)
+	}
+
+	comments, err := us.comments.List(ctx, siteID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+
+	var size int64
+	for _, resource := range []any{articles, trashed, comments} {
+		data, err := json.Marshal(resource)
+		if err != nil {
+			return 0, fmt.Errorf("unable to measure storage: %w", err)
+		}
+
+		size += int64(len(data))
+	}
+
+	return size, nil
+}
+
+// GetUsage returns the usage report of the site held by the context.
+func (us *UsageServiceImpl) GetUsage(ctx context.Context) (models.Usage, error) {
+	site, _ := tenant.FromContext(ctx)
+
+	requests, err := us.usage.Requests(ctx, site.ID)
+	if err != nil {
+		return models.Usage{}, fmt.Errorf("unable to fetch usage: %w", err)
+	}
+
+	rateLimited, err := us.usage.RateLimited(ctx, site.ID)
+	if err != nil {
+		return models.Usage{}, fmt.Errorf("unable to fetch usage: %w", err)
+	}
+
+	storage, err := us.StorageUsage(ctx, site.ID)
+	if err != nil {
+		return models.Usage{}, err
+	}
+
+	return models.Usage{
+		SiteID:       site.ID,
+		Requests:     requests,
+		RateLimited:  rateLimited,
+		StorageBytes: storage,
+		Quota:        us.Quota(site),
+	}, nil
+}
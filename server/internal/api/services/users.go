@@ -14,10 +14,13 @@ The package contains the following key functionalities:
 - GetUserByID: Fetches a user based on their unique ID.
 - CreateUser: Creates a new user with a given name and email.
 - UpdateUser: Updates the details of an existing user.
-- DeleteUser: Removes a user from the system by their ID.
+- DeleteUser: Removes a user from the system by their ID, anonymizing their comments.
+- ExportUser: Exports every piece of data of a user (profile, content and consents).
+- GetUserBySlug and GetArticlesOfUser: Serve the authors and their articles.
 
 This package is meant to handle typical CRUD operations related to users in the system,
-with the methods returning appropriate data or errors as needed.
+with the methods returning appropriate data or errors as needed. Every operation is
+scoped to the site (tenant) held by the context passed to it.
 
 The package also defines a constructor function, `NewUserService`, to initialize and
 return an instance of `UserService`, which implements the `IUserService` interface.
@@ -25,152 +28,385 @@ return an instance of `UserService`, which implements the `IUserService` interfa
 package services
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 
 	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/markdown"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
 )
 
+// AnonymousName replaces the name of the deleted users in their comments.
+const AnonymousName = "Anonymous"
+
 // UserService defines the methods for user management.
 type UserService interface {
-	// GetAllUsers retrieves all users and returns a slice of User models and an error.
-	GetAllUsers() ([]models.User, error)
+	// GetAllUsers retrieves the page of users matching the query and returns a slice of
+	// User models, the number of users matching the query and an error (if any).
+	GetAllUsers(
+		ctx context.Context,
+		query repository.UserQuery,
+	) ([]models.User, int, error)
 
 	// GetUserByID fetches a user by ID and returns the User model and an error (if
 	// any).
-	GetUserByID(id uuid.UUID) (models.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (models.User, error)
 
-	// CreateUser creates a new user with the given name and email and returns the
-	// created User model and an error (if any).
-	CreateUser(name, email string) (models.User, error)
+	// CreateUser creates a new user with the given name, email, role and profile and
+	// returns the created User model and an error (if any).
+	CreateUser(
+		ctx context.Context,
+		name, email string,
+		role auth.Role,
+		profile models.Profile,
+	) (models.User, error)
 
 	// UpdatedUser updates an existing user's details identified by their unique ID and
 	// returns the updated User model and an error (if any).
-	UpdateUser(id uuid.UUID, name, email string) (models.User, error)
+	UpdateUser(
+		ctx context.Context,
+		id uuid.UUID,
+		name, email string,
+		role auth.Role,
+		profile models.Profile,
+	) (models.User, error)
+
+	// DeleteUser removes a user identified by their unique ID from the system and
+	// anonymizes their comments.
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+
+	// ExportUser exports every piece of data of a user identified by their unique ID.
+	ExportUser(ctx context.Context, id uuid.UUID) (models.UserExport, error)
 
-	// DeleteUser removes a user identified by their unique ID from the system.
-	DeleteUser(id uuid.UUID) error
+	// GetUserBySlug fetches a user by the slug of their name (see `models.Author`).
+	GetUserBySlug(ctx context.Context, slug string) (models.User, error)
+
+	// GetArticlesOfUser retrieves a page of the articles authored by a user identified
+	// by their unique ID and matching the query, along with the number of articles
+	// matching the query.
+	GetArticlesOfUser(
+		ctx context.Context,
+		id uuid.UUID,
+		query repository.ArticleQuery,
+	) ([]models.Article, int, error)
 }
 
-// The `UserServiceImpl` struct implements the IUserService interface
-type UserServiceImpl struct{}
+// The `UserServiceImpl` struct implements the UserService interface
+type UserServiceImpl struct {
+	users    repository.UserRepository
+	articles repository.ArticleRepository
+	comments repository.CommentRepository
+	consents repository.ConsentRepository
+	ids      IDGenerator
+	clock    Clock
+}
 
 /*
-NewUserService creates and returns a new instance of the UserService struct.
+NewUserService creates and returns a new instance of the UserServiceImpl struct.
 
-This constructor function initializes a zero-value UserService struct, returning a
-pointer to it. It does not perform any additional setup or initialization logic beyond
-the default struct creation. This is typically used to instantiate the UserService when
-no custom initialization is required.
+This constructor function initializes a UserServiceImpl struct backed by the given user
+repository, returning a pointer to it. The article, comment and consent repositories are
+used to gather the content of a user when exporting or deleting their data.
 
 Returns:
-- *UserService: A pointer to the newly created UserService instance.
+- *UserServiceImpl: A pointer to the newly created UserServiceImpl instance.
 */
-func NewUserService() *UserServiceImpl {
-	return &UserServiceImpl{}
+func NewUserService(
+	users repository.UserRepository,
+	articles repository.ArticleRepository,
+	comments repository.CommentRepository,
+	consents repository.ConsentRepository,
+	ids IDGenerator,
+	clock Clock,
+) *UserServiceImpl {
+	return &UserServiceImpl{
+		users:    users,
+		articles: articles,
+		comments: comments,
+		consents: consents,
+		ids:      ids,
+		clock:    clock,
+	}
 }
 
 /*
-GetAllUsers retrieves all users from the system. It generates a new unique user ID and
-returns a slice of hardcoded User models along with any error encountered. In case of
-error during UUID generation, it returns an empty slice of users and an error message.
+GetAllUsers retrieves the page of users of the site held by the context matching the
+query. It returns a slice of User models and the number of users matching the query,
+along with any error encountered while fetching them.
+
+The search text only matches the email addresses of the users when the request is made
+by an admin; the email addresses of the users are personal data which the other roles
+must not be able to probe.
 */
-func (us *UserServiceImpl) GetAllUsers() ([]models.User, error) {
-	userID, err := uuid.NewV7()
+func (us *UserServiceImpl) GetAllUsers(
+	ctx context.Context,
+	query repository.UserQuery,
+) ([]models.User, int, error) {
+	principal, ok := auth.FromContext(ctx)
+	query.SearchEmail = ok && principal.HasRole(auth.RoleAdmin)
+
+	users, total, err := us.users.Query(ctx, tenant.SiteID(ctx), query)
 	if err != nil {
-		return []models.User{}, fmt.Errorf("%w\n", err)
-	}
-
-	users := []models.User{
-		{
-			ID:    userID,
-			Name:  "Somraj Saha",
-			Email: "somraj.saha@weburz.com",
-		},
-		{
-			ID:    userID,
-			Name:  "John Doe",
-			Email: "john.doe@example.com",
-		},
-		{
-			ID:    userID,
-			Name:  "Sagar Kapoor",
-			Email: "sagar.kapoor@weburz.com",
-		},
-	}
-
-	return users, nil
+		return []models.User{}, 0, fmt.Errorf("unable to fetch users: %w", err)
+	}
+
+	return users, total, nil
 }
 
 /*
 GetUserByID retrieves a user by their unique ID. It returns the corresponding User
-model with hardcoded details and an error (if any). If no error occurs, the user details
-are returned with a nil error.
+model and an error (if any), wrapping `repository.ErrNotFound` if no such user exists
+within the site held by the context.
 */
-func (us *UserServiceImpl) GetUserByID(id uuid.UUID) (models.User, error) {
-	userID, err := uuid.Parse(id.String())
+func (us *UserServiceImpl) GetUserByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.User, error) {
+	user, err := us.users.Get(ctx, tenant.SiteID(ctx), id)
 	if err != nil {
-		return models.User{}, fmt.Errorf("%w\n", err)
-	}
-
-	user := models.User{
-		ID:    userID,
-		Name:  "Somraj Saha",
-		Email: "somraj.saha@weburz.com",
+		return models.User{}, fmt.Errorf("unable to fetch user %s: %w", id, err)
 	}
 
 	return user, nil
 }
 
 /*
-CreateUser creates a new user with the provided name and email. It generates a new
-unique user ID and returns the newly created User model along with any error encountered
-during UUID generation or other issues.
+CreateUser creates a new user with the provided name, email, role (`auth.RoleAuthor` if
+empty) and profile. It generates a new unique user ID and returns the newly created
+User model along with any error encountered while storing the user.
 */
-func (us *UserServiceImpl) CreateUser(name, email string) (models.User, error) {
-	userID, err := uuid.NewV7()
-	if err != nil {
-		return models.User{}, fmt.Errorf("%w\n", err)
-	}
+func (us *UserServiceImpl) CreateUser(
+	ctx context.Context,
+	name, email string,
+	role auth.Role,
+	profile models.Profile,
+) (models.User, error) {
+	userID := us.ids.NewID()
+	now := us.clock.Now()
 
 	user := models.User{
-		ID:    userID,
-		Name:  name,
-		Email: email,
+		ID:        userID,
+		SiteID:    tenant.SiteID(ctx),
+		Name:      name,
+		Email:     email,
+		Role:      cmp.Or(role, auth.RoleAuthor),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Profile:   profile,
+	}
+
+	if err := us.users.Create(ctx, user); err != nil {
+		return models.User{}, fmt.Errorf("unable to create user: %w", err)
 	}
 
 	return user, nil
 }
 
 /*
-UpdateUser updates an existing user's details using the provided ID, name, and email. It
-parses the ID to ensure it's valid and returns the updated User model along with any
-error encountered during ID parsing or other operations.
+UpdateUser updates an existing user's details using the provided ID, name, email, role
+(left unchanged if empty) and profile. It returns the updated User model along with any
+error encountered, wrapping `repository.ErrNotFound` if no such user exists within the
+site held by the context.
 */
 func (us *UserServiceImpl) UpdateUser(
+	ctx context.Context,
 	id uuid.UUID,
 	name, email string,
+	role auth.Role,
+	profile models.Profile,
 ) (models.User, error) {
-	userID, err := uuid.Parse(id.String())
+	user, err := us.users.Get(ctx, tenant.SiteID(ctx), id)
 	if err != nil {
-		return models.User{}, fmt.Errorf("%w\n", err)
+		return models.User{}, fmt.Errorf("unable to fetch user %s: %w", id, err)
 	}
 
-	user := models.User{
-		ID:    userID,
-		Name:  name,
-		Email: email,
+	user.Name = name
+	user.Email = email
+	user.Role = cmp.Or(role, user.Role)
+	user.Profile = profile
+	user.UpdatedAt = us.clock.Now()
+
+	if err := us.users.Update(ctx, user); err != nil {
+		return models.User{}, fmt.Errorf("unable to update user %s: %w", id, err)
 	}
 
 	return user, nil
 }
 
 /*
-DeleteUser removes a user from the system using the provided unique user ID. It prints
-a message confirming the user has been deleted.
+DeleteUser removes a user from the system using the provided unique user ID, wrapping
+`repository.ErrNotFound` if no such user exists within the site held by the context.
+
+The comments made by the user are anonymized rather than deleted (or orphaned): their
+content is kept so that the discussions they are part of still make sense, but the
+name and the email address of the user are erased from them.
 */
-func (us *UserServiceImpl) DeleteUser(id uuid.UUID) error {
-	fmt.Printf("User %s deleted!", id)
+func (us *UserServiceImpl) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	user, err := us.users.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return fmt.Errorf("unable to fetch user %s: %w", id, err)
+	}
+
+	comments, err := us.commentsOf(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		comment.Name = AnonymousName
+		comment.Email = ""
+		comment.UpdatedAt = us.clock.Now()
+
+		if err := us.comments.Update(ctx, comment); err != nil {
+			return fmt.Errorf("unable to anonymize comment %s: %w", comment.ID, err)
+		}
+	}
+
+	if err := us.users.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete user %s: %w", id, err)
+	}
+
 	return nil
 }
+
+/*
+ExportUser exports every piece of data of the user identified by id: their profile, the
+articles they authored, the comments they made (i.e. with their email address) and the
+consents they gave along with them. It wraps `repository.ErrNotFound` if no such user
+exists within the site held by the context.
+*/
+func (us *UserServiceImpl) ExportUser(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.UserExport, error) {
+	user, err := us.users.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.UserExport{}, fmt.Errorf("unable to fetch user %s: %w", id, err)
+	}
+
+	articles, err := us.articles.List(ctx, user.SiteID)
+	if err != nil {
+		return models.UserExport{}, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	// The trashed articles are still held until they are purged
+	trashed, err := us.articles.ListTrashed(ctx, user.SiteID)
+	if err != nil {
+		return models.UserExport{}, fmt.Errorf("unable to fetch trash: %w", err)
+	}
+	articles = append(articles, trashed...)
+
+	articles = slices.DeleteFunc(articles, func(a models.Article) bool {
+		return !a.AuthoredBy(user.ID)
+	})
+
+	comments, err := us.commentsOf(ctx, user)
+	if err != nil {
+		return models.UserExport{}, err
+	}
+
+	consents, err := us.consents.ListByEmail(ctx, user.SiteID, user.Email)
+	if err != nil {
+		return models.UserExport{}, fmt.Errorf("unable to fetch consents: %w", err)
+	}
+
+	return models.UserExport{
+		User:       user,
+		Articles:   articles,
+		Comments:   comments,
+		Consents:   consents,
+		ExportedAt: us.clock.Now(),
+	}, nil
+}
+
+/*
+GetUserBySlug retrieves the user of the site held by the context whose name has the
+given slug (e.g. "jane-doe" for "Jane Doe"), whatever its case, wrapping
+`repository.ErrNotFound` if no such user exists. The first user created wins when
+several users share the slug.
+*/
+func (us *UserServiceImpl) GetUserBySlug(
+	ctx context.Context,
+	slug string,
+) (models.User, error) {
+	slug = strings.ToLower(slug)
+	query := repository.UserQuery{
+		Page: pagination.Page{Number: 1, Size: pagination.MaxSize},
+	}
+
+	for {
+		users, total, err := us.users.Query(ctx, tenant.SiteID(ctx), query)
+		if err != nil {
+			return models.User{}, fmt.Errorf("unable to fetch users: %w", err)
+		}
+
+		for _, user := range users {
+			if markdown.Slugify(user.Name) == slug {
+				return user, nil
+			}
+		}
+
+		if query.Page.Offset()+len(users) >= total || len(users) == 0 {
+			return models.User{}, fmt.Errorf(
+				"unable to fetch user %q: %w", slug, repository.ErrNotFound,
+			)
+		}
+
+		query.Page.Number++
+	}
+}
+
+/*
+GetArticlesOfUser retrieves a page of the articles authored by the user of the site held
+by the context identified by id and matching the query (newest first unless it sorts
+them otherwise), along with the number of articles matching the query. The author of
+the query is overridden by the user.
+
+`repository.ErrNotFound` is returned (wrapped) if no such user exists.
+*/
+func (us *UserServiceImpl) GetArticlesOfUser(
+	ctx context.Context,
+	id uuid.UUID,
+	query repository.ArticleQuery,
+) ([]models.Article, int, error) {
+	user, err := us.GetUserByID(ctx, id)
+	if err != nil {
+		return []models.Article{}, 0, err
+	}
+
+	query.Author, query.AuthorID = "", user.ID
+	articles, total, err := us.articles.Query(ctx, user.SiteID, query)
+	if err != nil {
+		return []models.Article{}, 0, fmt.Errorf("unable to fetch articles: %w", err)
+	}
+
+	if err := countComments(ctx, us.comments, articles); err != nil {
+		return []models.Article{}, 0, err
+	}
+	allInSiteZone(ctx, articles)
+
+	return articles, total, nil
+}
+
+// commentsOf returns the comments made by the user, i.e. with their email address.
+func (us *UserServiceImpl) commentsOf(
+	ctx context.Context,
+	user models.User,
+) ([]models.Comment, error) {
+	comments, err := us.comments.List(ctx, user.SiteID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch comments: %w", err)
+	}
+
+	return slices.DeleteFunc(comments, func(c models.Comment) bool {
+		return !strings.EqualFold(c.Email, user.Email)
+	}), nil
+}
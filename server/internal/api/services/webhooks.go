@@ -0,0 +1,651 @@
+/*
+Package services provides operations for the outbound webhooks of the sites.
+
+The primary interface, `WebhookService`, defines methods to manage the webhooks of a
+site, i.e. the endpoints its events are posted to, and to debug their deliveries. The
+`WebhookServiceImpl` struct provides the concrete implementation of these methods, and
+posts the events published on the sites to their webhooks (see
+`WebhookServiceImpl.Dispatch`).
+
+The events are posted in the background, as a task per webhook and event, signed with
+the secret of the webhook (see the `webhook` package). A failed delivery is retried by
+the task queue, with an exponential backoff and a random jitter, and a webhook whose
+endpoint keeps failing is disabled automatically, its administrators being notified by
+email and by a `webhook.disabled` event, until it is enabled again. Every attempt is
+kept in the delivery log of the webhook, along with the response of its endpoint, and
+can be replayed on request.
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/events"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+	"github.com/Weburz/burzcontent/server/internal/webhook"
+)
+
+const (
+	// deliverWebhookTask is the kind of the tasks posting the events to the webhooks.
+	deliverWebhookTask = "webhook.deliver"
+
+	// webhookFailedEvent is the type of the events of the failed deliveries.
+	webhookFailedEvent = "webhook.failed"
+
+	// maxWebhookFailures is the number of consecutive failed attempts after which a
+	// webhook is disabled, provided it has been failing for webhookFailurePeriod.
+	maxWebhookFailures = 10
+
+	// webhookFailurePeriod is the time a webhook has to keep failing for to be
+	// disabled, so that a short outage of its endpoint does not disable it.
+	webhookFailurePeriod = time.Hour
+)
+
+// WebhookClient posts the events to the endpoints of the webhooks, like
+// `webhook.Client` does.
+type WebhookClient interface {
+	Send(
+		ctx context.Context,
+		endpoint, secret string,
+		msg webhook.Message,
+	) (int, error)
+}
+
+/*
+webhookDelivery represents the payload of the tasks posting the events to the
+webhooks.
+
+Fields:
+  - ID: The unique identifier of the delivery (UUID).
+  - SiteID: The unique identifier of the site of the webhook (UUID).
+  - WebhookID: The unique identifier of the webhook (UUID).
+  - Event: The type of the event.
+  - Payload: The JSON body posted to the endpoint, see `webhookPayload`.
+*/
+type webhookDelivery struct {
+	ID        uuid.UUID       `json:"id"`
+	SiteID    uuid.UUID       `json:"site_id"`
+	WebhookID uuid.UUID       `json:"webhook_id"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// webhookPayload represents the JSON body posted to the endpoint of a webhook: the
+// event, along with the unique identifier of its delivery.
+type webhookPayload struct {
+	ID uuid.UUID `json:"id"`
+	events.Event
+}
+
+// WebhookService defines the methods for managing the outbound webhooks of a site.
+type WebhookService interface {
+	// GetAllWebhooks retrieves every webhook of the site.
+	GetAllWebhooks(ctx context.Context) ([]models.Webhook, error)
+
+	// GetWebhookByID fetches a webhook by its unique ID.
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (models.Webhook, error)
+
+	// CreateWebhook creates a new webhook of the site, returning the stored webhook
+	// along with its secret.
+	CreateWebhook(
+		ctx context.Context,
+		webhook models.Webhook,
+	) (models.Webhook, string, error)
+
+	// UpdateWebhook updates the URL, events and state of a webhook.
+	UpdateWebhook(
+		ctx context.Context,
+		id uuid.UUID,
+		webhook models.Webhook,
+	) (models.Webhook, error)
+
+	// RotateSecret replaces the secret of the webhook identified by its unique ID,
+	// returning the stored webhook along with its new secret.
+	RotateSecret(ctx context.Context, id uuid.UUID) (models.Webhook, string, error)
+
+	// DeleteWebhook removes a webhook identified by its unique ID.
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+
+	// GetDeliveries retrieves a page of the delivery attempts of a webhook, along with
+	// the number of its attempts.
+	GetDeliveries(
+		ctx context.Context,
+		webhookID uuid.UUID,
+		page pagination.Page,
+	) ([]models.WebhookDelivery, int, error)
+
+	// ReplayDelivery posts the event of a delivery attempt to its webhook again.
+	ReplayDelivery(ctx context.Context, id uuid.UUID) (models.WebhookDelivery, error)
+}
+
+// WebhookServiceImpl is the concrete implementation of the WebhookService interface.
+type WebhookServiceImpl struct {
+	mu         sync.Mutex // Serializes the updates, so that no failure is missed
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+	sites      repository.SiteRepository
+	users      repository.UserRepository
+	client     WebhookClient
+	tasks      TaskQueue
+	mailer     mailer.Mailer
+	templates  TemplateProvider
+	events     EventPublisher
+	ids        IDGenerator
+	clock      Clock
+}
+
+/*
+NewWebhookService creates and returns a new instance of WebhookServiceImpl backed by
+the given repositories, posting the events with the given client, as tasks of the given
+queue, and logging their attempts to the given delivery repository. The administrators
+of the sites are notified of the disabled webhooks with the given mailer, rendered with
+the templates of their site, and with the given publisher.
+*/
+func NewWebhookService(
+	webhooks repository.WebhookRepository,
+	deliveries repository.WebhookDeliveryRepository,
+	sites repository.SiteRepository,
+	users repository.UserRepository,
+	client WebhookClient,
+	tasks TaskQueue,
+	mailer mailer.Mailer,
+	templates TemplateProvider,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *WebhookServiceImpl {
+	ws := &WebhookServiceImpl{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		sites:      sites,
+		users:      users,
+		client:     client,
+		tasks:      tasks,
+		mailer:     mailer,
+		templates:  templates,
+		events:     events,
+		ids:        ids,
+		clock:      clock,
+	}
+	tasks.Handle(deliverWebhookTask, ws.runDeliver)
+
+	return ws
+}
+
+// GetAllWebhooks retrieves every webhook of the site held by the context.
+func (ws *WebhookServiceImpl) GetAllWebhooks(
+	ctx context.Context,
+) ([]models.Webhook, error) {
+	webhooks, err := ws.webhooks.List(ctx, tenant.SiteID(ctx))
+	if err != nil {
+		return []models.Webhook{}, fmt.Errorf("unable to fetch webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhookByID fetches a webhook of the site held by the context, wrapping
+// `repository.ErrNotFound` if no such webhook exists.
+func (ws *WebhookServiceImpl) GetWebhookByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Webhook, error) {
+	webhook, err := ws.webhooks.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("unable to fetch webhook %s: %w", id, err)
+	}
+
+	return webhook, nil
+}
+
+/*
+CreateWebhook creates a new, enabled, webhook in the site held by the context, with a
+new random secret.
+
+The secret is only returned by this method (and by `RotateSecret`), the webhook never
+being serialized with it.
+*/
+func (ws *WebhookServiceImpl) CreateWebhook(
+	ctx context.Context,
+	hook models.Webhook,
+) (models.Webhook, string, error) {
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return models.Webhook{}, "", err
+	}
+
+	now := ws.clock.Now()
+	hook = models.Webhook{
+		ID:        ws.ids.NewID(),
+		SiteID:    tenant.SiteID(ctx),
+		URL:       hook.URL,
+		Events:    normalizeEvents(hook.Events),
+		Secret:    secret,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := ws.webhooks.Create(ctx, hook); err != nil {
+		return models.Webhook{}, "", fmt.Errorf("unable to create webhook: %w", err)
+	}
+
+	return hook, secret, nil
+}
+
+/*
+UpdateWebhook updates the URL, events and state of an existing webhook of the site held
+by the context. Enabling a disabled webhook resets its failures.
+
+`repository.ErrNotFound` is returned (wrapped) if no such webhook exists.
+*/
+func (ws *WebhookServiceImpl) UpdateWebhook(
+	ctx context.Context,
+	id uuid.UUID,
+	hook models.Webhook,
+) (models.Webhook, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	existing, err := ws.GetWebhookByID(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	if hook.Enabled && !existing.Enabled {
+		existing.Failures = 0
+		existing.FailingSince = nil
+		existing.LastError = ""
+		existing.DisabledAt = nil
+	}
+
+	existing.URL = hook.URL
+	existing.Events = normalizeEvents(hook.Events)
+	existing.Enabled = hook.Enabled
+	existing.UpdatedAt = ws.clock.Now()
+
+	if err := ws.webhooks.Update(ctx, existing); err != nil {
+		return models.Webhook{}, fmt.Errorf("unable to update webhook %s: %w", id, err)
+	}
+
+	return existing, nil
+}
+
+/*
+RotateSecret replaces the secret of an existing webhook of the site held by the
+context with a new random one, e.g. when it leaked. The events are signed with the new
+secret from then on, including the retries of the failed deliveries.
+
+`repository.ErrNotFound` is returned (wrapped) if no such webhook exists.
+*/
+func (ws *WebhookServiceImpl) RotateSecret(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.Webhook, string, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	existing, err := ws.GetWebhookByID(ctx, id)
+	if err != nil {
+		return models.Webhook{}, "", err
+	}
+
+	if existing.Secret, err = webhook.GenerateSecret(); err != nil {
+		return models.Webhook{}, "", err
+	}
+	existing.UpdatedAt = ws.clock.Now()
+
+	if err := ws.webhooks.Update(ctx, existing); err != nil {
+		return models.Webhook{}, "", fmt.Errorf(
+			"unable to rotate secret of webhook %s: %w", id, err,
+		)
+	}
+
+	return existing, existing.Secret, nil
+}
+
+// DeleteWebhook removes a webhook of the site held by the context, along with its
+// delivery log, wrapping `repository.ErrNotFound` if no such webhook exists. The
+// pending deliveries of the webhook are dropped.
+func (ws *WebhookServiceImpl) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	siteID := tenant.SiteID(ctx)
+
+	if err := ws.webhooks.Delete(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to delete webhook %s: %w", id, err)
+	}
+
+	if err := ws.deliveries.DeleteByWebhook(ctx, siteID, id); err != nil {
+		return fmt.Errorf("unable to delete deliveries of webhook %s: %w", id, err)
+	}
+
+	return nil
+}
+
+/*
+GetDeliveries retrieves a page of the delivery attempts of a webhook of the site held
+by the context, most recent first, along with the number of its attempts. Only the
+last 1000 attempts of each webhook are kept.
+
+`repository.ErrNotFound` is returned (wrapped) if no such webhook exists.
+*/
+func (ws *WebhookServiceImpl) GetDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	page pagination.Page,
+) ([]models.WebhookDelivery, int, error) {
+	hook, err := ws.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		return []models.WebhookDelivery{}, 0, err
+	}
+
+	deliveries, err := ws.deliveries.List(ctx, hook.SiteID, hook.ID)
+	if err != nil {
+		return []models.WebhookDelivery{}, 0, fmt.Errorf(
+			"unable to fetch deliveries of webhook %s: %w", webhookID, err,
+		)
+	}
+
+	return pagination.Slice(deliveries, page), len(deliveries), nil
+}
+
+/*
+ReplayDelivery posts the event of a delivery attempt of the site held by the context to
+its webhook again, at once and whether the webhook is enabled or not (e.g. to check its
+endpoint before enabling it again), and returns the new attempt.
+
+The replay is signed with the current secret of the webhook, and shares the delivery ID
+of the replayed attempt, so that the endpoints processing each delivery once ignore the
+deliveries they already processed. It is not retried if it fails, the outcome being
+told by the returned attempt, but it counts towards the failures of the webhook.
+
+`repository.ErrNotFound` is returned (wrapped) if no such attempt exists.
+*/
+func (ws *WebhookServiceImpl) ReplayDelivery(
+	ctx context.Context,
+	id uuid.UUID,
+) (models.WebhookDelivery, error) {
+	siteID := tenant.SiteID(ctx)
+
+	replayed, err := ws.deliveries.Get(ctx, siteID, id)
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf(
+			"unable to fetch delivery %s: %w", id, err,
+		)
+	}
+
+	hook, err := ws.webhooks.Get(ctx, siteID, replayed.WebhookID)
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf(
+			"unable to fetch webhook %s: %w", replayed.WebhookID, err,
+		)
+	}
+
+	attempt, _ := ws.deliver(ctx, hook, webhookDelivery{
+		ID:        replayed.DeliveryID,
+		SiteID:    hook.SiteID,
+		WebhookID: hook.ID,
+		Event:     replayed.Event,
+		Payload:   replayed.Payload,
+	}, 1, true)
+
+	return attempt, nil
+}
+
+/*
+Dispatch queues the delivery of the event to every enabled webhook of its site which
+the event matches, i.e. whose events are empty or hold its type or a pattern matching
+it (e.g. "article.*", see `path.Match`).
+
+It is meant to observe the events published on the sites (see `events.Broker.Observe`),
+hence it returns at once, and the delivery of the event is skipped if it can not be
+queued (e.g. if the queue is full), like the events are skipped by the subscribers which
+do not keep up. The failed deliveries (`webhook.failed`) are never delivered, lest the
+failing webhooks keep failing on the failures of one another.
+*/
+func (ws *WebhookServiceImpl) Dispatch(event events.Event) {
+	if event.Type == webhookFailedEvent {
+		return
+	}
+
+	ctx := context.Background()
+
+	webhooks, err := ws.webhooks.List(ctx, event.SiteID)
+	if err != nil {
+		return
+	}
+
+	for _, hook := range webhooks {
+		if !hook.Enabled || !matchesEvent(hook.Events, event.Type) {
+			continue
+		}
+
+		deliveryID := ws.ids.NewID()
+		payload, err := json.Marshal(webhookPayload{ID: deliveryID, Event: event})
+		if err != nil {
+			return
+		}
+
+		_ = ws.tasks.Enqueue(ctx, deliverWebhookTask, webhookDelivery{
+			ID:        deliveryID,
+			SiteID:    hook.SiteID,
+			WebhookID: hook.ID,
+			Event:     event.Type,
+			Payload:   payload,
+		})
+	}
+}
+
+/*
+runDeliver runs a task posting an event to the endpoint of a webhook, and records the
+outcome of the attempt on the webhook, disabling it if its endpoint keeps failing.
+
+An error is returned, for the task to be retried, if the endpoint could not be reached
+or did not accept the event. The deliveries of the webhooks which were deleted or
+disabled meanwhile are dropped.
+*/
+func (ws *WebhookServiceImpl) runDeliver(ctx context.Context, task queue.Task) error {
+	var delivery webhookDelivery
+	if err := json.Unmarshal(task.Payload, &delivery); err != nil {
+		return fmt.Errorf("malformed webhook delivery: %w", err)
+	}
+
+	hook, err := ws.webhooks.Get(ctx, delivery.SiteID, delivery.WebhookID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to fetch webhook %s: %w", delivery.WebhookID, err)
+	} else if !hook.Enabled {
+		return nil
+	}
+
+	if _, err := ws.deliver(ctx, hook, delivery, task.Attempts, false); err != nil {
+		return fmt.Errorf("unable to deliver event to webhook %s: %w", hook.ID, err)
+	}
+
+	return nil
+}
+
+/*
+deliver makes an attempt to post the event of the delivery to the endpoint of the
+webhook, keeps the attempt in the delivery log and records its outcome on the webhook
+(see `recordAttempt`), publishing a `webhook.failed` event holding the attempt if it
+failed. The attempt is returned, along with its error if it failed.
+*/
+func (ws *WebhookServiceImpl) deliver(
+	ctx context.Context,
+	hook models.Webhook,
+	delivery webhookDelivery,
+	attempt int,
+	replay bool,
+) (models.WebhookDelivery, error) {
+	now := ws.clock.Now()
+	start := time.Now()
+
+	status, err := ws.client.Send(ctx, hook.URL, hook.Secret, webhook.Message{
+		ID:       delivery.ID.String(),
+		Event:    delivery.Event,
+		Payload:  delivery.Payload,
+		SignedAt: now,
+	})
+
+	logged := models.WebhookDelivery{
+		ID:          ws.ids.NewID(),
+		SiteID:      hook.SiteID,
+		WebhookID:   hook.ID,
+		DeliveryID:  delivery.ID,
+		Event:       delivery.Event,
+		Payload:     delivery.Payload,
+		Attempt:     attempt,
+		Replay:      replay,
+		StatusCode:  status,
+		LatencyMS:   time.Since(start).Milliseconds(),
+		AttemptedAt: now,
+	}
+	if err != nil {
+		logged.Error = err.Error()
+	}
+
+	// The delivery log is best-effort, the attempt standing even if it is not logged
+	_ = ws.deliveries.Create(ctx, logged)
+	ws.recordAttempt(ctx, hook, err)
+
+	if err != nil {
+		ws.events.Publish(hook.SiteID, webhookFailedEvent, logged)
+	}
+
+	return logged, err
+}
+
+/*
+recordAttempt records the outcome of an attempt to post an event to the webhook: a
+successful attempt resets its failures, while a failed one counts as one more failure.
+The webhook is disabled once it failed `maxWebhookFailures` times in a row for at least
+`webhookFailurePeriod`, and its administrators are notified.
+*/
+func (ws *WebhookServiceImpl) recordAttempt(
+	ctx context.Context,
+	hook models.Webhook,
+	attemptErr error,
+) {
+	ws.mu.Lock()
+
+	// Refetch the webhook, which may have been updated by another attempt meanwhile
+	hook, err := ws.webhooks.Get(ctx, hook.SiteID, hook.ID)
+	if err != nil || (attemptErr == nil && hook.Failures == 0) {
+		ws.mu.Unlock()
+		return
+	}
+
+	now := ws.clock.Now()
+	disabled := false
+	if attemptErr == nil {
+		hook.Failures = 0
+		hook.FailingSince = nil
+		hook.LastError = ""
+	} else {
+		hook.Failures++
+		hook.LastError = attemptErr.Error()
+		if hook.FailingSince == nil {
+			hook.FailingSince = &now
+		}
+
+		if hook.Enabled && hook.Failures >= maxWebhookFailures &&
+			now.Sub(*hook.FailingSince) >= webhookFailurePeriod {
+			hook.Enabled = false
+			hook.DisabledAt = &now
+			disabled = true
+		}
+	}
+	hook.UpdatedAt = now
+
+	err = ws.webhooks.Update(ctx, hook)
+	ws.mu.Unlock()
+
+	if err == nil && disabled {
+		ws.events.Publish(hook.SiteID, "webhook.disabled", hook)
+		ws.notifyDisabled(ctx, hook)
+	}
+}
+
+// notifyDisabled emails the administrators of the site of the webhook that it was
+// disabled. The webhook stays disabled even if the email can not be sent, the
+// administrators being notified by the event anyway.
+func (ws *WebhookServiceImpl) notifyDisabled(ctx context.Context, hook models.Webhook) {
+	site, err := ws.sites.Get(ctx, hook.SiteID)
+	if err != nil {
+		return
+	}
+	ctx = tenant.NewContext(ctx, site)
+
+	admins, _, err := ws.users.Query(ctx, site.ID, repository.UserQuery{
+		Role: auth.RoleAdmin,
+		Page: pagination.Page{Number: 1, Size: pagination.MaxSize},
+	})
+	if err != nil || len(admins) == 0 {
+		return
+	}
+
+	var to []string
+	for _, admin := range admins {
+		to = append(to, admin.Email)
+	}
+
+	templates, err := ws.templates.ActiveTemplates(ctx)
+	if err != nil {
+		return
+	}
+
+	email, err := mailer.RenderWith(templates, "webhook_disabled", to, map[string]any{
+		"SiteName":     site.Name,
+		"URL":          hook.URL,
+		"Failures":     hook.Failures,
+		"FailingSince": *hook.FailingSince,
+		"LastError":    hook.LastError,
+	})
+	if err != nil {
+		return
+	}
+
+	_ = ws.mailer.Send(ctx, email)
+}
+
+// matchesEvent reports whether the type of an event matches the events of a webhook,
+// i.e. one of their patterns, or every type if there is none.
+func matchesEvent(patterns []string, kind string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := path.Match(pattern, kind)
+		return ok
+	})
+}
+
+// normalizeEvents returns the events of a webhook without duplicates, and as an empty
+// slice rather than nil.
+func normalizeEvents(kinds []string) []string {
+	normalized := []string{}
+	for _, kind := range kinds {
+		if !slices.Contains(normalized, kind) {
+			normalized = append(normalized, kind)
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,512 @@
+/*
+Package services provides operations for the webmentions of the articles.
+
+The primary interface, `WebmentionService`, defines methods to receive the webmentions
+sent to the articles (see https://www.w3.org/TR/webmention/), to list them and to
+moderate them. The `WebmentionServiceImpl` struct provides the concrete implementation
+of these methods, and sends the webmentions of the newly published articles to the
+pages they link to (see `PublicationNotifier`).
+
+The received webmentions are verified in the background, as tasks of the task queue of
+the server: a webmention is only stored, pending moderation, once its source was
+fetched and found to link to its target, and it is removed if its source no longer
+does when it is sent again. Only the approved webmentions are displayed to the readers.
+The webmentions of the articles are sent in the background too, as a task per link,
+and both are retried by the task queue when the other site can not be reached.
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+	"github.com/Weburz/burzcontent/server/internal/webmention"
+)
+
+const (
+	// webmentionTimeout is the time the verification of a received webmention, or the
+	// sending of the webmentions of an article, can take.
+	webmentionTimeout = time.Minute
+
+	// maxSentWebmentions is the number of links of an article webmentions are sent to.
+	maxSentWebmentions = 20
+
+	// verifyWebmentionTask is the kind of the tasks verifying the received
+	// webmentions.
+	verifyWebmentionTask = "webmention.verify"
+
+	// sendWebmentionTask is the kind of the tasks sending the webmentions of the
+	// articles.
+	sendWebmentionTask = "webmention.send"
+)
+
+// ErrInvalidWebmention is returned when a received webmention is malformed, or does
+// not target a published article of the site.
+var ErrInvalidWebmention = errors.New("invalid webmention")
+
+// WebmentionClient verifies the received webmentions and sends the webmentions of the
+// articles, like `webmention.Client` does.
+type WebmentionClient interface {
+	Discover(ctx context.Context, target string) (string, error)
+	Send(ctx context.Context, endpoint, source, target string) error
+	Verify(ctx context.Context, source, target string) (string, error)
+}
+
+/*
+receivedWebmention represents the payload of the tasks verifying the received
+webmentions.
+
+Fields:
+  - SiteID: The unique identifier of the site of the target article (UUID).
+  - ArticleID: The unique identifier of the target article (UUID).
+  - Source: The URL of the page linking to the article.
+  - Target: The URL of the article.
+*/
+type receivedWebmention struct {
+	SiteID    uuid.UUID `json:"site_id"`
+	ArticleID uuid.UUID `json:"article_id"`
+	Source    string    `json:"source"`
+	Target    string    `json:"target"`
+}
+
+/*
+sentWebmention represents the payload of the tasks sending the webmentions of the
+articles.
+
+Fields:
+  - Source: The URL of the article.
+  - Target: The URL of the page the article links to.
+*/
+type sentWebmention struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// PublicationNotifier is notified of each article of a site which is published for
+// the first time.
+type PublicationNotifier interface {
+	NotifyPublished(ctx context.Context, article models.Article)
+}
+
+// WebmentionService defines the methods for the webmentions of the articles.
+type WebmentionService interface {
+	// ReceiveWebmention receives the webmention of source linking to target.
+	ReceiveWebmention(ctx context.Context, source, target string) error
+
+	// GetWebmentions retrieves the webmentions received by the article, whatever
+	// their status.
+	GetWebmentions(
+		ctx context.Context,
+		articleID uuid.UUID,
+	) ([]models.Webmention, error)
+
+	// GetApprovedWebmentions retrieves the approved webmentions received by the
+	// published article.
+	GetApprovedWebmentions(
+		ctx context.Context,
+		articleID uuid.UUID,
+	) ([]models.Webmention, error)
+
+	// ModerateWebmention sets the moderation status of a webmention.
+	ModerateWebmention(
+		ctx context.Context,
+		id uuid.UUID,
+		status string,
+	) (models.Webmention, error)
+
+	// DeleteWebmention removes a webmention identified by its unique ID.
+	DeleteWebmention(ctx context.Context, id uuid.UUID) error
+}
+
+// WebmentionServiceImpl is the concrete implementation of the WebmentionService
+// interface.
+type WebmentionServiceImpl struct {
+	mu          sync.Mutex // Serializes the verifications, storing each source once
+	webmentions repository.WebmentionRepository
+	articles    repository.ArticleRepository
+	client      WebmentionClient
+	tasks       TaskQueue
+	events      EventPublisher
+	ids         IDGenerator
+	clock       Clock
+}
+
+/*
+NewWebmentionService creates and returns a new instance of WebmentionServiceImpl
+backed by the given repositories, verifying and sending the webmentions with the given
+client, as tasks of the given queue, and publishing the verified ones with the given
+publisher. The new webmentions are assigned their IDs by the given generator, and are
+stamped with the time told by the given clock.
+*/
+func NewWebmentionService(
+	webmentions repository.WebmentionRepository,
+	articles repository.ArticleRepository,
+	client WebmentionClient,
+	tasks TaskQueue,
+	events EventPublisher,
+	ids IDGenerator,
+	clock Clock,
+) *WebmentionServiceImpl {
+	ws := &WebmentionServiceImpl{
+		webmentions: webmentions,
+		articles:    articles,
+		client:      client,
+		tasks:       tasks,
+		events:      events,
+		ids:         ids,
+		clock:       clock,
+	}
+
+	tasks.Handle(verifyWebmentionTask, ws.runVerify)
+	tasks.Handle(sendWebmentionTask, ws.runSend)
+
+	return ws
+}
+
+/*
+ReceiveWebmention receives the webmention of source linking to target, which has to be
+the URL of a published article of the site held by the context (e.g.
+`https://blog.weburz.com/articles/{id}`).
+
+The webmention is verified in the background: it is stored, pending moderation, (or
+updated, if it was already received) once source is found to link to target, with a
+`webmention.received` event, and removed if source no longer does.
+`ErrInvalidWebmention` is returned (wrapped) if source or target is not an HTTP(S) URL,
+if they are the same URL or if target is not a published article of the site.
+*/
+func (ws *WebmentionServiceImpl) ReceiveWebmention(
+	ctx context.Context,
+	source, target string,
+) error {
+	sourceURL, sourceErr := url.Parse(source)
+	targetURL, targetErr := url.Parse(target)
+	if sourceErr != nil || targetErr != nil || !isWebURL(sourceURL) ||
+		!isWebURL(targetURL) {
+		return fmt.Errorf(
+			"%w: source and target must be HTTP(S) URLs", ErrInvalidWebmention,
+		)
+	} else if source == target {
+		return fmt.Errorf(
+			"%w: source and target are the same URL", ErrInvalidWebmention,
+		)
+	}
+
+	article, err := ws.articleOf(ctx, targetURL)
+	if err != nil {
+		return err
+	}
+
+	err = ws.tasks.Enqueue(ctx, verifyWebmentionTask, receivedWebmention{
+		SiteID:    article.SiteID,
+		ArticleID: article.ID,
+		Source:    source,
+		Target:    target,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to queue webmention: %w", err)
+	}
+
+	return nil
+}
+
+/*
+runVerify runs a task verifying that the source of a received webmention links to its
+target, storing (or updating) the webmention received by the article if it does and
+removing it if it no longer does.
+
+An error is returned, for the task to be retried, if the source could not be fetched
+(e.g. it is temporarily down).
+*/
+func (ws *WebmentionServiceImpl) runVerify(ctx context.Context, task queue.Task) error {
+	var received receivedWebmention
+	if err := json.Unmarshal(task.Payload, &received); err != nil {
+		return fmt.Errorf("malformed webmention: %w", err)
+	}
+
+	article, err := ws.articles.Get(ctx, received.SiteID, received.ArticleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The article was deleted meanwhile
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to fetch article %s: %w", received.ArticleID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webmentionTimeout)
+	defer cancel()
+
+	source, target := received.Source, received.Target
+	title, err := ws.client.Verify(ctx, source, target)
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	existing, getErr := ws.webmentions.GetBySource(
+		ctx,
+		article.SiteID,
+		article.ID,
+		source,
+	)
+	switch {
+	case errors.Is(err, webmention.ErrNoLink),
+		errors.Is(err, webmention.ErrSourceGone):
+		if getErr == nil {
+			_ = ws.webmentions.Delete(ctx, article.SiteID, existing.ID)
+		}
+		return nil
+	case errors.Is(err, webmention.ErrForbiddenAddress):
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to verify webmention of %s: %w", source, err)
+	case getErr == nil:
+		existing.Target = target
+		existing.Title = title
+		existing.UpdatedAt = ws.clock.Now()
+		_ = ws.webmentions.Update(ctx, existing)
+		return nil
+	}
+
+	now := ws.clock.Now()
+	stored := models.Webmention{
+		ID:        ws.ids.NewID(),
+		SiteID:    article.SiteID,
+		ArticleID: article.ID,
+		Source:    source,
+		Target:    target,
+		Title:     title,
+		Status:    models.WebmentionPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := ws.webmentions.Create(ctx, stored); err == nil {
+		ws.events.Publish(article.SiteID, "webmention.received", stored)
+	}
+
+	return nil
+}
+
+/*
+GetWebmentions retrieves the webmentions received by the article of the site held by
+the context, whatever their status, oldest first.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists.
+*/
+func (ws *WebmentionServiceImpl) GetWebmentions(
+	ctx context.Context,
+	articleID uuid.UUID,
+) ([]models.Webmention, error) {
+	siteID := tenant.SiteID(ctx)
+
+	if _, err := ws.articles.Get(ctx, siteID, articleID); err != nil {
+		return []models.Webmention{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	webmentions, err := ws.webmentions.ListByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return []models.Webmention{}, fmt.Errorf(
+			"unable to fetch webmentions: %w", err,
+		)
+	}
+
+	return webmentions, nil
+}
+
+/*
+GetApprovedWebmentions retrieves the approved webmentions received by the published
+article of the site held by the context, oldest first.
+
+`repository.ErrNotFound` is returned (wrapped) if no such article exists or if it is
+not published.
+*/
+func (ws *WebmentionServiceImpl) GetApprovedWebmentions(
+	ctx context.Context,
+	articleID uuid.UUID,
+) ([]models.Webmention, error) {
+	siteID := tenant.SiteID(ctx)
+
+	article, err := ws.articles.Get(ctx, siteID, articleID)
+	if err == nil && !article.IsPublished {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return []models.Webmention{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	webmentions, err := ws.webmentions.ListByArticle(ctx, siteID, articleID)
+	if err != nil {
+		return []models.Webmention{}, fmt.Errorf(
+			"unable to fetch webmentions: %w", err,
+		)
+	}
+
+	return slices.DeleteFunc(webmentions, func(w models.Webmention) bool {
+		return w.Status != models.WebmentionApproved
+	}), nil
+}
+
+/*
+ModerateWebmention sets the moderation status of the webmention of the site held by the
+context identified by id, either "approved" or "rejected".
+
+`repository.ErrNotFound` is returned (wrapped) if no such webmention exists.
+*/
+func (ws *WebmentionServiceImpl) ModerateWebmention(
+	ctx context.Context,
+	id uuid.UUID,
+	status string,
+) (models.Webmention, error) {
+	webmention, err := ws.webmentions.Get(ctx, tenant.SiteID(ctx), id)
+	if err != nil {
+		return models.Webmention{}, fmt.Errorf(
+			"unable to fetch webmention %s: %w", id, err,
+		)
+	}
+
+	webmention.Status = status
+	webmention.UpdatedAt = ws.clock.Now()
+
+	if err := ws.webmentions.Update(ctx, webmention); err != nil {
+		return models.Webmention{}, fmt.Errorf(
+			"unable to update webmention %s: %w", id, err,
+		)
+	}
+
+	return webmention, nil
+}
+
+// DeleteWebmention removes the webmention of the site held by the context identified
+// by id, wrapping `repository.ErrNotFound` if no such webmention exists.
+func (ws *WebmentionServiceImpl) DeleteWebmention(
+	ctx context.Context,
+	id uuid.UUID,
+) error {
+	if err := ws.webmentions.Delete(ctx, tenant.SiteID(ctx), id); err != nil {
+		return fmt.Errorf("unable to delete webmention %s: %w", id, err)
+	}
+
+	return nil
+}
+
+/*
+NotifyPublished sends, in the background, the webmentions of the newly published
+article to the pages of the other sites it links to (up to `maxSentWebmentions` of
+them) which advertise a webmention endpoint.
+
+Each webmention is sent by a task of its own, which is retried if the page or its
+endpoint can not be reached. The pages without an endpoint are skipped.
+*/
+func (ws *WebmentionServiceImpl) NotifyPublished(
+	ctx context.Context,
+	article models.Article,
+) {
+	site, _ := tenant.FromContext(ctx)
+	source := site.URL("/articles/" + article.ID.String())
+
+	var targets []string
+	for _, link := range webmention.Links(article.Content, nil) {
+		if u, err := url.Parse(link); err == nil && !isSiteHost(site, u.Hostname()) {
+			targets = append(targets, link)
+		}
+	}
+
+	for _, target := range targets[:min(len(targets), maxSentWebmentions)] {
+		sent := sentWebmention{Source: source, Target: target}
+		_ = ws.tasks.Enqueue(ctx, sendWebmentionTask, sent)
+	}
+}
+
+// runSend runs a task sending the webmention of an article to the endpoint advertised
+// by the page it links to, returning an error for the task to be retried if the page
+// or the endpoint can not be reached.
+func (ws *WebmentionServiceImpl) runSend(ctx context.Context, task queue.Task) error {
+	var sent sentWebmention
+	if err := json.Unmarshal(task.Payload, &sent); err != nil {
+		return fmt.Errorf("malformed webmention: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webmentionTimeout)
+	defer cancel()
+
+	endpoint, err := ws.client.Discover(ctx, sent.Target)
+	if errors.Is(err, webmention.ErrNoEndpoint) ||
+		errors.Is(err, webmention.ErrForbiddenAddress) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to discover endpoint of %s: %w", sent.Target, err)
+	}
+
+	return ws.client.Send(ctx, endpoint, sent.Source, sent.Target)
+}
+
+/*
+articleOf returns the published article of the site held by the context whose URL is
+target, i.e. `/articles/{id}` (under the `/s/{site}` path prefix of the site, if any) on
+one of the hosts of the site.
+
+`ErrInvalidWebmention` is returned (wrapped) if target is not such a URL.
+*/
+func (ws *WebmentionServiceImpl) articleOf(
+	ctx context.Context,
+	target *url.URL,
+) (models.Article, error) {
+	site, _ := tenant.FromContext(ctx)
+
+	path := strings.TrimPrefix(target.Path, "/s/"+site.Slug)
+	rawID, ok := strings.CutPrefix(strings.TrimSuffix(path, "/"), "/articles/")
+	articleID, err := uuid.Parse(rawID)
+
+	if !ok || err != nil || site.PrimaryHost() != "" &&
+		!isSiteHost(site, target.Hostname()) {
+		return models.Article{}, fmt.Errorf(
+			"%w: target is not an article of the site", ErrInvalidWebmention,
+		)
+	}
+
+	article, err := ws.articles.Get(ctx, site.ID, articleID)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && !article.IsPublished {
+		return models.Article{}, fmt.Errorf(
+			"%w: target is not a published article", ErrInvalidWebmention,
+		)
+	} else if err != nil {
+		return models.Article{}, fmt.Errorf(
+			"unable to fetch article %s: %w", articleID, err,
+		)
+	}
+
+	return article, nil
+}
+
+// isSiteHost reports whether host is one of the hostnames or verified custom domains
+// of the site.
+func isSiteHost(site models.Site, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if slices.Contains(site.Hostnames, host) {
+		return true
+	}
+
+	return slices.ContainsFunc(site.Domains, func(d models.Domain) bool {
+		return d.Verified && strings.EqualFold(d.Name, host)
+	})
+}
+
+// isWebURL reports whether the URL is an absolute HTTP(S) URL.
+func isWebURL(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
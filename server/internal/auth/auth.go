@@ -0,0 +1,128 @@
+/*
+Package auth provides the primitives used to authenticate and authorize API requests.
+
+Requests are authenticated with API keys, which are scoped to a single site and carry
+a role. Once authenticated, the `Principal` making the request is stored in the request
+context (see `NewContext` and `FromContext`) so that the middleware and the handlers
+further down the chain can authorize it.
+
+API keys are never stored in plain text; only their SHA-256 digest (see `HashKey`) is
+persisted, along with a short prefix to help humans identify a key.
+*/
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// KeyPrefix is prepended to every generated API key to make them easy to recognise
+// (e.g. by secret scanners).
+const KeyPrefix = "bzc_"
+
+// Role is the role granted to a principal, which determines what it is allowed to do.
+type Role string
+
+const (
+	// RoleAdmin is allowed to do anything within its site, including managing the API
+	// keys and reading the usage reports.
+	RoleAdmin Role = "admin"
+
+	// RoleEditor is allowed to manage every piece of content of its site.
+	RoleEditor Role = "editor"
+
+	// RoleAuthor is allowed to manage its own content.
+	RoleAuthor Role = "author"
+)
+
+// Roles lists every role which can be granted, from the most to the least privileged.
+var Roles = []Role{RoleAdmin, RoleEditor, RoleAuthor}
+
+/*
+Principal represents the authenticated party making a request.
+
+Fields:
+  - KeyID: The unique identifier of the API key used to authenticate.
+  - SiteID: The unique identifier of the site the principal is scoped to.
+  - UserID: The unique identifier of the user owning the API key, if any.
+  - Role: The role granted to the principal.
+  - Root: Whether the principal authenticated with the root API key of the
+    deployment, which is not scoped to any site.
+*/
+type Principal struct {
+	KeyID  uuid.UUID
+	SiteID uuid.UUID
+	UserID uuid.UUID
+	Role   Role
+	Root   bool
+}
+
+// HasRole reports whether the principal was granted one of the roles. Root and admin
+// principals are granted every role.
+func (p Principal) HasRole(roles ...Role) bool {
+	return p.Root || p.Role == RoleAdmin || slices.Contains(roles, p.Role)
+}
+
+// CanAccessSite reports whether the principal is allowed to access the site.
+func (p Principal) CanAccessSite(siteID uuid.UUID) bool {
+	return p.Root || p.SiteID == siteID
+}
+
+// contextKey is the unexported type of the context key holding the principal.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the principal.
+func NewContext(parent context.Context, principal Principal) context.Context {
+	return context.WithValue(parent, contextKey{}, principal)
+}
+
+// FromContext returns the principal held by the context and whether one was found.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(Principal)
+	return principal, ok
+}
+
+/*
+GenerateKey generates a new random API key.
+
+The key is made of the `KeyPrefix` followed by 32 random bytes encoded with the URL-safe
+base64 alphabet. The returned prefix (the first 12 characters of the key) can be shown
+to humans to identify the key without disclosing it.
+*/
+func GenerateKey() (key, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("unable to generate API key: %w", err)
+	}
+
+	key = KeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, key[:12], nil
+}
+
+// KeyFromHeader returns the API key presented in the headers of a request, i.e. in the
+// `Authorization` header using the `Bearer` scheme or in the `X-API-Key` header, or an
+// empty string if none is.
+func KeyFromHeader(header http.Header) string {
+	if bearer, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer "); ok {
+		return bearer
+	}
+
+	return header.Get("X-API-Key")
+}
+
+// HashKey returns the hex-encoded SHA-256 digest of the API key, which is the only
+// form an API key is ever persisted in.
+func HashKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,112 @@
+/*
+Package challenge provides the challenges the anonymous clients have to solve before
+submitting content (e.g. the comments of the readers), so that the bots are turned away
+before the spam filters run.
+
+The challenges are pluggable (see `Verifier`): a lightweight proof of work issued by
+the server itself (see `ProofOfWork`), which needs no third party, or a CAPTCHA of
+hCaptcha or Cloudflare Turnstile (see `Remote`), whose widget is rendered by the
+frontend and whose response is verified with the provider. Either way, the client
+fetches the challenge (see `Verifier.Issue`) and presents its solution in the
+`X-Challenge-Response` header of its request (see `ResponseHeader`).
+*/
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+// ResponseHeader is the header the requests present the solution of their challenge in.
+const ResponseHeader = "X-Challenge-Response"
+
+// The providers of the challenges.
+const (
+	ProviderProofOfWork = "pow"       // A proof of work issued by the server
+	ProviderHCaptcha    = "hcaptcha"  // A CAPTCHA of hCaptcha
+	ProviderTurnstile   = "turnstile" // A CAPTCHA of Cloudflare Turnstile
+)
+
+// ErrFailed is returned when the solution of a challenge is wrong, malformed, expired
+// or was already presented.
+var ErrFailed = errors.New("challenge failed")
+
+/*
+Challenge represents a challenge a client has to solve, as issued to it.
+
+Fields:
+  - Provider: The provider of the challenge, i.e. "pow", "hcaptcha" or "turnstile".
+  - SiteKey: The public key the frontend renders the widget of the CAPTCHA with, if
+    configured.
+  - Token: The token of the proof of work.
+  - Difficulty: The number of leading zero bits the SHA-256 digest of the solution of
+    the proof of work has to have.
+  - ExpiresAt: When the proof of work expires.
+
+The solution of a proof of work is the token followed by a colon and by the decimal
+counter the client found, e.g. "eyJ...:48213", such that the SHA-256 digest of the
+solution has the required number of leading zero bits.
+*/
+type Challenge struct {
+	Provider   string     `json:"provider"`
+	SiteKey    string     `json:"site_key,omitempty"`
+	Token      string     `json:"token,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// Verifier issues the challenges and verifies their solutions.
+type Verifier interface {
+	// Issue returns a new challenge for a client to solve.
+	Issue() (Challenge, error)
+
+	// Verify verifies the solution of a challenge presented by the client of the given
+	// IP address, returning an error wrapping `ErrFailed` if it is wrong, or another
+	// error if it could not be verified at all.
+	Verify(ctx context.Context, solution, remoteIP string) error
+}
+
+/*
+Parse returns the verifier of the challenges described by spec: "pow" (or "pow:<bits>"
+with the difficulty of the proofs of work, 16 bits by default), "hcaptcha" or
+"turnstile" (optionally followed by a colon and by the site key of the widget, e.g.
+"turnstile:0x4AAAAAAA"). The proofs of work are signed with secret (a random key if
+empty), and the CAPTCHAs verified with it, in which case it is required.
+
+Example:
+
+	verifier, err := challenge.Parse("pow:18", "", services.SystemClock{})
+*/
+func Parse(spec, secret string, clock clock.Clock) (Verifier, error) {
+	provider, param, _ := strings.Cut(strings.TrimSpace(spec), ":")
+
+	switch strings.ToLower(provider) {
+	case "", ProviderProofOfWork:
+		difficulty := DefaultDifficulty
+		if param != "" {
+			var err error
+			difficulty, err = strconv.Atoi(param)
+			if err != nil || difficulty < 1 || difficulty > maxDifficulty {
+				return nil, fmt.Errorf(
+					"invalid difficulty %q, expected 1-%d bits", param, maxDifficulty,
+				)
+			}
+		}
+
+		return NewProofOfWork([]byte(secret), difficulty, clock), nil
+	case ProviderHCaptcha, ProviderTurnstile:
+		if secret == "" {
+			return nil, fmt.Errorf("no secret key to verify the %s responses", provider)
+		}
+
+		return NewRemote(strings.ToLower(provider), param, secret, remoteTimeout), nil
+	}
+
+	return nil, fmt.Errorf("unknown challenge provider %q", provider)
+}
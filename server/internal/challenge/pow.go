@@ -0,0 +1,162 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+const (
+	// DefaultDifficulty is the difficulty of the proofs of work, in bits, which takes
+	// a browser a fraction of a second to solve.
+	DefaultDifficulty = 16
+
+	// maxDifficulty is the highest difficulty of the proofs of work, in bits.
+	maxDifficulty = 32
+
+	// powLifetime is the time the clients have to present the solution of a proof of
+	// work.
+	powLifetime = 10 * time.Minute
+
+	// maxSpent is the number of presented tokens above which the expired ones are
+	// swept.
+	maxSpent = 10000
+)
+
+/*
+ProofOfWork issues the proofs of work itself, as tokens signed with HMAC-SHA256 which
+bind a random nonce to an expiry time, so that they can be verified without being
+stored. Each token is only accepted once: the tokens presented are kept until they
+expire.
+*/
+type ProofOfWork struct {
+	key        []byte
+	difficulty int
+	clock      clock.Clock
+
+	mu    sync.Mutex
+	spent map[string]time.Time // The expiry of the presented tokens, by token
+}
+
+// NewProofOfWork creates and returns a new ProofOfWork whose proofs of work have the
+// given difficulty, in bits, and are signed with the given secret key, or a random key
+// if it is empty (in which case they do not survive a restart of the server).
+func NewProofOfWork(key []byte, difficulty int, clock clock.Clock) *ProofOfWork {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+
+	return &ProofOfWork{
+		key:        key,
+		difficulty: difficulty,
+		clock:      clock,
+		spent:      make(map[string]time.Time),
+	}
+}
+
+// Issue returns a new proof of work, expiring after 10 minutes.
+func (p *ProofOfWork) Issue() (Challenge, error) {
+	expiresAt := p.clock.Now().Add(powLifetime).Truncate(time.Second).UTC()
+
+	claims := make([]byte, 8, 24)
+	binary.BigEndian.PutUint64(claims, uint64(expiresAt.Unix()))
+	claims = append(claims, make([]byte, 16)...)
+	if _, err := rand.Read(claims[8:]); err != nil {
+		return Challenge{}, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(claims)
+
+	return Challenge{
+		Provider:   ProviderProofOfWork,
+		Token:      encoded + "." + p.signature(encoded),
+		Difficulty: p.difficulty,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+// Verify verifies the solution of a proof of work (see `Challenge`), whose token has
+// to be signed with the key of p, unexpired and presented for the first time.
+func (p *ProofOfWork) Verify(_ context.Context, solution, _ string) error {
+	token, counter, ok := strings.Cut(solution, ":")
+	if !ok {
+		return ErrFailed
+	}
+	if _, err := strconv.ParseUint(counter, 10, 64); err != nil {
+		return ErrFailed
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(p.signature(encoded))) {
+		return ErrFailed
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(claims) != 24 {
+		return ErrFailed
+	}
+
+	now := p.clock.Now()
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(claims)), 0)
+	if !now.Before(expiresAt) {
+		return ErrFailed
+	}
+
+	if leadingZeros(sha256.Sum256([]byte(solution))) < p.difficulty {
+		return ErrFailed
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.spent[token]; ok {
+		return ErrFailed
+	}
+	if len(p.spent) >= maxSpent {
+		p.sweep(now)
+	}
+	p.spent[token] = expiresAt
+
+	return nil
+}
+
+// sweep removes the expired tokens from the presented ones; p.mu must be held.
+func (p *ProofOfWork) sweep(now time.Time) {
+	for token, expiresAt := range p.spent {
+		if !now.Before(expiresAt) {
+			delete(p.spent, token)
+		}
+	}
+}
+
+// signature returns the base64url encoding of the HMAC-SHA256 of the encoded claims.
+func (p *ProofOfWork) signature(encoded string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(encoded))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// leadingZeros returns the number of leading zero bits of the digest.
+func leadingZeros(digest [sha256.Size]byte) int {
+	zeros := 0
+	for _, b := range digest {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+
+	return zeros
+}
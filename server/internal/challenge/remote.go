@@ -0,0 +1,95 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// remoteTimeout is the time the providers of the CAPTCHAs have to verify a response.
+const remoteTimeout = 10 * time.Second
+
+// verifyURLs are the endpoints verifying the responses of the CAPTCHAs, by provider.
+var verifyURLs = map[string]string{
+	ProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	ProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// Remote verifies the responses of the CAPTCHAs of hCaptcha or Cloudflare Turnstile
+// with their provider, which share the same verification API.
+type Remote struct {
+	provider  string
+	siteKey   string
+	secret    string
+	verifyURL string
+	http      *http.Client
+}
+
+// NewRemote creates and returns a new Remote verifying the responses of the CAPTCHAs
+// of the given provider ("hcaptcha" or "turnstile") with the secret key, timing out
+// after timeout. The site key is handed to the clients along with the challenges.
+func NewRemote(provider, siteKey, secret string, timeout time.Duration) *Remote {
+	return &Remote{
+		provider:  provider,
+		siteKey:   siteKey,
+		secret:    secret,
+		verifyURL: verifyURLs[provider],
+		http:      &http.Client{Timeout: timeout},
+	}
+}
+
+// Issue returns the challenge of the provider, whose widget the frontend renders.
+func (r *Remote) Issue() (Challenge, error) {
+	return Challenge{Provider: r.provider, SiteKey: r.siteKey}, nil
+}
+
+// Verify verifies the response of the CAPTCHA solved by the client of the given IP
+// address with the provider.
+func (r *Remote) Verify(ctx context.Context, solution, remoteIP string) error {
+	form := url.Values{
+		"secret":   {r.secret},
+		"response": {solution},
+		"remoteip": {remoteIP},
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		r.verifyURL,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create verification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to verify %s response: %w", r.provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(
+			"unable to verify %s response: status %d", r.provider, resp.StatusCode,
+		)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unable to decode %s verification: %w", r.provider, err)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+
+	return nil
+}
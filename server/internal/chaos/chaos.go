@@ -0,0 +1,225 @@
+/*
+Package chaos provides the faults injected into the responses of the server outside
+of production, so that the retries of the frontends and the SDKs can be tried against
+a realistically unreliable server.
+
+The faults are configured as rules (see `Parse`), each matching some routes and
+delaying their responses by a random latency and/or failing a share of them, e.g. with
+the `CHAOS=GET /articles/*: latency=100ms-2s, errors=5%` environment variable. The
+first rule matching a request applies to it.
+*/
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRule is returned when a rule of the faults can not be parsed.
+var ErrInvalidRule = errors.New("invalid fault rule")
+
+/*
+Rule represents the faults injected into the responses of some routes.
+
+Fields:
+  - Method: The HTTP method of the requests the rule matches, every method if empty.
+  - Pattern: The path of the requests the rule matches. A pattern ending with `*`
+    matches every path starting with the rest of it (e.g. `/articles/*`), the other
+    patterns match the path exactly.
+  - MinLatency: The minimum latency added to the responses.
+  - MaxLatency: The maximum latency added to the responses, the latency being drawn
+    uniformly between both.
+  - ErrorRate: The share of the requests failed, between 0 and 1.
+  - Status: The HTTP status code of the failed requests.
+*/
+type Rule struct {
+	Method     string
+	Pattern    string
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	ErrorRate  float64
+	Status     int
+}
+
+// Matches reports whether the rule applies to a request of the method to the path.
+func (r Rule) Matches(method, path string) bool {
+	if r.Method != "" && r.Method != method {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(r.Pattern, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+
+	return path == r.Pattern
+}
+
+/*
+Fault represents the fault injected into the response to a request.
+
+Fields:
+  - Latency: The latency added to the response.
+  - Status: The HTTP status code the request is failed with, 0 if it is not failed.
+*/
+type Fault struct {
+	Latency time.Duration
+	Status  int
+}
+
+/*
+Parse parses the rules of the faults given as a semicolon-separated list of routes,
+each made of an optional HTTP method and a path pattern (see `Rule.Pattern`) followed
+by `:` and a comma-separated list of settings:
+  - latency: The latency added to the responses, e.g. `latency=200ms`, or the range it
+    is drawn from, e.g. `latency=100ms-2s`.
+  - errors: The share of the requests failed, as a percentage (e.g. `errors=5%`) or a
+    fraction (e.g. `errors=0.05`).
+  - status: The HTTP status code of the failed requests, 503 if not set.
+
+For example, "GET /articles/*: latency=100ms-2s, errors=5%; *: errors=1%, status=500".
+An error wrapping `ErrInvalidRule` is returned if a rule is invalid.
+*/
+func Parse(spec string) ([]Rule, error) {
+	rules := []Rule{}
+
+	for _, field := range strings.Split(spec, ";") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		rule, err := parseRule(field)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidRule, field, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// parseRule parses a single rule of the faults, e.g. "GET /articles/*: errors=5%".
+func parseRule(field string) (Rule, error) {
+	route, settings, ok := strings.Cut(field, ":")
+	if !ok {
+		return Rule{}, errors.New("missing settings")
+	}
+
+	rule := Rule{Status: http.StatusServiceUnavailable}
+
+	parts := strings.Fields(route)
+	switch len(parts) {
+	case 1:
+		rule.Pattern = parts[0]
+	case 2:
+		rule.Method, rule.Pattern = strings.ToUpper(parts[0]), parts[1]
+	default:
+		return Rule{}, errors.New("the route must be a path, optionally after a method")
+	}
+	if rule.Pattern != "*" && !strings.HasPrefix(rule.Pattern, "/") {
+		return Rule{}, fmt.Errorf("the path %q must start with a slash", rule.Pattern)
+	}
+
+	for _, setting := range strings.Split(settings, ",") {
+		name, value, _ := strings.Cut(setting, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		var err error
+		switch strings.ToLower(name) {
+		case "latency":
+			rule.MinLatency, rule.MaxLatency, err = parseLatency(value)
+		case "errors":
+			rule.ErrorRate, err = parseRate(value)
+		case "status":
+			rule.Status, err = strconv.Atoi(value)
+			if err == nil && (rule.Status < 400 || rule.Status > 599) {
+				err = fmt.Errorf("the status %d is not an error", rule.Status)
+			}
+		default:
+			err = fmt.Errorf("unknown setting %q", name)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+
+	return rule, nil
+}
+
+// parseLatency parses a latency, e.g. "200ms", or a range of latencies, e.g.
+// "100ms-2s".
+func parseLatency(value string) (time.Duration, time.Duration, error) {
+	low, high, isRange := strings.Cut(value, "-")
+
+	minLatency, err := time.ParseDuration(strings.TrimSpace(low))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latency %q", value)
+	}
+
+	maxLatency := minLatency
+	if isRange {
+		maxLatency, err = time.ParseDuration(strings.TrimSpace(high))
+		if err != nil || maxLatency < minLatency {
+			return 0, 0, fmt.Errorf("invalid latency %q", value)
+		}
+	}
+
+	return minLatency, maxLatency, nil
+}
+
+// parseRate parses a share of the requests, as a percentage (e.g. "5%") or a
+// fraction (e.g. "0.05").
+func parseRate(value string) (float64, error) {
+	percent, isPercent := strings.CutSuffix(value, "%")
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+	if isPercent {
+		rate /= 100
+	}
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("invalid error rate %q", value)
+	}
+
+	return rate, nil
+}
+
+// Injector draws the faults injected into the responses to the requests from its
+// rules. It is safe for concurrent use.
+type Injector struct {
+	rules []Rule
+}
+
+// New creates and returns a new Injector drawing the faults from the given rules.
+func New(rules []Rule) *Injector {
+	return &Injector{rules: rules}
+}
+
+/*
+Fault draws the fault injected into the response to a request of the method to the
+path from the first rule matching it, and reports whether a rule matches it at all.
+*/
+func (in *Injector) Fault(method, path string) (Fault, bool) {
+	for _, rule := range in.rules {
+		if !rule.Matches(method, path) {
+			continue
+		}
+
+		fault := Fault{Latency: rule.MinLatency}
+		if spread := rule.MaxLatency - rule.MinLatency; spread > 0 {
+			fault.Latency += rand.N(spread + 1)
+		}
+		if rule.ErrorRate > 0 && rand.Float64() < rule.ErrorRate {
+			fault.Status = rule.Status
+		}
+
+		return fault, true
+	}
+
+	return Fault{}, false
+}
@@ -0,0 +1,23 @@
+/*
+Package clock defines the `Clock` telling the current time to the packages which stamp,
+expire or rate their resources with it, so that the time can be fixed by the tests.
+
+The time of the system is told by `System`, and a time which only moves when advanced
+by `services.FixedClock` (for the tests).
+*/
+package clock
+
+import "time"
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the Clock telling the current time of the system, in UTC.
+type System struct{}
+
+// Now returns the current time of the system, in UTC.
+func (System) Now() time.Time {
+	return time.Now().UTC()
+}
@@ -9,13 +9,115 @@ and set up the necessary components for the server.
 */
 package config
 
-import "github.com/Weburz/burzcontent/server/internal/api/handlers"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/handlers"
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/challenge"
+	"github.com/Weburz/burzcontent/server/internal/chaos"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/flags"
+	"github.com/Weburz/burzcontent/server/internal/geoip"
+	"github.com/Weburz/burzcontent/server/internal/hooks"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+	"github.com/Weburz/burzcontent/server/internal/mailer"
+	"github.com/Weburz/burzcontent/server/internal/naming"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+	"github.com/Weburz/burzcontent/server/internal/repository"
+	"github.com/Weburz/burzcontent/server/internal/secrets"
+)
+
+// queueSize is the number of pending tasks the in-memory task queue can hold.
+const queueSize = 10000
+
+// Version is the release version of the server, set at build time with
+// `-ldflags "-X github.com/Weburz/burzcontent/server/internal/config.Version=v1.2.3"`.
+var Version = "dev"
 
 // Config holds the server configuration settings, such as the port and environment
 // type.
 type Config struct {
-	Port string // The port on which the server will listen
-	Env  string // The environment type (e.g., "development", "production")
+	Port      string // The port on which the server will listen
+	AdminPort string // The port serving the management API, under /admin when empty
+	Env       string // The environment type (e.g., "development", "production")
+	Release   string // The release version of the server, reported with the errors
+
+	CacheMaxAge int // The number of seconds the public API responses may be cached
+
+	DefaultSite string // The slug of the site serving unknown hostnames, if any
+	RootAPIKey  string // The API key granted the admin role on every site, if any
+
+	RateLimit    int   // The default number of requests per minute served to a site
+	StorageQuota int64 // The default storage quota of a site, in bytes
+
+	DebugPort  string // The port serving the pprof endpoints and the metrics, if any
+	DebugToken string // The bearer token required to access the debug port
+
+	SentryDSN string // The DSN of the Sentry project errors are reported to, if any
+
+	SMTPHost     string // The host of the SMTP server, emails are logged if empty
+	SMTPPort     string // The port of the SMTP server
+	SMTPUsername string // The username to authenticate to the SMTP server with, if any
+	SMTPPassword string // The password to authenticate to the SMTP server with
+	MailFrom     string // The address the emails are sent from
+
+	MaxReadRequests  int // The ceiling of concurrent read requests, unlimited when 0
+	MaxWriteRequests int // The ceiling of concurrent write requests, unlimited when 0
+
+	ShareLinkMaxLifetime int // The maximum lifetime of the share links, in seconds
+	TrashRetentionDays   int // The days the articles stay in the trash, forever when 0
+
+	PreviewSecret string // The key signing the preview tokens, random if empty
+
+	MaxBundleSize int64 // The maximum size of the uploaded template bundles, in bytes
+	MaxImportSize int64 // The maximum size of the imported files, in bytes
+	MaxBackupSize int64 // The maximum size of the restored backup archives, in bytes
+
+	IDFormat string // The format of the new identifiers, "uuidv7" or "ulid"
+
+	FeatureFlags string // The feature flags of every site, e.g. "webmentions=off"
+
+	Jobs          string // The schedules of the jobs, e.g. "backup=0 2 * * *"
+	JobJitter     int    // The maximum random delay of the runs of the jobs, in seconds
+	BackupDir     string // The directory the backups are written to, disabled if empty
+	RevisionLimit int    // The revisions kept per article, every revision when 0
+
+	AuditRetentionDays int // The days the audit entries are kept, forever when 0
+
+	QueueURL         string // The Redis server of the task queue, in memory if empty
+	QueueWorkers     int    // The number of background tasks run at once
+	QueueMaxAttempts int    // The runs of a failed task before it is dead-lettered
+
+	Chaos string // The faults injected outside of production, e.g. "*: errors=1%"
+
+	AccessLog string // The file of the Combined Log Format access log, "-" for stdout
+
+	LogSampling string // The shares of the requests logged, e.g. "GET /healthz: 1%"
+
+	EncryptionKeys string // The keys encrypting the personal data (or their secret)
+
+	GeoIPDatabase string // The MaxMind DB file locating the countries of the clients
+	GeoRules      string // The access rules by country, e.g. "KP=block, DE=read-only"
+
+	HookSecrets string // The secrets of the inbound webhooks, e.g. "github=s3cr3t"
+	HookActions string // The jobs run by their events, e.g. "github:push=backup"
+
+	JSONNaming string // The naming convention of the JSON fields, e.g. "camelCase"
+
+	CommentChallenge string // The challenge of the anonymous commenters, e.g. "pow:18"
+	ChallengeSecret  string // The key verifying the CAPTCHAs or signing the proofs
 }
 
 /*
@@ -23,10 +125,70 @@ NewConfig creates and initializes a new instance of Config with default values.
 
 This function returns a new `Config` instance with default values:
   - Port: "8000"
+  - AdminPort: "" (the management API is served on the same port, under `/admin`)
   - Env: "development"
+  - Release: The `Version` the server was built with
+  - CacheMaxAge: 300
+  - DefaultSite: "default"
+  - RootAPIKey: "" (the root API key is disabled)
+  - RateLimit: 600
+  - StorageQuota: 104857600 (100 MiB)
+  - DebugPort: "" (the pprof endpoints and the metrics are disabled)
+  - DebugToken: ""
+  - SentryDSN: "" (the errors are not reported)
+  - SMTPHost: "" (the emails are logged instead of being sent)
+  - SMTPPort: "587"
+  - SMTPUsername: "" (no authentication)
+  - SMTPPassword: ""
+  - MailFrom: "BurzContent <no-reply@localhost>"
+  - MaxReadRequests: 512
+  - MaxWriteRequests: 64
+  - ShareLinkMaxLifetime: 604800 (7 days)
+  - TrashRetentionDays: 30
+  - PreviewSecret: "" (a random key, the previews not surviving a restart)
+  - MaxBundleSize: 8388608 (8 MiB)
+  - MaxImportSize: 67108864 (64 MiB)
+  - MaxBackupSize: 268435456 (256 MiB)
+  - IDFormat: "uuidv7"
+  - FeatureFlags: "" (every feature flag takes its default value)
+  - Jobs: "" (every job runs on its default schedule, see `Scheduler.Configure`)
+  - JobJitter: 10
+  - BackupDir: "" (the sites are not backed up)
+  - RevisionLimit: 100
+  - AuditRetentionDays: 365
+  - QueueURL: "" (the background tasks are kept in memory, see `NewTaskQueue()`)
+  - QueueWorkers: 4
+  - QueueMaxAttempts: 5
+  - Chaos: "" (no fault is injected, see `NewFaultInjector()`)
+  - AccessLog: "" (no access log is written, see `NewAccessLog()`)
+  - LogSampling: "" (every request is logged, see `NewLogSampler()`)
+  - EncryptionKeys: "" (the personal data is stored in plain text, see `NewKeyring()`)
+  - GeoIPDatabase: "" (the countries of the clients are not located)
+  - GeoRules: "" (no country is restricted, see `NewGeoRules()`)
+  - HookSecrets: "" (every inbound webhook is rejected, see `hooks.ParseSecrets`)
+  - HookActions: "" (the inbound webhooks run no job, see `hooks.ParseActions`)
+  - JSONNaming: "" (the fields are named as the models declare them, unless the
+    clients ask for a convention, see `NewJSONNaming()`)
+  - CommentChallenge: "" (the anonymous commenters solve proofs of work, see
+    `NewCommentChallenge()`)
+  - ChallengeSecret: "" (a random key, the proofs of work not surviving a restart)
 
-These default values can be overridden by setting the respective fields after
-creating the `Config` instance.
+Each default value can be overridden by its respective environment variable (`PORT`,
+`ADMIN_PORT`, `ENV`, `RELEASE`, `CACHE_MAX_AGE`, `DEFAULT_SITE`, `ROOT_API_KEY`,
+`RATE_LIMIT`, `STORAGE_QUOTA`, `DEBUG_PORT`, `DEBUG_TOKEN`, `SENTRY_DSN`, `SMTP_HOST`,
+`SMTP_PORT`, `SMTP_USERNAME`, `SMTP_PASSWORD`, `MAIL_FROM`, `MAX_READ_REQUESTS`,
+`MAX_WRITE_REQUESTS`, `SHARE_LINK_MAX_LIFETIME`, `TRASH_RETENTION_DAYS`,
+`PREVIEW_SECRET`, `MAX_BUNDLE_SIZE`, `MAX_IMPORT_SIZE`, `MAX_BACKUP_SIZE`, `ID_FORMAT`,
+`FEATURE_FLAGS`, `JOBS`, `JOB_JITTER`, `BACKUP_DIR`, `REVISION_LIMIT`,
+`AUDIT_RETENTION_DAYS`, `QUEUE_URL`, `QUEUE_WORKERS`, `QUEUE_MAX_ATTEMPTS`, `CHAOS`,
+`ACCESS_LOG`, `LOG_SAMPLING`, `ENCRYPTION_KEYS`, `GEOIP_DATABASE`, `GEO_RULES`,
+`HOOK_SECRETS`, `HOOK_ACTIONS`, `JSON_NAMING`, `COMMENT_CHALLENGE` and
+`CHALLENGE_SECRET`) or by setting the respective fields after creating the `Config`
+instance. The sensitive settings (`ROOT_API_KEY`, `DEBUG_TOKEN`, `SENTRY_DSN`,
+`SMTP_USERNAME`, `SMTP_PASSWORD`, `PREVIEW_SECRET`, `QUEUE_URL`, `ENCRYPTION_KEYS`,
+`HOOK_SECRETS` and `CHALLENGE_SECRET`) may reference a secret held in a file, a Docker
+secret or HashiCorp Vault instead, e.g.
+`SMTP_PASSWORD=secret://docker/smtp-password` (see the `secrets` package).
 
 Example:
   - This function is used to create a configuration object before initializing
@@ -34,21 +196,481 @@ Example:
 */
 func NewConfig() *Config {
 	return &Config{
-		Port: "8000",        // Default port
-		Env:  "development", // Default environment
+		Port:      getEnv("PORT", "8000"),       // Default port
+		AdminPort: getEnv("ADMIN_PORT", ""),     // Default management API port
+		Env:       getEnv("ENV", "development"), // Default environment
+		Release:   getEnv("RELEASE", Version),   // Default release version
+
+		CacheMaxAge: getEnvInt("CACHE_MAX_AGE", 300),
+
+		DefaultSite: getEnv("DEFAULT_SITE", repository.DefaultSiteSlug),
+		RootAPIKey:  getSecret("ROOT_API_KEY"),
+
+		RateLimit:    getEnvInt("RATE_LIMIT", 600),
+		StorageQuota: int64(getEnvInt("STORAGE_QUOTA", 100<<20)),
+
+		DebugPort:  getEnv("DEBUG_PORT", ""),
+		DebugToken: getSecret("DEBUG_TOKEN"),
+
+		SentryDSN: getSecret("SENTRY_DSN"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getSecret("SMTP_USERNAME"),
+		SMTPPassword: getSecret("SMTP_PASSWORD"),
+		MailFrom:     getEnv("MAIL_FROM", "BurzContent <no-reply@localhost>"),
+
+		MaxReadRequests:  getEnvInt("MAX_READ_REQUESTS", 512),
+		MaxWriteRequests: getEnvInt("MAX_WRITE_REQUESTS", 64),
+
+		ShareLinkMaxLifetime: getEnvInt("SHARE_LINK_MAX_LIFETIME", 7*24*60*60),
+		TrashRetentionDays:   getEnvInt("TRASH_RETENTION_DAYS", 30),
+
+		PreviewSecret: getSecret("PREVIEW_SECRET"),
+
+		MaxBundleSize: int64(getEnvInt("MAX_BUNDLE_SIZE", 8<<20)),
+		MaxImportSize: int64(getEnvInt("MAX_IMPORT_SIZE", 64<<20)),
+		MaxBackupSize: int64(getEnvInt("MAX_BACKUP_SIZE", 256<<20)),
+
+		IDFormat: getEnv("ID_FORMAT", ids.FormatUUIDv7),
+
+		FeatureFlags: getEnv("FEATURE_FLAGS", ""),
+
+		Jobs:          getEnv("JOBS", ""),
+		JobJitter:     getEnvInt("JOB_JITTER", 10),
+		BackupDir:     getEnv("BACKUP_DIR", ""),
+		RevisionLimit: getEnvInt("REVISION_LIMIT", 100),
+
+		AuditRetentionDays: getEnvInt("AUDIT_RETENTION_DAYS", 365),
+
+		QueueURL:         getSecret("QUEUE_URL"),
+		QueueWorkers:     getEnvInt("QUEUE_WORKERS", 4),
+		QueueMaxAttempts: getEnvInt("QUEUE_MAX_ATTEMPTS", 5),
+
+		Chaos: getEnv("CHAOS", ""),
+
+		AccessLog:   getEnv("ACCESS_LOG", ""),
+		LogSampling: getEnv("LOG_SAMPLING", ""),
+
+		// Resolved by NewKeyring, for the server to refuse to start if it can not be
+		EncryptionKeys: getEnv("ENCRYPTION_KEYS", ""),
+
+		GeoIPDatabase: getEnv("GEOIP_DATABASE", ""),
+		GeoRules:      getEnv("GEO_RULES", ""),
+
+		HookSecrets: getSecret("HOOK_SECRETS"),
+		HookActions: getEnv("HOOK_ACTIONS", ""),
+
+		JSONNaming: getEnv("JSON_NAMING", ""),
+
+		CommentChallenge: getEnv("COMMENT_CHALLENGE", ""),
+		ChallengeSecret:  getSecret("CHALLENGE_SECRET"),
+	}
+}
+
+/*
+NewLogger returns the logger of the server (see `logger.New`), writing JSON lines to
+the `logs.json` file in production and human-readable text to the standard output
+otherwise.
+
+An error is returned if the log file can not be opened.
+*/
+func (c *Config) NewLogger() (*slog.Logger, error) {
+	if c.Env != "production" {
+		return logger.New(c.Env, os.Stdout), nil
+	}
+
+	file, err := os.OpenFile("logs.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o666)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log file: %w", err)
+	}
+
+	return logger.New(c.Env, file), nil
+}
+
+/*
+NewAccessLog returns the writer of the access log of the server, in the Combined Log
+Format (see `middleware.AccessLog`), on top of the structured logs: the configured
+file, opened for appending, or the standard output for "-". The personal data and the
+secrets are masked from the lines (see `logger.Redact`), e.g. the IP addresses of the
+clients are truncated to their network. Nil is returned if no access log is configured.
+
+An error is returned if the file can not be opened.
+*/
+func (c *Config) NewAccessLog() (io.Writer, error) {
+	switch c.AccessLog {
+	case "":
+		return nil, nil
+	case "-":
+		return logger.NewRedactWriter(os.Stdout), nil
+	}
+
+	file, err := os.OpenFile(c.AccessLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open access log: %w", err)
+	}
+
+	return logger.NewRedactWriter(file), nil
+}
+
+/*
+NewLogSampler returns the sampler of the requests logged by the server (see
+`logger.ParseSampling` for the format of its rules), or nil if every request is logged.
+The errors are always logged, whatever the rules.
+
+An error is returned if the rules are invalid.
+*/
+func (c *Config) NewLogSampler() (*logger.Sampler, error) {
+	if c.LogSampling == "" {
+		return nil, nil
 	}
+
+	rules, err := logger.ParseSampling(c.LogSampling)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger.NewSampler(rules), nil
+}
+
+/*
+NewKeyring returns the keyring encrypting the personal data stored by the server (see
+`encryption.ParseKeys` for the format of its keys), or nil if no key is configured, the
+personal data being stored in plain text. The keys may be given as a reference to a
+secret held outside of the environment (see `getSecret`).
+
+An error is returned if the keys are invalid, or if their secret can not be resolved.
+*/
+func (c *Config) NewKeyring() (*encryption.Keyring, error) {
+	keys, err := resolveSecret(c.EncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve ENCRYPTION_KEYS: %w", err)
+	}
+
+	return encryption.ParseKeys(keys)
+}
+
+/*
+NewGeoDatabase returns the MaxMind DB file locating the countries of the clients of the
+server (e.g. the GeoLite2 Country database), read in memory, or nil if none is
+configured.
+
+An error is returned if the file can not be read.
+*/
+func (c *Config) NewGeoDatabase() (*geoip.DB, error) {
+	if c.GeoIPDatabase == "" {
+		return nil, nil
+	}
+
+	return geoip.Open(c.GeoIPDatabase)
+}
+
+/*
+NewJSONNaming returns the naming convention of the fields of the JSON documents of the
+APIs for the clients which do not ask for one (see `middleware.JSONNaming`), i.e.
+"camelCase", "snake_case" or "default" (the fields being named as the models declare
+them, as with an empty value).
+
+An error is returned if the convention is unknown.
+*/
+func (c *Config) NewJSONNaming() (naming.Convention, error) {
+	return naming.Parse(c.JSONNaming)
+}
+
+/*
+NewCommentChallenge returns the challenge the anonymous commenters have to solve (see
+`challenge.Parse` for its format): a proof of work issued by the server, signed with
+the challenge secret (e.g. "pow:18"), or a CAPTCHA of hCaptcha or Cloudflare Turnstile
+verified with it (e.g. "turnstile:0x4AAAAAAA").
+
+An error is returned if the provider is unknown, or if no secret is configured to
+verify the CAPTCHAs.
+*/
+func (c *Config) NewCommentChallenge() (challenge.Verifier, error) {
+	return challenge.Parse(
+		c.CommentChallenge,
+		c.ChallengeSecret,
+		services.SystemClock{},
+	)
+}
+
+/*
+NewGeoRules returns the access rules of the server by country (see `geoip.ParseRules`
+for their format), or nil if no country is restricted.
+
+An error is returned if the rules are invalid, or if no MaxMind DB file is configured
+to locate the countries of the clients.
+*/
+func (c *Config) NewGeoRules() (geoip.Rules, error) {
+	if c.GeoRules == "" {
+		return nil, nil
+	}
+
+	if c.GeoIPDatabase == "" {
+		return nil, errors.New("no MaxMind DB file is configured (GEOIP_DATABASE)")
+	}
+
+	return geoip.ParseRules(c.GeoRules)
 }
 
 /*
 InitialiseHandlers initializes and returns a new instance of Handlers.
 
 This function calls the `handlers.NewHandlers()` function to create a new
-`Handlers` instance, which contains the necessary request handlers for the server.
+`Handlers` instance, which contains the necessary request handlers for the server. The
+handlers are backed by a new in-memory store (see `repository.NewMemoryStore()`)
+encrypting the personal data with the configured keys (see `NewKeyring()`), run
+their background tasks on the configured task queue (see `NewTaskQueue()`), send their
+emails with the configured mailer (see `NewMailer()`) and assign the identifiers of the
+new resources in the configured format (see `NewIDGenerator()`). The handlers log with
+the given logger (see `NewLogger()`).
+
+An error is returned if encryption keys are configured but can not be used, rather
+than storing the personal data in plain text.
 
 Example:
   - This function can be used to set up the handlers needed by the server,
     including those for user-related HTTP requests.
 */
-func (c *Config) InitialiseHandlers() *handlers.Handlers {
-	return handlers.NewHandlers()
+func (c *Config) InitialiseHandlers(
+	requestLogger *slog.Logger,
+) (*handlers.Handlers, error) {
+	generator, err := c.NewIDGenerator()
+	if err != nil {
+		log.Printf("Version 7 UUIDs will be generated: %v", err)
+		generator = ids.UUIDv7{}
+	}
+
+	keyring, err := c.NewKeyring()
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt the personal data: %w", err)
+	}
+
+	tasks, err := c.NewTaskQueue(generator, keyring, requestLogger)
+	if err != nil {
+		log.Printf("Background tasks will be kept in memory: %v", err)
+		tasks = queue.New(
+			queue.NewMemoryBackend(queueSize),
+			c.queueOptions(generator, keyring, requestLogger),
+		)
+	}
+
+	mail, err := c.NewMailer(tasks, requestLogger)
+	if err != nil {
+		log.Printf("Emails will only be logged: %v", err)
+		mail = mailer.LogMailer{Logger: requestLogger}
+	}
+
+	featureFlags, err := flags.Parse(c.FeatureFlags)
+	if err != nil {
+		log.Printf("Feature flags will take their default value: %v", err)
+	}
+
+	hookSecrets, err := hooks.ParseSecrets(c.HookSecrets)
+	if err != nil {
+		log.Printf("Every inbound webhook will be rejected: %v", err)
+	}
+
+	hookActions, err := hooks.ParseActions(c.HookActions)
+	if err != nil {
+		log.Printf("Inbound webhooks will run no job: %v", err)
+	}
+
+	commentChallenge, err := c.NewCommentChallenge()
+	if err != nil {
+		log.Printf("Anonymous commenters will solve proofs of work: %v", err)
+	}
+
+	store := repository.NewMemoryStore(keyring)
+
+	h := handlers.NewHandlers(store, handlers.Options{
+		DefaultSite: c.DefaultSite,
+		RootAPIKey:  c.RootAPIKey,
+		DefaultQuota: models.SiteQuota{
+			RequestsPerMinute: c.RateLimit,
+			StorageBytes:      c.StorageQuota,
+		},
+		Mailer:               mail,
+		Tasks:                tasks,
+		ShareLinkMaxLifetime: time.Duration(c.ShareLinkMaxLifetime) * time.Second,
+		UploadLimits: handlers.UploadLimits{
+			Bundle: c.MaxBundleSize,
+			Import: c.MaxImportSize,
+			Backup: c.MaxBackupSize,
+		},
+		PreviewSecret: c.PreviewSecret,
+		IDs:           generator,
+		FeatureFlags:  featureFlags,
+		Logger:        requestLogger,
+		Retention: models.RetentionPolicy{
+			RevisionsPerArticle: c.RevisionLimit,
+			AuditLogDays:        c.AuditRetentionDays,
+		},
+		HookSecrets:      hookSecrets,
+		HookActions:      hookActions,
+		CommentChallenge: commentChallenge,
+	})
+
+	return h, nil
+}
+
+/*
+NewMailer returns the mailer sending the emails of the server.
+
+The emails are sent through the configured SMTP server, in the background as tasks of
+the given task queue, or only logged with the given logger if no SMTP server is
+configured.
+*/
+func (c *Config) NewMailer(
+	tasks *queue.Queue,
+	logger *slog.Logger,
+) (mailer.Mailer, error) {
+	if c.SMTPHost == "" {
+		return mailer.LogMailer{Logger: logger}, nil
+	}
+
+	smtp, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host:     c.SMTPHost,
+		Port:     c.SMTPPort,
+		Username: c.SMTPUsername,
+		Password: c.SMTPPassword,
+		From:     c.MailFrom,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mailer.NewQueue(tasks, smtp), nil
+}
+
+/*
+NewTaskQueue returns the queue running the background tasks of the server (e.g.
+sending the emails), which keeps its tasks in memory, or in the Redis server of the
+configured URL (e.g. "redis://localhost:6379/0") so that they survive the restarts of
+the server. The tasks are identified by the given generator, like the resources are
+(see `NewIDGenerator()`), their payloads encrypted with the given keyring, like the
+personal data is (see `NewKeyring()`), and their failures logged with the given logger.
+
+An error is returned if the URL is not a valid Redis URL.
+*/
+func (c *Config) NewTaskQueue(
+	generator ids.Generator,
+	keyring *encryption.Keyring,
+	logger *slog.Logger,
+) (*queue.Queue, error) {
+	opts := c.queueOptions(generator, keyring, logger)
+	if c.QueueURL == "" {
+		return queue.New(queue.NewMemoryBackend(queueSize), opts), nil
+	}
+
+	backend, err := queue.NewRedisBackend(c.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return queue.New(backend, opts), nil
+}
+
+// queueOptions returns the settings of the task queue of the server, whose tasks are
+// identified by the given generator, whose payloads are encrypted with the keyring and
+// whose failures are logged with the logger.
+func (c *Config) queueOptions(
+	generator ids.Generator,
+	keyring *encryption.Keyring,
+	logger *slog.Logger,
+) queue.Options {
+	return queue.Options{
+		Workers:     c.QueueWorkers,
+		MaxAttempts: c.QueueMaxAttempts,
+		IDs:         generator,
+		Keyring:     keyring,
+		Logger:      logger,
+	}
+}
+
+/*
+NewIDGenerator returns the generator of the identifiers of the new resources, in the
+configured format: version 7 UUIDs ("uuidv7") or ULIDs ("ulid").
+
+An error is returned if the format is unknown.
+*/
+func (c *Config) NewIDGenerator() (services.IDGenerator, error) {
+	switch strings.ToLower(c.IDFormat) {
+	case ids.FormatUUIDv7:
+		return ids.UUIDv7{}, nil
+	case ids.FormatULID:
+		return ids.NewULID(services.SystemClock{}), nil
+	}
+
+	return nil, fmt.Errorf("unknown ID format %q", c.IDFormat)
+}
+
+/*
+NewFaultInjector returns the injector of the configured faults into the responses of
+the server (see `chaos.Parse` for their format), or nil if no fault is configured.
+
+An error is returned if the faults are configured in production, where they are never
+injected, or if they are invalid.
+*/
+func (c *Config) NewFaultInjector() (*chaos.Injector, error) {
+	if c.Chaos == "" {
+		return nil, nil
+	}
+
+	if strings.EqualFold(c.Env, "production") {
+		return nil, errors.New("faults are never injected in production")
+	}
+
+	rules, err := chaos.Parse(c.Chaos)
+	if err != nil {
+		return nil, err
+	}
+
+	return chaos.New(rules), nil
+}
+
+// getEnv returns the value of the environment variable named by the key, or the
+// fallback value if the variable is not set or is empty.
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+/*
+getSecret returns the value of the environment variable named by the key, resolving it
+if it is a reference to a secret held outside of the environment (see the `secrets`
+package), e.g. `SMTP_PASSWORD=secret://docker/smtp-password`. An empty string is
+returned if the variable is not set, or if the secret can not be resolved.
+*/
+func getSecret(key string) string {
+	secret, err := resolveSecret(getEnv(key, ""))
+	if err != nil {
+		log.Printf("%s is not set: %v", key, err)
+		return ""
+	}
+
+	return secret
+}
+
+// resolveSecret returns the value, resolved if it is a reference to a secret held
+// outside of the environment (see the `secrets` package).
+func resolveSecret(value string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return secrets.NewResolver().Resolve(ctx, value)
+}
+
+// getEnvInt returns the integer value of the environment variable named by the key,
+// or the fallback value if the variable is not set or is not a valid integer.
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+
+	return value
 }
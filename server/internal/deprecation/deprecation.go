@@ -0,0 +1,227 @@
+/*
+Package deprecation keeps track of the deprecated routes and fields of the APIs, so
+that they can be retired once their consumers moved to their successors.
+
+The deprecated routes and fields are registered with a `Registry` as `Notice`s, in a
+single place, and the responses using them are flagged (see `Registry.Flag`) with a
+`Deprecation` header (RFC 9745) and, once their removal is scheduled, a `Sunset`
+header (RFC 8594). The registry counts how many times each consumer (API key) used
+each of them, and logs it as the counts grow, so that the maintainers know who still
+depends on them before removing them.
+
+The usage is only counted in memory: it is lost on restart.
+*/
+package deprecation
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/clock"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+/*
+Notice describes a deprecated route or field of the APIs.
+
+Fields:
+  - Name: The name of the route (its method and pattern, e.g. "PUT /articles/new") or
+    of the field (its resource and JSON key, joined by a dot).
+  - Since: When the route or field was deprecated.
+  - Sunset: When the route or field is to be removed (zero if not scheduled yet).
+  - Successor: What replaces the route or field, e.g. "POST /articles".
+*/
+type Notice struct {
+	Name      string    `json:"name"`
+	Since     time.Time `json:"since"`
+	Sunset    time.Time `json:"sunset,omitzero"`
+	Successor string    `json:"successor,omitempty"`
+}
+
+// SetHeaders sets the `Deprecation` header, and the `Sunset` header if its removal is
+// scheduled, of a response using the deprecated route or field.
+func (n Notice) SetHeaders(header http.Header) {
+	header.Set("Deprecation", "@"+strconv.FormatInt(n.Since.Unix(), 10))
+	if !n.Sunset.IsZero() {
+		header.Set("Sunset", n.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+/*
+Usage represents how many times a consumer used a deprecated route or field.
+
+Fields:
+  - SiteID: The unique identifier of the site the consumer used it on (UUID), which is
+    nil for the routes which are not scoped to a site.
+  - Consumer: The consumer, i.e. the ID of its API key, "root" for the root API key or
+    "anonymous" for the unauthenticated requests.
+  - Count: The number of times the consumer used it.
+  - LastUsedAt: When the consumer last used it.
+*/
+type Usage struct {
+	SiteID     uuid.UUID `json:"site_id"`
+	Consumer   string    `json:"consumer"`
+	Count      int64     `json:"count"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// Report holds a deprecated route or field along with its usage by each consumer,
+// from the most frequent one.
+type Report struct {
+	Notice
+	Usage []Usage `json:"usage"`
+}
+
+// usageKey identifies the usage of a deprecated route or field by a consumer.
+type usageKey struct {
+	name     string
+	siteID   uuid.UUID
+	consumer string
+}
+
+// Registry holds the deprecated routes and fields of the APIs by their name, along
+// with their usage. It is safe for concurrent use.
+type Registry struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	notices map[string]Notice
+	usage   map[usageKey]*Usage
+}
+
+// NewRegistry creates and returns a new Registry without any deprecated route or
+// field, stamping the usage with the time told by the given clock.
+func NewRegistry(clock clock.Clock) *Registry {
+	return &Registry{
+		clock:   clock,
+		notices: make(map[string]Notice),
+		usage:   make(map[usageKey]*Usage),
+	}
+}
+
+// Register registers the deprecated routes or fields, replacing the ones registered
+// with the same names.
+func (r *Registry) Register(notices ...Notice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, notice := range notices {
+		r.notices[notice.Name] = notice
+	}
+}
+
+// Notice returns the named deprecated route or field and whether it is registered.
+func (r *Registry) Notice(name string) (Notice, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notice, ok := r.notices[name]
+	return notice, ok
+}
+
+/*
+Flag flags the response to the request as using the named deprecated route or field,
+with its `Deprecation` and `Sunset` headers, and counts its usage by the consumer
+making the request. The usage is logged the first time and every time its count
+reaches a power of ten, with the logger of the request (see `logger.FromContext`). Flag
+does nothing if the name is not registered.
+*/
+func (r *Registry) Flag(w http.ResponseWriter, req *http.Request, name string) {
+	notice, ok := r.Notice(name)
+	if !ok {
+		return
+	}
+	notice.SetHeaders(w.Header())
+
+	key := usageKey{
+		name:     name,
+		siteID:   tenant.SiteID(req.Context()),
+		consumer: consumer(req),
+	}
+
+	r.mu.Lock()
+	usage := r.usage[key]
+	if usage == nil {
+		usage = &Usage{SiteID: key.siteID, Consumer: key.consumer}
+		r.usage[key] = usage
+	}
+	usage.Count++
+	usage.LastUsedAt = r.clock.Now()
+	count := usage.Count
+	r.mu.Unlock()
+
+	if isPowerOfTen(count) {
+		ctx := req.Context()
+		logger.FromContext(ctx).InfoContext(
+			ctx, "Deprecated route or field used",
+			"name", name,
+			"count", count,
+			"consumer", key.consumer,
+			"site_id", key.siteID,
+			"successor", cmp.Or(notice.Successor, "none"),
+		)
+	}
+}
+
+// Report returns every deprecated route and field, by name, along with their usage on
+// the given sites (or on every site if none is given).
+func (r *Registry) Report(siteIDs ...uuid.UUID) []Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reports := make([]Report, 0, len(r.notices))
+	for _, notice := range r.notices {
+		report := Report{Notice: notice, Usage: []Usage{}}
+		for key, usage := range r.usage {
+			if key.name == notice.Name &&
+				(len(siteIDs) == 0 || slices.Contains(siteIDs, key.siteID)) {
+				report.Usage = append(report.Usage, *usage)
+			}
+		}
+
+		slices.SortFunc(report.Usage, func(a, b Usage) int {
+			return cmp.Or(
+				cmp.Compare(b.Count, a.Count),
+				cmp.Compare(a.Consumer, b.Consumer),
+			)
+		})
+		reports = append(reports, report)
+	}
+
+	slices.SortFunc(reports, func(a, b Report) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return reports
+}
+
+// consumer returns the consumer making the request, from the principal it was
+// authenticated as.
+func consumer(r *http.Request) string {
+	principal, ok := auth.FromContext(r.Context())
+	switch {
+	case !ok:
+		return "anonymous"
+	case principal.Root:
+		return "root"
+	default:
+		return principal.KeyID.String()
+	}
+}
+
+// isPowerOfTen reports whether n is a power of ten (1, 10, 100, ...).
+func isPowerOfTen(n int64) bool {
+	for n >= 10 && n%10 == 0 {
+		n /= 10
+	}
+
+	return n == 1
+}
@@ -0,0 +1,175 @@
+/*
+Package encryption provides the encryption at rest of the personal data stored by the
+server (e.g. the email addresses of the users and of the commenters), with keys
+managed by the application.
+
+The values are encrypted with AES-256-GCM by the repositories, before they are stored,
+and decrypted once read, so that the rest of the server only ever handles plain text.
+Each encrypted value records the key it was encrypted with, hence the keys can be
+rotated: the values are encrypted with the primary key of the `Keyring`, while the
+former keys are kept to decrypt the values encrypted before the rotation, until they
+are encrypted again with the primary key.
+*/
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix is the prefix of the encrypted values, followed by the ID of their key and
+// their ciphertext.
+const prefix = "enc:v1:"
+
+var (
+	// ErrInvalidKey is returned when a key of the keyring can not be parsed.
+	ErrInvalidKey = errors.New("invalid encryption key")
+
+	// ErrUnknownKey is returned when a value was encrypted with a key missing from the
+	// keyring.
+	ErrUnknownKey = errors.New("unknown encryption key")
+)
+
+/*
+Keyring holds the keys encrypting the values: the primary key, encrypting the values,
+and the former keys, only decrypting the values encrypted before the rotation of the
+keys. It is safe for concurrent use.
+
+A nil Keyring leaves the values as they are, in plain text.
+*/
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+/*
+ParseKeys parses the keys of a keyring given as a comma-separated list of keys, each
+made of its ID, `:` and 32 random bytes encoded in base64, the first key being the
+primary one, e.g. "2025-06:<base64>,2024-01:<base64>". A key can be generated with
+`openssl rand -base64 32`.
+
+Nil is returned if the list is empty. An error wrapping `ErrInvalidKey` is returned if
+a key is invalid.
+*/
+func ParseKeys(spec string) (*Keyring, error) {
+	var keyring *Keyring
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(field, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%w: expected <id>:<base64 key>", ErrInvalidKey)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf(
+				"%w %q: expected 32 bytes in base64", ErrInvalidKey, id,
+			)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidKey, id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidKey, id, err)
+		}
+
+		if keyring == nil {
+			keyring = &Keyring{primary: id, keys: make(map[string]cipher.AEAD)}
+		} else if _, ok := keyring.keys[id]; ok {
+			return nil, fmt.Errorf("%w %q: duplicate ID", ErrInvalidKey, id)
+		}
+		keyring.keys[id] = aead
+	}
+
+	return keyring, nil
+}
+
+/*
+Encrypt encrypts the value with the primary key, e.g. into
+"enc:v1:2025-06:<base64>". The empty values, and every value if the keyring is nil,
+are returned as they are.
+*/
+func (k *Keyring) Encrypt(value string) (string, error) {
+	if k == nil || value == "" {
+		return value, nil
+	}
+
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("unable to encrypt value: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(k.primary))
+
+	return prefix + k.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+/*
+Decrypt decrypts the value encrypted by `Encrypt`, with the key it was encrypted with.
+The values which are not encrypted are returned as they are.
+
+An error wrapping `ErrUnknownKey` is returned if the key of the value is missing from
+the keyring, and an error if the value can not be decrypted.
+*/
+func (k *Keyring) Decrypt(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("unable to decrypt value: malformed value")
+	}
+
+	if k == nil {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("unable to decrypt value: malformed value")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt value: %w", err)
+	}
+
+	return string(plain), nil
+}
+
+// IsEncrypted reports whether the value was encrypted by `Encrypt`, rather than given
+// in plain text.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Stale reports whether the value has to be encrypted again to be encrypted with the
+// primary key, i.e. it is stored in plain text or encrypted with a former key.
+func (k *Keyring) Stale(value string) bool {
+	if k == nil || value == "" {
+		return false
+	}
+
+	return !strings.HasPrefix(value, prefix+k.primary+":")
+}
@@ -0,0 +1,101 @@
+/*
+Package errreport reports the unexpected errors and the panics of the server to an
+error tracking service (Sentry).
+
+Reporting is optional: until `Init` is called with a DSN, the functions of this
+package do nothing. Each report carries the context of the request it occurred in:
+the request itself (without its cookies and authorization headers), the site it was
+resolved to, the user or API key which made it and the release version of the server.
+*/
+package errreport
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	sentry "github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+// enabled reports whether the errors are reported, i.e. whether `Init` was called
+// with a DSN.
+var enabled bool
+
+/*
+Init enables the reporting of the errors to the Sentry project identified by the DSN,
+tagging them with the release version and the environment of the server.
+
+The reporting is left disabled if the DSN is empty.
+*/
+func Init(dsn, release, environment string) error {
+	if dsn == "" {
+		return nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         dsn,
+		Release:     release,
+		Environment: environment,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to initialise error reporting: %w", err)
+	}
+
+	enabled = true
+	return nil
+}
+
+// Report reports an unexpected error which occurred while serving the request.
+func Report(r *http.Request, err error) {
+	if !enabled || err == nil {
+		return
+	}
+
+	hubFor(r).CaptureException(err)
+}
+
+// ReportPanic reports a panic recovered while serving the request, along with the
+// stack trace of the panicking goroutine.
+func ReportPanic(r *http.Request, recovered any) {
+	if !enabled {
+		return
+	}
+
+	hubFor(r).Recover(recovered)
+}
+
+// Flush waits until the pending reports are sent, or until the timeout elapses.
+func Flush(timeout time.Duration) {
+	if enabled {
+		sentry.Flush(timeout)
+	}
+}
+
+// hubFor returns a hub whose scope holds the context of the request.
+func hubFor(r *http.Request) *sentry.Hub {
+	hub := sentry.CurrentHub().Clone()
+	scope := hub.Scope()
+
+	scope.SetRequest(r)
+	if site, ok := tenant.FromContext(r.Context()); ok {
+		scope.SetTag("site", site.Slug)
+	}
+
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		switch {
+		case principal.Root:
+			scope.SetUser(sentry.User{ID: "root"})
+		case principal.UserID != uuid.Nil:
+			scope.SetUser(sentry.User{ID: principal.UserID.String()})
+		}
+
+		scope.SetTag("api_key", principal.KeyID.String())
+		scope.SetTag("role", string(principal.Role))
+	}
+
+	return hub
+}
@@ -0,0 +1,116 @@
+/*
+Package events provides an in-process publish/subscribe broker of the events of the
+sites, such as the progress of long-running operations.
+
+Every event belongs to a single site and is only delivered to the subscribers of that
+site. The delivery is best-effort: the events are not persisted, and a subscriber which
+does not keep up with the events of its site misses some of them rather than slowing
+down the publishers.
+
+Besides the subscribers of the sites, observers (e.g. the outbound webhooks) are
+notified of every event of every site, synchronously, and have to return at once.
+*/
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+// bufferSize is the number of events buffered for each subscriber.
+const bufferSize = 64
+
+/*
+Event represents something which happened on a site.
+
+Fields:
+  - Type: The type of the event, e.g. "restore.progress".
+  - SiteID: The unique identifier of the site the event belongs to (UUID).
+  - Data: The payload of the event, which is encoded to JSON.
+  - At: When the event was published.
+*/
+type Event struct {
+	Type   string    `json:"type"`
+	SiteID uuid.UUID `json:"site_id"`
+	Data   any       `json:"data,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Broker dispatches the published events to the subscribers of their site.
+type Broker struct {
+	clock clock.Clock
+
+	mu        sync.RWMutex
+	subs      map[uuid.UUID]map[chan Event]struct{}
+	observers []func(Event)
+}
+
+// NewBroker creates and returns a new Broker without any subscriber, stamping the
+// events with the time told by the given clock.
+func NewBroker(clock clock.Clock) *Broker {
+	return &Broker{clock: clock, subs: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Publish publishes an event of the given type and payload to the subscribers of the
+// site, skipping the subscribers whose buffer is full, and to the observers.
+func (b *Broker) Publish(siteID uuid.UUID, kind string, data any) {
+	event := Event{Type: kind, SiteID: siteID, Data: data, At: b.clock.Now()}
+
+	b.mu.RLock()
+	for ch := range b.subs[siteID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	observers := b.observers
+	b.mu.RUnlock()
+
+	for _, observe := range observers {
+		observe(event)
+	}
+}
+
+// Observe registers a function called with every event published on any site, once
+// its subscribers are notified. The function is called by the publisher, hence it has
+// to hand the slow work (e.g. sending the event to another server) off at once.
+func (b *Broker) Observe(observe func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.observers = append(b.observers, observe)
+}
+
+/*
+Subscribe subscribes to the events of the site.
+
+The events are received on the returned channel until the returned function is called,
+which has to be done once the subscriber is done with the events.
+*/
+func (b *Broker) Subscribe(siteID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+
+	b.mu.Lock()
+	if b.subs[siteID] == nil {
+		b.subs[siteID] = make(map[chan Event]struct{})
+	}
+	b.subs[siteID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subs[siteID], ch)
+			if len(b.subs[siteID]) == 0 {
+				delete(b.subs, siteID)
+			}
+		})
+	}
+}
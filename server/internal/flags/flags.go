@@ -0,0 +1,215 @@
+/*
+Package flags provides the feature flags of the server, which let the risky features
+be rolled out gradually: enabled on a few sites first, or for a few requests only.
+
+A flag is evaluated for a request from the most to the least specific of its values:
+  - The value the request itself asks for, through the `X-Feature-Flags` header of the
+    authenticated requests (see `NewContext`), e.g. to try a feature out.
+  - The value set on the site (the tenant) the request is resolved to, through the
+    management API (see `models.Site.Flags`).
+  - The value set on every site by the configuration of the server (see `Parse`), e.g.
+    with the `FEATURE_FLAGS=webmentions=off` environment variable.
+  - The default value of the flag.
+*/
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Weburz/burzcontent/server/internal/tenant"
+)
+
+const (
+	// Comments enables the comments of the articles.
+	Comments = "comments"
+
+	// Webmentions enables the webmentions received by the articles.
+	Webmentions = "webmentions"
+)
+
+// The sources of the values of the flags, from the least to the most specific.
+const (
+	SourceDefault = "default"
+	SourceConfig  = "config"
+	SourceSite    = "site"
+	SourceRequest = "request"
+)
+
+// ErrUnknownFlag is returned when a flag which is not defined is referred to.
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+/*
+Flag represents a feature flag.
+
+Fields:
+  - Name: The name of the flag, e.g. "comments".
+  - Description: What the flag enables.
+  - Default: Whether the flag is enabled when no value is set.
+*/
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// Builtin lists the flags of the server, by name.
+var Builtin = []Flag{
+	{
+		Name:        Comments,
+		Description: "The comments of the articles, on both APIs",
+		Default:     true,
+	},
+	{
+		Name:        Webmentions,
+		Description: "The webmentions received by the articles, on both APIs",
+		Default:     true,
+	},
+}
+
+// State holds a flag along with its value for a request and the source of the value
+// (e.g. `SourceSite`).
+type State struct {
+	Flag
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+}
+
+// Lookup returns the named flag and whether it is defined.
+func Lookup(name string) (Flag, bool) {
+	i := slices.IndexFunc(Builtin, func(f Flag) bool { return f.Name == name })
+	if i < 0 {
+		return Flag{}, false
+	}
+
+	return Builtin[i], true
+}
+
+/*
+Parse parses the values of the flags given as a comma-separated list of names, each
+optionally followed by `=` and a boolean (`on`/`off` or any value accepted by
+`strconv.ParseBool`), e.g. "comments=off, webmentions". A name without a value enables
+its flag. An error wrapping `ErrUnknownFlag` is returned for the undefined flags.
+*/
+func Parse(spec string) (map[string]bool, error) {
+	values := make(map[string]bool)
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		name, raw, hasValue := strings.Cut(field, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := Lookup(name); !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+		}
+
+		enabled := true
+		if hasValue {
+			var err error
+			if enabled, err = parseBool(strings.TrimSpace(raw)); err != nil {
+				return nil, fmt.Errorf(
+					"invalid value of feature flag %q: %q", name, raw,
+				)
+			}
+		}
+
+		values[name] = enabled
+	}
+
+	return values, nil
+}
+
+// Set evaluates the flags with the values set on every site by the configuration of
+// the server.
+type Set struct {
+	config map[string]bool
+}
+
+// New creates and returns a new Set, whose flags take the given values on every site
+// (their default value if not given).
+func New(config map[string]bool) *Set {
+	return &Set{config: config}
+}
+
+// Enabled reports whether the named flag is enabled for the request the context
+// belongs to. The flags which are not defined are never enabled.
+func (s *Set) Enabled(ctx context.Context, name string) bool {
+	state, ok := s.State(ctx, name)
+	return ok && state.Enabled
+}
+
+// State returns the state of the named flag for the request the context belongs to,
+// and whether the flag is defined.
+func (s *Set) State(ctx context.Context, name string) (State, bool) {
+	flag, ok := Lookup(name)
+	if !ok {
+		return State{}, false
+	}
+
+	return s.evaluate(ctx, flag), true
+}
+
+// Evaluate returns the state of every flag for the request the context belongs to.
+func (s *Set) Evaluate(ctx context.Context) []State {
+	states := make([]State, 0, len(Builtin))
+	for _, flag := range Builtin {
+		states = append(states, s.evaluate(ctx, flag))
+	}
+
+	return states
+}
+
+// evaluate returns the state of the flag for the request the context belongs to.
+func (s *Set) evaluate(ctx context.Context, flag Flag) State {
+	if enabled, ok := fromContext(ctx)[flag.Name]; ok {
+		return State{Flag: flag, Enabled: enabled, Source: SourceRequest}
+	}
+
+	if site, ok := tenant.FromContext(ctx); ok {
+		if enabled, ok := site.Flags[flag.Name]; ok {
+			return State{Flag: flag, Enabled: enabled, Source: SourceSite}
+		}
+	}
+
+	if enabled, ok := s.config[flag.Name]; ok {
+		return State{Flag: flag, Enabled: enabled, Source: SourceConfig}
+	}
+
+	return State{Flag: flag, Enabled: flag.Default, Source: SourceDefault}
+}
+
+// contextKey is the unexported type of the context key holding the values of the flags
+// asked for by the request.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the values of the flags
+// asked for by the request, which take precedence over every other value.
+func NewContext(parent context.Context, values map[string]bool) context.Context {
+	return context.WithValue(parent, contextKey{}, values)
+}
+
+// fromContext returns the values of the flags held by the context, if any.
+func fromContext(ctx context.Context) map[string]bool {
+	values, _ := ctx.Value(contextKey{}).(map[string]bool)
+	return values
+}
+
+// parseBool parses a boolean, accepting `on` and `off` along with the values accepted
+// by `strconv.ParseBool`.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	default:
+		return strconv.ParseBool(s)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Package geoip provides the access rules of the server by country, which restrict the
+content of the sites licensed for some countries only.
+
+The country of each request is located from the IP address of its client, in a MaxMind
+DB file (e.g. the GeoLite2 Country database, see `Open`), and held by the context of
+the request (see `NewContext`) so that the page views recorded by the analytics carry
+it. The rules (see `ParseRules`) then either block the requests of a country, or only
+let its read requests through.
+*/
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The modes of the access rules.
+const (
+	// ModeBlock rejects every request of the country.
+	ModeBlock = "block"
+
+	// ModeReadOnly rejects the requests of the country which are not read requests.
+	ModeReadOnly = "read-only"
+)
+
+// ErrInvalidRule is returned when an access rule can not be parsed.
+var ErrInvalidRule = errors.New("invalid geo access rule")
+
+// Rules holds the access rules, mapping the ISO 3166-1 alpha-2 code of each restricted
+// country (e.g. "DE") to its mode (e.g. `ModeReadOnly`).
+type Rules map[string]string
+
+/*
+ParseRules parses the access rules given as a comma-separated list of ISO 3166-1
+alpha-2 country codes, each followed by `=` and the mode of the country (`block` or
+`read-only`), e.g. "KP=block, DE=read-only".
+
+Nil is returned if the list is empty. An error wrapping `ErrInvalidRule` is returned if
+a rule is invalid.
+*/
+func ParseRules(spec string) (Rules, error) {
+	var rules Rules
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		code, mode, ok := strings.Cut(field, "=")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		mode = strings.ToLower(strings.TrimSpace(mode))
+		if !ok || !isCountryCode(code) {
+			return nil, fmt.Errorf("%w %q: invalid country code", ErrInvalidRule, field)
+		}
+
+		if mode != ModeBlock && mode != ModeReadOnly {
+			return nil, fmt.Errorf("%w %q: unknown mode", ErrInvalidRule, field)
+		}
+
+		if rules == nil {
+			rules = make(Rules)
+		}
+		rules[code] = mode
+	}
+
+	return rules, nil
+}
+
+// Mode returns the mode of the country, or an empty string if it is not restricted
+// (e.g. if its country is unknown).
+func (r Rules) Mode(country string) string {
+	return r[strings.ToUpper(country)]
+}
+
+// isCountryCode reports whether the code is made of two ASCII letters.
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// contextKey is the unexported type of the context key holding the country of the
+// request.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the ISO 3166-1 alpha-2 code
+// of the country the request was made from.
+func NewContext(parent context.Context, country string) context.Context {
+	return context.WithValue(parent, contextKey{}, country)
+}
+
+// FromContext returns the ISO 3166-1 alpha-2 code of the country the request was made
+// from, or an empty string if it is unknown.
+func FromContext(ctx context.Context) string {
+	country, _ := ctx.Value(contextKey{}).(string)
+	return country
+}
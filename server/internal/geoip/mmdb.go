@@ -0,0 +1,81 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// ErrInvalidDatabase is returned when a MaxMind DB file can not be read.
+var ErrInvalidDatabase = errors.New("invalid MaxMind DB file")
+
+/*
+DB is a MaxMind DB file (see https://maxmind.github.io/MaxMind-DB/), read in memory,
+which locates the IP addresses. Only the country of the addresses is looked up, hence
+any database holding the `country` (or the `registered_country`) of the networks will
+do, e.g. GeoLite2 Country or GeoLite2 City. It is safe for concurrent use.
+*/
+type DB struct {
+	reader *maxminddb.Reader
+}
+
+// record holds the fields of the records of a MaxMind DB file the country is looked up
+// from.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+/*
+Open reads the MaxMind DB file at path in memory.
+
+An error is returned if the file can not be read, and an error wrapping
+`ErrInvalidDatabase` if it is not a valid MaxMind DB file.
+*/
+func Open(path string) (*DB, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read MaxMind DB file: %w", err)
+	}
+
+	reader, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+
+	return &DB{reader: reader}, nil
+}
+
+/*
+Country returns the ISO 3166-1 alpha-2 code of the country of the IP address (e.g.
+"DE"): the country the network is located in, or else the one it is registered in. An
+empty string is returned if the address is not found.
+
+An error wrapping `ErrInvalidDatabase` is returned if the record of the address can not
+be read.
+*/
+func (db *DB) Country(addr netip.Addr) (string, error) {
+	addr = addr.Unmap()
+	if addr.Is6() && db.reader.Metadata.IPVersion == 4 {
+		return "", nil
+	}
+
+	var r record
+	if err := db.reader.Lookup(net.IP(addr.AsSlice()), &r); err != nil {
+		return "", fmt.Errorf("%w: record of %s: %v", ErrInvalidDatabase, addr, err)
+	}
+
+	if r.Country.ISOCode != "" {
+		return r.Country.ISOCode, nil
+	}
+
+	return r.RegisteredCountry.ISOCode, nil
+}
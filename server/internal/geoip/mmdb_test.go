@@ -0,0 +1,140 @@
+package geoip_test
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Weburz/burzcontent/server/internal/geoip"
+)
+
+/*
+testDB is a MaxMind DB file (IPv4, 24-bit records) whose search tree is a single node:
+the addresses of `0.0.0.0/1` are located in Germany, and the ones of `128.0.0.0/1` are
+not found.
+*/
+var testDB = concat(
+	// The search tree: the left record points to the first field of the data section
+	// (node count + 16), and the right record to no data (node count)
+	[]byte{0x00, 0x00, 0x11, 0x00, 0x00, 0x01},
+	make([]byte, 16),
+	// The data section: {"country": {"iso_code": "DE"}}
+	[]byte{0xE1, 0x47}, []byte("country"),
+	[]byte{0xE1, 0x48}, []byte("iso_code"), []byte{0x42}, []byte("DE"),
+	// The metadata
+	[]byte("\xAB\xCD\xEFMaxMind.com"),
+	[]byte{0xE5},
+	[]byte{0x4A}, []byte("node_count"), []byte{0xC1, 0x01},
+	[]byte{0x4B}, []byte("record_size"), []byte{0xA1, 0x18},
+	[]byte{0x4A}, []byte("ip_version"), []byte{0xA1, 0x04},
+	[]byte{0x5B}, []byte("binary_format_major_version"), []byte{0xA1, 0x02},
+	[]byte{0x4D}, []byte("database_type"), []byte{0x44}, []byte("Test"),
+)
+
+// concat concatenates the byte slices.
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+
+	return b
+}
+
+// openBytes opens the MaxMind DB file holding the bytes.
+func openBytes(tb testing.TB, b []byte) (*geoip.DB, error) {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "test.mmdb")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		tb.Fatalf("Unable to write the database: %v", err)
+	}
+
+	return geoip.Open(path)
+}
+
+func TestCountry(t *testing.T) {
+	db, err := openBytes(t, testDB)
+	if err != nil {
+		t.Fatalf("Unable to open the database: %v", err)
+	}
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"1.2.3.4", "DE"},
+		{"127.0.0.1", "DE"},
+		{"::ffff:1.2.3.4", "DE"},
+		{"128.0.0.1", ""},
+		{"203.0.113.7", ""},
+		{"2001:db8::1", ""},
+	}
+
+	for _, test := range tests {
+		country, err := db.Country(netip.MustParseAddr(test.addr))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v\n", test.addr, err)
+		} else if country != test.want {
+			t.Errorf("%s: expected the country %q. Got %q\n", test.addr, test.want,
+				country)
+		}
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":            {},
+		"garbage":          []byte("not a MaxMind DB file"),
+		"no metadata":      testDB[:len(testDB)-90],
+		"truncated fields": testDB[:len(testDB)-4],
+		"record size": bytes.Replace(
+			testDB,
+			[]byte("record_size\xA1\x18"),
+			[]byte("record_size\xA1\x10"),
+			1,
+		),
+		"tree size": bytes.Replace(
+			testDB,
+			[]byte("node_count\xC1\x01"),
+			[]byte("node_count\xC1\x40"),
+			1,
+		),
+	}
+
+	for name, b := range tests {
+		if _, err := openBytes(t, b); !errors.Is(err, geoip.ErrInvalidDatabase) {
+			t.Errorf("%s: expected ErrInvalidDatabase. Got %v\n", name, err)
+		}
+	}
+}
+
+// FuzzCountry checks that the malformed databases are rejected, or their records
+// reported as invalid, rather than panicking.
+func FuzzCountry(f *testing.F) {
+	f.Add(testDB, []byte{1, 2, 3, 4})
+	f.Add(testDB[:len(testDB)-4], []byte{200, 0, 0, 1})
+
+	f.Fuzz(func(t *testing.T, b []byte, ip []byte) {
+		db, err := openBytes(t, b)
+		if err != nil {
+			if !errors.Is(err, geoip.ErrInvalidDatabase) {
+				t.Errorf("Expected ErrInvalidDatabase. Got %v", err)
+			}
+			return
+		}
+
+		addr, ok := netip.AddrFromSlice(ip)
+		if !ok {
+			return
+		}
+
+		if _, err := db.Country(addr); err != nil &&
+			!errors.Is(err, geoip.ErrInvalidDatabase) {
+			t.Errorf("Expected ErrInvalidDatabase. Got %v", err)
+		}
+	})
+}
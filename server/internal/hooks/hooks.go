@@ -0,0 +1,217 @@
+/*
+Package hooks provides the inbound webhooks of the server, through which external
+services (e.g. GitHub or Stripe) notify the sites of their events.
+
+Each integration is identified by its name (e.g. "github") and shares a secret with the
+server (see `ParseSecrets`), with which the external service signs its requests using
+HMAC-SHA256 (see `Verify`). The events of the integrations are then translated into
+internal actions, i.e. runs of the jobs of the server (see `ParseActions`), e.g. to
+regenerate the sitemaps once the content is pushed to a repository.
+*/
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stripe is the name of the integration verified like the webhooks of Stripe.
+const Stripe = "stripe"
+
+// Tolerance is the maximum age of the timestamp of a request signed like the webhooks
+// of Stripe, which guards against the replays of the requests.
+const Tolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature is returned when the signature of a request is missing or
+	// does not match its body.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrInvalidHook is returned when a secret or an action of an integration can not
+	// be parsed.
+	ErrInvalidHook = errors.New("invalid webhook setting")
+)
+
+/*
+ParseSecrets parses the secrets of the integrations given as a comma-separated list of
+integration names, each followed by `=` and its secret, e.g.
+"github=s3cr3t, stripe=whsec_...".
+
+An error wrapping `ErrInvalidHook` is returned if an integration has no name or no
+secret.
+*/
+func ParseSecrets(spec string) (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		name, secret, _ := strings.Cut(field, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		secret = strings.TrimSpace(secret)
+		if name == "" || secret == "" {
+			return nil, fmt.Errorf("%w: secret of %q", ErrInvalidHook, name)
+		}
+
+		secrets[name] = secret
+	}
+
+	return secrets, nil
+}
+
+// Actions maps the events of the integrations, as "integration:event" (the event
+// being `*` for every event of the integration), to the names of the jobs they run.
+type Actions map[string][]string
+
+/*
+ParseActions parses the actions of the integrations given as a semicolon-separated list
+of events, each made of the name of its integration, `:` and its type (`*` for every
+event of the integration), followed by `=` and a comma-separated list of the jobs it
+runs, e.g. "github:push=regenerate-sitemaps; stripe:*=publish-scheduled".
+
+An error wrapping `ErrInvalidHook` is returned if an action is invalid. The jobs are not
+checked, the unknown ones failing when they are run.
+*/
+func ParseActions(spec string) (Actions, error) {
+	actions := make(Actions)
+
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, jobs, _ := strings.Cut(item, "=")
+		name, event, ok := strings.Cut(strings.TrimSpace(key), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		event = strings.TrimSpace(event)
+		if !ok || name == "" || event == "" {
+			return nil, fmt.Errorf("%w: action %q", ErrInvalidHook, item)
+		}
+
+		for _, job := range strings.Split(jobs, ",") {
+			if job = strings.ToLower(strings.TrimSpace(job)); job != "" {
+				actions[name+":"+event] = append(actions[name+":"+event], job)
+			}
+		}
+
+		if len(actions[name+":"+event]) == 0 {
+			return nil, fmt.Errorf("%w: action %q runs no job", ErrInvalidHook, item)
+		}
+	}
+
+	return actions, nil
+}
+
+// Jobs returns the names of the jobs run by the event of the integration: the ones of
+// the event itself, then the ones of every event of the integration.
+func (a Actions) Jobs(integration, event string) []string {
+	jobs := append([]string{}, a[integration+":"+event]...)
+	return append(jobs, a[integration+":*"]...)
+}
+
+/*
+Verify verifies the HMAC-SHA256 signature of the body of a request of the integration
+with its secret:
+  - The requests of the "stripe" integration are signed like the webhooks of Stripe,
+    with a `Stripe-Signature` header (e.g. `t=1700000000,v1=<hex>`) signing the
+    timestamp, `.` and the body. The timestamp has to be within `Tolerance` of now.
+  - The requests of the other integrations are signed like the webhooks of GitHub,
+    with a `X-Hub-Signature-256` header (e.g. `sha256=<hex>`) signing the body.
+
+An error wrapping `ErrInvalidSignature` is returned if the signature is missing or
+does not match.
+*/
+func Verify(
+	integration, secret string,
+	header http.Header,
+	body []byte,
+	now time.Time,
+) error {
+	if integration == Stripe {
+		return verifyStripe(secret, header.Get("Stripe-Signature"), body, now)
+	}
+
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok || !matches(secret, body, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// verifyStripe verifies the value of the `Stripe-Signature` header of a request, one of
+// whose `v1` signatures has to match.
+func verifyStripe(secret, value string, body []byte, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(value, ",") {
+		key, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidSignature)
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > Tolerance || age < -Tolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrInvalidSignature)
+	}
+
+	signed := append([]byte(timestamp+"."), body...)
+	for _, signature := range signatures {
+		if matches(secret, signed, signature) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// matches reports whether the hex-encoded signature is the HMAC-SHA256 of the message
+// with the secret, in constant time.
+func matches(secret string, message []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(message)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Event returns the type of the event notified by a request: the `X-GitHub-Event`
+// header of the requests of GitHub, or else the `type` field of their JSON body (e.g.
+// "invoice.paid" for Stripe). An empty string is returned if it is unknown.
+func Event(header http.Header, body []byte) string {
+	if event := header.Get("X-GitHub-Event"); event != "" {
+		return event
+	}
+
+	var payload struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(body, &payload)
+
+	return payload.Type
+}
@@ -0,0 +1,143 @@
+/*
+Package idempotency provides an in-memory cache of the responses to the requests
+carrying an idempotency key.
+
+A `Store` remembers the response to the first request made with a key for a window of
+time, so that the retries of the request (e.g. after a network failure) are answered
+with the original response instead of being processed again. Each key is bound to the
+fingerprint of the request it was first used with, so that a key can not be reused for
+another request.
+*/
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+var (
+	// ErrInProgress is returned when a request is made with a key whose first request
+	// is still being processed.
+	ErrInProgress = errors.New("request with the same idempotency key in progress")
+
+	// ErrMismatch is returned when a key is reused for another request than the one it
+	// was first used with.
+	ErrMismatch = errors.New("idempotency key used for another request")
+)
+
+/*
+Response is a response recorded for an idempotency key.
+
+Fields:
+  - Status: The HTTP status code of the response.
+  - Header: The HTTP headers of the response.
+  - Body: The body of the response.
+*/
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// entry is the state of a single key; its response is nil while the first request
+// made with the key is being processed.
+type entry struct {
+	fingerprint string
+	response    *Response
+	created     time.Time
+}
+
+// maxEntries is the number of entries above which the expired entries are swept.
+const maxEntries = 10000
+
+// Store is a concurrency-safe cache of the responses recorded for idempotency keys.
+type Store struct {
+	window time.Duration
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// New creates and returns a new Store remembering the responses for the window, as
+// time goes by on the given clock.
+func New(window time.Duration, clock clock.Clock) *Store {
+	return &Store{
+		window:  window,
+		clock:   clock,
+		entries: make(map[string]*entry),
+	}
+}
+
+/*
+Begin starts a request made with the key, identified by its fingerprint.
+
+It returns the recorded response if the request was already processed, in which case
+it must not be processed again, or nil if it has to be processed (and then either
+completed with `Complete` or abandoned with `Abort`). `ErrInProgress` is returned if
+the first request made with the key is still being processed, and `ErrMismatch` if the
+key was first used with another request.
+*/
+func (s *Store) Begin(key, fingerprint string) (*Response, error) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if ok && now.Sub(e.created) >= s.window {
+		delete(s.entries, key)
+		ok = false
+	}
+
+	if !ok {
+		if len(s.entries) >= maxEntries {
+			s.sweep(now)
+		}
+
+		s.entries[key] = &entry{fingerprint: fingerprint, created: now}
+		return nil, nil
+	}
+
+	switch {
+	case e.fingerprint != fingerprint:
+		return nil, ErrMismatch
+	case e.response == nil:
+		return nil, ErrInProgress
+	}
+
+	return e.response, nil
+}
+
+// Complete records the response to the request started with the key, which is
+// returned by `Begin` for the rest of the window.
+func (s *Store) Complete(key string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.response = &response
+	}
+}
+
+// Abort forgets the request started with the key, which can then be made again (e.g.
+// after it failed unexpectedly).
+func (s *Store) Abort(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+// sweep removes the entries which are older than the window; s.mu must be held.
+func (s *Store) sweep(now time.Time) {
+	for key, e := range s.entries {
+		if now.Sub(e.created) >= s.window {
+			delete(s.entries, key)
+		}
+	}
+}
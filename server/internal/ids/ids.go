@@ -0,0 +1,149 @@
+/*
+Package ids provides the generators of the unique identifiers of the resources.
+
+Every identifier is a 128-bit value held by a `uuid.UUID`, and is sortable by creation
+time. Two generators are available:
+  - `UUIDv7`, generating version 7 UUIDs (the default).
+  - `ULID`, generating ULIDs (see https://github.com/ulid/spec), for the systems which
+    sort the resources by ULID natively. The ULIDs are still written in the UUID
+    notation by the API, their 16 bytes being laid out as defined by the
+    specification, so that they can be encoded in the ULID notation losslessly.
+
+The `Sequential` generator generates predictable identifiers instead, for the tests
+asserting the exact responses of the handlers. Every generator is a `Generator`.
+*/
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+const (
+	// FormatUUIDv7 is the name of the format of the identifiers generated by `UUIDv7`.
+	FormatUUIDv7 = "uuidv7"
+
+	// FormatULID is the name of the format of the identifiers generated by `ULID`.
+	FormatULID = "ulid"
+)
+
+// Generator generates the unique identifiers of the new resources, like `UUIDv7`,
+// `ULID` and `Sequential` do.
+type Generator interface {
+	NewID() uuid.UUID
+}
+
+// UUIDv7 generates version 7 UUIDs, which are monotonic within the process.
+type UUIDv7 struct{}
+
+// NewID generates a new version 7 UUID.
+func (UUIDv7) NewID() uuid.UUID {
+	// The generation can only fail if the system random number generator does, which
+	// never happens since Go 1.24
+	return uuid.Must(uuid.NewV7())
+}
+
+/*
+ULID generates ULIDs timestamped with the time of its clock, which are monotonic within
+the process: the ULIDs generated within the same millisecond increment the random part
+of the previous one.
+*/
+type ULID struct {
+	clock clock.Clock
+
+	mu   sync.Mutex
+	last uuid.UUID
+}
+
+// NewULID creates and returns a new ULID generator, timestamping the ULIDs with the
+// time told by the given clock.
+func NewULID(clock clock.Clock) *ULID {
+	return &ULID{clock: clock}
+}
+
+// NewID generates a new ULID.
+func (g *ULID) NewID() uuid.UUID {
+	ms := uint64(g.clock.Now().UnixMilli())
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// The timestamp is held by the first 48 bits, which are never set to an earlier
+	// time than the one of the previous ULID (e.g. if the clock goes backwards)
+	var id uuid.UUID
+	if last := binary.BigEndian.Uint64(g.last[:8]) >> 16; ms <= last {
+		id, ms = g.last, last
+		if !increment(id[6:]) {
+			// The random part overflowed, hence the next millisecond is borrowed
+			ms++
+			_, _ = rand.Read(id[6:])
+		}
+	} else {
+		_, _ = rand.Read(id[6:])
+	}
+
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], ms<<16)
+	copy(id[:6], timestamp[:6])
+
+	g.last = id
+
+	return id
+}
+
+/*
+Sequential generates predictable identifiers: the identifiers generated from the same
+seed are the same, in the same order, e.g. "00000000-0000-7001-8000-000000000001" for
+the first identifier of seed 1. They are shaped as version 7 UUIDs, the seed being held
+by the first 64 bits (bar the version) and a counter by the last 64 bits (bar the
+variant), hence sort in the order they are generated.
+
+It is meant for the tests only, the identifiers not being unique across generators.
+*/
+type Sequential struct {
+	mu   sync.Mutex
+	seed uint64
+	next uint64
+}
+
+// NewSequential creates and returns a new Sequential generator starting from the
+// given seed.
+func NewSequential(seed uint64) *Sequential {
+	return &Sequential{seed: seed}
+}
+
+// NewID generates the next identifier of the sequence.
+func (g *Sequential) NewID() uuid.UUID {
+	g.mu.Lock()
+	g.next++
+	n := g.next
+	g.mu.Unlock()
+
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[:8], g.seed)
+	binary.BigEndian.PutUint64(id[8:], n)
+
+	// Set the version (7) and the variant (RFC 9562) bits
+	id[6] = 0x70 | id[6]&0x0f
+	id[8] = 0x80 | id[8]&0x3f
+
+	return id
+}
+
+// increment adds one to the big-endian number held by b, reporting false if it
+// overflowed.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
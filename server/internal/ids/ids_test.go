@@ -0,0 +1,37 @@
+package ids_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/ids"
+)
+
+// fixedClock tells the same time on every call.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// TestULID checks that the ULIDs are timestamped with the time of the clock of the
+// generator, and are monotonic within the same millisecond.
+func TestULID(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	generator := ids.NewULID(fixedClock(now))
+
+	first := generator.NewID()
+	second := generator.NewID()
+
+	for _, id := range [][16]byte{first, second} {
+		ms := binary.BigEndian.Uint64(id[:8]) >> 16
+		if expected := uint64(now.UnixMilli()); ms != expected {
+			t.Errorf("Expected the timestamp %d. Got %d\n", expected, ms)
+		}
+	}
+	if bytes.Compare(first[:], second[:]) >= 0 {
+		t.Errorf("Expected %s to sort before %s\n", first, second)
+	}
+}
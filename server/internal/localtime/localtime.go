@@ -0,0 +1,199 @@
+/*
+Package localtime resolves the wall clock times of a time zone (e.g. "2026-03-29T02:30"
+in Europe/Berlin) to the times they stand for, across its daylight saving time
+transitions.
+
+The times are stored in UTC, but the sites schedule their content in their own time
+zone: the times given without a UTC offset in the request bodies are taken in the time
+zone of the site (see `Localize`), and the schedules of the jobs may be evaluated in
+any time zone (see `scheduler.Parse`). A wall clock time skipped when the clocks are
+moved forward stands for the time they are moved forward at, and one repeated when
+they are moved back for the first time it is shown (see `Resolve`).
+*/
+package localtime
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// layouts are the layouts of the times given without a UTC offset, from the most to
+// the least precise.
+var layouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+}
+
+// timeType is the type of the times, which the wall clock times are localized for.
+var timeType = reflect.TypeFor[time.Time]()
+
+// WallClock returns the wall clock time of t (i.e. its date and its time of the day in
+// its time zone) as a time in UTC.
+func WallClock(t time.Time) time.Time {
+	return time.Date(
+		t.Year(),
+		t.Month(),
+		t.Day(),
+		t.Hour(),
+		t.Minute(),
+		t.Second(),
+		t.Nanosecond(),
+		time.UTC,
+	)
+}
+
+/*
+Resolve returns the first time at which the clocks of the time zone show the wall clock
+time (held in UTC, see `WallClock`), or the time they were moved forward at if they
+skipped it.
+
+Example:
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	// 2026-03-29T03:00:00+02:00, the clocks skipping from 02:00 to 03:00
+	t := localtime.Resolve(time.Date(2026, 3, 29, 2, 30, 0, 0, time.UTC), berlin)
+*/
+func Resolve(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(
+		wall.Year(),
+		wall.Month(),
+		wall.Day(),
+		wall.Hour(),
+		wall.Minute(),
+		wall.Second(),
+		wall.Nanosecond(),
+		loc,
+	)
+
+	// The time skipped when the clocks were moved forward is normalized to either side
+	// of the transition
+	if shown := WallClock(t); !shown.Equal(wall) {
+		start, end := t.ZoneBounds()
+		if shown.After(wall) {
+			return start
+		}
+		return end
+	}
+
+	// The time shown twice when the clocks were moved back is first shown in the zone
+	// preceding the one of t, if t is the second time
+	start, _ := t.ZoneBounds()
+	if !start.IsZero() {
+		_, offset := t.Zone()
+		_, previous := start.Add(-time.Second).Zone()
+		earlier := t.Add(time.Duration(offset-previous) * time.Second)
+		if earlier.Before(start) && WallClock(earlier).Equal(wall) {
+			return earlier
+		}
+	}
+
+	return t
+}
+
+// Parse parses a time given without a UTC offset (e.g. "2026-03-29T02:30" or
+// "2026-03-29 02:30:00") as a wall clock time of the time zone, and reports whether it
+// is one.
+func Parse(value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range layouts {
+		if wall, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return Resolve(wall, loc), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+/*
+Localize replaces the times given without a UTC offset in a decoded JSON value (i.e.
+the strings of its fields holding a `time.Time` in the type it is decoded into) with
+the RFC 3339 encoding of the times they stand for in the time zone (see `Parse`), so
+that they are decoded as such. The other values are kept, for the decoder to report
+the malformed ones.
+*/
+func Localize(value any, t reflect.Type, loc *time.Location) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		if s, ok := value.(string); ok {
+			if local, ok := Parse(s, loc); ok {
+				return local.Format(time.RFC3339Nano)
+			}
+		}
+	case t.Kind() == reflect.Struct:
+		if object, ok := value.(map[string]any); ok {
+			fields := fieldsOf(t)
+			for key, v := range object {
+				if ft, ok := fields[key]; ok {
+					object[key] = Localize(v, ft, loc)
+				}
+			}
+		}
+	case t.Kind() == reflect.Slice, t.Kind() == reflect.Array:
+		if values, ok := value.([]any); ok {
+			for i, v := range values {
+				values[i] = Localize(v, t.Elem(), loc)
+			}
+		}
+	case t.Kind() == reflect.Map:
+		if object, ok := value.(map[string]any); ok {
+			for key, v := range object {
+				object[key] = Localize(v, t.Elem(), loc)
+			}
+		}
+	}
+
+	return value
+}
+
+// fieldCache caches the types of the fields of the structs, by type.
+var fieldCache sync.Map
+
+// fieldsOf returns the types of the fields of the struct type (including the fields of
+// its embedded structs) by their name in the JSON documents.
+func fieldsOf(t reflect.Type) map[string]reflect.Type {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string]reflect.Type)
+	}
+
+	found := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		// Inline the fields of the embedded structs, like the decoder does
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			for key, inner := range fieldsOf(ft) {
+				if _, ok := found[key]; !ok {
+					found[key] = inner
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		found[name] = f.Type
+	}
+
+	fieldCache.Store(t, found)
+
+	return found
+}
@@ -1,59 +1,68 @@
-// Package logger provides a simple logging utility using the standard library's
-// log/slog package. It initializes a global logger that can be accessed globally
-// throughout the application.
+/*
+Package logger provides the structured logger of the server, built on the standard
+library's log/slog package.
+
+The logger is constructed once by the server from its configuration (see `New`), then
+handed down to the handlers and stored in the context of each request (see
+`NewContext`), along with the attributes identifying the request, such as its request
+ID and the user making it. The code serving a request logs through the logger of its
+context (see `FromContext`), hence every line is tied to the request, and to its trace
+when logged along with the context (see `NewTraceHandler`). The personal data and the
+secrets are masked from every line (see `Redact`).
+*/
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
-	"os"
 )
 
-var (
-	// logger holds the global instance of the logger used throughout the application.
-	logger *slog.Logger
-)
+// contextKey is the type of the key the logger is stored under in a context.
+type contextKey struct{}
+
+/*
+New creates and returns a new logger writing to w, in the format of the environment
+type:
+  - production: JSON lines, from the info level up.
+  - any other environment: human-readable text along with the location of the calls,
+    from the debug level up.
+
+The records logged within a request carry the IDs of its trace (see
+`NewTraceHandler`), and the personal data and the secrets are masked from every record
+(see `NewRedactHandler`).
+*/
+func New(env string, w io.Writer) *slog.Logger {
+	if env == "production" {
+		return slog.New(NewTraceHandler(NewRedactHandler(slog.NewJSONHandler(
+			w,
+			&slog.HandlerOptions{Level: slog.LevelInfo},
+		))))
+	}
+
+	return slog.New(NewTraceHandler(NewRedactHandler(slog.NewTextHandler(
+		w,
+		&slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug},
+	))))
+}
+
+// NewContext returns a copy of the context holding the logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
 
-// init initializes the logger with a text handler that writes logs to standard output.
-// The logger instance is created and assigned to the global 'logger' variable.
-func init() {
-	var handler slog.Handler
-
-	// Get the runtime environment type (could be "development" or "production"). It
-	// should be fetched from an environment variable
-	mode := os.Getenv("ENV")
-
-	// Choose the appropriate handler based on the mode
-	if mode == "production" {
-		// Attempt to open a JSON-based log, else panic and exit the execution
-		file, err := os.OpenFile("logs.json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-		if err != nil {
-			panic(err)
-		}
-
-		// Custom JSONHandler options
-		opts := &slog.HandlerOptions{
-			AddSource: false,          // Disable stack tracing in production logs
-			Level:     slog.LevelInfo, // Set the log level to "ERROR" in production
-		}
-		// Production mode: use JsonHandler
-		handler = slog.NewJSONHandler(file, opts)
-	} else {
-		// Custom TextHandler options
-		opts := &slog.HandlerOptions{
-			AddSource: true,            // Show the location of the stack in the log
-			Level:     slog.LevelDebug, // Set the log level to "DEBUG"
-		}
-		// Development mode: use TextHandler
-		handler = slog.NewTextHandler(os.Stdout, opts)
+// FromContext returns the logger held by the context, or the default logger of the
+// slog package if the context holds none (e.g. outside of a request).
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
 	}
 
-	// Initialize the logger with the chosen handler
-	logger = slog.New(handler)
+	return slog.Default()
 }
 
-// NewLogger returns the global logger instance, which can be used to log messages
-// throughout the application. This function provides easy access to the logger
-// without needing to create a new instance.
-func NewLogger() *slog.Logger {
-	return logger
+// With returns a copy of the context whose logger has the given attributes attached,
+// e.g. `With(ctx, "user_id", id)`.
+func With(ctx context.Context, args ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(args...))
 }
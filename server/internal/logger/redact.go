@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+)
+
+// redacted replaces the secrets masked by `Redact`.
+const redacted = "[REDACTED]"
+
+var (
+	// queryTokenPattern matches the values of the query parameters carrying secrets or
+	// personal data, e.g. `?token=...` in the logged URLs.
+	queryTokenPattern = regexp.MustCompile(
+		`(?i)([?&](?:token|key|api_key|secret|password|signature|sig|email)=)[^&\s"]+`,
+	)
+
+	// bearerPattern matches the credentials of the `Authorization` headers.
+	bearerPattern = regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[^\s"]+`)
+
+	// jwtPattern matches the JSON Web Tokens, e.g. the ones of the identity providers.
+	jwtPattern = regexp.MustCompile(`\beyJ[\w-]+\.[\w-]+\.[\w-]+`)
+
+	// apiKeyPattern matches the API keys issued by the server (see `auth.GenerateKey`).
+	apiKeyPattern = regexp.MustCompile(regexp.QuoteMeta(auth.KeyPrefix) + `[\w-]+`)
+
+	// emailPattern matches the email addresses, whose domain is kept.
+	emailPattern = regexp.MustCompile(
+		`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,})`,
+	)
+
+	// ipPattern matches the candidate IPv4 and IPv6 addresses, the ones which do not
+	// parse (e.g. the times) being left as they are.
+	ipPattern = regexp.MustCompile(
+		`\b(?:\d{1,3}\.){3}\d{1,3}\b|(?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f.]*`,
+	)
+)
+
+/*
+Redact masks the personal data and the secrets found in the text, so that it can be
+logged:
+  - The email addresses keep their domain only, e.g. "***@example.com".
+  - The IP addresses are truncated to their network, i.e. the last byte of the IPv4
+    addresses and the last 80 bits of the IPv6 addresses are zeroed, e.g.
+    "192.0.2.0", which is enough to tell the traffic of a network apart.
+  - The API keys, the bearer tokens, the JSON Web Tokens and the values of the query
+    parameters carrying secrets (e.g. `?token=`) are replaced by "[REDACTED]".
+*/
+func Redact(text string) string {
+	text = queryTokenPattern.ReplaceAllString(text, "${1}"+redacted)
+	text = bearerPattern.ReplaceAllString(text, "${1} "+redacted)
+	text = jwtPattern.ReplaceAllString(text, redacted)
+	text = apiKeyPattern.ReplaceAllString(text, auth.KeyPrefix+redacted)
+	text = emailPattern.ReplaceAllString(text, "***@${1}")
+
+	return ipPattern.ReplaceAllStringFunc(text, maskIP)
+}
+
+// maskIP zeroes the host part of the IP address, leaving the text as it is if it is not
+// an IP address.
+func maskIP(text string) string {
+	addr, err := netip.ParseAddr(text)
+	if err != nil {
+		return text
+	}
+
+	bits := 48
+	if addr = addr.Unmap(); addr.Is4() {
+		bits = 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return text
+	}
+
+	return prefix.Addr().String()
+}
+
+// sensitiveKey reports whether the attributes of the key hold a secret as a whole, e.g.
+// "token" or "preview_token", which may not be told apart from the rest of the text.
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, name := range []string{"authorization", "cookie", "api_key"} {
+		if key == name {
+			return true
+		}
+	}
+
+	for _, name := range []string{"token", "secret", "password"} {
+		if key == name || strings.HasSuffix(key, "_"+name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+NewRedactHandler wraps the handler so that the personal data and the secrets are masked
+from the message and the attributes of every record (see `Redact`), along with the
+whole value of the attributes named after a secret (e.g. "token" or "password").
+
+The attributes which are neither strings nor groups (e.g. errors or structs) are logged
+as their redacted text, so that the email addresses of a logged user do not slip
+through.
+*/
+func NewRedactHandler(h slog.Handler) slog.Handler {
+	return &redactHandler{Handler: h}
+}
+
+// redactHandler masks the personal data and the secrets of the records.
+type redactHandler struct {
+	slog.Handler
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redactedRecord := slog.NewRecord(
+		record.Time,
+		record.Level,
+		Redact(record.Message),
+		record.PC,
+	)
+
+	record.Attrs(func(a slog.Attr) bool {
+		redactedRecord.AddAttrs(redactAttr(a))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, redactedRecord)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redactedAttrs = append(redactedAttrs, redactAttr(a))
+	}
+
+	return &redactHandler{Handler: h.Handler.WithAttrs(redactedAttrs)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// redactAttr masks the personal data and the secrets of the attribute.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	switch kind := a.Value.Kind(); {
+	case kind == slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]any, 0, len(group))
+		for _, ga := range group {
+			attrs = append(attrs, redactAttr(ga))
+		}
+		return slog.Group(a.Key, attrs...)
+	case sensitiveKey(a.Key):
+		return slog.String(a.Key, redacted)
+	case kind == slog.KindString:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	case kind == slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+		return slog.String(a.Key, Redact(fmt.Sprintf("%+v", a.Value.Any())))
+	default:
+		return a
+	}
+}
+
+/*
+NewRedactWriter wraps the writer so that the personal data and the secrets are masked
+from the text written to it (see `Redact`), for the logs which are not structured, e.g.
+the ones of the standard log package or the access log.
+
+Each write is redacted on its own, hence the text has to be written a line at a time,
+like the log package does.
+*/
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+// redactWriter masks the personal data and the secrets of the written text.
+type redactWriter struct {
+	w io.Writer
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
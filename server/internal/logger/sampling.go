@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSampling is returned when a rule of the log sampling can not be parsed.
+var ErrInvalidSampling = errors.New("invalid log sampling rule")
+
+/*
+SamplingRule represents the share of the requests to some routes whose successful
+responses are logged.
+
+Fields:
+  - Method: The HTTP method of the requests the rule matches, every method if empty.
+  - Pattern: The path of the requests the rule matches. A pattern ending with `*`
+    matches every path starting with the rest of it (e.g. `/articles/*`), the other
+    patterns match the path exactly.
+  - Rate: The share of the successful responses logged, between 0 and 1.
+*/
+type SamplingRule struct {
+	Method  string
+	Pattern string
+	Rate    float64
+}
+
+// Matches reports whether the rule applies to a request of the method to the path.
+func (r SamplingRule) Matches(method, path string) bool {
+	if r.Method != "" && r.Method != method {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(r.Pattern, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+
+	return path == r.Pattern
+}
+
+/*
+ParseSampling parses the rules of the log sampling given as a semicolon-separated list
+of routes, each made of an optional HTTP method and a path pattern (see
+`SamplingRule.Pattern`) followed by `:` and the share of the successful responses
+logged, as a percentage (e.g. `1%`) or a fraction (e.g. `0.01`).
+
+For example, "GET /healthz: 1%; GET /articles/*: 10%". An error wrapping
+`ErrInvalidSampling` is returned if a rule is invalid.
+*/
+func ParseSampling(spec string) ([]SamplingRule, error) {
+	rules := []SamplingRule{}
+
+	for _, field := range strings.Split(spec, ";") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		rule, err := parseSamplingRule(field)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidSampling, field, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// parseSamplingRule parses a single rule of the log sampling, e.g. "GET /healthz: 1%".
+func parseSamplingRule(field string) (SamplingRule, error) {
+	route, value, ok := strings.Cut(field, ":")
+	if !ok {
+		return SamplingRule{}, errors.New("missing rate")
+	}
+
+	rule := SamplingRule{}
+
+	parts := strings.Fields(route)
+	switch len(parts) {
+	case 1:
+		rule.Pattern = parts[0]
+	case 2:
+		rule.Method, rule.Pattern = strings.ToUpper(parts[0]), parts[1]
+	default:
+		return SamplingRule{}, errors.New(
+			"the route must be a path, optionally after a method",
+		)
+	}
+	if rule.Pattern != "*" && !strings.HasPrefix(rule.Pattern, "/") {
+		return SamplingRule{}, fmt.Errorf(
+			"the path %q must start with a slash", rule.Pattern,
+		)
+	}
+
+	value = strings.TrimSpace(value)
+	percent, isPercent := strings.CutSuffix(value, "%")
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+	if isPercent {
+		rate /= 100
+	}
+	if err != nil || rate < 0 || rate > 1 {
+		return SamplingRule{}, fmt.Errorf("invalid rate %q", value)
+	}
+	rule.Rate = rate
+
+	return rule, nil
+}
+
+/*
+Sampler decides which requests are logged from its rules, so that the routes with a
+high volume of traffic (e.g. the health checks, or the reads served from the caches)
+do not drown the logs. It is safe for concurrent use.
+
+The responses with an error status (4xx and 5xx) are always logged, the successful
+ones are logged at the rate of the first rule matching their request, or always if no
+rule matches it. A nil Sampler logs every request.
+*/
+type Sampler struct {
+	rules []SamplingRule
+}
+
+// NewSampler creates and returns a new Sampler deciding from the given rules.
+func NewSampler(rules []SamplingRule) *Sampler {
+	return &Sampler{rules: rules}
+}
+
+/*
+Sample reports whether the request of the method to the path, answered with the status,
+is logged. The request is drawn once, when its statistics are held by its context (see
+`NewStatsContext`), so that all the logs of a request sample it alike; each call draws
+anew otherwise.
+*/
+func (s *Sampler) Sample(ctx context.Context, method, path string, status int) bool {
+	if s == nil || status >= 400 {
+		return true
+	}
+
+	for _, rule := range s.rules {
+		if rule.Matches(method, path) {
+			return rule.Rate >= 1 || draw(ctx) < rule.Rate
+		}
+	}
+
+	return true
+}
+
+// draw returns the number between 0 and 1 drawn for the request of the context.
+func draw(ctx context.Context) float64 {
+	if stats := StatsFromContext(ctx); stats != nil {
+		return stats.draw
+	}
+
+	return rand.Float64()
+}
@@ -0,0 +1,46 @@
+package logger_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+// TestSampleOnce checks that every log of a request samples it alike, and that its
+// errors are always logged.
+func TestSampleOnce(t *testing.T) {
+	rules, err := logger.ParseSampling("GET /articles: 50%")
+	if err != nil {
+		t.Fatalf("Unable to parse the sampling rules: %v", err)
+	}
+	sampler := logger.NewSampler(rules)
+
+	picked := 0
+	for range 1000 {
+		ctx, _ := logger.NewStatsContext(context.Background())
+
+		sample := func(status int) bool {
+			return sampler.Sample(ctx, http.MethodGet, "/articles", status)
+		}
+
+		sampled := sample(http.StatusOK)
+		for range 10 {
+			if sample(http.StatusOK) != sampled {
+				t.Fatal("Expected the request to be sampled alike by every log")
+			}
+		}
+		if sampled {
+			picked++
+		}
+
+		if !sample(http.StatusNotFound) {
+			t.Error("Expected the errors to be always logged")
+		}
+	}
+
+	if picked == 0 || picked == 1000 {
+		t.Errorf("Expected about half of the requests to be logged. Got %d\n", picked)
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// statsKey is the type of the key the statistics of a request are stored under in a
+// context.
+type statsKey struct{}
+
+/*
+Stats gathers the statistics of a request which are only known deep down the code
+serving it, to be logged along with the request once it is served (see
+`middleware.RequestLogger`), and the draw sampling the logs of the request (see
+`Sampler`). It is safe for concurrent use.
+*/
+type Stats struct {
+	mu        sync.Mutex
+	draw      float64
+	storeTime time.Duration
+	cache     string
+}
+
+// NewStatsContext returns a copy of the context holding empty statistics, along with
+// the statistics themselves. The request of the context is drawn for the sampling of
+// its logs.
+func NewStatsContext(ctx context.Context) (context.Context, *Stats) {
+	stats := &Stats{draw: rand.Float64()}
+	return context.WithValue(ctx, statsKey{}, stats), stats
+}
+
+// StatsFromContext returns the statistics held by the context, or nil if it holds none
+// (e.g. outside of a request).
+func StatsFromContext(ctx context.Context) *Stats {
+	stats, _ := ctx.Value(statsKey{}).(*Stats)
+	return stats
+}
+
+/*
+AddStoreTime adds the duration of a lookup of the store to the statistics of the
+request of the context, if any.
+
+The lookups report their own duration (see the `repository` package).
+*/
+func AddStoreTime(ctx context.Context, d time.Duration) {
+	if stats := StatsFromContext(ctx); stats != nil {
+		stats.mu.Lock()
+		defer stats.mu.Unlock()
+
+		stats.storeTime += d
+	}
+}
+
+// SetCacheStatus records whether the response to the request of the context was
+// served from a cache, as "hit" or "miss", in its statistics, if any.
+func SetCacheStatus(ctx context.Context, status string) {
+	if stats := StatsFromContext(ctx); stats != nil {
+		stats.mu.Lock()
+		defer stats.mu.Unlock()
+
+		stats.cache = status
+	}
+}
+
+// StoreTime returns the time spent in the lookups of the store.
+func (s *Stats) StoreTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.storeTime
+}
+
+// CacheStatus returns whether the response was served from a cache, as "hit" or
+// "miss", or an empty string if no cache was involved.
+func (s *Stats) CacheStatus() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Weburz/burzcontent/server/internal/tracing"
+)
+
+/*
+NewTraceHandler wraps the handler so that every record logged with a context holding a
+span context (see the `tracing` package) carries its trace and span IDs, as the
+`trace_id` and `span_id` attributes, so that the logs can be joined with the traces.
+
+The records are only correlated when logged with their context, e.g. with
+`logger.FromContext(ctx).ErrorContext(ctx, ...)`.
+*/
+func NewTraceHandler(h slog.Handler) slog.Handler {
+	return &traceHandler{Handler: h}
+}
+
+// traceHandler attaches the trace and span IDs of the context to the records.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc, ok := tracing.FromContext(ctx); ok {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID),
+			slog.String("span_id", sc.SpanID),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
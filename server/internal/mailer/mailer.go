@@ -0,0 +1,82 @@
+/*
+Package mailer sends the emails of the server, such as password resets, invitations
+and comment notifications.
+
+The emails are sent through the `Mailer` interface, which is implemented by:
+  - `SMTPMailer`, which sends the emails through an SMTP server.
+  - `LogMailer`, which only logs the emails, for the deployments (and development
+    environments) without any SMTP server.
+
+Sending an email can take a while, hence the emails are usually sent in the
+background through a `Queue`, as tasks of the task queue of the server (see the
+`queue` package). The body of the emails is rendered from the HTML and
+text templates of the package (see `Render`), which the sites may override with their
+own templates (see `RenderWith`).
+
+Example:
+
+	emails := mailer.NewQueue(tasks, mailer.NewSMTPMailer(cfg))
+
+	msg, err := mailer.Render("invite", []string{"jane@example.com"}, data)
+	if err != nil {
+		return err
+	}
+
+	return emails.Enqueue(ctx, msg)
+*/
+package mailer
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+/*
+Message represents an email.
+
+Fields:
+  - To: The addresses of the recipients of the email.
+  - ReplyTo: The address the replies to the email are sent to, if not the sender.
+  - Subject: The subject of the email.
+  - Text: The plain text body of the email.
+  - HTML: The HTML body of the email, sent as an alternative to the plain text one
+    if not empty.
+*/
+type Message struct {
+	To      []string
+	ReplyTo string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	// Send sends the email, returning once it is accepted by the mail server.
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogMailer is a Mailer which logs the emails instead of sending them, with its
+// logger (or the logger held by the context of the email if nil, see
+// `logger.FromContext`).
+type LogMailer struct {
+	Logger *slog.Logger
+}
+
+// Send logs the recipients and the subject of the email.
+func (m LogMailer) Send(ctx context.Context, msg Message) error {
+	log := m.Logger
+	if log == nil {
+		log = logger.FromContext(ctx)
+	}
+
+	log.InfoContext(
+		ctx, "Email not sent, SMTP is not configured",
+		"to", msg.To,
+		"subject", msg.Subject,
+	)
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/queue"
+)
+
+const (
+	// TaskKind is the kind of the tasks sending the emails.
+	TaskKind = "email.send"
+
+	// sendTimeout is the time an attempt to send an email can take.
+	sendTimeout = 30 * time.Second
+)
+
+/*
+Queue sends emails in the background, as tasks of a task queue.
+
+The sending of an email is retried by the task queue, with an exponential backoff
+between the attempts, before the email is moved to its dead-letter list.
+*/
+type Queue struct {
+	tasks *queue.Queue
+}
+
+// NewQueue creates a new Queue sending the emails with the mailer, as tasks of the
+// given task queue.
+func NewQueue(tasks *queue.Queue, mailer Mailer) *Queue {
+	tasks.Handle(TaskKind, func(ctx context.Context, task queue.Task) error {
+		var msg Message
+		if err := json.Unmarshal(task.Payload, &msg); err != nil {
+			return fmt.Errorf("malformed email: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+
+		return mailer.Send(ctx, msg)
+	})
+
+	return &Queue{tasks: tasks}
+}
+
+// Enqueue queues the email to be sent, returning `queue.ErrQueueFull` (wrapped) if
+// the task queue is full.
+func (q *Queue) Enqueue(ctx context.Context, msg Message) error {
+	return q.tasks.Enqueue(ctx, TaskKind, msg)
+}
+
+// Send queues the email to be sent, which makes the queue itself a Mailer.
+func (q *Queue) Send(ctx context.Context, msg Message) error {
+	return q.Enqueue(ctx, msg)
+}
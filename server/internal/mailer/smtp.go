@@ -0,0 +1,206 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+/*
+SMTPConfig holds the settings of an SMTP server.
+
+Fields:
+  - Host: The hostname of the SMTP server.
+  - Port: The port of the SMTP server, on which the connection is upgraded with
+    STARTTLS if the server supports it (or is encrypted from the start on port 465).
+  - Username: The username to authenticate with (no authentication if empty).
+  - Password: The password to authenticate with.
+  - From: The address the emails are sent from, e.g. `BurzContent
+    <no-reply@example.com>`.
+*/
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer is a Mailer which sends the emails through an SMTP server.
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewSMTPMailer creates and returns a new instance of SMTPMailer sending the emails
+// through the SMTP server of the given settings.
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.From, err)
+	}
+
+	return &SMTPMailer{cfg: cfg, from: from}, nil
+}
+
+// Send sends the email through the SMTP server.
+func (sm *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("unable to send email: no recipient")
+	}
+
+	body, err := sm.compose(msg)
+	if err != nil {
+		return fmt.Errorf("unable to compose email: %w", err)
+	}
+
+	client, err := sm.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to connect to SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.Mail(sm.from.Address); err != nil {
+		return fmt.Errorf("unable to send email: %w", err)
+	}
+
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("unable to send email to %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("unable to send email: %w", err)
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("unable to send email: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("unable to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, upgrading the connection with STARTTLS and
+// authenticating as configured.
+func (sm *SMTPMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(sm.cfg.Host, sm.cfg.Port)
+	tlsConfig := &tls.Config{ServerName: sm.cfg.Host}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The connection is encrypted from the start on the SMTPS port
+	if sm.cfg.Port == "465" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, sm.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok && sm.cfg.Port != "465" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if sm.cfg.Username != "" {
+		auth := smtp.PlainAuth("", sm.cfg.Username, sm.cfg.Password, sm.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// compose returns the MIME encoding of the email, whose body is either plain text or
+// a `multipart/alternative` of plain text and HTML.
+func (sm *SMTPMailer) compose(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", sm.from.String())
+	header("To", strings.Join(msg.To, ", "))
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", rand.Text(), sm.cfg.Host))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	boundary := rand.Text()
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		header("Content-Type", part.contentType+`; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable writes the quoted-printable encoding of the body.
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,150 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// templatesFS holds the templates of the emails.
+//
+// Each email has a text template (`<name>.txt`), which also defines the `subject`
+// template, and an HTML template (`<name>.html`) defining the `content` template,
+// which is rendered within the common `layout.html` (whose `subject` function returns
+// the rendered subject).
+//
+//go:embed templates
+var templatesFS embed.FS
+
+// defaultTemplates holds the default templates of the emails, at the root of the file
+// system.
+var defaultTemplates, _ = fs.Sub(templatesFS, "templates")
+
+/*
+Render renders the email of the named template (e.g. "invite") for the recipients.
+
+The data is passed to the templates as is. Every template expects a `SiteName` field
+along with the fields of its own:
+  - invite: `Name`, `Role`, `URL` and `ExpiresAt` (time.Time).
+  - password_reset: `Name`, `URL` and `ExpiresAt` (time.Time).
+  - comment_notification: `Name`, `ArticleTitle`, `CommentAuthor`, `CommentContent`
+    and `URL`.
+  - contact: `Name`, `Email` and `Message`.
+  - review_notification: `Name`, `ArticleTitle`, `Approved` (bool) and `Comment`.
+  - comment_digest: `Name`, `Count` (int) and `Articles`, each with a `Title`, a `URL`
+    and `Comments`, each with an `Author` and a `Content`.
+  - webhook_disabled: `URL`, `Failures` (int), `FailingSince` (time.Time) and
+    `LastError`.
+*/
+func Render(name string, to []string, data any) (Message, error) {
+	return RenderWith(nil, name, to, data)
+}
+
+/*
+RenderWith renders the email of the named template like `Render` does, with the
+templates of overrides (e.g. the template bundle of a site) taking precedence over the
+default templates of the same name. The default templates are used if overrides is
+nil.
+*/
+func RenderWith(overrides fs.FS, name string, to []string, data any) (Message, error) {
+	templates := defaultTemplates
+	if overrides != nil {
+		templates = overlay{top: overrides, base: defaultTemplates}
+	}
+
+	text, err := texttemplate.ParseFS(templates, name+".txt")
+	if err != nil {
+		return Message{}, fmt.Errorf("unable to parse template %q: %w", name, err)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("unable to render template %q: %w", name, err)
+	}
+
+	// The title of the HTML body is the subject, rendered by the text template
+	html, err := htmltemplate.New(name).
+		Funcs(htmltemplate.FuncMap{
+			"subject": func() string { return subject.String() },
+		}).
+		ParseFS(templates, "layout.html", name+".html")
+	if err != nil {
+		return Message{}, fmt.Errorf("unable to parse template %q: %w", name, err)
+	}
+
+	if err := text.Execute(&textBody, data); err != nil {
+		return Message{}, fmt.Errorf("unable to render template %q: %w", name, err)
+	}
+
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return Message{}, fmt.Errorf("unable to render template %q: %w", name, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    textBody.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
+
+/*
+ValidateTemplates checks that every file of overrides overrides a default template (e.g.
+"invite.html" or "layout.html") and is a valid template, i.e. that the templates can
+be used with `RenderWith`.
+*/
+func ValidateTemplates(overrides fs.FS) error {
+	return fs.WalkDir(overrides, ".", func(
+		file string,
+		d fs.DirEntry,
+		err error,
+	) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if _, err := fs.Stat(defaultTemplates, file); err != nil {
+			return fmt.Errorf("unknown template %q", file)
+		}
+
+		content, err := fs.ReadFile(overrides, file)
+		if err != nil {
+			return fmt.Errorf("unable to read template %q: %w", file, err)
+		}
+
+		if path.Ext(file) == ".txt" {
+			_, err = texttemplate.New(file).Parse(string(content))
+		} else {
+			_, err = htmltemplate.New(file).
+				Funcs(htmltemplate.FuncMap{"subject": func() string { return "" }}).
+				Parse(string(content))
+		}
+		if err != nil {
+			return fmt.Errorf("unable to parse template %q: %w", file, err)
+		}
+
+		return nil
+	})
+}
+
+// overlay is a file system whose files are read from top, or from base if they do not
+// exist in top.
+type overlay struct {
+	top, base fs.FS
+}
+
+// Open opens the named file of top, falling back to the one of base.
+func (o overlay) Open(name string) (fs.File, error) {
+	file, err := o.top.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.base.Open(name)
+	}
+
+	return file, err
+}
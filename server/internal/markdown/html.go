@@ -0,0 +1,152 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fence is the line opening and closing a code block.
+const fence = "```"
+
+var (
+	// codeSpanPattern matches the code spans of a line, e.g. "`go test`".
+	codeSpanPattern = regexp.MustCompile("`([^`]+)`")
+
+	// linkPattern matches the links of a line, e.g. "[Weburz](https://weburz.com)".
+	linkPattern = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+
+	// strongPattern matches the strongly emphasized text of a line, e.g. "**bold**"
+	// or "__bold__".
+	strongPattern = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+
+	// emPattern matches the emphasized text of a line, e.g. "*italic*" or "_italic_".
+	// The underscores have to be at the boundaries of the words, so that the
+	// identifiers such as "snake_case_name" are left alone.
+	emPattern = regexp.MustCompile(
+		`\*(\S(?:.*?\S)?)\*|(^|[^\p{L}\p{N}_])_(\S(?:.*?\S)?)_([^\p{L}\p{N}_]|$)`,
+	)
+
+	// placeholderPattern matches the placeholders of the code spans and the URLs of a
+	// line, which are restored once the emphasis is rendered.
+	placeholderPattern = regexp.MustCompile("\x00([0-9]+)\x00")
+)
+
+/*
+ToHTML renders a safe subset of Markdown, meant for short texts such as the comments,
+to HTML:
+  - Paragraphs, separated by blank lines, whose line breaks are kept.
+  - Strong emphasis (`**bold**` or `__bold__`) and emphasis (`*italic*` or
+    `_italic_`).
+  - Links (`[text](https://example.com)`).
+  - Code spans (between backticks) and code blocks (between lines of three
+    backticks).
+  - Blockquotes (lines starting with `>`), which may be nested.
+
+The HTML the text holds is passed through as is, and so are the URLs of the links: the
+rendered HTML has to be sanitized before being served (see the `sanitize` package).
+*/
+func ToHTML(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	return renderBlocks(strings.Split(s, "\n"))
+}
+
+// renderBlocks renders the lines as a sequence of blocks (paragraphs, code blocks and
+// blockquotes), each on its own line.
+func renderBlocks(lines []string) string {
+	var blocks []string
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, "<p>"+renderInline(paragraph)+"</p>")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, fence):
+			flush()
+
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+
+			blocks = append(blocks, fmt.Sprintf(
+				"<pre><code>%s</code></pre>",
+				html.EscapeString(strings.Join(code, "\n")),
+			))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+
+			var quote []string
+			for ; i < len(lines); i++ {
+				trimmed := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(trimmed, ">") {
+					break
+				}
+				trimmed = strings.TrimPrefix(trimmed, ">")
+				quote = append(quote, strings.TrimPrefix(trimmed, " "))
+			}
+			i--
+
+			blocks = append(blocks, "<blockquote>\n"+renderBlocks(quote)+
+				"\n</blockquote>")
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return strings.Join(blocks, "\n")
+}
+
+// renderInline renders the lines of a paragraph, separated by line breaks, along with
+// their code spans, links and emphasis.
+func renderInline(lines []string) string {
+	// The code spans and the URLs are set aside, so that their content is not taken
+	// for emphasis
+	var aside []string
+	setAside := func(s string) string {
+		aside = append(aside, s)
+		return "\x00" + strconv.Itoa(len(aside)-1) + "\x00"
+	}
+
+	s := strings.Join(lines, "<br>\n")
+	s = codeSpanPattern.ReplaceAllStringFunc(s, func(m string) string {
+		code := codeSpanPattern.FindStringSubmatch(m)[1]
+		return setAside("<code>" + html.EscapeString(code) + "</code>")
+	})
+	s = linkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		match := linkPattern.FindStringSubmatch(m)
+		href := html.EscapeString(html.UnescapeString(match[2]))
+		return `<a href="` + setAside(href) + `">` + match[1] + "</a>"
+	})
+
+	s = strongPattern.ReplaceAllString(s, "<strong>$1$2</strong>")
+	// The boundaries of the words are part of the matches, which is why the adjacent
+	// emphasis (e.g. "_a_ _b_") takes another pass
+	for previous := ""; previous != s; {
+		previous = s
+		s = emPattern.ReplaceAllString(s, "<em>$1</em>$2<em>$3</em>$4")
+		s = strings.ReplaceAll(s, "<em></em>", "")
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(placeholderPattern.FindStringSubmatch(m)[1])
+		return aside[i]
+	})
+}
@@ -0,0 +1,185 @@
+/*
+Package markdown reads the Markdown documents of a content directory, such as the ones
+of static site generators (Hugo, Jekyll, Eleventy, etc.).
+
+A document may start with a YAML front matter, delimited by `---` lines, holding its
+metadata:
+
+	---
+	title: Hello World
+	date: 2024-01-02
+	tags: [go, news]
+	slug: hello-world
+	draft: false
+	---
+
+	The content of the document.
+
+Every field of the front matter is optional, the unknown ones are ignored.
+
+The package also renders a safe subset of Markdown to HTML (see `ToHTML`), for the
+short texts written by the readers such as the comments.
+*/
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter is the line opening and closing the front matter of a document.
+const delimiter = "---"
+
+// FrontMatter is the metadata of a document.
+type FrontMatter struct {
+	Title string    `yaml:"title"`
+	Date  time.Time `yaml:"date"`
+	Tags  []string  `yaml:"tags"`
+	Slug  string    `yaml:"slug"`
+	Draft bool      `yaml:"draft"`
+}
+
+// Document is a Markdown document of a content directory.
+type Document struct {
+	Path string // The path of the document, relative to the content directory
+	FrontMatter
+	Body string // The Markdown content of the document, without its front matter
+}
+
+/*
+Parse parses a Markdown document, splitting its front matter (if any) from its body.
+
+The slug of the document defaults to the slug of its file name (e.g. `hello-world`
+for `Hello World.md`) and its title to its file name, when the front matter does not
+set them. Slugs are always lowercase.
+*/
+func Parse(path string, data []byte) (Document, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	doc := Document{Path: path, Body: string(data)}
+
+	if rest, ok := cutDelimiter(data); ok {
+		// Collect the lines of the front matter up to the closing delimiter
+		var front []byte
+		for {
+			if len(rest) == 0 {
+				return Document{}, fmt.Errorf("%s: unterminated front matter", path)
+			}
+
+			if body, end := cutDelimiter(rest); end {
+				rest = body
+				break
+			}
+
+			line, next, _ := bytes.Cut(rest, []byte("\n"))
+			front = append(append(front, line...), '\n')
+			rest = next
+		}
+
+		if err := yaml.Unmarshal(front, &doc.FrontMatter); err != nil {
+			return Document{}, fmt.Errorf("%s: invalid front matter: %w", path, err)
+		}
+
+		doc.Body = string(bytes.TrimLeft(rest, "\r\n"))
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if doc.Title == "" {
+		doc.Title = name
+	}
+	if doc.Slug == "" {
+		doc.Slug = Slugify(name)
+	}
+	doc.Slug = strings.ToLower(doc.Slug)
+
+	return doc, nil
+}
+
+/*
+ReadDir reads every Markdown (`.md`) document of the directory and its
+subdirectories, in lexical order.
+
+The hidden files and directories (whose name starts with a dot) are skipped.
+*/
+func ReadDir(dir string) ([]Document, error) {
+	var docs []Document
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		doc, err := Parse(rel, data)
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", dir, err)
+	}
+
+	return docs, nil
+}
+
+// Slugify returns the URL-friendly form of s, made of lowercase letters, digits and
+// dashes (e.g. "hello-world" for "Hello, World!").
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	return b.String()
+}
+
+// cutDelimiter returns the data following the delimiter line it starts with, and
+// whether it starts with one.
+func cutDelimiter(data []byte) ([]byte, bool) {
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	if string(bytes.TrimRight(line, " \t\r")) != delimiter {
+		return data, false
+	}
+
+	return rest, true
+}
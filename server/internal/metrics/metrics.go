@@ -0,0 +1,212 @@
+/*
+Package metrics provides a registry of the metrics of the server, exposed in the text
+format of Prometheus (see `Registry.ServeHTTP`) for the dashboards to scrape them.
+
+The registry holds counters (see `Counter`), which only go up and may be broken down
+by labels (e.g. the site an event belongs to), and gauges (see `Registry.GaugeFunc`),
+whose value is collected when the metrics are scraped (e.g. the number of pending
+tasks of the task queue).
+
+Example:
+
+	registry := metrics.NewRegistry()
+	published := registry.Counter(
+		"burzcontent_articles_published_total",
+		"The number of articles published.",
+		"site",
+	)
+	published.Inc(siteID.String())
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text format of Prometheus.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelEscaper escapes the values of the labels in the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes the descriptions of the metrics in the text format.
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// metric is a metric of the registry, which writes its samples in the text format.
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds the metrics of the server. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+// NewRegistry creates and returns a new Registry without any metric.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+/*
+Counter registers and returns a new counter of the given name and description, broken
+down by the given labels (none if the counter is a single value), or the counter
+already registered under the name.
+
+The name has to follow the conventions of Prometheus, i.e. be made of letters, digits
+and underscores and end with `_total` (e.g. "burzcontent_articles_published_total").
+*/
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.metrics[name].(*Counter); ok {
+		return c
+	}
+
+	c := &Counter{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]float64),
+	}
+	r.metrics[name] = c
+
+	return c
+}
+
+// GaugeFunc registers a gauge of the given name and description, whose value is
+// collected with the function each time the metrics are scraped.
+func (r *Registry) GaugeFunc(name, help string, collect func() float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics[name] = gaugeFunc{name: name, help: help, collect: collect}
+}
+
+// ServeHTTP serves the current value of every metric of the registry in the text
+// format of Prometheus, sorted by name.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	metrics := make([]metric, len(names))
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(http.StatusOK)
+
+	b := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(b)
+	}
+	_ = b.Flush()
+}
+
+// Counter is a counter of the registry, broken down by its labels.
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64 // The values of the counter, by series (see `series`)
+}
+
+// Inc increments the counter of the series of the given label values (in the order
+// of the labels of the counter) by 1.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add increments the counter of the series of the given label values (in the order of
+// the labels of the counter) by delta, which is ignored if it is negative.
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+
+	key := c.series(values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] += delta
+}
+
+// series returns the labels of the series of the given label values, in the text
+// format (e.g. `{site="default"}`), the missing values being empty.
+func (c *Counter) series(values []string) string {
+	if len(c.labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(c.labels))
+	for i, label := range c.labels {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = label + `="` + labelEscaper.Replace(value) + `"`
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// write writes the samples of the counter, sorted by series.
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	series := make([]string, 0, len(c.values))
+	for key := range c.values {
+		series = append(series, key)
+	}
+	slices.Sort(series)
+
+	values := make([]float64, len(series))
+	for i, key := range series {
+		values[i] = c.values[key]
+	}
+	c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	for i, key := range series {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, key, formatValue(values[i]))
+	}
+}
+
+// gaugeFunc is a gauge of the registry, whose value is collected when it is scraped.
+type gaugeFunc struct {
+	name    string
+	help    string
+	collect func() float64
+}
+
+// write writes the current value of the gauge.
+func (g gaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.collect()))
+}
+
+// writeHeader writes the description and the type of a metric.
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// formatValue formats the value of a sample in the text format.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,337 @@
+/*
+Package naming converts the names of the fields of the JSON documents of the APIs to
+the naming convention a client asked for.
+
+The models name their fields as they were declared (mostly in snake_case, e.g.
+"created_at", and in camelCase for a few, e.g. "isPublished"); a `Convention` renames
+every field of a response consistently, e.g. "createdAt" and "isPublished" in camelCase
+or "created_at" and "is_published" in snake_case (see `Rewrite`), while the fields of a
+request body are matched to the fields of its model whichever convention they follow
+(see `Match`).
+*/
+package naming
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Convention is a naming convention of the fields of the JSON documents.
+type Convention string
+
+const (
+	// Default names the fields as the models declare them.
+	Default Convention = ""
+
+	// CamelCase names the fields in camelCase, e.g. "createdAt".
+	CamelCase Convention = "camelCase"
+
+	// SnakeCase names the fields in snake_case, e.g. "created_at".
+	SnakeCase Convention = "snake_case"
+)
+
+// ErrUnknownConvention is returned when parsing the name of an unknown convention.
+var ErrUnknownConvention = errors.New("unknown naming convention")
+
+/*
+Parse returns the convention of the given name, i.e. "camelCase" or "snake_case"
+(whatever their case, e.g. "camelcase"), or `Default` for an empty name or "default".
+
+Example:
+
+	convention, err := naming.Parse(os.Getenv("JSON_NAMING"))
+*/
+func Parse(name string) (Convention, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "default":
+		return Default, nil
+	case "camelcase", "camel":
+		return CamelCase, nil
+	case "snake_case", "snake":
+		return SnakeCase, nil
+	}
+
+	return Default, fmt.Errorf("%w: %q", ErrUnknownConvention, name)
+}
+
+// Convert returns the name of a field in the convention, e.g. "siteId" for "site_id"
+// in camelCase, or the name itself in the default convention.
+func (c Convention) Convert(name string) string {
+	switch c {
+	case CamelCase:
+		var b strings.Builder
+		upper := false
+		for i, r := range name {
+			switch {
+			case r == '_' && i > 0:
+				upper = true
+			case upper:
+				b.WriteRune(unicode.ToUpper(r))
+				upper = false
+			default:
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	case SnakeCase:
+		var b strings.Builder
+		prev := '_'
+		for _, r := range name {
+			// Split the words before an upper case letter, but not the acronyms
+			if unicode.IsUpper(r) {
+				if prev != '_' && !unicode.IsUpper(prev) {
+					b.WriteByte('_')
+				}
+				b.WriteRune(unicode.ToLower(r))
+			} else {
+				b.WriteRune(r)
+			}
+			prev = r
+		}
+		return b.String()
+	}
+
+	return name
+}
+
+// contextKey is the unexported type of the context key holding the convention.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the convention the client
+// asked for.
+func NewContext(parent context.Context, convention Convention) context.Context {
+	return context.WithValue(parent, contextKey{}, convention)
+}
+
+// FromContext returns the convention held by the context, or `Default` if none is.
+func FromContext(ctx context.Context) Convention {
+	convention, _ := ctx.Value(contextKey{}).(Convention)
+	return convention
+}
+
+/*
+Rewrite renames the fields of the JSON document in the convention, keeping their order
+and their values.
+
+The values of the fields named by verbatim which are objects (e.g. the payloads of the
+events, or the maps indexed by date) are kept as is, their keys being data rather than
+the names of fields.
+*/
+func Rewrite(data []byte, c Convention, verbatim []string) ([]byte, error) {
+	// frame is the state of an object or an array being rewritten
+	type frame struct {
+		object   bool // Whether the frame is an object rather than an array
+		wantKey  bool // Whether the next token of the object is a key
+		empty    bool // Whether no member was written yet
+		verbatim bool // Whether the keys of the frame are kept as is
+		keep     bool // Whether the value of the current key is kept as is
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	var stack []frame
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		var top *frame
+		if n := len(stack); n > 0 {
+			top = &stack[n-1]
+		}
+
+		// Close the object or the array, the next token of its parent being a key
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			if n := len(stack); n > 0 && stack[n-1].object {
+				stack[n-1].wantKey = true
+			}
+			continue
+		}
+
+		if top != nil {
+			if !top.empty && (!top.object || top.wantKey) {
+				out.WriteByte(',')
+			}
+			top.empty = false
+		}
+
+		// Rename the key, unless its object is kept as is
+		if top != nil && top.object && top.wantKey {
+			key, _ := token.(string)
+			if !top.verbatim {
+				key = c.Convert(key)
+			}
+			if err := writeJSON(&out, key); err != nil {
+				return nil, err
+			}
+			out.WriteByte(':')
+
+			top.wantKey = false
+			top.keep = top.verbatim || slices.Contains(verbatim, token.(string))
+			continue
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			out.WriteByte(byte(delim))
+			stack = append(stack, frame{
+				object:  delim == '{',
+				wantKey: delim == '{',
+				empty:   true,
+				// Only the objects of the fields named by verbatim are kept as is
+				// (along with what they hold), not their arrays, e.g. of flags
+				verbatim: top != nil && (top.verbatim || delim == '{' && top.keep),
+			})
+			continue
+		}
+
+		if err := writeJSON(&out, token); err != nil {
+			return nil, err
+		}
+		if top != nil && top.object {
+			top.wantKey = true
+		}
+	}
+
+	if bytes.HasSuffix(data, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), nil
+}
+
+// writeJSON writes the JSON encoding of a scalar token of a JSON document.
+func writeJSON(out *bytes.Buffer, token json.Token) error {
+	if number, ok := token.(json.Number); ok {
+		out.WriteString(number.String())
+		return nil
+	}
+
+	encoded, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	out.Write(encoded)
+
+	return nil
+}
+
+/*
+Match renames the fields of a decoded JSON value (i.e. of its maps) to the names of the
+fields of the type it is decoded into, whichever convention they follow, so that e.g.
+"siteId", "SiteID" and "site_id" all name the `json:"site_id"` field of a struct.
+
+The fields which do not name any field of the type are kept, for the decoder to report
+them, as are the keys of the maps of the type.
+*/
+func Match(value any, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+
+		fields := fieldsOf(t)
+		matched := make(map[string]any, len(object))
+		for key, v := range object {
+			if f, ok := fields[normalize(key)]; ok {
+				matched[f.name] = Match(v, f.typ)
+			} else {
+				matched[key] = v
+			}
+		}
+		return matched
+	case reflect.Slice, reflect.Array:
+		if values, ok := value.([]any); ok {
+			for i, v := range values {
+				values[i] = Match(v, t.Elem())
+			}
+		}
+	case reflect.Map:
+		if object, ok := value.(map[string]any); ok {
+			for key, v := range object {
+				object[key] = Match(v, t.Elem())
+			}
+		}
+	}
+
+	return value
+}
+
+// field is a field of a struct, as named in its JSON documents.
+type field struct {
+	name string
+	typ  reflect.Type
+}
+
+// fieldCache caches the fields of the structs, by type.
+var fieldCache sync.Map
+
+// fieldsOf returns the fields of the struct type (including the fields of its embedded
+// structs) by their normalized name.
+func fieldsOf(t reflect.Type) map[string]field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string]field)
+	}
+
+	found := make(map[string]field)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		// Inline the fields of the embedded structs, like the decoder does
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for key, inner := range fieldsOf(ft) {
+				if _, ok := found[key]; !ok {
+					found[key] = inner
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		found[normalize(name)] = field{name: name, typ: f.Type}
+	}
+
+	fieldCache.Store(t, found)
+
+	return found
+}
+
+// normalize returns the name of a field without its case and its underscores, which
+// is the same in every convention.
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
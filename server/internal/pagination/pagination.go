@@ -0,0 +1,170 @@
+/*
+Package pagination provides the primitives used to page and sort the listings of the
+API.
+
+Listings follow the JSON:API conventions: the page is selected with the
+`page[number]` (starting at 1) and `page[size]` query parameters, and the order with
+the `sort` query parameter holding a field name, optionally prefixed by `-` to sort in
+descending order (e.g. `?sort=-name&page[number]=2&page[size]=50`). The responses carry
+a `Meta` object describing the page served, along with a `Link` header (RFC 8288)
+pointing to the neighbouring pages so that generic HTTP clients can page through the
+listings without parsing the responses (see `Meta.Links`).
+*/
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSize is the number of items served per page when none is requested.
+	DefaultSize = 25
+
+	// MaxSize is the largest number of items which can be served per page.
+	MaxSize = 100
+)
+
+// ErrInvalidQuery is returned when the paging or sorting parameters are invalid.
+var ErrInvalidQuery = errors.New("invalid paging or sorting parameters")
+
+// Page selects a page of a listing.
+type Page struct {
+	Number int // The number of the page, starting at 1
+	Size   int // The number of items per page
+}
+
+/*
+ParsePage returns the page selected by the `page[number]` and `page[size]` query
+parameters, defaulting to the first page of `DefaultSize` items.
+
+`ErrInvalidQuery` is returned if a parameter is not a positive integer or if the size
+exceeds `MaxSize`.
+*/
+func ParsePage(query url.Values) (Page, error) {
+	page := Page{Number: 1, Size: DefaultSize}
+
+	for param, value := range map[string]*int{
+		"page[number]": &page.Number,
+		"page[size]":   &page.Size,
+	} {
+		if !query.Has(param) {
+			continue
+		}
+
+		n, err := strconv.Atoi(query.Get(param))
+		if err != nil || n < 1 {
+			return Page{}, ErrInvalidQuery
+		}
+
+		*value = n
+	}
+
+	if page.Size > MaxSize {
+		return Page{}, ErrInvalidQuery
+	}
+
+	return page, nil
+}
+
+// Offset returns the number of items preceding the page, saturated at `math.MaxInt`
+// for the pages too far off for it to be represented.
+func (p Page) Offset() int {
+	if p.Number <= 1 || p.Size <= 0 {
+		return 0
+	}
+	if p.Number-1 > math.MaxInt/p.Size {
+		return math.MaxInt
+	}
+
+	return (p.Number - 1) * p.Size
+}
+
+// Sort orders a listing by a single field.
+type Sort struct {
+	Field string // The name of the field, the natural order of the listing if empty
+	Desc  bool   // Whether the listing is sorted in descending order
+}
+
+/*
+ParseSort returns the order selected by the `sort` query parameter, e.g. `name` or
+`-name`, defaulting to the natural order of the listing.
+
+`ErrInvalidQuery` is returned if the field is not one of the allowed fields.
+*/
+func ParseSort(query url.Values, allowed ...string) (Sort, error) {
+	value := query.Get("sort")
+	if value == "" {
+		return Sort{}, nil
+	}
+
+	field, desc := strings.CutPrefix(value, "-")
+	if !slices.Contains(allowed, field) {
+		return Sort{}, ErrInvalidQuery
+	}
+
+	return Sort{Field: field, Desc: desc}, nil
+}
+
+// Slice returns the items of the page, out of every item of the listing, none if the
+// page is past the last one.
+func Slice[T any](items []T, page Page) []T {
+	start := min(page.Offset(), len(items))
+	end := start + min(max(page.Size, 0), len(items)-start)
+
+	return items[start:end]
+}
+
+// Meta describes the page of a listing served by a response.
+type Meta struct {
+	Total int `json:"total"` // The number of items of the whole listing
+	Page  int `json:"page"`  // The number of the page served
+	Size  int `json:"size"`  // The number of items per page
+	Pages int `json:"pages"` // The number of pages of the listing
+}
+
+// NewMeta returns the description of the page of a listing holding total items.
+func NewMeta(page Page, total int) Meta {
+	return Meta{
+		Total: total,
+		Page:  page.Number,
+		Size:  page.Size,
+		Pages: (total + page.Size - 1) / page.Size,
+	}
+}
+
+/*
+Links returns the value of the `Link` header (RFC 8288) pointing to the first, previous,
+next and last pages of the listing, e.g. `</users?page%5Bnumber%5D=1>; rel="first"`.
+The URLs of the pages are the URL of the page served (relative to the host) with its
+`page[number]` query parameter replaced, so that the other parameters are kept.
+
+The previous and next pages are left out on the first and last pages respectively.
+*/
+func (m Meta) Links(u *url.URL) string {
+	last := max(m.Pages, 1)
+
+	link := func(number int, rel string) string {
+		query := u.Query()
+		query.Set("page[number]", strconv.Itoa(number))
+
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if m.Page > 1 {
+		links = append(links, link(min(m.Page-1, last), "prev"))
+	}
+	if m.Page < last {
+		links = append(links, link(m.Page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+
+	return strings.Join(links, ", ")
+}
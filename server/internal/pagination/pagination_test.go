@@ -0,0 +1,96 @@
+package pagination_test
+
+import (
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+)
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query string
+		want  pagination.Page
+		err   bool
+	}{
+		{"", pagination.Page{Number: 1, Size: pagination.DefaultSize}, false},
+		{"page[number]=3&page[size]=10", pagination.Page{Number: 3, Size: 10}, false},
+		{"page[number]=0", pagination.Page{}, true},
+		{"page[size]=-1", pagination.Page{}, true},
+		{"page[size]=101", pagination.Page{}, true},
+		{"page[number]=two", pagination.Page{}, true},
+	}
+
+	for _, tt := range tests {
+		query, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("Unable to parse the query %q: %v", tt.query, err)
+		}
+
+		page, err := pagination.ParsePage(query)
+		if (err != nil) != tt.err || page != tt.want {
+			t.Errorf("ParsePage(%q) = %v, %v; want %v", tt.query, page, err, tt.want)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		page pagination.Page
+		want int
+	}{
+		{pagination.Page{Number: 1, Size: 25}, 0},
+		{pagination.Page{Number: 3, Size: 25}, 50},
+		{pagination.Page{Number: math.MaxInt, Size: 2}, math.MaxInt},
+		{pagination.Page{Number: math.MaxInt / 2, Size: 100}, math.MaxInt},
+		{pagination.Page{}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.page.Offset(); got != tt.want {
+			t.Errorf("%+v.Offset() = %d; want %d", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		page pagination.Page
+		want []int
+	}{
+		{pagination.Page{Number: 1, Size: 2}, []int{1, 2}},
+		{pagination.Page{Number: 3, Size: 2}, []int{5}},
+		{pagination.Page{Number: 4, Size: 2}, []int{}},
+		{pagination.Page{Number: 1, Size: math.MaxInt}, items},
+		{pagination.Page{Number: math.MaxInt, Size: 2}, []int{}},
+	}
+
+	for _, tt := range tests {
+		if got := pagination.Slice(items, tt.page); !slices.Equal(got, tt.want) {
+			t.Errorf("Slice(%v, %+v) = %v; want %v", items, tt.page, got, tt.want)
+		}
+	}
+}
+
+// TestSliceOverflow pages through the largest page numbers the query parameters
+// accept, which used to overflow the offset and panic.
+func TestSliceOverflow(t *testing.T) {
+	query := url.Values{
+		"page[number]": {strconv.Itoa(math.MaxInt)},
+		"page[size]":   {"2"},
+	}
+
+	page, err := pagination.ParsePage(query)
+	if err != nil {
+		t.Fatalf("Unable to parse the page: %v", err)
+	}
+
+	if got := pagination.Slice([]int{1, 2, 3}, page); len(got) != 0 {
+		t.Errorf("Slice() = %v; want no item", got)
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Package params carries the URL parameters of a request, once validated and parsed,
+through the request context.
+
+The UUID parameters (e.g. the `{id}` of `/articles/{id}`) are parsed once per request
+by the `middleware.UUIDParams` middleware, which rejects the malformed ones, and then
+read by the handlers through `UUID`.
+*/
+package params
+
+import (
+	"context"
+	"maps"
+
+	"github.com/google/uuid"
+)
+
+// contextKey is the unexported type of the context key holding the parsed parameters.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the UUID parsed from the
+// named parameter, along with the parameters the parent context already holds.
+func NewContext(parent context.Context, name string, id uuid.UUID) context.Context {
+	ids, _ := parent.Value(contextKey{}).(map[string]uuid.UUID)
+
+	ids = maps.Clone(ids)
+	if ids == nil {
+		ids = make(map[string]uuid.UUID, 1)
+	}
+	ids[name] = id
+
+	return context.WithValue(parent, contextKey{}, ids)
+}
+
+/*
+UUID returns the UUID parsed from the named parameter of the request.
+
+If the parameter was not parsed (i.e. the route does not run the `UUIDParams`
+middleware for it), `uuid.Nil` is returned, which never matches any stored resource.
+*/
+func UUID(ctx context.Context, name string) uuid.UUID {
+	ids, _ := ctx.Value(contextKey{}).(map[string]uuid.UUID)
+	return ids[name]
+}
@@ -0,0 +1,114 @@
+/*
+Package preview signs and verifies the short-lived tokens granting access to a draft
+article, which the headless frontends (e.g. the Draft Mode of Next.js or an Astro
+middleware) use to render the previews of the drafts.
+
+A token binds an article of a site to an expiry time, and is signed with HMAC-SHA256 so
+that it can be verified without being stored. It is made of the base64url encoding of
+its claims and of their signature, separated by a dot.
+*/
+package preview
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned when a token is malformed, is not signed with the key of
+// the signer or expired.
+var ErrInvalidToken = errors.New("invalid or expired preview token")
+
+/*
+Claims are the claims of a preview token.
+
+Fields:
+  - SiteID: The unique identifier of the site the article belongs to (UUID).
+  - ArticleID: The unique identifier of the previewed article (UUID).
+  - ExpiresAt: When the token expires.
+*/
+type Claims struct {
+	SiteID    uuid.UUID
+	ArticleID uuid.UUID
+	ExpiresAt time.Time
+}
+
+// Signer signs and verifies the preview tokens with a secret key.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates and returns a new Signer using the given secret key, or a random
+// key if it is empty (in which case the tokens do not survive a restart of the server).
+func NewSigner(key []byte) *Signer {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+
+	return &Signer{key: key}
+}
+
+// Sign returns the token holding the claims.
+func (s *Signer) Sign(claims Claims) string {
+	payload := strings.Join([]string{
+		claims.SiteID.String(),
+		claims.ArticleID.String(),
+		strconv.FormatInt(claims.ExpiresAt.Unix(), 10),
+	}, ":")
+
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + s.signature(encoded)
+}
+
+// Verify returns the claims of the token at the given time, or `ErrInvalidToken` if
+// the token is malformed, not signed with the key of the signer or expired.
+func (s *Signer) Verify(token string, now time.Time) (Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signature(encoded))) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	siteID, siteErr := uuid.Parse(fields[0])
+	articleID, articleErr := uuid.Parse(fields[1])
+	expiresAt, expiryErr := strconv.ParseInt(fields[2], 10, 64)
+	if siteErr != nil || articleErr != nil || expiryErr != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	claims := Claims{
+		SiteID:    siteID,
+		ArticleID: articleID,
+		ExpiresAt: time.Unix(expiresAt, 0).UTC(),
+	}
+	if !now.Before(claims.ExpiresAt) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// signature returns the base64url encoding of the HMAC-SHA256 of the encoded claims.
+func (s *Signer) signature(encoded string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encoded))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
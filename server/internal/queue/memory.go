@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// maxDead is the number of tasks kept in the dead-letter list of a MemoryBackend.
+const maxDead = 1000
+
+// MemoryBackend is a Backend keeping the tasks in the memory of the process. It is
+// safe for concurrent use.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	size    int
+	pending []Task
+	dead    []Task
+	ready   chan struct{} // Signalled when a task is pushed
+}
+
+// NewMemoryBackend creates and returns a new MemoryBackend holding at most size
+// pending tasks, and the last 1000 tasks of the dead-letter list.
+func NewMemoryBackend(size int) *MemoryBackend {
+	return &MemoryBackend{size: max(size, 1), ready: make(chan struct{}, 1)}
+}
+
+// Push adds a task to the pending tasks, returning `ErrQueueFull` if there are
+// already as many as the backend can hold.
+func (mb *MemoryBackend) Push(_ context.Context, task Task) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if len(mb.pending) >= mb.size {
+		return ErrQueueFull
+	}
+	mb.pending = append(mb.pending, task)
+	mb.signal()
+
+	return nil
+}
+
+// Pop removes the oldest pending task and returns it, waiting for one if there is
+// none, until the context is done.
+func (mb *MemoryBackend) Pop(ctx context.Context) (Task, error) {
+	for {
+		mb.mu.Lock()
+		if len(mb.pending) > 0 {
+			task := mb.pending[0]
+			mb.pending = slices.Delete(mb.pending, 0, 1)
+
+			// Wake up another worker if there are more tasks
+			if len(mb.pending) > 0 {
+				mb.signal()
+			}
+			mb.mu.Unlock()
+
+			return task, nil
+		}
+		mb.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Task{}, ctx.Err()
+		case <-mb.ready:
+		}
+	}
+}
+
+// Pending returns the number of pending tasks.
+func (mb *MemoryBackend) Pending(_ context.Context) (int, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return len(mb.pending), nil
+}
+
+// Bury adds a task to the dead-letter list, dropping the oldest task of the list if
+// it is full.
+func (mb *MemoryBackend) Bury(_ context.Context, task Task) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.dead = append(mb.dead, task)
+	if len(mb.dead) > maxDead {
+		mb.dead = slices.Delete(mb.dead, 0, len(mb.dead)-maxDead)
+	}
+
+	return nil
+}
+
+// Dead returns the tasks of the dead-letter list, most recent first.
+func (mb *MemoryBackend) Dead(_ context.Context) ([]Task, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	tasks := slices.Clone(mb.dead)
+	slices.Reverse(tasks)
+
+	return tasks, nil
+}
+
+// Unbury removes a task from the dead-letter list and returns it, or returns
+// `ErrNotFound` if it is not there.
+func (mb *MemoryBackend) Unbury(_ context.Context, id uuid.UUID) (Task, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	i := slices.IndexFunc(mb.dead, func(t Task) bool { return t.ID == id })
+	if i < 0 {
+		return Task{}, ErrNotFound
+	}
+
+	task := mb.dead[i]
+	mb.dead = slices.Delete(mb.dead, i, i+1)
+
+	return task, nil
+}
+
+// signal wakes up a worker waiting for a task, if any; mb.mu must be held.
+func (mb *MemoryBackend) signal() {
+	select {
+	case mb.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,537 @@
+/*
+Package queue runs the slow work of the server (e.g. sending the emails or the
+webmentions of the articles) in the background, off the path of the requests.
+
+The work is split into tasks of a given kind, which are enqueued with a JSON payload
+and run by a pool of workers with the handler registered for their kind. A task whose
+handler fails is retried a few times, with an exponential backoff between the
+attempts (randomized, so that the tasks failing together are not retried together),
+before it is moved to the dead-letter list, from which it can be retried or discarded
+by hand.
+
+The tasks are stored by a `Backend`, which is either:
+  - `MemoryBackend`, which keeps them in the memory of the process (the tasks are lost
+    when the server stops).
+  - `RedisBackend`, which keeps them in Redis lists, so that they survive the restarts
+    of the server and are shared by all the servers using the same Redis server.
+
+Example:
+
+	tasks := queue.New(queue.NewMemoryBackend(1000), queue.Options{})
+	tasks.Handle("email.send", func(ctx context.Context, task queue.Task) error {
+		var msg mailer.Message
+		if err := json.Unmarshal(task.Payload, &msg); err != nil {
+			return err
+		}
+
+		return smtp.Send(ctx, msg)
+	})
+	tasks.Start(ctx)
+
+	err := tasks.Enqueue(ctx, "email.send", msg)
+*/
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+// maxBackoff is the longest delay before the retry of a failed task.
+const maxBackoff = time.Hour
+
+// ErrQueueFull is returned when a task can not be enqueued because the queue is full.
+var ErrQueueFull = errors.New("task queue is full")
+
+// ErrNotFound is returned when a task is not in the dead-letter list.
+var ErrNotFound = errors.New("task not found")
+
+/*
+Task represents a unit of background work.
+
+Fields:
+  - ID: The unique identifier of the task (UUID).
+  - Kind: The kind of the task, which tells the handler running it, e.g. "email.send".
+  - Payload: The JSON encoding of the input of the task, which is stored encrypted
+    (as a JSON string) if the queue has a keyring, and handed to the handlers
+    decrypted.
+  - Attempts: The number of times the task was run.
+  - EnqueuedAt: When the task was enqueued.
+  - LastError: Why the last run of the task failed, if it did.
+  - FailedAt: When the task was moved to the dead-letter list, if it was.
+*/
+type Task struct {
+	ID         uuid.UUID       `json:"id"`
+	Kind       string          `json:"kind"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	LastError  string          `json:"last_error,omitempty"`
+	FailedAt   *time.Time      `json:"failed_at,omitempty"`
+}
+
+// HandlerFunc runs a task, returning an error if it has to be retried.
+type HandlerFunc func(ctx context.Context, task Task) error
+
+// Backend stores the pending tasks and the dead-letter list of a Queue.
+type Backend interface {
+	// Push adds a task to the pending tasks.
+	Push(ctx context.Context, task Task) error
+
+	// Pop removes the oldest pending task and returns it, waiting for one if there is
+	// none, until the context is done (returning its error).
+	Pop(ctx context.Context) (Task, error)
+
+	// Pending returns the number of pending tasks.
+	Pending(ctx context.Context) (int, error)
+
+	// Bury adds a task to the dead-letter list.
+	Bury(ctx context.Context, task Task) error
+
+	// Dead returns the tasks of the dead-letter list, most recent first.
+	Dead(ctx context.Context) ([]Task, error)
+
+	// Unbury removes a task from the dead-letter list and returns it, or returns
+	// `ErrNotFound` if it is not there.
+	Unbury(ctx context.Context, id uuid.UUID) (Task, error)
+}
+
+/*
+Options holds the settings of a Queue.
+
+Fields:
+  - Workers: The number of tasks run at once (4 if zero).
+  - MaxAttempts: The number of times a task is run before it is moved to the
+    dead-letter list (5 if zero).
+  - Backoff: The delay before the first retry of a failed task, which doubles with each
+    retry up to an hour (5 seconds if zero). Each delay is lengthened by a random
+    jitter of up to a fifth of it.
+  - Timeout: The time a run of a task can take (a minute if zero).
+  - IDs: The generator of the identifiers of the enqueued tasks (version 7 UUIDs if
+    nil).
+  - Clock: The clock the tasks are stamped with when enqueued and when moved to the
+    dead-letter list (the system clock if nil).
+  - Keyring: The keyring encrypting the payloads of the tasks, which may hold personal
+    data (e.g. the recipients and the body of the emails), before they are stored by
+    the backend (in plain text if nil).
+  - Logger: The logger of the failures of the tasks, which their handlers log through
+    too (see `logger.FromContext`), the default logger of the slog package if nil.
+*/
+type Options struct {
+	Workers     int
+	MaxAttempts int
+	Backoff     time.Duration
+	Timeout     time.Duration
+	IDs         ids.Generator
+	Clock       clock.Clock
+	Keyring     *encryption.Keyring
+	Logger      *slog.Logger
+}
+
+/*
+Stats represents the state of a Queue.
+
+Fields:
+  - Workers: The number of tasks run at once.
+  - Pending: The number of tasks waiting for a worker.
+  - Running: The number of tasks being run.
+  - Retrying: The number of failed tasks waiting for their retry.
+  - Dead: The number of tasks in the dead-letter list.
+  - Succeeded: The number of runs which succeeded since the server started.
+  - Failed: The number of runs which failed since the server started.
+*/
+type Stats struct {
+	Workers   int `json:"workers"`
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Retrying  int `json:"retrying"`
+	Dead      int `json:"dead"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// Queue runs the tasks stored by its backend with a pool of workers. It is safe for
+// concurrent use.
+type Queue struct {
+	backend Backend
+	opts    Options
+
+	mu        sync.Mutex
+	handlers  map[string]HandlerFunc
+	retries   map[uuid.UUID]*retry
+	stop      context.CancelFunc
+	wg        sync.WaitGroup
+	running   int
+	succeeded int
+	failed    int
+}
+
+// retry holds a failed task waiting for its retry.
+type retry struct {
+	task  Task
+	timer *time.Timer
+}
+
+// New creates and returns a new Queue storing its tasks with the backend. The queue
+// does not run any task until it is started.
+func New(backend Backend, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 5 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Minute
+	}
+	if opts.IDs == nil {
+		opts.IDs = ids.UUIDv7{}
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.System{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	return &Queue{
+		backend:  backend,
+		opts:     opts,
+		handlers: make(map[string]HandlerFunc),
+		retries:  make(map[uuid.UUID]*retry),
+	}
+}
+
+// Handle registers the handler running the tasks of the given kind, replacing the one
+// registered before, if any.
+func (q *Queue) Handle(kind string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[kind] = handler
+}
+
+// Enqueue enqueues a task of the given kind whose payload is the JSON encoding of
+// payload (encrypted with the keyring of the queue, if any), returning `ErrQueueFull`
+// (wrapped) if the backend can not take it.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("unable to encode the payload of task %q: %w", kind, err)
+	}
+
+	data, err = q.seal(data)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt the payload of task %q: %w", kind, err)
+	}
+
+	task := Task{
+		ID:         q.opts.IDs.NewID(),
+		Kind:       kind,
+		Payload:    data,
+		EnqueuedAt: q.opts.Clock.Now(),
+	}
+
+	if err := q.backend.Push(ctx, task); err != nil {
+		return fmt.Errorf("unable to enqueue task %q: %w", kind, err)
+	}
+
+	return nil
+}
+
+// Start runs the pending tasks in the background with the workers of the queue, until
+// the context is cancelled or the queue is closed. It is a no-op if the queue was
+// already started.
+func (q *Queue) Start(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stop != nil {
+		return
+	}
+
+	ctx, q.stop = context.WithCancel(ctx)
+	for range q.opts.Workers {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+}
+
+/*
+Close stops the workers of the queue and waits until the tasks being run are done, or
+until the context is done. The failed tasks waiting for their retry are pushed back to
+the pending tasks, to be run once the queue (or another one sharing its backend) is
+started again.
+*/
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stop != nil {
+		q.stop()
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for id, r := range q.retries {
+		if r.timer.Stop() {
+			if err := q.backend.Push(ctx, r.task); err != nil {
+				q.opts.Logger.ErrorContext(
+					ctx, "Unable to requeue task", "task_id", id, "error", err,
+				)
+			}
+		}
+		delete(q.retries, id)
+	}
+
+	return nil
+}
+
+// Stats returns the state of the queue.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	pending, err := q.backend.Pending(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("unable to count pending tasks: %w", err)
+	}
+
+	dead, err := q.backend.Dead(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("unable to fetch dead tasks: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return Stats{
+		Workers:   q.opts.Workers,
+		Pending:   pending,
+		Running:   q.running,
+		Retrying:  len(q.retries),
+		Dead:      len(dead),
+		Succeeded: q.succeeded,
+		Failed:    q.failed,
+	}, nil
+}
+
+// DeadLetters returns the tasks of the dead-letter list, most recent first.
+func (q *Queue) DeadLetters(ctx context.Context) ([]Task, error) {
+	tasks, err := q.backend.Dead(ctx)
+	if err != nil {
+		return []Task{}, fmt.Errorf("unable to fetch dead tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// Retry moves a task of the dead-letter list back to the pending tasks, with its
+// attempts reset, returning `ErrNotFound` (wrapped) if it is not in the list.
+func (q *Queue) Retry(ctx context.Context, id uuid.UUID) (Task, error) {
+	task, err := q.backend.Unbury(ctx, id)
+	if err != nil {
+		return Task{}, fmt.Errorf("unable to fetch dead task %s: %w", id, err)
+	}
+
+	task.Attempts = 0
+	task.LastError = ""
+	task.FailedAt = nil
+
+	if err := q.backend.Push(ctx, task); err != nil {
+		return Task{}, fmt.Errorf("unable to enqueue task %s: %w", id, err)
+	}
+
+	return task, nil
+}
+
+// Discard removes a task from the dead-letter list for good, returning `ErrNotFound`
+// (wrapped) if it is not in the list.
+func (q *Queue) Discard(ctx context.Context, id uuid.UUID) error {
+	if _, err := q.backend.Unbury(ctx, id); err != nil {
+		return fmt.Errorf("unable to discard dead task %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// work runs the pending tasks one at a time, until the context is cancelled.
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		task, err := q.backend.Pop(ctx)
+		if ctx.Err() != nil && err != nil {
+			return
+		} else if err != nil {
+			q.opts.Logger.ErrorContext(ctx, "Unable to fetch pending task", "error", err)
+
+			// Wait a bit before trying again, e.g. while Redis is down
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		// Let the task complete even if the queue is closed meanwhile
+		q.run(context.WithoutCancel(ctx), task)
+	}
+}
+
+// run runs a task once, then schedules its retry or moves it to the dead-letter list
+// if it failed.
+func (q *Queue) run(ctx context.Context, task Task) {
+	q.mu.Lock()
+	handler, ok := q.handlers[task.Kind]
+	q.running++
+	q.mu.Unlock()
+
+	task.Attempts++
+	err := fmt.Errorf("no handler for tasks of kind %q", task.Kind)
+	if ok {
+		err = q.call(ctx, handler, task)
+	}
+
+	q.mu.Lock()
+	q.running--
+	if err == nil {
+		q.succeeded++
+		q.mu.Unlock()
+		return
+	}
+	q.failed++
+	q.mu.Unlock()
+
+	task.LastError = err.Error()
+
+	if !ok || task.Attempts >= q.opts.MaxAttempts {
+		now := q.opts.Clock.Now()
+		task.FailedAt = &now
+
+		q.opts.Logger.WarnContext(
+			ctx, "Task moved to the dead-letter list",
+			"task_id", task.ID,
+			"kind", task.Kind,
+			"attempts", task.Attempts,
+			"error", err,
+		)
+		if err := q.backend.Bury(ctx, task); err != nil {
+			q.opts.Logger.ErrorContext(
+				ctx, "Unable to bury task", "task_id", task.ID, "error", err,
+			)
+		}
+		return
+	}
+
+	// Spread the retries of the tasks which failed together, e.g. while a server they
+	// call is down, rather than retrying them all at once
+	backoff := min(q.opts.Backoff<<min(task.Attempts-1, 16), maxBackoff)
+	backoff += rand.N(backoff/5 + 1)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.retries[task.ID] = &retry{
+		task: task,
+		timer: time.AfterFunc(backoff, func() {
+			q.mu.Lock()
+			delete(q.retries, task.ID)
+			q.mu.Unlock()
+
+			if err := q.backend.Push(ctx, task); err != nil {
+				q.opts.Logger.ErrorContext(
+					ctx, "Unable to requeue task", "task_id", task.ID, "error", err,
+				)
+			}
+		}),
+	}
+}
+
+// seal returns the payload encrypted with the keyring of the queue, as a JSON string,
+// or as is if the queue has no keyring.
+func (q *Queue) seal(payload json.RawMessage) (json.RawMessage, error) {
+	if q.opts.Keyring == nil {
+		return payload, nil
+	}
+
+	sealed, err := q.opts.Keyring.Encrypt(string(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(sealed)
+}
+
+// open returns the payload encrypted by `seal` decrypted, and the other payloads (e.g.
+// the ones enqueued before the queue had a keyring) as they are.
+func (q *Queue) open(payload json.RawMessage) (json.RawMessage, error) {
+	var sealed string
+	if err := json.Unmarshal(payload, &sealed); err != nil ||
+		!encryption.IsEncrypted(sealed) {
+		return payload, nil
+	}
+
+	plain, err := q.opts.Keyring.Decrypt(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(plain), nil
+}
+
+// call calls the handler with the task, its payload decrypted, within the timeout of
+// the queue and with a context holding the logger of the queue, turning its panics
+// into errors.
+func (q *Queue) call(
+	ctx context.Context,
+	handler HandlerFunc,
+	task Task,
+) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, q.opts.Timeout)
+	defer cancel()
+
+	ctx = logger.NewContext(
+		ctx,
+		q.opts.Logger.With("task_id", task.ID, "kind", task.Kind),
+	)
+
+	task.Payload, err = q.open(task.Payload)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt the payload: %w", err)
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+
+	return handler(ctx, task)
+}
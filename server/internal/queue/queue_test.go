@@ -0,0 +1,197 @@
+package queue_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/queue"
+)
+
+// TestDeadLetters checks that a task without a handler is moved to the dead-letter
+// list, identified and stamped with the generator and the clock of the queue.
+func TestDeadLetters(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tasks := queue.New(queue.NewMemoryBackend(10), queue.Options{
+		Workers: 1,
+		IDs:     ids.NewSequential(1),
+		Clock:   services.NewFixedClock(now),
+	})
+
+	ctx := context.Background()
+	if err := tasks.Enqueue(ctx, "unknown", map[string]int{"n": 1}); err != nil {
+		t.Fatalf("Unable to enqueue the task: %v", err)
+	}
+
+	tasks.Start(ctx)
+	defer tasks.Close(ctx)
+
+	var dead []queue.Task
+	for deadline := time.Now().Add(5 * time.Second); len(dead) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("The task was not moved to the dead-letter list")
+		}
+		time.Sleep(time.Millisecond)
+
+		var err error
+		if dead, err = tasks.DeadLetters(ctx); err != nil {
+			t.Fatalf("Unable to fetch the dead tasks: %v", err)
+		}
+	}
+
+	task := dead[0]
+	expectedID := uuid.MustParse("00000000-0000-7001-8000-000000000001")
+	if task.ID != expectedID {
+		t.Errorf("Expected the ID %s. Got %s\n", expectedID, task.ID)
+	}
+	if string(task.Payload) != `{"n":1}` {
+		t.Errorf("Expected the payload %s. Got %s\n", `{"n":1}`, task.Payload)
+	}
+	if task.Attempts != 1 {
+		t.Errorf("Expected 1 attempt. Got %d\n", task.Attempts)
+	}
+	if !task.EnqueuedAt.Equal(now) {
+		t.Errorf("Expected to be enqueued at %s. Got %s\n", now, task.EnqueuedAt)
+	}
+	if task.FailedAt == nil || !task.FailedAt.Equal(now) {
+		t.Errorf("Expected to fail at %s. Got %v\n", now, task.FailedAt)
+	}
+}
+
+// TestSealedPayload checks that the payloads of the tasks are stored encrypted with the
+// keyring of the queue, and handed to their handler decrypted.
+func TestSealedPayload(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	keyring, err := encryption.ParseKeys("test:" + key)
+	if err != nil {
+		t.Fatalf("Unable to parse the key: %v", err)
+	}
+
+	backend := queue.NewMemoryBackend(10)
+	tasks := queue.New(backend, queue.Options{Workers: 1, Keyring: keyring})
+
+	ctx := context.Background()
+	payload := map[string]string{"to": "jane@example.com"}
+	if err := tasks.Enqueue(ctx, "email.send", payload); err != nil {
+		t.Fatalf("Unable to enqueue the task: %v", err)
+	}
+
+	stored, err := backend.Pop(ctx)
+	if err != nil {
+		t.Fatalf("Unable to fetch the task: %v", err)
+	}
+	if strings.Contains(string(stored.Payload), "jane@example.com") {
+		t.Errorf("Expected the payload to be stored encrypted. Got %s\n", stored.Payload)
+	}
+	if err := backend.Push(ctx, stored); err != nil {
+		t.Fatalf("Unable to push the task back: %v", err)
+	}
+
+	handled := make(chan json.RawMessage, 1)
+	tasks.Handle("email.send", func(_ context.Context, task queue.Task) error {
+		handled <- task.Payload
+		return nil
+	})
+	tasks.Start(ctx)
+	defer tasks.Close(ctx)
+
+	select {
+	case got := <-handled:
+		if expected := `{"to":"jane@example.com"}`; string(got) != expected {
+			t.Errorf("Expected the payload %s. Got %s\n", expected, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The task was not run")
+	}
+}
+
+// TestRetry checks that a failed task is retried after a backoff until it succeeds, and
+// moved to the dead-letter list once it failed as many times as allowed.
+func TestRetry(t *testing.T) {
+	tasks := queue.New(queue.NewMemoryBackend(10), queue.Options{
+		Workers:     1,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	})
+
+	var mu sync.Mutex
+	attempts := make(map[string][]time.Time)
+	tasks.Handle("flaky", func(_ context.Context, task queue.Task) error {
+		var name string
+		if err := json.Unmarshal(task.Payload, &name); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts[name] = append(attempts[name], time.Now())
+		if name == "broken" || len(attempts[name]) < 2 {
+			return fmt.Errorf("attempt %d failed", task.Attempts)
+		}
+
+		return nil
+	})
+
+	ctx := context.Background()
+	for _, name := range []string{"recovering", "broken"} {
+		if err := tasks.Enqueue(ctx, "flaky", name); err != nil {
+			t.Fatalf("Unable to enqueue the task: %v", err)
+		}
+	}
+
+	tasks.Start(ctx)
+	defer tasks.Close(ctx)
+
+	var stats queue.Stats
+	for deadline := time.Now().Add(5 * time.Second); stats.Dead == 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("The broken task was not moved to the dead-letter list: %+v", stats)
+		}
+		time.Sleep(time.Millisecond)
+
+		var err error
+		if stats, err = tasks.Stats(ctx); err != nil {
+			t.Fatalf("Unable to fetch the stats: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if n := len(attempts["recovering"]); n != 2 || stats.Succeeded != 1 {
+		t.Errorf("Expected the recovering task to succeed on its second attempt. "+
+			"Got %d attempt(s) and %+v\n", n, stats)
+	}
+	if n := len(attempts["broken"]); n != 3 || stats.Failed != 4 {
+		t.Errorf("Expected the broken task to fail 3 times. Got %d attempt(s) and %+v\n",
+			n, stats)
+	}
+
+	// The delay before each retry doubles, on top of its jitter
+	broken := attempts["broken"]
+	for i := 1; i < len(broken); i++ {
+		delay, backoff := broken[i].Sub(broken[i-1]), time.Millisecond<<(i-1)
+		if delay < backoff {
+			t.Errorf("Expected retry %d after at least %s. Got %s\n", i, backoff, delay)
+		}
+	}
+
+	dead, err := tasks.DeadLetters(ctx)
+	if err != nil {
+		t.Fatalf("Unable to fetch the dead tasks: %v", err)
+	}
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastError != "attempt 3 failed" {
+		t.Errorf("Expected the broken task after 3 attempts. Got %+v\n", dead)
+	}
+}
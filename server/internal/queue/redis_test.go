@@ -0,0 +1,323 @@
+package queue_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/queue"
+)
+
+/*
+fakeRedis is a Redis server speaking enough of the RESP protocol for a RedisBackend,
+which records the commands it is sent.
+
+It accepts the `AUTH` command whose arguments are auth (any if nil), and replies to the
+commands named in fail with the given error instead of running them.
+*/
+type fakeRedis struct {
+	addr string
+	auth []string
+
+	mu       sync.Mutex
+	lists    map[string][]string
+	fail     map[string]string
+	commands [][]string
+	conns    int
+}
+
+// newFakeRedis starts a fakeRedis, stopped at the end of the test.
+func newFakeRedis(t *testing.T, auth ...string) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeRedis{
+		addr:  listener.Addr().String(),
+		auth:  auth,
+		lists: make(map[string][]string),
+		fail:  make(map[string]string),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			f.mu.Lock()
+			f.conns++
+			f.mu.Unlock()
+
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+// serve replies to the commands sent on the connection, until it is closed.
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		if _, err := io.WriteString(conn, f.reply(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "*"), "\r\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+
+	return args, nil
+}
+
+// reply runs the command and returns its encoded reply.
+func (f *fakeRedis) reply(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, args)
+	if msg, ok := f.fail[args[0]]; ok {
+		return "-" + msg + "\r\n"
+	}
+
+	switch args[0] {
+	case "AUTH":
+		if f.auth != nil && !slices.Equal(args[1:], f.auth) {
+			return "-WRONGPASS invalid username-password pair\r\n"
+		}
+		return "+OK\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "LPUSH":
+		f.lists[args[1]] = append([]string{args[2]}, f.lists[args[1]]...)
+		return fmt.Sprintf(":%d\r\n", len(f.lists[args[1]]))
+	case "BRPOP":
+		list := f.lists[args[1]]
+		if len(list) == 0 {
+			return "*-1\r\n"
+		}
+		f.lists[args[1]] = list[:len(list)-1]
+		return "*2\r\n" + bulk(args[1]) + bulk(list[len(list)-1])
+	case "LLEN":
+		return fmt.Sprintf(":%d\r\n", len(f.lists[args[1]]))
+	case "LTRIM":
+		stop, _ := strconv.Atoi(args[3])
+		if list := f.lists[args[1]]; len(list) > stop+1 {
+			f.lists[args[1]] = list[:stop+1]
+		}
+		return "+OK\r\n"
+	case "LRANGE":
+		list := f.lists[args[1]]
+		reply := fmt.Sprintf("*%d\r\n", len(list))
+		for _, item := range list {
+			reply += bulk(item)
+		}
+		return reply
+	case "LREM":
+		list := f.lists[args[1]]
+		i := slices.Index(list, args[3])
+		if i < 0 {
+			return ":0\r\n"
+		}
+		f.lists[args[1]] = slices.Delete(list, i, i+1)
+		return ":1\r\n"
+	}
+
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+// bulk encodes a bulk string.
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+// sent returns the commands sent to the server so far.
+func (f *fakeRedis) sent() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.commands)
+}
+
+// connections returns the number of connections opened to the server so far.
+func (f *fakeRedis) connections() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.conns
+}
+
+// TestRedisBackend checks that the tasks pushed to a RedisBackend are popped in order,
+// and are moved to and from the dead-letter list.
+func TestRedisBackend(t *testing.T) {
+	server := newFakeRedis(t)
+	backend, err := queue.NewRedisBackend("redis://" + server.addr + "/2")
+	if err != nil {
+		t.Fatalf("Unable to create the backend: %v", err)
+	}
+
+	ctx := context.Background()
+	first := queue.Task{ID: uuid.New(), Kind: "email.send", Payload: []byte(`{"n":1}`)}
+	second := queue.Task{ID: uuid.New(), Kind: "email.send", Payload: []byte(`{"n":2}`)}
+	for _, task := range []queue.Task{first, second} {
+		if err := backend.Push(ctx, task); err != nil {
+			t.Fatalf("Unable to push the task: %v", err)
+		}
+	}
+
+	if pending, err := backend.Pending(ctx); err != nil || pending != 2 {
+		t.Errorf("Expected 2 pending tasks. Got %d (%v)\n", pending, err)
+	}
+
+	task, err := backend.Pop(ctx)
+	if err != nil || task.ID != first.ID || string(task.Payload) != `{"n":1}` {
+		t.Fatalf("Expected the first task. Got %+v (%v)\n", task, err)
+	}
+
+	if err := backend.Bury(ctx, task); err != nil {
+		t.Fatalf("Unable to bury the task: %v", err)
+	}
+	dead, err := backend.Dead(ctx)
+	if err != nil || len(dead) != 1 || dead[0].ID != first.ID {
+		t.Errorf("Expected the first task to be dead. Got %+v (%v)\n", dead, err)
+	}
+
+	if task, err := backend.Unbury(ctx, first.ID); err != nil || task.ID != first.ID {
+		t.Errorf("Expected to unbury the first task. Got %+v (%v)\n", task, err)
+	}
+	if _, err := backend.Unbury(ctx, first.ID); !errors.Is(err, queue.ErrNotFound) {
+		t.Errorf("Expected %v. Got %v\n", queue.ErrNotFound, err)
+	}
+
+	commands := server.sent()
+	if expected := []string{"SELECT", "2"}; !slices.Equal(commands[0], expected) {
+		t.Errorf("Expected the connection to select %v. Got %v\n", expected, commands[0])
+	}
+	if n := server.connections(); n != 1 {
+		t.Errorf("Expected the connection to be reused. Got %d connections\n", n)
+	}
+}
+
+// TestRedisAuth checks that the connections authenticate as the user of the URL, or
+// with its password only.
+func TestRedisAuth(t *testing.T) {
+	tests := []struct {
+		userinfo string
+		auth     []string
+	}{
+		{"", nil},
+		{":secret@", []string{"AUTH", "secret"}},
+		{"burzcontent:secret@", []string{"AUTH", "burzcontent", "secret"}},
+	}
+	for _, tt := range tests {
+		server := newFakeRedis(t)
+		backend, err := queue.NewRedisBackend("redis://" + tt.userinfo + server.addr)
+		if err != nil {
+			t.Fatalf("Unable to create the backend: %v", err)
+		}
+
+		if _, err := backend.Pending(context.Background()); err != nil {
+			t.Fatalf("Unable to count the pending tasks: %v", err)
+		}
+
+		var auth []string
+		if commands := server.sent(); commands[0][0] == "AUTH" {
+			auth = commands[0]
+		}
+		if !slices.Equal(auth, tt.auth) {
+			t.Errorf("Expected %q to send %v. Got %v\n", tt.userinfo, tt.auth, auth)
+		}
+	}
+
+	server := newFakeRedis(t, "burzcontent", "secret")
+	backend, err := queue.NewRedisBackend("redis://burzcontent:wrong@" + server.addr)
+	if err != nil {
+		t.Fatalf("Unable to create the backend: %v", err)
+	}
+	_, err = backend.Pending(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Expected the wrong password to be rejected. Got %v\n", err)
+	}
+}
+
+// TestRedisErrorReply checks that the errors replied by Redis are returned, and that
+// the connection they were replied on is reused.
+func TestRedisErrorReply(t *testing.T) {
+	server := newFakeRedis(t)
+	backend, err := queue.NewRedisBackend("redis://" + server.addr)
+	if err != nil {
+		t.Fatalf("Unable to create the backend: %v", err)
+	}
+
+	msg := "WRONGTYPE Operation against a key holding the wrong kind of value"
+	server.mu.Lock()
+	server.fail["LLEN"] = msg
+	server.mu.Unlock()
+
+	ctx := context.Background()
+	if _, err := backend.Pending(ctx); err == nil || !strings.Contains(err.Error(), msg) {
+		t.Errorf("Expected the error %q. Got %v\n", msg, err)
+	}
+
+	server.mu.Lock()
+	delete(server.fail, "LLEN")
+	server.mu.Unlock()
+
+	if _, err := backend.Pending(ctx); err != nil {
+		t.Errorf("Expected to count the pending tasks. Got %v\n", err)
+	}
+	if n := server.connections(); n != 1 {
+		t.Errorf("Expected the connection to be reused. Got %d connections\n", n)
+	}
+}
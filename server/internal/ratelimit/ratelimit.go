@@ -0,0 +1,117 @@
+/*
+Package ratelimit provides an in-memory token bucket rate limiter.
+
+A `Limiter` keeps one bucket per key (e.g. the unique identifier of a site or the IP
+address of a client). Each bucket holds up to `limit` tokens and is refilled
+continuously at a rate of `limit` tokens per window, so that a key can burst up to its
+limit and then sustain `limit` requests per window.
+*/
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+/*
+Result describes the outcome of a rate limiting decision.
+
+Fields:
+  - Allowed: Whether the request is allowed.
+  - Limit: The maximum number of requests allowed per window.
+  - Remaining: The number of requests which can still be made right away.
+  - Reset: The duration after which the bucket is full again.
+  - RetryAfter: The duration after which a rejected request may be retried.
+*/
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// bucket is the token bucket of a single key.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// maxBuckets is the number of buckets above which the full (idle) buckets are swept.
+const maxBuckets = 10000
+
+// Limiter is a concurrency-safe token bucket rate limiter keeping one bucket per key.
+type Limiter struct {
+	window time.Duration
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New creates and returns a new Limiter refilling its buckets once per window, as
+// time goes by on the given clock.
+func New(window time.Duration, clock clock.Clock) *Limiter {
+	return &Limiter{
+		window:  window,
+		clock:   clock,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+/*
+Allow takes a token from the bucket of the key and reports whether the request is
+allowed, given a limit of `limit` requests per window.
+
+A limit of zero (or less) disables the rate limiting of the key.
+*/
+func (l *Limiter) Allow(key string, limit int) Result {
+	if limit <= 0 {
+		return Result{Allowed: true, Limit: limit}
+	}
+
+	now := l.clock.Now()
+	rate := float64(limit) / float64(l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.sweep(now)
+		}
+
+		b = &bucket{tokens: float64(limit), updated: now}
+		l.buckets[key] = b
+	}
+
+	// Refill the bucket for the time elapsed since it was last updated
+	b.tokens = math.Min(float64(limit), b.tokens+float64(now.Sub(b.updated))*rate)
+	b.updated = now
+
+	result := Result{Allowed: b.tokens >= 1, Limit: limit}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((float64(limit) - b.tokens) / rate)
+
+	return result
+}
+
+// sweep removes the buckets which have been idle for a whole window, since they would
+// be full again anyway; l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.window {
+			delete(l.buckets, key)
+		}
+	}
+}
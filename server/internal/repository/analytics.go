@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// AnalyticsRepository defines the data access methods of the page views of the sites.
+type AnalyticsRepository interface {
+	// ListSince returns the page views of the site recorded since the given time,
+	// oldest first.
+	ListSince(
+		ctx context.Context,
+		siteID uuid.UUID,
+		since time.Time,
+	) ([]models.PageView, error)
+
+	// Create records a new page view of the site referenced by its `SiteID` field.
+	Create(ctx context.Context, view models.PageView) error
+}
+
+// MemoryAnalyticsRepository is an in-memory implementation of AnalyticsRepository.
+type MemoryAnalyticsRepository struct {
+	table *table[models.PageView]
+}
+
+// NewMemoryAnalyticsRepository creates and returns a new empty
+// MemoryAnalyticsRepository.
+func NewMemoryAnalyticsRepository() *MemoryAnalyticsRepository {
+	return &MemoryAnalyticsRepository{
+		table: newTable(
+			func(v models.PageView) uuid.UUID { return v.ID },
+			func(v models.PageView) uuid.UUID { return v.SiteID },
+		),
+	}
+}
+
+// ListSince returns the page views of the site recorded since the given time, oldest
+// first.
+func (ar *MemoryAnalyticsRepository) ListSince(
+	ctx context.Context,
+	siteID uuid.UUID,
+	since time.Time,
+) ([]models.PageView, error) {
+	return ar.table.list(ctx, siteID, func(v models.PageView) bool {
+		return !v.At.Before(since)
+	}), nil
+}
+
+// Create records a new page view of the site referenced by its `SiteID` field.
+func (ar *MemoryAnalyticsRepository) Create(
+	ctx context.Context,
+	view models.PageView,
+) error {
+	return ar.table.insert(ctx, view)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// APIKeyRepository defines the data access methods of the API keys.
+type APIKeyRepository interface {
+	// List returns every API key of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.APIKey, error)
+
+	// Get returns the API key of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.APIKey, error)
+
+	// GetByHash returns the API key (of any site) with the given digest, or
+	// `ErrNotFound`. It is only meant to authenticate requests.
+	GetByHash(ctx context.Context, hash string) (models.APIKey, error)
+
+	// Create stores a new API key in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, key models.APIKey) error
+
+	// Update replaces an existing API key of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound`.
+	Update(ctx context.Context, key models.APIKey) error
+
+	// Delete removes the API key of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryAPIKeyRepository is an in-memory implementation of APIKeyRepository.
+type MemoryAPIKeyRepository struct {
+	table *table[models.APIKey]
+}
+
+// NewMemoryAPIKeyRepository creates and returns a new empty MemoryAPIKeyRepository.
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		table: newTable(
+			func(k models.APIKey) uuid.UUID { return k.ID },
+			func(k models.APIKey) uuid.UUID { return k.SiteID },
+		),
+	}
+}
+
+// List returns every API key of the site.
+func (kr *MemoryAPIKeyRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.APIKey, error) {
+	return kr.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the API key of the site identified by id, or `ErrNotFound`.
+func (kr *MemoryAPIKeyRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.APIKey, error) {
+	return kr.table.get(ctx, siteID, id)
+}
+
+// GetByHash returns the API key (of any site) with the given digest, or `ErrNotFound`.
+func (kr *MemoryAPIKeyRepository) GetByHash(
+	ctx context.Context,
+	hash string,
+) (models.APIKey, error) {
+	kr.table.mu.RLock()
+	defer kr.table.mu.RUnlock()
+
+	for _, key := range kr.table.rows {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+
+	return models.APIKey{}, ErrNotFound
+}
+
+// Create stores a new API key in the site referenced by its `SiteID` field.
+func (kr *MemoryAPIKeyRepository) Create(ctx context.Context, key models.APIKey) error {
+	return kr.table.insert(ctx, key)
+}
+
+// Update replaces an existing API key of the site referenced by its `SiteID` field.
+func (kr *MemoryAPIKeyRepository) Update(ctx context.Context, key models.APIKey) error {
+	return kr.table.update(ctx, key)
+}
+
+// Delete removes the API key of the site identified by id, or returns `ErrNotFound`.
+func (kr *MemoryAPIKeyRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return kr.table.delete(ctx, siteID, id)
+}
@@ -0,0 +1,598 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+)
+
+/*
+ArticleRepository defines the data access methods of the articles.
+
+The deleted articles are moved to the trash of their site, from which they can be
+restored until they are purged. The trashed articles are left out of every method but
+the ones dedicated to the trash (and `Delete`, which purges them).
+*/
+type ArticleRepository interface {
+	// List returns every article of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Article, error)
+
+	// Get returns the article of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Article, error)
+
+	// GetByShortID returns the article of the site with the given short ID, or
+	// `ErrNotFound`.
+	GetByShortID(
+		ctx context.Context,
+		siteID uuid.UUID,
+		shortID string,
+	) (models.Article, error)
+
+	// ListTrashed returns every trashed article of the site.
+	ListTrashed(ctx context.Context, siteID uuid.UUID) ([]models.Article, error)
+
+	// ListTrashedBefore returns the articles (of any site) trashed before the given
+	// time. It is only meant to purge the trash of the sites.
+	ListTrashedBefore(ctx context.Context, before time.Time) ([]models.Article, error)
+
+	// ListExpired returns the published articles (of any site) which expired at the
+	// given time. It is only meant to unpublish them.
+	ListExpired(ctx context.Context, at time.Time) ([]models.Article, error)
+
+	// ListScheduled returns the unpublished articles of the site whose publication
+	// was scheduled at or before the given time. It is only meant to publish them.
+	ListScheduled(
+		ctx context.Context,
+		siteID uuid.UUID,
+		at time.Time,
+	) ([]models.Article, error)
+
+	// Query returns a page of the articles of the site matching the query, newest
+	// first, along with the number of articles matching the query.
+	Query(
+		ctx context.Context,
+		siteID uuid.UUID,
+		query ArticleQuery,
+	) ([]models.Article, int, error)
+
+	// CountByMonth returns the months (in the given location) the published articles
+	// of the site were published in, newest first, with the number of articles
+	// published in each of them.
+	CountByMonth(
+		ctx context.Context,
+		siteID uuid.UUID,
+		loc *time.Location,
+	) ([]models.ArchiveMonth, error)
+
+	// CountTags returns the tags of the published articles of the site, with the
+	// number of articles tagged with each of them and how many of those were published
+	// since the given time, in a single pass over the articles.
+	CountTags(
+		ctx context.Context,
+		siteID uuid.UUID,
+		since time.Time,
+	) ([]models.Tag, error)
+
+	// Trash moves the article of the site identified by id to the trash, or returns
+	// `ErrNotFound`.
+	Trash(ctx context.Context, siteID, id uuid.UUID, at time.Time) error
+
+	// Restore restores the trashed article of the site identified by id, or returns
+	// `ErrNotFound`.
+	Restore(ctx context.Context, siteID, id uuid.UUID) error
+
+	// Create stores a new article in the site referenced by its `SiteID` field, or
+	// returns `ErrConflict` if its short ID is already taken.
+	Create(ctx context.Context, article models.Article) error
+
+	// Update replaces an existing article of the site referenced by its `SiteID`
+	// field, whose version has to follow the stored one, or returns `ErrNotFound`
+	// (`ErrStale` if the version does not follow the stored one, or `ErrConflict` if
+	// its short ID is already taken by another article).
+	Update(ctx context.Context, article models.Article) error
+
+	// Delete removes the article of the site identified by id for good, whether it is
+	// trashed or not, or returns `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+
+	// Replace replaces every article of the site, trashed or not, with the given ones
+	// at once, and returns the articles it replaced, or returns `ErrConflict`
+	// (changing nothing) if an article of another site has the same identifier as one
+	// of them, or if two of them have the same short ID.
+	Replace(
+		ctx context.Context,
+		siteID uuid.UUID,
+		articles []models.Article,
+	) ([]models.Article, error)
+}
+
+// Query returns a page of the articles of the site matching the query, in the order of
+// the query or newest first (the articles which were never published last), along with
+// the number of articles matching the query.
+func (ar *MemoryArticleRepository) Query(
+	ctx context.Context,
+	siteID uuid.UUID,
+	query ArticleQuery,
+) ([]models.Article, int, error) {
+	bounded := !query.PublishedFrom.IsZero() || !query.PublishedBefore.IsZero()
+	articles := ar.table.list(ctx, siteID, func(a models.Article) bool {
+		if a.DeletedAt != nil || (query.PublishedOnly && !a.IsPublished) ||
+			(query.Author != "" && a.Author != query.Author) ||
+			(query.AuthorID != uuid.Nil && !a.AuthoredBy(query.AuthorID)) ||
+			a.UpdatedAt.Before(query.UpdatedSince) {
+			return false
+		}
+
+		if a.PublishedAt == nil {
+			return !bounded
+		}
+
+		before := query.PublishedBefore
+		return !a.PublishedAt.Before(query.PublishedFrom) &&
+			(before.IsZero() || a.PublishedAt.Before(before))
+	})
+
+	slices.SortStableFunc(articles, func(a, b models.Article) int {
+		var c int
+		switch query.Sort.Field {
+		case "":
+			return -comparePublishedAt(a, b)
+		case "title":
+			c = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case "created_at":
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case "updated_at":
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		case "published_at":
+			c = comparePublishedAt(a, b)
+		}
+
+		if query.Sort.Desc {
+			return -c
+		}
+
+		return c
+	})
+
+	return pagination.Slice(articles, query.Page), len(articles), nil
+}
+
+// CountByMonth returns the months (in the given location) the published articles of
+// the site were published in, newest first, with the number of articles published in
+// each of them. The articles without any publication date are left out.
+func (ar *MemoryArticleRepository) CountByMonth(
+	ctx context.Context,
+	siteID uuid.UUID,
+	loc *time.Location,
+) ([]models.ArchiveMonth, error) {
+	ar.table.mu.RLock()
+	defer ar.table.mu.RUnlock()
+
+	counts := make(map[models.ArchiveMonth]int)
+	for _, id := range ar.table.order {
+		article := ar.table.rows[id]
+		if article.SiteID != siteID || article.DeletedAt != nil ||
+			!article.IsPublished || article.PublishedAt == nil {
+			continue
+		}
+
+		published := article.PublishedAt.In(loc)
+		counts[models.ArchiveMonth{
+			Year:  published.Year(),
+			Month: int(published.Month()),
+		}]++
+	}
+
+	months := make([]models.ArchiveMonth, 0, len(counts))
+	for month, count := range counts {
+		month.Count = count
+		months = append(months, month)
+	}
+
+	slices.SortFunc(months, func(a, b models.ArchiveMonth) int {
+		return (b.Year*12 + b.Month) - (a.Year*12 + a.Month)
+	})
+
+	return months, nil
+}
+
+// comparePublishedAt compares the publication dates of two articles, an article
+// without any publication date being older than any other.
+func comparePublishedAt(a, b models.Article) int {
+	switch {
+	case a.PublishedAt == nil && b.PublishedAt == nil:
+		return 0
+	case a.PublishedAt == nil:
+		return -1
+	case b.PublishedAt == nil:
+		return 1
+	}
+
+	return a.PublishedAt.Compare(*b.PublishedAt)
+}
+
+// CountTags returns the tags of the published articles of the site, with the number of
+// articles tagged with each of them and how many of those were published since the
+// given time, in the order they are first found.
+func (ar *MemoryArticleRepository) CountTags(
+	ctx context.Context,
+	siteID uuid.UUID,
+	since time.Time,
+) ([]models.Tag, error) {
+	ar.table.mu.RLock()
+	defer ar.table.mu.RUnlock()
+
+	tags := []models.Tag{}
+	index := make(map[string]int)
+	for _, id := range ar.table.order {
+		article := ar.table.rows[id]
+		if article.SiteID != siteID || article.DeletedAt != nil ||
+			!article.IsPublished {
+			continue
+		}
+
+		recent := article.PublishedAt != nil && !article.PublishedAt.Before(since)
+		for _, name := range article.Tags {
+			i, ok := index[name]
+			if !ok {
+				i = len(tags)
+				index[name] = i
+				tags = append(tags, models.Tag{Name: name})
+			}
+
+			tags[i].Count++
+			if recent {
+				tags[i].Delta++
+			}
+		}
+	}
+
+	return tags, nil
+}
+
+/*
+ArticleQuery selects, orders and pages the articles of a site.
+
+Fields:
+  - PublishedOnly: Whether only the published articles match.
+  - Author: The name of the author of the articles (any author matches if empty).
+  - AuthorID: The unique identifier of the user who authored the articles (any author
+    matches if nil).
+  - PublishedFrom: The time the articles were published at or after (unbounded if
+    zero).
+  - PublishedBefore: The time the articles were published before (unbounded if zero).
+  - UpdatedSince: The time the articles were last updated at or after (unbounded if
+    zero).
+  - Sort: The order of the articles, either by "title", "created_at", "updated_at" or
+    "published_at" (newest publication first if unset).
+  - Page: The page of articles to return.
+
+The articles without any publication date only match the queries which are unbounded.
+*/
+type ArticleQuery struct {
+	PublishedOnly   bool
+	Author          string
+	AuthorID        uuid.UUID
+	PublishedFrom   time.Time
+	PublishedBefore time.Time
+	UpdatedSince    time.Time
+	Sort            pagination.Sort
+	Page            pagination.Page
+}
+
+// ArticleSortFields lists the fields the articles can be sorted by.
+var ArticleSortFields = []string{"title", "created_at", "updated_at", "published_at"}
+
+// shortIDKey is the key of an article in the index of the short IDs, which are unique
+// within a site.
+type shortIDKey struct {
+	siteID  uuid.UUID
+	shortID string
+}
+
+/*
+MemoryArticleRepository is an in-memory implementation of ArticleRepository.
+
+The articles having a short ID are indexed by it, so that they are looked up without
+scanning the articles of their site.
+*/
+type MemoryArticleRepository struct {
+	mu       sync.Mutex // Serializes the writes, so that the short IDs remain unique
+	shortIDs map[shortIDKey]uuid.UUID
+	table    *table[models.Article]
+}
+
+// NewMemoryArticleRepository creates and returns a new empty MemoryArticleRepository.
+func NewMemoryArticleRepository() *MemoryArticleRepository {
+	return &MemoryArticleRepository{
+		shortIDs: make(map[shortIDKey]uuid.UUID),
+		table: newTable(
+			func(a models.Article) uuid.UUID { return a.ID },
+			func(a models.Article) uuid.UUID { return a.SiteID },
+		),
+	}
+}
+
+// List returns every article of the site.
+func (ar *MemoryArticleRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Article, error) {
+	return ar.table.list(ctx, siteID, func(a models.Article) bool {
+		return a.DeletedAt == nil
+	}), nil
+}
+
+// Get returns the article of the site identified by id, or `ErrNotFound`.
+func (ar *MemoryArticleRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Article, error) {
+	article, err := ar.table.get(ctx, siteID, id)
+	if err == nil && article.DeletedAt != nil {
+		return models.Article{}, ErrNotFound
+	}
+
+	return article, err
+}
+
+// GetByShortID returns the article of the site with the given short ID, or
+// `ErrNotFound`.
+func (ar *MemoryArticleRepository) GetByShortID(
+	ctx context.Context,
+	siteID uuid.UUID,
+	shortID string,
+) (models.Article, error) {
+	ar.mu.Lock()
+	id, ok := ar.shortIDs[shortIDKey{siteID: siteID, shortID: shortID}]
+	ar.mu.Unlock()
+
+	if !ok {
+		return models.Article{}, ErrNotFound
+	}
+
+	return ar.Get(ctx, siteID, id)
+}
+
+// ListTrashed returns every trashed article of the site.
+func (ar *MemoryArticleRepository) ListTrashed(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Article, error) {
+	return ar.table.list(ctx, siteID, func(a models.Article) bool {
+		return a.DeletedAt != nil
+	}), nil
+}
+
+// ListTrashedBefore returns the articles (of any site) trashed before the given time.
+func (ar *MemoryArticleRepository) ListTrashedBefore(
+	ctx context.Context,
+	before time.Time,
+) ([]models.Article, error) {
+	ar.table.mu.RLock()
+	defer ar.table.mu.RUnlock()
+
+	articles := []models.Article{}
+	for _, id := range ar.table.order {
+		article := ar.table.rows[id]
+		if article.DeletedAt != nil && article.DeletedAt.Before(before) {
+			articles = append(articles, article)
+		}
+	}
+
+	return articles, nil
+}
+
+// ListExpired returns the published articles (of any site) which expired at the given
+// time.
+func (ar *MemoryArticleRepository) ListExpired(
+	ctx context.Context,
+	at time.Time,
+) ([]models.Article, error) {
+	ar.table.mu.RLock()
+	defer ar.table.mu.RUnlock()
+
+	articles := []models.Article{}
+	for _, id := range ar.table.order {
+		article := ar.table.rows[id]
+		if article.DeletedAt == nil && article.IsPublished &&
+			article.ExpiresAt != nil && !article.ExpiresAt.After(at) {
+			articles = append(articles, article)
+		}
+	}
+
+	return articles, nil
+}
+
+// ListScheduled returns the unpublished articles of the site whose publication was
+// scheduled at or before the given time.
+func (ar *MemoryArticleRepository) ListScheduled(
+	ctx context.Context,
+	siteID uuid.UUID,
+	at time.Time,
+) ([]models.Article, error) {
+	return ar.table.list(ctx, siteID, func(a models.Article) bool {
+		return a.DeletedAt == nil && !a.IsPublished &&
+			a.PublishAt != nil && !a.PublishAt.After(at)
+	}), nil
+}
+
+// Trash moves the article of the site identified by id to the trash, or returns
+// `ErrNotFound`.
+func (ar *MemoryArticleRepository) Trash(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+	at time.Time,
+) error {
+	return ar.setDeletedAt(siteID, id, &at)
+}
+
+// Restore restores the trashed article of the site identified by id, or returns
+// `ErrNotFound`.
+func (ar *MemoryArticleRepository) Restore(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return ar.setDeletedAt(siteID, id, nil)
+}
+
+// Create stores a new article in the site referenced by its `SiteID` field, or
+// returns `ErrConflict` if its short ID is already taken.
+func (ar *MemoryArticleRepository) Create(
+	ctx context.Context,
+	article models.Article,
+) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.taken(article) {
+		return ErrConflict
+	}
+
+	if err := ar.table.insert(ctx, article); err != nil {
+		return err
+	}
+	ar.index(models.Article{}, article)
+
+	return nil
+}
+
+// Update replaces an existing article of the site referenced by its `SiteID` field,
+// provided that its version follows the stored one.
+func (ar *MemoryArticleRepository) Update(
+	ctx context.Context,
+	article models.Article,
+) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	existing, err := ar.table.get(ctx, article.SiteID, article.ID)
+	if err != nil {
+		return err
+	}
+
+	if article.Version != existing.Version+1 {
+		return ErrStale
+	}
+
+	if ar.taken(article) {
+		return ErrConflict
+	}
+
+	if err := ar.table.update(ctx, article); err != nil {
+		return err
+	}
+	ar.index(existing, article)
+
+	return nil
+}
+
+// Delete removes the article of the site identified by id for good, whether it is
+// trashed or not, or returns `ErrNotFound`.
+func (ar *MemoryArticleRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	existing, err := ar.table.get(ctx, siteID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := ar.table.delete(ctx, siteID, id); err != nil {
+		return err
+	}
+	ar.index(existing, models.Article{})
+
+	return nil
+}
+
+// Replace replaces every article of the site, trashed or not, with the given ones at
+// once, and returns the articles it replaced.
+func (ar *MemoryArticleRepository) Replace(
+	ctx context.Context,
+	siteID uuid.UUID,
+	articles []models.Article,
+) ([]models.Article, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	shortIDs := make(map[string]bool, len(articles))
+	for _, article := range articles {
+		if article.ShortID == "" {
+			continue
+		}
+		if shortIDs[article.ShortID] {
+			return nil, ErrConflict
+		}
+		shortIDs[article.ShortID] = true
+	}
+
+	replaced, err := ar.table.replace(ctx, siteID, articles)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, article := range replaced {
+		ar.index(article, models.Article{})
+	}
+	for _, article := range articles {
+		ar.index(models.Article{}, article)
+	}
+
+	return replaced, nil
+}
+
+// taken reports whether another article of the site already has the short ID of the
+// article; ar.mu must be held.
+func (ar *MemoryArticleRepository) taken(article models.Article) bool {
+	if article.ShortID == "" {
+		return false
+	}
+
+	id, ok := ar.shortIDs[shortIDKey{siteID: article.SiteID, shortID: article.ShortID}]
+	return ok && id != article.ID
+}
+
+// index replaces the short ID of the previous version of an article by the one of its
+// next version in the index (either being the zero value when the article is created
+// or deleted); ar.mu must be held.
+func (ar *MemoryArticleRepository) index(previous, next models.Article) {
+	if previous.ShortID != "" {
+		key := shortIDKey{siteID: previous.SiteID, shortID: previous.ShortID}
+		delete(ar.shortIDs, key)
+	}
+
+	if next.ShortID != "" {
+		ar.shortIDs[shortIDKey{siteID: next.SiteID, shortID: next.ShortID}] = next.ID
+	}
+}
+
+// setDeletedAt moves the article of the site identified by id to the trash (when at
+// is set) or out of it, or returns `ErrNotFound` if the article is already there.
+func (ar *MemoryArticleRepository) setDeletedAt(
+	siteID, id uuid.UUID,
+	at *time.Time,
+) error {
+	ar.table.mu.Lock()
+	defer ar.table.mu.Unlock()
+
+	article, ok := ar.table.rows[id]
+	if !ok || article.SiteID != siteID || (article.DeletedAt == nil) == (at == nil) {
+		return ErrNotFound
+	}
+
+	article.DeletedAt = at
+	ar.table.rows[id] = article
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// AuditRepository defines the data access methods of the audit log.
+type AuditRepository interface {
+	// List returns every entry of the audit log of the site, oldest first.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.AuditEntry, error)
+
+	// Create appends a new entry to the audit log of the site referenced by its
+	// `SiteID` field.
+	Create(ctx context.Context, entry models.AuditEntry) error
+
+	// ListBefore returns the entries of the audit log of the site recorded before the
+	// given time, oldest first.
+	ListBefore(
+		ctx context.Context,
+		siteID uuid.UUID,
+		before time.Time,
+	) ([]models.AuditEntry, error)
+
+	// DeleteBefore removes the entries of the audit log of the site recorded before the
+	// given time, returning how many were removed.
+	DeleteBefore(ctx context.Context, siteID uuid.UUID, before time.Time) (int, error)
+}
+
+// MemoryAuditRepository is an in-memory implementation of AuditRepository.
+type MemoryAuditRepository struct {
+	table *table[models.AuditEntry]
+}
+
+// NewMemoryAuditRepository creates and returns a new empty MemoryAuditRepository.
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{
+		table: newTable(
+			func(e models.AuditEntry) uuid.UUID { return e.ID },
+			func(e models.AuditEntry) uuid.UUID { return e.SiteID },
+		),
+	}
+}
+
+// List returns every entry of the audit log of the site, oldest first.
+func (ar *MemoryAuditRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.AuditEntry, error) {
+	return ar.table.list(ctx, siteID, nil), nil
+}
+
+// Create appends a new entry to the audit log of the site referenced by its `SiteID`
+// field.
+func (ar *MemoryAuditRepository) Create(
+	ctx context.Context,
+	entry models.AuditEntry,
+) error {
+	return ar.table.insert(ctx, entry)
+}
+
+// ListBefore returns the entries of the audit log of the site recorded before the given
+// time, oldest first.
+func (ar *MemoryAuditRepository) ListBefore(
+	ctx context.Context,
+	siteID uuid.UUID,
+	before time.Time,
+) ([]models.AuditEntry, error) {
+	return ar.table.list(ctx, siteID, func(e models.AuditEntry) bool {
+		return e.At.Before(before)
+	}), nil
+}
+
+// DeleteBefore removes the entries of the audit log of the site recorded before the
+// given time, returning how many were removed.
+func (ar *MemoryAuditRepository) DeleteBefore(
+	ctx context.Context,
+	siteID uuid.UUID,
+	before time.Time,
+) (int, error) {
+	deleted := ar.table.deleteWhere(ctx, siteID, func(e models.AuditEntry) bool {
+		return e.At.Before(before)
+	})
+
+	return len(deleted), nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+)
+
+// CommentRepository defines the data access methods of the comments.
+type CommentRepository interface {
+	// List returns every comment of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Comment, error)
+
+	// ListByArticle returns the comments made on the article of the site.
+	ListByArticle(ctx context.Context, siteID, articleID uuid.UUID) ([]models.Comment, error)
+
+	// CountByArticle returns the number of comments made on each article of the site
+	// which has any, in a single pass over the comments.
+	CountByArticle(ctx context.Context, siteID uuid.UUID) (map[uuid.UUID]int, error)
+
+	// Get returns the comment of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Comment, error)
+
+	// Create stores a new comment in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, comment models.Comment) error
+
+	// Update replaces an existing comment of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound`.
+	Update(ctx context.Context, comment models.Comment) error
+
+	// Delete removes the comment of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+
+	// Replace replaces every comment of the site with the given ones at once, and
+	// returns the comments it replaced, or returns `ErrConflict` (changing nothing) if
+	// a comment of another site has the same identifier as one of them.
+	Replace(
+		ctx context.Context,
+		siteID uuid.UUID,
+		comments []models.Comment,
+	) ([]models.Comment, error)
+
+	// ReEncrypt encrypts again the personal data of the comments of the site which is
+	// not encrypted with the primary key, and returns the number of comments encrypted
+	// again.
+	ReEncrypt(ctx context.Context, siteID uuid.UUID) (int, error)
+}
+
+// MemoryCommentRepository is an in-memory implementation of CommentRepository.
+type MemoryCommentRepository struct {
+	table   *table[models.Comment]
+	keyring *encryption.Keyring
+}
+
+/*
+NewMemoryCommentRepository creates and returns a new empty MemoryCommentRepository.
+
+The email addresses of the commenters are stored encrypted with the keyring, like the
+ones of the users (see `NewMemoryUserRepository`).
+*/
+func NewMemoryCommentRepository(keyring *encryption.Keyring) *MemoryCommentRepository {
+	comments := newTable(
+		func(c models.Comment) uuid.UUID { return c.ID },
+		func(c models.Comment) uuid.UUID { return c.SiteID },
+	)
+
+	comments.seal = func(c models.Comment) (models.Comment, error) {
+		email, err := keyring.Encrypt(c.Email)
+		if err != nil {
+			return models.Comment{}, err
+		}
+
+		c.Email = email
+		return c, nil
+	}
+	comments.unseal = func(c models.Comment) models.Comment {
+		if email, err := keyring.Decrypt(c.Email); err == nil {
+			c.Email = email
+		}
+
+		return c
+	}
+
+	return &MemoryCommentRepository{table: comments, keyring: keyring}
+}
+
+// List returns every comment of the site.
+func (cr *MemoryCommentRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Comment, error) {
+	return cr.table.list(ctx, siteID, nil), nil
+}
+
+// ListByArticle returns the comments made on the article of the site.
+func (cr *MemoryCommentRepository) ListByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) ([]models.Comment, error) {
+	return cr.table.list(ctx, siteID, func(c models.Comment) bool {
+		return c.ArticleID == articleID
+	}), nil
+}
+
+// CountByArticle returns the number of comments made on each article of the site
+// which has any.
+func (cr *MemoryCommentRepository) CountByArticle(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (map[uuid.UUID]int, error) {
+	cr.table.mu.RLock()
+	defer cr.table.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int)
+	for _, id := range cr.table.order {
+		comment := cr.table.rows[id]
+		if comment.SiteID == siteID {
+			counts[comment.ArticleID]++
+		}
+	}
+
+	return counts, nil
+}
+
+// Get returns the comment of the site identified by id, or `ErrNotFound`.
+func (cr *MemoryCommentRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Comment, error) {
+	return cr.table.get(ctx, siteID, id)
+}
+
+// Create stores a new comment in the site referenced by its `SiteID` field.
+func (cr *MemoryCommentRepository) Create(
+	ctx context.Context,
+	comment models.Comment,
+) error {
+	return cr.table.insert(ctx, comment)
+}
+
+// Update replaces an existing comment of the site referenced by its `SiteID` field.
+func (cr *MemoryCommentRepository) Update(
+	ctx context.Context,
+	comment models.Comment,
+) error {
+	return cr.table.update(ctx, comment)
+}
+
+// Delete removes the comment of the site identified by id, or returns `ErrNotFound`.
+func (cr *MemoryCommentRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return cr.table.delete(ctx, siteID, id)
+}
+
+// Replace replaces every comment of the site with the given ones at once, and returns
+// the comments it replaced.
+func (cr *MemoryCommentRepository) Replace(
+	ctx context.Context,
+	siteID uuid.UUID,
+	comments []models.Comment,
+) ([]models.Comment, error) {
+	return cr.table.replace(ctx, siteID, comments)
+}
+
+// ReEncrypt encrypts again the email addresses of the commenters of the site which
+// are not encrypted with the primary key of the keyring.
+func (cr *MemoryCommentRepository) ReEncrypt(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (int, error) {
+	return cr.table.reseal(ctx, siteID, func(c models.Comment) bool {
+		return cr.keyring.Stale(c.Email)
+	})
+}
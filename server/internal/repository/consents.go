@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+)
+
+// ConsentRepository defines the data access methods of the consents of the visitors
+// to the processing of their personal data.
+type ConsentRepository interface {
+	// ListByEmail returns the consents of the site given with the email address,
+	// whatever its case.
+	ListByEmail(
+		ctx context.Context,
+		siteID uuid.UUID,
+		email string,
+	) ([]models.Consent, error)
+
+	// Create stores a new consent in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, consent models.Consent) error
+
+	// Update replaces an existing consent of the site referenced by its `SiteID` field,
+	// or returns `ErrNotFound`.
+	Update(ctx context.Context, consent models.Consent) error
+
+	// ReEncrypt encrypts again the personal data of the consents of the site which is
+	// not encrypted with the primary key, and returns the number of consents encrypted
+	// again.
+	ReEncrypt(ctx context.Context, siteID uuid.UUID) (int, error)
+}
+
+// MemoryConsentRepository is an in-memory implementation of ConsentRepository.
+type MemoryConsentRepository struct {
+	table   *table[models.Consent]
+	keyring *encryption.Keyring
+}
+
+/*
+NewMemoryConsentRepository creates and returns a new empty MemoryConsentRepository.
+
+The email addresses and the IP addresses of the visitors are stored encrypted with the
+keyring, like the email addresses of the users (see `NewMemoryUserRepository`).
+*/
+func NewMemoryConsentRepository(keyring *encryption.Keyring) *MemoryConsentRepository {
+	consents := newTable(
+		func(c models.Consent) uuid.UUID { return c.ID },
+		func(c models.Consent) uuid.UUID { return c.SiteID },
+	)
+
+	consents.seal = func(c models.Consent) (models.Consent, error) {
+		email, err := keyring.Encrypt(c.Email)
+		if err != nil {
+			return models.Consent{}, err
+		}
+
+		ip, err := keyring.Encrypt(c.IP)
+		if err != nil {
+			return models.Consent{}, err
+		}
+
+		c.Email, c.IP = email, ip
+		return c, nil
+	}
+	consents.unseal = func(c models.Consent) models.Consent {
+		if email, err := keyring.Decrypt(c.Email); err == nil {
+			c.Email = email
+		}
+		if ip, err := keyring.Decrypt(c.IP); err == nil {
+			c.IP = ip
+		}
+
+		return c
+	}
+
+	return &MemoryConsentRepository{table: consents, keyring: keyring}
+}
+
+// ListByEmail returns the consents of the site given with the email address, whatever
+// its case.
+func (cr *MemoryConsentRepository) ListByEmail(
+	ctx context.Context,
+	siteID uuid.UUID,
+	email string,
+) ([]models.Consent, error) {
+	return cr.table.list(ctx, siteID, func(c models.Consent) bool {
+		return strings.EqualFold(c.Email, email)
+	}), nil
+}
+
+// Create stores a new consent in the site referenced by its `SiteID` field.
+func (cr *MemoryConsentRepository) Create(
+	ctx context.Context,
+	consent models.Consent,
+) error {
+	return cr.table.insert(ctx, consent)
+}
+
+// Update replaces an existing consent of the site referenced by its `SiteID` field,
+// or returns `ErrNotFound`.
+func (cr *MemoryConsentRepository) Update(
+	ctx context.Context,
+	consent models.Consent,
+) error {
+	return cr.table.update(ctx, consent)
+}
+
+// ReEncrypt encrypts again the email addresses and the IP addresses of the consents of
+// the site which are not encrypted with the primary key of the keyring.
+func (cr *MemoryConsentRepository) ReEncrypt(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (int, error) {
+	return cr.table.reseal(ctx, siteID, func(c models.Consent) bool {
+		return cr.keyring.Stale(c.Email) || cr.keyring.Stale(c.IP)
+	})
+}
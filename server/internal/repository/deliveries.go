@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// maxDeliveries is the number of delivery attempts of each webhook kept by a
+// MemoryWebhookDeliveryRepository.
+const maxDeliveries = 1000
+
+// WebhookDeliveryRepository defines the data access methods of the delivery attempts
+// of the outbound webhooks.
+type WebhookDeliveryRepository interface {
+	// List returns the delivery attempts of the webhook of the site, most recent first.
+	List(
+		ctx context.Context,
+		siteID, webhookID uuid.UUID,
+	) ([]models.WebhookDelivery, error)
+
+	// Get returns the delivery attempt of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.WebhookDelivery, error)
+
+	// Create stores a new delivery attempt in the site referenced by its `SiteID`
+	// field.
+	Create(ctx context.Context, delivery models.WebhookDelivery) error
+
+	// DeleteByWebhook removes every delivery attempt of the webhook of the site.
+	DeleteByWebhook(ctx context.Context, siteID, webhookID uuid.UUID) error
+}
+
+// MemoryWebhookDeliveryRepository is an in-memory implementation of
+// WebhookDeliveryRepository.
+type MemoryWebhookDeliveryRepository struct {
+	mu    sync.Mutex // Serializes the writes, so that the oldest attempts are dropped
+	table *table[models.WebhookDelivery]
+}
+
+// NewMemoryWebhookDeliveryRepository creates and returns a new empty
+// MemoryWebhookDeliveryRepository, keeping the last 1000 attempts of each webhook.
+func NewMemoryWebhookDeliveryRepository() *MemoryWebhookDeliveryRepository {
+	return &MemoryWebhookDeliveryRepository{
+		table: newTable(
+			func(d models.WebhookDelivery) uuid.UUID { return d.ID },
+			func(d models.WebhookDelivery) uuid.UUID { return d.SiteID },
+		),
+	}
+}
+
+// List returns the delivery attempts of the webhook of the site, most recent first.
+func (dr *MemoryWebhookDeliveryRepository) List(
+	ctx context.Context,
+	siteID, webhookID uuid.UUID,
+) ([]models.WebhookDelivery, error) {
+	deliveries := dr.table.list(ctx, siteID, func(d models.WebhookDelivery) bool {
+		return d.WebhookID == webhookID
+	})
+	slices.Reverse(deliveries)
+
+	return deliveries, nil
+}
+
+// Get returns the delivery attempt of the site identified by id, or `ErrNotFound`.
+func (dr *MemoryWebhookDeliveryRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.WebhookDelivery, error) {
+	return dr.table.get(ctx, siteID, id)
+}
+
+// Create stores a new delivery attempt in the site referenced by its `SiteID` field,
+// dropping the oldest attempt of its webhook if it already has 1000 of them.
+func (dr *MemoryWebhookDeliveryRepository) Create(
+	ctx context.Context,
+	delivery models.WebhookDelivery,
+) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	if err := dr.table.insert(ctx, delivery); err != nil {
+		return err
+	}
+
+	deliveries := dr.table.list(
+		ctx,
+		delivery.SiteID,
+		func(d models.WebhookDelivery) bool {
+			return d.WebhookID == delivery.WebhookID
+		},
+	)
+	if len(deliveries) <= maxDeliveries {
+		return nil
+	}
+
+	oldest := deliveries[:len(deliveries)-maxDeliveries]
+	dr.table.deleteWhere(ctx, delivery.SiteID, func(d models.WebhookDelivery) bool {
+		return slices.ContainsFunc(oldest, func(o models.WebhookDelivery) bool {
+			return o.ID == d.ID
+		})
+	})
+
+	return nil
+}
+
+// DeleteByWebhook removes every delivery attempt of the webhook of the site.
+func (dr *MemoryWebhookDeliveryRepository) DeleteByWebhook(
+	ctx context.Context,
+	siteID, webhookID uuid.UUID,
+) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	dr.table.deleteWhere(ctx, siteID, func(d models.WebhookDelivery) bool {
+		return d.WebhookID == webhookID
+	})
+
+	return nil
+}
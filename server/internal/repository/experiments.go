@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// ExperimentRepository defines the data access methods of the A/B experiments.
+type ExperimentRepository interface {
+	// List returns every experiment of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Experiment, error)
+
+	// Get returns the experiment of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Experiment, error)
+
+	// GetByKey returns the experiment of the site identified by its key, or
+	// `ErrNotFound`.
+	GetByKey(
+		ctx context.Context,
+		siteID uuid.UUID,
+		key string,
+	) (models.Experiment, error)
+
+	// Create stores a new experiment in the site referenced by its `SiteID` field, or
+	// returns `ErrConflict` if its key is already taken.
+	Create(ctx context.Context, experiment models.Experiment) error
+
+	// Update replaces an existing experiment of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound` (or `ErrConflict` if its key is already taken by
+	// another experiment).
+	Update(ctx context.Context, experiment models.Experiment) error
+
+	// Delete removes the experiment of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryExperimentRepository is an in-memory implementation of ExperimentRepository.
+type MemoryExperimentRepository struct {
+	mu    sync.Mutex // Serializes the writes, so that the keys remain unique
+	table *table[models.Experiment]
+}
+
+// NewMemoryExperimentRepository creates and returns a new empty
+// MemoryExperimentRepository.
+func NewMemoryExperimentRepository() *MemoryExperimentRepository {
+	return &MemoryExperimentRepository{
+		table: newTable(
+			func(e models.Experiment) uuid.UUID { return e.ID },
+			func(e models.Experiment) uuid.UUID { return e.SiteID },
+		),
+	}
+}
+
+// List returns every experiment of the site.
+func (er *MemoryExperimentRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Experiment, error) {
+	return er.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the experiment of the site identified by id, or `ErrNotFound`.
+func (er *MemoryExperimentRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Experiment, error) {
+	return er.table.get(ctx, siteID, id)
+}
+
+// GetByKey returns the experiment of the site identified by its key, or
+// `ErrNotFound`.
+func (er *MemoryExperimentRepository) GetByKey(
+	ctx context.Context,
+	siteID uuid.UUID,
+	key string,
+) (models.Experiment, error) {
+	experiments := er.table.list(ctx, siteID, func(e models.Experiment) bool {
+		return e.Key == key
+	})
+	if len(experiments) == 0 {
+		return models.Experiment{}, ErrNotFound
+	}
+
+	return experiments[0], nil
+}
+
+// Create stores a new experiment in the site referenced by its `SiteID` field, or
+// returns `ErrConflict` if its key is already taken.
+func (er *MemoryExperimentRepository) Create(
+	ctx context.Context,
+	experiment models.Experiment,
+) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	if er.taken(ctx, experiment) {
+		return ErrConflict
+	}
+
+	return er.table.insert(ctx, experiment)
+}
+
+// Update replaces an existing experiment of the site referenced by its `SiteID` field.
+func (er *MemoryExperimentRepository) Update(
+	ctx context.Context,
+	experiment models.Experiment,
+) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	if _, err := er.table.get(ctx, experiment.SiteID, experiment.ID); err != nil {
+		return err
+	}
+
+	if er.taken(ctx, experiment) {
+		return ErrConflict
+	}
+
+	return er.table.update(ctx, experiment)
+}
+
+// Delete removes the experiment of the site identified by id, or returns
+// `ErrNotFound`.
+func (er *MemoryExperimentRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return er.table.delete(ctx, siteID, id)
+}
+
+// taken reports whether another experiment of the site already has the key of the
+// experiment; er.mu must be held.
+func (er *MemoryExperimentRepository) taken(
+	ctx context.Context,
+	experiment models.Experiment,
+) bool {
+	others := er.table.list(ctx, experiment.SiteID, func(e models.Experiment) bool {
+		return e.ID != experiment.ID && e.Key == experiment.Key
+	})
+
+	return len(others) > 0
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// EditLockRepository defines the data access methods of the edit locks of the
+// articles.
+type EditLockRepository interface {
+	// GetByArticle returns the lock of the article of the site, expired or not, or
+	// `ErrNotFound`.
+	GetByArticle(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+	) (models.EditLock, error)
+
+	// Create stores a new lock in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, lock models.EditLock) error
+
+	// Update replaces an existing lock of the site referenced by its `SiteID` field, or
+	// returns `ErrNotFound`.
+	Update(ctx context.Context, lock models.EditLock) error
+
+	// DeleteByArticle removes the lock of the article of the site, if any.
+	DeleteByArticle(ctx context.Context, siteID, articleID uuid.UUID) error
+}
+
+// MemoryEditLockRepository is an in-memory implementation of EditLockRepository.
+type MemoryEditLockRepository struct {
+	table *table[models.EditLock]
+}
+
+// NewMemoryEditLockRepository creates and returns a new empty
+// MemoryEditLockRepository.
+func NewMemoryEditLockRepository() *MemoryEditLockRepository {
+	return &MemoryEditLockRepository{
+		table: newTable(
+			func(l models.EditLock) uuid.UUID { return l.ID },
+			func(l models.EditLock) uuid.UUID { return l.SiteID },
+		),
+	}
+}
+
+// GetByArticle returns the lock of the article of the site, expired or not, or
+// `ErrNotFound`.
+func (lr *MemoryEditLockRepository) GetByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) (models.EditLock, error) {
+	locks := lr.table.list(ctx, siteID, func(l models.EditLock) bool {
+		return l.ArticleID == articleID
+	})
+	if len(locks) == 0 {
+		return models.EditLock{}, ErrNotFound
+	}
+
+	return locks[0], nil
+}
+
+// Create stores a new lock in the site referenced by its `SiteID` field.
+func (lr *MemoryEditLockRepository) Create(
+	ctx context.Context,
+	lock models.EditLock,
+) error {
+	return lr.table.insert(ctx, lock)
+}
+
+// Update replaces an existing lock of the site referenced by its `SiteID` field, or
+// returns `ErrNotFound`.
+func (lr *MemoryEditLockRepository) Update(
+	ctx context.Context,
+	lock models.EditLock,
+) error {
+	return lr.table.update(ctx, lock)
+}
+
+// DeleteByArticle removes the lock of the article of the site, if any.
+func (lr *MemoryEditLockRepository) DeleteByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) error {
+	lock, err := lr.GetByArticle(ctx, siteID, articleID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return lr.table.delete(ctx, siteID, lock.ID)
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/markdown"
+)
+
+// DefaultSiteSlug is the slug of the site seeded by `NewMemoryStore`.
+const DefaultSiteSlug = "default"
+
+/*
+NewMemoryStore creates and returns a new Store backed by the in-memory repositories.
+
+The store is seeded with a default site (served on `localhost`) holding a few sample
+users, articles (along with their first revision) and comments, so that the API
+returns meaningful data during development. The personal data of the users, of the commenters and of their consents
+is encrypted with the keyring, if not nil (see the `encryption` package).
+*/
+func NewMemoryStore(keyring *encryption.Keyring) *Store {
+	return NewMemoryStoreWith(ids.UUIDv7{}, time.Now().UTC(), keyring)
+}
+
+/*
+NewMemoryStoreWith creates and returns a new Store backed by the in-memory repositories
+and seeded like `NewMemoryStore` does, with the identifiers of the given generator and
+stamped with the given time. The personal data is encrypted with the keyring, if not
+nil.
+
+Along with an `ids.Sequential` generator, the seeded data is the same on every call,
+e.g. for the tests asserting the exact responses of the handlers.
+*/
+func NewMemoryStoreWith(
+	generator ids.Generator,
+	now time.Time,
+	keyring *encryption.Keyring,
+) *Store {
+	store := &Store{
+		Sites:         NewMemorySiteRepository(),
+		Articles:      NewMemoryArticleRepository(),
+		Users:         NewMemoryUserRepository(keyring),
+		Comments:      NewMemoryCommentRepository(keyring),
+		APIKeys:       NewMemoryAPIKeyRepository(),
+		Usage:         NewMemoryUsageRepository(),
+		Audit:         NewMemoryAuditRepository(),
+		Analytics:     NewMemoryAnalyticsRepository(),
+		Redirects:     NewMemoryRedirectRepository(),
+		ShareLinks:    NewMemoryShareLinkRepository(),
+		Pages:         NewMemoryPageRepository(),
+		Menus:         NewMemoryMenuRepository(),
+		Templates:     NewMemoryTemplateBundleRepository(),
+		Revisions:     NewMemoryRevisionRepository(),
+		Reviews:       NewMemoryReviewRepository(),
+		Subscriptions: NewMemorySubscriptionRepository(),
+		Notifications: NewMemoryNotificationRepository(),
+		Mentions:      NewMemoryMentionRepository(),
+		EditLocks:     NewMemoryEditLockRepository(),
+		Webmentions:   NewMemoryWebmentionRepository(),
+		Experiments:   NewMemoryExperimentRepository(),
+		Consents:      NewMemoryConsentRepository(keyring),
+		Policies:      NewMemoryPolicyRepository(),
+		Webhooks:      NewMemoryWebhookRepository(),
+		Deliveries:    NewMemoryWebhookDeliveryRepository(),
+		Keyring:       keyring,
+	}
+
+	seed(context.Background(), store, generator, now)
+
+	return store
+}
+
+// seed populates the store with the sample data of the default site, identified by
+// the given generator and stamped with the given time.
+func seed(ctx context.Context, store *Store, generator ids.Generator, now time.Time) {
+	site := models.Site{
+		ID:        generator.NewID(),
+		Name:      "BurzContent",
+		Slug:      DefaultSiteSlug,
+		Hostnames: []string{"localhost", "127.0.0.1"},
+	}
+	_ = store.Sites.Create(ctx, site)
+
+	users := []models.User{
+		{
+			Name:  "Somraj Saha",
+			Email: "somraj.saha@weburz.com",
+			Role:  auth.RoleAdmin,
+		},
+		{Name: "John Doe", Email: "john.doe@example.com", Role: auth.RoleAuthor},
+		{
+			Name:  "Sagar Kapoor",
+			Email: "sagar.kapoor@weburz.com",
+			Role:  auth.RoleEditor,
+		},
+	}
+	for i := range users {
+		users[i].ID = generator.NewID()
+		users[i].SiteID = site.ID
+		users[i].CreatedAt, users[i].UpdatedAt = now, now
+		_ = store.Users.Create(ctx, users[i])
+	}
+
+	articles := []models.Article{
+		{
+			Title:       "Go Programming Basics",
+			Author:      "John Doe",
+			AuthorID:    &users[1].ID,
+			IsPublished: true,
+		},
+		{Title: "Advanced Go Techniques", Author: "Jane Smith", IsPublished: false},
+		{Title: "Understanding Go Concurrency", Author: "Alice Johnson", IsPublished: true},
+	}
+	for i := range articles {
+		articles[i].ID = generator.NewID()
+		articles[i].SiteID = site.ID
+		articles[i].CreatedAt, articles[i].UpdatedAt = now, now
+		articles[i].Version = 1
+		_ = store.Articles.Create(ctx, articles[i])
+	}
+
+	comments := []models.Comment{
+		{
+			Name:    "John Doe",
+			Email:   "john.doe@example.com",
+			Content: "This is a great article",
+		},
+		{
+			Name:    "Jane Smith",
+			Email:   "jane.smith@example.com",
+			Content: "I found this article really helpful, thanks!",
+		},
+		{
+			Name:    "Alice Johnson",
+			Email:   "alice.johnson@example.com",
+			Content: "Interesting perspective, I learned a lot!",
+		},
+		{
+			Name:    "Somraj Saha",
+			Email:   "somraj.saha@weburz.com",
+			Content: "This is a test comment for experimental purposes ONLY!",
+		},
+	}
+	for _, comment := range comments {
+		comment.ID = generator.NewID()
+		comment.SiteID = site.ID
+		comment.CreatedAt, comment.UpdatedAt = now, now
+		comment.ArticleID = articles[0].ID
+		comment.RenderedHTML = markdown.ToHTML(comment.Content)
+		_ = store.Comments.Create(ctx, comment)
+	}
+
+	// Record the first revision of the articles, as if they were created through the
+	// API, so that their later versions can be compared with it
+	for _, article := range articles {
+		changes := []models.FieldChange{
+			{Field: "title", Old: "", New: article.Title, Summary: "Title changed"},
+			{Field: "author", Old: "", New: article.Author, Summary: "Author changed"},
+		}
+		if article.IsPublished {
+			changes = append(changes, models.FieldChange{
+				Field:   "isPublished",
+				Old:     false,
+				New:     true,
+				Summary: "Published",
+			})
+		}
+
+		_ = store.Revisions.Create(ctx, models.Revision{
+			ID:        generator.NewID(),
+			SiteID:    site.ID,
+			ArticleID: article.ID,
+			Number:    article.Version,
+			CreatedAt: now,
+			Changes:   changes,
+			Article:   article,
+		})
+	}
+}
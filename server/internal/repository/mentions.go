@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// MentionRepository defines the data access methods of the mentions of the users in
+// the comments.
+type MentionRepository interface {
+	// ListByUser returns the mentions of the user of the site, oldest first.
+	ListByUser(ctx context.Context, siteID, userID uuid.UUID) ([]models.Mention, error)
+
+	// ListByComment returns the mentions made in the comment of the site.
+	ListByComment(
+		ctx context.Context,
+		siteID, commentID uuid.UUID,
+	) ([]models.Mention, error)
+
+	// Create stores a new mention in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, mention models.Mention) error
+
+	// DeleteByComment removes every mention made in the comment of the site.
+	DeleteByComment(ctx context.Context, siteID, commentID uuid.UUID) error
+}
+
+// MemoryMentionRepository is an in-memory implementation of MentionRepository.
+type MemoryMentionRepository struct {
+	table *table[models.Mention]
+}
+
+// NewMemoryMentionRepository creates and returns a new empty MemoryMentionRepository.
+func NewMemoryMentionRepository() *MemoryMentionRepository {
+	return &MemoryMentionRepository{
+		table: newTable(
+			func(m models.Mention) uuid.UUID { return m.ID },
+			func(m models.Mention) uuid.UUID { return m.SiteID },
+		),
+	}
+}
+
+// ListByUser returns the mentions of the user of the site, oldest first.
+func (mr *MemoryMentionRepository) ListByUser(
+	ctx context.Context,
+	siteID, userID uuid.UUID,
+) ([]models.Mention, error) {
+	return mr.table.list(ctx, siteID, func(m models.Mention) bool {
+		return m.UserID == userID
+	}), nil
+}
+
+// ListByComment returns the mentions made in the comment of the site.
+func (mr *MemoryMentionRepository) ListByComment(
+	ctx context.Context,
+	siteID, commentID uuid.UUID,
+) ([]models.Mention, error) {
+	return mr.table.list(ctx, siteID, func(m models.Mention) bool {
+		return m.CommentID == commentID
+	}), nil
+}
+
+// Create stores a new mention in the site referenced by its `SiteID` field.
+func (mr *MemoryMentionRepository) Create(
+	ctx context.Context,
+	mention models.Mention,
+) error {
+	return mr.table.insert(ctx, mention)
+}
+
+// DeleteByComment removes every mention made in the comment of the site.
+func (mr *MemoryMentionRepository) DeleteByComment(
+	ctx context.Context,
+	siteID, commentID uuid.UUID,
+) error {
+	mentions, _ := mr.ListByComment(ctx, siteID, commentID)
+	for _, mention := range mentions {
+		if err := mr.table.delete(ctx, siteID, mention.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
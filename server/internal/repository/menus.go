@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// MenuRepository defines the data access methods of the menus.
+type MenuRepository interface {
+	// List returns every menu of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Menu, error)
+
+	// Get returns the menu of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Menu, error)
+
+	// GetByHandle returns the menu of the site with the given handle, or `ErrNotFound`.
+	GetByHandle(
+		ctx context.Context,
+		siteID uuid.UUID,
+		handle string,
+	) (models.Menu, error)
+
+	// Create stores a new menu in the site referenced by its `SiteID` field, or
+	// returns `ErrConflict` if its handle is already taken.
+	Create(ctx context.Context, menu models.Menu) error
+
+	// Update replaces an existing menu of the site referenced by its `SiteID` field,
+	// or returns `ErrNotFound` (or `ErrConflict` if its handle is already taken by
+	// another menu).
+	Update(ctx context.Context, menu models.Menu) error
+
+	// Delete removes the menu of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryMenuRepository is an in-memory implementation of MenuRepository.
+type MemoryMenuRepository struct {
+	mu    sync.Mutex // Serializes the writes, so that the handles remain unique
+	table *table[models.Menu]
+}
+
+// NewMemoryMenuRepository creates and returns a new empty MemoryMenuRepository.
+func NewMemoryMenuRepository() *MemoryMenuRepository {
+	return &MemoryMenuRepository{
+		table: newTable(
+			func(m models.Menu) uuid.UUID { return m.ID },
+			func(m models.Menu) uuid.UUID { return m.SiteID },
+		),
+	}
+}
+
+// List returns every menu of the site.
+func (mr *MemoryMenuRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Menu, error) {
+	return mr.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the menu of the site identified by id, or `ErrNotFound`.
+func (mr *MemoryMenuRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Menu, error) {
+	return mr.table.get(ctx, siteID, id)
+}
+
+// GetByHandle returns the menu of the site with the given handle, or `ErrNotFound`.
+func (mr *MemoryMenuRepository) GetByHandle(
+	ctx context.Context,
+	siteID uuid.UUID,
+	handle string,
+) (models.Menu, error) {
+	menus := mr.table.list(ctx, siteID, func(m models.Menu) bool {
+		return m.Handle == handle
+	})
+	if len(menus) == 0 {
+		return models.Menu{}, ErrNotFound
+	}
+
+	return menus[0], nil
+}
+
+// Create stores a new menu in the site referenced by its `SiteID` field, or returns
+// `ErrConflict` if its handle is already taken.
+func (mr *MemoryMenuRepository) Create(
+	ctx context.Context,
+	menu models.Menu,
+) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if mr.taken(ctx, menu) {
+		return ErrConflict
+	}
+
+	return mr.table.insert(ctx, menu)
+}
+
+// Update replaces an existing menu of the site referenced by its `SiteID` field.
+func (mr *MemoryMenuRepository) Update(
+	ctx context.Context,
+	menu models.Menu,
+) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, err := mr.table.get(ctx, menu.SiteID, menu.ID); err != nil {
+		return err
+	}
+
+	if mr.taken(ctx, menu) {
+		return ErrConflict
+	}
+
+	return mr.table.update(ctx, menu)
+}
+
+// Delete removes the menu of the site identified by id, or returns `ErrNotFound`.
+func (mr *MemoryMenuRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return mr.table.delete(ctx, siteID, id)
+}
+
+// taken reports whether another menu of the site already has the handle of the menu;
+// mr.mu must be held.
+func (mr *MemoryMenuRepository) taken(ctx context.Context, menu models.Menu) bool {
+	others := mr.table.list(ctx, menu.SiteID, func(m models.Menu) bool {
+		return m.ID != menu.ID && m.Handle == menu.Handle
+	})
+
+	return len(others) > 0
+}
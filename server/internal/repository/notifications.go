@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// NotificationRepository defines the data access methods of the in-app notifications
+// of the users.
+type NotificationRepository interface {
+	// ListByUser returns the notifications of the user of the site, oldest first.
+	ListByUser(
+		ctx context.Context,
+		siteID, userID uuid.UUID,
+	) ([]models.Notification, error)
+
+	// Create stores a new notification in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, notification models.Notification) error
+}
+
+// MemoryNotificationRepository is an in-memory implementation of
+// NotificationRepository.
+type MemoryNotificationRepository struct {
+	table *table[models.Notification]
+}
+
+// NewMemoryNotificationRepository creates and returns a new empty
+// MemoryNotificationRepository.
+func NewMemoryNotificationRepository() *MemoryNotificationRepository {
+	return &MemoryNotificationRepository{
+		table: newTable(
+			func(n models.Notification) uuid.UUID { return n.ID },
+			func(n models.Notification) uuid.UUID { return n.SiteID },
+		),
+	}
+}
+
+// ListByUser returns the notifications of the user of the site, oldest first.
+func (nr *MemoryNotificationRepository) ListByUser(
+	ctx context.Context,
+	siteID, userID uuid.UUID,
+) ([]models.Notification, error) {
+	return nr.table.list(ctx, siteID, func(n models.Notification) bool {
+		return n.UserID == userID
+	}), nil
+}
+
+// Create stores a new notification in the site referenced by its `SiteID` field.
+func (nr *MemoryNotificationRepository) Create(
+	ctx context.Context,
+	notification models.Notification,
+) error {
+	return nr.table.insert(ctx, notification)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// PageRepository defines the data access methods of the pages.
+type PageRepository interface {
+	// List returns every page of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Page, error)
+
+	// Get returns the page of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Page, error)
+
+	// GetByPath returns the page of the site with the given path, or `ErrNotFound`.
+	GetByPath(
+		ctx context.Context,
+		siteID uuid.UUID,
+		path string,
+	) (models.Page, error)
+
+	// Create stores a new page in the site referenced by its `SiteID` field, or
+	// returns `ErrConflict` if its path is already taken.
+	Create(ctx context.Context, page models.Page) error
+
+	// Update replaces an existing page of the site referenced by its `SiteID` field,
+	// or returns `ErrNotFound` (or `ErrConflict` if its path is already taken by
+	// another page).
+	Update(ctx context.Context, page models.Page) error
+
+	// Delete removes the page of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryPageRepository is an in-memory implementation of PageRepository.
+type MemoryPageRepository struct {
+	mu    sync.Mutex // Serializes the writes, so that the paths remain unique
+	table *table[models.Page]
+}
+
+// NewMemoryPageRepository creates and returns a new empty MemoryPageRepository.
+func NewMemoryPageRepository() *MemoryPageRepository {
+	return &MemoryPageRepository{
+		table: newTable(
+			func(p models.Page) uuid.UUID { return p.ID },
+			func(p models.Page) uuid.UUID { return p.SiteID },
+		),
+	}
+}
+
+// List returns every page of the site.
+func (pr *MemoryPageRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Page, error) {
+	return pr.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the page of the site identified by id, or `ErrNotFound`.
+func (pr *MemoryPageRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Page, error) {
+	return pr.table.get(ctx, siteID, id)
+}
+
+// GetByPath returns the page of the site with the given path, or `ErrNotFound`.
+func (pr *MemoryPageRepository) GetByPath(
+	ctx context.Context,
+	siteID uuid.UUID,
+	path string,
+) (models.Page, error) {
+	pages := pr.table.list(ctx, siteID, func(p models.Page) bool {
+		return p.Path == path
+	})
+	if len(pages) == 0 {
+		return models.Page{}, ErrNotFound
+	}
+
+	return pages[0], nil
+}
+
+// Create stores a new page in the site referenced by its `SiteID` field, or returns
+// `ErrConflict` if its path is already taken.
+func (pr *MemoryPageRepository) Create(
+	ctx context.Context,
+	page models.Page,
+) error {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if pr.taken(ctx, page) {
+		return ErrConflict
+	}
+
+	return pr.table.insert(ctx, page)
+}
+
+// Update replaces an existing page of the site referenced by its `SiteID` field.
+func (pr *MemoryPageRepository) Update(
+	ctx context.Context,
+	page models.Page,
+) error {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if _, err := pr.table.get(ctx, page.SiteID, page.ID); err != nil {
+		return err
+	}
+
+	if pr.taken(ctx, page) {
+		return ErrConflict
+	}
+
+	return pr.table.update(ctx, page)
+}
+
+// Delete removes the page of the site identified by id, or returns `ErrNotFound`.
+func (pr *MemoryPageRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return pr.table.delete(ctx, siteID, id)
+}
+
+// taken reports whether another page of the site already has the path of the page;
+// pr.mu must be held.
+func (pr *MemoryPageRepository) taken(ctx context.Context, page models.Page) bool {
+	others := pr.table.list(ctx, page.SiteID, func(p models.Page) bool {
+		return p.ID != page.ID && p.Path == page.Path
+	})
+
+	return len(others) > 0
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// PolicyRepository defines the data access methods of the policies of the sites and
+// of their acceptances by the users.
+type PolicyRepository interface {
+	// List returns every policy of the site, in the order of publication.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Policy, error)
+
+	// Get returns the policy of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Policy, error)
+
+	// Create stores a new policy in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, policy models.Policy) error
+
+	// ListAcceptances returns the acceptances of the policies of the site, in the
+	// order they were made, for which the keep function returns true (every acceptance
+	// if keep is nil).
+	ListAcceptances(
+		ctx context.Context,
+		siteID uuid.UUID,
+		keep func(models.PolicyAcceptance) bool,
+	) ([]models.PolicyAcceptance, error)
+
+	// CreateAcceptance stores a new acceptance of a policy in the site referenced by
+	// its `SiteID` field.
+	CreateAcceptance(ctx context.Context, acceptance models.PolicyAcceptance) error
+}
+
+// MemoryPolicyRepository is an in-memory implementation of PolicyRepository.
+type MemoryPolicyRepository struct {
+	policies    *table[models.Policy]
+	acceptances *table[models.PolicyAcceptance]
+}
+
+// NewMemoryPolicyRepository creates and returns a new empty MemoryPolicyRepository.
+func NewMemoryPolicyRepository() *MemoryPolicyRepository {
+	return &MemoryPolicyRepository{
+		policies: newTable(
+			func(p models.Policy) uuid.UUID { return p.ID },
+			func(p models.Policy) uuid.UUID { return p.SiteID },
+		),
+		acceptances: newTable(
+			func(a models.PolicyAcceptance) uuid.UUID { return a.ID },
+			func(a models.PolicyAcceptance) uuid.UUID { return a.SiteID },
+		),
+	}
+}
+
+// List returns every policy of the site, in the order of publication.
+func (pr *MemoryPolicyRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Policy, error) {
+	return pr.policies.list(ctx, siteID, nil), nil
+}
+
+// Get returns the policy of the site identified by id, or `ErrNotFound`.
+func (pr *MemoryPolicyRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Policy, error) {
+	return pr.policies.get(ctx, siteID, id)
+}
+
+// Create stores a new policy in the site referenced by its `SiteID` field.
+func (pr *MemoryPolicyRepository) Create(
+	ctx context.Context,
+	policy models.Policy,
+) error {
+	return pr.policies.insert(ctx, policy)
+}
+
+// ListAcceptances returns the acceptances of the policies of the site, in the order
+// they were made, for which the keep function returns true (every acceptance if keep
+// is nil).
+func (pr *MemoryPolicyRepository) ListAcceptances(
+	ctx context.Context,
+	siteID uuid.UUID,
+	keep func(models.PolicyAcceptance) bool,
+) ([]models.PolicyAcceptance, error) {
+	return pr.acceptances.list(ctx, siteID, keep), nil
+}
+
+// CreateAcceptance stores a new acceptance of a policy in the site referenced by its
+// `SiteID` field.
+func (pr *MemoryPolicyRepository) CreateAcceptance(
+	ctx context.Context,
+	acceptance models.PolicyAcceptance,
+) error {
+	return pr.acceptances.insert(ctx, acceptance)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// RedirectRepository defines the data access methods of the redirects.
+type RedirectRepository interface {
+	// List returns every redirect of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Redirect, error)
+
+	// Get returns the redirect of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Redirect, error)
+
+	// GetBySource returns the redirect of the site whose source is the path, or
+	// `ErrNotFound`.
+	GetBySource(
+		ctx context.Context,
+		siteID uuid.UUID,
+		source string,
+	) (models.Redirect, error)
+
+	// Create stores a new redirect in the site referenced by its `SiteID` field, or
+	// returns `ErrConflict` if its source is already redirected.
+	Create(ctx context.Context, redirect models.Redirect) error
+
+	// Update replaces an existing redirect of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound` (or `ErrConflict` if its source is already
+	// redirected by another redirect).
+	Update(ctx context.Context, redirect models.Redirect) error
+
+	// Delete removes the redirect of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryRedirectRepository is an in-memory implementation of RedirectRepository.
+type MemoryRedirectRepository struct {
+	mu    sync.Mutex // Serializes the writes, so that the sources remain unique
+	table *table[models.Redirect]
+}
+
+// NewMemoryRedirectRepository creates and returns a new empty
+// MemoryRedirectRepository.
+func NewMemoryRedirectRepository() *MemoryRedirectRepository {
+	return &MemoryRedirectRepository{
+		table: newTable(
+			func(r models.Redirect) uuid.UUID { return r.ID },
+			func(r models.Redirect) uuid.UUID { return r.SiteID },
+		),
+	}
+}
+
+// List returns every redirect of the site.
+func (rr *MemoryRedirectRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Redirect, error) {
+	return rr.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the redirect of the site identified by id, or `ErrNotFound`.
+func (rr *MemoryRedirectRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Redirect, error) {
+	return rr.table.get(ctx, siteID, id)
+}
+
+// GetBySource returns the redirect of the site whose source is the path, or
+// `ErrNotFound`.
+func (rr *MemoryRedirectRepository) GetBySource(
+	ctx context.Context,
+	siteID uuid.UUID,
+	source string,
+) (models.Redirect, error) {
+	redirects := rr.table.list(ctx, siteID, func(r models.Redirect) bool {
+		return r.Source == source
+	})
+	if len(redirects) == 0 {
+		return models.Redirect{}, ErrNotFound
+	}
+
+	return redirects[0], nil
+}
+
+// Create stores a new redirect in the site referenced by its `SiteID` field, or
+// returns `ErrConflict` if its source is already redirected.
+func (rr *MemoryRedirectRepository) Create(
+	ctx context.Context,
+	redirect models.Redirect,
+) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if rr.taken(ctx, redirect) {
+		return ErrConflict
+	}
+
+	return rr.table.insert(ctx, redirect)
+}
+
+// Update replaces an existing redirect of the site referenced by its `SiteID` field.
+func (rr *MemoryRedirectRepository) Update(
+	ctx context.Context,
+	redirect models.Redirect,
+) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if _, err := rr.table.get(ctx, redirect.SiteID, redirect.ID); err != nil {
+		return err
+	}
+
+	if rr.taken(ctx, redirect) {
+		return ErrConflict
+	}
+
+	return rr.table.update(ctx, redirect)
+}
+
+// Delete removes the redirect of the site identified by id, or returns `ErrNotFound`.
+func (rr *MemoryRedirectRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return rr.table.delete(ctx, siteID, id)
+}
+
+// taken reports whether another redirect of the site already redirects the source of
+// the redirect; rr.mu must be held.
+func (rr *MemoryRedirectRepository) taken(
+	ctx context.Context,
+	redirect models.Redirect,
+) bool {
+	others := rr.table.list(ctx, redirect.SiteID, func(r models.Redirect) bool {
+		return r.ID != redirect.ID && r.Source == redirect.Source
+	})
+
+	return len(others) > 0
+}
@@ -0,0 +1,385 @@
+/*
+Package repository provides the data access layer of the system.
+
+Every resource (articles, users, comments, etc.) is accessed through a repository
+interface, which keeps the services independent of the underlying storage. The package
+currently ships in-memory implementations of these interfaces, which are seeded with
+sample data by `NewMemoryStore`.
+
+Except for the sites themselves, every resource belongs to a single site. The
+repositories take the unique identifier of the site as an explicit argument of every
+method and never return, modify or delete a resource belonging to another site, which
+enforces the data isolation between the tenants in a single place.
+*/
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist (within the
+	// given site).
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrConflict is returned when a resource with the same identifier already exists.
+	ErrConflict = errors.New("resource already exists")
+
+	// ErrStale is returned when a resource is updated from another version of it than
+	// the current one (i.e. it was updated in the meantime).
+	ErrStale = errors.New("resource version is stale")
+)
+
+/*
+Store groups the repositories of every resource of the system.
+
+Fields:
+  - Sites: The repository of the sites (tenants).
+  - Articles: The repository of the articles.
+  - Users: The repository of the users.
+  - Comments: The repository of the comments.
+  - APIKeys: The repository of the API keys.
+  - Usage: The repository of the usage counters of the sites.
+  - Audit: The repository of the audit log of the management API.
+  - Analytics: The repository of the page views of the sites.
+  - Redirects: The repository of the redirects of the sites.
+  - ShareLinks: The repository of the share links of the articles.
+  - Pages: The repository of the static pages of the sites.
+  - Menus: The repository of the navigation menus of the sites.
+  - Templates: The repository of the template bundles of the sites.
+  - Revisions: The repository of the revisions of the articles.
+  - Reviews: The repository of the editorial reviews of the articles.
+  - Subscriptions: The repository of the subscriptions of the users to the comments
+    of the articles.
+  - Notifications: The repository of the in-app notifications of the users.
+  - Mentions: The repository of the mentions of the users in the comments.
+  - EditLocks: The repository of the locks of the articles being edited.
+  - Webmentions: The repository of the webmentions received by the articles.
+  - Experiments: The repository of the A/B experiments of the sites.
+  - Consents: The repository of the consents of the visitors to the processing of
+    their personal data.
+  - Policies: The repository of the policies (e.g. the terms of service) of the sites
+    and of their acceptances by the users.
+  - Webhooks: The repository of the outbound webhooks of the sites.
+  - Deliveries: The repository of the delivery attempts of the outbound webhooks.
+  - Keyring: The keyring the personal data is encrypted with by the repositories (nil
+    if it is stored in plain text), e.g. for the backups to hold it encrypted too.
+*/
+type Store struct {
+	Sites         SiteRepository
+	Articles      ArticleRepository
+	Users         UserRepository
+	Comments      CommentRepository
+	APIKeys       APIKeyRepository
+	Usage         UsageRepository
+	Audit         AuditRepository
+	Analytics     AnalyticsRepository
+	Redirects     RedirectRepository
+	ShareLinks    ShareLinkRepository
+	Pages         PageRepository
+	Menus         MenuRepository
+	Templates     TemplateBundleRepository
+	Revisions     RevisionRepository
+	Reviews       ReviewRepository
+	Subscriptions SubscriptionRepository
+	Notifications NotificationRepository
+	Mentions      MentionRepository
+	EditLocks     EditLockRepository
+	Webmentions   WebmentionRepository
+	Experiments   ExperimentRepository
+	Consents      ConsentRepository
+	Policies      PolicyRepository
+	Webhooks      WebhookRepository
+	Deliveries    WebhookDeliveryRepository
+	Keyring       *encryption.Keyring
+}
+
+/*
+table is a generic, concurrency-safe, in-memory table of site-scoped resources.
+
+The rows are kept in insertion order so that listings are stable. The id and site
+functions extract the unique identifier of a row and of the site it belongs to.
+
+The seal function, if any, transforms the rows before they are stored (e.g. to
+encrypt their personal data), and the unseal function transforms them back once read,
+so that the methods of the table only ever handle the rows as given.
+
+The time spent in the methods of the table, waiting for its lock included, is added to
+the store time of the request of their context (see `logger.AddStoreTime`).
+*/
+type table[T any] struct {
+	mu     sync.RWMutex
+	rows   map[uuid.UUID]T
+	order  []uuid.UUID
+	id     func(T) uuid.UUID
+	site   func(T) uuid.UUID
+	seal   func(T) (T, error)
+	unseal func(T) T
+}
+
+// newTable creates and returns a new empty table.
+func newTable[T any](id, site func(T) uuid.UUID) *table[T] {
+	return &table[T]{
+		rows: make(map[uuid.UUID]T),
+		id:   id,
+		site: site,
+	}
+}
+
+// observe adds the time elapsed since start to the store time of the request of the
+// context, if any.
+func observe(ctx context.Context, start time.Time) {
+	logger.AddStoreTime(ctx, time.Since(start))
+}
+
+// open returns the stored row as given, see `unseal`.
+func (t *table[T]) open(row T) T {
+	if t.unseal == nil {
+		return row
+	}
+
+	return t.unseal(row)
+}
+
+// close returns the row as stored, see `seal`.
+func (t *table[T]) close(row T) (T, error) {
+	if t.seal == nil {
+		return row, nil
+	}
+
+	return t.seal(row)
+}
+
+// list returns the rows of the site for which the keep function returns true (every
+// row of the site if keep is nil). Only the rows of the site are unsealed.
+func (t *table[T]) list(
+	ctx context.Context,
+	siteID uuid.UUID,
+	keep func(T) bool,
+) []T {
+	defer observe(ctx, time.Now())
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := []T{}
+	for _, id := range t.order {
+		stored := t.rows[id]
+		if t.site(stored) != siteID {
+			continue
+		}
+
+		if row := t.open(stored); keep == nil || keep(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// get returns the row of the site identified by id.
+func (t *table[T]) get(ctx context.Context, siteID, id uuid.UUID) (T, error) {
+	defer observe(ctx, time.Now())
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	row, ok := t.rows[id]
+	if !ok || t.site(row) != siteID {
+		var zero T
+		return zero, ErrNotFound
+	}
+
+	return t.open(row), nil
+}
+
+// insert stores a new row, failing if a row with the same identifier exists.
+func (t *table[T]) insert(ctx context.Context, row T) error {
+	defer observe(ctx, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := t.id(row)
+	if _, ok := t.rows[id]; ok {
+		return ErrConflict
+	}
+
+	row, err := t.close(row)
+	if err != nil {
+		return err
+	}
+
+	t.rows[id] = row
+	t.order = append(t.order, id)
+
+	return nil
+}
+
+// update replaces an existing row, failing if it does not exist within its site.
+func (t *table[T]) update(ctx context.Context, row T) error {
+	defer observe(ctx, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := t.id(row)
+	existing, ok := t.rows[id]
+	if !ok || t.site(existing) != t.site(row) {
+		return ErrNotFound
+	}
+
+	row, err := t.close(row)
+	if err != nil {
+		return err
+	}
+
+	t.rows[id] = row
+
+	return nil
+}
+
+// delete removes the row of the site identified by id.
+func (t *table[T]) delete(ctx context.Context, siteID, id uuid.UUID) error {
+	defer observe(ctx, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	row, ok := t.rows[id]
+	if !ok || t.site(row) != siteID {
+		return ErrNotFound
+	}
+
+	delete(t.rows, id)
+	t.order = slices.DeleteFunc(t.order, func(v uuid.UUID) bool { return v == id })
+
+	return nil
+}
+
+// deleteWhere removes the rows of the site for which the match function returns true,
+// in a single pass over the table, and returns them. Only the rows of the site are
+// unsealed.
+func (t *table[T]) deleteWhere(
+	ctx context.Context,
+	siteID uuid.UUID,
+	match func(T) bool,
+) []T {
+	defer observe(ctx, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	deleted := []T{}
+	t.order = slices.DeleteFunc(t.order, func(id uuid.UUID) bool {
+		stored := t.rows[id]
+		if t.site(stored) != siteID {
+			return false
+		}
+
+		row := t.open(stored)
+		if !match(row) {
+			return false
+		}
+
+		deleted = append(deleted, row)
+		delete(t.rows, id)
+
+		return true
+	})
+
+	return deleted
+}
+
+// reseal stores again the rows of the site for which the stale function returns true
+// (given the rows as stored), e.g. to encrypt them with a new key, and returns their
+// number.
+func (t *table[T]) reseal(
+	ctx context.Context,
+	siteID uuid.UUID,
+	stale func(T) bool,
+) (int, error) {
+	defer observe(ctx, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := 0
+	for _, id := range t.order {
+		stored := t.rows[id]
+		if t.site(stored) != siteID || !stale(stored) {
+			continue
+		}
+
+		row, err := t.close(t.open(stored))
+		if err != nil {
+			return count, err
+		}
+
+		t.rows[id] = row
+		count++
+	}
+
+	return count, nil
+}
+
+/*
+replace replaces every row of the site with the given rows at once, and returns the
+rows it replaced.
+
+Nothing is changed if any of the rows can not be stored, i.e. if two rows, or a row and
+a row of another site, have the same identifier (`ErrConflict`) or if a row can not be
+sealed.
+*/
+func (t *table[T]) replace(ctx context.Context, siteID uuid.UUID, rows []T) ([]T, error) {
+	defer observe(ctx, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[uuid.UUID]bool, len(rows))
+	sealed := make([]T, 0, len(rows))
+	for _, row := range rows {
+		id := t.id(row)
+		if existing, ok := t.rows[id]; seen[id] || ok && t.site(existing) != siteID {
+			return nil, ErrConflict
+		}
+		seen[id] = true
+
+		row, err := t.close(row)
+		if err != nil {
+			return nil, err
+		}
+		sealed = append(sealed, row)
+	}
+
+	replaced := []T{}
+	t.order = slices.DeleteFunc(t.order, func(id uuid.UUID) bool {
+		row := t.rows[id]
+		if t.site(row) != siteID {
+			return false
+		}
+
+		replaced = append(replaced, t.open(row))
+		delete(t.rows, id)
+
+		return true
+	})
+
+	for _, row := range sealed {
+		id := t.id(row)
+		t.rows[id] = row
+		t.order = append(t.order, id)
+	}
+
+	return replaced, nil
+}
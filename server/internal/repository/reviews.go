@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// ReviewRepository defines the data access methods of the reviews of the articles.
+type ReviewRepository interface {
+	// GetByArticle returns the review of the article of the site, or `ErrNotFound`.
+	GetByArticle(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+	) (models.Review, error)
+
+	// Create stores a new review in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, review models.Review) error
+
+	// Update replaces an existing review of the site referenced by its `SiteID` field,
+	// or returns `ErrNotFound`.
+	Update(ctx context.Context, review models.Review) error
+
+	// DeleteByArticle removes the review of the article of the site, if any.
+	DeleteByArticle(ctx context.Context, siteID, articleID uuid.UUID) error
+}
+
+// MemoryReviewRepository is an in-memory implementation of ReviewRepository.
+type MemoryReviewRepository struct {
+	table *table[models.Review]
+}
+
+// NewMemoryReviewRepository creates and returns a new empty MemoryReviewRepository.
+func NewMemoryReviewRepository() *MemoryReviewRepository {
+	return &MemoryReviewRepository{
+		table: newTable(
+			func(r models.Review) uuid.UUID { return r.ID },
+			func(r models.Review) uuid.UUID { return r.SiteID },
+		),
+	}
+}
+
+// GetByArticle returns the review of the article of the site, or `ErrNotFound`.
+func (rr *MemoryReviewRepository) GetByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) (models.Review, error) {
+	reviews := rr.table.list(ctx, siteID, func(r models.Review) bool {
+		return r.ArticleID == articleID
+	})
+	if len(reviews) == 0 {
+		return models.Review{}, ErrNotFound
+	}
+
+	return reviews[0], nil
+}
+
+// Create stores a new review in the site referenced by its `SiteID` field.
+func (rr *MemoryReviewRepository) Create(
+	ctx context.Context,
+	review models.Review,
+) error {
+	return rr.table.insert(ctx, review)
+}
+
+// Update replaces an existing review of the site referenced by its `SiteID` field, or
+// returns `ErrNotFound`.
+func (rr *MemoryReviewRepository) Update(
+	ctx context.Context,
+	review models.Review,
+) error {
+	return rr.table.update(ctx, review)
+}
+
+// DeleteByArticle removes the review of the article of the site, if any.
+func (rr *MemoryReviewRepository) DeleteByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) error {
+	review, err := rr.GetByArticle(ctx, siteID, articleID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return rr.table.delete(ctx, siteID, review.ID)
+}
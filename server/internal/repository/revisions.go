@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// RevisionRepository defines the data access methods of the revisions of the articles.
+type RevisionRepository interface {
+	// ListByArticle returns every revision of the article of the site, oldest first.
+	ListByArticle(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+	) ([]models.Revision, error)
+
+	// GetByNumber returns the revision of the article of the site with the given
+	// number, or `ErrNotFound`.
+	GetByNumber(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+		number int,
+	) (models.Revision, error)
+
+	// Create stores a new revision in the site referenced by its `SiteID` field, or
+	// returns `ErrConflict` if the article already has a revision with its number.
+	Create(ctx context.Context, revision models.Revision) error
+
+	// DeleteByArticle removes every revision of the article of the site.
+	DeleteByArticle(ctx context.Context, siteID, articleID uuid.UUID) error
+
+	// ListPrunable returns the revisions of every article of the site but its latest
+	// keep ones, i.e. the revisions `Prune` would remove, oldest first.
+	ListPrunable(
+		ctx context.Context,
+		siteID uuid.UUID,
+		keep int,
+	) ([]models.Revision, error)
+
+	// Prune removes the revisions of every article of the site but its latest keep
+	// ones, returning the removed revisions, oldest first.
+	Prune(ctx context.Context, siteID uuid.UUID, keep int) ([]models.Revision, error)
+}
+
+// MemoryRevisionRepository is an in-memory implementation of RevisionRepository.
+type MemoryRevisionRepository struct {
+	table *table[models.Revision]
+}
+
+// NewMemoryRevisionRepository creates and returns a new empty
+// MemoryRevisionRepository.
+func NewMemoryRevisionRepository() *MemoryRevisionRepository {
+	return &MemoryRevisionRepository{
+		table: newTable(
+			func(r models.Revision) uuid.UUID { return r.ID },
+			func(r models.Revision) uuid.UUID { return r.SiteID },
+		),
+	}
+}
+
+// ListByArticle returns every revision of the article of the site, oldest first.
+func (rr *MemoryRevisionRepository) ListByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) ([]models.Revision, error) {
+	return rr.table.list(ctx, siteID, func(r models.Revision) bool {
+		return r.ArticleID == articleID
+	}), nil
+}
+
+// GetByNumber returns the revision of the article of the site with the given number,
+// or `ErrNotFound`.
+func (rr *MemoryRevisionRepository) GetByNumber(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+	number int,
+) (models.Revision, error) {
+	revisions := rr.table.list(ctx, siteID, func(r models.Revision) bool {
+		return r.ArticleID == articleID && r.Number == number
+	})
+	if len(revisions) == 0 {
+		return models.Revision{}, ErrNotFound
+	}
+
+	return revisions[0], nil
+}
+
+// Create stores a new revision in the site referenced by its `SiteID` field, or
+// returns `ErrConflict` if the article already has a revision with its number.
+func (rr *MemoryRevisionRepository) Create(
+	ctx context.Context,
+	revision models.Revision,
+) error {
+	// The numbers follow the versions of the articles, whose updates are serialized
+	// by the article repository, so that no two revisions race for the same number
+	_, err := rr.GetByNumber(ctx, revision.SiteID, revision.ArticleID, revision.Number)
+	if err == nil {
+		return ErrConflict
+	}
+
+	return rr.table.insert(ctx, revision)
+}
+
+// DeleteByArticle removes every revision of the article of the site.
+func (rr *MemoryRevisionRepository) DeleteByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) error {
+	revisions, _ := rr.ListByArticle(ctx, siteID, articleID)
+	for _, revision := range revisions {
+		if err := rr.table.delete(ctx, siteID, revision.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListPrunable returns the revisions of every article of the site but its latest keep
+// ones, i.e. the revisions `Prune` would remove, oldest first.
+func (rr *MemoryRevisionRepository) ListPrunable(
+	ctx context.Context,
+	siteID uuid.UUID,
+	keep int,
+) ([]models.Revision, error) {
+	revisions := rr.table.list(ctx, siteID, nil)
+
+	// The revisions are listed in the order they were recorded, hence each revision
+	// is prunable if the article has keep revisions recorded after it
+	later := make(map[uuid.UUID]int)
+	prunable := []models.Revision{}
+	for _, revision := range slices.Backward(revisions) {
+		if later[revision.ArticleID] < keep {
+			later[revision.ArticleID]++
+			continue
+		}
+
+		prunable = append(prunable, revision)
+	}
+	slices.Reverse(prunable)
+
+	return prunable, nil
+}
+
+// Prune removes the revisions of every article of the site but its latest keep ones,
+// returning the removed revisions, oldest first.
+func (rr *MemoryRevisionRepository) Prune(
+	ctx context.Context,
+	siteID uuid.UUID,
+	keep int,
+) ([]models.Revision, error) {
+	prunable, err := rr.ListPrunable(ctx, siteID, keep)
+	if err != nil {
+		return []models.Revision{}, err
+	}
+
+	ids := make(map[uuid.UUID]bool, len(prunable))
+	for _, revision := range prunable {
+		ids[revision.ID] = true
+	}
+
+	// The revisions deleted in the meantime, along with their article, are skipped
+	return rr.table.deleteWhere(ctx, siteID, func(r models.Revision) bool {
+		return ids[r.ID]
+	}), nil
+}
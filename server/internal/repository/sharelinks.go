@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// ShareLinkRepository defines the data access methods of the share links.
+type ShareLinkRepository interface {
+	// ListByArticle returns every share link of the article of the site.
+	ListByArticle(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+	) ([]models.ShareLink, error)
+
+	// GetByHash returns the share link of the site with the given digest, or
+	// `ErrNotFound`.
+	GetByHash(
+		ctx context.Context,
+		siteID uuid.UUID,
+		hash string,
+	) (models.ShareLink, error)
+
+	// Create stores a new share link in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, link models.ShareLink) error
+
+	// Delete removes the share link of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryShareLinkRepository is an in-memory implementation of ShareLinkRepository.
+type MemoryShareLinkRepository struct {
+	table *table[models.ShareLink]
+}
+
+// NewMemoryShareLinkRepository creates and returns a new empty
+// MemoryShareLinkRepository.
+func NewMemoryShareLinkRepository() *MemoryShareLinkRepository {
+	return &MemoryShareLinkRepository{
+		table: newTable(
+			func(l models.ShareLink) uuid.UUID { return l.ID },
+			func(l models.ShareLink) uuid.UUID { return l.SiteID },
+		),
+	}
+}
+
+// ListByArticle returns every share link of the article of the site.
+func (lr *MemoryShareLinkRepository) ListByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) ([]models.ShareLink, error) {
+	return lr.table.list(ctx, siteID, func(l models.ShareLink) bool {
+		return l.ArticleID == articleID
+	}), nil
+}
+
+// GetByHash returns the share link of the site with the given digest, or
+// `ErrNotFound`.
+func (lr *MemoryShareLinkRepository) GetByHash(
+	ctx context.Context,
+	siteID uuid.UUID,
+	hash string,
+) (models.ShareLink, error) {
+	links := lr.table.list(ctx, siteID, func(l models.ShareLink) bool {
+		return l.Hash == hash
+	})
+	if len(links) == 0 {
+		return models.ShareLink{}, ErrNotFound
+	}
+
+	return links[0], nil
+}
+
+// Create stores a new share link in the site referenced by its `SiteID` field.
+func (lr *MemoryShareLinkRepository) Create(
+	ctx context.Context,
+	link models.ShareLink,
+) error {
+	return lr.table.insert(ctx, link)
+}
+
+// Delete removes the share link of the site identified by id, or returns
+// `ErrNotFound`.
+func (lr *MemoryShareLinkRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return lr.table.delete(ctx, siteID, id)
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// SiteRepository defines the data access methods of the sites.
+type SiteRepository interface {
+	// List returns every site of the deployment.
+	List(ctx context.Context) ([]models.Site, error)
+
+	// Get returns the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, id uuid.UUID) (models.Site, error)
+
+	// GetBySlug returns the site identified by slug, or `ErrNotFound`.
+	GetBySlug(ctx context.Context, slug string) (models.Site, error)
+
+	// GetByHostname returns the site serving the hostname, or `ErrNotFound`.
+	GetByHostname(ctx context.Context, hostname string) (models.Site, error)
+
+	// Create stores a new site, or returns `ErrConflict` if its identifier, slug or
+	// one of its hostnames is already taken.
+	Create(ctx context.Context, site models.Site) error
+
+	// Update replaces an existing site, or returns `ErrNotFound`.
+	Update(ctx context.Context, site models.Site) error
+
+	// Delete removes the site identified by id, or returns `ErrNotFound`.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// MemorySiteRepository is an in-memory implementation of SiteRepository.
+type MemorySiteRepository struct {
+	mu    sync.RWMutex
+	sites []models.Site
+}
+
+// NewMemorySiteRepository creates and returns a new empty MemorySiteRepository.
+func NewMemorySiteRepository() *MemorySiteRepository {
+	return &MemorySiteRepository{}
+}
+
+// List returns every site of the deployment.
+func (sr *MemorySiteRepository) List(ctx context.Context) ([]models.Site, error) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	return slices.Clone(sr.sites), nil
+}
+
+// Get returns the site identified by id, or `ErrNotFound`.
+func (sr *MemorySiteRepository) Get(ctx context.Context, id uuid.UUID) (models.Site, error) {
+	return sr.find(func(s models.Site) bool { return s.ID == id })
+}
+
+// GetBySlug returns the site identified by slug, or `ErrNotFound`.
+func (sr *MemorySiteRepository) GetBySlug(
+	ctx context.Context,
+	slug string,
+) (models.Site, error) {
+	return sr.find(func(s models.Site) bool { return s.Slug == slug })
+}
+
+// GetByHostname returns the site serving the hostname (case-insensitively), either as
+// one of its hostnames or as one of its verified custom domains, or `ErrNotFound`.
+func (sr *MemorySiteRepository) GetByHostname(
+	ctx context.Context,
+	hostname string,
+) (models.Site, error) {
+	return sr.find(func(s models.Site) bool {
+		return slices.ContainsFunc(s.Hostnames, func(h string) bool {
+			return strings.EqualFold(h, hostname)
+		}) || slices.ContainsFunc(s.Domains, func(d models.Domain) bool {
+			return d.Verified && strings.EqualFold(d.Name, hostname)
+		})
+	})
+}
+
+// Create stores a new site, or returns `ErrConflict` if its identifier, slug or one of
+// its hostnames is already taken.
+func (sr *MemorySiteRepository) Create(ctx context.Context, site models.Site) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if sr.taken(site) {
+		return ErrConflict
+	}
+
+	sr.sites = append(sr.sites, site)
+
+	return nil
+}
+
+// Update replaces an existing site, or returns `ErrNotFound`.
+func (sr *MemorySiteRepository) Update(ctx context.Context, site models.Site) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	i := slices.IndexFunc(sr.sites, func(s models.Site) bool { return s.ID == site.ID })
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	if sr.taken(site) {
+		return ErrConflict
+	}
+
+	sr.sites[i] = site
+
+	return nil
+}
+
+// Delete removes the site identified by id, or returns `ErrNotFound`.
+func (sr *MemorySiteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	i := slices.IndexFunc(sr.sites, func(s models.Site) bool { return s.ID == id })
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	sr.sites = slices.Delete(sr.sites, i, i+1)
+
+	return nil
+}
+
+// find returns the first site matching the predicate, or `ErrNotFound`.
+func (sr *MemorySiteRepository) find(match func(models.Site) bool) (models.Site, error) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	i := slices.IndexFunc(sr.sites, match)
+	if i < 0 {
+		return models.Site{}, ErrNotFound
+	}
+
+	return sr.sites[i], nil
+}
+
+// taken reports whether another site already uses the slug or one of the hostnames
+// (or custom domains) of the site; sr.mu must be held.
+func (sr *MemorySiteRepository) taken(site models.Site) bool {
+	hosts := hostsOf(site)
+
+	return slices.ContainsFunc(sr.sites, func(s models.Site) bool {
+		if s.ID == site.ID {
+			return false
+		}
+
+		if s.Slug == site.Slug {
+			return true
+		}
+
+		return slices.ContainsFunc(hostsOf(s), func(h string) bool {
+			return slices.ContainsFunc(hosts, func(o string) bool {
+				return strings.EqualFold(h, o)
+			})
+		})
+	})
+}
+
+// hostsOf returns the hostnames and the custom domains of the site.
+func hostsOf(site models.Site) []string {
+	hosts := slices.Clone(site.Hostnames)
+	for _, domain := range site.Domains {
+		hosts = append(hosts, domain.Name)
+	}
+
+	return hosts
+}
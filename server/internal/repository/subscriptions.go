@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// SubscriptionRepository defines the data access methods of the subscriptions of the
+// users to the comments of the articles.
+type SubscriptionRepository interface {
+	// ListByUser returns the subscriptions of the user of the site.
+	ListByUser(
+		ctx context.Context,
+		siteID, userID uuid.UUID,
+	) ([]models.Subscription, error)
+
+	// ListByArticle returns the subscriptions to the article of the site.
+	ListByArticle(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+	) ([]models.Subscription, error)
+
+	// Get returns the subscription of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Subscription, error)
+
+	// Create stores a new subscription in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, subscription models.Subscription) error
+
+	// Update replaces an existing subscription of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound`.
+	Update(ctx context.Context, subscription models.Subscription) error
+
+	// Delete removes the subscription of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+
+	// DeleteByArticle removes every subscription to the article of the site.
+	DeleteByArticle(ctx context.Context, siteID, articleID uuid.UUID) error
+}
+
+// MemorySubscriptionRepository is an in-memory implementation of
+// SubscriptionRepository.
+type MemorySubscriptionRepository struct {
+	table *table[models.Subscription]
+}
+
+// NewMemorySubscriptionRepository creates and returns a new empty
+// MemorySubscriptionRepository.
+func NewMemorySubscriptionRepository() *MemorySubscriptionRepository {
+	return &MemorySubscriptionRepository{
+		table: newTable(
+			func(s models.Subscription) uuid.UUID { return s.ID },
+			func(s models.Subscription) uuid.UUID { return s.SiteID },
+		),
+	}
+}
+
+// ListByUser returns the subscriptions of the user of the site.
+func (sr *MemorySubscriptionRepository) ListByUser(
+	ctx context.Context,
+	siteID, userID uuid.UUID,
+) ([]models.Subscription, error) {
+	return sr.table.list(ctx, siteID, func(s models.Subscription) bool {
+		return s.UserID == userID
+	}), nil
+}
+
+// ListByArticle returns the subscriptions to the article of the site.
+func (sr *MemorySubscriptionRepository) ListByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) ([]models.Subscription, error) {
+	return sr.table.list(ctx, siteID, func(s models.Subscription) bool {
+		return s.ArticleID == articleID
+	}), nil
+}
+
+// Get returns the subscription of the site identified by id, or `ErrNotFound`.
+func (sr *MemorySubscriptionRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Subscription, error) {
+	return sr.table.get(ctx, siteID, id)
+}
+
+// Create stores a new subscription in the site referenced by its `SiteID` field.
+func (sr *MemorySubscriptionRepository) Create(
+	ctx context.Context,
+	subscription models.Subscription,
+) error {
+	return sr.table.insert(ctx, subscription)
+}
+
+// Update replaces an existing subscription of the site referenced by its `SiteID`
+// field, or returns `ErrNotFound`.
+func (sr *MemorySubscriptionRepository) Update(
+	ctx context.Context,
+	subscription models.Subscription,
+) error {
+	return sr.table.update(ctx, subscription)
+}
+
+// Delete removes the subscription of the site identified by id, or returns
+// `ErrNotFound`.
+func (sr *MemorySubscriptionRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return sr.table.delete(ctx, siteID, id)
+}
+
+// DeleteByArticle removes every subscription to the article of the site.
+func (sr *MemorySubscriptionRepository) DeleteByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) error {
+	subscriptions, _ := sr.ListByArticle(ctx, siteID, articleID)
+	for _, subscription := range subscriptions {
+		if err := sr.table.delete(ctx, siteID, subscription.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
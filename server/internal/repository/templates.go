@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// TemplateBundleRepository defines the data access methods of the template bundles.
+type TemplateBundleRepository interface {
+	// List returns every template bundle of the site, in upload order.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.TemplateBundle, error)
+
+	// Get returns the template bundle of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.TemplateBundle, error)
+
+	// GetActive returns the active template bundle of the site, or `ErrNotFound`.
+	GetActive(ctx context.Context, siteID uuid.UUID) (models.TemplateBundle, error)
+
+	// Create stores a new template bundle in the site referenced by its `SiteID`
+	// field.
+	Create(ctx context.Context, bundle models.TemplateBundle) error
+
+	// Activate makes the template bundle of the site identified by id its active
+	// bundle (or deactivates every bundle of the site if id is `uuid.Nil`), or returns
+	// `ErrNotFound`.
+	Activate(ctx context.Context, siteID, id uuid.UUID, at time.Time) error
+
+	// Delete removes the template bundle of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryTemplateBundleRepository is an in-memory implementation of
+// TemplateBundleRepository.
+type MemoryTemplateBundleRepository struct {
+	mu    sync.Mutex // Serializes the activations, so that one bundle at most is active
+	table *table[models.TemplateBundle]
+}
+
+// NewMemoryTemplateBundleRepository creates and returns a new empty
+// MemoryTemplateBundleRepository.
+func NewMemoryTemplateBundleRepository() *MemoryTemplateBundleRepository {
+	return &MemoryTemplateBundleRepository{
+		table: newTable(
+			func(b models.TemplateBundle) uuid.UUID { return b.ID },
+			func(b models.TemplateBundle) uuid.UUID { return b.SiteID },
+		),
+	}
+}
+
+// List returns every template bundle of the site, in upload order.
+func (br *MemoryTemplateBundleRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.TemplateBundle, error) {
+	return br.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the template bundle of the site identified by id, or `ErrNotFound`.
+func (br *MemoryTemplateBundleRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.TemplateBundle, error) {
+	return br.table.get(ctx, siteID, id)
+}
+
+// GetActive returns the active template bundle of the site, or `ErrNotFound`.
+func (br *MemoryTemplateBundleRepository) GetActive(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (models.TemplateBundle, error) {
+	bundles := br.table.list(ctx, siteID, func(b models.TemplateBundle) bool {
+		return b.Active
+	})
+	if len(bundles) == 0 {
+		return models.TemplateBundle{}, ErrNotFound
+	}
+
+	return bundles[0], nil
+}
+
+// Create stores a new template bundle in the site referenced by its `SiteID` field.
+func (br *MemoryTemplateBundleRepository) Create(
+	ctx context.Context,
+	bundle models.TemplateBundle,
+) error {
+	return br.table.insert(ctx, bundle)
+}
+
+// Activate makes the template bundle of the site identified by id its active bundle
+// (or deactivates every bundle of the site if id is `uuid.Nil`), or returns
+// `ErrNotFound`.
+func (br *MemoryTemplateBundleRepository) Activate(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+	at time.Time,
+) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	if id != uuid.Nil {
+		if _, err := br.table.get(ctx, siteID, id); err != nil {
+			return err
+		}
+	}
+
+	for _, bundle := range br.table.list(ctx, siteID, nil) {
+		active := bundle.ID == id
+		if bundle.Active == active {
+			continue
+		}
+
+		bundle.Active = active
+		if active {
+			bundle.ActivatedAt = &at
+		}
+
+		if err := br.table.update(ctx, bundle); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the template bundle of the site identified by id, or returns
+// `ErrNotFound`.
+func (br *MemoryTemplateBundleRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return br.table.delete(ctx, siteID, id)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+/*
+UsageRepository defines the data access methods of the usage counters of the sites.
+
+The counters are indexed by day (formatted as YYYY-MM-DD) so that the usage can be
+reported per billing period.
+*/
+type UsageRepository interface {
+	// AddRequests increments the served requests counter of the site for the day.
+	AddRequests(ctx context.Context, siteID uuid.UUID, day string, n int64) error
+
+	// AddRateLimited increments the rate-limited requests counter of the site for the
+	// day.
+	AddRateLimited(ctx context.Context, siteID uuid.UUID, day string, n int64) error
+
+	// Requests returns the served requests counters of the site, indexed by day.
+	Requests(ctx context.Context, siteID uuid.UUID) (map[string]int64, error)
+
+	// RateLimited returns the rate-limited requests counters of the site, indexed by
+	// day.
+	RateLimited(ctx context.Context, siteID uuid.UUID) (map[string]int64, error)
+}
+
+// MemoryUsageRepository is an in-memory implementation of UsageRepository.
+type MemoryUsageRepository struct {
+	mu          sync.Mutex
+	requests    map[uuid.UUID]map[string]int64
+	rateLimited map[uuid.UUID]map[string]int64
+}
+
+// NewMemoryUsageRepository creates and returns a new empty MemoryUsageRepository.
+func NewMemoryUsageRepository() *MemoryUsageRepository {
+	return &MemoryUsageRepository{
+		requests:    make(map[uuid.UUID]map[string]int64),
+		rateLimited: make(map[uuid.UUID]map[string]int64),
+	}
+}
+
+// AddRequests increments the served requests counter of the site for the day.
+func (ur *MemoryUsageRepository) AddRequests(
+	ctx context.Context,
+	siteID uuid.UUID,
+	day string,
+	n int64,
+) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	add(ur.requests, siteID, day, n)
+
+	return nil
+}
+
+// AddRateLimited increments the rate-limited requests counter of the site for the day.
+func (ur *MemoryUsageRepository) AddRateLimited(
+	ctx context.Context,
+	siteID uuid.UUID,
+	day string,
+	n int64,
+) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	add(ur.rateLimited, siteID, day, n)
+
+	return nil
+}
+
+// Requests returns the served requests counters of the site, indexed by day.
+func (ur *MemoryUsageRepository) Requests(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (map[string]int64, error) {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	return copyCounters(ur.requests[siteID]), nil
+}
+
+// RateLimited returns the rate-limited requests counters of the site, indexed by day.
+func (ur *MemoryUsageRepository) RateLimited(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (map[string]int64, error) {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	return copyCounters(ur.rateLimited[siteID]), nil
+}
+
+// add increments the counter of the site for the day; the caller must hold the lock.
+func add(counters map[uuid.UUID]map[string]int64, siteID uuid.UUID, day string, n int64) {
+	if counters[siteID] == nil {
+		counters[siteID] = make(map[string]int64)
+	}
+
+	counters[siteID][day] += n
+}
+
+// copyCounters returns a copy of the counters, which is never nil.
+func copyCounters(counters map[string]int64) map[string]int64 {
+	if counters == nil {
+		return map[string]int64{}
+	}
+
+	return maps.Clone(counters)
+}
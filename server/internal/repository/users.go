@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/encryption"
+	"github.com/Weburz/burzcontent/server/internal/pagination"
+)
+
+// UserRepository defines the data access methods of the users.
+type UserRepository interface {
+	// List returns every user of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.User, error)
+
+	// Query returns the page of the users of the site matching the query, along with
+	// the number of users matching it.
+	Query(
+		ctx context.Context,
+		siteID uuid.UUID,
+		query UserQuery,
+	) ([]models.User, int, error)
+
+	// Get returns the user of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.User, error)
+
+	// Create stores a new user in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, user models.User) error
+
+	// Update replaces an existing user of the site referenced by its `SiteID` field,
+	// or returns `ErrNotFound`.
+	Update(ctx context.Context, user models.User) error
+
+	// Delete removes the user of the site identified by id, or returns `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+
+	// Replace replaces every user of the site with the given ones at once, and returns
+	// the users it replaced, or returns `ErrConflict` (changing nothing) if a user of
+	// another site has the same identifier as one of them.
+	Replace(
+		ctx context.Context,
+		siteID uuid.UUID,
+		users []models.User,
+	) ([]models.User, error)
+
+	// ReEncrypt encrypts again the personal data of the users of the site which is not
+	// encrypted with the primary key, and returns the number of users encrypted again.
+	ReEncrypt(ctx context.Context, siteID uuid.UUID) (int, error)
+}
+
+/*
+UserQuery selects, orders and pages the users of a site.
+
+Fields:
+  - Search: The case-insensitive text the name of the users has to contain (any user
+    matches if empty).
+  - SearchEmail: Whether the search text also matches the email of the users.
+  - Role: The role the users have to be granted (any role matches if empty).
+  - Sort: The order of the users, either by "name", "email", "role", "created_at" or
+    "updated_at" (the order of creation if unset).
+  - Page: The page of users to return.
+*/
+type UserQuery struct {
+	Search      string
+	SearchEmail bool
+	Role        auth.Role
+	Sort        pagination.Sort
+	Page        pagination.Page
+}
+
+// UserSortFields lists the fields the users can be sorted by.
+var UserSortFields = []string{"name", "email", "role", "created_at", "updated_at"}
+
+// MemoryUserRepository is an in-memory implementation of UserRepository.
+type MemoryUserRepository struct {
+	table   *table[models.User]
+	keyring *encryption.Keyring
+}
+
+/*
+NewMemoryUserRepository creates and returns a new empty MemoryUserRepository.
+
+The email addresses of the users are stored encrypted with the keyring, and stored in
+plain text if the keyring is nil. The addresses which can not be decrypted (e.g. their
+key is missing from the keyring) are returned encrypted.
+*/
+func NewMemoryUserRepository(keyring *encryption.Keyring) *MemoryUserRepository {
+	users := newTable(
+		func(u models.User) uuid.UUID { return u.ID },
+		func(u models.User) uuid.UUID { return u.SiteID },
+	)
+
+	users.seal = func(u models.User) (models.User, error) {
+		email, err := keyring.Encrypt(u.Email)
+		if err != nil {
+			return models.User{}, err
+		}
+
+		u.Email = email
+		return u, nil
+	}
+	users.unseal = func(u models.User) models.User {
+		if email, err := keyring.Decrypt(u.Email); err == nil {
+			u.Email = email
+		}
+
+		return u
+	}
+
+	return &MemoryUserRepository{table: users, keyring: keyring}
+}
+
+// List returns every user of the site.
+func (ur *MemoryUserRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.User, error) {
+	return ur.table.list(ctx, siteID, nil), nil
+}
+
+// Query returns the page of the users of the site matching the query, along with the
+// number of users matching it.
+func (ur *MemoryUserRepository) Query(
+	ctx context.Context,
+	siteID uuid.UUID,
+	query UserQuery,
+) ([]models.User, int, error) {
+	search := strings.ToLower(query.Search)
+	users := ur.table.list(ctx, siteID, func(u models.User) bool {
+		if query.Role != "" && u.Role != query.Role {
+			return false
+		}
+
+		return strings.Contains(strings.ToLower(u.Name), search) ||
+			query.SearchEmail && strings.Contains(strings.ToLower(u.Email), search)
+	})
+
+	if query.Sort.Field != "" {
+		slices.SortStableFunc(users, func(a, b models.User) int {
+			var c int
+			switch query.Sort.Field {
+			case "name":
+				c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+			case "email":
+				c = strings.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email))
+			case "role":
+				c = strings.Compare(string(a.Role), string(b.Role))
+			case "created_at":
+				c = a.CreatedAt.Compare(b.CreatedAt)
+			case "updated_at":
+				c = a.UpdatedAt.Compare(b.UpdatedAt)
+			}
+
+			if query.Sort.Desc {
+				return -c
+			}
+
+			return c
+		})
+	}
+
+	return pagination.Slice(users, query.Page), len(users), nil
+}
+
+// Get returns the user of the site identified by id, or `ErrNotFound`.
+func (ur *MemoryUserRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.User, error) {
+	return ur.table.get(ctx, siteID, id)
+}
+
+// Create stores a new user in the site referenced by its `SiteID` field.
+func (ur *MemoryUserRepository) Create(ctx context.Context, user models.User) error {
+	return ur.table.insert(ctx, user)
+}
+
+// Update replaces an existing user of the site referenced by its `SiteID` field.
+func (ur *MemoryUserRepository) Update(ctx context.Context, user models.User) error {
+	return ur.table.update(ctx, user)
+}
+
+// Delete removes the user of the site identified by id, or returns `ErrNotFound`.
+func (ur *MemoryUserRepository) Delete(ctx context.Context, siteID, id uuid.UUID) error {
+	return ur.table.delete(ctx, siteID, id)
+}
+
+// Replace replaces every user of the site with the given ones at once, and returns the
+// users it replaced.
+func (ur *MemoryUserRepository) Replace(
+	ctx context.Context,
+	siteID uuid.UUID,
+	users []models.User,
+) ([]models.User, error) {
+	return ur.table.replace(ctx, siteID, users)
+}
+
+// ReEncrypt encrypts again the email addresses of the users of the site which are not
+// encrypted with the primary key of the keyring.
+func (ur *MemoryUserRepository) ReEncrypt(
+	ctx context.Context,
+	siteID uuid.UUID,
+) (int, error) {
+	return ur.table.reseal(ctx, siteID, func(u models.User) bool {
+		return ur.keyring.Stale(u.Email)
+	})
+}
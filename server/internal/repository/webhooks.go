@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// WebhookRepository defines the data access methods of the outbound webhooks.
+type WebhookRepository interface {
+	// List returns every webhook of the site.
+	List(ctx context.Context, siteID uuid.UUID) ([]models.Webhook, error)
+
+	// Get returns the webhook of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Webhook, error)
+
+	// Create stores a new webhook in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, webhook models.Webhook) error
+
+	// Update replaces an existing webhook of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound`.
+	Update(ctx context.Context, webhook models.Webhook) error
+
+	// Delete removes the webhook of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+}
+
+// MemoryWebhookRepository is an in-memory implementation of WebhookRepository.
+type MemoryWebhookRepository struct {
+	table *table[models.Webhook]
+}
+
+// NewMemoryWebhookRepository creates and returns a new empty MemoryWebhookRepository.
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{
+		table: newTable(
+			func(w models.Webhook) uuid.UUID { return w.ID },
+			func(w models.Webhook) uuid.UUID { return w.SiteID },
+		),
+	}
+}
+
+// List returns every webhook of the site.
+func (wr *MemoryWebhookRepository) List(
+	ctx context.Context,
+	siteID uuid.UUID,
+) ([]models.Webhook, error) {
+	return wr.table.list(ctx, siteID, nil), nil
+}
+
+// Get returns the webhook of the site identified by id, or `ErrNotFound`.
+func (wr *MemoryWebhookRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Webhook, error) {
+	return wr.table.get(ctx, siteID, id)
+}
+
+// Create stores a new webhook in the site referenced by its `SiteID` field.
+func (wr *MemoryWebhookRepository) Create(
+	ctx context.Context,
+	webhook models.Webhook,
+) error {
+	return wr.table.insert(ctx, webhook)
+}
+
+// Update replaces an existing webhook of the site referenced by its `SiteID` field,
+// or returns `ErrNotFound`.
+func (wr *MemoryWebhookRepository) Update(
+	ctx context.Context,
+	webhook models.Webhook,
+) error {
+	return wr.table.update(ctx, webhook)
+}
+
+// Delete removes the webhook of the site identified by id, or returns `ErrNotFound`.
+func (wr *MemoryWebhookRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return wr.table.delete(ctx, siteID, id)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// WebmentionRepository defines the data access methods of the webmentions received by
+// the articles.
+type WebmentionRepository interface {
+	// ListByArticle returns the webmentions received by the article of the site.
+	ListByArticle(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+	) ([]models.Webmention, error)
+
+	// Get returns the webmention of the site identified by id, or `ErrNotFound`.
+	Get(ctx context.Context, siteID, id uuid.UUID) (models.Webmention, error)
+
+	// GetBySource returns the webmention received by the article of the site from the
+	// source, or `ErrNotFound`.
+	GetBySource(
+		ctx context.Context,
+		siteID, articleID uuid.UUID,
+		source string,
+	) (models.Webmention, error)
+
+	// Create stores a new webmention in the site referenced by its `SiteID` field.
+	Create(ctx context.Context, webmention models.Webmention) error
+
+	// Update replaces an existing webmention of the site referenced by its `SiteID`
+	// field, or returns `ErrNotFound`.
+	Update(ctx context.Context, webmention models.Webmention) error
+
+	// Delete removes the webmention of the site identified by id, or returns
+	// `ErrNotFound`.
+	Delete(ctx context.Context, siteID, id uuid.UUID) error
+
+	// DeleteByArticle removes every webmention received by the article of the site.
+	DeleteByArticle(ctx context.Context, siteID, articleID uuid.UUID) error
+}
+
+// MemoryWebmentionRepository is an in-memory implementation of WebmentionRepository.
+type MemoryWebmentionRepository struct {
+	table *table[models.Webmention]
+}
+
+// NewMemoryWebmentionRepository creates and returns a new empty
+// MemoryWebmentionRepository.
+func NewMemoryWebmentionRepository() *MemoryWebmentionRepository {
+	return &MemoryWebmentionRepository{
+		table: newTable(
+			func(w models.Webmention) uuid.UUID { return w.ID },
+			func(w models.Webmention) uuid.UUID { return w.SiteID },
+		),
+	}
+}
+
+// ListByArticle returns the webmentions received by the article of the site.
+func (wr *MemoryWebmentionRepository) ListByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) ([]models.Webmention, error) {
+	return wr.table.list(ctx, siteID, func(w models.Webmention) bool {
+		return w.ArticleID == articleID
+	}), nil
+}
+
+// Get returns the webmention of the site identified by id, or `ErrNotFound`.
+func (wr *MemoryWebmentionRepository) Get(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) (models.Webmention, error) {
+	return wr.table.get(ctx, siteID, id)
+}
+
+// GetBySource returns the webmention received by the article of the site from the
+// source, or `ErrNotFound`.
+func (wr *MemoryWebmentionRepository) GetBySource(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+	source string,
+) (models.Webmention, error) {
+	webmentions := wr.table.list(ctx, siteID, func(w models.Webmention) bool {
+		return w.ArticleID == articleID && w.Source == source
+	})
+	if len(webmentions) == 0 {
+		return models.Webmention{}, ErrNotFound
+	}
+
+	return webmentions[0], nil
+}
+
+// Create stores a new webmention in the site referenced by its `SiteID` field.
+func (wr *MemoryWebmentionRepository) Create(
+	ctx context.Context,
+	webmention models.Webmention,
+) error {
+	return wr.table.insert(ctx, webmention)
+}
+
+// Update replaces an existing webmention of the site referenced by its `SiteID` field,
+// or returns `ErrNotFound`.
+func (wr *MemoryWebmentionRepository) Update(
+	ctx context.Context,
+	webmention models.Webmention,
+) error {
+	return wr.table.update(ctx, webmention)
+}
+
+// Delete removes the webmention of the site identified by id, or returns
+// `ErrNotFound`.
+func (wr *MemoryWebmentionRepository) Delete(
+	ctx context.Context,
+	siteID, id uuid.UUID,
+) error {
+	return wr.table.delete(ctx, siteID, id)
+}
+
+// DeleteByArticle removes every webmention received by the article of the site.
+func (wr *MemoryWebmentionRepository) DeleteByArticle(
+	ctx context.Context,
+	siteID, articleID uuid.UUID,
+) error {
+	webmentions, _ := wr.ListByArticle(ctx, siteID, articleID)
+	for _, webmention := range webmentions {
+		if err := wr.table.delete(ctx, siteID, webmention.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
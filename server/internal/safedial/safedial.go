@@ -0,0 +1,58 @@
+/*
+Package safedial dials the hosts of the URLs chosen by third parties (e.g. the
+endpoints of the outbound webhooks or the sources of the received webmentions),
+refusing to connect to the loopback, private, link-local, multicast and unspecified
+addresses, so that the clients fetching these URLs can not be used to reach the
+internal network of the server.
+
+The addresses are checked once resolved, right before connecting to them, hence a
+hostname resolving to a forbidden address is refused too, whatever it resolved to when
+its URL was validated.
+
+Example:
+
+	client := &http.Client{
+		Transport: &http.Transport{DialContext: safedial.NewDialer(timeout).DialContext},
+	}
+*/
+package safedial
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a host resolves to an address the dialer refuses
+// to connect to (e.g. a loopback or private address).
+var ErrForbiddenAddress = errors.New("forbidden address")
+
+// NewDialer creates and returns a new dialer timing out after timeout, which refuses to
+// connect to the forbidden addresses with an error wrapping `ErrForbiddenAddress`.
+func NewDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !allowed(net.ParseIP(host)) {
+				return fmt.Errorf(
+					"unable to connect to %s: %w", host, ErrForbiddenAddress,
+				)
+			}
+
+			return nil
+		},
+	}
+}
+
+// allowed reports whether the dialer may connect to the IP address.
+func allowed(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast()
+}
@@ -0,0 +1,41 @@
+package safedial_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/safedial"
+)
+
+// TestNewDialer checks which addresses the dialer refuses to connect to.
+func TestNewDialer(t *testing.T) {
+	control := safedial.NewDialer(time.Second).Control
+
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.0.0.1:80", false},
+		{"172.16.5.4:80", false},
+		{"192.168.1.1:80", false},
+		{"[fd00::1]:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"224.0.0.1:80", false},
+		{"0.0.0.0:80", false},
+		{"[::]:80", false},
+	}
+	for _, tt := range tests {
+		err := control("tcp", tt.address, nil)
+		if tt.allowed && err != nil {
+			t.Errorf("Expected %s to be allowed. Got %v\n", tt.address, err)
+		} else if !tt.allowed && !errors.Is(err, safedial.ErrForbiddenAddress) {
+			t.Errorf("Expected %s to be forbidden. Got %v\n", tt.address, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Package sanitize sanitizes the HTML of the user-generated content (e.g. the comments
+and the content of the articles) against an allowlist of elements and attributes, so
+that no script can round-trip through the content to its readers.
+
+A `Policy` lists the elements and the attributes it allows, every other element being
+stripped from the HTML (keeping its text, except for the elements whose text is not
+meant to be displayed, such as `<script>` and `<style>`) along with every other
+attribute. The attributes holding URLs (e.g. `href`) are only kept if their URL is
+relative or uses a safe scheme (HTTP(S) and mailto), which rules out the
+`javascript:` URLs. Every HTML comment is stripped as well. The frames (`<iframe>`)
+only keep their source if the policy allows its host, e.g. for the embeds of the
+articles.
+
+The policies are built on the ones of bluemonday, which parses the HTML like the
+browsers do (with the tokenizer of `golang.org/x/net/html`) and writes the text back
+escaped, so that what is stripped can never make up a new tag out of the text around
+it (e.g. `<<script></script>img src=x onerror=alert(1)>`).
+*/
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// rawTextElements lists the elements whose content is not displayed as text, which is
+// stripped along with them.
+var rawTextElements = []string{
+	"script", "style", "iframe", "noembed", "noframes", "noscript", "template",
+	"textarea", "title", "xmp",
+}
+
+// urlSchemes lists the schemes the URLs of the attributes are allowed to use.
+var urlSchemes = []string{"http", "https", "mailto"}
+
+/*
+Policy is an allowlist of the elements and the attributes the sanitized HTML may hold.
+The zero value is not usable, a policy being created with `NewPolicy` (or one of the
+predefined policies) and built with its chainable methods, e.g.
+
+	policy := sanitize.NewPolicy().
+		AllowElements("p", "em", "strong").
+		AllowAttrs("a", "href")
+
+A policy is safe for concurrent use once built.
+*/
+type Policy struct {
+	policy *bluemonday.Policy
+}
+
+// NewPolicy creates and returns a new policy allowing no element at all, i.e. which
+// strips every tag from the HTML.
+func NewPolicy() *Policy {
+	policy := bluemonday.NewPolicy()
+	policy.SkipElementsContent(rawTextElements...)
+	policy.RequireParseableURLs(true)
+	policy.AllowRelativeURLs(true)
+	policy.AllowURLSchemes(urlSchemes...)
+
+	return &Policy{policy: policy}
+}
+
+// StrictPolicy returns a policy stripping every tag from the HTML, leaving its text
+// only.
+func StrictPolicy() *Policy {
+	return NewPolicy()
+}
+
+// CommentPolicy returns the policy of the comments, allowing the basic formatting of
+// the text, quotes, code and links (which are marked as not endorsed by the site).
+func CommentPolicy() *Policy {
+	return NewPolicy().
+		AllowElements("p", "br", "b", "i", "em", "strong", "code", "pre", "blockquote").
+		AllowAttrs("a", "href", "title").
+		RequireNoFollowOnLinks()
+}
+
+// ArticlePolicy returns the policy of the content of the articles, allowing the
+// structure of a document (headings, lists, tables, figures, etc.), its formatting,
+// links and images.
+func ArticlePolicy() *Policy {
+	return NewPolicy().
+		AllowElements(
+			"p", "br", "hr", "div", "span", "section", "article", "aside",
+			"h1", "h2", "h3", "h4", "h5", "h6",
+			"b", "i", "u", "s", "em", "strong", "small", "mark", "sub", "sup",
+			"code", "pre", "kbd", "samp", "var", "abbr", "del", "ins",
+			"ul", "ol", "li", "dl", "dt", "dd",
+			"table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption",
+			"figure", "figcaption", "wbr",
+		).
+		AllowAttrs("a", "href", "title", "rel").
+		AllowAttrs("img", "src", "alt", "title", "width", "height").
+		AllowAttrs("blockquote", "cite").
+		AllowAttrs("q", "cite").
+		AllowAttrs("th", "colspan", "rowspan", "scope").
+		AllowAttrs("td", "colspan", "rowspan")
+}
+
+// EmbedPolicy returns the policy of the embeds the shortcodes of the articles expand to
+// (see the `shortcode` package), allowing what the article policy does along with the
+// classes of the figures and the quotes, and the frames of YouTube and GitHub Gist.
+func EmbedPolicy() *Policy {
+	return ArticlePolicy().
+		AllowAttrs("figure", "class").
+		AllowAttrs("blockquote", "class").
+		AllowAttrs("img", "loading").
+		AllowFrames("www.youtube-nocookie.com", "gist.github.com")
+}
+
+// AllowElements allows the named elements, without any attribute unless allowed with
+// `AllowAttrs`.
+func (p *Policy) AllowElements(names ...string) *Policy {
+	p.policy.AllowElements(names...)
+	return p
+}
+
+// AllowAttrs allows the element along with the named attributes on it.
+func (p *Policy) AllowAttrs(element string, attrs ...string) *Policy {
+	p.policy.AllowElements(element)
+	p.policy.AllowAttrs(attrs...).OnElements(element)
+
+	return p
+}
+
+// AllowFrames allows the frames along with their size, title and permissions, and
+// their source if it is an HTTPS URL on one of the hosts (e.g.
+// "www.youtube-nocookie.com"). Their fallback content is kept as (escaped) text.
+func (p *Policy) AllowFrames(hosts ...string) *Policy {
+	quoted := make([]string, len(hosts))
+	for i, host := range hosts {
+		quoted[i] = regexp.QuoteMeta(strings.ToLower(host))
+	}
+	source := regexp.MustCompile(
+		`^(?i:https)://(?i:` + strings.Join(quoted, "|") + `)(?:[/?#]|$)`,
+	)
+
+	p.policy.AllowAttrs("src").Matching(source).OnElements("iframe")
+	p.policy.AllowAttrs(
+		"title", "width", "height", "allow", "allowfullscreen", "loading",
+		"referrerpolicy",
+	).OnElements("iframe")
+
+	return p
+}
+
+// RequireNoFollowOnLinks marks every link of the sanitized HTML as not endorsed by the
+// site (`rel="nofollow"`), along with its own relationship if any.
+func (p *Policy) RequireNoFollowOnLinks() *Policy {
+	p.policy.RequireNoFollowOnLinks(true)
+	return p
+}
+
+// Sanitize returns the HTML stripped of the elements and the attributes the policy
+// does not allow.
+func (p *Policy) Sanitize(s string) string {
+	return p.policy.Sanitize(s)
+}
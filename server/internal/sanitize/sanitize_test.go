@@ -0,0 +1,144 @@
+package sanitize_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+
+	"github.com/Weburz/burzcontent/server/internal/sanitize"
+)
+
+// policies lists the predefined policies, by name.
+var policies = map[string]*sanitize.Policy{
+	"strict":  sanitize.StrictPolicy(),
+	"comment": sanitize.CommentPolicy(),
+	"article": sanitize.ArticlePolicy(),
+	"embed":   sanitize.EmbedPolicy(),
+}
+
+// scriptElements lists the elements which must never make it through a policy.
+var scriptElements = []string{
+	"script", "style", "svg", "math", "object", "embed", "noscript", "template",
+	"textarea", "xmp", "base", "form", "meta", "link",
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		policy string
+		input  string
+		want   string
+	}{
+		{"comment", "<p>Hello <em>world</em></p>", "<p>Hello <em>world</em></p>"},
+		{"comment", "<p>Hi<script>alert(1)</script></p>", "<p>Hi</p>"},
+		{"comment", "a < b && c > d", "a &lt; b &amp;&amp; c &gt; d"},
+		{
+			"comment",
+			`<a href="https://example.com" onclick="alert(1)">link</a>`,
+			`<a href="https://example.com" rel="nofollow">link</a>`,
+		},
+		{"comment", `<a href="javascript:alert(1)">link</a>`, "link"},
+		{"comment", "<h1>Title</h1>", "Title"},
+		{"article", "<h1>Title</h1><!-- note -->", "<h1>Title</h1>"},
+		{
+			"article",
+			`<img src="/cat.png" alt="A cat" onerror="alert(1)">`,
+			`<img src="/cat.png" alt="A cat">`,
+		},
+		{
+			"embed",
+			`<iframe src="https://www.youtube-nocookie.com/embed/x"></iframe>`,
+			`<iframe src="https://www.youtube-nocookie.com/embed/x"></iframe>`,
+		},
+		{
+			"embed",
+			`<iframe src="https://example.com/embed/x" width="560"></iframe>`,
+			`<iframe width="560"></iframe>`,
+		},
+		{"strict", "<p>Hello <b>world</b></p>", "Hello world"},
+	}
+
+	for _, tt := range tests {
+		got := policies[tt.policy].Sanitize(tt.input)
+		if got != tt.want {
+			t.Errorf("%s.Sanitize(%q) = %q; want %q", tt.policy, tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestSanitizeMutations checks the payloads which are known to turn into markup once
+// a naive sanitizer strips a part of them (mutation XSS), or once the browsers parse
+// them again, against every policy.
+func TestSanitizeMutations(t *testing.T) {
+	payloads := []string{
+		`<<script></script>img src=x onerror=alert(1)>`,
+		`<<!-- -->img src=x onerror=alert(1)>`,
+		`<<span>img src=x onerror=alert(1)>`,
+		`<<p>img src=x onerror=alert(1)>`,
+		`<scr<script>ipt>alert(1)</scr</script>ipt>`,
+		`<img src=x onerror=alert(1)//`,
+		`<img/src=x/onerror=alert(1)>`,
+		`<svg><p><style><img src=x onerror=alert(1)></style></p></svg>`,
+		`<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`,
+		`<noscript><p title="</noscript><img src=x onerror=alert(1)>"></noscript>`,
+		`<textarea><img src=x onerror=alert(1)></textarea>`,
+		`<title><img src=x onerror=alert(1)></title>`,
+		`<iframe src="https://gist.github.com/x"><img src=x onerror=alert(1)></iframe>`,
+		`<a href="jav&#x09;ascript:alert(1)">x</a>`,
+		`<a href=" JaVaScRiPt:alert(1)">x</a>`,
+		`<a href="data:text/html,<script>alert(1)</script>">x</a>`,
+		`<img src="x" alt="&quot; onerror=&quot;alert(1)">`,
+		`<p title="</p><img src=x onerror=alert(1)>">x</p>`,
+		`<!--><img src=x onerror=alert(1)>-->`,
+		`<![CDATA[><img src=x onerror=alert(1)>]]>`,
+	}
+
+	for name, policy := range policies {
+		for _, payload := range payloads {
+			got := policy.Sanitize(payload)
+			if problem := unsafeMarkup(got); problem != "" {
+				t.Errorf("%s.Sanitize(%q) = %q, %s", name, payload, got, problem)
+			}
+
+			// The sanitized HTML has to be stable once parsed and sanitized again
+			if again := policy.Sanitize(got); unsafeMarkup(again) != "" {
+				t.Errorf("%s.Sanitize(%q) is unsafe once sanitized again: %q",
+					name, payload, again)
+			}
+		}
+	}
+}
+
+// unsafeMarkup describes the markup of the HTML which could run a script, the way the
+// browsers parse it, or returns an empty string if there is none.
+func unsafeMarkup(s string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				return "which is not parsed: " + tokenizer.Err().Error()
+			}
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			for _, name := range scriptElements {
+				if token.Data == name {
+					return "holding a <" + name + "> element"
+				}
+			}
+
+			for _, attr := range token.Attr {
+				value := strings.ToLower(strings.TrimSpace(attr.Val))
+				switch {
+				case strings.HasPrefix(attr.Key, "on"):
+					return "holding an " + attr.Key + " handler"
+				case strings.HasPrefix(value, "javascript:"),
+					strings.HasPrefix(value, "data:"):
+					return "holding a script URL in " + attr.Key
+				}
+			}
+		}
+	}
+}
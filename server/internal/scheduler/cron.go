@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/localtime"
+)
+
+// ErrInvalidSchedule is returned when a cron expression can not be parsed.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// descriptors maps the shorthands of the cron expressions to their expansion.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes a field of the cron expressions, by its name and its bounds.
+type field struct {
+	name     string
+	min, max int
+}
+
+// The fields of the cron expressions, in order.
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Schedule is a parsed cron expression, telling when a job runs.
+//
+// The expressions have five fields: the minute (0-59), the hour (0-23), the day of the
+// month (1-31), the month (1-12) and the day of the week (0-7, Sunday being either 0 or
+// 7). Each field is a `*`, a value (e.g. `5`), a range (e.g. `1-5`), any of them with a
+// step (e.g. `*/15` or `0-30/10`) or a comma-separated list of those (e.g. `0,30`). As
+// with cron, a job whose days of the month and of the week are both restricted runs on
+// the days matching either. The shorthands `@yearly`, `@monthly`, `@weekly`, `@daily`
+// and `@hourly` are accepted too.
+//
+// The schedules are evaluated in UTC, unless the expression is prefixed with the IANA
+// time zone they are evaluated in, e.g. `CRON_TZ=Europe/Berlin 0 8 * * *`. The times
+// skipped when the clocks are moved forward (e.g. 02:30 on the last Sunday of March in
+// Europe/Berlin) run once, when they are; those repeated when the clocks are moved back
+// run once, the first time.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // Bit sets of the values of the fields
+
+	// Whether the days of the month and of the week are restricted
+	domRestricted, dowRestricted bool
+
+	location *time.Location // The time zone of the schedule, UTC if nil
+}
+
+// Parse parses a cron expression, returning an error wrapping `ErrInvalidSchedule` if
+// it is malformed or if its time zone is unknown.
+func Parse(expr string) (Schedule, error) {
+	spec := strings.TrimSpace(expr)
+
+	var location *time.Location
+	if zone, ok := strings.CutPrefix(spec, "CRON_TZ="); ok {
+		name, rest, _ := strings.Cut(zone, " ")
+
+		var err error
+		if location, err = time.LoadLocation(name); err != nil || name == "" ||
+			strings.EqualFold(name, "local") {
+			return Schedule{}, fmt.Errorf(
+				"%w %q: unknown time zone %q", ErrInvalidSchedule, expr, name,
+			)
+		}
+		spec = strings.TrimSpace(rest)
+	}
+
+	if expansion, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expansion
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return Schedule{}, fmt.Errorf(
+			"%w %q: expected %d fields", ErrInvalidSchedule, expr, len(fields),
+		)
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("%w %q: %v", ErrInvalidSchedule, expr, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return Schedule{
+		minute:        sets[0],
+		hour:          sets[1],
+		dom:           sets[2],
+		month:         sets[3],
+		dow:           sets[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+		location:      location,
+	}, nil
+}
+
+// Next returns the first time matching the schedule strictly after t, or the zero time
+// if none does within the next five years (e.g. for `0 0 30 2 *`). The times of the
+// schedule are resolved in its time zone with `localtime.Resolve`.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := s.location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// The fields are matched against the wall clock of the time zone, held in UTC
+	wall := localtime.WallClock(t.In(loc)).Truncate(time.Minute).Add(time.Minute)
+	limit := wall.AddDate(5, 0, 0)
+
+	for wall.Before(limit) {
+		wall = s.nextWall(wall, limit)
+		if wall.IsZero() {
+			break
+		}
+
+		// The time repeated when the clocks were moved back already went by the
+		// second time it is shown, hence the runs at this time are not repeated
+		if next := localtime.Resolve(wall, loc); next.After(t) {
+			return next
+		}
+		wall = wall.Add(time.Minute)
+	}
+
+	return time.Time{}
+}
+
+// nextWall returns the first wall clock time matching the schedule at or after t, or
+// the zero time if none does before limit.
+func (s Schedule) nextWall(t, limit time.Time) time.Time {
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !has(s.hour, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the days of the month and of the
+// week of the schedule.
+func (s Schedule) matchesDay(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// parseField parses a field of a cron expression into the bit set of its values.
+func parseField(spec string, f field) (uint64, error) {
+	var set uint64
+
+	for _, item := range strings.Split(spec, ",") {
+		rng, stepSpec, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q of the %s", stepSpec, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rng != "*" {
+			lowSpec, highSpec, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if low, err = parseValue(lowSpec, f); err != nil {
+				return 0, err
+			}
+
+			high = low
+			if isRange {
+				if high, err = parseValue(highSpec, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q of the %s", rng, f.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// parseValue parses a value of a field of a cron expression, within its bounds.
+func parseValue(spec string, f field) (int, error) {
+	v, err := strconv.Atoi(spec)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf(
+			"invalid %s %q, expected %d-%d", f.name, spec, f.min, f.max,
+		)
+	}
+
+	return v, nil
+}
+
+// has reports whether the bit set holds the value.
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,165 @@
+package scheduler_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/Weburz/burzcontent/server/internal/scheduler"
+)
+
+// TestParseInvalid checks that the malformed cron expressions are rejected.
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 5m",
+		"CRON_TZ=Mars/Olympus 0 * * * *",
+		"CRON_TZ=Local 0 * * * *",
+		"CRON_TZ= 0 * * * *",
+	}
+	for _, expr := range tests {
+		_, err := scheduler.Parse(expr)
+		if !errors.Is(err, scheduler.ErrInvalidSchedule) {
+			t.Errorf("Expected %q to be invalid. Got %v\n", expr, err)
+		}
+	}
+}
+
+// TestNext checks the next run of the schedules, including across the changes of the
+// clocks of their time zone.
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		after string
+		next  string // Empty if the schedule never runs
+	}{
+		{"every minute", "* * * * *", "2026-01-01T10:07:30Z", "2026-01-01T10:08:00Z"},
+		{"step", "*/15 * * * *", "2026-01-01T10:07:00Z", "2026-01-01T10:15:00Z"},
+		{"strictly after", "0 3 * * *", "2026-01-01T03:00:00Z", "2026-01-02T03:00:00Z"},
+		{"range", "0 9-17/4 * * *", "2026-01-01T13:00:00Z", "2026-01-01T17:00:00Z"},
+		{"list", "0,30 8 * * *", "2026-01-01T08:10:00Z", "2026-01-01T08:30:00Z"},
+		{"shorthand", "@monthly", "2026-01-15T00:00:00Z", "2026-02-01T00:00:00Z"},
+		{"next year", "0 0 1 1 *", "2026-06-01T00:00:00Z", "2027-01-01T00:00:00Z"},
+		{"leap day", "0 0 29 2 *", "2026-01-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"never", "0 0 30 2 *", "2026-01-01T00:00:00Z", ""},
+
+		// 2026-01-01 is a Thursday
+		{"day of week", "0 0 * * 1", "2026-01-01T00:00:00Z", "2026-01-05T00:00:00Z"},
+		{"sunday as 7", "0 0 * * 7", "2026-01-01T00:00:00Z", "2026-01-04T00:00:00Z"},
+		{"sunday as 0", "0 0 * * 0", "2026-01-01T00:00:00Z", "2026-01-04T00:00:00Z"},
+		{"either day", "0 0 13 * 5", "2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"},
+		{"either date", "0 0 3 * 1", "2026-01-01T00:00:00Z", "2026-01-03T00:00:00Z"},
+		// As with cron, a step of every day does not restrict the days of the month
+		{"both days", "0 0 */2 * 5", "2026-01-01T00:00:00Z", "2026-01-09T00:00:00Z"},
+		{"date only", "0 0 13 * *", "2026-01-01T00:00:00Z", "2026-01-13T00:00:00Z"},
+
+		{
+			"time zone",
+			"CRON_TZ=Europe/Berlin 0 8 * * *",
+			"2026-01-01T00:00:00Z",
+			"2026-01-01T07:00:00Z",
+		},
+		{
+			"time zone in summer",
+			"CRON_TZ=Europe/Berlin 0 8 * * *",
+			"2026-07-01T00:00:00Z",
+			"2026-07-01T06:00:00Z",
+		},
+		{
+			"time zone shorthand",
+			"CRON_TZ=America/New_York @daily",
+			"2026-01-01T12:00:00Z",
+			"2026-01-02T05:00:00Z",
+		},
+
+		// The clocks of Europe/Berlin skip from 02:00 to 03:00 on 2026-03-29, and are
+		// moved back from 03:00 to 02:00 on 2026-10-25
+		{
+			"skipped time",
+			"CRON_TZ=Europe/Berlin 30 2 * * *",
+			"2026-03-29T00:00:00Z",
+			"2026-03-29T01:00:00Z",
+		},
+		{
+			"after the skipped time",
+			"CRON_TZ=Europe/Berlin 30 2 * * *",
+			"2026-03-29T01:00:00Z",
+			"2026-03-30T00:30:00Z",
+		},
+		{
+			"repeated time",
+			"CRON_TZ=Europe/Berlin 30 2 * * *",
+			"2026-10-24T23:00:00Z",
+			"2026-10-25T00:30:00Z",
+		},
+		{
+			"repeated time, second time",
+			"CRON_TZ=Europe/Berlin 30 2 * * *",
+			"2026-10-25T00:30:00Z",
+			"2026-10-26T01:30:00Z",
+		},
+		{
+			"hourly across the repeated hour",
+			"CRON_TZ=Europe/Berlin 30 * * * *",
+			"2026-10-25T00:30:00Z",
+			"2026-10-25T02:30:00Z",
+		},
+	}
+	for _, tt := range tests {
+		schedule, err := scheduler.Parse(tt.expr)
+		if err != nil {
+			t.Errorf("%s: unable to parse %q: %v", tt.name, tt.expr, err)
+			continue
+		}
+
+		after, _ := time.Parse(time.RFC3339, tt.after)
+		var expected time.Time
+		if tt.next != "" {
+			expected, _ = time.Parse(time.RFC3339, tt.next)
+		}
+
+		if next := schedule.Next(after); !next.Equal(expected) {
+			t.Errorf("%s: expected %q after %s to run at %s. Got %s\n",
+				tt.name, tt.expr, tt.after, expected, next.UTC())
+		}
+	}
+}
+
+// TestNextRunsOnce checks that a schedule runs once a day across the changes of the
+// clocks of its time zone, even at the times they skip or repeat.
+func TestNextRunsOnce(t *testing.T) {
+	for _, expr := range []string{
+		"CRON_TZ=Europe/Berlin 30 2 * * *",
+		"CRON_TZ=Europe/Berlin 0 3 * * *",
+		"CRON_TZ=America/New_York 30 1 * * *",
+	} {
+		schedule, err := scheduler.Parse(expr)
+		if err != nil {
+			t.Fatalf("Unable to parse %q: %v", expr, err)
+		}
+
+		runs := 0
+		end := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+		for next := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC); ; runs++ {
+			if next = schedule.Next(next); !next.Before(end) {
+				break
+			}
+		}
+
+		if runs != 365 {
+			t.Errorf("Expected %q to run 365 times in 2026. Got %d\n", expr, runs)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+/*
+Package scheduler runs the recurring jobs of the server (e.g. publishing the scheduled
+articles or backing up the sites) on cron schedules.
+
+Each job is registered with a default schedule (see `Parse` for the syntax of the cron
+expressions), which the configuration of the server can override or disable (see
+`Scheduler.Configure`). The runs of a job are delayed by a random jitter, so that the
+jobs of several servers do not all run at once, and never overlap: a run which is due
+while the previous one is still going is skipped. A job can also be run on demand (see
+`Scheduler.Trigger`). The status of the last run of every job is reported by
+`Scheduler.Status`. The jobs log through the logger held by their context (see
+`logger.FromContext`), which is the logger of the scheduler.
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/clock"
+	"github.com/Weburz/burzcontent/server/internal/logger"
+)
+
+var (
+	// ErrUnknownJob is returned when a job which is not registered is referred to.
+	ErrUnknownJob = errors.New("unknown job")
+
+	// ErrJobDisabled is returned when a job which is disabled is triggered.
+	ErrJobDisabled = errors.New("job is disabled")
+)
+
+/*
+Job represents a recurring job.
+
+Fields:
+  - Name: The name of the job, e.g. "backup".
+  - Description: What the job does.
+  - Schedule: The cron expression of the runs of the job, e.g. "0 3 * * *".
+  - Enabled: Whether the job runs at all.
+  - Jitter: The maximum random delay of each run of the job.
+  - Run: The function running the job, whose context is cancelled when the scheduler
+    stops.
+*/
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Enabled     bool
+	Jitter      time.Duration
+	Run         func(ctx context.Context) error
+}
+
+/*
+Status represents the status of a job and of its last run.
+
+Fields:
+  - Name, Description, Schedule and Enabled: The settings of the job.
+  - Running: Whether the job is running.
+  - NextRunAt: When the job runs next (before its jitter), if enabled.
+  - LastRunAt: When the last run of the job started, if it ever ran.
+  - LastDuration: How long the last run of the job took, in milliseconds.
+  - LastError: Why the last run of the job failed, if it did.
+  - Runs: The number of runs of the job since the server started.
+  - Failures: The number of these runs which failed.
+  - Skipped: The number of runs skipped because the previous run was still going.
+*/
+type Status struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Schedule     string     `json:"schedule"`
+	Enabled      bool       `json:"enabled"`
+	Running      bool       `json:"running"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration int64      `json:"last_duration_ms"`
+	LastError    string     `json:"last_error,omitempty"`
+	Runs         int        `json:"runs"`
+	Failures     int        `json:"failures"`
+	Skipped      int        `json:"skipped"`
+}
+
+// entry holds a registered job, its parsed schedule and its status.
+type entry struct {
+	job      Job
+	schedule Schedule
+	status   Status
+}
+
+// Scheduler runs the registered jobs on their schedules. It is safe for concurrent use.
+type Scheduler struct {
+	mu      sync.Mutex
+	entries []*entry
+	ctx     context.Context // The context of the jobs, once started
+	logger  *slog.Logger
+	clock   clock.Clock
+}
+
+// New creates and returns a new Scheduler without any job, which logs the runs of the
+// jobs with the given logger (the default logger of the slog package if nil) and tells
+// when they are due and when they ran with the given clock (the system clock if nil).
+func New(logger *slog.Logger, clk clock.Clock) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if clk == nil {
+		clk = clock.System{}
+	}
+
+	return &Scheduler{logger: logger, clock: clk}
+}
+
+// Add registers a job, returning an error if its schedule is invalid (see `Parse`) or
+// if a job with the same name is already registered. The jobs have to be registered
+// before the scheduler is started.
+func (s *Scheduler) Add(job Job) error {
+	schedule, err := Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("unable to register job %q: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lookup(job.Name) != nil {
+		return fmt.Errorf("job %q is already registered", job.Name)
+	}
+
+	s.entries = append(s.entries, &entry{
+		job:      job,
+		schedule: schedule,
+		status: Status{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+			Enabled:     job.Enabled,
+		},
+	})
+
+	return nil
+}
+
+/*
+Configure overrides the settings of the registered jobs with the given spec, a
+semicolon-separated list of job names each followed by `=` and either `on`, `off` or a
+cron expression, e.g. "backup=0 2 * * *; prune=off". A cron expression also
+enables its job, and may give the time zone it is evaluated in, e.g.
+"comment-digest=CRON_TZ=Europe/Berlin 0 8 * * *".
+
+Nothing is changed if the spec is invalid: an error wrapping `ErrUnknownJob` is
+returned for the jobs which are not registered, and one wrapping `ErrInvalidSchedule`
+for the invalid cron expressions.
+*/
+func (s *Scheduler) Configure(spec string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type override struct {
+		entry    *entry
+		schedule Schedule
+		expr     string
+		enabled  bool
+	}
+
+	var overrides []override
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(item, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		e := s.lookup(name)
+		if e == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownJob, name)
+		}
+
+		o := override{entry: e, schedule: e.schedule, expr: e.job.Schedule}
+		switch strings.ToLower(value) {
+		case "on":
+			o.enabled = true
+		case "off":
+			o.enabled = false
+		default:
+			schedule, err := Parse(value)
+			if err != nil {
+				return fmt.Errorf("unable to configure job %q: %w", name, err)
+			}
+			o.schedule, o.expr, o.enabled = schedule, value, true
+		}
+
+		overrides = append(overrides, o)
+	}
+
+	for _, o := range overrides {
+		o.entry.schedule = o.schedule
+		o.entry.job.Schedule = o.expr
+		o.entry.job.Enabled = o.enabled
+		o.entry.status.Schedule = o.expr
+		o.entry.status.Enabled = o.enabled
+	}
+
+	return nil
+}
+
+// Start runs the enabled jobs on their schedules in the background, until the context
+// is cancelled. It is a no-op if the scheduler was already started.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx != nil {
+		return
+	}
+	s.ctx = ctx
+
+	for _, e := range s.entries {
+		if e.job.Enabled {
+			go s.loop(ctx, e)
+		}
+	}
+}
+
+/*
+Trigger runs the named job once now, in the background and on top of its schedule
+(e.g. when an external event calls for it), and reports whether it did: like for the
+scheduled runs, the run is skipped if the previous one is still going.
+
+An error wrapping `ErrUnknownJob` is returned if the job is not registered, and one
+wrapping `ErrJobDisabled` if it is disabled or the scheduler is not started.
+*/
+func (s *Scheduler) Trigger(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.lookup(name)
+	if e == nil {
+		return false, fmt.Errorf("%w: %q", ErrUnknownJob, name)
+	}
+
+	if !e.job.Enabled || s.ctx == nil {
+		return false, fmt.Errorf("%w: %q", ErrJobDisabled, name)
+	}
+
+	if e.status.Running {
+		e.status.Skipped++
+		return false, nil
+	}
+	e.status.Running = true
+
+	go s.run(s.ctx, e)
+
+	return true, nil
+}
+
+// Status returns the status of every registered job, sorted by name.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		statuses = append(statuses, e.status)
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses
+}
+
+// loop runs the job of the entry whenever it is due, until the context is cancelled.
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	after := s.clock.Now()
+	for {
+		next := e.schedule.Next(after)
+		if next.IsZero() {
+			s.logger.WarnContext(ctx, "Job will never run again", "job", e.job.Name)
+			return
+		}
+
+		s.mu.Lock()
+		e.status.NextRunAt = &next
+		s.mu.Unlock()
+
+		delay := next.Sub(s.clock.Now())
+		if e.job.Jitter > 0 {
+			delay += rand.N(e.job.Jitter)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Schedule the next run from the time this one was due rather than from now,
+		// which may be past it because of the jitter, unless the runs fell behind
+		// (e.g. the host was suspended)
+		after = next
+		if behind := s.clock.Now().Add(-e.job.Jitter); behind.After(after) {
+			after = behind
+		}
+
+		s.mu.Lock()
+		if e.status.Running {
+			e.status.Skipped++
+			s.mu.Unlock()
+			s.logger.WarnContext(
+				ctx, "Job skipped, its previous run is still going", "job", e.job.Name,
+			)
+			continue
+		}
+		e.status.Running = true
+		s.mu.Unlock()
+
+		go s.run(ctx, e)
+	}
+}
+
+// run runs the job of the entry once, with a context holding the logger of the
+// scheduler, and records the outcome in its status.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	ctx = logger.NewContext(ctx, s.logger.With("job", e.job.Name))
+	start := s.clock.Now().UTC()
+	began := time.Now() // The duration of the run is measured by the monotonic clock
+
+	s.mu.Lock()
+	e.status.LastRunAt = &start
+	s.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("panic: %v", v)
+			}
+		}()
+
+		return e.job.Run(ctx)
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.status.Running = false
+	e.status.LastDuration = time.Since(began).Milliseconds()
+	e.status.Runs++
+	e.status.LastError = ""
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+		logger.FromContext(ctx).ErrorContext(ctx, "Job failed", "error", err)
+	}
+}
+
+// lookup returns the entry of the named job, if registered; s.mu must be held.
+func (s *Scheduler) lookup(name string) *entry {
+	i := slices.IndexFunc(s.entries, func(e *entry) bool { return e.job.Name == name })
+	if i < 0 {
+		return nil
+	}
+
+	return s.entries[i]
+}
@@ -0,0 +1,58 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/scheduler"
+)
+
+// TestSchedulerClock checks that the next and the last runs of the jobs are told by the
+// clock of the scheduler.
+func TestSchedulerClock(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 10, 7, 30, 0, time.UTC)
+	jobs := scheduler.New(nil, services.NewFixedClock(now))
+
+	ran := make(chan struct{})
+	err := jobs.Add(scheduler.Job{
+		Name:     "test",
+		Schedule: "0 * * * *",
+		Enabled:  true,
+		Run: func(context.Context) error {
+			close(ran)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to add the job: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs.Start(ctx)
+
+	if ok, err := jobs.Trigger("test"); !ok || err != nil {
+		t.Fatalf("Unable to trigger the job: %v", err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The job was not run")
+	}
+
+	next := time.Date(2026, time.January, 1, 11, 0, 0, 0, time.UTC)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		status := jobs.Status()[0]
+		if status.NextRunAt != nil && status.NextRunAt.Equal(next) &&
+			status.LastRunAt != nil && status.LastRunAt.Equal(now) && !status.Running {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the next run at %s and the last one at %s. Got %+v",
+				next, now, status)
+		}
+	}
+}
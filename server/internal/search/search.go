@@ -0,0 +1,227 @@
+/*
+Package search matches the search queries against the text of the articles, locating
+the matches for the search interfaces to highlight them and cutting snippets of the
+text around them.
+
+A query is split into its terms (see `Terms`), each of which matches the words of a
+text it is a prefix of, regardless of their case (e.g. "prog" matches "Programming").
+The matches are located by their offsets in UTF-16 code units (see `Match`), the way
+JavaScript indexes its strings, so that the clients can highlight them as is, and the
+snippets are HTML-escaped with the matches marked up with `<mark>` elements (see
+`Snippet`), so that they can be rendered as is.
+*/
+package search
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxTerms is the number of terms of a query above which the other terms are ignored.
+const maxTerms = 16
+
+// ellipsis marks the ends of the text cut off from a snippet.
+const ellipsis = "…"
+
+// tagPattern matches the tags of an HTML content.
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Match is a match of a term in a text, by its offsets in UTF-16 code units.
+type Match struct {
+	Start int `json:"start"` // The offset of the first code unit of the match
+	End   int `json:"end"`   // The offset following the last code unit of the match
+}
+
+// span is a match of a term in a text, by its offsets in bytes.
+type span struct {
+	start, end int
+}
+
+// Terms returns the distinct terms of a query in lower case, i.e. its words, ignoring
+// the punctuation. Only the first 16 terms are kept.
+func Terms(query string) []string {
+	terms := []string{}
+	for _, word := range strings.FieldsFunc(query, isSeparator) {
+		term := strings.ToLower(word)
+		if !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+
+	return terms
+}
+
+// Find returns the matches of the terms in the text, in order.
+func Find(text string, terms []string) []Match {
+	spans := find(text, terms)
+
+	// The offsets are converted in a single pass, the spans being in order
+	matches := make([]Match, 0, len(spans))
+	offset, units := 0, 0
+	advance := func(to int) int {
+		for _, r := range text[offset:to] {
+			units += utf16.RuneLen(r)
+		}
+		offset = to
+
+		return units
+	}
+	for _, s := range spans {
+		start := advance(s.start)
+		matches = append(matches, Match{Start: start, End: advance(s.end)})
+	}
+
+	return matches
+}
+
+// Contains reports whether the term matches a word of the text.
+func Contains(text, term string) bool {
+	return len(find(text, []string{term})) > 0
+}
+
+/*
+Snippet returns the passage of the text around the first match of the terms, of about
+length characters (the beginning of the text if no term matches), cut on the words.
+The passage is HTML-escaped, its matches are wrapped in `<mark>` elements and the ends
+of the text cut off are marked with an ellipsis.
+
+Example:
+
+	// "…of the <mark>Go</mark> programming language, which…"
+	snippet := search.Snippet(text, search.Terms("go"), 40)
+*/
+func Snippet(text string, terms []string, length int) string {
+	spans := find(text, terms)
+
+	// The passage starts a quarter of its length before the first match, on a word
+	start := 0
+	if len(spans) > 0 {
+		first := spans[0].start
+		start = first
+		for n := 0; start > 0 && n < length/4; n++ {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+
+		if start > 0 && !unicode.IsSpace(lastRune(text[:start])) {
+			if i := strings.IndexFunc(text[start:first], unicode.IsSpace); i >= 0 {
+				start += i
+			} else {
+				start = first
+			}
+		}
+		start = first - len(strings.TrimLeftFunc(text[start:first], unicode.IsSpace))
+	}
+
+	// The passage ends after length characters, on a word
+	end := start
+	for n := 0; end < len(text) && n < length; n++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	if end < len(text) && !unicode.IsSpace(lastRune(text[:end])) {
+		if i := strings.LastIndexFunc(text[start:end], unicode.IsSpace); i > 0 {
+			end = start + i
+		}
+	}
+	end = start + len(strings.TrimRightFunc(text[start:end], unicode.IsSpace))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+
+	offset := start
+	for _, s := range spans {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+
+		s.start, s.end = max(s.start, start), min(s.end, end)
+		b.WriteString(html.EscapeString(text[offset:s.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[s.start:s.end]))
+		b.WriteString("</mark>")
+		offset = s.end
+	}
+	b.WriteString(html.EscapeString(text[offset:end]))
+
+	if end < len(text) {
+		b.WriteString(ellipsis)
+	}
+
+	return b.String()
+}
+
+// PlainText returns the text of an HTML content, without its tags and with its
+// entities decoded, its whitespace collapsed into single spaces.
+func PlainText(content string) string {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(content, " "))
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// find returns the spans of the matches of the terms in the text, in order. The
+// longest term matching a word wins, so that the spans never overlap.
+func find(text string, terms []string) []span {
+	var spans []span
+
+	previous := ' '
+	for i, r := range text {
+		if isSeparator(previous) && !isSeparator(r) {
+			end := -1
+			for _, term := range terms {
+				if n, ok := prefixFold(text[i:], term); ok && i+n > end {
+					end = i + n
+				}
+			}
+
+			if end >= 0 {
+				spans = append(spans, span{start: i, end: end})
+			}
+		}
+
+		previous = r
+	}
+
+	return spans
+}
+
+// prefixFold reports whether the (lower case) term is a prefix of s regardless of its
+// case, along with the length of the prefix in bytes.
+func prefixFold(s, term string) (int, bool) {
+	if term == "" {
+		return 0, false
+	}
+
+	n := 0
+	for _, want := range term {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if size == 0 || unicode.ToLower(r) != want {
+			return 0, false
+		}
+		n += size
+	}
+
+	return n, true
+}
+
+// lastRune returns the last rune of s.
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// isSeparator reports whether r separates the words, i.e. is neither a letter nor a
+// digit.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,175 @@
+/*
+Package secrets resolves the references to the secrets held outside of the
+configuration of the server, so that the sensitive settings (e.g. the SMTP password or
+the root API key) do not have to be passed as plain environment variables.
+
+A reference is a value of the form `secret://<provider>/<path>`, where the provider is
+one of:
+  - file: The content of a file, e.g. `secret://file/etc/burzcontent/smtp-password`
+    reads `/etc/burzcontent/smtp-password`. The Kubernetes secrets mounted as volumes
+    are read this way.
+  - docker: The content of a Docker secret, e.g. `secret://docker/smtp-password` reads
+    `/run/secrets/smtp-password`.
+  - vault: A field of a secret of the KV (version 2) secrets engine of HashiCorp Vault,
+    e.g. `secret://vault/secret/burzcontent#smtp_password` reads the `smtp_password`
+    field of the `burzcontent` secret of the engine mounted at `secret`. Vault is
+    reached at the `VAULT_ADDR` address with the `VAULT_TOKEN` token.
+
+The trailing line breaks of the files are trimmed, since most editors append one.
+*/
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Scheme is the prefix of the references to the secrets.
+const Scheme = "secret://"
+
+// dockerSecretsDir is the directory the Docker secrets are mounted in.
+const dockerSecretsDir = "/run/secrets"
+
+// ErrInvalidReference is returned when a reference to a secret can not be parsed.
+var ErrInvalidReference = errors.New("invalid secret reference")
+
+// IsReference reports whether the value is a reference to a secret, rather than the
+// secret itself.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+/*
+Resolver resolves the references to the secrets (see the package documentation).
+
+Fields:
+  - VaultAddr: The address of the Vault server, e.g. "https://vault.example.com:8200".
+  - VaultToken: The token authenticating to the Vault server.
+  - Client: The HTTP client reaching the Vault server.
+*/
+type Resolver struct {
+	VaultAddr  string
+	VaultToken string
+	Client     *http.Client
+}
+
+// NewResolver creates and returns a new Resolver reaching Vault as configured by the
+// `VAULT_ADDR` and `VAULT_TOKEN` environment variables.
+func NewResolver() *Resolver {
+	return &Resolver{
+		VaultAddr:  os.Getenv("VAULT_ADDR"),
+		VaultToken: os.Getenv("VAULT_TOKEN"),
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+/*
+Resolve returns the secret referenced by ref. The values which are not references are
+returned as they are.
+
+An error wrapping `ErrInvalidReference` is returned if the reference is invalid, and
+an error if the secret can not be read.
+*/
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	if !IsReference(ref) {
+		return ref, nil
+	}
+
+	provider, name, ok := strings.Cut(strings.TrimPrefix(ref, Scheme), "/")
+	if !ok || name == "" {
+		return "", fmt.Errorf("%w %q: missing path", ErrInvalidReference, ref)
+	}
+
+	switch provider {
+	case "file":
+		return readFile("/" + name)
+	case "docker":
+		if strings.Contains(name, "/") {
+			return "", fmt.Errorf("%w %q: invalid name", ErrInvalidReference, ref)
+		}
+		return readFile(path.Join(dockerSecretsDir, name))
+	case "vault":
+		secret, field, ok := strings.Cut(name, "#")
+		mount, secretPath, hasPath := strings.Cut(secret, "/")
+		if !ok || field == "" || !hasPath || secretPath == "" {
+			return "", fmt.Errorf(
+				"%w %q: expected vault/<mount>/<path>#<field>",
+				ErrInvalidReference,
+				ref,
+			)
+		}
+		return r.readVault(ctx, mount, secretPath, field)
+	default:
+		return "", fmt.Errorf(
+			"%w %q: unknown provider %q", ErrInvalidReference, ref, provider,
+		)
+	}
+}
+
+// readFile returns the content of the file holding a secret, without its trailing
+// line breaks.
+func readFile(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readVault returns the field of the secret at the path of the KV (version 2) secrets
+// engine mounted at mount.
+func (r *Resolver) readVault(
+	ctx context.Context,
+	mount, secretPath, field string,
+) (string, error) {
+	if r.VaultAddr == "" {
+		return "", errors.New("unable to read secret: VAULT_ADDR is not set")
+	}
+
+	url := strings.TrimSuffix(r.VaultAddr, "/") + "/v1/" + mount + "/data/" + secretPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", r.VaultToken)
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf(
+			"unable to read secret: %s: %s",
+			resp.Status,
+			strings.TrimSpace(string(msg)),
+		)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("unable to decode secret: %w", err)
+	}
+
+	value, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("unable to read secret: no string field %q", field)
+	}
+
+	return value, nil
+}
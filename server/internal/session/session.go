@@ -0,0 +1,164 @@
+/*
+Package session provides an in-memory store of the browser sessions of the management
+API, through which the embedded admin interface authenticates its requests.
+
+A session is opened with an API key (see `auth.GenerateKey`), which it holds for its
+lifetime: its token is handed to the browser in an `HttpOnly` cookie (see
+`CookieName`), so that the scripts of the pages can not read it, and the requests
+presenting the cookie are authenticated with the API key of their session, as if they
+presented the key itself. Revoking or rotating the API key hence closes its sessions.
+
+The cookie being sent by the browser along with every request, whichever page it is
+made from, the write requests presenting it also have to present the CSRF token of the
+session in the `X-CSRF-Token` header (see `CSRFHeader`), which only the pages of the
+admin interface are able to read.
+
+The tokens are only stored as their SHA-256 digest (see `auth.HashKey`), and the
+sessions do not survive a restart of the server.
+*/
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/auth"
+	"github.com/Weburz/burzcontent/server/internal/clock"
+)
+
+const (
+	// CookieName is the name of the cookie holding the token of a session.
+	CookieName = "burz_session"
+
+	// CSRFHeader is the header the write requests present the CSRF token of their
+	// session in.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// maxSessions is the number of sessions above which the expired sessions are swept.
+const maxSessions = 10000
+
+/*
+Session represents a browser session opened with an API key.
+
+Fields:
+  - Key: The API key the requests of the session are authenticated with, which is
+    never disclosed.
+  - CSRFToken: The token the write requests of the session have to present in the
+    `X-CSRF-Token` header.
+  - CreatedAt: When the session was opened.
+  - ExpiresAt: When the session expires.
+*/
+type Session struct {
+	Key       string
+	CSRFToken string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// CheckCSRF reports whether the token is the CSRF token of the session, comparing
+// them in constant time.
+func (s Session) CheckCSRF(token string) bool {
+	return token != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(s.CSRFToken)) == 1
+}
+
+// Store is a concurrency-safe store of the sessions, by the digest of their token.
+type Store struct {
+	lifetime time.Duration
+	clock    clock.Clock
+
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// New creates and returns a new Store whose sessions expire after lifetime, as time
+// goes by on the given clock.
+func New(lifetime time.Duration, clock clock.Clock) *Store {
+	return &Store{
+		lifetime: lifetime,
+		clock:    clock,
+		sessions: make(map[string]Session),
+	}
+}
+
+// Create opens a new session authenticated with the API key, and returns its token
+// along with the session.
+func (s *Store) Create(key string) (string, Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", Session{}, fmt.Errorf("unable to generate session token: %w", err)
+	}
+
+	csrf, err := randomToken()
+	if err != nil {
+		return "", Session{}, fmt.Errorf("unable to generate CSRF token: %w", err)
+	}
+
+	now := s.clock.Now()
+	session := Session{
+		Key:       key,
+		CSRFToken: csrf,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.lifetime),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.sessions) >= maxSessions {
+		s.sweep(now)
+	}
+	s.sessions[auth.HashKey(token)] = session
+
+	return token, session, nil
+}
+
+// Get returns the session of the token and whether it was found, the expired sessions
+// not being found.
+func (s *Store) Get(token string) (Session, bool) {
+	now := s.clock.Now()
+	digest := auth.HashKey(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[digest]
+	if ok && !now.Before(session.ExpiresAt) {
+		delete(s.sessions, digest)
+		return Session{}, false
+	}
+
+	return session, ok
+}
+
+// Delete closes the session of the token, if any.
+func (s *Store) Delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, auth.HashKey(token))
+}
+
+// sweep removes the expired sessions; s.mu must be held.
+func (s *Store) sweep(now time.Time) {
+	for digest, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(s.sessions, digest)
+		}
+	}
+}
+
+// randomToken returns 32 random bytes encoded with the URL-safe base64 alphabet.
+func randomToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
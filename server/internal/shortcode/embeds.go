@@ -0,0 +1,222 @@
+package shortcode
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// youTubeID matches the ID of a YouTube video.
+	youTubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+	// tweetPath matches the path of the URL of a tweet, e.g. `/weburz/status/123`.
+	tweetPath = regexp.MustCompile(`^/([A-Za-z0-9_]{1,15})/status(?:es)?/([0-9]+)/?$`)
+
+	// gistPath matches the path of a gist, with or without its owner, e.g.
+	// `weburz/1a2b3c`.
+	gistPath = regexp.MustCompile(`^/?(?:([A-Za-z0-9-]+)/)?([0-9a-f]+)/?$`)
+
+	// imgPattern matches the `<img>` tags of the (sanitized) HTML.
+	imgPattern = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+
+	// tagPattern matches the tags of the HTML.
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+	// imgAttrPattern matches the source and the alternative text of an `<img>` tag, as
+	// written by the sanitizer.
+	imgAttrPattern = regexp.MustCompile(`\b(src|alt)="([^"]*)"`)
+)
+
+/*
+Default creates and returns a new Registry with the built-in shortcodes, whose embeds
+are sanitized by the given sanitizer:
+  - `[youtube <video ID or URL>]`: A YouTube video, played from the privacy-enhanced
+    domain of YouTube. Its `title` argument is the title of its frame.
+  - `[twitter <tweet URL>]` (or `[tweet ...]`): A tweet, as the quote the widget of X
+    (Twitter) embedded by the frontend turns into the tweet.
+  - `[gist <owner/ID or URL>]`: A GitHub Gist, optionally limited to one of its files
+    with its `file` argument.
+  - `[gallery]...[/gallery]`: A gallery of the images (or image URLs) it encloses, laid
+    out in as many columns as its `columns` argument (3 by default), with the caption
+    of its `caption` argument.
+*/
+func Default(sanitizer Sanitizer) *Registry {
+	r := NewRegistry(sanitizer)
+	r.Register("youtube", youTube)
+	r.Register("twitter", tweet)
+	r.Register("tweet", tweet)
+	r.Register("gist", gist)
+	r.Register("gallery", gallery)
+
+	return r
+}
+
+// youTube expands the shortcode of a YouTube video, given by its ID or URL.
+func youTube(s Shortcode) (string, error) {
+	raw := cmp.Or(s.Arg("id", 0), s.Attrs["url"])
+
+	id := raw
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		id = youTubeVideo(u)
+	}
+	if !youTubeID.MatchString(id) {
+		return "", fmt.Errorf("%w: %q is not a YouTube video", ErrInvalidShortcode, raw)
+	}
+
+	return fmt.Sprintf(
+		`<figure class="embed embed-youtube"><iframe `+
+			`src="https://www.youtube-nocookie.com/embed/%s" title="%s" width="560" `+
+			`height="315" allow="encrypted-media; picture-in-picture; fullscreen" `+
+			`allowfullscreen loading="lazy"></iframe></figure>`,
+		id,
+		html.EscapeString(cmp.Or(s.Attrs["title"], "YouTube video")),
+	), nil
+}
+
+// youTubeVideo returns the ID of the video of the YouTube URL, or an empty string if it
+// is not the URL of a video.
+func youTubeVideo(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+
+	switch host {
+	case "youtu.be":
+		return strings.Trim(u.Path, "/")
+	case "youtube.com", "youtube-nocookie.com":
+		if u.Path == "/watch" {
+			return u.Query().Get("v")
+		}
+
+		for _, prefix := range []string{"/embed/", "/shorts/", "/live/"} {
+			if id, ok := strings.CutPrefix(u.Path, prefix); ok {
+				return strings.Trim(id, "/")
+			}
+		}
+	}
+
+	return ""
+}
+
+// tweet expands the shortcode of a tweet, given by its URL on X or Twitter.
+func tweet(s Shortcode) (string, error) {
+	raw := s.Arg("url", 0)
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is not a tweet", ErrInvalidShortcode, raw)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "mobile.")
+	m := tweetPath.FindStringSubmatch(u.Path)
+	if (host != "twitter.com" && host != "x.com") || m == nil {
+		return "", fmt.Errorf("%w: %q is not a tweet", ErrInvalidShortcode, raw)
+	}
+
+	link := "https://twitter.com/" + m[1] + "/status/" + m[2]
+	return fmt.Sprintf(
+		`<blockquote class="twitter-tweet"><a href="%s">%s</a></blockquote>`,
+		link,
+		link,
+	), nil
+}
+
+// gist expands the shortcode of a GitHub Gist, given by its owner and ID (or its ID
+// only) or by its URL.
+func gist(s Shortcode) (string, error) {
+	raw := cmp.Or(s.Arg("id", 0), s.Attrs["url"])
+
+	path := raw
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		if !strings.EqualFold(u.Hostname(), "gist.github.com") {
+			return "", fmt.Errorf("%w: %q is not a gist", ErrInvalidShortcode, raw)
+		}
+		path = u.Path
+	}
+
+	m := gistPath.FindStringSubmatch(path)
+	if m == nil {
+		return "", fmt.Errorf("%w: %q is not a gist", ErrInvalidShortcode, raw)
+	}
+
+	src := "https://gist.github.com/" + strings.TrimPrefix(m[1]+"/"+m[2], "/") + ".pibb"
+	if file := s.Attrs["file"]; file != "" {
+		src += "?" + url.Values{"file": {file}}.Encode()
+	}
+
+	return fmt.Sprintf(
+		`<figure class="embed embed-gist"><iframe src="%s" title="GitHub Gist" `+
+			`width="100%%" height="400" loading="lazy"></iframe></figure>`,
+		html.EscapeString(src),
+	), nil
+}
+
+// gallery expands the shortcode of a gallery of the images it encloses, given as
+// `<img>` tags or as URLs.
+func gallery(s Shortcode) (string, error) {
+	columns := 3
+	if raw, ok := s.Attrs["columns"]; ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 6 {
+			return "", fmt.Errorf(
+				"%w: %q columns, must be 1 to 6", ErrInvalidShortcode, raw,
+			)
+		}
+		columns = n
+	}
+
+	var images []string
+	for _, img := range imgPattern.FindAllString(s.Content, -1) {
+		var src, alt string
+		for _, m := range imgAttrPattern.FindAllStringSubmatch(img, -1) {
+			if m[1] == "src" {
+				src = html.UnescapeString(m[2])
+			} else {
+				alt = html.UnescapeString(m[2])
+			}
+		}
+		if src != "" {
+			images = append(images, image(src, alt))
+		}
+	}
+
+	// The URLs given as text, outside of the tags
+	text := tagPattern.ReplaceAllString(s.Content, " ")
+	for _, field := range strings.Fields(html.UnescapeString(text)) {
+		if u, err := url.Parse(field); err == nil &&
+			(u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+			images = append(images, image(field, ""))
+		}
+	}
+
+	if len(images) == 0 {
+		return "", fmt.Errorf("%w: the gallery holds no image", ErrInvalidShortcode)
+	}
+
+	caption := ""
+	if s.Attrs["caption"] != "" {
+		caption = "<figcaption>" + html.EscapeString(s.Attrs["caption"]) +
+			"</figcaption>"
+	}
+
+	return fmt.Sprintf(
+		`<figure class="embed embed-gallery gallery-columns-%d">%s%s</figure>`,
+		columns,
+		strings.Join(images, ""),
+		caption,
+	), nil
+}
+
+// image returns the `<img>` tag of an image of a gallery.
+func image(src, alt string) string {
+	return fmt.Sprintf(
+		`<img src="%s" alt="%s" loading="lazy">`,
+		html.EscapeString(src),
+		html.EscapeString(alt),
+	)
+}
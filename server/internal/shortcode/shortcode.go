@@ -0,0 +1,290 @@
+/*
+Package shortcode expands the shortcodes of the content of the articles (e.g.
+`[youtube dQw4w9WgXcQ]`) into the HTML of their embeds.
+
+A shortcode is made of its name and of its arguments within square brackets, the
+arguments being either positional (`[gist weburz/1a2b3c]`) or named (`[youtube
+id="dQw4w9WgXcQ" title="Demo"]`), and their values being quoted or not. A shortcode may
+enclose content up to its closing tag, e.g. the images of a gallery:
+
+	[gallery columns="2"]
+	<img src="https://example.com/a.jpg" alt="A">
+	<img src="https://example.com/b.jpg" alt="B">
+	[/gallery]
+
+The shortcodes are expanded by the handlers of a `Registry`, with which the sites can
+register their own embeds along with the built-in ones (see `Default`). The HTML of
+each embed is sanitized, and the shortcodes which are unknown or malformed are left as
+they are. A shortcode is escaped by doubling its brackets (e.g. `[[youtube
+dQw4w9WgXcQ]]`), which leaves it verbatim without its extra brackets.
+*/
+package shortcode
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidShortcode is returned by the handlers when a shortcode is malformed, e.g.
+// when its URL is not one of the embedded service.
+var ErrInvalidShortcode = errors.New("invalid shortcode")
+
+/*
+Shortcode represents a shortcode of the content of an article.
+
+Fields:
+  - Name: The name of the shortcode, in lowercase.
+  - Args: The positional arguments of the shortcode, unescaped.
+  - Attrs: The named arguments of the shortcode by their name (in lowercase),
+    unescaped.
+  - Content: The content enclosed by the shortcode, up to its closing tag (empty if it
+    has none).
+*/
+type Shortcode struct {
+	Name    string
+	Args    []string
+	Attrs   map[string]string
+	Content string
+}
+
+// Arg returns the value of the named argument of the shortcode, or of its positional
+// argument at index i if it has none (an empty string if it has neither).
+func (s Shortcode) Arg(name string, i int) string {
+	if value, ok := s.Attrs[name]; ok {
+		return value
+	} else if i < len(s.Args) {
+		return s.Args[i]
+	}
+
+	return ""
+}
+
+// Handler expands a shortcode into HTML, or returns an error (e.g.
+// `ErrInvalidShortcode`) if the shortcode can not be expanded.
+type Handler func(s Shortcode) (string, error)
+
+// Sanitizer sanitizes the HTML of the embeds, like the policies of the `sanitize`
+// package do.
+type Sanitizer interface {
+	Sanitize(s string) string
+}
+
+// Registry holds the handlers of the shortcodes by their name, and expands the
+// shortcodes of the content with them. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	sanitizer Sanitizer
+}
+
+// NewRegistry creates and returns a new Registry without any shortcode, whose embeds
+// are sanitized by the given sanitizer (e.g. `sanitize.EmbedPolicy()`).
+func NewRegistry(sanitizer Sanitizer) *Registry {
+	return &Registry{
+		handlers:  make(map[string]Handler),
+		sanitizer: sanitizer,
+	}
+}
+
+// Register registers the handler of the named shortcode, replacing the one it had if
+// any. The names of the shortcodes are case-insensitive.
+func (r *Registry) Register(name string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[strings.ToLower(name)] = handler
+}
+
+// Names returns the names of the registered shortcodes, in alphabetical order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+/*
+Expand returns the (sanitized) HTML content with its registered shortcodes expanded
+into their (sanitized) embeds.
+
+A shortcode standing alone in its paragraph (e.g. `<p>[youtube dQw4w9WgXcQ]</p>`) is
+expanded in place of the paragraph, so that its embed is not nested in it. The
+shortcodes which are not registered, or whose handler fails, are left as they are.
+*/
+func (r *Registry) Expand(content string) string {
+	var b bytes.Buffer
+	b.Grow(len(content))
+
+	for content != "" {
+		i := strings.IndexByte(content, '[')
+		if i < 0 {
+			b.WriteString(content)
+			break
+		}
+
+		b.WriteString(content[:i])
+		content = content[i:]
+
+		if strings.HasPrefix(content, "[[") {
+			// Leave the escaped shortcode verbatim, without its extra brackets
+			if _, rest, ok := r.parse(content[1:]); ok && strings.HasPrefix(rest, "]") {
+				b.WriteString(content[1 : len(content)-len(rest)])
+				content = rest[1:]
+				continue
+			}
+		}
+
+		s, rest, ok := r.parse(content)
+		if !ok {
+			b.WriteByte('[')
+			content = content[1:]
+			continue
+		}
+
+		embed, err := r.handler(s.Name)(s)
+		if err != nil {
+			b.WriteByte('[')
+			content = content[1:]
+			continue
+		}
+
+		if b.Len() >= 3 && bytes.HasSuffix(b.Bytes(), []byte("<p>")) &&
+			strings.HasPrefix(rest, "</p>") {
+			b.Truncate(b.Len() - 3)
+			rest = rest[4:]
+		}
+
+		b.WriteString(r.sanitizer.Sanitize(embed))
+		content = rest
+	}
+
+	return b.String()
+}
+
+// handler returns the handler of the named shortcode, or nil if it is not registered.
+func (r *Registry) handler(name string) Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.handlers[name]
+}
+
+// parse parses the registered shortcode s starts with, along with the content it
+// encloses if any, returning the rest of s, or reports that s does not start with one.
+func (r *Registry) parse(s string) (Shortcode, string, bool) {
+	i := 1
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	if i == 1 || !isLetter(s[1]) {
+		return Shortcode{}, s, false
+	}
+
+	sc := Shortcode{Name: strings.ToLower(s[1:i]), Attrs: make(map[string]string)}
+	if r.handler(sc.Name) == nil {
+		return Shortcode{}, s, false
+	}
+
+	for {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) || s[i] == '<' || s[i] == '[' {
+			return Shortcode{}, s, false
+		} else if s[i] == ']' {
+			i++
+			break
+		} else if s[i] == '/' && i+1 < len(s) && s[i+1] == ']' {
+			// The self-closing shortcode, e.g. `[youtube dQw4w9WgXcQ /]`
+			i += 2
+			break
+		}
+
+		start := i
+		for i < len(s) && isNameChar(s[i]) {
+			i++
+		}
+		name := ""
+		if i > start && isLetter(s[start]) && i < len(s) && s[i] == '=' {
+			name = strings.ToLower(s[start:i])
+			i++
+		} else {
+			i = start
+		}
+
+		value, n, ok := parseValue(s[i:])
+		if !ok {
+			return Shortcode{}, s, false
+		}
+		i += n
+
+		if name != "" {
+			sc.Attrs[name] = value
+		} else {
+			sc.Args = append(sc.Args, value)
+		}
+	}
+
+	rest := s[i:]
+	closing := "[/" + sc.Name + "]"
+	if end := strings.Index(strings.ToLower(rest), closing); end >= 0 {
+		sc.Content = rest[:end]
+		rest = rest[end+len(closing):]
+	}
+
+	return sc, rest, true
+}
+
+// parseValue parses the (quoted or unquoted) value of an argument s starts with,
+// returning its unescaped value and its length in s, or reports that s does not start
+// with one.
+func parseValue(s string) (string, int, bool) {
+	if s == "" {
+		return "", 0, false
+	}
+
+	if quote := s[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(s[1:], quote)
+		if end < 0 || strings.ContainsAny(s[1:1+end], "<\n") {
+			return "", 0, false
+		}
+
+		return html.UnescapeString(s[1 : 1+end]), end + 2, true
+	}
+
+	i := 0
+	for i < len(s) && !isSpace(s[i]) && s[i] != ']' && s[i] != '<' && s[i] != '[' {
+		i++
+	}
+	if i == 0 {
+		return "", 0, false
+	}
+
+	return html.UnescapeString(s[:i]), i, true
+}
+
+// isNameChar reports whether c can be part of the name of a shortcode or of an
+// argument.
+func isNameChar(c byte) bool {
+	return isLetter(c) || '0' <= c && c <= '9' || c == '-' || c == '_'
+}
+
+// isLetter reports whether c is an ASCII letter.
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// isSpace reports whether c is a whitespace character.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,61 @@
+/*
+Package tenant carries the site (the tenant) a request was resolved to through the
+request context.
+
+The site is resolved once per request by the `middleware.Tenant` middleware and then
+read by the services through `SiteID`, which they pass on to the repositories so that
+every query is scoped to a single site.
+*/
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Weburz/burzcontent/server/internal/api/models"
+)
+
+// contextKey is the unexported type of the context key holding the resolved site.
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the resolved site.
+func NewContext(parent context.Context, site models.Site) context.Context {
+	return context.WithValue(parent, contextKey{}, site)
+}
+
+// FromContext returns the site held by the context and whether one was found.
+func FromContext(ctx context.Context) (models.Site, bool) {
+	site, ok := ctx.Value(contextKey{}).(models.Site)
+	return site, ok
+}
+
+/*
+SiteID returns the unique identifier of the site held by the context.
+
+If the context does not hold a site, `uuid.Nil` is returned, which never matches any
+stored resource. Hence a request which was not resolved to a site can never read or
+modify the data of any site.
+*/
+func SiteID(ctx context.Context) uuid.UUID {
+	site, ok := FromContext(ctx)
+	if !ok {
+		return uuid.Nil
+	}
+
+	return site.ID
+}
+
+// Location returns the time zone of the site held by the context, as configured in its
+// settings (UTC if the context does not hold a site).
+func Location(ctx context.Context) *time.Location {
+	site, _ := FromContext(ctx)
+
+	loc, err := time.LoadLocation(site.EffectiveSettings().Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
@@ -10,6 +10,10 @@ Functions:
     the recorded response.
   - CheckResponseCode: Compares the expected and actual HTTP response
     codes, reporting errors if they don't match.
+  - BenchmarkRequest: Repeatedly executes an HTTP request using a handler to measure
+    the performance of the hot paths (serialization, validation, middleware chain).
+  - NewDeterministicHandlers: Creates the handlers of a server whose responses are the
+    same on every run, so that their exact bodies can be asserted.
 */
 package testutils
 
@@ -17,6 +21,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/api/handlers"
+	"github.com/Weburz/burzcontent/server/internal/api/services"
+	"github.com/Weburz/burzcontent/server/internal/ids"
+	"github.com/Weburz/burzcontent/server/internal/repository"
 )
 
 /*
@@ -65,3 +75,87 @@ func CheckResponseCode(t *testing.T, expected, actual int) {
 		t.Errorf("Expected response code %d. Got %d\n", expected, actual)
 	}
 }
+
+/*
+BenchmarkRequest repeatedly executes the HTTP requests built by newRequest against the
+given handler and reports the time and allocations spent per request.
+
+A new request is built for every iteration since request bodies can only be read
+once. The response code of every iteration is compared against the expected one, so
+that a benchmark never silently measures an error path.
+
+Parameters:
+
+	b: The testing.B instance driving the benchmark.
+	h: The HTTP handler (usually the full router) that processes the request.
+	expected: The expected HTTP status code.
+	newRequest: A function building the HTTP request to be executed.
+
+Example:
+
+	func BenchmarkGetSettings(b *testing.B) {
+		h, _ := testutils.NewDeterministicHandlers(1, time.Unix(0, 0), opts)
+		server, _ := api.NewAPI(config.NewConfig(), h)
+		testutils.BenchmarkRequest(b, server.Router, http.StatusOK,
+			func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/settings", nil)
+				req.Host = "localhost"
+				return req
+			})
+	}
+*/
+func BenchmarkRequest(
+	b *testing.B,
+	h http.Handler,
+	expected int,
+	newRequest func() *http.Request,
+) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		b.StopTimer()
+		req := newRequest()
+		b.StartTimer()
+
+		rr := ExecuteRequest(req, h)
+		if rr.Code != expected {
+			b.Fatalf("Expected response code %d. Got %d\n", expected, rr.Code)
+		}
+	}
+}
+
+/*
+NewDeterministicHandlers creates the handlers of a server whose responses are the same
+on every run: the identifiers of the seeded and the new resources are generated by an
+`ids.Sequential` generator from the given seed, and the resources are stamped with the
+time told by a `services.FixedClock`, which is returned so that the tests can advance
+it (e.g. past the expiry of a share link).
+
+The given options are used for the other settings of the handlers.
+
+Parameters:
+
+	seed: The seed of the identifiers.
+	now: The time the clock is fixed at.
+	opts: The other options of the handlers.
+
+Example:
+
+	h, clock := testutils.NewDeterministicHandlers(1, time.Unix(0, 0), opts)
+	server, _ := api.NewAPI(config.NewConfig(), h)
+*/
+func NewDeterministicHandlers(
+	seed uint64,
+	now time.Time,
+	opts handlers.Options,
+) (*handlers.Handlers, *services.FixedClock) {
+	generator := ids.NewSequential(seed)
+	clock := services.NewFixedClock(now)
+
+	opts.IDs = generator
+	opts.Clock = clock
+
+	store := repository.NewMemoryStoreWith(generator, clock.Now(), nil)
+
+	return handlers.NewHandlers(store, opts), clock
+}
@@ -0,0 +1,271 @@
+/*
+Package textdiff compares texts line by line.
+
+The differences are computed with the algorithm of Eugene W. Myers ("An O(ND)
+Difference Algorithm and Its Variations", 1986), which finds the shortest sequence of
+edits turning a text into another one, and can be written in the unified format of the
+`diff -u` command.
+*/
+package textdiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Op is the operation of an edit.
+type Op int
+
+const (
+	// Equal keeps a line of the old text in the new text.
+	Equal Op = iota
+
+	// Delete removes a line of the old text.
+	Delete
+
+	// Insert adds a line of the new text.
+	Insert
+)
+
+// Edit is an edit of a single line turning the old text into the new text.
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// maxEdits is the number of edits above which the differences are not minimized
+// anymore, since the cost of the algorithm grows with the square of the number of
+// edits: the remaining lines of the old text are then all deleted, and the ones of
+// the new text all inserted.
+const maxEdits = 1000
+
+// contextLines is the number of unchanged lines written around the changes in the
+// unified format.
+const contextLines = 3
+
+// SplitLines splits a text into its lines, without their line feeds (none if the text
+// is empty).
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Lines returns the edits turning the lines a into the lines b, in order.
+func Lines(a, b []string) []Edit {
+	// The common prefix and suffix are kept as is, so that the algorithm only runs on
+	// the lines in between
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{Op: Equal, Line: line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Op: Equal, Line: line})
+	}
+
+	return edits
+}
+
+/*
+myers returns the shortest sequence of edits turning the lines a into the lines b (or
+a longer one if it requires more than `maxEdits` edits).
+
+The furthest reaching path on each diagonal k (the lines of a consumed minus the ones
+of b) is tracked for each number of edits d, from which the edits are backtracked
+once a path reaches the end of both a and b.
+*/
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+
+	// v holds the number of lines of a consumed by the furthest reaching path of each
+	// diagonal k, at index k+offset
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down from diagonal k+1, inserting a line of b
+			} else {
+				x = v[offset+k-1] + 1 // Right from diagonal k-1, deleting a line of a
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset)
+			}
+		}
+	}
+
+	edits := make([]Edit, 0, n+m)
+	for _, line := range a {
+		edits = append(edits, Edit{Op: Delete, Line: line})
+	}
+	for _, line := range b {
+		edits = append(edits, Edit{Op: Insert, Line: line})
+	}
+
+	return edits
+}
+
+// backtrack returns the edits of the path reaching the end of both a and b, from the
+// furthest reaching paths traced before each number of edits d.
+func backtrack(a, b []string, trace [][]int, offset int) []Edit {
+	var edits []Edit
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{Op: Equal, Line: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{Op: Insert, Line: b[prevY]})
+			} else {
+				edits = append(edits, Edit{Op: Delete, Line: a[prevX]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(edits)
+
+	return edits
+}
+
+/*
+Unified returns the differences between the lines a and b in the unified format,
+labelling them with the given names, e.g.:
+
+	--- revision 1
+	+++ revision 2
+	@@ -1,3 +1,3 @@
+	 title: Go Basics
+	-author: John Doe
+	+author: Jane Smith
+	 isPublished: true
+
+An empty string is returned if the lines are the same.
+*/
+func Unified(nameA, nameB string, a, b []string) string {
+	edits := Lines(a, b)
+
+	// changes holds the indexes of the edits which are not equal
+	var changes []int
+	for i, edit := range edits {
+		if edit.Op != Equal {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	// starts holds the line numbers of a and b (from 0) each edit starts at
+	type position struct{ a, b int }
+	starts := make([]position, len(edits))
+	var pos position
+	for i, edit := range edits {
+		starts[i] = pos
+		if edit.Op != Insert {
+			pos.a++
+		}
+		if edit.Op != Delete {
+			pos.b++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for i := 0; i < len(changes); {
+		// The changes separated by at most twice the context make up a single hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*contextLines+1 {
+			j++
+		}
+
+		first := max(changes[i]-contextLines, 0)
+		last := min(changes[j]+contextLines, len(edits)-1)
+
+		countA, countB := 0, 0
+		for _, edit := range edits[first : last+1] {
+			if edit.Op != Insert {
+				countA++
+			}
+			if edit.Op != Delete {
+				countB++
+			}
+		}
+
+		fmt.Fprintf(
+			&sb,
+			"@@ -%s +%s @@\n",
+			hunkRange(starts[first].a, countA),
+			hunkRange(starts[first].b, countB),
+		)
+		for _, edit := range edits[first : last+1] {
+			sb.WriteString([...]string{" ", "-", "+"}[edit.Op] + edit.Line + "\n")
+		}
+
+		i = j + 1
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats the range of lines of a hunk starting at the given line (from 0),
+// as written in its header.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		// An empty range refers to the line preceding it
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
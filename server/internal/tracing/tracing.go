@@ -0,0 +1,115 @@
+/*
+Package tracing provides the trace context of the requests served by the server, as
+defined by the W3C Trace Context specification (see
+https://www.w3.org/TR/trace-context/), so that the logs of a request can be joined with
+its traces.
+
+The trace context of a request is read from its `traceparent` header, or started anew
+if the header is missing or invalid (see `middleware.Trace`), and stored in the context
+of the request (see `NewContext`). Every record logged within the request carries its
+trace and span IDs (see `logger.New`). Once the server is instrumented with
+OpenTelemetry, the span context of its spans takes over the one of this package.
+*/
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// contextKey is the type of the key the span context is stored under in a context.
+type contextKey struct{}
+
+/*
+SpanContext represents the span of a trace serving a request.
+
+Fields:
+  - TraceID: The ID of the trace, as 32 lowercase hexadecimal digits.
+  - SpanID: The ID of the span, as 16 lowercase hexadecimal digits.
+  - Sampled: Whether the trace is sampled (i.e. recorded) by the caller.
+*/
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+/*
+Parse parses the value of a `traceparent` header, e.g.
+"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", and reports whether it is
+valid. The IDs made of zeros only are invalid.
+*/
+func Parse(traceparent string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+
+	// The later versions may append fields, but not the first one
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isID(traceID, 32) || !isID(spanID, 16) || len(flags) != 2 {
+		return SpanContext{}, false
+	}
+
+	bits, err := hex.DecodeString(flags)
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: bits[0]&1 == 1}, true
+}
+
+// isID reports whether the value is an ID of the given number of lowercase
+// hexadecimal digits, which are not all zeros.
+func isID(value string, digits int) bool {
+	if len(value) != digits || strings.Trim(value, "0") == "" {
+		return false
+	}
+
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewTraceID generates a new random trace ID.
+func NewTraceID() string {
+	return randomID(16)
+}
+
+// NewSpanID generates a new random span ID.
+func NewSpanID() string {
+	return randomID(8)
+}
+
+// randomID generates a random ID of the given number of bytes, as hexadecimal digits.
+func randomID(size int) string {
+	b := make([]byte, size)
+
+	// The generation can only fail if the system random number generator does, which
+	// never happens since Go 1.24
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// NewContext returns a copy of the context holding the span context.
+func NewContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the span context held by the context, and reports whether it
+// holds one.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKey{}).(SpanContext)
+	return sc, ok
+}
@@ -0,0 +1,151 @@
+/*
+Package webhook implements the client side of the outbound webhooks of the sites,
+through which the events of a site are posted to the endpoints of the integrators.
+
+Each request is signed with the secret of its endpoint, in the `X-Burz-Signature`
+header (see `Sign`), so that the endpoint can check that it was sent by the server and
+was not replayed later on: the header holds the time the request was signed at and the
+HMAC-SHA256 of that time, `.` and the body, e.g. `t=1700000000,v1=<hex>`.
+
+The endpoints are chosen by the administrators of the sites, hence the client refuses
+to connect to the loopback, private and link-local addresses (see the `safedial`
+package), so that it can not be used to reach the internal network of the server.
+*/
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/safedial"
+)
+
+// SecretPrefix is the prefix of the secrets of the endpoints, which tells them apart
+// from the other secrets of the server.
+const SecretPrefix = "whsec_"
+
+// maxResponseSize is the number of bytes of a response read before it is discarded.
+const maxResponseSize = 64 << 10
+
+// ErrForbiddenAddress is returned when an endpoint resolves to an address the client
+// refuses to connect to (e.g. a loopback or private address, see `safedial`).
+var ErrForbiddenAddress = safedial.ErrForbiddenAddress
+
+/*
+Message represents a request posted to an endpoint.
+
+Fields:
+  - ID: The unique identifier of the delivery, sent in the `X-Burz-Delivery` header,
+    which stays the same when the delivery is retried.
+  - Event: The type of the event, sent in the `X-Burz-Event` header.
+  - Payload: The JSON body of the request.
+  - SignedAt: The time the request is signed at.
+*/
+type Message struct {
+	ID       string
+	Event    string
+	Payload  []byte
+	SignedAt time.Time
+}
+
+// Client posts the messages of the webhooks to their endpoints.
+type Client struct {
+	http      *http.Client
+	userAgent string
+}
+
+// NewClient creates and returns a new Client whose requests time out after timeout
+// and are made with the given `User-Agent` header.
+func NewClient(timeout time.Duration, userAgent string) *Client {
+	dialer := safedial.NewDialer(timeout)
+
+	return &Client{
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+			// Do not follow the redirects, which the endpoint could use to reach
+			// another host than the one the administrators registered
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		userAgent: userAgent,
+	}
+}
+
+/*
+Send posts the message to the endpoint, signed with the secret, and returns the status
+code of the response.
+
+An error is returned if the endpoint can not be reached or does not respond with a 2xx
+status, in which case the status code is returned too (or 0 if there is no response).
+*/
+func (c *Client) Send(
+	ctx context.Context,
+	endpoint, secret string,
+	msg Message,
+) (int, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		endpoint,
+		bytes.NewReader(msg.Payload),
+	)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("X-Burz-Event", msg.Event)
+	req.Header.Set("X-Burz-Delivery", msg.ID)
+	req.Header.Set("X-Burz-Signature", Sign(secret, msg.SignedAt, msg.Payload))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain the response, so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf(
+			"webhook rejected by %s: %s", endpoint, resp.Status,
+		)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the value of the `X-Burz-Signature` header of the payload signed with
+// the secret at the given time, e.g. `t=1700000000,v1=<hex>`.
+func Sign(secret string, at time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret generates a new random secret for an endpoint, made of
+// `SecretPrefix` and 32 random bytes encoded in hexadecimal.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("unable to generate webhook secret: %w", err)
+	}
+
+	return SecretPrefix + hex.EncodeToString(secret), nil
+}
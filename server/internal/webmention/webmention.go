@@ -0,0 +1,296 @@
+/*
+Package webmention implements the client side of the Webmention protocol
+(https://www.w3.org/TR/webmention/): discovering the endpoint of a page and sending it
+a webmention, and verifying that the source of a received webmention links to its
+target.
+
+The URLs the client fetches are chosen by third parties (e.g. the source of a received
+webmention), hence it refuses to connect to the loopback, private and link-local
+addresses (see the `safedial` package), so that it can not be used to reach the
+internal network of the server.
+*/
+package webmention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Weburz/burzcontent/server/internal/safedial"
+)
+
+// maxBodySize is the number of bytes of a page read to find its links.
+const maxBodySize = 1 << 20
+
+var (
+	// ErrNoEndpoint is returned when a page does not advertise any webmention endpoint.
+	ErrNoEndpoint = errors.New("no webmention endpoint")
+
+	// ErrNoLink is returned when the source of a webmention does not link to its
+	// target.
+	ErrNoLink = errors.New("source does not link to target")
+
+	// ErrSourceGone is returned when the source of a webmention no longer exists.
+	ErrSourceGone = errors.New("source no longer exists")
+
+	// ErrForbiddenAddress is returned when a URL resolves to an address the client
+	// refuses to connect to (e.g. a loopback or private address, see `safedial`).
+	ErrForbiddenAddress = safedial.ErrForbiddenAddress
+)
+
+var (
+	// tagPattern matches the `<a>` and `<link>` tags of a page.
+	tagPattern = regexp.MustCompile(`(?i)<(a|link)\b[^>]*>`)
+
+	// attrPattern matches the attributes of a tag, whose value is quoted or not.
+	attrPattern = regexp.MustCompile(
+		`(?i)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`,
+	)
+
+	// linkHeaderPattern matches a link of a `Link` header, e.g.
+	// `<https://example.com/webmention>; rel="webmention"`.
+	linkHeaderPattern = regexp.MustCompile(`<([^>]*)>\s*;\s*rel="?([^";,]*)"?`)
+)
+
+// Client discovers the webmention endpoints, sends the webmentions and verifies the
+// received ones.
+type Client struct {
+	http      *http.Client
+	userAgent string
+}
+
+// NewClient creates and returns a new Client whose requests time out after timeout
+// and are made with the given `User-Agent` header.
+func NewClient(timeout time.Duration, userAgent string) *Client {
+	dialer := safedial.NewDialer(timeout)
+
+	return &Client{
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+		userAgent: userAgent,
+	}
+}
+
+/*
+Discover returns the webmention endpoint advertised by the page at target, in the
+`Link` header of its response or in a `<link>` or `<a>` element of its HTML, resolved
+against the URL of the page.
+
+`ErrNoEndpoint` is returned if the page does not advertise any endpoint.
+*/
+func (c *Client) Discover(ctx context.Context, target string) (string, error) {
+	resp, body, err := c.get(ctx, target)
+	if err != nil {
+		return "", err
+	}
+
+	var endpoint string
+	for _, header := range resp.Header.Values("Link") {
+		for _, m := range linkHeaderPattern.FindAllStringSubmatch(header, -1) {
+			if endpoint == "" && hasRel(m[2], "webmention") {
+				endpoint = m[1]
+			}
+		}
+	}
+
+	if endpoint == "" && isHTML(resp) {
+		for _, t := range tags(body) {
+			if rel, ok := t.attrs["rel"]; ok && hasRel(rel, "webmention") {
+				if href, ok := t.attrs["href"]; ok {
+					endpoint = href
+					break
+				}
+			}
+		}
+	}
+
+	if endpoint == "" {
+		return "", ErrNoEndpoint
+	}
+
+	resolved, err := resp.Request.URL.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid webmention endpoint %q: %w", endpoint, err)
+	}
+
+	return resolved.String(), nil
+}
+
+// Send sends the webmention of source linking to target to the endpoint.
+func (c *Client) Send(ctx context.Context, endpoint, source, target string) error {
+	form := url.Values{"source": {source}, "target": {target}}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		endpoint,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webmention rejected by %s: %s", endpoint, resp.Status)
+	}
+
+	return nil
+}
+
+/*
+Verify verifies that the page at source links to target, returning the title of the
+page (if any).
+
+`ErrSourceGone` is returned if the page no longer exists, and `ErrNoLink` if it does
+not link to target.
+*/
+func (c *Client) Verify(ctx context.Context, source, target string) (string, error) {
+	resp, body, err := c.get(ctx, source)
+	if err != nil {
+		return "", err
+	}
+
+	if u, err := url.Parse(target); err == nil {
+		u.Fragment = ""
+		target = u.String()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusNotFound:
+		return "", ErrSourceGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return "", fmt.Errorf("unable to fetch %s: %s", source, resp.Status)
+	case isHTML(resp) && !slices.Contains(Links(body, resp.Request.URL), target):
+		return "", ErrNoLink
+	case !isHTML(resp) && !strings.Contains(body, target):
+		return "", ErrNoLink
+	}
+
+	return title(body), nil
+}
+
+/*
+Links returns the absolute HTTP(S) URLs the `<a>` elements of the HTML link to, in
+order and without duplicates, the relative URLs being resolved against base (if not
+nil).
+*/
+func Links(body string, base *url.URL) []string {
+	var links []string
+	for _, t := range tags(body) {
+		href, ok := t.attrs["href"]
+		if !ok || t.name != "a" {
+			continue
+		}
+
+		u, err := url.Parse(strings.TrimSpace(href))
+		if err == nil && base != nil {
+			u = base.ResolveReference(u)
+		}
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+
+		u.Fragment = ""
+		if link := u.String(); !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// get fetches the page at rawURL, returning its response and (the beginning of) its
+// body.
+func (c *Client) get(
+	ctx context.Context,
+	rawURL string,
+) (*http.Response, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read %s: %w", rawURL, err)
+	}
+
+	return resp, string(body), nil
+}
+
+// tag is an `<a>` or `<link>` tag of a page, along with its (unescaped) attributes.
+type tag struct {
+	name  string
+	attrs map[string]string
+}
+
+// tags returns the `<a>` and `<link>` tags of the HTML.
+func tags(body string) []tag {
+	var all []tag
+	for _, m := range tagPattern.FindAllStringSubmatch(body, -1) {
+		t := tag{name: strings.ToLower(m[1]), attrs: make(map[string]string)}
+		for _, a := range attrPattern.FindAllStringSubmatch(m[0], -1) {
+			name := strings.ToLower(a[1])
+			if _, ok := t.attrs[name]; !ok {
+				t.attrs[name] = html.UnescapeString(a[2] + a[3] + a[4])
+			}
+		}
+		all = append(all, t)
+	}
+
+	return all
+}
+
+// title returns the title of the HTML page, if any.
+func title(body string) string {
+	start := strings.Index(strings.ToLower(body), "<title")
+	if start < 0 {
+		return ""
+	}
+
+	rest := body[start:]
+	open := strings.IndexByte(rest, '>')
+	end := strings.Index(strings.ToLower(rest), "</title>")
+	if open < 0 || end < open {
+		return ""
+	}
+
+	return strings.TrimSpace(html.UnescapeString(rest[open+1 : end]))
+}
+
+// hasRel reports whether the space-separated relationships hold rel.
+func hasRel(rels, rel string) bool {
+	return slices.ContainsFunc(strings.Fields(rels), func(r string) bool {
+		return strings.EqualFold(r, rel)
+	})
+}
+
+// isHTML reports whether the response holds HTML.
+func isHTML(resp *http.Response) bool {
+	return strings.Contains(resp.Header.Get("Content-Type"), "html")
+}
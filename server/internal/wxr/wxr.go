@@ -0,0 +1,143 @@
+/*
+Package wxr parses the WordPress eXtended RSS (WXR) files produced by the export tool
+of WordPress (Tools > Export).
+
+A WXR file is an RSS 2.0 document extended with the `wp`, `dc`, `content` and
+`excerpt` namespaces. Only the parts of the document which can be mapped to the
+resources of BurzContent are parsed: the authors, the items (posts, pages,
+attachments, etc.) along with their categories and tags, and the comments of the
+items.
+
+Example:
+
+	export, err := wxr.Parse(file)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range export.Channel.Items {
+		fmt.Println(item.PostType, item.Title)
+	}
+*/
+package wxr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of the dates of a WXR file, which are in UTC when their
+// element has the `_gmt` suffix.
+const dateLayout = "2006-01-02 15:04:05"
+
+// Export is the root element of a WXR file.
+type Export struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel Channel  `xml:"channel"`
+}
+
+// Channel is the channel of a WXR file, i.e. the exported WordPress site.
+type Channel struct {
+	Title   string   `xml:"title"`
+	Link    string   `xml:"link"`
+	Authors []Author `xml:"author"`
+	Items   []Item   `xml:"item"`
+}
+
+// Author is an author (`wp:author`) of the exported WordPress site.
+type Author struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+// Item is an item of the exported WordPress site, whose type is given by `PostType`
+// (e.g. "post", "page" or "attachment").
+type Item struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	Creator     string     `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Content     string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostID      int        `xml:"post_id"`
+	PostDateGMT string     `xml:"post_date_gmt"`
+	PostName    string     `xml:"post_name"`
+	PostType    string     `xml:"post_type"`
+	Status      string     `xml:"status"`
+	Categories  []Category `xml:"category"`
+	Comments    []Comment  `xml:"comment"`
+}
+
+// Published returns when the item was published, or false if it never was.
+func (i Item) Published() (time.Time, bool) {
+	return parseDate(i.PostDateGMT)
+}
+
+// Category is a term (`category` element) an item is classified with, whose taxonomy
+// is given by `Domain` (e.g. "category" or "post_tag").
+type Category struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// Comment is a comment (`wp:comment`) made on an item.
+type Comment struct {
+	ID          int    `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+}
+
+// Published returns when the comment was made, or false if the date is unknown.
+func (c Comment) Published() (time.Time, bool) {
+	return parseDate(c.DateGMT)
+}
+
+// IsApproved reports whether the comment was approved, i.e. is neither pending nor
+// marked as spam or trashed.
+func (c Comment) IsApproved() bool {
+	return c.Approved == "1"
+}
+
+// IsPingback reports whether the comment is a pingback or a trackback rather than a
+// comment made by a reader.
+func (c Comment) IsPingback() bool {
+	return c.Type == "pingback" || c.Type == "trackback"
+}
+
+// Parse parses the WXR file read from r.
+func Parse(r io.Reader) (*Export, error) {
+	var export Export
+
+	decoder := xml.NewDecoder(r)
+	// WordPress exports declare their encoding, which is always UTF-8 in practice
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if !strings.EqualFold(charset, "utf-8") {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+
+		return input, nil
+	}
+
+	if err := decoder.Decode(&export); err != nil {
+		return nil, fmt.Errorf("unable to parse WXR file: %w", err)
+	}
+
+	return &export, nil
+}
+
+// parseDate parses a (GMT) date of a WXR file, which is zero for the drafts.
+func parseDate(value string) (time.Time, bool) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil || date.Year() < 1970 {
+		return time.Time{}, false
+	}
+
+	return date, true
+}